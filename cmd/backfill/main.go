@@ -0,0 +1,141 @@
+// Command backfill runs the data backfills registered in internal/backfill.
+//
+//	backfill list
+//	backfill status
+//	backfill run -name feedback-source [-dry-run] [-batch 500] [-rate 1000] [-reset]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"rizon-backend/internal/backfill"
+	"rizon-backend/internal/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	name := fs.String("name", "", "backfill to run")
+	dryRun := fs.Bool("dry-run", false, "count changes without writing")
+	batch := fs.Int("batch", 500, "documents per batch")
+	rate := fs.Float64("rate", 0, "max documents per second (0 = unlimited)")
+	reset := fs.Bool("reset", false, "ignore the saved checkpoint and start over")
+	fs.Parse(args)
+
+	if cmd == "list" {
+		for _, b := range backfill.All {
+			fmt.Printf("%-24s %s\n", b.Name, b.Description)
+		}
+		return
+	}
+
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		log.Fatal("❌ MONGODB_URI is required")
+	}
+	if err := database.Connect(mongoURI, getEnv("DB_NAME", "rizon")); err != nil {
+		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
+	}
+	if uri := os.Getenv("SECONDARY_MONGODB_URI"); uri != "" {
+		collections := database.DefaultSecondaryCollections
+		if list := os.Getenv("SECONDARY_COLLECTIONS"); list != "" {
+			collections = splitList(list)
+		}
+		if err := database.ConnectSecondary(uri, getEnv("SECONDARY_DB_NAME", getEnv("DB_NAME", "rizon")), collections); err != nil {
+			log.Fatalf("❌ Failed to connect to secondary MongoDB: %v", err)
+		}
+	}
+
+	// Ctrl-C stops after the current batch; the next run resumes from there
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch cmd {
+	case "status":
+		for _, n := range backfill.Names() {
+			cp, err := backfill.LoadCheckpoint(ctx, n)
+			if err != nil {
+				log.Fatalf("❌ Loading checkpoint for %s failed: %v", n, err)
+			}
+			switch {
+			case cp == nil:
+				fmt.Printf("%-24s never run\n", n)
+			case cp.CompletedAt != nil:
+				fmt.Printf("%-24s completed %s: %d scanned, %d updated\n", n, cp.CompletedAt.Format(time.RFC3339), cp.Stats.Scanned, cp.Stats.Updated)
+			default:
+				fmt.Printf("%-24s stopped at %s: %d scanned, %d updated\n", n, cp.Stats.LastID.Hex(), cp.Stats.Scanned, cp.Stats.Updated)
+			}
+		}
+
+	case "run":
+		b, ok := backfill.Find(*name)
+		if !ok {
+			log.Fatalf("❌ Unknown backfill %q (see \"backfill list\")", *name)
+		}
+		started := time.Now()
+		var lastLog time.Time
+		stats, err := backfill.Run(ctx, b, backfill.Options{
+			DryRun:    *dryRun,
+			BatchSize: *batch,
+			Rate:      *rate,
+			Reset:     *reset,
+			Progress: func(stats backfill.Stats, total int64) {
+				if time.Since(lastLog) < 5*time.Second {
+					return
+				}
+				lastLog = time.Now()
+				perSecond := float64(stats.Scanned) / time.Since(started).Seconds()
+				log.Printf("⏳ %s: %d/%d scanned, %d updated (%.0f docs/s)", b.Name, stats.Scanned, total, stats.Updated, perSecond)
+			},
+		})
+		verb := "updated"
+		if *dryRun {
+			verb = "would update"
+		}
+		if err != nil {
+			log.Fatalf("❌ %s stopped after %d scanned (%d %s): %v", b.Name, stats.Scanned, stats.Updated, verb, err)
+		}
+		log.Printf("✅ %s: %d scanned, %d %s, %d skipped in %s", b.Name, stats.Scanned, stats.Updated, verb, stats.Skipped, time.Since(started).Round(time.Millisecond))
+
+	default:
+		usage()
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backfill list|status|run [flags]")
+	os.Exit(2)
+}
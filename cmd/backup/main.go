@@ -0,0 +1,164 @@
+// Command backup dumps Mongo collections to a gzipped NDJSON file (locally or
+// in S3) and restores them again.
+//
+//	backup dump    [-collections users,feedbacks] [-out dump.ndjson.gz | -s3]
+//	backup restore [-collections users] (-in dump.ndjson.gz | -s3-key path/to/object)
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/backup"
+	"rizon-backend/internal/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	collections := fs.String("collections", strings.Join(backup.DefaultCollections, ","), "comma-separated collections")
+	out := fs.String("out", "", "write dump to this file")
+	in := fs.String("in", "", "restore from this file")
+	toS3 := fs.Bool("s3", false, "upload dump to BACKUP_S3_BUCKET")
+	s3Key := fs.String("s3-key", "", "restore from this S3 object key")
+	fs.Parse(args)
+
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		log.Fatal("❌ MONGODB_URI is required")
+	}
+	if err := database.Connect(mongoURI, getEnv("DB_NAME", "rizon")); err != nil {
+		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
+	}
+	if uri := os.Getenv("SECONDARY_MONGODB_URI"); uri != "" {
+		collections := database.DefaultSecondaryCollections
+		if list := os.Getenv("SECONDARY_COLLECTIONS"); list != "" {
+			collections = splitList(list)
+		}
+		if err := database.ConnectSecondary(uri, getEnv("SECONDARY_DB_NAME", getEnv("DB_NAME", "rizon")), collections); err != nil {
+			log.Fatalf("❌ Failed to connect to secondary MongoDB: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	names := splitList(*collections)
+
+	switch cmd {
+	case "dump":
+		var buf bytes.Buffer
+		stats, err := backup.Dump(ctx, database.GetCollection, names, &buf)
+		if err != nil {
+			log.Fatalf("❌ Dump failed: %v", err)
+		}
+		switch {
+		case *toS3:
+			store := s3FromEnv()
+			key := backup.ObjectKey(getEnv("BACKUP_S3_PREFIX", "backups"), time.Now())
+			if err := store.Put(ctx, key, buf.Bytes()); err != nil {
+				log.Fatalf("❌ Upload failed: %v", err)
+			}
+			log.Printf("☁️  Uploaded %d bytes to s3://%s/%s", buf.Len(), os.Getenv("BACKUP_S3_BUCKET"), key)
+		case *out != "":
+			if err := os.WriteFile(*out, buf.Bytes(), 0o600); err != nil {
+				log.Fatalf("❌ Write failed: %v", err)
+			}
+			log.Printf("💾 Wrote %d bytes to %s", buf.Len(), *out)
+		default:
+			os.Stdout.Write(buf.Bytes())
+		}
+		logStats("Dumped", stats)
+
+	case "restore":
+		var r io.Reader
+		switch {
+		case *s3Key != "":
+			data, err := s3FromEnv().Get(ctx, *s3Key)
+			if err != nil {
+				log.Fatalf("❌ Download failed: %v", err)
+			}
+			r = bytes.NewReader(data)
+		case *in != "":
+			f, err := os.Open(*in)
+			if err != nil {
+				log.Fatalf("❌ Open failed: %v", err)
+			}
+			defer f.Close()
+			r = f
+		default:
+			r = os.Stdin
+		}
+		// Only filter when -collections was given explicitly
+		var only []string
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "collections" {
+				only = names
+			}
+		})
+		stats, err := backup.Restore(ctx, database.GetCollection, r, only)
+		if err != nil {
+			log.Fatalf("❌ Restore failed: %v", err)
+		}
+		logStats("Restored", stats)
+
+	default:
+		usage()
+	}
+}
+
+func s3FromEnv() *backup.S3Store {
+	bucket := os.Getenv("BACKUP_S3_BUCKET")
+	if bucket == "" {
+		log.Fatal("❌ BACKUP_S3_BUCKET is required for S3 backups")
+	}
+	return backup.NewS3Store(
+		os.Getenv("BACKUP_S3_ENDPOINT"),
+		getEnv("BACKUP_S3_REGION", "us-east-1"),
+		bucket,
+		os.Getenv("AWS_ACCESS_KEY_ID"),
+		os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	)
+}
+
+func logStats(verb string, stats backup.Stats) {
+	for name, n := range stats {
+		log.Printf("✅ %s %d documents from %s", verb, n, name)
+	}
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backup dump|restore [flags]")
+	os.Exit(2)
+}
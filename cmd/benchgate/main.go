@@ -0,0 +1,131 @@
+// Command benchgate reads `go test -bench` output on stdin and fails when a
+// benchmark's p95-ms metric is over its budget, so CI can catch latency
+// regressions in the repository hot paths.
+//
+//	go test ./internal/repository -run '^$' -bench . -count 3 | \
+//	    benchgate -budget 'FeedbackCreate=5ms,*=10ms'
+//
+// Budgets are keyed by benchmark name without the Benchmark prefix; "*" sets
+// a default. With -count above one the worst run is checked. Output with no
+// p95 results fails too, since it usually means the benchmarks were skipped.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// benchLine matches "BenchmarkName-8   1234   5678 ns/op   1.23 p95-ms".
+var benchLine = regexp.MustCompile(`^Benchmark(\S+?)(?:-\d+)?\s+\d+\s+(.*)$`)
+
+func main() {
+	budgetSpec := flag.String("budget", "", "p95 budgets, e.g. FeedbackCreate=5ms,*=10ms")
+	flag.Parse()
+
+	budgets, err := parseBudgets(*budgetSpec)
+	if err != nil {
+		log.Fatalf("❌ Invalid -budget: %v", err)
+	}
+	p95s, err := readP95s(io.TeeReader(os.Stdin, os.Stderr))
+	if err != nil {
+		log.Fatalf("❌ Reading benchmark output: %v", err)
+	}
+	if len(p95s) == 0 {
+		log.Fatal("❌ No p95-ms results in the input; were the benchmarks skipped?")
+	}
+	if !report(p95s, budgets) {
+		os.Exit(1)
+	}
+}
+
+// readP95s returns the worst p95 seen for each benchmark.
+func readP95s(r io.Reader) (map[string]time.Duration, error) {
+	p95s := map[string]time.Duration{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := benchLine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		fields := strings.Fields(m[2])
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "p95-ms" {
+				continue
+			}
+			ms, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", m[1], err)
+			}
+			p95 := time.Duration(ms * float64(time.Millisecond))
+			p95s[m[1]] = max(p95s[m[1]], p95)
+		}
+	}
+	return p95s, scanner.Err()
+}
+
+// report prints a row per benchmark and returns false when a budget is exceeded.
+func report(p95s map[string]time.Duration, budgets map[string]time.Duration) bool {
+	names := make([]string, 0, len(p95s))
+	for name := range p95s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ok := true
+	fmt.Printf("\n%-36s %10s %10s\n", "benchmark", "p95", "budget")
+	for _, name := range names {
+		budget, hasBudget := budgets[name]
+		if !hasBudget {
+			budget, hasBudget = budgets["*"]
+		}
+		budgetCol := "-"
+		if hasBudget {
+			budgetCol = budget.String()
+			if p95s[name] > budget {
+				budgetCol += " ❌"
+				ok = false
+			}
+		}
+		fmt.Printf("%-36s %10s %10s\n", name, p95s[name].Round(time.Microsecond), budgetCol)
+	}
+	for name := range budgets {
+		if _, ran := p95s[name]; name != "*" && !ran {
+			fmt.Printf("❌ %s has a budget but didn't run\n", name)
+			ok = false
+		}
+	}
+	if ok {
+		fmt.Println("✅ Within budget")
+	}
+	return ok
+}
+
+// parseBudgets reads "name=duration,..." into a map.
+func parseBudgets(spec string) (map[string]time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not name=duration", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		budgets[strings.TrimSpace(name)] = d
+	}
+	return budgets, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadP95s(t *testing.T) {
+	out := `goos: linux
+pkg: rizon-backend/internal/repository
+BenchmarkFeedbackCreate-8         	    2000	    812345 ns/op	         1.500 p95-ms
+BenchmarkFeedbackCreate-8         	    2000	    812345 ns/op	         2.250 p95-ms
+BenchmarkUserFindOrCreate         	    2000	    400000 ns/op	         0.900 p95-ms
+BenchmarkNoMetric-8               	    2000	    400000 ns/op
+--- SKIP: BenchmarkSkipped
+PASS`
+	got, err := readP95s(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]time.Duration{
+		"FeedbackCreate":   2250 * time.Microsecond, // worst of the runs
+		"UserFindOrCreate": 900 * time.Microsecond,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for name, p95 := range want {
+		if got[name] != p95 {
+			t.Errorf("%s = %s, want %s", name, got[name], p95)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	p95s := map[string]time.Duration{"FeedbackCreate": 3 * time.Millisecond, "UserFindOrCreate": time.Millisecond}
+	tests := []struct {
+		name    string
+		budgets map[string]time.Duration
+		ok      bool
+	}{
+		{"no budgets", nil, true},
+		{"within", map[string]time.Duration{"*": 5 * time.Millisecond}, true},
+		{"named over", map[string]time.Duration{"FeedbackCreate": 2 * time.Millisecond, "*": 5 * time.Millisecond}, false},
+		{"default over", map[string]time.Duration{"*": 2 * time.Millisecond}, false},
+		{"budgeted but missing", map[string]time.Duration{"Gone": time.Second}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok := report(p95s, tt.budgets); ok != tt.ok {
+				t.Errorf("ok = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Command contracts checks or regenerates the response fixtures shared with
+// the mobile client. Run it from the repository root.
+//
+//	contracts check
+//	contracts update
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"rizon-backend/internal/contracts"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "check":
+		problems, err := contracts.Check(contracts.Dir)
+		if err != nil {
+			log.Fatalf("❌ Contract check failed: %v", err)
+		}
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "❌ "+p)
+		}
+		if len(problems) > 0 {
+			fmt.Fprintln(os.Stderr, "Review the change with the mobile team, then run `make contract-fixtures`.")
+			os.Exit(1)
+		}
+		log.Printf("✅ %d contracts match for versions %v", len(contracts.All), contracts.Versions)
+
+	case "update":
+		if err := contracts.Write(contracts.Dir); err != nil {
+			log.Fatalf("❌ Writing fixtures failed: %v", err)
+		}
+		log.Printf("💾 Wrote fixtures to %s", contracts.Dir)
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: contracts check|update")
+	os.Exit(2)
+}
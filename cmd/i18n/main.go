@@ -0,0 +1,291 @@
+// Command i18n keeps the message catalogs in internal/i18n/locales in step
+// with the code. Run it from the repository root.
+//
+//	i18n extract      add messages used in code to en.json (empty, to be written)
+//	i18n check        fail on messages missing from en.json, unused ones, or
+//	                  translations whose placeholders differ from the English
+//	i18n todo <tag>   print the English messages <tag> hasn't translated, as a
+//	                  catalog to hand to translators
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"rizon-backend/internal/i18n"
+)
+
+// Message IDs appear in templates as {{t "id" ...}} / {{tn "id" ...}} and
+// in Go as .T("id", ...) / .N("id", ...). IDs built at run time can't be
+// found; list them in i18n.FormatKeys or avoid them.
+var usePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\{\{-?\s*tn?\s+"([^"]+)"`),
+	regexp.MustCompile(`\.[TN]\("([^"]+)"\s*[,)]`),
+}
+
+// sourceDirs are scanned for message IDs, except skipDirs (the i18n package
+// and this command, whose examples aren't real messages).
+var (
+	sourceDirs = []string{"cmd", "internal"}
+	skipDirs   = map[string]bool{"internal/i18n": true, "cmd/i18n": true}
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "extract":
+		used, err := usedIDs()
+		if err != nil {
+			log.Fatalf("❌ Scanning sources failed: %v", err)
+		}
+		en, err := readCatalog(i18n.DefaultLocale)
+		if err != nil {
+			log.Fatalf("❌ Reading catalog failed: %v", err)
+		}
+		var added []string
+		for id := range used {
+			if _, ok := en[id]; !ok {
+				en[id] = i18n.Message{}
+				added = append(added, id)
+			}
+		}
+		sort.Strings(added)
+		if len(added) == 0 {
+			log.Printf("✅ %s.json already has all %d messages", i18n.DefaultLocale, len(used))
+			return
+		}
+		if err := writeCatalog(i18n.DefaultLocale, en); err != nil {
+			log.Fatalf("❌ Writing catalog failed: %v", err)
+		}
+		for _, id := range added {
+			fmt.Printf("+ %s (%s)\n", id, strings.Join(used[id], ", "))
+		}
+		log.Printf("💾 Added %d messages to %s.json; write the English text, then run `make i18n-check`", len(added), i18n.DefaultLocale)
+
+	case "check":
+		problems, untranslated, err := check()
+		if err != nil {
+			log.Fatalf("❌ i18n check failed: %v", err)
+		}
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "❌ "+p)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		for _, u := range untranslated {
+			log.Printf("ℹ️  %s", u)
+		}
+		log.Printf("✅ Catalogs for %v match the code", i18n.Locales())
+
+	case "todo":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		todo, err := missingTranslations(os.Args[2])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		out, err := encode(todo)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		os.Stdout.Write(out)
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: i18n extract|check|todo <tag>")
+	os.Exit(2)
+}
+
+// usedIDs maps each message ID used in code to where it's used.
+func usedIDs() (map[string][]string, error) {
+	used := map[string][]string{}
+	for _, id := range i18n.FormatKeys {
+		used[id] = append(used[id], "i18n formatting")
+	}
+	for _, dir := range sourceDirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && skipDirs[filepath.ToSlash(path)] {
+				return filepath.SkipDir
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, re := range usePatterns {
+				for _, m := range re.FindAllSubmatchIndex(src, -1) {
+					id := string(src[m[2]:m[3]])
+					line := bytes.Count(src[:m[0]], []byte("\n")) + 1
+					used[id] = append(used[id], fmt.Sprintf("%s:%d", path, line))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return used, nil
+}
+
+func check() (problems, untranslated []string, err error) {
+	used, err := usedIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	en, err := readCatalog(i18n.DefaultLocale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for id, where := range used {
+		m, ok := en[id]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is used (%s) but missing from %s.json; run `make i18n-extract`", id, where[0], i18n.DefaultLocale))
+		case m.Text == "" && m.Plural == nil:
+			problems = append(problems, fmt.Sprintf("%s has no English text in %s.json", id, i18n.DefaultLocale))
+		}
+	}
+	for id := range en {
+		if _, ok := used[id]; !ok {
+			problems = append(problems, fmt.Sprintf("%s in %s.json isn't used anywhere", id, i18n.DefaultLocale))
+		}
+	}
+
+	// Formats are patterns of their own, not sentences to translate
+	formats := map[string]bool{}
+	for _, id := range i18n.FormatKeys {
+		formats[id] = true
+	}
+
+	for _, tag := range i18n.Locales() {
+		if tag == i18n.DefaultLocale {
+			continue
+		}
+		catalog, err := readCatalog(tag)
+		if err != nil {
+			return nil, nil, err
+		}
+		for id, m := range catalog {
+			source, ok := en[id]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.json has %s, which isn't in %s.json", tag, id, i18n.DefaultLocale))
+				continue
+			}
+			if formats[id] {
+				continue
+			}
+			want := placeholderSet(source)
+			for _, text := range m.Texts() {
+				if got := placeholderSet(i18n.Message{Text: text}); got != want {
+					problems = append(problems, fmt.Sprintf("%s.json %s uses placeholders [%s], the English uses [%s]", tag, id, got, want))
+				}
+			}
+		}
+		if !strings.Contains(tag, "-") {
+			if todo, err := missingTranslations(tag); err == nil && len(todo) > 0 {
+				untranslated = append(untranslated, fmt.Sprintf("%s.json is missing %d messages (English is shown instead); see `go run ./cmd/i18n todo %s`", tag, len(todo), tag))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems, untranslated, nil
+}
+
+// placeholderSet lists a message's placeholders, sorted and joined; plural
+// forms may omit {count}.
+func placeholderSet(m i18n.Message) string {
+	seen := map[string]bool{}
+	for _, text := range m.Texts() {
+		for _, p := range i18n.Placeholders(text) {
+			if p != "count" {
+				seen[p] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for p := range seen {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// missingTranslations returns the English messages tag has no entry for.
+func missingTranslations(tag string) (i18n.Catalog, error) {
+	en, err := readCatalog(i18n.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := readCatalog(tag)
+	if err != nil {
+		if os.IsNotExist(err) {
+			catalog = i18n.Catalog{} // a new language: everything is missing
+		} else {
+			return nil, err
+		}
+	}
+	todo := i18n.Catalog{}
+	for id, m := range en {
+		if _, ok := catalog[id]; !ok {
+			todo[id] = m
+		}
+	}
+	return todo, nil
+}
+
+func readCatalog(tag string) (i18n.Catalog, error) {
+	data, err := os.ReadFile(filepath.Join(i18n.Dir, tag+".json"))
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := i18n.ParseCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s.json: %w", tag, err)
+	}
+	return catalog, nil
+}
+
+func writeCatalog(tag string, catalog i18n.Catalog) error {
+	out, err := encode(catalog)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(i18n.Dir, tag+".json"), out, 0o644)
+}
+
+// encode writes a catalog the way the checked-in files are laid out: sorted
+// keys, two-space indent, no HTML escaping.
+func encode(catalog i18n.Catalog) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(catalog); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
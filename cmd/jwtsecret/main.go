@@ -0,0 +1,129 @@
+// Command jwtsecret walks through rotating JWT_SECRET without logging users
+// out (see package jwtkeys). It reads the current JWT_SECRET and
+// JWT_ACCEPTED_SECRETS and prints the configuration to deploy next; it
+// never changes anything itself.
+//
+//	jwtsecret status               list configured keys
+//	jwtsecret mint                 add a new secret, accepted but not issuing yet
+//	jwtsecret flip [-kid id]       issue with an accepted secret, keep the old one accepted
+//	jwtsecret retire -kid id       stop accepting a secret
+//
+// Deploy each step to every instance before running the next. Retire the
+// old key once GET /admin/auth/jwt-keys shows no new validations for it.
+// Signed download links made with it keep working until then too, but
+// aren't counted there.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"rizon-backend/internal/jwtkeys"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	kid := fs.String("kid", "", "key ID, as shown by status")
+	fs.Parse(args)
+
+	current := os.Getenv("JWT_SECRET")
+	if current == "" {
+		log.Fatal("❌ JWT_SECRET is required")
+	}
+	accepted := splitList(os.Getenv("JWT_ACCEPTED_SECRETS"))
+
+	switch cmd {
+	case "status":
+		fmt.Printf("%s  issuing\n", jwtkeys.KeyID(current))
+		for _, s := range accepted {
+			fmt.Printf("%s  accepted\n", jwtkeys.KeyID(s))
+		}
+
+	case "mint":
+		secret := newSecret()
+		log.Printf("🔑 Minted key %s. Deploy this, then run flip:", jwtkeys.KeyID(secret))
+		printConfig(current, append([]string{secret}, accepted...))
+
+	case "flip":
+		if len(accepted) == 0 {
+			log.Fatal("❌ No accepted secret to flip to; run mint first")
+		}
+		i := 0
+		if *kid != "" {
+			i = indexOf(accepted, *kid)
+		}
+		next := accepted[i]
+		rest := append([]string{current}, slices.Delete(slices.Clone(accepted), i, i+1)...)
+		log.Printf("🔁 Key %s will issue; %s stays accepted. Deploy this, then retire %s once it's unused:",
+			jwtkeys.KeyID(next), jwtkeys.KeyID(current), jwtkeys.KeyID(current))
+		printConfig(next, rest)
+
+	case "retire":
+		if *kid == "" {
+			log.Fatal("❌ -kid is required")
+		}
+		if *kid == jwtkeys.KeyID(current) {
+			log.Fatal("❌ Can't retire the issuing key; flip first")
+		}
+		i := indexOf(accepted, *kid)
+		log.Printf("🗑️  Tokens signed with %s will stop working once this is deployed:", *kid)
+		printConfig(current, slices.Delete(slices.Clone(accepted), i, i+1))
+
+	default:
+		usage()
+	}
+}
+
+// newSecret returns 48 random bytes, base64url encoded.
+func newSecret() string {
+	b := make([]byte, 48)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("❌ Generating secret: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func indexOf(secrets []string, kid string) int {
+	for i, s := range secrets {
+		if jwtkeys.KeyID(s) == kid {
+			return i
+		}
+	}
+	log.Fatalf("❌ No accepted secret has key ID %s", kid)
+	return -1
+}
+
+func printConfig(issuing string, accepted []string) {
+	fmt.Printf("JWT_SECRET=%s\n", issuing)
+	fmt.Printf("JWT_ACCEPTED_SECRETS=%s\n", strings.Join(accepted, ","))
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jwtsecret status|mint|flip|retire [-kid id]")
+	os.Exit(2)
+}
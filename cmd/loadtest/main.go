@@ -0,0 +1,383 @@
+// Command loadtest drives the auth and feedback flows against a running
+// environment at a fixed request rate and checks latency against per-endpoint
+// p95 budgets, exiting non-zero when a budget or the error budget is blown so
+// CI can gate on it.
+//
+//	loadtest -target https://staging.example.com -token $JWT -refresh-token $REFRESH \
+//	    -scenario all -rate 50 -duration 1m \
+//	    -budget auth_request=400ms,feedback_submit=300ms
+//
+// Point it at staging: login requests send email and feedback posts to Slack
+// unless the target runs with mock providers.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// step is one request in a scenario, named for reporting and budgets.
+type step struct {
+	name string
+	do   func(ctx context.Context, c *client, n int64) (int, error)
+}
+
+var scenarios = map[string][]step{
+	"auth": {
+		{"auth_request", func(ctx context.Context, c *client, n int64) (int, error) {
+			// Unique addresses so the per-email login limit doesn't kick in
+			email := fmt.Sprintf("loadtest+%s-%d@%s", c.runID, n, c.emailDomain)
+			return c.do(ctx, http.MethodPost, "/auth/request", map[string]string{"email": email}, false)
+		}},
+		{"auth_refresh", func(ctx context.Context, c *client, _ int64) (int, error) {
+			return c.refresh(ctx)
+		}},
+		{"user_status", func(ctx context.Context, c *client, _ int64) (int, error) {
+			return c.do(ctx, http.MethodGet, "/user/status", nil, true)
+		}},
+	},
+	"feedback": {
+		{"feedback_submit", func(ctx context.Context, c *client, n int64) (int, error) {
+			return c.do(ctx, http.MethodPost, "/feedback", map[string]interface{}{
+				"text":            fmt.Sprintf("Load test feedback %d", n),
+				"rating":          5,
+				"category":        "other",
+				"idempotency_key": uuid.New().String(),
+			}, true)
+		}},
+		{"feedback_follow_ups", func(ctx context.Context, c *client, _ int64) (int, error) {
+			return c.do(ctx, http.MethodGet, "/feedback/follow-ups", nil, true)
+		}},
+		{"feedback_prompt", func(ctx context.Context, c *client, _ int64) (int, error) {
+			return c.do(ctx, http.MethodGet, "/feedback/prompt", nil, true)
+		}},
+	},
+}
+
+func main() {
+	target := flag.String("target", os.Getenv("LOADTEST_TARGET"), "base URL of the environment under test")
+	token := flag.String("token", os.Getenv("LOADTEST_TOKEN"), "user JWT for authenticated requests")
+	refreshToken := flag.String("refresh-token", os.Getenv("LOADTEST_REFRESH_TOKEN"), "refresh token from the same sign-in as -token")
+	scenario := flag.String("scenario", "all", "auth, feedback or all")
+	rate := flag.Float64("rate", 20, "requests per second across all steps")
+	duration := flag.Duration("duration", 30*time.Second, "how long to send requests")
+	concurrency := flag.Int("concurrency", 50, "max requests in flight")
+	budgetSpec := flag.String("budget", "", "p95 budgets, e.g. auth_request=400ms,feedback_submit=300ms (\"*\" sets a default)")
+	maxErrors := flag.Float64("max-error-rate", 0.01, "fail when more than this fraction of requests fail")
+	emailDomain := flag.String("email-domain", "example.com", "domain for generated login emails")
+	flag.Parse()
+
+	if *target == "" {
+		log.Fatal("❌ -target (or LOADTEST_TARGET) is required")
+	}
+	budgets, err := parseBudgets(*budgetSpec)
+	if err != nil {
+		log.Fatalf("❌ Invalid -budget: %v", err)
+	}
+
+	var steps []step
+	switch *scenario {
+	case "all":
+		steps = append(append(steps, scenarios["auth"]...), scenarios["feedback"]...)
+	default:
+		steps = scenarios[*scenario]
+	}
+	if len(steps) == 0 {
+		log.Fatalf("❌ Unknown scenario %q", *scenario)
+	}
+	if *token == "" {
+		log.Fatal("❌ -token (or LOADTEST_TOKEN) is required for the authenticated steps")
+	}
+	for _, s := range steps {
+		if s.name == "auth_refresh" && *refreshToken == "" {
+			log.Fatal("❌ -refresh-token (or LOADTEST_REFRESH_TOKEN) is required for the auth_refresh step")
+		}
+	}
+
+	c := &client{
+		base:         strings.TrimRight(*target, "/"),
+		token:        *token,
+		refreshToken: *refreshToken,
+		emailDomain:  *emailDomain,
+		runID:        uuid.New().String()[:8],
+		http:         &http.Client{Timeout: 30 * time.Second},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	results := newRecorder()
+	log.Printf("🚀 %s scenario against %s at %.0f req/s for %s", *scenario, c.base, *rate, *duration)
+	started := time.Now()
+	dropped := run(runCtx, c, steps, *rate, *concurrency, results)
+	elapsed := time.Since(started)
+
+	if ok := report(results, elapsed, dropped, budgets, *maxErrors); !ok {
+		os.Exit(1)
+	}
+}
+
+// run sends requests at a fixed rate, cycling through steps, until ctx ends.
+// Ticks that find every slot busy are dropped (and counted) rather than
+// queued, so a slow server can't hide behind a backlog.
+func run(ctx context.Context, c *client, steps []step, rate float64, concurrency int, results *recorder) (dropped int64) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var n int64
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return dropped
+		case <-ticker.C:
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+
+		i := atomic.AddInt64(&n, 1)
+		s := steps[int(i-1)%len(steps)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			// Requests already sent finish even after the run ends
+			reqCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			start := time.Now()
+			status, err := s.do(reqCtx, c, i)
+			results.add(s.name, time.Since(start), status, err)
+		}()
+	}
+}
+
+type client struct {
+	base        string
+	emailDomain string
+	runID       string
+	http        *http.Client
+
+	// Refresh tokens are single-use: refreshes run one at a time and
+	// replace both tokens for the requests that follow.
+	refreshing   sync.Mutex
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+}
+
+func (c *client) accessToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// refresh rotates the session via /auth/refresh.
+func (c *client) refresh(ctx context.Context) (int, error) {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	c.mu.Lock()
+	body := map[string]string{"refresh_token": c.refreshToken}
+	c.mu.Unlock()
+	data, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"/auth/refresh", bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rizon-loadtest")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+
+	var session struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		return resp.StatusCode, err
+	}
+	c.mu.Lock()
+	c.token, c.refreshToken = session.Token, session.RefreshToken
+	c.mu.Unlock()
+	return resp.StatusCode, nil
+}
+
+func (c *client) do(ctx context.Context, method, path string, body interface{}, auth bool) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "rizon-loadtest")
+	if auth {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken())
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+type stepStats struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	steps map[string]*stepStats
+}
+
+func newRecorder() *recorder {
+	return &recorder{steps: map[string]*stepStats{}}
+}
+
+func (r *recorder) add(name string, latency time.Duration, status int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.steps[name]
+	if s == nil {
+		s = &stepStats{statuses: map[int]int{}}
+		r.steps[name] = s
+	}
+	s.latencies = append(s.latencies, latency)
+	s.statuses[status]++
+	if err != nil || status == 0 || status >= 400 {
+		s.errors++
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// report prints a table per step and returns false when a budget is exceeded.
+func report(r *recorder, elapsed time.Duration, dropped int64, budgets map[string]time.Duration, maxErrorRate float64) bool {
+	names := make([]string, 0, len(r.steps))
+	for name := range r.steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ok := true
+	var total, failed int
+	fmt.Printf("\n%-22s %7s %7s %9s %9s %9s %9s  %s\n", "step", "count", "errors", "p50", "p95", "p99", "budget", "statuses")
+	for _, name := range names {
+		s := r.steps[name]
+		sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+		p95 := percentile(s.latencies, 0.95)
+		total += len(s.latencies)
+		failed += s.errors
+
+		budget, hasBudget := budgets[name]
+		if !hasBudget {
+			budget, hasBudget = budgets["*"]
+		}
+		budgetCol := "-"
+		if hasBudget {
+			budgetCol = budget.String()
+			if p95 > budget {
+				budgetCol += " ❌"
+				ok = false
+			}
+		}
+		fmt.Printf("%-22s %7d %7d %9s %9s %9s %9s  %v\n", name, len(s.latencies), s.errors,
+			percentile(s.latencies, 0.50).Round(time.Millisecond), p95.Round(time.Millisecond),
+			percentile(s.latencies, 0.99).Round(time.Millisecond), budgetCol, s.statuses)
+	}
+
+	errorRate := 0.0
+	if total > 0 {
+		errorRate = float64(failed) / float64(total)
+	}
+	fmt.Printf("\n%d requests in %s (%.1f req/s), %.2f%% errors, %d dropped\n",
+		total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds(), errorRate*100, dropped)
+	if errorRate > maxErrorRate {
+		fmt.Printf("❌ Error rate %.2f%% is over the %.2f%% budget\n", errorRate*100, maxErrorRate*100)
+		ok = false
+	}
+	if dropped > 0 {
+		fmt.Println("⚠️  Some ticks were dropped with every slot busy; raise -concurrency or the server is saturated")
+	}
+	if ok {
+		fmt.Println("✅ Within budget")
+	}
+	return ok
+}
+
+// parseBudgets reads "name=duration,..." into a map.
+func parseBudgets(spec string) (map[string]time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not name=duration", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		budgets[strings.TrimSpace(name)] = d
+	}
+	return budgets, nil
+}
@@ -0,0 +1,256 @@
+// Command replay sends requests recorded on staging (REQUEST_RECORDING_PERCENT)
+// to another build and reports, per route, where the response status differs
+// from the recorded one, so refactors can be checked against real traffic
+// shapes before they ship.
+//
+//	replay -target http://localhost:8080 -since 24h -token $JWT -admin-key $KEY
+//	replay -target http://localhost:8080 -route /feedback -prefix /v1
+//
+// Recorded credentials are never stored: requests that were authenticated
+// are sent with -token, -admin-key or -api-key, and skipped when the matching
+// flag is missing. Replays write to the target, so point it at a disposable
+// database, and run it with REQUEST_SIGNING_ENFORCE off.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	target := flag.String("target", os.Getenv("REPLAY_TARGET"), "base URL of the build under test")
+	since := flag.Duration("since", 24*time.Hour, "replay requests recorded within this window")
+	route := flag.String("route", "", "only replay routes starting with this prefix")
+	limit := flag.Int64("limit", 0, "max requests to replay (0 = all)")
+	prefix := flag.String("prefix", "", "path prefix added to every request, e.g. /v1")
+	token := flag.String("token", os.Getenv("REPLAY_TOKEN"), "user JWT for requests recorded with one")
+	adminKey := flag.String("admin-key", os.Getenv("REPLAY_ADMIN_KEY"), "admin key for admin requests")
+	apiKey := flag.String("api-key", os.Getenv("REPLAY_API_KEY"), "integration key for integration requests")
+	concurrency := flag.Int("concurrency", 10, "max requests in flight")
+	maxMismatch := flag.Float64("max-mismatch-rate", 0, "fail when more than this fraction of replayed requests change status")
+	flag.Parse()
+
+	if *target == "" {
+		log.Fatal("❌ -target (or REPLAY_TARGET) is required")
+	}
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		log.Fatal("❌ MONGODB_URI (where the recordings are) is required")
+	}
+	if err := database.Connect(mongoURI, getEnv("DB_NAME", "rizon")); err != nil {
+		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	r := &replayer{
+		base: strings.TrimRight(*target, "/") + *prefix,
+		credentials: map[string]credential{
+			models.RecordedAuthUser:        {"Authorization", bearer(*token)},
+			models.RecordedAuthAdmin:       {"X-Admin-Key", *adminKey},
+			models.RecordedAuthIntegration: {"X-API-Key", *apiKey},
+		},
+		http:   &http.Client{Timeout: 30 * time.Second},
+		routes: map[string]*routeStats{},
+	}
+
+	slots := make(chan struct{}, *concurrency)
+	var wg sync.WaitGroup
+	started := time.Now()
+	err := repository.NewRecordedRequestRepo().Each(ctx, time.Now().Add(-*since), *route, *limit, func(req *models.RecordedRequest) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r.replay(ctx, req)
+		}()
+		return nil
+	})
+	wg.Wait()
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("❌ Reading recordings failed: %v", err)
+	}
+
+	if ok := r.report(time.Since(started), *maxMismatch); !ok {
+		os.Exit(1)
+	}
+}
+
+type credential struct {
+	header string
+	value  string
+}
+
+func bearer(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "Bearer " + token
+}
+
+type routeStats struct {
+	replayed   int
+	skipped    int
+	mismatches map[string]int // "recorded→replayed" status pairs
+	recorded   []time.Duration
+	latencies  []time.Duration
+}
+
+type replayer struct {
+	base        string
+	credentials map[string]credential
+	http        *http.Client
+
+	mu     sync.Mutex
+	routes map[string]*routeStats
+}
+
+func (r *replayer) stats(key string) *routeStats {
+	s := r.routes[key]
+	if s == nil {
+		s = &routeStats{mismatches: map[string]int{}}
+		r.routes[key] = s
+	}
+	return s
+}
+
+func (r *replayer) replay(ctx context.Context, rec *models.RecordedRequest) {
+	key := rec.Method + " " + rec.Route
+
+	var cred credential
+	if rec.Auth != models.RecordedAuthNone {
+		cred = r.credentials[rec.Auth]
+		if cred.value == "" {
+			r.mu.Lock()
+			r.stats(key).skipped++
+			r.mu.Unlock()
+			return
+		}
+	}
+
+	var body io.Reader
+	if rec.Body != "" {
+		body = strings.NewReader(rec.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, rec.Method, r.base+rec.URI, body)
+	if err != nil {
+		log.Printf("⚠️  Skipping %s %s: %v", rec.Method, rec.URI, err)
+		return
+	}
+	for name, value := range rec.Headers {
+		req.Header.Set(name, value)
+	}
+	if cred.header != "" {
+		req.Header.Set(cred.header, cred.value)
+	}
+
+	start := time.Now()
+	status := 0
+	resp, err := r.http.Do(req)
+	if err == nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		status = resp.StatusCode
+	}
+	elapsed := time.Since(start)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats(key)
+	s.replayed++
+	s.recorded = append(s.recorded, time.Duration(rec.DurationMs)*time.Millisecond)
+	s.latencies = append(s.latencies, elapsed)
+	if status != rec.Status {
+		s.mismatches[fmt.Sprintf("%d→%d", rec.Status, status)]++
+	}
+}
+
+func p95(latencies []time.Duration) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(float64(len(sorted))*0.95+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// report prints a table per route and returns false when the mismatch rate
+// is over the budget.
+func (r *replayer) report(elapsed time.Duration, maxMismatch float64) bool {
+	keys := make([]string, 0, len(r.routes))
+	for key := range r.routes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var replayed, skipped, mismatched int
+	fmt.Printf("\n%-44s %8s %8s %10s %12s %12s  %s\n", "route", "replayed", "skipped", "mismatched", "p95 before", "p95 after", "status changes")
+	for _, key := range keys {
+		s := r.routes[key]
+		changed := 0
+		pairs := make([]string, 0, len(s.mismatches))
+		for pair, n := range s.mismatches {
+			changed += n
+			pairs = append(pairs, fmt.Sprintf("%s×%d", pair, n))
+		}
+		sort.Strings(pairs)
+		replayed += s.replayed
+		skipped += s.skipped
+		mismatched += changed
+
+		marker := ""
+		if changed > 0 {
+			marker = " ❌"
+		}
+		fmt.Printf("%-44s %8d %8d %10d %12s %12s  %s%s\n", key, s.replayed, s.skipped, changed,
+			p95(s.recorded).Round(time.Millisecond), p95(s.latencies).Round(time.Millisecond), strings.Join(pairs, " "), marker)
+	}
+
+	rate := 0.0
+	if replayed > 0 {
+		rate = float64(mismatched) / float64(replayed)
+	}
+	fmt.Printf("\n%d requests replayed in %s, %d skipped (no credentials), %.2f%% changed status\n",
+		replayed, elapsed.Round(time.Millisecond), skipped, rate*100)
+	if rate > maxMismatch {
+		fmt.Printf("❌ Mismatch rate %.2f%% is over the %.2f%% budget\n", rate*100, maxMismatch*100)
+		return false
+	}
+	fmt.Println("✅ Responses match the recording")
+	return true
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
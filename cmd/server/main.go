@@ -1,22 +1,75 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"rizon-backend/internal/abuse"
+	"rizon-backend/internal/adminfeed"
+	"rizon-backend/internal/agegate"
+	"rizon-backend/internal/attestation"
+	"rizon-backend/internal/authz"
+	"rizon-backend/internal/backfill"
+	"rizon-backend/internal/backup"
+	"rizon-backend/internal/buildinfo"
+	"rizon-backend/internal/bulkmail"
+	"rizon-backend/internal/campaign"
+	"rizon-backend/internal/captcha"
+	"rizon-backend/internal/changestream"
+	"rizon-backend/internal/contracts"
+	"rizon-backend/internal/crypto"
 	"rizon-backend/internal/database"
+	"rizon-backend/internal/dataexport"
+	"rizon-backend/internal/experiments"
+	"rizon-backend/internal/flags"
+	"rizon-backend/internal/geo"
 	"rizon-backend/internal/handlers"
+	"rizon-backend/internal/issues"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/jwtkeys"
+	"rizon-backend/internal/lifecycle"
+	"rizon-backend/internal/lock"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/maintenance"
+	"rizon-backend/internal/metering"
 	customMiddleware "rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/oidc"
+	"rizon-backend/internal/onboarding"
+	"rizon-backend/internal/presence"
+	"rizon-backend/internal/ratelimit"
+	"rizon-backend/internal/realtime"
+	"rizon-backend/internal/recording"
+	"rizon-backend/internal/redact"
 	"rizon-backend/internal/repository"
+	"rizon-backend/internal/roadmap"
+	"rizon-backend/internal/rollup"
+	"rizon-backend/internal/scheduler"
+	"rizon-backend/internal/sentry"
+	"rizon-backend/internal/service"
+	"rizon-backend/internal/sessionpolicy"
+	"rizon-backend/internal/signedurl"
 	"rizon-backend/internal/slack"
+	"rizon-backend/internal/userimport"
+	"rizon-backend/internal/virusscan"
+	"rizon-backend/internal/webauthn"
+	"rizon-backend/internal/winback"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 func main() {
@@ -28,6 +81,14 @@ func main() {
 	dbName := getEnv("DB_NAME", "rizon")
 	jwtSecret := getEnv("JWT_SECRET", "")
 	port := getEnv("PORT", "8080")
+	adminAPIKey := getEnv("ADMIN_API_KEY", "")
+	appEnv := getEnv("APP_ENV", "production")
+	drainGrace := getEnvSeconds("DRAIN_GRACE_SECONDS", 25*time.Second)
+	drainDelay := getEnvSeconds("DRAIN_DELAY_SECONDS", 5*time.Second)
+	changeStreamsEnabled := getEnv("CHANGE_STREAMS_ENABLED", "false") == "true"
+	requestSigningSecret := getEnv("REQUEST_SIGNING_SECRET", "")
+	requestSigningEnforce := getEnv("REQUEST_SIGNING_ENFORCE", "false") == "true"
+	attestationRequired := getEnv("ATTESTATION_REQUIRED", "false") == "true"
 
 	if mongoURI == "" {
 		log.Fatal("❌ MONGODB_URI is required")
@@ -35,82 +96,1024 @@ func main() {
 	if jwtSecret == "" {
 		log.Fatal("❌ JWT_SECRET is required")
 	}
+	// Secrets still accepted while rotating JWT_SECRET (see package jwtkeys)
+	acceptedSecrets := getEnvList("JWT_ACCEPTED_SECRETS", nil)
+	jwtKeys, err := jwtkeys.New(jwtSecret, acceptedSecrets)
+	if err != nil {
+		log.Fatalf("❌ Invalid JWT configuration: %v", err)
+	}
+	if adminAPIKey == "" {
+		log.Println("⚠️  ADMIN_API_KEY not set, admin routes are disabled")
+	}
+
+	// Mask emails/phones/tokens in logs and Slack payloads (disable only for local debugging)
+	redact.SetEnabled(getEnv("REDACT_PII", "true") == "true")
 
 	// Connect to MongoDB
+	database.SlowQueryThreshold = getEnvMillis("MONGO_SLOW_QUERY_MS", database.SlowQueryThreshold)
 	if err := database.Connect(mongoURI, dbName); err != nil {
 		log.Fatalf("❌ Failed to connect to MongoDB: %v", err)
 	}
+	// Optional second cluster for analytics/event collections
+	if uri := getEnv("SECONDARY_MONGODB_URI", ""); uri != "" {
+		collections := getEnvList("SECONDARY_COLLECTIONS", database.DefaultSecondaryCollections)
+		if err := database.ConnectSecondary(uri, getEnv("SECONDARY_DB_NAME", dbName), collections); err != nil {
+			log.Fatalf("❌ Failed to connect to secondary MongoDB: %v", err)
+		}
+	}
+	// Data residency regions for organizations' feedback, e.g. DATA_REGIONS=eu
+	// with REGION_EU_MONGODB_URI, or REGION_EU_COLLECTION_PREFIX for
+	// prefixed collections in the primary database
+	for _, region := range getEnvList("DATA_REGIONS", nil) {
+		key := "REGION_" + strings.ToUpper(region) + "_"
+		if err := database.ConnectRegion(region, getEnv(key+"MONGODB_URI", ""), getEnv(key+"DB_NAME", dbName), getEnv(key+"COLLECTION_PREFIX", region+"_")); err != nil {
+			log.Fatalf("❌ Failed to connect to MongoDB for region %s: %v", region, err)
+		}
+	}
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepo()
 	tokenRepo := repository.NewAuthTokenRepo()
+	refreshTokenRepo := repository.NewRefreshTokenRepo()
+	revokedTokenRepo := repository.NewRevokedTokenRepo()
+	credentialRepo := repository.NewCredentialRepo()
 	feedbackRepo := repository.NewFeedbackRepo()
+	usageRepo := repository.NewUsageRepo()
+	recordedRequestRepo := repository.NewRecordedRequestRepo()
+	quotaRepo := repository.NewQuotaRepo()
+	resumeTokenRepo := repository.NewResumeTokenRepo()
+	nonceRepo := repository.NewNonceRepo()
+	attestationRepo := repository.NewAttestationRepo()
+	adminKeyRepo := repository.NewAdminKeyRepo()
+	loginLinkRepo := repository.NewLoginLinkRepo()
+	dataKeyRepo := repository.NewDataKeyRepo()
+	featureFlagRepo := repository.NewFeatureFlagRepo()
+	documentSchemaRepo := repository.NewDocumentSchemaRepo()
+	experimentRepo := repository.NewExperimentRepo()
+	eventRepo := repository.NewEventRepo()
+	audienceRepo := repository.NewAudienceRepo()
+	metricsRollupRepo := repository.NewMetricsRollupRepo()
+	sessionPolicyRepo := repository.NewSessionPolicyRepo()
+	consentRepo := repository.NewConsentRepo()
+	abuseRepo := repository.NewAbuseRepo()
+	incidentRepo := repository.NewIncidentRepo()
+	adminNoteRepo := repository.NewAdminNoteRepo()
+	auditRepo := repository.NewAuditRepo()
+	exportRepo := repository.NewExportRepo()
+	attachmentRepo := repository.NewAttachmentRepo()
+	quarantineRepo := repository.NewQuarantineRepo()
+	orgRepo := repository.NewOrganizationRepo()
+	orgUsageRepo := repository.NewOrgUsageRepo()
+	feedbackPromptRepo := repository.NewFeedbackPromptRepo()
+	deviceRepo := repository.NewDeviceRepo()
+	emailSuppressionRepo := repository.NewEmailSuppressionRepo()
+	onboardingReminderRepo := repository.NewOnboardingReminderRepo()
+	winbackRepo := repository.NewWinbackRepo()
+	roadmapRepo := repository.NewRoadmapRepo()
+	adminNotificationRepo := repository.NewAdminNotificationRepo()
+	issueDeliveryRepo := repository.NewIssueDeliveryRepo()
+
+	// The app fetches its status on every foreground; cache it briefly and
+	// drop it whenever anything it reflects changes.
+	statusCache := customMiddleware.NewResponseCache("user_status", getEnvSeconds("USER_STATUS_CACHE_SECONDS", 30*time.Second))
+	invalidateStatus := func(id bson.ObjectID) { statusCache.Invalidate(id.Hex()) }
+	userRepo.OnChange(invalidateStatus)
+	orgRepo.OnMembershipChange(invalidateStatus)
+
+	// Envelope encryption of sensitive fields (enabled when master keys are configured)
+	var envelope *crypto.Envelope
+	if keys := getEnv("ENCRYPTION_KEYS", ""); keys != "" {
+		keyring, err := crypto.ParseKeyring(keys, getEnv("ENCRYPTION_ACTIVE_KEY", "v1"))
+		if err != nil {
+			log.Fatalf("❌ Invalid encryption configuration: %v", err)
+		}
+		envelope = crypto.NewEnvelope(keyring, dataKeyRepo)
+		feedbackRepo.WithEncryption(envelope)
+		orgRepo.WithEncryption(envelope)
+	}
+
+	// Cross-replica coordination
+	locker := lock.NewLocker()
+	limiter := ratelimit.NewLimiter()
+	queue := jobs.NewQueue()
 
 	// Ensure indexes
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := userRepo.EnsureIndexes(ctx); err != nil {
-		log.Printf("⚠️  Warning: failed to create user indexes: %v", err)
+	indexes := []maintenance.Index{
+		{Name: "user", Ensure: userRepo.EnsureIndexes},
+		{Name: "token", Ensure: tokenRepo.EnsureIndexes},
+		{Name: "refresh token", Ensure: refreshTokenRepo.EnsureIndexes},
+		{Name: "revoked token", Ensure: revokedTokenRepo.EnsureIndexes},
+		{Name: "credential", Ensure: credentialRepo.EnsureIndexes},
+		{Name: "feedback", Ensure: feedbackRepo.EnsureIndexes},
+		{Name: "usage", Ensure: usageRepo.EnsureIndexes},
+		{Name: "recorded request", Ensure: recordedRequestRepo.EnsureIndexes},
+		{Name: "experiment", Ensure: experimentRepo.EnsureIndexes},
+		{Name: "event", Ensure: eventRepo.EnsureIndexes},
+		{Name: "audience", Ensure: audienceRepo.EnsureIndexes},
+		{Name: "metrics rollup", Ensure: metricsRollupRepo.EnsureIndexes},
+		{Name: "quota", Ensure: quotaRepo.EnsureIndexes},
+		{Name: "nonce", Ensure: nonceRepo.EnsureIndexes},
+		{Name: "attestation", Ensure: attestationRepo.EnsureIndexes},
+		{Name: "admin key", Ensure: adminKeyRepo.EnsureIndexes},
+		{Name: "login link", Ensure: loginLinkRepo.EnsureIndexes},
+		{Name: "data key", Ensure: dataKeyRepo.EnsureIndexes},
+		{Name: "feature flag", Ensure: featureFlagRepo.EnsureIndexes},
+		{Name: "session policy", Ensure: sessionPolicyRepo.EnsureIndexes},
+		{Name: "consent", Ensure: consentRepo.EnsureIndexes},
+		{Name: "abuse", Ensure: abuseRepo.EnsureIndexes},
+		{Name: "lock", Ensure: locker.EnsureIndexes},
+		{Name: "rate limit", Ensure: limiter.EnsureIndexes},
+		{Name: "incident", Ensure: incidentRepo.EnsureIndexes},
+		{Name: "admin note", Ensure: adminNoteRepo.EnsureIndexes},
+		{Name: "audit log", Ensure: auditRepo.EnsureIndexes},
+		{Name: "export", Ensure: exportRepo.EnsureIndexes},
+		{Name: "device", Ensure: deviceRepo.EnsureIndexes},
+		{Name: "attachment", Ensure: attachmentRepo.EnsureIndexes},
+		{Name: "quarantine", Ensure: quarantineRepo.EnsureIndexes},
+		{Name: "organization", Ensure: orgRepo.EnsureIndexes},
+		{Name: "org usage", Ensure: orgUsageRepo.EnsureIndexes},
+		{Name: "email suppression", Ensure: emailSuppressionRepo.EnsureIndexes},
+		{Name: "onboarding reminder", Ensure: onboardingReminderRepo.EnsureIndexes},
+		{Name: "winback", Ensure: winbackRepo.EnsureIndexes},
+		{Name: "roadmap", Ensure: roadmapRepo.EnsureIndexes},
+		{Name: "admin notification", Ensure: adminNotificationRepo.EnsureIndexes},
+		{Name: "issue delivery", Ensure: issueDeliveryRepo.EnsureIndexes},
+		{Name: "job", Ensure: queue.EnsureIndexes},
 	}
-	if err := tokenRepo.EnsureIndexes(ctx); err != nil {
-		log.Printf("⚠️  Warning: failed to create token indexes: %v", err)
+	for _, region := range database.Regions() {
+		indexes = append(indexes, maintenance.Index{Name: "feedback (" + region + ")", Ensure: func(ctx context.Context) error {
+			return feedbackRepo.EnsureIndexes(database.WithRegion(ctx, region))
+		}})
+	}
+	for _, index := range indexes {
+		if err := index.Ensure(ctx); err != nil {
+			log.Printf("⚠️  Warning: failed to create %s indexes: %v", index.Name, err)
+		}
+	}
+
+	// Background workers run until shutdown
+	appCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	// Start usage metering (aggregated in memory, flushed to Mongo periodically)
+	meter := metering.NewMeter(usageRepo, quotaRepo)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		meter.Run(appCtx, 10*time.Second)
+	}()
+
+	// Opt-in capture of anonymized staging traffic for cmd/replay
+	var requestRecorder *recording.Recorder
+	if percent := getEnvInt("REQUEST_RECORDING_PERCENT", 0); percent > 0 {
+		if appEnv == "production" || appEnv == "prod" {
+			log.Println("⚠️  REQUEST_RECORDING_PERCENT is ignored in production")
+		} else {
+			requestRecorder, err = recording.NewRecorder(recordedRequestRepo, recording.Config{
+				SampleRate:   float64(percent) / 100,
+				Retention:    time.Duration(getEnvInt("REQUEST_RECORDING_RETENTION_DAYS", 7)) * 24 * time.Hour,
+				SkipPrefixes: recording.DefaultSkipPrefixes,
+			})
+			if err != nil {
+				log.Fatalf("❌ Failed to start request recording: %v", err)
+			}
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				requestRecorder.Run(appCtx, 5*time.Second)
+			}()
+			log.Printf("🎙️  Recording %d%% of requests for replay", percent)
+		}
+	}
+
+	// Last-seen tracking per user and device (throttled to one write per flush)
+	presenceTracker := presence.NewTracker(deviceRepo, userRepo)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		presenceTracker.Run(appCtx, time.Minute)
+	}()
+
+	// Feature flags (cached in memory, refreshed from Mongo)
+	flagStore := flags.NewStore(featureFlagRepo)
+	if err := flagStore.Refresh(ctx); err != nil {
+		log.Printf("⚠️  Warning: failed to load feature flags: %v", err)
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		flagStore.Run(appCtx, 30*time.Second)
+	}()
+
+	// A/B experiments (cached like flags; exposures flushed in batches)
+	experimentEngine := experiments.NewEngine(experimentRepo, eventRepo, flagStore)
+	if err := experimentEngine.Refresh(ctx); err != nil {
+		log.Printf("⚠️  Warning: failed to load experiments: %v", err)
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		experimentEngine.Run(appCtx, 30*time.Second)
+	}()
+
+	// Session lifetime policies (how long a refresh token lasts): cohorts opt
+	// in via "jwt-policy:<name>" feature flags
+	sessionPolicies, err := sessionpolicy.Parse(getEnv("JWT_LIFETIME_POLICIES", ""))
+	if err != nil {
+		log.Fatalf("❌ Invalid JWT_LIFETIME_POLICIES: %v", err)
+	}
+	sessions := sessionpolicy.NewSelector(getEnvSeconds("JWT_LIFETIME_SECONDS", 30*24*time.Hour), sessionPolicies, flagStore, sessionPolicyRepo)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		sessions.Run(appCtx, 10*time.Second)
+	}()
+
+	// Initialize Slack notifier (Web API when configured, mock otherwise)
+	var notifier slack.Notifier = slack.NewMockSlack()
+	var slackQueue *slack.Queue
+	if token, channel := getEnv("SLACK_BOT_TOKEN", ""), getEnv("SLACK_CHANNEL_ID", ""); token != "" && channel != "" {
+		// Bursts and 429s are held and posted as one summary
+		slackQueue = slack.NewQueue(slack.NewClient(token, channel), slack.QueueConfig{
+			Burst:    int(getEnvInt("SLACK_BURST", int64(slack.DefaultQueueConfig.Burst))),
+			Window:   getEnvSeconds("SLACK_BURST_WINDOW_SECONDS", slack.DefaultQueueConfig.Window),
+			Capacity: int(getEnvInt("SLACK_QUEUE_CAPACITY", int64(slack.DefaultQueueConfig.Capacity))),
+		})
+		notifier = slackQueue
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			slackQueue.Run(appCtx, time.Second)
+		}()
+	}
+
+	// Dashboard notification center. Undelivered Slack messages and failed
+	// jobs land there too.
+	adminFeed := adminfeed.New(adminNotificationRepo)
+	notifier = slack.SkipSandbox(adminFeed.WatchSlack(notifier))
+	queue.OnFailure(adminFeed.JobFailed)
+
+	// Scheduled jobs (run once per interval across all replicas)
+	sched := scheduler.New(locker)
+	sched.Every("usage-retention", 24*time.Hour, func(ctx context.Context, now time.Time) error {
+		cutoff := now.UTC().AddDate(0, 0, -90).Format("2006-01-02")
+		deleted, err := usageRepo.DeleteBefore(ctx, cutoff)
+		if err == nil && deleted > 0 {
+			log.Printf("🧹 Removed %d usage records older than %s", deleted, cutoff)
+		}
+		return err
+	})
+	// Last month's organization usage reports, for invoicing
+	orgUsageService := service.NewOrgUsageService(orgRepo, usageRepo, orgUsageRepo, notifier)
+	sched.Every("org-usage-summary", 6*time.Hour, func(ctx context.Context, now time.Time) error {
+		made, err := orgUsageService.SummarizeLastMonth(ctx, now)
+		if made > 0 {
+			log.Printf("🧾 Saved %d organization usage reports", made)
+		}
+		return err
+	})
+	// Reminders 24 and 72 hours after signup for users who haven't onboarded
+	onboardingReminders := onboarding.NewReminders(userRepo, onboardingReminderRepo, emailSuppressionRepo, queue)
+	sched.Every("onboarding-reminders", time.Hour, func(ctx context.Context, now time.Time) error {
+		queued, err := onboardingReminders.Run(ctx, now)
+		if queued > 0 {
+			log.Printf("👋 Queued %d onboarding reminders", queued)
+		}
+		return err
+	})
+	// Admin-configured email to users who stopped coming back
+	winbackRunner := winback.NewRunner(winbackRepo, userRepo, audienceRepo, emailSuppressionRepo, queue)
+	sched.Every("winback", time.Hour, func(ctx context.Context, now time.Time) error {
+		queued, reactivated, err := winbackRunner.Run(ctx, now)
+		if queued > 0 || reactivated > 0 {
+			log.Printf("📬 Queued %d winback emails; %d users came back", queued, reactivated)
+		}
+		return err
+	})
+	sched.Every("auto-unban", time.Minute, func(ctx context.Context, now time.Time) error {
+		lifted, err := userRepo.LiftExpiredRestrictions(ctx, now)
+		if err == nil && lifted > 0 {
+			log.Printf("🔓 Lifted %d expired suspensions/bans", lifted)
+		}
+		return err
+	})
+	// Hourly/daily aggregates behind the admin analytics endpoints
+	roller := rollup.NewRoller(metricsRollupRepo,
+		rollup.Source{
+			Metric:      models.MetricLoginLinks,
+			Granularity: models.RollupHourly,
+			Settle:      30 * time.Minute, // links can be clicked for 15 minutes after sending
+			Compute:     loginLinkRepo.Rollup,
+		},
+		rollup.Source{
+			Metric:      models.MetricSignups,
+			Granularity: models.RollupDaily,
+			Settle:      time.Minute,
+			Compute:     userRepo.SignupRollup,
+		},
+		rollup.Source{
+			Metric:      models.MetricOnboardingReminders,
+			Granularity: models.RollupDaily,
+			Settle:      time.Minute,
+			Compute:     onboardingReminderRepo.Rollup,
+		},
+		rollup.Source{
+			Metric:      models.MetricWinback,
+			Granularity: models.RollupDaily,
+			Settle:      2 * time.Hour, // reactivations are marked hourly
+			Compute:     winbackRepo.Rollup,
+		},
+	)
+	sched.Every("metrics-rollup", 15*time.Minute, func(ctx context.Context, now time.Time) error {
+		return roller.Run(ctx, now)
+	})
+	sched.Every("attachment-gc", time.Hour, func(ctx context.Context, now time.Time) error {
+		// Blobs touched in the last hour may belong to an upload in progress
+		deleted, err := attachmentRepo.DeleteOrphanBlobs(ctx, now.Add(-time.Hour))
+		if err == nil && deleted > 0 {
+			log.Printf("🧹 Removed %d unreferenced attachment blobs", deleted)
+		}
+		return err
+	})
+	if envelope != nil {
+		// Re-wrap data keys after ENCRYPTION_ACTIVE_KEY changes
+		sched.Every("data-key-rotation", time.Hour, func(ctx context.Context, _ time.Time) error {
+			total := 0
+			for {
+				rotated, err := envelope.RotateMasterKey(ctx, 100)
+				total += rotated
+				if err != nil {
+					return err
+				}
+				if rotated == 0 {
+					break
+				}
+			}
+			if total > 0 {
+				log.Printf("🔐 Re-wrapped %d data keys with the active master key", total)
+			}
+			return nil
+		})
 	}
-	if err := feedbackRepo.EnsureIndexes(ctx); err != nil {
-		log.Printf("⚠️  Warning: failed to create feedback indexes: %v", err)
+	var backupStore *backup.S3Store
+	if bucket := getEnv("BACKUP_S3_BUCKET", ""); bucket != "" {
+		store := backup.NewS3Store(
+			getEnv("BACKUP_S3_ENDPOINT", ""),
+			getEnv("BACKUP_S3_REGION", "us-east-1"),
+			bucket,
+			getEnv("AWS_ACCESS_KEY_ID", ""),
+			getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		)
+		backupStore = store
+		prefix := getEnv("BACKUP_S3_PREFIX", "backups")
+		sched.Every("backup", getEnvSeconds("BACKUP_INTERVAL_SECONDS", 24*time.Hour), func(ctx context.Context, now time.Time) error {
+			var buf bytes.Buffer
+			stats, err := backup.Dump(ctx, database.GetCollection, backup.DefaultCollections, &buf)
+			if err != nil {
+				return err
+			}
+			key := backup.ObjectKey(prefix, now)
+			if err := store.Put(ctx, key, buf.Bytes()); err != nil {
+				return err
+			}
+			log.Printf("☁️  Backed up %d collections (%d bytes) to s3://%s/%s", len(stats), buf.Len(), bucket, key)
+			return nil
+		})
 	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		sched.Run(appCtx)
+	}()
 
-	// Initialize Slack notifier (mock)
-	notifier := slack.NewMockSlack()
+	// Connection draining for zero-downtime deploys
+	drainer := lifecycle.NewDrainer()
+
+	// Realtime events, fed by Mongo change streams so all replicas see every write
+	hub := realtime.NewHub()
+	if changeStreamsEnabled {
+		watcher := changestream.NewWatcher(resumeTokenRepo)
+		watcher.Subscribe(func(e changestream.Event) {
+			hub.Publish(realtime.Event{
+				Type: e.Collection + "." + e.Operation,
+				Data: map[string]interface{}{
+					"id":       e.DocumentID,
+					"document": e.Document,
+				},
+			})
+		})
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			watcher.Run(appCtx, "feedbacks", "announcements", "notifications")
+		}()
+	} else {
+		adminFeed.WithHub(hub)
+	}
+
+	// Email providers in failover order: Resend, then SMTP (e.g. SES)
+	var emailProviders []mailer.Provider
+	if apiKey := getEnv("RESEND_API_KEY", ""); apiKey != "" {
+		emailProviders = append(emailProviders, mailer.NewResend(apiKey))
+	}
+	if host := getEnv("SMTP_HOST", ""); host != "" {
+		emailProviders = append(emailProviders, mailer.NewSMTP(getEnv("SMTP_PROVIDER_NAME", "smtp"), host, getEnv("SMTP_PORT", "587"), getEnv("SMTP_USERNAME", ""), getEnv("SMTP_PASSWORD", "")))
+	}
+	// In development, emails with no provider to send them land in the
+	// /dev/mailbox page instead of only being logged
+	var devMailbox *mailer.Mailbox
+	if len(emailProviders) == 0 && isDev(appEnv) {
+		devMailbox = mailer.NewMailbox(int(getEnvInt("DEV_MAILBOX_SIZE", 50)))
+		emailProviders = append(emailProviders, devMailbox)
+	}
+	mailConfig := mailer.DefaultConfig
+	mailConfig.Cooldown = getEnvSeconds("EMAIL_BREAKER_COOLDOWN_SECONDS", mailConfig.Cooldown)
+	mailConfig.Timeout = getEnvSeconds("EMAIL_SEND_TIMEOUT_SECONDS", mailConfig.Timeout)
+	mail := mailer.New(getEnv("FROM_EMAIL", ""), emailProviders, notifier, mailConfig)
+
+	// Queued (non-transactional) email limits; admins override the default
+	// at /admin/email/throttle. Resend allows about 2 requests a second.
+	emailThrottleRepo := repository.NewEmailThrottleRepo()
+	emailThrottle := bulkmail.NewThrottle(emailThrottleRepo, limiter, models.EmailThrottle{
+		MaxPerMinute: map[string]int{"*": int(getEnvInt("EMAIL_MAX_PER_MINUTE", 100))},
+		QuietHours:   []models.QuietHours{},
+	})
+	if err := emailThrottle.Refresh(ctx); err != nil {
+		log.Printf("⚠️  Warning: failed to load email throttle: %v", err)
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		emailThrottle.Run(appCtx, 30*time.Second)
+	}()
+
+	// Personal data exports, delivered by email
+	baseURL := getEnv("BASE_URL", "http://localhost:"+port)
+	// Download links are signed with JWT_SECRET too, so they rotate with it
+	signer := signedurl.New(jwtSecret, nonceRepo).WithAccepted(acceptedSecrets)
+	exporter := dataexport.NewExporter(userRepo, feedbackRepo, consentRepo, exportRepo, mail, signer, baseURL)
+
+	// Background job queue (shared by all replicas)
+	queue.Register(userimport.JobType, userimport.Handler(userRepo))
+	queue.Register(dataexport.JobType, exporter.Handle)
+	queue.Register(bulkmail.JobType, bulkmail.Handler(mail, emailThrottle, emailSuppressionRepo))
+	queue.RegisterLongRunning(campaign.JobType, campaign.Handler(userRepo, queue), time.Hour)
+	queue.RegisterLongRunning(roadmap.JobType, roadmap.Handler(roadmapRepo, userRepo, queue), time.Hour)
+	maintenanceTasks := maintenance.NewRegistry(maintenance.RebuildIndexes(indexes))
+	for _, b := range backfill.All {
+		maintenanceTasks.Add(maintenance.Backfill(b))
+	}
+	queue.RegisterLongRunning(maintenance.JobType, maintenanceTasks.Handle, 2*time.Hour)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		queue.Run(appCtx, 5*time.Second)
+	}()
+
+	// Temporary IP blocks for sign-up spam and token guessing
+	abuseConfig := abuse.DefaultConfig
+	abuseConfig.BlockDuration = getEnvSeconds("ABUSE_BLOCK_SECONDS", abuseConfig.BlockDuration)
+	abuseDetector := abuse.NewDetector(abuseRepo, notifier, abuseConfig).WithAdminFeed(adminFeed)
+
+	// Issue trackers for promoting feedback (each is optional)
+	var issueTrackers []issues.Tracker
+	if apiKey, team := getEnv("LINEAR_API_KEY", ""), getEnv("LINEAR_TEAM_ID", ""); apiKey != "" && team != "" {
+		issueTrackers = append(issueTrackers, issues.NewLinear(apiKey, team))
+	}
+	if base, project := getEnv("JIRA_BASE_URL", ""), getEnv("JIRA_PROJECT_KEY", ""); base != "" && project != "" {
+		issueTrackers = append(issueTrackers, issues.NewJira(base, getEnv("JIRA_EMAIL", ""), getEnv("JIRA_API_TOKEN", ""), project, getEnv("JIRA_ISSUE_TYPE", "Bug")))
+	}
+
+	// App attestation verifiers (each platform is optional)
+	var playIntegrity *attestation.PlayIntegrityVerifier
+	if pkg, sa := getEnv("PLAY_INTEGRITY_PACKAGE_NAME", ""), getEnv("GOOGLE_SERVICE_ACCOUNT_JSON", ""); pkg != "" && sa != "" {
+		v, err := attestation.NewPlayIntegrityVerifier(pkg, sa)
+		if err != nil {
+			log.Fatalf("❌ Invalid Play Integrity configuration: %v", err)
+		}
+		playIntegrity = v
+	}
+	var appAttest *attestation.AppAttestVerifier
+	if appID := getEnv("APP_ATTEST_APP_ID", ""); appID != "" {
+		v, err := attestation.NewAppAttestVerifier(appID, getEnv("APP_ATTEST_ENV", "production") == "development")
+		if err != nil {
+			log.Fatalf("❌ Invalid App Attest configuration: %v", err)
+		}
+		appAttest = v
+	}
+
+	// Passkey sign-in (optional); the RP ID must be the domain serving the
+	// app's associated domains / asset links files
+	var passkeyService *service.PasskeyService
+	if rpID := getEnv("PASSKEY_RP_ID", ""); rpID != "" {
+		rp, err := webauthn.New(webauthn.Config{
+			RPID:    rpID,
+			RPName:  getEnv("PASSKEY_RP_NAME", "Rizon"),
+			Origins: getEnvList("PASSKEY_ORIGINS", nil),
+		})
+		if err != nil {
+			log.Fatalf("❌ Invalid passkey configuration: %v", err)
+		}
+		passkeyService = service.NewPasskeyService(credentialRepo, rp)
+	}
+
+	// Bump these when the legal documents change; users are prompted to re-consent
+	legalVersions := models.LegalVersions{
+		Terms:   getEnv("TERMS_VERSION", "1"),
+		Privacy: getEnv("PRIVACY_VERSION", "1"),
+	}
+
+	// Minimum age per region (COPPA/GDPR); under-age accounts are blocked
+	ageRules, err := agegate.ParseRules(getEnv("AGE_MINIMUMS", "default=13"))
+	if err != nil {
+		log.Fatalf("❌ Invalid AGE_MINIMUMS: %v", err)
+	}
+
+	// Initialize services
+	oidcClient := oidc.New()
+	authService := service.NewAuthService(tokenRepo, loginLinkRepo, consentRepo, refreshTokenRepo, revokedTokenRepo, limiter, sessions, jwtKeys).
+		WithSingleActiveLink(getEnv("LOGIN_LINK_SINGLE_ACTIVE", "true") == "true").
+		WithLoginCodeSecret(getEnv("LOGIN_CODE_SECRET", jwtSecret)).
+		WithAccessTokenLifetime(getEnvSeconds("JWT_ACCESS_TOKEN_SECONDS", service.DefaultAccessTokenTTL)).
+		WithGoogleSignIn(oidcClient, getEnvList("GOOGLE_CLIENT_IDS", nil))
+	schemaService := service.NewSchemaService(documentSchemaRepo)
+	audienceService := service.NewAudienceService(audienceRepo, userRepo)
+	userService := service.NewUserService(userRepo, ageRules, getEnv("AGE_GATE_REQUIRED", "false") == "true").WithSchemas(schemaService).WithOnboardingReminders(onboardingReminderRepo)
+	feedbackService := service.NewFeedbackService(feedbackRepo).WithAdminFeed(adminFeed)
+	orgService := service.NewOrgService(orgRepo, userRepo, mail)
+	ssoService := service.NewSSOService(orgRepo, userService, authService, oidcClient, signer)
+	scimService := service.NewSCIMService(orgRepo, userService)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(tokenRepo, userRepo, jwtSecret)
-	feedbackHandler := handlers.NewFeedbackHandler(feedbackRepo, notifier)
-	userHandler := handlers.NewUserHandler(userRepo)
+	authHandler := handlers.NewAuthHandler(authService, userService, loginLinkRepo, mail, legalVersions, handlers.AppLinks{
+		IOSStoreURL:     getEnv("IOS_APP_STORE_URL", ""),
+		AndroidStoreURL: getEnv("ANDROID_PLAY_STORE_URL", ""),
+		WebLoginURL:     getEnv("WEB_LOGIN_URL", ""),
+	}).WithAbuseDetection(abuseDetector).WithGeo(geo.HeaderResolver{}).WithOrganizations(orgService).WithSSO(ssoService).WithAudit(auditRepo).WithPasskeys(passkeyService)
+	// Lets E2E suites sign in without email; refused in production
+	if secret := getEnv("E2E_TEST_SECRET", ""); secret != "" {
+		if appEnv == "production" || appEnv == "prod" {
+			log.Println("⚠️  E2E_TEST_SECRET is ignored in production")
+		} else {
+			authHandler.WithTestLogin(secret)
+			log.Printf("🧪 Test login enabled at /auth/test-login (%s)", appEnv)
+		}
+	}
+	// Captcha for the public feedback form; the form is disabled without it
+	var captchaVerifier *captcha.Verifier
+	if secret := getEnv("CAPTCHA_SECRET", ""); secret != "" {
+		captchaVerifier, err = captcha.New(getEnv("CAPTCHA_PROVIDER", "turnstile"), secret)
+		if err != nil {
+			log.Fatalf("❌ Invalid CAPTCHA_PROVIDER: %v", err)
+		}
+	}
+	feedbackHandler := handlers.NewFeedbackHandler(feedbackService, feedbackRepo, notifier).
+		WithIssueTrackers(issueTrackers, getEnv("FEEDBACK_AUTO_ISSUE_PROVIDER", ""), issueDeliveryRepo).
+		WithDashboardURL(getEnv("DASHBOARD_URL", "")).
+		WithPublicForm(captchaVerifier, limiter).
+		WithOrganizations(orgService)
+	userHandler := handlers.NewUserHandler(userService, userRepo)
+	usageHandler := handlers.NewUsageHandler(usageRepo, quotaRepo)
+	healthHandler := handlers.NewHealthHandler(appEnv, drainer, drainGrace)
+	eventsHandler := handlers.NewEventsHandler(hub, drainer)
+	notificationHandler := handlers.NewNotificationHandler(mail)
+	adminNotificationHandler := handlers.NewAdminNotificationHandler(adminNotificationRepo)
+	emailThrottleHandler := handlers.NewEmailThrottleHandler(emailThrottleRepo, emailThrottle)
+	emailSuppressionHandler := handlers.NewEmailSuppressionHandler(emailSuppressionRepo)
+	organizationHandler := handlers.NewOrganizationHandler(orgRepo)
+	orgMemberHandler := handlers.NewOrgMemberHandler(orgService, userRepo, feedbackRepo)
+	ssoHandler := handlers.NewSSOHandler(ssoService)
+	scimHandler := handlers.NewSCIMHandler(scimService, orgRepo)
+	orgUsageHandler := handlers.NewOrgUsageHandler(orgUsageService, orgService)
+	adminKeyHandler := handlers.NewAdminKeyHandler(adminKeyRepo)
+	loginAnalyticsHandler := handlers.NewLoginAnalyticsHandler(roller)
+	featureFlagHandler := handlers.NewFeatureFlagHandler(featureFlagRepo, flagStore)
+	schemaHandler := handlers.NewSchemaHandler(schemaService)
+	experimentHandler := handlers.NewExperimentHandler(experimentEngine, experimentRepo, eventRepo)
+	audienceHandler := handlers.NewAudienceHandler(audienceService, queue)
+	sessionPolicyHandler := handlers.NewSessionPolicyHandler(sessionPolicyRepo, sessions)
+	integrationHandler := handlers.NewIntegrationHandler(feedbackRepo)
+	consentHandler := handlers.NewConsentHandler(consentRepo, legalVersions)
+	abuseHandler := handlers.NewAbuseHandler(abuseRepo, limiter)
+	signupAnalyticsHandler := handlers.NewSignupAnalyticsHandler(roller)
+	onboardingAnalyticsHandler := handlers.NewOnboardingAnalyticsHandler(roller)
+	winbackHandler := handlers.NewWinbackHandler(winbackRepo, audienceRepo, roller)
+	roadmapHandler := handlers.NewRoadmapHandler(roadmapRepo, feedbackRepo, queue)
+	sandboxHandler := handlers.NewSandboxHandler(feedbackRepo.EnsureIndexes, roadmapRepo.EnsureIndexes, roadmapRepo.SeedSandbox)
+	jobHandler := handlers.NewJobHandler(queue)
+	maintenanceHandler := handlers.NewMaintenanceHandler(queue, maintenanceTasks)
+	resilienceHandler := handlers.NewResilienceHandler().WithSlackQueue(slackQueue)
+	statusHandler := handlers.NewStatusHandler(incidentRepo, mail)
+	slackCommandHandler := handlers.NewSlackCommandHandler(userRepo, feedbackRepo)
+	adminNoteHandler := handlers.NewAdminNoteHandler(userRepo, adminNoteRepo)
+	exportHandler := handlers.NewExportHandler(exportRepo, queue, exporter)
+	activityHandler := handlers.NewActivityHandler(deviceRepo, usageRepo)
+	feedbackPromptHandler := handlers.NewFeedbackPromptHandler(feedbackPromptRepo, userRepo, feedbackRepo)
+	backupHandler := handlers.NewBackupHandler(backupStore, signer, baseURL)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentRepo, userRepo, signer, baseURL, getEnvInt("ATTACHMENT_QUOTA_BYTES", 100<<20))
+	// Virus scanning of uploads: "clamav" (clamd host:port) or "http" (scan API URL)
+	if provider := getEnv("VIRUS_SCANNER", ""); provider != "" {
+		scanner, err := virusscan.New(provider, getEnv("VIRUS_SCANNER_ADDR", "localhost:3310"), getEnv("VIRUS_SCANNER_API_KEY", ""))
+		if err != nil {
+			log.Fatalf("❌ Invalid VIRUS_SCANNER: %v", err)
+		}
+		attachmentHandler.WithScanner(scanner, quarantineRepo, getEnv("VIRUS_SCAN_FAIL_OPEN", "false") == "true")
+	}
+	downloadHandler := handlers.NewDownloadHandler(signer).
+		WithSource("exports", exportHandler.DownloadSource()).
+		WithSource("attachments", attachmentHandler.DownloadSource()).
+		WithSource("backups", backupHandler.DownloadSource())
+	userMergeHandler := handlers.NewUserMergeHandler(userRepo, feedbackRepo, consentRepo, adminNoteRepo, auditRepo)
+	userImportHandler := handlers.NewUserImportHandler(queue)
+	attestationHandler := handlers.NewAttestationHandler(attestationRepo, attestation.NewVerifier(playIntegrity, appAttest))
+
+	// Panic reporting; disabled without a DSN
+	var sentryClient *sentry.Client
+	if dsn := getEnv("SENTRY_DSN", ""); dsn != "" {
+		sentryClient, err = sentry.New(dsn, appEnv, buildinfo.Commit)
+		if err != nil {
+			log.Fatalf("❌ Invalid SENTRY_DSN: %v", err)
+		}
+	}
 
-	// Setup chi router
+	// Proxies allowed to set X-Forwarded-For / X-Real-IP
+	trustedProxies, err := customMiddleware.ParseCIDRs(getEnvList("TRUSTED_PROXY_CIDRS", customMiddleware.DefaultTrustedProxies))
+	if err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXY_CIDRS: %v", err)
+	}
+
+	// Setup chi router. Routes are mounted through the registry with their
+	// policy, so the policy table, /admin/routes and the OpenAPI skeleton
+	// come from the same declarations as the router.
 	r := chi.NewRouter()
+	// Slow queries answer 503 instead of leaving clients waiting; uploads get
+	// longer, streams and the drain hook none
+	routes := authz.NewRegistry().WithTimeout(getEnvSeconds("REQUEST_TIMEOUT_SECONDS", 15*time.Second))
+	uploadTimeout := getEnvSeconds("UPLOAD_TIMEOUT_SECONDS", 2*time.Minute)
+	api := routes.On(r)
+	routeHandler := handlers.NewRouteHandler(routes)
 
 	// Global middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.RealIP)
+	r.Use(customMiddleware.RealIP(trustedProxies))
 	r.Use(middleware.RequestID)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
+	r.Use(customMiddleware.Logger)
+	r.Use(customMiddleware.Recoverer(sentryClient))
+	r.Use(customMiddleware.APIVersion)
+	r.Use(customMiddleware.ReadYourWrites(getEnvSeconds("READ_YOUR_WRITES_WINDOW_SECONDS", 10*time.Second)))
+	r.Use(drainer.Middleware)
+	// Requests with a sandbox key use the sandbox collections. Only routes
+	// whose policy is sandboxed accept them.
+	r.Use(customMiddleware.Sandbox(adminKeyRepo))
+	if requestRecorder != nil {
+		r.Use(customMiddleware.RecordRequests(requestRecorder, recording.MaxBodyBytes))
+	}
+
+	// CORS per route group: the dashboard for admin routes, the marketing
+	// site for public ones, the web app for user routes. Integration and
+	// SCIM routes are server-to-server and send no CORS headers.
+	corsFor := func(origins []string, headers ...string) func(http.Handler) http.Handler {
+		return cors.Handler(cors.Options{
+			AllowedOrigins:   origins,
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders:   append([]string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Version", "X-Consistency-Token"}, headers...),
+			ExposedHeaders:   []string{"Link", "X-API-Version", "X-Consistency-Token", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-RateLimit-Tier", "Retry-After"},
+			AllowCredentials: true,
+			MaxAge:           300,
+		})
+	}
+	adminOrigins := []string{"*"}
+	if u, err := url.Parse(getEnv("DASHBOARD_URL", "")); err == nil && u.Host != "" {
+		adminOrigins = []string{u.Scheme + "://" + u.Host}
+	}
+	appHeaders := []string{"X-Signature", "X-Signature-Timestamp", "X-Signature-Nonce", "X-Attestation-Token", "X-App-Version", "X-App-Build", "X-OS", "X-OS-Version", "X-Device-Model", "X-Device-ID", "X-Sandbox-Key"}
+	r.Use(routes.Policies().CORS(r, map[authz.AuthType]func(http.Handler) http.Handler{
+		authz.Public: corsFor(getEnvList("CORS_PUBLIC_ORIGINS", []string{"*"}), appHeaders...),
+		authz.User:   corsFor(getEnvList("CORS_APP_ORIGINS", []string{"*"}), appHeaders...),
+		authz.Admin:  corsFor(getEnvList("CORS_ADMIN_ORIGINS", adminOrigins), "X-Admin-Key"),
 	}))
 
-	// Health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok","service":"rizon-backend"}`))
+	// Per-plan API rate limits for users and integration keys
+	rateLimitTiers, err := ratelimit.ParseTiers(getEnv("RATE_LIMIT_TIERS", ""))
+	if err != nil {
+		log.Fatalf("❌ Invalid RATE_LIMIT_TIERS: %v", err)
+	}
+	apiRateLimit := customMiddleware.RateLimit(limiter, rateLimitTiers, orgService)
+
+	// Authentication, permissions and entitlements for every route
+	authenticators := authz.Authenticators{
+		authz.User:        chi.Chain(customMiddleware.JWTAuth(jwtKeys, sessions, revokedTokenRepo), customMiddleware.AccountGuard(userRepo), apiRateLimit, customMiddleware.Presence(presenceTracker), customMiddleware.DataResidency(orgService)).Handler,
+		authz.Admin:       customMiddleware.AdminAuth(adminAPIKey, adminKeyRepo),
+		authz.Integration: chi.Chain(customMiddleware.IntegrationAuth(adminKeyRepo), apiRateLimit).Handler,
+		authz.SCIM:        customMiddleware.SCIMAuth(orgRepo),
+		authz.Sandbox:     customMiddleware.RequireSandbox,
+	}
+	r.Use(routes.Policies().Enforce(r, authenticators, flagStore))
+
+	// Health check and build metadata
+	api.Get("/health", healthHandler.Health, public)
+	api.Get("/version", healthHandler.Version, public)
+
+	// Response fixtures for client contract tests (development only)
+	fixtures := contracts.Handler(isDev(appEnv))
+	api.Get("/__fixtures__", fixtures, public)
+	api.Get("/__fixtures__/{version}/{name}", fixtures, public)
+	api.Get("/ready", healthHandler.Ready, public)
+	api.Get("/legal/versions", consentHandler.Versions, public)
+	api.Get("/status", statusHandler.Status, public)
+
+	// Emails caught in development (404 elsewhere)
+	devMailboxHandler := handlers.NewDevMailboxHandler(devMailbox)
+	api.Get("/dev/mailbox", devMailboxHandler.List, public)
+	api.Delete("/dev/mailbox", devMailboxHandler.Clear, public)
+	api.Get("/dev/mailbox/{id}", devMailboxHandler.Get, public)
+
+	// preStop hook
+	api.Timeout(0).Get("/internal/drain", healthHandler.Drain, admin(models.PermOpsWrite))
+	api.Timeout(0).Post("/internal/drain", healthHandler.Drain, admin(models.PermOpsWrite))
+
+	// Login (no auth required)
+	api.Group(func(r *authz.Router) {
+		// Optional HMAC signing by the mobile app (anti-abuse)
+		r.Use(customMiddleware.RequestSignature(customMiddleware.SignatureOptions{
+			Secret:  requestSigningSecret,
+			MaxSkew: 5 * time.Minute,
+			Enforce: requestSigningEnforce,
+			Nonces:  nonceRepo,
+		}))
+		r.Use(customMiddleware.BlockedIPs(abuseDetector))
+
+		r.RateLimited("login:email").With(customMiddleware.RequireAttestation(attestationRepo, attestationRequired)).Post("/auth/request", authHandler.RequestLogin, public)
+		r.Get("/auth/verify", authHandler.VerifyToken, public)
+		// The code from a login email requested with "code": true (5 tries per code)
+		r.RateLimited("login:code").Post("/auth/verify-code", authHandler.VerifyCode, public)
+		// Sign in with Google on the device (404 unless GOOGLE_CLIENT_IDS is set)
+		r.Post("/auth/google", authHandler.GoogleLogin, public)
+		// Sign in with a passkey added earlier (404 unless PASSKEY_RP_ID is set)
+		r.Post("/auth/passkey/options", authHandler.PasskeyLoginOptions, public)
+		r.Post("/auth/passkey", authHandler.PasskeyLogin, public)
+		// The access token may already have expired, so the refresh token is the credential
+		r.Post("/auth/refresh", authHandler.Refresh, public)
+		r.Get("/auth/attest/challenge", attestationHandler.Challenge, public)
+		r.Post("/auth/attest", attestationHandler.Attest, public)
+	})
+	// Opened from email clients, which can't sign requests
+	api.Get("/auth/redirect", authHandler.RedirectToApp, public)
+	// E2E test sign-in (404 unless E2E_TEST_SECRET is set outside production)
+	api.Post("/auth/test-login", authHandler.TestLogin, public)
+	// Organization single sign-on, run in the browser
+	api.With(customMiddleware.BlockedIPs(abuseDetector)).Get("/auth/sso/{org}/start", ssoHandler.Start, public)
+	api.With(customMiddleware.BlockedIPs(abuseDetector)).Get("/auth/sso/{org}/callback", ssoHandler.Callback, public)
+	// Web feedback form on the marketing site (captcha + rate limited)
+	api.RateLimited("public_feedback").With(customMiddleware.BlockedIPs(abuseDetector)).Post("/public/feedback", feedbackHandler.SubmitPublicFeedback, public)
+	// Signed links to exports, attachments and backups
+	api.Timeout(0).Get("/download/{token}", downloadHandler.Download, public)
+	// Wipes the caller's sandbox between integration test runs
+	api.Post("/sandbox/reset", sandboxHandler.Reset, sandbox)
+
+	// App user routes
+	api.Group(func(r *authz.Router) {
+		r.Use(customMiddleware.Metering(meter))
+
+		// Reachable while a terms update is pending, so the app can sign out or re-consent
+		r.Post("/auth/logout", authHandler.Logout, user)
+		r.Get("/user/consents", consentHandler.Get, user)
+		r.Post("/user/consents", consentHandler.Create, user)
+		r.Get("/user/export", exportHandler.Request, user)
+		r.Get("/user/export/status", exportHandler.Status, user)
+
+		r.Group(func(r *authz.Router) {
+			r.Use(customMiddleware.RequireTerms(consentRepo, getEnv("TERMS_REQUIRED_VERSION", "")))
+
+			r.With(customMiddleware.Quota(meter, "feedback")).Post("/feedback", feedbackHandler.SubmitFeedback, sandboxed(user))
+			r.Get("/feedback/follow-ups", feedbackHandler.ListFollowUps, sandboxed(user))
+			r.Post("/feedback/{id}/reaction", feedbackHandler.React, sandboxed(user))
+			r.Patch("/feedback/{id}", feedbackHandler.Edit, sandboxed(user))
+			r.Delete("/feedback/{id}", feedbackHandler.Delete, sandboxed(user))
+			r.Get("/roadmap", roadmapHandler.List, sandboxed(user))
+			r.Post("/roadmap/{id}/vote", roadmapHandler.Vote, sandboxed(user))
+			r.Get("/feedback/prompt", feedbackPromptHandler.Get, user)
+			r.Post("/feedback/prompt/events", feedbackPromptHandler.RecordEvent, user)
+			r.With(statusCache.Handler).Get("/user/status", userHandler.GetStatus, user)
+			r.Get("/config/experiments", experimentHandler.Config, user)
+			r.Patch("/user/onboarding", userHandler.CompleteOnboarding, user)
+			r.Patch("/user/profile", userHandler.UpdateProfile, user)
+			r.Patch("/user/preferences", userHandler.UpdatePreferences, user)
+			r.Post("/user/age", userHandler.SetAge, user)
+			r.Post("/user/passkeys/options", authHandler.PasskeyRegistrationOptions, user)
+			r.Post("/user/passkeys", authHandler.RegisterPasskey, user)
+			r.Get("/user/passkeys", authHandler.ListPasskeys, user)
+			r.Delete("/user/passkeys/{id}", authHandler.DeletePasskey, user)
+			r.Get("/user/usage", usageHandler.GetUsage, user)
+			r.Post("/user/heartbeat", activityHandler.Heartbeat, user)
+			r.Timeout(uploadTimeout).Post("/user/attachments", attachmentHandler.Upload, user)
+			r.Get("/user/attachments", attachmentHandler.List, user)
+			r.Delete("/user/attachments/{id}", attachmentHandler.Delete, user)
+			r.Get("/user/avatar", attachmentHandler.GetAvatar, user)
+			r.Timeout(uploadTimeout).Put("/user/avatar", attachmentHandler.SetAvatar, user)
+			r.Get("/user/orgs", orgMemberHandler.MyOrganizations, user)
+			r.Get("/user/org-invites", orgMemberHandler.MyInvites, user)
+			r.Post("/user/org-invites/{id}/accept", orgMemberHandler.AcceptInvite, user)
+			r.Get("/orgs/{id}/members", orgMemberHandler.ListMembers, user)
+			r.Post("/orgs/{id}/invites", orgMemberHandler.Invite, user)
+			r.Delete("/orgs/{id}/members/{userID}", orgMemberHandler.RemoveMember, user)
+			r.Get("/orgs/{id}/feedback", orgMemberHandler.ListFeedback, user)
+			r.Get("/orgs/{id}/usage", orgUsageHandler.Get, user)
+
+			// Dark-launched endpoints set an Entitlement (feature flag) in their policy
+		})
+	})
+
+	// Slack app callbacks (signed with SLACK_SIGNING_SECRET)
+	api.Route("/webhooks/slack", func(r *authz.Router) {
+		r.Use(customMiddleware.SlackSignature(getEnv("SLACK_SIGNING_SECRET", "")))
+
+		r.Post("/commands", slackCommandHandler.Command, public)
+		r.Post("/interactions", feedbackHandler.SlackInteraction, public)
+	})
+
+	// Polling triggers for Zapier/Make
+	api.Get("/integrations/feedback", integrationHandler.ListFeedback, integration(models.PermIntegrationsRead))
+
+	// SCIM 2.0 provisioning for organizations' identity providers
+	api.Route("/scim/v2", func(r *authz.Router) {
+		r.Get("/ServiceProviderConfig", scimHandler.ServiceProviderConfig, scim)
+		r.Get("/Users", scimHandler.ListUsers, scim)
+		r.Post("/Users", scimHandler.CreateUser, scim)
+		r.Get("/Users/{id}", scimHandler.GetUser, scim)
+		r.Put("/Users/{id}", scimHandler.ReplaceUser, scim)
+		r.Patch("/Users/{id}", scimHandler.PatchUser, scim)
+		r.Delete("/Users/{id}", scimHandler.DeleteUser, scim)
 	})
 
-	// Public routes (no auth required)
-	r.Post("/auth/request", authHandler.RequestLogin)
-	r.Get("/auth/verify", authHandler.VerifyToken)
-	r.Get("/auth/redirect", authHandler.RedirectToApp)
+	// Admin routes (permissions in each policy)
+	api.Route("/admin", func(r *authz.Router) {
+		r.Get("/quotas", usageHandler.ListQuotas, admin(models.PermQuotasRead))
+		r.Put("/quotas/{endpoint}", usageHandler.SetQuota, admin(models.PermQuotasWrite))
+		r.Delete("/quotas/{endpoint}", usageHandler.DeleteQuota, admin(models.PermQuotasWrite))
+
+		r.Timeout(0).Get("/events/stream", eventsHandler.Stream, admin(models.PermFeedbackRead))
+		r.Get("/notifications", adminNotificationHandler.List, admin(models.PermNotificationsRead))
+		r.Post("/notifications/read", adminNotificationHandler.MarkRead, admin(models.PermNotificationsRead))
+		r.Timeout(0).Get("/notifications/stream", eventsHandler.Notifications, admin(models.PermNotificationsRead))
+
+		r.Get("/feedback/stats", feedbackHandler.Stats, admin(models.PermFeedbackRead))
+		r.Get("/feedback/prompt-rules", feedbackPromptHandler.GetRules, admin(models.PermFeedbackRead))
+		r.Put("/feedback/prompt-rules", feedbackPromptHandler.SetRules, admin(models.PermFeedbackWrite))
+		r.Patch("/feedback/{id}/status", feedbackHandler.UpdateStatus, admin(models.PermFeedbackWrite))
+		r.Post("/feedback/{id}/issues", feedbackHandler.PromoteToIssue, admin(models.PermFeedbackWrite))
+		r.Get("/issue-deliveries", feedbackHandler.ListIssueDeliveries, admin(models.PermFeedbackRead))
+		r.Post("/issue-deliveries/{id}/redeliver", feedbackHandler.RedeliverIssue, admin(models.PermFeedbackWrite))
+		r.Post("/feedback/{id}/roadmap", roadmapHandler.Publish, admin(models.PermFeedbackWrite))
+		r.Delete("/roadmap/{id}", roadmapHandler.Unpublish, admin(models.PermFeedbackWrite))
+		r.Post("/roadmap/{id}/shipped", roadmapHandler.Ship, admin(models.PermFeedbackWrite))
+
+		r.Get("/notifications/templates", notificationHandler.ListTemplates, admin(models.PermNotificationsRead))
+		r.Get("/notifications/preview", notificationHandler.Preview, admin(models.PermNotificationsRead))
+		r.Post("/test-email", notificationHandler.TestEmail, admin(models.PermNotificationsWrite))
+		r.Get("/email/stats", notificationHandler.EmailStats, admin(models.PermNotificationsRead))
+		r.Get("/email/throttle", emailThrottleHandler.Get, admin(models.PermNotificationsRead))
+		r.Put("/email/throttle", emailThrottleHandler.Set, admin(models.PermNotificationsWrite))
+		r.Get("/email/suppressions", emailSuppressionHandler.List, admin(models.PermNotificationsRead))
+		r.Post("/email/suppressions", emailSuppressionHandler.Add, admin(models.PermNotificationsWrite))
+		r.Delete("/email/suppressions/{email}", emailSuppressionHandler.Remove, admin(models.PermNotificationsWrite))
+		r.Get("/winback", winbackHandler.Get, admin(models.PermNotificationsRead))
+		r.Put("/winback", winbackHandler.Set, admin(models.PermNotificationsWrite))
+
+		r.Get("/users/{id}", adminNoteHandler.GetUser, admin(models.PermUsersRead))
+		r.Get("/users/{id}/notes", adminNoteHandler.List, admin(models.PermUsersRead))
+		r.Get("/users/{id}/devices", activityHandler.ListDevices, admin(models.PermUsersRead))
+		r.Post("/users/{id}/notes", adminNoteHandler.Create, admin(models.PermUsersWrite))
+		r.Delete("/users/{id}/notes/{noteID}", adminNoteHandler.Delete, admin(models.PermUsersWrite))
+		r.Get("/users/{id}/consents", consentHandler.History, admin(models.PermUsersRead))
+		r.Put("/users/{id}/status", userHandler.SetStatus, admin(models.PermUsersWrite))
+		r.Post("/users/{id}/send-login-link", authHandler.AdminSendLoginLink, admin(models.PermUsersWrite))
+		r.Get("/auth/jwt-keys", authHandler.JWTKeys, admin(models.PermOpsWrite))
+		r.Timeout(uploadTimeout).Post("/users/import", userImportHandler.Import, admin(models.PermUsersWrite))
+		r.Post("/users/merge", userMergeHandler.Merge, admin(models.PermUsersWrite))
+		r.Get("/quarantine", attachmentHandler.ListQuarantine, admin(models.PermFeedbackRead))
+		r.Post("/quarantine/{id}/release", attachmentHandler.ReleaseQuarantined, admin(models.PermFeedbackWrite))
+		r.Post("/quarantine/{id}/discard", attachmentHandler.DiscardQuarantined, admin(models.PermFeedbackWrite))
+		r.Get("/orgs", organizationHandler.List, admin(models.PermUsersRead))
+		r.Post("/orgs", organizationHandler.Create, admin(models.PermUsersWrite))
+		r.Put("/orgs/{id}", organizationHandler.Update, admin(models.PermUsersWrite))
+		r.Get("/orgs/{id}/members", orgMemberHandler.AdminListMembers, admin(models.PermUsersRead))
+		r.Post("/orgs/{id}/invites", orgMemberHandler.AdminInvite, admin(models.PermUsersWrite))
+		r.Put("/orgs/{id}/sso", ssoHandler.Configure, admin(models.PermUsersWrite))
+		r.Delete("/orgs/{id}/sso", ssoHandler.Remove, admin(models.PermUsersWrite))
+		r.Put("/orgs/{id}/members/{userID}/sso-subject", ssoHandler.LinkSubject, admin(models.PermUsersWrite))
+		r.Post("/orgs/{id}/scim-token", scimHandler.IssueToken, admin(models.PermUsersWrite))
+		r.Delete("/orgs/{id}/scim-token", scimHandler.RevokeToken, admin(models.PermUsersWrite))
+		r.Get("/orgs/{id}/usage", orgUsageHandler.AdminGet, admin(models.PermBillingRead))
+		r.Get("/billing/org-usage", orgUsageHandler.ListForMonth, admin(models.PermBillingRead))
+		r.Post("/backups/link", backupHandler.Link, admin(models.PermOpsWrite))
+
+		r.Get("/jobs", jobHandler.List, admin(models.PermOpsWrite))
+		r.Get("/jobs/{id}", jobHandler.Get, admin(models.PermOpsWrite))
+		r.Post("/jobs/{id}/cancel", jobHandler.Cancel, admin(models.PermOpsWrite))
+		r.Post("/jobs/{id}/retry", jobHandler.Retry, admin(models.PermOpsWrite))
+		r.Get("/maintenance/tasks", maintenanceHandler.ListTasks, admin(models.PermOpsWrite))
+		r.Post("/maintenance/tasks/{name}", maintenanceHandler.Start, admin(models.PermOpsWrite))
+		r.Get("/breakers", resilienceHandler.Breakers, admin(models.PermOpsWrite))
+		r.Get("/outbound", resilienceHandler.Outbound, admin(models.PermOpsWrite))
+		r.Get("/database/stats", resilienceHandler.DatabaseStats, admin(models.PermOpsWrite))
+		r.Get("/cache/stats", resilienceHandler.CacheStats, admin(models.PermOpsWrite))
+		r.Get("/timeouts", resilienceHandler.Timeouts, admin(models.PermOpsWrite))
+		r.Get("/slack/queue", resilienceHandler.SlackQueue, admin(models.PermOpsWrite))
+		r.Get("/routes", routeHandler.List, admin(models.PermOpsWrite))
+		r.Get("/routes/openapi.json", routeHandler.OpenAPI, admin(models.PermOpsWrite))
+		r.Post("/incidents", statusHandler.CreateIncident, admin(models.PermOpsWrite))
+		r.Post("/incidents/{id}/updates", statusHandler.AddIncidentUpdate, admin(models.PermOpsWrite))
+
+		r.Get("/analytics/login-links", loginAnalyticsHandler.Stats, admin(models.PermUsersRead))
+		r.Get("/analytics/session-policies", sessionPolicyHandler.Stats, admin(models.PermUsersRead))
+		r.Get("/analytics/signups", signupAnalyticsHandler.ByCountry, admin(models.PermUsersRead))
+		r.Get("/analytics/onboarding-reminders", onboardingAnalyticsHandler.Reminders, admin(models.PermUsersRead))
+		r.Get("/analytics/winback", winbackHandler.Stats, admin(models.PermUsersRead))
+		r.Get("/analytics/active-users", activityHandler.ActiveUsers, admin(models.PermUsersRead))
 
-	// Protected routes (JWT required)
-	r.Group(func(r chi.Router) {
-		r.Use(customMiddleware.JWTAuth(jwtSecret))
+		r.Get("/abuse/blocks", abuseHandler.ListBlocks, admin(models.PermOpsWrite))
+		r.Delete("/abuse/blocks/{ip}", abuseHandler.LiftBlock, admin(models.PermOpsWrite))
+		r.Get("/abuse/activity/{ip}", abuseHandler.Activity, admin(models.PermOpsWrite))
+		r.Delete("/abuse/activity/{ip}", abuseHandler.ClearActivity, admin(models.PermOpsWrite))
+		r.Get("/rate-limits", abuseHandler.ListRateLimits, admin(models.PermOpsWrite))
+		r.Delete("/rate-limits", abuseHandler.ResetRateLimit, admin(models.PermOpsWrite))
 
-		r.Post("/feedback", feedbackHandler.SubmitFeedback)
-		r.Get("/user/status", userHandler.GetStatus)
-		r.Patch("/user/onboarding", userHandler.CompleteOnboarding)
+		r.Get("/flags", featureFlagHandler.List, admin(models.PermFlagsRead))
+		r.Put("/flags/{key}", featureFlagHandler.Set, admin(models.PermFlagsWrite))
+		r.Delete("/flags/{key}", featureFlagHandler.Delete, admin(models.PermFlagsWrite))
+
+		r.Get("/experiments", experimentHandler.List, admin(models.PermFlagsRead))
+		r.Put("/experiments/{key}", experimentHandler.Set, admin(models.PermFlagsWrite))
+		r.Delete("/experiments/{key}", experimentHandler.Delete, admin(models.PermFlagsWrite))
+		r.Get("/experiments/{key}/results", experimentHandler.Results, admin(models.PermFlagsRead))
+		r.Get("/audiences", audienceHandler.List, admin(models.PermNotificationsRead))
+		r.Post("/audiences", audienceHandler.Create, admin(models.PermNotificationsWrite))
+		r.Post("/audiences/preview", audienceHandler.Preview, admin(models.PermNotificationsRead))
+		r.Put("/audiences/{id}", audienceHandler.Update, admin(models.PermNotificationsWrite))
+		r.Delete("/audiences/{id}", audienceHandler.Delete, admin(models.PermNotificationsWrite))
+		r.Get("/audiences/{id}/preview", audienceHandler.PreviewSaved, admin(models.PermNotificationsRead))
+		r.Post("/campaigns", audienceHandler.SendCampaign, admin(models.PermNotificationsWrite))
+
+		r.Get("/schemas", schemaHandler.List, admin(models.PermUsersRead))
+		r.Get("/schemas/{name}", schemaHandler.Get, admin(models.PermUsersRead))
+		r.Put("/schemas/{name}", schemaHandler.Put, admin(models.PermUsersWrite))
+		r.Delete("/schemas/{name}", schemaHandler.Delete, admin(models.PermUsersWrite))
+		r.Post("/schemas/{name}/validate", schemaHandler.Validate, admin(models.PermUsersRead))
+
+		r.Get("/api-keys", adminKeyHandler.List, admin(models.PermKeysManage))
+		r.Post("/api-keys", adminKeyHandler.Create, admin(models.PermKeysManage))
+		r.Put("/api-keys/{id}/permissions", adminKeyHandler.UpdatePermissions, admin(models.PermKeysManage))
+		r.Delete("/api-keys/{id}", adminKeyHandler.Revoke, admin(models.PermKeysManage))
 	})
 
+	if err := routes.Policies().Verify(r, authenticators); err != nil {
+		log.Fatalf("❌ Invalid %v", err)
+	}
+
 	// Start server
-	log.Printf("🚀 Rizon backend starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("❌ Server failed: %v", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		log.Printf("🚀 Rizon backend (%s, commit %s) starting on port %s", appEnv, buildinfo.Commit, port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("❌ Server failed: %v", err)
+		}
+	}()
+
+	// Wait for SIGTERM/SIGINT or a completed /internal/drain
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
+	select {
+	case sig := <-stop:
+		log.Printf("🛑 Received %s, draining", sig)
+		drainer.StartDrain()
+		// Give the load balancer time to observe the failing readiness probe
+		time.Sleep(drainDelay)
+	case <-drainer.ShutdownRequested():
+		log.Println("🛑 Drain complete, shutting down")
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), drainGrace)
+	defer cancelShutdown()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️  Warning: graceful shutdown incomplete: %v", err)
+	}
+	if err := drainer.Wait(shutdownCtx, 0); err != nil {
+		log.Printf("⚠️  Warning: streams still open at shutdown: %v", err)
 	}
+
+	stopWorkers()
+	workers.Wait()
+	log.Println("👋 Rizon backend stopped")
+}
+
+// isDev reports whether APP_ENV names a development environment, so every
+// dev-only route agrees on it.
+func isDev(appEnv string) bool {
+	return appEnv == "dev" || appEnv == "development"
 }
 
 func getEnv(key, fallback string) string {
@@ -119,3 +1122,51 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvList reads a comma-separated list from the environment.
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvInt reads a non-negative integer from the environment.
+func getEnvInt(key string, fallback int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n >= 0 {
+			return n
+		}
+		log.Printf("⚠️  Warning: invalid %s=%q, using default", key, value)
+	}
+	return fallback
+}
+
+// getEnvMillis reads an integer number of milliseconds from the environment.
+func getEnvMillis(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return time.Duration(n) * time.Millisecond
+		}
+		log.Printf("⚠️  Warning: invalid %s=%q, using default", key, value)
+	}
+	return fallback
+}
+
+// getEnvSeconds reads an integer number of seconds from the environment.
+func getEnvSeconds(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second
+		}
+		log.Printf("⚠️  Warning: invalid %s=%q, using default", key, value)
+	}
+	return fallback
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"rizon-backend/internal/authz"
+)
+
+// Route policies. Every route declares one where it is mounted; the server
+// refuses to start if a route reaches the router without one.
+var (
+	public  = authz.Policy{Auth: authz.Public}
+	user    = authz.Policy{Auth: authz.User}
+	scim    = authz.Policy{Auth: authz.SCIM}                     // an organization's SCIM bearer token
+	sandbox = authz.Policy{Auth: authz.Sandbox, Sandboxed: true} // a sandbox key in X-Sandbox-Key
+)
+
+// sandboxed also lets sandbox requests use the route. Only for routes that
+// write nothing but database.SandboxCollections.
+func sandboxed(policy authz.Policy) authz.Policy {
+	policy.Sandboxed = true
+	return policy
+}
+
+// admin requires the admin key, or a scoped key holding permission.
+func admin(permission string) authz.Policy {
+	return authz.Policy{Auth: authz.Admin, Permission: permission}
+}
+
+// integration requires a scoped X-API-Key holding permission.
+func integration(permission string) authz.Policy {
+	return authz.Policy{Auth: authz.Integration, Permission: permission}
+}
@@ -0,0 +1,164 @@
+package abuse
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/adminfeed"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/slack"
+	"rizon-backend/internal/templates"
+)
+
+// Config tunes the heuristics. Counts are per IP within Window.
+type Config struct {
+	Window             time.Duration
+	MaxEmailsPerIP     int
+	SequentialRun      int // this many numbered variants of one address look scripted
+	MaxInvalidVerifies int64
+	BlockDuration      time.Duration
+}
+
+// DefaultConfig is tuned to leave shared NATs (offices, campuses) alone.
+var DefaultConfig = Config{
+	Window:             time.Hour,
+	MaxEmailsPerIP:     10,
+	SequentialRun:      4,
+	MaxInvalidVerifies: 20,
+	BlockDuration:      time.Hour,
+}
+
+// Detector watches auth traffic per IP and temporarily blocks addresses that
+// look like sign-up spam or token guessing, notifying ops when it does.
+type Detector struct {
+	repo     *repository.AbuseRepo
+	notifier slack.Notifier
+	feed     *adminfeed.Feed
+	cfg      Config
+}
+
+func NewDetector(repo *repository.AbuseRepo, notifier slack.Notifier, cfg Config) *Detector {
+	return &Detector{repo: repo, notifier: notifier, cfg: cfg}
+}
+
+// WithAdminFeed sends block alerts to the dashboard's notification center
+// instead of Slack.
+func (d *Detector) WithAdminFeed(feed *adminfeed.Feed) *Detector {
+	d.feed = feed
+	return d
+}
+
+func (d *Detector) windowStart(now time.Time) time.Time {
+	return now.Truncate(d.cfg.Window)
+}
+
+// LoginRequested records a login link request and blocks the IP when it has
+// asked for too many distinct or sequential-looking addresses.
+func (d *Detector) LoginRequested(ctx context.Context, ip, email string) {
+	emails, err := d.repo.RecordEmail(ctx, ip, strings.ToLower(email), d.windowStart(time.Now()), d.cfg.Window)
+	if err != nil {
+		log.Printf("Error recording auth activity: %v", err)
+		return
+	}
+	switch {
+	case len(emails) > d.cfg.MaxEmailsPerIP:
+		d.block(ctx, ip, models.BlockReasonManyEmails,
+			fmt.Sprintf("%d distinct emails within %s", len(emails), d.cfg.Window))
+	case sequentialRun(emails) >= d.cfg.SequentialRun:
+		d.block(ctx, ip, models.BlockReasonSequential,
+			fmt.Sprintf("%d numbered variants of one address within %s", sequentialRun(emails), d.cfg.Window))
+	}
+}
+
+// VerifyFailed records an invalid, expired or reused login token from ip.
+func (d *Detector) VerifyFailed(ctx context.Context, ip string) {
+	count, err := d.repo.RecordInvalidVerify(ctx, ip, d.windowStart(time.Now()), d.cfg.Window)
+	if err != nil {
+		log.Printf("Error recording auth activity: %v", err)
+		return
+	}
+	if count > d.cfg.MaxInvalidVerifies {
+		d.block(ctx, ip, models.BlockReasonInvalidVerify,
+			fmt.Sprintf("%d invalid verify attempts within %s", count, d.cfg.Window))
+	}
+}
+
+// Blocked returns the active block for ip, or nil.
+func (d *Detector) Blocked(ctx context.Context, ip string) (*models.IPBlock, error) {
+	return d.repo.FindActiveBlock(ctx, ip)
+}
+
+func (d *Detector) block(ctx context.Context, ip, reason, details string) {
+	now := time.Now()
+	created, err := d.repo.Block(ctx, &models.IPBlock{
+		IP:        ip,
+		Reason:    reason,
+		Details:   details,
+		CreatedAt: now,
+		ExpiresAt: now.Add(d.cfg.BlockDuration),
+	})
+	if err != nil {
+		log.Printf("Error blocking IP: %v", err)
+		return
+	}
+	if !created {
+		return
+	}
+
+	log.Printf("🚫 Blocked %s for %s: %s", ip, d.cfg.BlockDuration, details)
+	if d.feed != nil {
+		d.feed.Notify(ctx, models.AdminNotificationAbuse,
+			fmt.Sprintf("Blocked %s for %s", ip, d.cfg.BlockDuration),
+			details,
+			map[string]string{"ip": ip, "reason": reason},
+		)
+		return
+	}
+	content, err := templates.Render("abuse_ip_blocked", templates.ChannelSlack, map[string]interface{}{
+		"IP":       ip,
+		"Reason":   reason,
+		"Details":  details,
+		"Duration": d.cfg.BlockDuration.String(),
+	})
+	if err != nil {
+		log.Printf("Error rendering Slack message: %v", err)
+		return
+	}
+	go func() {
+		if _, err := d.notifier.Publish(context.Background(), content.Text); err != nil {
+			log.Printf("Error publishing to Slack: %v", err)
+		}
+	}()
+}
+
+var trailingDigits = regexp.MustCompile(`^(.*?)(\d+)$`)
+
+// sequentialRun returns the largest number of emails that share a domain and a
+// local part differing only in a trailing number (bob1@x, bob2@x, bob3@x).
+func sequentialRun(emails []string) int {
+	groups := map[string]int{}
+	best := 0
+	for _, email := range emails {
+		local, domain, ok := strings.Cut(email, "@")
+		if !ok {
+			continue
+		}
+		// Ignore plus-addressing so bob+1, bob+2 count too
+		local = strings.ReplaceAll(local, "+", "")
+		m := trailingDigits.FindStringSubmatch(local)
+		if m == nil {
+			continue
+		}
+		key := m[1] + "@" + domain
+		groups[key]++
+		if groups[key] > best {
+			best = groups[key]
+		}
+	}
+	return best
+}
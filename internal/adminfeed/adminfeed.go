@@ -0,0 +1,123 @@
+// Package adminfeed fills the dashboard's notification center: new
+// feedback, abuse alerts, failed jobs and failed Slack deliveries, stored
+// in the notifications collection and pushed to connected dashboards over
+// the realtime hub. Alerts that only need a glance go here instead of
+// Slack.
+package adminfeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/realtime"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/slack"
+)
+
+// EventType is the realtime event type of a new notification, the same
+// whether it comes from the change stream or from Feed.
+const EventType = "notifications.insert"
+
+// Feed stores admin notifications.
+type Feed struct {
+	repo *repository.AdminNotificationRepo
+	hub  *realtime.Hub
+}
+
+func New(repo *repository.AdminNotificationRepo) *Feed {
+	return &Feed{repo: repo}
+}
+
+// WithHub publishes new notifications to hub directly. Only use it when
+// change streams are off: otherwise the watcher already publishes every
+// insert, on every replica.
+func (f *Feed) WithHub(hub *realtime.Hub) *Feed {
+	f.hub = hub
+	return f
+}
+
+// Notify stores a notification. Failures are logged, not returned: a
+// missing notification must never fail the request or job that raised it.
+// Sandbox traffic doesn't notify.
+func (f *Feed) Notify(ctx context.Context, kind, title, body string, data map[string]string) {
+	if f == nil || database.InSandbox(ctx) {
+		return
+	}
+	n := &models.AdminNotification{Kind: kind, Title: title, Body: body, Data: data}
+
+	// Use a fresh context so the caller's cancellation doesn't drop it
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := f.repo.Create(saveCtx, n); err != nil {
+		log.Printf("Error storing admin notification: %v", err)
+		return
+	}
+	if f.hub != nil {
+		f.hub.Publish(realtime.Event{
+			Type: EventType,
+			Data: map[string]interface{}{"id": n.ID.Hex(), "document": n},
+		})
+	}
+}
+
+// JobFailed notifies about a job that ran out of attempts. Pass it to
+// jobs.Queue.OnFailure.
+func (f *Feed) JobFailed(job *models.Job, err error) {
+	f.Notify(context.Background(), models.AdminNotificationJobFailed,
+		fmt.Sprintf("Job %s failed", job.Type),
+		err.Error(),
+		map[string]string{"job_id": job.ID.Hex(), "type": job.Type},
+	)
+}
+
+// WatchSlack wraps a Slack notifier so messages it fails to deliver show up
+// in the feed.
+func (f *Feed) WatchSlack(inner slack.Notifier) slack.Notifier {
+	return &watchedSlack{inner: inner, feed: f}
+}
+
+type watchedSlack struct {
+	inner slack.Notifier
+	feed  *Feed
+}
+
+func (s *watchedSlack) Publish(ctx context.Context, message string) (*slack.Message, error) {
+	posted, err := s.inner.Publish(ctx, message)
+	s.failed(ctx, err, message)
+	return posted, err
+}
+
+func (s *watchedSlack) Reply(ctx context.Context, thread *slack.Message, message string) (*slack.Message, error) {
+	posted, err := s.inner.Reply(ctx, thread, message)
+	s.failed(ctx, err, message)
+	return posted, err
+}
+
+func (s *watchedSlack) PublishWithButtons(ctx context.Context, message string, buttons []slack.Button) (*slack.Message, error) {
+	posted, err := s.inner.PublishWithButtons(ctx, message, buttons)
+	s.failed(ctx, err, message)
+	return posted, err
+}
+
+// slackPreviewLength bounds how much of an undelivered message is kept.
+const slackPreviewLength = 200
+
+func (s *watchedSlack) failed(ctx context.Context, err error, message string) {
+	// Held messages are posted later
+	if err == nil || errors.Is(err, slack.ErrQueued) {
+		return
+	}
+	if runes := []rune(message); len(runes) > slackPreviewLength {
+		message = string(runes[:slackPreviewLength]) + "…"
+	}
+	s.feed.Notify(ctx, models.AdminNotificationDeliveryFailed,
+		"Slack message not delivered",
+		err.Error(),
+		map[string]string{"channel": "slack", "message": message},
+	)
+}
@@ -0,0 +1,99 @@
+package agegate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Age bands stored on the user. The exact date of birth is never persisted.
+const (
+	BandUnder13 = "under_13"
+	Band13To15  = "13_15"
+	Band16To17  = "16_17"
+	BandAdult   = "18_plus"
+)
+
+// Bands lists valid age bands, youngest first.
+var Bands = []string{BandUnder13, Band13To15, Band16To17, BandAdult}
+
+// bandMinimum is the lowest age a band guarantees.
+var bandMinimum = map[string]int{
+	BandUnder13: 0,
+	Band13To15:  13,
+	Band16To17:  16,
+	BandAdult:   18,
+}
+
+// Rules holds the minimum age per region (ISO 3166-1 alpha-2 country code),
+// e.g. 13 under COPPA in the US and up to 16 under GDPR in parts of the EU.
+type Rules struct {
+	Default   int
+	ByCountry map[string]int
+}
+
+// ParseRules reads "default=13,DE=16,NL=16,FR=15". Missing default means 13.
+func ParseRules(spec string) (Rules, error) {
+	rules := Rules{Default: 13, ByCountry: map[string]int{}}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		region, raw, ok := strings.Cut(part, "=")
+		age, err := strconv.Atoi(raw)
+		if !ok || err != nil || age < 0 || age > 21 {
+			return rules, fmt.Errorf("invalid age rule %q, want REGION=age", part)
+		}
+		if strings.EqualFold(region, "default") {
+			rules.Default = age
+		} else {
+			rules.ByCountry[strings.ToUpper(region)] = age
+		}
+	}
+	return rules, nil
+}
+
+// MinimumAge returns the minimum age for a country code.
+func (r Rules) MinimumAge(country string) int {
+	if age, ok := r.ByCountry[strings.ToUpper(country)]; ok {
+		return age
+	}
+	return r.Default
+}
+
+// Allowed reports whether someone in band may use the app in country. A band
+// is allowed only if every age in it meets the regional minimum.
+func (r Rules) Allowed(band, country string) bool {
+	return bandMinimum[band] >= r.MinimumAge(country)
+}
+
+// ValidBand reports whether band is a known age band.
+func ValidBand(band string) bool {
+	_, ok := bandMinimum[band]
+	return ok
+}
+
+// AgeOn returns the age in whole years of someone born on dob at time now.
+func AgeOn(dob, now time.Time) int {
+	age := now.Year() - dob.Year()
+	if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+// BandFor maps an age to its band.
+func BandFor(age int) string {
+	switch {
+	case age < 13:
+		return BandUnder13
+	case age < 16:
+		return Band13To15
+	case age < 18:
+		return Band16To17
+	default:
+		return BandAdult
+	}
+}
@@ -0,0 +1,124 @@
+// Package apiversion negotiates the response format a client wants.
+//
+// Version 1 (the default, and what the docs describe) uses snake_case JSON
+// keys. Version 2 uses camelCase for the mobile app. Clients pick a version
+// with the X-API-Version header, or ask for a casing directly with an Accept
+// profile, e.g. `Accept: application/json; profile="camelCase"`.
+package apiversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Casing is the style of JSON object keys in a response.
+type Casing int
+
+const (
+	SnakeCase Casing = iota
+	CamelCase
+)
+
+// Supported versions.
+const (
+	V1      = "1"
+	V2      = "2"
+	Default = V1
+)
+
+var versionCasing = map[string]Casing{
+	V1: SnakeCase,
+	V2: CamelCase,
+}
+
+// ErrUnsupported is returned for a version this server doesn't know.
+var ErrUnsupported = errors.New("unsupported API version")
+
+// Negotiation is the outcome for one request.
+type Negotiation struct {
+	Version string
+	Casing  Casing
+}
+
+// Negotiate reads the requested version and casing. An Accept profile wins
+// over the version's casing, so a v1 client can still opt into camelCase.
+func Negotiate(r *http.Request) (Negotiation, error) {
+	n := Negotiation{Version: Default}
+	if v := strings.TrimSpace(r.Header.Get("X-API-Version")); v != "" {
+		v = strings.TrimPrefix(strings.ToLower(v), "v")
+		if _, ok := versionCasing[v]; !ok {
+			return n, ErrUnsupported
+		}
+		n.Version = v
+	}
+	n.Casing = versionCasing[n.Version]
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(params["profile"]) {
+		case "camelcase", "camel":
+			n.Casing = CamelCase
+		case "snake_case", "snake":
+			n.Casing = SnakeCase
+		}
+	}
+	return n, nil
+}
+
+// snakeKey matches keys produced from our snake_case struct tags. Anything
+// else (IDs, user-supplied map keys with other characters) is left alone.
+var snakeKey = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)+$`)
+
+// ToCamelCase rewrites every snake_case object key in a JSON document to
+// camelCase. Values are untouched; numbers keep their exact representation.
+func ToCamelCase(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(camelize(v)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func camelize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			out[camelKey(k)] = camelize(val)
+		}
+		return out
+	case []interface{}:
+		for i := range v {
+			v[i] = camelize(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func camelKey(k string) string {
+	if !snakeKey.MatchString(k) {
+		return k
+	}
+	parts := strings.Split(k, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,196 @@
+package attestation
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// appleAppAttestRootCA is the Apple App Attestation Root CA, valid until 2045.
+const appleAppAttestRootCA = `-----BEGIN CERTIFICATE-----
+MIICITCCAaegAwIBAgIQC/O+DvHN0uD7jG5yH2IXmDAKBggqhkjOPQQDAzBSMSYw
+JAYDVQQDDB1BcHBsZSBBcHAgQXR0ZXN0YXRpb24gUm9vdCBDQTETMBEGA1UECgwK
+QXBwbGUgSW5jLjETMBEGA1UECAwKQ2FsaWZvcm5pYTAeFw0yMDAzMTgxODMyNTNa
+Fw00NTAzMTUwMDAwMDBaMFIxJjAkBgNVBAMMHUFwcGxlIEFwcCBBdHRlc3RhdGlv
+biBSb290IENBMRMwEQYDVQQKDApBcHBsZSBJbmMuMRMwEQYDVQQIDApDYWxpZm9y
+bmlhMHYwEAYHKoZIzj0CAQYFK4EEACIDYgAERTHhmLW07ATaFQIEVwTtT4dyctdh
+NbJhFs/Ii2FdCgAHGbpphY3+d8qjuDngIN3WVhQUBHAoMeQ/cLiP1sOUtgjqK9au
+Yen1mMEvRq9Sk3Jm5X8U62H+xTD3FE9TgS41o0IwQDAPBgNVHRMBAf8EBTADAQH/
+MB0GA1UdDgQWBBSskRBTM72+aEH/pwyp5frq5eWKoTAOBgNVHQ8BAf8EBAMCAQYw
+CgYIKoZIzj0EAwMDaAAwZQIwQgFGnByvsiVbpTKwSga0kP0e8EeDS4+sQmTvb7vn
+53O5+FRXgeLhpJ06ysC5PrOyAjEAp5U4xDgEgllF7En3VcE3iexZZtKeYnpqtijV
+oyFraWVIyd/dganmrduC1bmTBGwD
+-----END CERTIFICATE-----`
+
+// OID of the certificate extension holding the attestation nonce.
+var appAttestNonceOID = asn1.ObjectIdentifier{1, 2, 840, 113635, 100, 8, 2}
+
+var (
+	aaguidProduction  = []byte("appattest\x00\x00\x00\x00\x00\x00\x00")
+	aaguidDevelopment = []byte("appattestdevelop")
+)
+
+// AppAttestVerifier validates Apple App Attest attestation objects following
+// Apple's "Validating apps that connect to your server" steps.
+type AppAttestVerifier struct {
+	appID       string // <TeamID>.<BundleID>
+	development bool
+	roots       *x509.CertPool
+}
+
+func NewAppAttestVerifier(appID string, development bool) (*AppAttestVerifier, error) {
+	block, _ := pem.Decode([]byte(appleAppAttestRootCA))
+	if block == nil {
+		return nil, errors.New("invalid App Attest root certificate")
+	}
+	root, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	return &AppAttestVerifier{
+		appID:       appID,
+		development: development,
+		roots:       roots,
+	}, nil
+}
+
+// Verify checks a base64 attestation object for the given key ID (base64) and challenge.
+func (v *AppAttestVerifier) Verify(attestationB64, keyIDB64, challenge string) (*Result, error) {
+	raw, err := base64.StdEncoding.DecodeString(attestationB64)
+	if err != nil {
+		return nil, errors.New("attestation is not valid base64")
+	}
+	keyID, err := base64.StdEncoding.DecodeString(keyIDB64)
+	if err != nil || len(keyID) != sha256.Size {
+		return nil, errors.New("key_id is not a valid base64 SHA-256 hash")
+	}
+
+	decoded, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := decoded.(map[string]interface{})
+	if !ok {
+		return nil, errCBOR
+	}
+	format, _ := obj["fmt"].(string)
+	authData, _ := obj["authData"].([]byte)
+	stmt, _ := obj["attStmt"].(map[string]interface{})
+	if format != "apple-appattest" || authData == nil || stmt == nil {
+		return nil, errors.New("not an App Attest attestation object")
+	}
+	x5c, _ := stmt["x5c"].([]interface{})
+	if len(x5c) < 2 {
+		return nil, errors.New("attestation is missing its certificate chain")
+	}
+
+	result := &Result{Passed: true, Details: map[string]string{"format": format}}
+
+	// 1. Certificate chain must lead to Apple's App Attest root
+	certs := make([]*x509.Certificate, 0, len(x5c))
+	for _, c := range x5c {
+		der, ok := c.([]byte)
+		if !ok {
+			return nil, errors.New("invalid certificate in chain")
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	credCert := certs[0]
+	if _, err := credCert.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		result.fail("certificate chain invalid")
+		return result, nil
+	}
+
+	// 2. Nonce extension must equal SHA256(authData || SHA256(challenge))
+	clientDataHash := sha256.Sum256([]byte(challenge))
+	expectedNonce := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	nonce, err := extractNonce(credCert)
+	if err != nil || !bytes.Equal(nonce, expectedNonce[:]) {
+		result.fail("nonce mismatch")
+	}
+
+	// 3. Key ID must be the SHA-256 of the credential public key
+	pub, ok := credCert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("credential certificate does not hold an EC key")
+	}
+	ecdhKey, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	pubHash := sha256.Sum256(ecdhKey.Bytes())
+	if !bytes.Equal(pubHash[:], keyID) {
+		result.fail("key id mismatch")
+	}
+
+	// 4. Authenticator data: rpIdHash, counter, aaguid, credentialId
+	if len(authData) < 55 {
+		return nil, errors.New("authenticator data too short")
+	}
+	appIDHash := sha256.Sum256([]byte(v.appID))
+	if !bytes.Equal(authData[:32], appIDHash[:]) {
+		result.fail("app id mismatch")
+	}
+	if counter := binary.BigEndian.Uint32(authData[33:37]); counter != 0 {
+		result.fail(fmt.Sprintf("unexpected counter %d", counter))
+	}
+	expectedAAGUID := aaguidProduction
+	if v.development {
+		expectedAAGUID = aaguidDevelopment
+	}
+	if !bytes.Equal(authData[37:53], expectedAAGUID) {
+		result.fail("environment mismatch")
+	}
+	credIDLen := int(binary.BigEndian.Uint16(authData[53:55]))
+	if len(authData) < 55+credIDLen || !bytes.Equal(authData[55:55+credIDLen], keyID) {
+		result.fail("credential id mismatch")
+	}
+
+	return result, nil
+}
+
+// extractNonce reads the nonce from the credential certificate's App Attest
+// extension: SEQUENCE { [1] EXPLICIT OCTET STRING }.
+func extractNonce(cert *x509.Certificate) ([]byte, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(appAttestNonceOID) {
+			continue
+		}
+		var seq asn1.RawValue
+		if _, err := asn1.Unmarshal(ext.Value, &seq); err != nil {
+			return nil, err
+		}
+		var tagged asn1.RawValue
+		if _, err := asn1.Unmarshal(seq.Bytes, &tagged); err != nil {
+			return nil, err
+		}
+		var nonce []byte
+		if _, err := asn1.Unmarshal(tagged.Bytes, &nonce); err != nil {
+			return nil, err
+		}
+		return nonce, nil
+	}
+	return nil, errors.New("nonce extension not found")
+}
@@ -0,0 +1,60 @@
+package attestation
+
+import (
+	"context"
+	"errors"
+)
+
+// Platforms accepted by Verify.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+)
+
+// ErrNotConfigured is returned when the verifier for a platform has no credentials.
+var ErrNotConfigured = errors.New("attestation is not configured for this platform")
+
+// Result is the outcome of verifying a platform attestation.
+type Result struct {
+	Passed  bool              `json:"passed"`
+	Reasons []string          `json:"reasons,omitempty"` // why the attestation failed
+	Details map[string]string `json:"details,omitempty"` // raw verdicts, kept for analysis
+}
+
+func (r *Result) fail(reason string) {
+	r.Passed = false
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// Verifier checks attestations from either platform. A nil platform verifier
+// means that platform isn't configured.
+type Verifier struct {
+	playIntegrity *PlayIntegrityVerifier
+	appAttest     *AppAttestVerifier
+}
+
+func NewVerifier(playIntegrity *PlayIntegrityVerifier, appAttest *AppAttestVerifier) *Verifier {
+	return &Verifier{
+		playIntegrity: playIntegrity,
+		appAttest:     appAttest,
+	}
+}
+
+// Verify checks a Play Integrity token (android) or an App Attest attestation
+// object (ios) against the challenge we issued.
+func (v *Verifier) Verify(ctx context.Context, platform, token, keyID, challenge string) (*Result, error) {
+	switch platform {
+	case PlatformAndroid:
+		if v.playIntegrity == nil {
+			return nil, ErrNotConfigured
+		}
+		return v.playIntegrity.Verify(ctx, token, challenge)
+	case PlatformIOS:
+		if v.appAttest == nil {
+			return nil, ErrNotConfigured
+		}
+		return v.appAttest.Verify(token, keyID, challenge)
+	default:
+		return nil, errors.New("unsupported platform")
+	}
+}
@@ -0,0 +1,386 @@
+package attestation
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+type cborMap [][2]interface{} // ordered, so encodings are stable
+
+func cborHead(major byte, n int) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	}
+}
+
+func encodeCBOR(v interface{}) []byte {
+	switch v := v.(type) {
+	case []byte:
+		return append(cborHead(2, len(v)), v...)
+	case string:
+		return append(cborHead(3, len(v)), v...)
+	case []interface{}:
+		out := cborHead(4, len(v))
+		for _, item := range v {
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	case cborMap:
+		out := cborHead(5, len(v))
+		for _, kv := range v {
+			out = append(out, encodeCBOR(kv[0])...)
+			out = append(out, encodeCBOR(kv[1])...)
+		}
+		return out
+	}
+	panic("unsupported CBOR value")
+}
+
+// appAttestCA stands in for Apple's App Attest CA: a root, an intermediate
+// and the device's credential certificate.
+type appAttestCA struct {
+	root, intermediate *x509.Certificate
+	intermediateKey    *ecdsa.PrivateKey
+}
+
+func newAppAttestCA(t *testing.T) *appAttestCA {
+	t.Helper()
+	rootKey := mustKey(t)
+	root := mustCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test App Attestation Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, rootKey, rootKey)
+	intermediateKey := mustKey(t)
+	intermediate := mustCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "Test App Attestation CA 1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, root, intermediateKey, rootKey)
+	return &appAttestCA{root: root, intermediate: intermediate, intermediateKey: intermediateKey}
+}
+
+func mustKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustCert(t *testing.T, template, parent *x509.Certificate, key, signer *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	if parent == nil {
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func (ca *appAttestCA) verifier(appID string, development bool) *AppAttestVerifier {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.root)
+	return &AppAttestVerifier{appID: appID, development: development, roots: roots}
+}
+
+// attestation is what the device sends: the attestation object and key ID,
+// both base64.
+type attestation struct {
+	object, keyID string
+}
+
+type attestOptions struct {
+	appID     string
+	challenge string // the nonce is bound to
+	aaguid    []byte
+	counter   uint32
+}
+
+func (ca *appAttestCA) attest(t *testing.T, opts attestOptions) attestation {
+	t.Helper()
+	credKey := mustKey(t)
+	ecdhKey, _ := credKey.PublicKey.ECDH()
+	keyID := sha256.Sum256(ecdhKey.Bytes())
+
+	appIDHash := sha256.Sum256([]byte(opts.appID))
+	authData := append(appIDHash[:], 0x40)
+	authData = binary.BigEndian.AppendUint32(authData, opts.counter)
+	authData = append(authData, opts.aaguid...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(keyID)))
+	authData = append(authData, keyID[:]...)
+
+	clientDataHash := sha256.Sum256([]byte(opts.challenge))
+	nonce := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	octets, _ := asn1.Marshal(nonce[:])
+	tagged, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, IsCompound: true, Bytes: octets})
+	ext, _ := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: tagged})
+
+	cred := mustCert(t, &x509.Certificate{
+		SerialNumber:    big.NewInt(3),
+		Subject:         pkix.Name{CommonName: "credential"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: appAttestNonceOID, Value: ext}},
+	}, ca.intermediate, credKey, ca.intermediateKey)
+
+	object := encodeCBOR(cborMap{
+		{"fmt", "apple-appattest"},
+		{"attStmt", cborMap{{"x5c", []interface{}{cred.Raw, ca.intermediate.Raw}}, {"receipt", []byte("receipt")}}},
+		{"authData", authData},
+	})
+	return attestation{
+		object: base64.StdEncoding.EncodeToString(object),
+		keyID:  base64.StdEncoding.EncodeToString(keyID[:]),
+	}
+}
+
+func TestAppAttestVerify(t *testing.T) {
+	ca := newAppAttestCA(t)
+	const appID = "TEAMID1234.app.rizon"
+	valid := attestOptions{appID: appID, challenge: "ch4llenge", aaguid: aaguidProduction}
+	otherKeyID := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name        string
+		opts        func(o *attestOptions)
+		keyID       string // overrides the attested key ID
+		development bool
+		wantReasons []string
+	}{
+		{"valid", func(o *attestOptions) {}, "", false, nil},
+		{"development", func(o *attestOptions) { o.aaguid = aaguidDevelopment }, "", true, nil},
+		{"wrong challenge", func(o *attestOptions) { o.challenge = "replayed" }, "", false, []string{"nonce mismatch"}},
+		{"wrong app", func(o *attestOptions) { o.appID = "TEAMID1234.app.other" }, "", false, []string{"app id mismatch"}},
+		{"used counter", func(o *attestOptions) { o.counter = 1 }, "", false, []string{"unexpected counter 1"}},
+		{"development build in production", func(o *attestOptions) { o.aaguid = aaguidDevelopment }, "", false, []string{"environment mismatch"}},
+		{"another key", func(o *attestOptions) {}, otherKeyID, false, []string{"key id mismatch", "credential id mismatch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.opts(&opts)
+			att := ca.attest(t, opts)
+			keyID := att.keyID
+			if tt.keyID != "" {
+				keyID = tt.keyID
+			}
+			result, err := ca.verifier(appID, tt.development).Verify(att.object, keyID, "ch4llenge")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Passed != (tt.wantReasons == nil) || !slices.Equal(result.Reasons, tt.wantReasons) {
+				t.Errorf("result = %+v, want reasons %v", result, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestAppAttestUntrustedChain(t *testing.T) {
+	att := newAppAttestCA(t).attest(t, attestOptions{appID: "app", challenge: "c", aaguid: aaguidProduction})
+	apple, err := NewAppAttestVerifier("app", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := apple.Verify(att.object, att.keyID, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || !slices.Equal(result.Reasons, []string{"certificate chain invalid"}) {
+		t.Errorf("result = %+v, want the chain rejected", result)
+	}
+}
+
+func TestAppAttestMalformed(t *testing.T) {
+	ca := newAppAttestCA(t)
+	v := ca.verifier("app", false)
+	att := ca.attest(t, attestOptions{appID: "app", challenge: "c", aaguid: aaguidProduction})
+	object, _ := base64.StdEncoding.DecodeString(att.object)
+
+	// Every truncation must fail cleanly rather than panic
+	for n := 0; n < len(object); n++ {
+		if _, err := v.Verify(base64.StdEncoding.EncodeToString(object[:n]), att.keyID, "c"); err == nil {
+			t.Fatalf("attestation cut to %d bytes was accepted", n)
+		}
+	}
+	for name, obj := range map[string][]byte{
+		"not a map":          encodeCBOR("apple-appattest"),
+		"wrong format":       encodeCBOR(cborMap{{"fmt", "packed"}, {"attStmt", cborMap{}}, {"authData", []byte{1}}}),
+		"no chain":           encodeCBOR(cborMap{{"fmt", "apple-appattest"}, {"attStmt", cborMap{}}, {"authData", []byte{1}}}),
+		"chain of strings":   encodeCBOR(cborMap{{"fmt", "apple-appattest"}, {"attStmt", cborMap{{"x5c", []interface{}{"a", "b"}}}}, {"authData", []byte{1}}}),
+		"huge byte string":   {0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"huge map":           {0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"integer map key":    {0xa1, 0x01, 0x01},
+		"deep nesting":       []byte("\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x40"),
+		"trailing bytes":     append(object, 0x00),
+		"indefinite length":  {0xbf, 0xff},
+		"truncated argument": {0x59, 0x01},
+	} {
+		if _, err := v.Verify(base64.StdEncoding.EncodeToString(obj), att.keyID, "c"); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+	if _, err := v.Verify("%%%", att.keyID, "c"); err == nil {
+		t.Error("accepted an attestation that isn't base64")
+	}
+	if _, err := v.Verify(att.object, base64.StdEncoding.EncodeToString([]byte("short")), "c"); err == nil {
+		t.Error("accepted a key ID that isn't a SHA-256 hash")
+	}
+}
+
+// googleAPIs serves the OAuth token endpoint and the Play Integrity decode
+// endpoint, standing in for oauth2.googleapis.com and
+// playintegrity.googleapis.com.
+type googleAPIs struct {
+	*httptest.Server
+	payload     integrityPayload
+	tokenMints  int
+	accessToken string
+}
+
+func newGoogleAPIs(t *testing.T) *googleAPIs {
+	t.Helper()
+	g := &googleAPIs{accessToken: "ya29.test"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		g.tokenMints++
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": g.accessToken, "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /v1/app.rizon:decodeIntegrityToken", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+g.accessToken {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"tokenPayloadExternal": g.payload})
+	})
+	g.Server = httptest.NewServer(mux)
+	t.Cleanup(g.Close)
+	return g
+}
+
+// RoundTrip sends every request to the test server, whatever its host.
+func (g *googleAPIs) RoundTrip(r *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(g.URL)
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newPlayIntegrity(t *testing.T, g *googleAPIs) *PlayIntegrityVerifier {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	account, _ := json.Marshal(serviceAccount{
+		ClientEmail: "verifier@rizon.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	v, err := NewPlayIntegrityVerifier("app.rizon", string(account))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.client = &http.Client{Transport: g}
+	return v
+}
+
+func TestPlayIntegrityVerify(t *testing.T) {
+	g := newGoogleAPIs(t)
+	v := newPlayIntegrity(t, g)
+	ctx := context.Background()
+
+	var genuine integrityPayload
+	genuine.RequestDetails.RequestPackageName = "app.rizon"
+	genuine.RequestDetails.Nonce = "ch4llenge"
+	genuine.AppIntegrity.AppRecognitionVerdict = "PLAY_RECOGNIZED"
+	genuine.DeviceIntegrity.DeviceRecognitionVerdict = []string{"MEETS_DEVICE_INTEGRITY"}
+
+	tests := []struct {
+		name        string
+		change      func(p *integrityPayload)
+		wantReasons []string
+	}{
+		{"genuine", func(p *integrityPayload) {}, nil},
+		{"strong integrity", func(p *integrityPayload) {
+			p.DeviceIntegrity.DeviceRecognitionVerdict = []string{"MEETS_STRONG_INTEGRITY"}
+		}, nil},
+		{"another app", func(p *integrityPayload) { p.RequestDetails.RequestPackageName = "com.clone" }, []string{"package name mismatch"}},
+		{"replayed", func(p *integrityPayload) { p.RequestDetails.Nonce = "old" }, []string{"nonce mismatch"}},
+		{"sideloaded", func(p *integrityPayload) { p.AppIntegrity.AppRecognitionVerdict = "UNRECOGNIZED_VERSION" }, []string{"app not recognized by Play"}},
+		{"rooted", func(p *integrityPayload) {
+			p.DeviceIntegrity.DeviceRecognitionVerdict = []string{"MEETS_BASIC_INTEGRITY"}
+		}, []string{"device integrity not met"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g.payload = genuine
+			tt.change(&g.payload)
+			result, err := v.Verify(ctx, "integrity-token", "ch4llenge")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Passed != (tt.wantReasons == nil) || !slices.Equal(result.Reasons, tt.wantReasons) {
+				t.Errorf("result = %+v, want reasons %v", result, tt.wantReasons)
+			}
+		})
+	}
+	if g.tokenMints != 1 {
+		t.Errorf("minted %d access tokens, want 1 reused", g.tokenMints)
+	}
+}
+
+func TestVerifierPlatforms(t *testing.T) {
+	v := NewVerifier(nil, nil)
+	for _, platform := range []string{PlatformAndroid, PlatformIOS} {
+		if _, err := v.Verify(context.Background(), platform, "token", "key", "challenge"); !errors.Is(err, ErrNotConfigured) {
+			t.Errorf("%s: err = %v, want ErrNotConfigured", platform, err)
+		}
+	}
+	if _, err := v.Verify(context.Background(), "windows", "token", "key", "challenge"); err == nil {
+		t.Error("accepted an unsupported platform")
+	}
+}
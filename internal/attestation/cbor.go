@@ -0,0 +1,109 @@
+package attestation
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Minimal CBOR decoder covering what App Attest attestation objects use:
+// unsigned/negative integers, byte and text strings, arrays, maps and simple
+// values. Indefinite lengths, tags and floats are not supported.
+
+var errCBOR = errors.New("malformed CBOR")
+
+func decodeCBOR(data []byte) (interface{}, error) {
+	v, rest, err := cborItem(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errCBOR
+	}
+	return v, nil
+}
+
+func cborItem(data []byte, depth int) (interface{}, []byte, error) {
+	if len(data) == 0 || depth > 16 {
+		return nil, nil, errCBOR
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24 && len(data) >= 1:
+		arg, data = uint64(data[0]), data[1:]
+	case info == 25 && len(data) >= 2:
+		arg, data = uint64(binary.BigEndian.Uint16(data)), data[2:]
+	case info == 26 && len(data) >= 4:
+		arg, data = uint64(binary.BigEndian.Uint32(data)), data[4:]
+	case info == 27 && len(data) >= 8:
+		arg, data = binary.BigEndian.Uint64(data), data[8:]
+	default:
+		return nil, nil, errCBOR
+	}
+
+	switch major {
+	case 0:
+		return int64(arg), data, nil
+	case 1:
+		return -1 - int64(arg), data, nil
+	case 2, 3:
+		if uint64(len(data)) < arg {
+			return nil, nil, errCBOR
+		}
+		if major == 2 {
+			return data[:arg], data[arg:], nil
+		}
+		return string(data[:arg]), data[arg:], nil
+	case 4:
+		if arg > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, rest, err := cborItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+			data = rest
+		}
+		return items, data, nil
+	case 5:
+		if arg > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		m := make(map[string]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, rest, err := cborItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, nil, errCBOR
+			}
+			val, rest, err := cborItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[k] = val
+			data = rest
+		}
+		return m, data, nil
+	case 7:
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		}
+	}
+	return nil, nil, errCBOR
+}
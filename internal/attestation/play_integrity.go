@@ -0,0 +1,190 @@
+package attestation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/httpclient"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const playIntegrityScope = "https://www.googleapis.com/auth/playintegrity"
+
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// PlayIntegrityVerifier decodes Play Integrity tokens via Google's server API
+// using a service account linked to the Play Console project.
+type PlayIntegrityVerifier struct {
+	packageName string
+	account     serviceAccount
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewPlayIntegrityVerifier(packageName, serviceAccountJSON string) (*PlayIntegrityVerifier, error) {
+	var account serviceAccount
+	if err := json.Unmarshal([]byte(serviceAccountJSON), &account); err != nil {
+		return nil, fmt.Errorf("invalid service account JSON: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &PlayIntegrityVerifier{
+		packageName: packageName,
+		account:     account,
+		client:      httpclient.New("play_integrity", httpclient.Options{}),
+	}, nil
+}
+
+type integrityPayload struct {
+	RequestDetails struct {
+		RequestPackageName string `json:"requestPackageName"`
+		Nonce              string `json:"nonce"`
+		TimestampMillis    string `json:"timestampMillis"`
+	} `json:"requestDetails"`
+	AppIntegrity struct {
+		AppRecognitionVerdict string `json:"appRecognitionVerdict"`
+	} `json:"appIntegrity"`
+	DeviceIntegrity struct {
+		DeviceRecognitionVerdict []string `json:"deviceRecognitionVerdict"`
+	} `json:"deviceIntegrity"`
+	AccountDetails struct {
+		AppLicensingVerdict string `json:"appLicensingVerdict"`
+	} `json:"accountDetails"`
+}
+
+func (v *PlayIntegrityVerifier) Verify(ctx context.Context, integrityToken, challenge string) (*Result, error) {
+	accessToken, err := v.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, _ := json.Marshal(map[string]string{"integrity_token": integrityToken})
+	endpoint := fmt.Sprintf("https://playintegrity.googleapis.com/v1/%s:decodeIntegrityToken", v.packageName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("play integrity request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("play integrity returned %d: %s", resp.StatusCode, snippet)
+	}
+
+	var decoded struct {
+		TokenPayloadExternal integrityPayload `json:"tokenPayloadExternal"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid play integrity response: %w", err)
+	}
+	payload := decoded.TokenPayloadExternal
+
+	result := &Result{
+		Passed: true,
+		Details: map[string]string{
+			"app_recognition":    payload.AppIntegrity.AppRecognitionVerdict,
+			"device_recognition": strings.Join(payload.DeviceIntegrity.DeviceRecognitionVerdict, ","),
+			"app_licensing":      payload.AccountDetails.AppLicensingVerdict,
+		},
+	}
+	if payload.RequestDetails.RequestPackageName != v.packageName {
+		result.fail("package name mismatch")
+	}
+	if payload.RequestDetails.Nonce != challenge {
+		result.fail("nonce mismatch")
+	}
+	if payload.AppIntegrity.AppRecognitionVerdict != "PLAY_RECOGNIZED" {
+		result.fail("app not recognized by Play")
+	}
+	meetsDevice := false
+	for _, verdict := range payload.DeviceIntegrity.DeviceRecognitionVerdict {
+		if verdict == "MEETS_DEVICE_INTEGRITY" || verdict == "MEETS_STRONG_INTEGRITY" {
+			meetsDevice = true
+		}
+	}
+	if !meetsDevice {
+		result.fail("device integrity not met")
+	}
+	return result, nil
+}
+
+// token returns a cached OAuth access token, minting a new one from the
+// service account key when it is about to expire.
+func (v *PlayIntegrityVerifier) token(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.accessToken != "" && time.Until(v.expiresAt) > time.Minute {
+		return v.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(v.account.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid service account key: %w", err)
+	}
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   v.account.ClientEmail,
+		"scope": playIntegrityScope,
+		"aud":   v.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token exchange failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token exchange returned %d", resp.StatusCode)
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	v.accessToken = tok.AccessToken
+	v.expiresAt = now.Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return v.accessToken, nil
+}
@@ -0,0 +1,163 @@
+package authz
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AuthType is how a caller proves who they are.
+type AuthType string
+
+const (
+	Public      AuthType = "public"      // anyone
+	User        AuthType = "user"        // app user JWT
+	Admin       AuthType = "admin"       // X-Admin-Key
+	Integration AuthType = "integration" // scoped X-API-Key for automation
+	SCIM        AuthType = "scim"        // an organization's SCIM bearer token
+	Sandbox     AuthType = "sandbox"     // a sandbox key in X-Sandbox-Key
+)
+
+// Policy declares what a route requires.
+type Policy struct {
+	Auth AuthType `json:"auth"`
+	// Key permission required of admin and integration callers
+	Permission string `json:"permission,omitempty"`
+	// Feature flag the user must be enrolled in (dark launches)
+	Entitlement string `json:"entitlement,omitempty"`
+	// Sandbox requests may use the route: it writes nothing outside
+	// database.SandboxCollections. Others refuse them.
+	Sandboxed bool `json:"sandboxed,omitempty"`
+}
+
+// Table maps "METHOD /route/pattern" to its policy.
+type Table map[string]Policy
+
+// Authenticators provides the middleware that authenticates each AuthType.
+type Authenticators map[AuthType]func(http.Handler) http.Handler
+
+func routeKey(method, pattern string) string {
+	return method + " " + pattern
+}
+
+// routePath is the path chi routes r on: the raw path when the URL has one,
+// so an encoded slash stays inside its segment. Looking routes up by
+// r.URL.Path instead would miss routes chi still serves.
+func routePath(r *http.Request) string {
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	if path == "" {
+		path = "/"
+	}
+	return path
+}
+
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace,
+}
+
+// notRouted answers a request that matches no route as chi would, with a
+// 405 if the path has routes for other methods and a 404 otherwise.
+func notRouted(mux *chi.Mux, w http.ResponseWriter, r *http.Request, path string) {
+	for _, method := range routeMethods {
+		if mux.Find(chi.NewRouteContext(), method, path) != "" {
+			mux.MethodNotAllowedHandler().ServeHTTP(w, r)
+			return
+		}
+	}
+	mux.NotFoundHandler().ServeHTTP(w, r)
+}
+
+// Enforce returns the single middleware that makes every auth decision. It
+// resolves the route the request will hit, then runs that route's
+// authenticator, permission check and entitlement check before the rest of
+// the chain. Unknown routes get the router's 404 or 405 here rather than
+// passing through, so no handler is ever reached unauthenticated; routes
+// without a policy are refused, as are sandbox requests to routes that
+// aren't Sandboxed.
+func (t Table) Enforce(mux *chi.Mux, auth Authenticators, flags middleware.FlagChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := routePath(r)
+			pattern := mux.Find(chi.NewRouteContext(), r.Method, path)
+			if pattern == "" {
+				notRouted(mux, w, r, path)
+				return
+			}
+
+			policy, ok := t[routeKey(r.Method, pattern)]
+			if !ok {
+				log.Printf("⚠️  No auth policy for %s %s", r.Method, pattern)
+				http.Error(w, `{"error":"forbidden","code":"no_policy"}`, http.StatusForbidden)
+				return
+			}
+			if database.InSandbox(r.Context()) && !policy.Sandboxed {
+				http.Error(w, `{"error":"route not available in a sandbox","code":"not_sandboxed"}`, http.StatusForbidden)
+				return
+			}
+
+			h := next
+			if policy.Entitlement != "" {
+				h = middleware.FeatureFlag(flags, policy.Entitlement)(h)
+			}
+			if policy.Permission != "" {
+				h = middleware.RequirePermission(policy.Permission)(h)
+			}
+			if policy.Auth != Public {
+				h = auth[policy.Auth](h)
+			}
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Verify checks the table against the router: every route needs a policy,
+// admin and integration routes need a permission, entitlements need a user,
+// and every policy must still match a route. Call it once routes are
+// registered and refuse to start on error.
+func (t Table) Verify(routes chi.Routes, auth Authenticators) error {
+	var problems []string
+	seen := map[string]bool{}
+
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		key := routeKey(method, route)
+		seen[key] = true
+
+		policy, ok := t[key]
+		switch {
+		case !ok:
+			problems = append(problems, key+": no policy")
+		case policy.Auth != Public && auth[policy.Auth] == nil:
+			problems = append(problems, fmt.Sprintf("%s: no authenticator for %q", key, policy.Auth))
+		case (policy.Auth == Admin || policy.Auth == Integration) && policy.Permission == "":
+			problems = append(problems, key+": permission required")
+		case policy.Entitlement != "" && policy.Auth != User:
+			problems = append(problems, key+": entitlements need user auth")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key := range t {
+		if !seen[key] {
+			problems = append(problems, key+": policy for unknown route")
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("route policies:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
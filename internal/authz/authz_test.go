@@ -0,0 +1,105 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rizon-backend/internal/database"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestEnforceSandbox(t *testing.T) {
+	mux := chi.NewRouter()
+	table := Table{
+		"GET /shared":   {Auth: Public},
+		"GET /isolated": {Auth: Public, Sandboxed: true},
+	}
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Sandbox-Key") != "" {
+				r = r.WithContext(database.WithSandbox(r.Context(), "test"))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	mux.Use(table.Enforce(mux, Authenticators{}, nil))
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	mux.Get("/shared", ok)
+	mux.Get("/isolated", ok)
+
+	tests := []struct {
+		path    string
+		sandbox bool
+		want    int
+	}{
+		{"/shared", false, http.StatusNoContent},
+		{"/shared", true, http.StatusForbidden},
+		{"/isolated", false, http.StatusNoContent},
+		{"/isolated", true, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.sandbox {
+			req.Header.Set("X-Sandbox-Key", "key")
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("GET %s (sandbox %v) = %d, want %d", tt.path, tt.sandbox, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestEnforceEncodedPath(t *testing.T) {
+	mux := chi.NewRouter()
+	table := Table{"PUT /admin/flags/{key}": {Auth: Admin, Permission: "flags:write"}}
+	denyAll := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	mux.Use(table.Enforce(mux, Authenticators{Admin: denyAll}, nil))
+	mux.Put("/admin/flags/{key}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{http.MethodPut, "/admin/flags/x", http.StatusUnauthorized},
+		// chi routes on the raw path, so a%2Fb is one {key} segment
+		{http.MethodPut, "/admin/flags/a%2Fb", http.StatusUnauthorized},
+		{http.MethodPut, "/admin/flags/a/b", http.StatusNotFound},
+		{http.MethodGet, "/admin/flags/x", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/nowhere", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestCORSEncodedPath(t *testing.T) {
+	mux := chi.NewRouter()
+	table := Table{"GET /admin/schemas/{name}": {Auth: Admin, Permission: "schemas:read"}}
+	tagged := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-CORS-Group", "admin")
+			next.ServeHTTP(w, r)
+		})
+	}
+	mux.Use(table.CORS(mux, map[AuthType]func(http.Handler) http.Handler{Admin: tagged}))
+	mux.Get("/admin/schemas/{name}", func(w http.ResponseWriter, r *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/schemas/a%2Fb", nil))
+	if rec.Header().Get("X-CORS-Group") != "admin" {
+		t.Error("encoded path didn't get the admin CORS group")
+	}
+}
@@ -0,0 +1,35 @@
+package authz
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CORS picks the CORS middleware for each request by its route's auth type,
+// so admin routes can admit only the dashboard while public routes admit the
+// marketing site. Preflights are matched on Access-Control-Request-Method.
+// Routes whose auth type has no entry, and unknown routes, get no CORS
+// headers.
+func (t Table) CORS(mux *chi.Mux, groups map[AuthType]func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := make(map[AuthType]http.Handler, len(groups))
+		for auth, mw := range groups {
+			wrapped[auth] = mw(next)
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := r.Method
+			if preflight := r.Header.Get("Access-Control-Request-Method"); method == http.MethodOptions && preflight != "" {
+				method = preflight
+			}
+			if pattern := mux.Find(chi.NewRouteContext(), method, routePath(r)); pattern != "" {
+				if h, ok := wrapped[t[routeKey(method, pattern)].Auth]; ok {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
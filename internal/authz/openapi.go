@@ -0,0 +1,101 @@
+package authz
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pathParam matches chi URL parameters, with an optional regexp: {id} or
+// {id:[0-9]+}.
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// securitySchemes describes how each auth type is presented. Public routes
+// have no scheme.
+var securitySchemes = map[AuthType]map[string]interface{}{
+	User:        {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+	Admin:       {"type": "apiKey", "in": "header", "name": "X-Admin-Key"},
+	Integration: {"type": "apiKey", "in": "header", "name": "X-API-Key"},
+	SCIM:        {"type": "http", "scheme": "bearer", "description": "The organization's SCIM token"},
+	Sandbox:     {"type": "apiKey", "in": "header", "name": "X-Sandbox-Key"},
+}
+
+// OpenAPI returns an OpenAPI 3 skeleton of the registered routes: paths,
+// parameters, auth and the permission, entitlement, rate limit and timeout
+// as x- extensions. Request and response bodies are left for the docs to fill
+// in.
+func (g *Registry) OpenAPI(title, version string) map[string]interface{} {
+	paths := map[string]map[string]interface{}{}
+	operationIDs := map[string]int{}
+	for _, route := range g.Routes() {
+		path := pathParam.ReplaceAllString(route.Pattern, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+
+		// Handlers serving several routes still need distinct operation IDs
+		operationID := route.Handler
+		if operationIDs[route.Handler]++; operationIDs[route.Handler] > 1 {
+			operationID += strconv.Itoa(operationIDs[route.Handler])
+		}
+		op := map[string]interface{}{
+			"operationId": operationID,
+			"tags":        []string{tag(route.Pattern)},
+			"responses":   map[string]interface{}{"default": map[string]interface{}{"description": "See the endpoint docs"}},
+		}
+		var params []map[string]interface{}
+		for _, m := range pathParam.FindAllStringSubmatch(route.Pattern, -1) {
+			params = append(params, map[string]interface{}{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]string{"type": "string"},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if route.Policy.Auth == Public {
+			op["security"] = []interface{}{}
+		} else {
+			op["security"] = []map[string][]string{{string(route.Policy.Auth): {}}}
+		}
+		if route.Policy.Permission != "" {
+			op["x-permission"] = route.Policy.Permission
+		}
+		if route.Policy.Entitlement != "" {
+			op["x-entitlement"] = route.Policy.Entitlement
+		}
+		if route.Policy.Sandboxed {
+			op["x-sandbox"] = true
+		}
+		if route.RateLimit != "" {
+			op["x-rate-limit"] = route.RateLimit
+		}
+		if route.TimeoutSeconds > 0 {
+			op["x-timeout-seconds"] = route.TimeoutSeconds
+		}
+		paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	schemes := map[string]interface{}{}
+	for auth, scheme := range securitySchemes {
+		schemes[string(auth)] = scheme
+	}
+	return map[string]interface{}{
+		"openapi":    "3.0.3",
+		"info":       map[string]string{"title": title, "version": version},
+		"paths":      paths,
+		"components": map[string]interface{}{"securitySchemes": schemes},
+	}
+}
+
+// tag groups operations by their first path segment, or the first two for
+// admin routes: "/admin/users/{id}" is "admin/users".
+func tag(pattern string) string {
+	parts := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	if parts[0] == "admin" && len(parts) > 1 {
+		return "admin/" + parts[1]
+	}
+	return parts[0]
+}
@@ -0,0 +1,183 @@
+package authz
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RateLimitTier is the per-plan API limit the user and integration
+// authenticators apply. Routes with those auth types get it by default.
+const RateLimitTier = "tier"
+
+// Route declares one endpoint: what serves it, what it requires, which
+// rate limit applies and how long it may take.
+type Route struct {
+	Method    string `json:"method"`
+	Pattern   string `json:"pattern"`
+	Handler   string `json:"handler"`
+	Policy    Policy `json:"policy"`
+	RateLimit string `json:"rate_limit,omitempty"`
+	// Zero when the route has no timeout
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty"`
+}
+
+// Registry records every route as it is mounted on the router, so the
+// policy table, the route listing and the API docs come from the same
+// declarations as the router itself.
+type Registry struct {
+	routes   []Route
+	policies Table
+	timeout  time.Duration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{policies: Table{}}
+}
+
+// WithTimeout sets the timeout routes get unless they set their own; see
+// Router.Timeout. Call it before On.
+func (g *Registry) WithTimeout(d time.Duration) *Registry {
+	g.timeout = d
+	return g
+}
+
+// Policies returns the table Enforce, CORS and Verify read. It fills in as
+// routes are mounted, so it can be handed to middleware before any route
+// exists.
+func (g *Registry) Policies() Table {
+	return g.policies
+}
+
+// Routes returns the declared routes sorted by pattern, then method.
+func (g *Registry) Routes() []Route {
+	routes := append([]Route(nil), g.routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// On wraps a chi router so routes mounted through it are recorded.
+func (g *Registry) On(r chi.Router) *Router {
+	return &Router{mux: r, registry: g, timeout: g.timeout}
+}
+
+// Router mirrors the parts of chi.Router the server uses, but every route
+// takes its policy alongside its handler.
+type Router struct {
+	mux       chi.Router
+	registry  *Registry
+	prefix    string
+	rateLimit string
+	timeout   time.Duration
+}
+
+// Use appends middleware to the router's stack, as chi.Router.Use.
+func (r *Router) Use(middlewares ...func(http.Handler) http.Handler) {
+	r.mux.Use(middlewares...)
+}
+
+// With returns a router that runs the middleware for its routes only.
+func (r *Router) With(middlewares ...func(http.Handler) http.Handler) *Router {
+	return &Router{mux: r.mux.With(middlewares...), registry: r.registry, prefix: r.prefix, rateLimit: r.rateLimit, timeout: r.timeout}
+}
+
+// RateLimited names the rate limit the next routes enforce themselves
+// (in a handler, service or middleware passed to With).
+func (r *Router) RateLimited(name string) *Router {
+	return &Router{mux: r.mux, registry: r.registry, prefix: r.prefix, rateLimit: name, timeout: r.timeout}
+}
+
+// Timeout overrides the registry's timeout for the next routes. Zero turns
+// it off, for streams and routes that wait on purpose.
+func (r *Router) Timeout(d time.Duration) *Router {
+	return &Router{mux: r.mux, registry: r.registry, prefix: r.prefix, rateLimit: r.rateLimit, timeout: d}
+}
+
+// Group mounts routes that share middleware, as chi.Router.Group.
+func (r *Router) Group(fn func(r *Router)) {
+	r.mux.Group(func(mux chi.Router) {
+		fn(&Router{mux: mux, registry: r.registry, prefix: r.prefix, rateLimit: r.rateLimit, timeout: r.timeout})
+	})
+}
+
+// Route mounts routes under a path prefix, as chi.Router.Route.
+func (r *Router) Route(pattern string, fn func(r *Router)) {
+	r.mux.Route(pattern, func(mux chi.Router) {
+		fn(&Router{mux: mux, registry: r.registry, prefix: r.prefix + pattern, rateLimit: r.rateLimit, timeout: r.timeout})
+	})
+}
+
+func (r *Router) Get(pattern string, h http.HandlerFunc, policy Policy) {
+	r.handle(http.MethodGet, pattern, h, policy)
+}
+
+func (r *Router) Post(pattern string, h http.HandlerFunc, policy Policy) {
+	r.handle(http.MethodPost, pattern, h, policy)
+}
+
+func (r *Router) Put(pattern string, h http.HandlerFunc, policy Policy) {
+	r.handle(http.MethodPut, pattern, h, policy)
+}
+
+func (r *Router) Patch(pattern string, h http.HandlerFunc, policy Policy) {
+	r.handle(http.MethodPatch, pattern, h, policy)
+}
+
+func (r *Router) Delete(pattern string, h http.HandlerFunc, policy Policy) {
+	r.handle(http.MethodDelete, pattern, h, policy)
+}
+
+func (r *Router) handle(method, pattern string, h http.HandlerFunc, policy Policy) {
+	full := r.prefix + pattern
+	name := handlerName(h)
+	if r.timeout > 0 {
+		r.mux.With(middleware.NewRequestTimeout(routeKey(method, full), r.timeout).Handler).Method(method, pattern, h)
+	} else {
+		r.mux.Method(method, pattern, h)
+	}
+
+	rateLimit := r.rateLimit
+	if rateLimit == "" && (policy.Auth == User || policy.Auth == Integration) {
+		rateLimit = RateLimitTier
+	}
+	r.registry.routes = append(r.registry.routes, Route{
+		Method:         method,
+		Pattern:        full,
+		Handler:        name,
+		Policy:         policy,
+		RateLimit:      rateLimit,
+		TimeoutSeconds: r.timeout.Seconds(),
+	})
+	r.registry.policies[routeKey(method, full)] = policy
+}
+
+// handlerName turns a method value's symbol, e.g.
+// "rizon-backend/internal/handlers.(*AuthHandler).Refresh-fm", into
+// "AuthHandler.Refresh".
+func handlerName(h http.HandlerFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if _, rest, ok := strings.Cut(name, "."); ok {
+		name = rest
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
@@ -0,0 +1,49 @@
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TestRegistryEnforcesEncodedPaths mounts admin {param} routes through the
+// registry, as the server does, and checks an encoded slash in the param
+// can't slip past their policy.
+func TestRegistryEnforcesEncodedPaths(t *testing.T) {
+	registry := NewRegistry()
+	mux := chi.NewRouter()
+	denyAll := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	}
+	auth := Authenticators{Admin: denyAll}
+	mux.Use(registry.Policies().Enforce(mux, auth, nil))
+
+	served := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	api := registry.On(mux)
+	api.Route("/admin", func(r *Router) {
+		r.Put("/flags/{key}", served, Policy{Auth: Admin, Permission: "flags:write"})
+		r.Put("/experiments/{key}", served, Policy{Auth: Admin, Permission: "experiments:write"})
+		r.Delete("/email/suppressions/{email}", served, Policy{Auth: Admin, Permission: "users:write"})
+		r.Delete("/abuse/{ip}", served, Policy{Auth: Admin, Permission: "ops:write"})
+	})
+	if err := registry.Policies().Verify(mux, auth); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ method, target string }{
+		{http.MethodPut, "/admin/flags/a%2Fb"},
+		{http.MethodPut, "/admin/experiments/%2F"},
+		{http.MethodDelete, "/admin/email/suppressions/a%2Fb%40example.com"},
+		{http.MethodDelete, "/admin/abuse/%2F%2F"},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Package backfill applies a per-document change to every matching document
+// in a collection: in _id order, in batches, optionally rate limited, with a
+// checkpoint saved after each batch so an interrupted run picks up where it
+// stopped.
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rizon-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Backfill describes one data change.
+type Backfill struct {
+	Name        string
+	Description string
+	Collection  string
+	Filter      bson.M // documents that still need the change
+	Projection  bson.M // fields Update needs; nil loads whole documents
+	// Update returns the update document for doc, or nil to leave it alone.
+	Update func(doc bson.M) (bson.M, error)
+}
+
+// Options tune a run.
+type Options struct {
+	DryRun    bool    // count what would change without writing anything
+	BatchSize int     // documents per round trip; defaults to 500
+	Rate      float64 // max documents per second; 0 means unlimited
+	Reset     bool    // ignore the saved checkpoint and start over
+	// Progress is called after every batch with the running totals and the
+	// number of matching documents there were at the start.
+	Progress func(stats Stats, total int64)
+}
+
+// Stats are the totals of a run, also saved as its checkpoint.
+type Stats struct {
+	Scanned int64         `bson:"scanned" json:"scanned"`
+	Updated int64         `bson:"updated" json:"updated"` // would-update in a dry run
+	Skipped int64         `bson:"skipped" json:"skipped"`
+	LastID  bson.ObjectID `bson:"last_id" json:"last_id"`
+}
+
+// Checkpoint is the saved state of a backfill, keyed by name.
+type Checkpoint struct {
+	Name        string     `bson:"_id" json:"name"`
+	Stats       Stats      `bson:"stats" json:"stats"`
+	StartedAt   time.Time  `bson:"started_at" json:"started_at"`
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+const defaultBatchSize = 500
+
+func checkpoints() *mongo.Collection {
+	return database.GetCollection("backfill_checkpoints")
+}
+
+// LoadCheckpoint returns the saved state of the named backfill, or nil.
+func LoadCheckpoint(ctx context.Context, name string) (*Checkpoint, error) {
+	var cp Checkpoint
+	err := checkpoints().FindOne(ctx, bson.M{"_id": name}).Decode(&cp)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// Run applies b. Dry runs read the checkpoint but never save one. A
+// cancelled ctx stops the run after the current batch is checkpointed.
+func Run(ctx context.Context, b Backfill, opts Options) (Stats, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	cp := &Checkpoint{Name: b.Name, StartedAt: time.Now()}
+	if !opts.Reset {
+		saved, err := LoadCheckpoint(ctx, b.Name)
+		if err != nil {
+			return Stats{}, fmt.Errorf("loading checkpoint: %w", err)
+		}
+		if saved != nil {
+			cp = saved
+			cp.CompletedAt = nil
+		}
+	}
+	stats := cp.Stats
+
+	coll := database.GetCollection(b.Collection)
+	total, err := coll.CountDocuments(ctx, afterID(b.Filter, stats.LastID))
+	if err != nil {
+		return stats, fmt.Errorf("counting documents: %w", err)
+	}
+
+	find := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(batchSize))
+	if b.Projection != nil {
+		find.SetProjection(b.Projection)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		started := time.Now()
+
+		cursor, err := coll.Find(ctx, afterID(b.Filter, stats.LastID), find)
+		if err != nil {
+			return stats, fmt.Errorf("reading batch: %w", err)
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return stats, fmt.Errorf("reading batch: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+
+		var writes []mongo.WriteModel
+		for _, doc := range docs {
+			id, ok := doc["_id"].(bson.ObjectID)
+			if !ok {
+				return stats, fmt.Errorf("document %v has a non-ObjectID _id", doc["_id"])
+			}
+			update, err := b.Update(doc)
+			if err != nil {
+				return stats, fmt.Errorf("document %s: %w", id.Hex(), err)
+			}
+			stats.Scanned++
+			stats.LastID = id
+			if update == nil {
+				stats.Skipped++
+				continue
+			}
+			stats.Updated++
+			writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(update))
+		}
+
+		if !opts.DryRun {
+			if len(writes) > 0 {
+				if _, err := coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+					return stats, fmt.Errorf("writing batch: %w", err)
+				}
+			}
+			cp.Stats = stats
+			if err := save(ctx, cp); err != nil {
+				return stats, err
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(stats, total)
+		}
+
+		if opts.Rate > 0 {
+			minDuration := time.Duration(float64(len(docs)) / opts.Rate * float64(time.Second))
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-time.After(minDuration - time.Since(started)):
+			}
+		}
+	}
+
+	if !opts.DryRun {
+		now := time.Now()
+		cp.Stats = stats
+		cp.CompletedAt = &now
+		if err := save(ctx, cp); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// afterID narrows filter to documents past the checkpoint.
+func afterID(filter bson.M, lastID bson.ObjectID) bson.M {
+	if lastID.IsZero() {
+		return filter
+	}
+	narrowed := bson.M{"_id": bson.M{"$gt": lastID}}
+	if len(filter) > 0 {
+		narrowed = bson.M{"$and": bson.A{filter, narrowed}}
+	}
+	return narrowed
+}
+
+func save(ctx context.Context, cp *Checkpoint) error {
+	cp.UpdatedAt = time.Now()
+	_, err := checkpoints().ReplaceOne(ctx, bson.M{"_id": cp.Name}, cp, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package backfill
+
+import (
+	"sort"
+
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// All lists the backfills the CLI and admin maintenance tasks can run. Add
+// new ones here when a field is introduced.
+var All = []Backfill{
+	{
+		Name:        "feedback-source",
+		Description: `Set source "app" on feedback stored before sources were recorded`,
+		Collection:  "feedbacks",
+		Filter:      bson.M{"source": bson.M{"$exists": false}},
+		Projection:  bson.M{"_id": 1},
+		Update: func(bson.M) (bson.M, error) {
+			return bson.M{"$set": bson.M{"source": models.FeedbackSourceApp}}, nil
+		},
+	},
+}
+
+// Find returns the named backfill.
+func Find(name string) (Backfill, bool) {
+	for _, b := range All {
+		if b.Name == name {
+			return b, true
+		}
+	}
+	return Backfill{}, false
+}
+
+// Names lists the registered backfills in order.
+func Names() []string {
+	names := make([]string, len(All))
+	for i, b := range All {
+		names[i] = b.Name
+	}
+	sort.Strings(names)
+	return names
+}
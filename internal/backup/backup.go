@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DefaultCollections are the collections worth restoring after data loss.
+// Ephemeral collections (tokens, nonces, locks, rate limits) are left out.
+// data_keys must travel with feedbacks or encrypted fields become unreadable.
+var DefaultCollections = []string{
+	"users",
+	"feedbacks",
+	"data_keys",
+	"quotas",
+	"admin_api_keys",
+	"usage_daily",
+	"email_suppressions",
+	"roadmap_items",
+	"roadmap_votes",
+}
+
+// record is one line of a dump: a document tagged with its collection,
+// encoded as canonical Extended JSON so types round-trip exactly.
+type record struct {
+	Collection string          `json:"c"`
+	Document   json.RawMessage `json:"d"`
+}
+
+// Stats summarises a dump or restore per collection.
+type Stats map[string]int
+
+// CollectionFunc resolves a collection by name, e.g. database.GetCollection,
+// so dumps follow the primary/secondary routing.
+type CollectionFunc func(name string) *mongo.Collection
+
+// Dump writes the given collections to w as gzipped newline-delimited JSON.
+func Dump(ctx context.Context, collection CollectionFunc, collections []string, w io.Writer) (Stats, error) {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	stats := Stats{}
+
+	for _, name := range collections {
+		cursor, err := collection(name).Find(ctx, bson.M{})
+		if err != nil {
+			return stats, fmt.Errorf("find %s: %w", name, err)
+		}
+		for cursor.Next(ctx) {
+			doc, err := bson.MarshalExtJSON(cursor.Current, true, false)
+			if err != nil {
+				cursor.Close(ctx)
+				return stats, fmt.Errorf("encode %s: %w", name, err)
+			}
+			if err := enc.Encode(record{Collection: name, Document: doc}); err != nil {
+				cursor.Close(ctx)
+				return stats, err
+			}
+			stats[name]++
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return stats, fmt.Errorf("read %s: %w", name, err)
+		}
+	}
+
+	return stats, gz.Close()
+}
+
+// Restore reads a dump produced by Dump and upserts every document by _id.
+// If only is non-empty, records for other collections are skipped.
+func Restore(ctx context.Context, collection CollectionFunc, r io.Reader, only []string) (Stats, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open dump: %w", err)
+	}
+	defer gz.Close()
+
+	allowed := map[string]bool{}
+	for _, name := range only {
+		allowed[name] = true
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 32*1024*1024) // Mongo documents cap at 16MB
+	stats := Stats{}
+
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return stats, fmt.Errorf("decode line: %w", err)
+		}
+		if len(allowed) > 0 && !allowed[rec.Collection] {
+			continue
+		}
+
+		var doc bson.D
+		if err := bson.UnmarshalExtJSON(rec.Document, true, &doc); err != nil {
+			return stats, fmt.Errorf("decode %s document: %w", rec.Collection, err)
+		}
+		var id any
+		for _, field := range doc {
+			if field.Key == "_id" {
+				id = field.Value
+				break
+			}
+		}
+		if id == nil {
+			return stats, fmt.Errorf("%s document without _id", rec.Collection)
+		}
+
+		_, err := collection(rec.Collection).ReplaceOne(ctx,
+			bson.M{"_id": id}, doc, options.Replace().SetUpsert(true))
+		if err != nil {
+			return stats, fmt.Errorf("restore %s: %w", rec.Collection, err)
+		}
+		stats[rec.Collection]++
+	}
+
+	return stats, scanner.Err()
+}
@@ -0,0 +1,36 @@
+package buildinfo
+
+import (
+	"runtime"
+	"time"
+)
+
+// Set at build time via:
+//
+//	go build -ldflags "-X rizon-backend/internal/buildinfo.Commit=<sha> -X rizon-backend/internal/buildinfo.BuildTime=<rfc3339>"
+var (
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+var startedAt = time.Now()
+
+// Info describes the running binary.
+type Info struct {
+	Commit        string `json:"commit"`
+	BuildTime     string `json:"build_time"`
+	GoVersion     string `json:"go_version"`
+	StartedAt     string `json:"started_at"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// Get returns the build metadata along with the current process uptime.
+func Get() Info {
+	return Info{
+		Commit:        Commit,
+		BuildTime:     BuildTime,
+		GoVersion:     runtime.Version(),
+		StartedAt:     startedAt.UTC().Format(time.RFC3339),
+		UptimeSeconds: int64(time.Since(startedAt).Seconds()),
+	}
+}
@@ -0,0 +1,87 @@
+// Package bulkmail sends non-transactional email (announcements, digests,
+// campaigns) through the job queue, where the Throttle keeps it inside
+// provider rate limits and recipients' quiet hours. Suppressed addresses are
+// dropped when the job runs.
+package bulkmail
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// JobType is the job queue type for queued email.
+const JobType = "email"
+
+// Payload is stored on the email job: the rendered message plus where the
+// recipient is, for quiet hours.
+type Payload struct {
+	To       string `bson:"to"`
+	Subject  string `bson:"subject"`
+	HTML     string `bson:"html"`
+	Text     string `bson:"text"`
+	Country  string `bson:"country,omitempty"`
+	TimeZone string `bson:"time_zone,omitempty"`
+}
+
+// Enqueue queues msg for user. createdBy names the campaign or admin that
+// sent it.
+func Enqueue(ctx context.Context, queue *jobs.Queue, user *models.User, msg *mailer.Message, createdBy string) (*models.Job, error) {
+	payload := Payload{
+		To:       msg.To,
+		Subject:  msg.Subject,
+		HTML:     msg.HTML,
+		Text:     msg.Text,
+		Country:  user.AgeCountry,
+		TimeZone: user.TimeZone,
+	}
+	if payload.Country == "" && user.SignupGeo != nil {
+		payload.Country = user.SignupGeo.Country
+	}
+	return queue.Enqueue(ctx, JobType, payload, createdBy)
+}
+
+// Handler sends queued email, deferring jobs the throttle holds back and
+// skipping suppressed recipients.
+func Handler(mail *mailer.Mailer, throttle *Throttle, suppressions *repository.EmailSuppressionRepo) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) (bson.M, error) {
+		var payload Payload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid payload: %v", jobs.ErrPermanent, err)
+		}
+
+		suppressed, err := suppressions.IsSuppressed(ctx, payload.To)
+		if err != nil {
+			return nil, fmt.Errorf("checking email suppression: %w", err)
+		}
+		if suppressed {
+			return bson.M{"suppressed": true}, nil
+		}
+
+		until, err := throttle.Hold(ctx, mail.Active(), payload.Country, payload.TimeZone, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("checking email throttle: %w", err)
+		}
+		if !until.IsZero() {
+			return nil, jobs.Defer(until)
+		}
+
+		delivery, err := mail.Send(ctx, &mailer.Message{
+			To:      payload.To,
+			Subject: payload.Subject,
+			HTML:    payload.HTML,
+			Text:    payload.Text,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"provider": delivery.Provider, "message_id": delivery.MessageID}, nil
+	}
+}
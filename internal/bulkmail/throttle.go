@@ -0,0 +1,181 @@
+package bulkmail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/ratelimit"
+	"rizon-backend/internal/repository"
+)
+
+// Throttle keeps the admin-set sending limits in memory and decides when a
+// queued email may go out. Per-minute limits are counted in Mongo, so they
+// hold across replicas.
+type Throttle struct {
+	repo     *repository.EmailThrottleRepo
+	limiter  *ratelimit.Limiter
+	defaults models.EmailThrottle
+
+	mu       sync.RWMutex
+	settings models.EmailThrottle
+}
+
+// NewThrottle uses defaults until an admin saves settings of their own.
+func NewThrottle(repo *repository.EmailThrottleRepo, limiter *ratelimit.Limiter, defaults models.EmailThrottle) *Throttle {
+	return &Throttle{repo: repo, limiter: limiter, defaults: defaults, settings: defaults}
+}
+
+// Refresh reloads the settings from Mongo.
+func (t *Throttle) Refresh(ctx context.Context) error {
+	saved, err := t.repo.Get(ctx)
+	if err != nil {
+		return err
+	}
+	settings := t.defaults
+	if saved != nil {
+		settings = *saved
+	}
+	t.mu.Lock()
+	t.settings = settings
+	t.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the settings every interval until ctx is cancelled.
+func (t *Throttle) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Error refreshing email throttle: %v", err)
+			}
+		}
+	}
+}
+
+// Settings returns the settings in effect on this replica.
+func (t *Throttle) Settings() models.EmailThrottle {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.settings
+}
+
+// Hold returns when an email to a recipient in country and timeZone may be
+// sent through provider, or the zero time if it may go now. A send that is
+// allowed counts against the provider's per-minute limit.
+func (t *Throttle) Hold(ctx context.Context, provider, country, timeZone string, now time.Time) (time.Time, error) {
+	settings := t.Settings()
+
+	if until := quietUntil(settings.QuietHours, country, timeZone, now); !until.IsZero() {
+		return until, nil
+	}
+
+	limit, ok := settings.MaxPerMinute[provider]
+	if !ok {
+		limit = settings.MaxPerMinute["*"]
+	}
+	if limit <= 0 {
+		return time.Time{}, nil
+	}
+	result, err := t.limiter.Allow(ctx, "email:"+provider, int64(limit), time.Minute)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !result.Allowed {
+		return result.ResetAt, nil
+	}
+	return time.Time{}, nil
+}
+
+// quietUntil returns the end of the quiet window the recipient is in, or the
+// zero time. A region-specific window replaces the "*" one. Recipients with
+// an unknown time zone are treated as UTC.
+func quietUntil(windows []models.QuietHours, country, timeZone string, now time.Time) time.Time {
+	var match *models.QuietHours
+	for i, w := range windows {
+		if strings.EqualFold(w.Region, country) && country != "" {
+			match = &windows[i]
+			break
+		}
+		if w.Region == "*" && match == nil {
+			match = &windows[i]
+		}
+	}
+	if match == nil {
+		return time.Time{}
+	}
+
+	start, err1 := parseClock(match.Start)
+	end, err2 := parseClock(match.End)
+	if err1 != nil || err2 != nil || start == end {
+		return time.Time{}
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "" {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endToday := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endToday
+	case start > end && minute >= start:
+		return endToday.AddDate(0, 0, 1)
+	case start > end && minute < end:
+		return endToday
+	}
+	return time.Time{}
+}
+
+// parseClock turns HH:MM into minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks settings an admin wants to save, normalising regions.
+func Validate(settings *models.EmailThrottle) error {
+	for provider, limit := range settings.MaxPerMinute {
+		if provider == "" {
+			return errors.New("provider name is required")
+		}
+		if limit < 0 {
+			return fmt.Errorf("max_per_minute for %s must not be negative", provider)
+		}
+	}
+	seen := map[string]bool{}
+	for i, w := range settings.QuietHours {
+		region := strings.ToUpper(strings.TrimSpace(w.Region))
+		if region != "*" && len(region) != 2 {
+			return errors.New("quiet hours region must be a two-letter country code or *")
+		}
+		settings.QuietHours[i].Region = region
+		if seen[region] {
+			return fmt.Errorf("quiet hours for %s are set twice", region)
+		}
+		seen[region] = true
+		if _, err := parseClock(w.Start); err != nil {
+			return err
+		}
+		if _, err := parseClock(w.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package campaign sends an announcement email to every user in an audience.
+// The campaign job only fans out: each recipient gets their own bulkmail job,
+// so throttling, quiet hours and retries work as for any other bulk email.
+package campaign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"rizon-backend/internal/bulkmail"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/templates"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// JobType is the job queue type for campaign fan-out.
+const JobType = "campaign"
+
+// Payload is stored on the campaign job. Template data is rendered once per
+// recipient so templates can address the user.
+type Payload struct {
+	Audience  models.AudienceFilter `bson:"audience"`
+	Subject   string                `bson:"subject"`
+	Message   string                `bson:"message"`
+	LinkURL   string                `bson:"link_url,omitempty"`
+	LinkLabel string                `bson:"link_label,omitempty"`
+}
+
+// Handler returns the job handler. Users who turned product emails off are
+// skipped. Once any email has been queued a failure is permanent, so a retry
+// never emails the same user twice.
+func Handler(users *repository.UserRepo, queue *jobs.Queue) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) (bson.M, error) {
+		var payload Payload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid payload: %v", jobs.ErrPermanent, err)
+		}
+
+		total, err := users.CountAudience(ctx, payload.Audience)
+		if err != nil {
+			return nil, err
+		}
+
+		createdBy := "campaign:" + job.ID.Hex()
+		var queued, skipped int64
+		err = users.EachInAudience(ctx, payload.Audience, func(user *models.User) error {
+			defer func() {
+				jobs.ReportProgress(ctx, queued+skipped, total, fmt.Sprintf("%d emails queued", queued))
+			}()
+			if p := user.Preferences; p != nil && p.ProductEmails != nil && !*p.ProductEmails {
+				skipped++
+				return nil
+			}
+			content, err := templates.RenderEmail("announcement", templates.EmailOptionsFor(user, ""), map[string]interface{}{
+				"Subject":   payload.Subject,
+				"Message":   payload.Message,
+				"LinkURL":   payload.LinkURL,
+				"LinkLabel": payload.LinkLabel,
+				"Brand":     templates.DefaultBrand,
+			})
+			if err != nil {
+				return fmt.Errorf("%w: %v", jobs.ErrPermanent, err)
+			}
+			if _, err := bulkmail.Enqueue(ctx, queue, user, mailer.FromRendered(user.Email, content), createdBy); err != nil {
+				return err
+			}
+			queued++
+			return nil
+		})
+		if err != nil {
+			if queued > 0 && !errors.Is(err, jobs.ErrPermanent) {
+				err = fmt.Errorf("%w: stopped after queueing %d emails: %v", jobs.ErrPermanent, queued, err)
+			}
+			return nil, err
+		}
+		return bson.M{"audience": total, "queued": queued, "skipped": skipped}, nil
+	}
+}
@@ -0,0 +1,84 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"rizon-backend/internal/httpclient"
+)
+
+// ErrFailed is returned when the provider rejects the token.
+var ErrFailed = errors.New("captcha verification failed")
+
+// verifyURLs are the siteverify endpoints of supported providers. All three
+// take the same form fields and return {"success": bool}.
+var verifyURLs = map[string]string{
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+}
+
+// Verifier checks captcha tokens solved in the browser.
+type Verifier struct {
+	provider  string
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// New creates a verifier for provider ("turnstile", "hcaptcha" or "recaptcha").
+func New(provider, secret string) (*Verifier, error) {
+	verifyURL, ok := verifyURLs[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown captcha provider %q", provider)
+	}
+	return &Verifier{
+		provider:  provider,
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    httpclient.New("captcha", httpclient.Options{}),
+	}, nil
+}
+
+func (v *Verifier) Provider() string { return v.provider }
+
+// Verify checks a token with the provider. remoteIP is optional.
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrFailed
+	}
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s siteverify: %w", v.provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s siteverify: status %d", v.provider, resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%s siteverify: %w", v.provider, err)
+	}
+	if !result.Success {
+		return ErrFailed
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package changestream
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Event is a single insert/update/replace/delete observed on a watched collection.
+type Event struct {
+	Collection string
+	Operation  string
+	DocumentID string
+	Document   bson.M // nil for deletes
+}
+
+// Handler receives change events. Handlers run on the watcher goroutine and
+// must not block (hand off to the realtime hub, invalidate caches, etc.).
+type Handler func(Event)
+
+// Watcher tails Mongo change streams for a set of collections and persists
+// resume tokens so a restarted instance doesn't miss events. Change streams
+// require a replica set (Atlas clusters are replica sets).
+type Watcher struct {
+	tokenRepo *repository.ResumeTokenRepo
+
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewWatcher(tokenRepo *repository.ResumeTokenRepo) *Watcher {
+	return &Watcher{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Subscribe registers a handler for all watched collections.
+func (w *Watcher) Subscribe(h Handler) {
+	w.mu.Lock()
+	w.handlers = append(w.handlers, h)
+	w.mu.Unlock()
+}
+
+// Run watches the given collections until ctx is cancelled, reconnecting with
+// backoff on errors.
+func (w *Watcher) Run(ctx context.Context, collections ...string) {
+	var wg sync.WaitGroup
+	for _, name := range collections {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			w.watchLoop(ctx, name)
+		}(name)
+	}
+	wg.Wait()
+}
+
+func (w *Watcher) watchLoop(ctx context.Context, name string) {
+	backoff := time.Second
+	for {
+		err := w.watch(ctx, name)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("⚠️  Change stream on %s stopped: %v (retrying in %s)", name, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+func (w *Watcher) watch(ctx context.Context, name string) error {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	token, err := w.tokenRepo.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if token != nil {
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := database.GetCollection(name).Watch(ctx, mongo.Pipeline{}, opts)
+	if err != nil {
+		// The stored token fell off the oplog; start fresh rather than looping forever
+		var serverErr mongo.ServerError
+		if token != nil && errors.As(err, &serverErr) && (serverErr.HasErrorCode(286) || serverErr.HasErrorCode(280)) {
+			log.Printf("⚠️  Resume token for %s is no longer valid, starting from now", name)
+			_ = w.tokenRepo.Delete(ctx, name)
+		}
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	log.Printf("👀 Watching %s for changes", name)
+	for stream.Next(ctx) {
+		var change struct {
+			OperationType string `bson:"operationType"`
+			DocumentKey   struct {
+				ID bson.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+			FullDocument bson.M `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			log.Printf("Error decoding change event on %s: %v", name, err)
+			continue
+		}
+
+		w.dispatch(Event{
+			Collection: name,
+			Operation:  change.OperationType,
+			DocumentID: change.DocumentKey.ID.Hex(),
+			Document:   change.FullDocument,
+		})
+
+		if err := w.tokenRepo.Save(ctx, name, stream.ResumeToken()); err != nil {
+			log.Printf("Error saving resume token for %s: %v", name, err)
+		}
+	}
+	return stream.Err()
+}
+
+func (w *Watcher) dispatch(event Event) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, h := range w.handlers {
+		h(event)
+	}
+}
@@ -0,0 +1,121 @@
+// Package clock abstracts time so token expiry, rate limit windows and
+// scheduling can be driven by a Fake instead of the wall clock.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time and makes tickers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the part of time.Ticker callers use.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the wall clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// Fake is a clock that only moves when told to. Its tickers fire during
+// Advance, dropping ticks nobody is waiting for like time.Ticker does.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set moves the clock to t, firing tickers that come due on the way.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	for _, tk := range f.tickers {
+		tk.fire(t)
+	}
+}
+
+// Advance moves the clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Tickers counts the tickers that haven't been stopped, so tests can wait
+// for goroutines to create theirs before advancing.
+func (f *Fake) Tickers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.tickers)
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tk := &fakeTicker{fake: f, c: make(chan time.Time, 1), interval: d, next: f.now.Add(d)}
+	f.tickers = append(f.tickers, tk)
+	return tk
+}
+
+type fakeTicker struct {
+	fake     *Fake
+	c        chan time.Time
+	interval time.Duration
+	next     time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+func (t *fakeTicker) Stop() {
+	f := t.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, tk := range f.tickers {
+		if tk == t {
+			f.tickers = append(f.tickers[:i], f.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// fire delivers at most one tick however far the clock jumped.
+func (t *fakeTicker) fire(now time.Time) {
+	if now.Before(t.next) {
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+	for !now.Before(t.next) {
+		t.next = t.next.Add(t.interval)
+	}
+}
@@ -0,0 +1,80 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestFakeNow(t *testing.T) {
+	f := NewFake(epoch)
+	if got := f.Now(); !got.Equal(epoch) {
+		t.Fatalf("Now = %v, want %v", got, epoch)
+	}
+	f.Advance(90 * time.Second)
+	if got, want := f.Now(), epoch.Add(90*time.Second); !got.Equal(want) {
+		t.Errorf("after Advance: Now = %v, want %v", got, want)
+	}
+	f.Set(epoch)
+	if got := f.Now(); !got.Equal(epoch) {
+		t.Errorf("after Set: Now = %v, want %v", got, epoch)
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	tk := f.NewTicker(time.Minute)
+
+	f.Advance(time.Minute - time.Nanosecond)
+	expectNoTick(t, tk, "before the interval")
+
+	f.Advance(time.Nanosecond)
+	if got := expectTick(t, tk, "at the interval"); !got.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("tick at %v, want %v", got, epoch.Add(time.Minute))
+	}
+
+	// A jump over several intervals delivers one tick, like time.Ticker
+	f.Advance(5 * time.Minute)
+	expectTick(t, tk, "after a jump")
+	expectNoTick(t, tk, "after a jump's one tick")
+
+	// The schedule stays on the original grid: next due at epoch+7m
+	f.Set(epoch.Add(7*time.Minute - time.Second))
+	expectNoTick(t, tk, "before the next grid point")
+	f.Advance(time.Second)
+	expectTick(t, tk, "at the next grid point")
+
+	tk.Stop()
+	f.Advance(time.Hour)
+	expectNoTick(t, tk, "after Stop")
+}
+
+func TestFakeTickerRejectsNonPositiveInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewTicker(0) didn't panic")
+		}
+	}()
+	NewFake(epoch).NewTicker(0)
+}
+
+func expectTick(t *testing.T, tk Ticker, when string) time.Time {
+	t.Helper()
+	select {
+	case got := <-tk.C():
+		return got
+	default:
+		t.Fatalf("no tick %s", when)
+		return time.Time{}
+	}
+}
+
+func expectNoTick(t *testing.T, tk Ticker, when string) {
+	t.Helper()
+	select {
+	case got := <-tk.C():
+		t.Fatalf("unexpected tick %s: %v", when, got)
+	default:
+	}
+}
@@ -0,0 +1,247 @@
+// Package contracts publishes golden response fixtures for the endpoints the
+// mobile app depends on, one set per API version. The fixtures are generated
+// from the same models the handlers encode, so a field rename or removal shows
+// up as a fixture diff that both teams review before a release.
+//
+// Regenerate with `make contract-fixtures`; `make contract-test` fails when the
+// committed fixtures no longer match what the server would send.
+package contracts
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/apiversion"
+	"rizon-backend/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Dir is where the fixtures live, relative to the repository root.
+const Dir = "internal/contracts/fixtures"
+
+// Contract is one response shape the client relies on.
+type Contract struct {
+	Name   string `json:"name"` // fixture file name, without .json
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+	// Sample is a response with every field populated, so omitempty fields
+	// appear in the fixture too.
+	Sample func() interface{} `json:"-"`
+}
+
+// Versions are the API versions fixtures are published for.
+var Versions = []string{apiversion.V1, apiversion.V2}
+
+var (
+	sampleTime   = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sampleUserID = mustID("6632a0b0c1d2e3f4a5b6c7d8")
+	sampleID     = mustID("6632a0b0c1d2e3f4a5b6c7d9")
+)
+
+func mustID(hex string) bson.ObjectID {
+	id, err := bson.ObjectIDFromHex(hex)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func sampleUser() *models.User {
+	return &models.User{
+		ID:                  sampleUserID,
+		Email:               "jane@example.com",
+		OnboardingCompleted: true,
+		AgeBand:             "18-24",
+		AgeCountry:          "DE",
+		SignupGeo:           &models.GeoLocation{Country: "DE", Region: "BE", City: "Berlin", TimeZone: "Europe/Berlin"},
+		Locale:              "de-DE",
+		TimeZone:            "Europe/Berlin",
+		Status:              models.UserStatusActive,
+		LastSeenAt:          &sampleTime,
+		LastAppVersion:      "2.3.1",
+		AvatarID:            &sampleID,
+		CreatedAt:           sampleTime,
+		UpdatedAt:           sampleTime,
+	}
+}
+
+func sampleFeedback() *models.Feedback {
+	return &models.Feedback{
+		ID:             sampleID,
+		UserID:         sampleUserID,
+		Text:           "The timer resets when I lock my phone",
+		Rating:         4,
+		Category:       models.FeedbackCategoryBug,
+		Source:         models.FeedbackSourceApp,
+		Client:         &models.ClientInfo{AppVersion: "2.3.1", BuildNumber: "451", OS: "ios", OSVersion: "17.4", DeviceModel: "iPhone15,2"},
+		IdempotencyKey: "9b2f6c1e-2f0a-4c7e-9d51-0c5f4f7f2a10",
+		Status:         models.FeedbackStatusResolved,
+		ResolvedAt:     &sampleTime,
+		Reaction:       &models.FeedbackReaction{Value: models.ReactionUp, Comment: "Works now", CreatedAt: sampleTime},
+		CreatedAt:      sampleTime,
+	}
+}
+
+func sampleAttachment() *models.Attachment {
+	return &models.Attachment{
+		ID:          sampleID,
+		SHA256:      "3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b",
+		Filename:    "screenshot.jpg",
+		ContentType: "image/jpeg",
+		Size:        183204,
+		Variants: []models.ImageVariant{
+			{Name: "thumbnail", ContentType: "image/jpeg", Width: 256, Height: 192, Size: 9120, URL: "https://api.example.com/download/sample-token"},
+		},
+		CreatedAt: sampleTime,
+		URL:       "https://api.example.com/download/sample-token",
+	}
+}
+
+// All lists the published contracts.
+var All = []Contract{
+	{Name: "error", Method: "*", Path: "*", Status: 400, Sample: func() interface{} {
+		return map[string]string{"error": "invalid request body"}
+	}},
+	{Name: "auth_request", Method: "POST", Path: "/auth/request", Status: 200, Sample: func() interface{} {
+		return map[string]string{"message": "login link sent to your email"}
+	}},
+	{Name: "auth_verify", Method: "GET", Path: "/auth/verify", Status: 200, Sample: func() interface{} {
+		return map[string]interface{}{"token": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.sample.signature", "refresh_token": "rzr_sample", "expires_in": 900, "user": sampleUser()}
+	}},
+	{Name: "user_status", Method: "GET", Path: "/user/status", Status: 200, Sample: func() interface{} {
+		return map[string]interface{}{"onboarding_completed": true, "age_required": false}
+	}},
+	{Name: "feedback_submit", Method: "POST", Path: "/feedback", Status: 201, Sample: func() interface{} {
+		return map[string]interface{}{"message": "feedback submitted successfully", "feedback": sampleFeedback()}
+	}},
+	{Name: "feedback_follow_ups", Method: "GET", Path: "/feedback/follow-ups", Status: 200, Sample: func() interface{} {
+		return map[string]interface{}{"follow_ups": []*models.Feedback{sampleFeedback()}}
+	}},
+	{Name: "feedback_prompt", Method: "GET", Path: "/feedback/prompt", Status: 200, Sample: func() interface{} {
+		return map[string]interface{}{"show": true, "reason": "eligible"}
+	}},
+	{Name: "attachments_list", Method: "GET", Path: "/user/attachments", Status: 200, Sample: func() interface{} {
+		return map[string]interface{}{"attachments": []*models.Attachment{sampleAttachment()}, "used": 183204, "quota": 104857600}
+	}},
+}
+
+// Render encodes a contract's sample as its fixture for version.
+func Render(c Contract, version string) ([]byte, error) {
+	body, err := json.Marshal(c.Sample())
+	if err != nil {
+		return nil, err
+	}
+	if version == apiversion.V2 {
+		if body, err = apiversion.ToCamelCase(body); err != nil {
+			return nil, err
+		}
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, bytes.TrimSpace(body), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func fixturePath(version, name string) string {
+	return "v" + version + "/" + name + ".json"
+}
+
+// Fixture returns the committed fixture, as embedded in the binary.
+func Fixture(version, name string) ([]byte, error) {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(sub, fixturePath(version, name))
+}
+
+// Check compares every committed fixture in dir with what the server sends
+// now and describes each mismatch.
+func Check(dir string) ([]string, error) {
+	var problems []string
+	for _, version := range Versions {
+		for _, c := range All {
+			want, err := Render(c, version)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.Name, err)
+			}
+			path := filepath.Join(dir, filepath.FromSlash(fixturePath(version, c.Name)))
+			got, err := os.ReadFile(path)
+			switch {
+			case os.IsNotExist(err):
+				problems = append(problems, path+": missing")
+			case err != nil:
+				return nil, err
+			case !bytes.Equal(bytes.ReplaceAll(got, []byte("\r\n"), []byte("\n")), want):
+				problems = append(problems, path+": response shape changed")
+			}
+		}
+	}
+	return problems, nil
+}
+
+// Write regenerates every fixture in dir.
+func Write(dir string) error {
+	for _, version := range Versions {
+		if err := os.MkdirAll(filepath.Join(dir, "v"+version), 0o755); err != nil {
+			return err
+		}
+		for _, c := range All {
+			body, err := Render(c, version)
+			if err != nil {
+				return fmt.Errorf("%s: %w", c.Name, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(fixturePath(version, c.Name))), body, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Handler serves the fixtures at /__fixtures__ (an index) and
+// /__fixtures__/{version}/{name}. It answers 404 unless enabled, so the route
+// can stay registered in every environment.
+func Handler(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !enabled {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		version := strings.TrimPrefix(chi.URLParam(r, "version"), "v")
+		name := chi.URLParam(r, "name")
+		if version == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"versions":  Versions,
+				"contracts": All,
+			})
+			return
+		}
+
+		body, err := Fixture(version, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"fixture not found"}` + "\n"))
+			return
+		}
+		w.Write(body)
+	}
+}
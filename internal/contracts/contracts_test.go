@@ -0,0 +1,67 @@
+package contracts
+
+import (
+	"bytes"
+	"testing"
+
+	"rizon-backend/internal/apiversion"
+)
+
+// TestFixturesMatch fails when a model change alters a published response
+// shape without the fixtures being regenerated and reviewed.
+func TestFixturesMatch(t *testing.T) {
+	for _, version := range Versions {
+		for _, c := range All {
+			t.Run("v"+version+"/"+c.Name, func(t *testing.T) {
+				want, err := Fixture(version, c.Name)
+				if err != nil {
+					t.Fatalf("missing fixture: %v", err)
+				}
+				got, err := Render(c, version)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n")), got) {
+					t.Errorf("response shape changed; run `make contract-fixtures` if this is intended\ngot:\n%s", got)
+				}
+			})
+		}
+	}
+}
+
+// TestV1Routes pins the v1 routes and status codes. Changing one breaks
+// shipped clients, so it needs a new version rather than an edit here.
+func TestV1Routes(t *testing.T) {
+	want := map[string]struct {
+		method, path string
+		status       int
+	}{
+		"error":               {"*", "*", 400},
+		"auth_request":        {"POST", "/auth/request", 200},
+		"auth_verify":         {"GET", "/auth/verify", 200},
+		"user_status":         {"GET", "/user/status", 200},
+		"feedback_submit":     {"POST", "/feedback", 201},
+		"feedback_follow_ups": {"GET", "/feedback/follow-ups", 200},
+		"feedback_prompt":     {"GET", "/feedback/prompt", 200},
+		"attachments_list":    {"GET", "/user/attachments", 200},
+	}
+	seen := map[string]bool{}
+	for _, c := range All {
+		seen[c.Name] = true
+		w, ok := want[c.Name]
+		if !ok {
+			continue // new contracts are fine
+		}
+		if c.Method != w.method || c.Path != w.path || c.Status != w.status {
+			t.Errorf("%s: %s %s %d, want %s %s %d", c.Name, c.Method, c.Path, c.Status, w.method, w.path, w.status)
+		}
+	}
+	for name := range want {
+		if !seen[name] {
+			t.Errorf("%s: contract removed", name)
+		}
+	}
+	if apiversion.Default != apiversion.V1 {
+		t.Errorf("default version = %s, want %s", apiversion.Default, apiversion.V1)
+	}
+}
@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+type memoryKeys map[string]*WrappedKey
+
+func (m memoryKeys) GetDataKey(_ context.Context, ownerID string) (*WrappedKey, error) {
+	return m[ownerID], nil
+}
+
+func (m memoryKeys) CreateDataKey(_ context.Context, ownerID string, key *WrappedKey) (*WrappedKey, error) {
+	if existing := m[ownerID]; existing != nil {
+		return existing, nil
+	}
+	m[ownerID] = key
+	return key, nil
+}
+
+func (m memoryKeys) ListDataKeysNotWrappedWith(_ context.Context, version string, limit int) (map[string]*WrappedKey, error) {
+	keys := map[string]*WrappedKey{}
+	for owner, key := range m {
+		if key.Version != version && len(keys) < limit {
+			keys[owner] = key
+		}
+	}
+	return keys, nil
+}
+
+func (m memoryKeys) ReplaceDataKey(_ context.Context, ownerID string, key *WrappedKey) error {
+	m[ownerID] = key
+	return nil
+}
+
+func masterKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func mustKeyring(t *testing.T, spec, active string) *Keyring {
+	t.Helper()
+	kr, err := ParseKeyring(spec, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		active  string
+		wantErr bool
+	}{
+		{"one key", "v1:" + masterKey(1), "v1", false},
+		{"rotated", "v1:" + masterKey(1) + ", v2:" + masterKey(2), "v2", false},
+		{"active missing", "v1:" + masterKey(1), "v2", true},
+		{"no version", ":" + masterKey(1), "", true},
+		{"short key", "v1:" + base64.StdEncoding.EncodeToString([]byte("short")), "v1", true},
+		{"not base64", "v1:%%%", "v1", true},
+		{"empty", "", "v1", true},
+	}
+	for _, tt := range tests {
+		if _, err := ParseKeyring(tt.spec, tt.active); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKeyringWrap(t *testing.T) {
+	kr := mustKeyring(t, "v1:"+masterKey(1), "v1")
+	dataKey := bytes.Repeat([]byte{9}, 32)
+
+	wrapped, err := kr.Wrap(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrapped.Version != "v1" || bytes.Contains(wrapped.Ciphertext, dataKey) {
+		t.Fatalf("wrapped = %+v", wrapped)
+	}
+	if got, err := kr.Unwrap(wrapped); err != nil || !bytes.Equal(got, dataKey) {
+		t.Fatalf("Unwrap = %x, %v", got, err)
+	}
+
+	// The version is bound to the ciphertext
+	relabelled := mustKeyring(t, "v1:"+masterKey(1)+",v2:"+masterKey(1), "v2")
+	if _, err := relabelled.Unwrap(&WrappedKey{Version: "v2", Ciphertext: wrapped.Ciphertext}); err == nil {
+		t.Error("unwrapped under another version's label")
+	}
+	if _, err := kr.Unwrap(&WrappedKey{Version: "v9", Ciphertext: wrapped.Ciphertext}); err == nil {
+		t.Error("unwrapped with an unknown version")
+	}
+	if _, err := kr.Unwrap(&WrappedKey{Version: "v1", Ciphertext: wrapped.Ciphertext[:8]}); err == nil {
+		t.Error("unwrapped a truncated key")
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := memoryKeys{}
+	env := NewEnvelope(mustKeyring(t, "v1:"+masterKey(1), "v1"), store)
+
+	sealed, err := env.Encrypt(ctx, "user-1", "+44 7700 900000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(sealed) || strings.Contains(sealed, "7700") {
+		t.Fatalf("Encrypt = %q", sealed)
+	}
+	if got, err := env.Decrypt(ctx, "user-1", sealed); err != nil || got != "+44 7700 900000" {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+
+	// A value sealed for one owner can't be read as another's
+	if _, err := env.Encrypt(ctx, "user-2", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.Decrypt(ctx, "user-2", sealed); err == nil {
+		t.Error("decrypted another owner's value")
+	}
+
+	if got, _ := env.Decrypt(ctx, "user-1", "legacy plaintext"); got != "legacy plaintext" {
+		t.Errorf("legacy value = %q, want it passed through", got)
+	}
+	if got, _ := env.Encrypt(ctx, "user-1", ""); got != "" {
+		t.Errorf("empty value = %q, want empty", got)
+	}
+	if _, err := env.Decrypt(ctx, "nobody", sealed); err == nil {
+		t.Error("decrypted for an owner without a data key")
+	}
+	if _, err := env.Decrypt(ctx, "user-1", fieldPrefix+"%%%"); err == nil {
+		t.Error("decrypted a malformed value")
+	}
+}
+
+func TestRotateMasterKey(t *testing.T) {
+	ctx := context.Background()
+	store := memoryKeys{}
+	old := NewEnvelope(mustKeyring(t, "v1:"+masterKey(1), "v1"), store)
+	var sealed []string
+	for _, owner := range []string{"a", "b", "c"} {
+		value, err := old.Encrypt(ctx, owner, "secret "+owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sealed = append(sealed, value)
+	}
+
+	env := NewEnvelope(mustKeyring(t, "v1:"+masterKey(1)+",v2:"+masterKey(2), "v2"), store)
+	total := 0
+	for {
+		n, err := env.RotateMasterKey(ctx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+	if total != 3 {
+		t.Errorf("rotated %d keys, want 3", total)
+	}
+
+	// Only the new master key is needed afterwards
+	rotated := NewEnvelope(mustKeyring(t, "v2:"+masterKey(2), "v2"), store)
+	for i, owner := range []string{"a", "b", "c"} {
+		if store[owner].Version != "v2" {
+			t.Errorf("%s wrapped with %s", owner, store[owner].Version)
+		}
+		if got, err := rotated.Decrypt(ctx, owner, sealed[i]); err != nil || got != "secret "+owner {
+			t.Errorf("%s: Decrypt = %q, %v", owner, got, err)
+		}
+	}
+}
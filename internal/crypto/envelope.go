@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Prefix marking an encrypted field value: "enc:v1:<base64(nonce||ciphertext)>".
+// Values without it are treated as legacy plaintext, so fields can be migrated
+// gradually.
+const fieldPrefix = "enc:v1:"
+
+// DataKeyStore persists each owner's wrapped data key.
+type DataKeyStore interface {
+	GetDataKey(ctx context.Context, ownerID string) (*WrappedKey, error)
+	// CreateDataKey stores the key unless one already exists, returning whichever is stored.
+	CreateDataKey(ctx context.Context, ownerID string, key *WrappedKey) (*WrappedKey, error)
+	ListDataKeysNotWrappedWith(ctx context.Context, version string, limit int) (map[string]*WrappedKey, error)
+	ReplaceDataKey(ctx context.Context, ownerID string, key *WrappedKey) error
+}
+
+// Envelope encrypts fields with a per-owner (per-user) data key, which is itself
+// wrapped by a master key. Rotating the master key only re-wraps data keys.
+type Envelope struct {
+	wrapper KeyWrapper
+	store   DataKeyStore
+
+	mu    sync.RWMutex
+	cache map[string][]byte
+}
+
+func NewEnvelope(wrapper KeyWrapper, store DataKeyStore) *Envelope {
+	return &Envelope{
+		wrapper: wrapper,
+		store:   store,
+		cache:   map[string][]byte{},
+	}
+}
+
+// IsEncrypted reports whether a stored value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, fieldPrefix)
+}
+
+// Encrypt encrypts a field value for the owner. Empty strings stay empty.
+func (e *Envelope) Encrypt(ctx context.Context, ownerID, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	key, err := e.dataKey(ctx, ownerID, true)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(key, []byte(plaintext), []byte("field:"+ownerID))
+	if err != nil {
+		return "", err
+	}
+	return fieldPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. Plaintext (unencrypted legacy) values pass through.
+func (e *Envelope) Decrypt(ctx context.Context, ownerID, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, fieldPrefix))
+	if err != nil {
+		return "", err
+	}
+	key, err := e.dataKey(ctx, ownerID, false)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(key, sealed, []byte("field:"+ownerID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// RotateMasterKey re-wraps up to batch data keys that aren't yet wrapped with
+// the active master key. Returns how many were rotated; call until it returns 0.
+func (e *Envelope) RotateMasterKey(ctx context.Context, batch int) (int, error) {
+	keys, err := e.store.ListDataKeysNotWrappedWith(ctx, e.wrapper.ActiveVersion(), batch)
+	if err != nil {
+		return 0, err
+	}
+	rotated := 0
+	for ownerID, wrapped := range keys {
+		plain, err := e.wrapper.Unwrap(wrapped)
+		if err != nil {
+			return rotated, err
+		}
+		rewrapped, err := e.wrapper.Wrap(plain)
+		if err != nil {
+			return rotated, err
+		}
+		if err := e.store.ReplaceDataKey(ctx, ownerID, rewrapped); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+func (e *Envelope) dataKey(ctx context.Context, ownerID string, create bool) ([]byte, error) {
+	e.mu.RLock()
+	key, ok := e.cache[ownerID]
+	e.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	wrapped, err := e.store.GetDataKey(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if wrapped == nil {
+		if !create {
+			return nil, errors.New("no data key for owner")
+		}
+		fresh := make([]byte, 32)
+		if _, err := rand.Read(fresh); err != nil {
+			return nil, err
+		}
+		newWrapped, err := e.wrapper.Wrap(fresh)
+		if err != nil {
+			return nil, err
+		}
+		// Another replica may have created one concurrently; use whichever won
+		if wrapped, err = e.store.CreateDataKey(ctx, ownerID, newWrapped); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err = e.wrapper.Unwrap(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.cache[ownerID] = key
+	e.mu.Unlock()
+	return key, nil
+}
@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WrappedKey is a data key encrypted under a master key version.
+type WrappedKey struct {
+	Version    string
+	Ciphertext []byte
+}
+
+// KeyWrapper encrypts and decrypts data keys with a master key. The env-based
+// Keyring implements it; a cloud KMS client can too.
+type KeyWrapper interface {
+	// ActiveVersion is the master key version new data keys are wrapped with.
+	ActiveVersion() string
+	Wrap(dataKey []byte) (*WrappedKey, error)
+	Unwrap(wrapped *WrappedKey) ([]byte, error)
+}
+
+// Keyring holds versioned AES-256 master keys loaded from configuration.
+// Old versions are kept so existing data keys can still be unwrapped after a
+// rotation.
+type Keyring struct {
+	keys   map[string][]byte
+	active string
+}
+
+// ParseKeyring parses "v1:<base64 32 bytes>,v2:<base64 32 bytes>" with the
+// given active version.
+func ParseKeyring(spec, active string) (*Keyring, error) {
+	kr := &Keyring{keys: map[string][]byte{}, active: active}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		version, encoded, ok := strings.Cut(entry, ":")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("invalid key entry %q (want version:base64key)", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("key %s must be 32 bytes of base64", version)
+		}
+		kr.keys[version] = key
+	}
+	if _, ok := kr.keys[active]; !ok {
+		return nil, fmt.Errorf("active key version %q is not in the keyring", active)
+	}
+	return kr, nil
+}
+
+func (k *Keyring) ActiveVersion() string {
+	return k.active
+}
+
+func (k *Keyring) Wrap(dataKey []byte) (*WrappedKey, error) {
+	ct, err := seal(k.keys[k.active], dataKey, []byte("datakey:"+k.active))
+	if err != nil {
+		return nil, err
+	}
+	return &WrappedKey{Version: k.active, Ciphertext: ct}, nil
+}
+
+func (k *Keyring) Unwrap(wrapped *WrappedKey) ([]byte, error) {
+	key, ok := k.keys[wrapped.Version]
+	if !ok {
+		return nil, fmt.Errorf("master key version %q not available", wrapped.Version)
+	}
+	return open(key, wrapped.Ciphertext, []byte("datakey:"+wrapped.Version))
+}
+
+// seal encrypts with AES-256-GCM, returning nonce||ciphertext.
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(key, sealed, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ct := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ct, aad)
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads marks ctx so reads made through Reader go to the primary.
+// Writes always go to the primary, so this gives read-your-writes even when
+// the connection string sends reads to secondaries (readPreference=...).
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether ctx was marked by WithPrimaryReads.
+func PrimaryReads(ctx context.Context) bool {
+	on, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return on
+}
+
+// primaryClones caches the primary-read clone of each collection.
+var primaryClones sync.Map // *mongo.Collection -> *mongo.Collection
+
+// Reader returns coll, or a clone of it that reads from the primary when ctx
+// asks for primary reads. Use it for reads a user expects to reflect their
+// own recent writes.
+func Reader(ctx context.Context, coll *mongo.Collection) *mongo.Collection {
+	if !PrimaryReads(ctx) {
+		return coll
+	}
+	if clone, ok := primaryClones.Load(coll); ok {
+		return clone.(*mongo.Collection)
+	}
+	clone := coll.Clone(options.Collection().SetReadPreference(readpref.Primary()))
+	actual, _ := primaryClones.LoadOrStore(coll, clone)
+	return actual.(*mongo.Collection)
+}
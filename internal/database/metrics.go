@@ -0,0 +1,332 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// SlowQueryThreshold is the command duration above which a command is logged.
+// Set it before connecting; zero disables slow-query logs.
+var SlowQueryThreshold = 200 * time.Millisecond
+
+// latencyBuckets are the upper bounds of the latency histogram; percentiles
+// are reported as the bound of the bucket they fall in.
+var latencyBuckets = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond,
+	25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+	500 * time.Millisecond, time.Second, 2500 * time.Millisecond, 5 * time.Second,
+}
+
+// latency is a count, max and bucketed histogram of durations.
+type latency struct {
+	count   int64
+	total   time.Duration
+	max     time.Duration
+	buckets []int64 // len(latencyBuckets)+1; the last counts everything slower
+}
+
+func (l *latency) add(d time.Duration) {
+	if l.buckets == nil {
+		l.buckets = make([]int64, len(latencyBuckets)+1)
+	}
+	l.count++
+	l.total += d
+	if d > l.max {
+		l.max = d
+	}
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return d <= latencyBuckets[i] })
+	l.buckets[i]++
+}
+
+func (l *latency) percentile(p float64) time.Duration {
+	if l.count == 0 {
+		return 0
+	}
+	rank := int64(float64(l.count)*p + 0.5)
+	var seen int64
+	for i, n := range l.buckets {
+		seen += n
+		if seen >= rank {
+			if i == len(latencyBuckets) {
+				return l.max
+			}
+			return latencyBuckets[i]
+		}
+	}
+	return l.max
+}
+
+// LatencyStats summarises a latency histogram in milliseconds.
+type LatencyStats struct {
+	Count  int64   `json:"count"`
+	MeanMS float64 `json:"mean_ms"`
+	P50MS  float64 `json:"p50_ms"`
+	P95MS  float64 `json:"p95_ms"`
+	P99MS  float64 `json:"p99_ms"`
+	MaxMS  float64 `json:"max_ms"`
+}
+
+func (l *latency) stats() LatencyStats {
+	s := LatencyStats{
+		Count: l.count,
+		P50MS: ms(l.percentile(0.50)),
+		P95MS: ms(l.percentile(0.95)),
+		P99MS: ms(l.percentile(0.99)),
+		MaxMS: ms(l.max),
+	}
+	if l.count > 0 {
+		s.MeanMS = ms(l.total / time.Duration(l.count))
+	}
+	return s
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+
+type commandKey struct {
+	collection, command string
+}
+
+type pendingCommand struct {
+	commandKey
+	shape string
+}
+
+// monitor collects pool and command metrics for one connection.
+type monitor struct {
+	name string
+
+	mu               sync.Mutex
+	since            time.Time
+	checkout         latency
+	waiting          int64
+	maxWaiting       int64
+	inUse            int64
+	open             int64
+	checkoutFailures map[string]int64
+	poolCleared      int64
+	commands         map[commandKey]*latency
+	failures         map[commandKey]int64
+	slow             int64
+
+	pending sync.Map // request ID -> pendingCommand
+}
+
+var (
+	monitorsMu sync.Mutex
+	monitors   []*monitor
+)
+
+func newMonitor(name string) *monitor {
+	m := &monitor{
+		name:             name,
+		since:            time.Now(),
+		checkoutFailures: map[string]int64{},
+		commands:         map[commandKey]*latency{},
+		failures:         map[commandKey]int64{},
+	}
+	monitorsMu.Lock()
+	monitors = append(monitors, m)
+	monitorsMu.Unlock()
+	return m
+}
+
+func (m *monitor) poolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{Event: func(e *event.PoolEvent) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		switch e.Type {
+		case event.ConnectionCheckOutStarted:
+			m.waiting++
+			m.maxWaiting = max(m.maxWaiting, m.waiting)
+		case event.ConnectionCheckedOut:
+			m.waiting--
+			m.inUse++
+			m.checkout.add(e.Duration)
+		case event.ConnectionCheckOutFailed:
+			m.waiting--
+			m.checkoutFailures[e.Reason]++
+		case event.ConnectionCheckedIn:
+			m.inUse--
+		case event.ConnectionCreated:
+			m.open++
+		case event.ConnectionClosed:
+			m.open--
+		case event.ConnectionPoolCleared:
+			m.poolCleared++
+		}
+	}}
+}
+
+func (m *monitor) commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			m.pending.Store(e.RequestID, pendingCommand{
+				commandKey: commandKey{collection: collectionOf(e.Command, e.CommandName), command: e.CommandName},
+				shape:      commandShape(e.Command, e.CommandName),
+			})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.finish(&e.CommandFinishedEvent, nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			m.finish(&e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
+
+func (m *monitor) finish(e *event.CommandFinishedEvent, failure error) {
+	value, ok := m.pending.LoadAndDelete(e.RequestID)
+	if !ok {
+		return
+	}
+	cmd := value.(pendingCommand)
+
+	m.mu.Lock()
+	l := m.commands[cmd.commandKey]
+	if l == nil {
+		l = &latency{}
+		m.commands[cmd.commandKey] = l
+	}
+	l.add(e.Duration)
+	if failure != nil {
+		m.failures[cmd.commandKey]++
+	}
+	slow := SlowQueryThreshold > 0 && e.Duration > SlowQueryThreshold
+	if slow {
+		m.slow++
+	}
+	m.mu.Unlock()
+
+	if slow {
+		log.Printf("🐢 Slow Mongo %s on %s.%s took %s%s", cmd.command, m.name, cmd.collection, e.Duration.Round(time.Millisecond), cmd.shape)
+	}
+}
+
+// collectionOf reads the collection from a command document, whose first
+// element is {<command>: <collection>} for CRUD commands.
+func collectionOf(cmd bson.Raw, name string) string {
+	if name == "getMore" {
+		name = "collection"
+	}
+	value, err := cmd.LookupErr(name)
+	if err != nil {
+		return ""
+	}
+	if coll, ok := value.StringValueOK(); ok {
+		return coll
+	}
+	return ""
+}
+
+// commandShape describes a command for the slow-query log using field and
+// stage names only, never values, so filters on emails or tokens stay out of
+// the logs.
+func commandShape(cmd bson.Raw, name string) string {
+	switch name {
+	case "aggregate":
+		pipeline, err := cmd.LookupErr("pipeline")
+		if err != nil {
+			return ""
+		}
+		values, err := pipeline.Array().Values()
+		if err != nil {
+			return ""
+		}
+		var stages []string
+		for _, v := range values {
+			if doc, ok := v.DocumentOK(); ok {
+				stages = append(stages, fieldNames(doc)...)
+			}
+		}
+		return fmt.Sprintf(" (pipeline: %s)", strings.Join(stages, ", "))
+	case "find", "count", "distinct", "findAndModify", "delete", "update":
+		for _, field := range []string{"filter", "query"} {
+			if doc, ok := cmd.Lookup(field).DocumentOK(); ok {
+				return fmt.Sprintf(" (filter: %s)", strings.Join(fieldNames(doc), ", "))
+			}
+		}
+	}
+	return ""
+}
+
+func fieldNames(doc bson.Raw) []string {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(elems))
+	for i, elem := range elems {
+		names[i] = elem.Key()
+	}
+	return names
+}
+
+// CommandStats are the latencies of one command on one collection.
+type CommandStats struct {
+	Collection string `json:"collection"`
+	Command    string `json:"command"`
+	Failures   int64  `json:"failures"`
+	LatencyStats
+}
+
+// PoolStats describe a connection's pool and commands since the process started.
+type PoolStats struct {
+	Connection       string           `json:"connection"` // "primary" or "secondary"
+	Since            time.Time        `json:"since"`
+	OpenConnections  int64            `json:"open_connections"`
+	InUse            int64            `json:"in_use"`
+	Waiting          int64            `json:"waiting"`     // checkouts queued right now
+	MaxWaiting       int64            `json:"max_waiting"` // high-water mark of the queue
+	Checkout         LatencyStats     `json:"checkout"`    // time to get a connection, including queueing
+	CheckoutFailures map[string]int64 `json:"checkout_failures"`
+	PoolCleared      int64            `json:"pool_cleared"`
+	SlowCommands     int64            `json:"slow_commands"`
+	Commands         []CommandStats   `json:"commands"` // slowest p95 first
+}
+
+// Stats returns the metrics of every connection.
+func Stats() []PoolStats {
+	monitorsMu.Lock()
+	defer monitorsMu.Unlock()
+
+	out := make([]PoolStats, 0, len(monitors))
+	for _, m := range monitors {
+		m.mu.Lock()
+		s := PoolStats{
+			Connection:       m.name,
+			Since:            m.since,
+			OpenConnections:  m.open,
+			InUse:            m.inUse,
+			Waiting:          m.waiting,
+			MaxWaiting:       m.maxWaiting,
+			Checkout:         m.checkout.stats(),
+			CheckoutFailures: map[string]int64{},
+			PoolCleared:      m.poolCleared,
+			SlowCommands:     m.slow,
+		}
+		for reason, n := range m.checkoutFailures {
+			s.CheckoutFailures[reason] = n
+		}
+		for key, l := range m.commands {
+			s.Commands = append(s.Commands, CommandStats{
+				Collection:   key.collection,
+				Command:      key.command,
+				Failures:     m.failures[key],
+				LatencyStats: l.stats(),
+			})
+		}
+		m.mu.Unlock()
+
+		sort.Slice(s.Commands, func(i, j int) bool { return s.Commands[i].P95MS > s.Commands[j].P95MS })
+		out = append(out, s)
+	}
+	return out
+}
@@ -11,26 +11,101 @@ import (
 
 var DB *mongo.Database
 
+// SecondaryDB, when configured, holds high-volume analytics/event collections
+// so their writes don't compete with the auth path on the primary cluster.
+var SecondaryDB *mongo.Database
+
+// secondaryCollections are routed to SecondaryDB by GetCollection.
+var secondaryCollections = map[string]bool{}
+
+// DefaultSecondaryCollections are the analytics and event collections moved
+// to the secondary connection unless configured otherwise.
+var DefaultSecondaryCollections = []string{
+	"usage_daily",
+	"login_link_events",
+	"session_policy_stats",
+	"auth_ip_activity",
+	"metrics_rollups",
+	"metrics_rollup_state",
+}
+
 func Connect(uri, dbName string) error {
+	db, err := connect(uri, dbName, "primary")
+	if err != nil {
+		return err
+	}
+	DB = db
+	log.Println("✅ Connected to MongoDB")
+	return nil
+}
+
+// ConnectSecondary opens the secondary connection and routes the given
+// collections to it. Must be called before repositories are created.
+func ConnectSecondary(uri, dbName string, collections []string) error {
+	db, err := connect(uri, dbName, "secondary")
+	if err != nil {
+		return err
+	}
+	SecondaryDB = db
+	for _, name := range collections {
+		secondaryCollections[name] = true
+	}
+	log.Printf("✅ Connected to secondary MongoDB (%d collections)", len(collections))
+	return nil
+}
+
+func connect(uri, dbName, name string) (*mongo.Database, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	clientOpts := options.Client().ApplyURI(uri)
+	m := newMonitor(name)
+	clientOpts := options.Client().ApplyURI(uri).
+		SetPoolMonitor(m.poolMonitor()).
+		SetMonitor(m.commandMonitor())
 	client, err := mongo.Connect(clientOpts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Ping the database to verify connection
 	if err := client.Ping(ctx, nil); err != nil {
-		return err
+		return nil, err
 	}
 
-	DB = client.Database(dbName)
-	log.Println("✅ Connected to MongoDB")
-	return nil
+	return client.Database(dbName), nil
 }
 
+// GetCollection returns the named collection from whichever connection it is routed to.
 func GetCollection(name string) *mongo.Collection {
+	if SecondaryDB != nil && secondaryCollections[name] {
+		return SecondaryDB.Collection(name)
+	}
 	return DB.Collection(name)
 }
+
+// Ping checks the primary connection and, when configured, the secondary.
+func Ping(ctx context.Context) error {
+	if err := DB.Client().Ping(ctx, nil); err != nil {
+		return err
+	}
+	if SecondaryDB != nil {
+		return SecondaryDB.Client().Ping(ctx, nil)
+	}
+	return nil
+}
+
+// WithTransaction runs fn in a transaction on the primary connection; pass
+// the context fn receives to every operation that should take part. Needs a
+// replica set, and collections routed to the secondary connection can't join.
+func WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
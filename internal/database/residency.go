@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// RegionalCollections hold user content and follow the data residency
+// region of the user's organization. Accounts stay global, since sign-in
+// looks them up by email before any region is known.
+var RegionalCollections = map[string]bool{
+	"feedbacks": true,
+}
+
+// regionStore is where a region's collections live: a database of their own,
+// or prefixed collections in the primary database.
+type regionStore struct {
+	db     *mongo.Database
+	prefix string
+}
+
+// regions are configured at startup and read-only afterwards.
+var regions = map[string]regionStore{}
+
+// ConnectRegion routes the region's regional collections to their own
+// database, or to prefix+name collections in the primary database when uri
+// is empty. Must be called before serving.
+func ConnectRegion(region, uri, dbName, prefix string) error {
+	if uri == "" {
+		regions[region] = regionStore{db: DB, prefix: prefix}
+		log.Printf("✅ Region %s stored in %s* collections", region, prefix)
+		return nil
+	}
+	db, err := connect(uri, dbName, "region-"+region)
+	if err != nil {
+		return err
+	}
+	regions[region] = regionStore{db: db, prefix: prefix}
+	log.Printf("✅ Connected to MongoDB for region %s", region)
+	return nil
+}
+
+// Regions lists the configured data residency regions.
+func Regions() []string {
+	out := make([]string, 0, len(regions))
+	for region := range regions {
+		out = append(out, region)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// HasRegion reports whether the region is configured.
+func HasRegion(region string) bool {
+	_, ok := regions[region]
+	return ok
+}
+
+type regionKey struct{}
+
+// WithRegion marks ctx so repositories store and read regional collections
+// in the region. An empty region means the default databases.
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, regionKey{}, region)
+}
+
+// RegionOf returns the region ctx was marked with by WithRegion.
+func RegionOf(ctx context.Context) string {
+	region, _ := ctx.Value(regionKey{}).(string)
+	return region
+}
+
+type regionalClone struct {
+	coll   *mongo.Collection
+	region string
+}
+
+// regionalClones caches each collection's counterpart per region.
+var regionalClones sync.Map // regionalClone -> *mongo.Collection
+
+// Regional returns coll's counterpart in ctx's region if coll is one of the
+// RegionalCollections, or coll itself. Callers must make sure ctx's region
+// is configured (see HasRegion): an unknown one falls back to coll.
+func Regional(ctx context.Context, coll *mongo.Collection) *mongo.Collection {
+	region := RegionOf(ctx)
+	if region == "" || !RegionalCollections[coll.Name()] {
+		return coll
+	}
+	store, ok := regions[region]
+	if !ok {
+		return coll
+	}
+	key := regionalClone{coll: coll, region: region}
+	if clone, ok := regionalClones.Load(key); ok {
+		return clone.(*mongo.Collection)
+	}
+	actual, _ := regionalClones.LoadOrStore(key, store.db.Collection(store.prefix+coll.Name()))
+	return actual.(*mongo.Collection)
+}
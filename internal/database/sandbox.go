@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SandboxCollections are the collections a sandbox gets its own copy of,
+// so integration tests can write to them on a deployed environment. Other
+// collections are shared with real traffic, so sandbox requests are only
+// accepted by routes that write to none of them (see authz.Policy).
+var SandboxCollections = map[string]bool{
+	"feedbacks":     true,
+	"roadmap_items": true,
+	"roadmap_votes": true,
+}
+
+type sandboxKey struct{}
+
+// WithSandbox marks ctx so repositories use the sandbox's collections.
+func WithSandbox(ctx context.Context, sandbox string) context.Context {
+	return context.WithValue(ctx, sandboxKey{}, sandbox)
+}
+
+// SandboxOf returns the sandbox ctx was marked with by WithSandbox, or "".
+func SandboxOf(ctx context.Context) string {
+	sandbox, _ := ctx.Value(sandboxKey{}).(string)
+	return sandbox
+}
+
+// InSandbox reports whether ctx belongs to a sandbox. Side effects outside
+// the database (Slack, issue trackers, email) should be skipped then.
+func InSandbox(ctx context.Context) bool {
+	return SandboxOf(ctx) != ""
+}
+
+func sandboxCollectionName(sandbox, name string) string {
+	return "sandbox_" + sandbox + "_" + name
+}
+
+type sandboxClone struct {
+	coll    *mongo.Collection
+	sandbox string
+}
+
+// sandboxClones caches each collection's counterpart per sandbox.
+var sandboxClones sync.Map // sandboxClone -> *mongo.Collection
+
+// Scoped returns coll's counterpart for ctx: the sandbox's copy when ctx is
+// in a sandbox and coll is one of the SandboxCollections, else its
+// counterpart in ctx's region (see Regional).
+func Scoped(ctx context.Context, coll *mongo.Collection) *mongo.Collection {
+	sandbox := SandboxOf(ctx)
+	if sandbox == "" || !SandboxCollections[coll.Name()] {
+		return Regional(ctx, coll)
+	}
+	key := sandboxClone{coll: coll, sandbox: sandbox}
+	if clone, ok := sandboxClones.Load(key); ok {
+		return clone.(*mongo.Collection)
+	}
+	actual, _ := sandboxClones.LoadOrStore(key, DB.Collection(sandboxCollectionName(sandbox, coll.Name())))
+	return actual.(*mongo.Collection)
+}
+
+// DropSandbox deletes every collection of the sandbox. Repositories
+// recreate them, without indexes until their EnsureIndexes runs with a
+// sandbox context.
+func DropSandbox(ctx context.Context, sandbox string) error {
+	for name := range SandboxCollections {
+		if err := DB.Collection(sandboxCollectionName(sandbox, name)).Drop(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
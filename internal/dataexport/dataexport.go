@@ -0,0 +1,127 @@
+package dataexport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/i18n"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/signedurl"
+	"rizon-backend/internal/templates"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// JobType is the job queue type for personal data exports.
+const JobType = "user_export"
+
+// LinkTTL is how long a download link (and the stored file) lives.
+const LinkTTL = 7 * 24 * time.Hour
+
+// Payload is stored on the export job.
+type Payload struct {
+	ExportID bson.ObjectID `bson:"export_id"`
+}
+
+// Exporter generates exports in the background and emails a signed,
+// time-limited download link.
+type Exporter struct {
+	users    *repository.UserRepo
+	feedback *repository.FeedbackRepo
+	consents *repository.ConsentRepo
+	exports  *repository.ExportRepo
+	mailer   *mailer.Mailer
+	signer   *signedurl.Signer
+	baseURL  string
+}
+
+func NewExporter(users *repository.UserRepo, feedback *repository.FeedbackRepo, consents *repository.ConsentRepo, exports *repository.ExportRepo, mail *mailer.Mailer, signer *signedurl.Signer, baseURL string) *Exporter {
+	return &Exporter{
+		users:    users,
+		feedback: feedback,
+		consents: consents,
+		exports:  exports,
+		mailer:   mail,
+		signer:   signer,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Document is the exported file.
+type Document struct {
+	ExportedAt time.Time         `json:"exported_at"`
+	User       *models.User      `json:"user"`
+	Feedback   []models.Feedback `json:"feedback"`
+	Consents   []models.Consent  `json:"consents"`
+}
+
+// Handle is the job handler. Once the file is stored the export counts as
+// done, so a retry never emails the link twice.
+func (e *Exporter) Handle(ctx context.Context, job *models.Job) (bson.M, error) {
+	var payload Payload
+	if err := job.DecodePayload(&payload); err != nil {
+		return nil, fmt.Errorf("%w: invalid payload: %v", jobs.ErrPermanent, err)
+	}
+
+	export, err := e.exports.FindByID(ctx, payload.ExportID)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil {
+		return nil, fmt.Errorf("%w: export %s not found", jobs.ErrPermanent, payload.ExportID.Hex())
+	}
+	if export.Status == models.ExportReady {
+		return bson.M{"export_id": export.ID.Hex(), "size": export.Size}, nil
+	}
+
+	user, err := e.users.FindByID(ctx, export.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: user not found", jobs.ErrPermanent)
+	}
+
+	doc := Document{ExportedAt: time.Now(), User: user}
+	if doc.Feedback, err = e.feedback.ListForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	if doc.Consents, err = e.consents.ListForUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", jobs.ErrPermanent, err)
+	}
+
+	if err := e.exports.SetReady(ctx, export.ID, data); err != nil {
+		return nil, err
+	}
+
+	loc := i18n.For(user.Locale)
+	content, err := templates.RenderEmail("data_export_ready", templates.EmailOptionsFor(user, loc.Locale()), map[string]interface{}{
+		"Link":    e.DownloadURL(export.ID, export.ExpiresAt),
+		"Expires": loc.Date(i18n.InZone(export.ExpiresAt, user.TimeZone), "medium"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", jobs.ErrPermanent, err)
+	}
+	delivery, err := e.mailer.Send(ctx, mailer.FromRendered(user.Email, content))
+	if err != nil {
+		// The file is ready and the app can still fetch it via the status endpoint
+		return bson.M{"export_id": export.ID.Hex(), "size": len(data), "email_error": err.Error()}, nil
+	}
+
+	return bson.M{"export_id": export.ID.Hex(), "size": len(data), "email_provider": delivery.Provider}, nil
+}
+
+// DownloadURL returns a signed link to the export, valid until it expires.
+func (e *Exporter) DownloadURL(id bson.ObjectID, expires time.Time) string {
+	return e.baseURL + "/download/" + e.signer.Sign("exports/"+id.Hex(), time.Until(expires))
+}
@@ -0,0 +1,252 @@
+// Package experiments assigns users to server-side A/B experiments and logs
+// their exposures to the events collection.
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FlagChecker decides whether a feature flag is on for a user.
+type FlagChecker interface {
+	Enabled(key, userID string) bool
+}
+
+// Engine keeps an in-memory copy of all experiments, like the feature flag
+// store, so assignment never hits Mongo. Exposures are buffered and flushed
+// in batches.
+type Engine struct {
+	repo   *repository.ExperimentRepo
+	events *repository.EventRepo
+	flags  FlagChecker
+
+	mu          sync.RWMutex
+	experiments []models.Experiment
+
+	pendingMu sync.Mutex
+	pending   map[exposureKey]time.Time
+}
+
+type exposureKey struct {
+	userID     string
+	experiment string
+	variant    string
+}
+
+func NewEngine(repo *repository.ExperimentRepo, events *repository.EventRepo, flags FlagChecker) *Engine {
+	return &Engine{repo: repo, events: events, flags: flags, pending: map[exposureKey]time.Time{}}
+}
+
+// Refresh reloads every experiment from Mongo.
+func (e *Engine) Refresh(ctx context.Context) error {
+	list, err := e.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.experiments = list
+	e.mu.Unlock()
+	return nil
+}
+
+// Run refreshes experiments and flushes exposures every interval until ctx
+// is cancelled, then performs a final flush.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			e.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			if err := e.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Error refreshing experiments: %v", err)
+			}
+			e.flush(ctx)
+		}
+	}
+}
+
+// Assign returns the user's variant in every running experiment they are
+// enrolled in, without logging an exposure.
+func (e *Engine) Assign(userID string) []models.ExperimentAssignment {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	assignments := []models.ExperimentAssignment{}
+	for i := range e.experiments {
+		if variant := e.variantFor(&e.experiments[i], userID); variant != nil {
+			assignments = append(assignments, models.ExperimentAssignment{
+				Experiment: e.experiments[i].Key,
+				Variant:    variant.Key,
+				Config:     variant.Config,
+			})
+		}
+	}
+	return assignments
+}
+
+// Expose assigns the user and logs an exposure for each assignment; call it
+// when the assignments are handed to the client.
+func (e *Engine) Expose(userID string) []models.ExperimentAssignment {
+	assignments := e.Assign(userID)
+	now := time.Now()
+	e.pendingMu.Lock()
+	for _, a := range assignments {
+		e.pending[exposureKey{userID: userID, experiment: a.Experiment, variant: a.Variant}] = now
+	}
+	e.pendingMu.Unlock()
+	return assignments
+}
+
+func (e *Engine) variantFor(exp *models.Experiment, userID string) *models.ExperimentVariant {
+	if exp.Status != models.ExperimentRunning || userID == "" {
+		return nil
+	}
+	if exp.FlagKey != "" && (e.flags == nil || !e.flags.Enabled(exp.FlagKey, userID)) {
+		return nil
+	}
+	// Enrollment and variant use independent hashes, so raising the traffic
+	// share enrolls new users without moving anyone already enrolled
+	if bucket(exp.Key+":traffic:"+userID, 10000) >= uint32(exp.TrafficPercent*100) {
+		return nil
+	}
+	total := 0
+	for _, v := range exp.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+	pick := int(bucket(exp.Key+":variant:"+userID, uint32(total)))
+	for i := range exp.Variants {
+		if pick < exp.Variants[i].Weight {
+			return &exp.Variants[i]
+		}
+		pick -= exp.Variants[i].Weight
+	}
+	return nil
+}
+
+func bucket(s string, n uint32) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32() % n
+}
+
+func (e *Engine) flush(ctx context.Context) {
+	e.pendingMu.Lock()
+	batch := e.pending
+	e.pending = map[exposureKey]time.Time{}
+	e.pendingMu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	exposures := make([]repository.Exposure, 0, len(batch))
+	for key, at := range batch {
+		userID, err := bson.ObjectIDFromHex(key.userID)
+		if err != nil {
+			continue
+		}
+		exposures = append(exposures, repository.Exposure{UserID: userID, Experiment: key.experiment, Variant: key.variant, At: at})
+	}
+	if err := e.events.RecordExposures(ctx, exposures); err != nil {
+		log.Printf("Error recording %d experiment exposures: %v", len(exposures), err)
+		// Put them back so they are retried on the next flush
+		e.pendingMu.Lock()
+		for key, at := range batch {
+			if _, newer := e.pending[key]; !newer {
+				e.pending[key] = at
+			}
+		}
+		e.pendingMu.Unlock()
+	}
+}
+
+// Save validates and stores an experiment, then reloads the cache so this
+// replica assigns with it straight away (others catch up on refresh).
+func (e *Engine) Save(ctx context.Context, exp *models.Experiment) error {
+	if err := Validate(exp); err != nil {
+		return err
+	}
+	if err := e.repo.Upsert(ctx, exp); err != nil {
+		return fmt.Errorf("saving experiment: %w", err)
+	}
+	e.refresh(ctx)
+	return nil
+}
+
+// Delete removes an experiment, reporting whether it existed.
+func (e *Engine) Delete(ctx context.Context, key string) (bool, error) {
+	deleted, err := e.repo.Delete(ctx, key)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	e.refresh(ctx)
+	return true, nil
+}
+
+func (e *Engine) refresh(ctx context.Context) {
+	if err := e.Refresh(ctx); err != nil {
+		log.Printf("Error refreshing experiments: %v", err)
+	}
+}
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidationError describes an experiment definition that can't be saved.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+// Validate checks an experiment definition.
+func Validate(exp *models.Experiment) error {
+	invalid := func(msg string) error { return &ValidationError{Message: msg} }
+	switch {
+	case !keyPattern.MatchString(exp.Key):
+		return invalid("experiment keys are lowercase letters, digits, _ and - (at most 64)")
+	case !slices.Contains(models.ExperimentStatuses, exp.Status):
+		return invalid(fmt.Sprintf("status must be one of %v", models.ExperimentStatuses))
+	case exp.TrafficPercent < 0 || exp.TrafficPercent > 100:
+		return invalid("traffic_percent must be between 0 and 100")
+	case len(exp.Variants) < 2:
+		return invalid("an experiment needs at least two variants")
+	}
+	seen := map[string]bool{}
+	for _, v := range exp.Variants {
+		if !keyPattern.MatchString(v.Key) {
+			return invalid("variant keys are lowercase letters, digits, _ and - (at most 64)")
+		}
+		if seen[v.Key] {
+			return invalid("duplicate variant " + v.Key)
+		}
+		seen[v.Key] = true
+		if v.Weight <= 0 {
+			return invalid("variant weights must be positive")
+		}
+	}
+	return nil
+}
+
+// IsValidationError reports whether err came from Validate.
+func IsValidationError(err error) bool {
+	var verr *ValidationError
+	return errors.As(err, &verr)
+}
@@ -0,0 +1,87 @@
+package flags
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+)
+
+// Store keeps an in-memory copy of all feature flags so per-request checks
+// never hit Mongo. Changes made on other replicas show up after the next refresh.
+type Store struct {
+	repo *repository.FeatureFlagRepo
+
+	mu    sync.RWMutex
+	flags map[string]models.FeatureFlag
+}
+
+func NewStore(repo *repository.FeatureFlagRepo) *Store {
+	return &Store{repo: repo, flags: map[string]models.FeatureFlag{}}
+}
+
+// Refresh reloads every flag from Mongo.
+func (s *Store) Refresh(ctx context.Context) error {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	flags := make(map[string]models.FeatureFlag, len(list))
+	for _, f := range list {
+		flags[f.Key] = f
+	}
+	s.mu.Lock()
+	s.flags = flags
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the cache every interval until ctx is cancelled.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Error refreshing feature flags: %v", err)
+			}
+		}
+	}
+}
+
+// Enabled reports whether the flag is on for userID. Unknown flags are off,
+// so routes registered behind a flag stay dark until the flag is created.
+func (s *Store) Enabled(key, userID string) bool {
+	s.mu.RLock()
+	flag, ok := s.flags[key]
+	s.mu.RUnlock()
+	if !ok || !flag.Enabled {
+		return false
+	}
+	if userID != "" && slices.Contains(flag.UserIDs, userID) {
+		return true
+	}
+	if flag.Percentage >= 100 {
+		return true
+	}
+	if userID == "" || flag.Percentage <= 0 {
+		return false
+	}
+	return bucket(key, userID) < flag.Percentage
+}
+
+// bucket maps a user to 0–99, stable per flag so raising the percentage
+// only ever adds users.
+func bucket(key, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + userID))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,110 @@
+package geo
+
+import (
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/models"
+)
+
+// Resolver resolves a request's location. Header-based resolution is built
+// in; an IP database (e.g. MaxMind GeoLite2) can be plugged in behind this.
+type Resolver interface {
+	Resolve(r *http.Request) models.GeoLocation
+}
+
+// HeaderResolver reads the geo headers added by CDNs and load balancers
+// (Cloudflare, CloudFront, Vercel, Google Cloud).
+type HeaderResolver struct{}
+
+func (HeaderResolver) Resolve(r *http.Request) models.GeoLocation {
+	loc := models.GeoLocation{
+		Country: firstHeader(r,
+			"CF-IPCountry",
+			"CloudFront-Viewer-Country",
+			"X-Vercel-IP-Country",
+			"X-AppEngine-Country",
+			"X-Client-Geo-Country"),
+		Region: firstHeader(r,
+			"CF-Region-Code",
+			"CloudFront-Viewer-Country-Region",
+			"X-Vercel-IP-Country-Region",
+			"X-AppEngine-Region"),
+		City: firstHeader(r,
+			"CF-IPCity",
+			"CloudFront-Viewer-City",
+			"X-Vercel-IP-City",
+			"X-AppEngine-City"),
+		TimeZone: firstHeader(r,
+			"CF-Timezone",
+			"CloudFront-Viewer-Time-Zone",
+			"X-Vercel-IP-Timezone"),
+	}
+	loc.Country = strings.ToUpper(loc.Country)
+	// Cloudflare uses XX for unknown and T1 for Tor
+	if len(loc.Country) != 2 || loc.Country == "XX" || loc.Country == "T1" {
+		loc.Country = ""
+	}
+	return loc
+}
+
+func firstHeader(r *http.Request, names ...string) string {
+	for _, name := range names {
+		if v := strings.TrimSpace(r.Header.Get(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+type countryDefaults struct {
+	locale   string
+	timeZone string
+}
+
+// defaults for the countries we see most; everything else falls back to en-US/UTC.
+var defaults = map[string]countryDefaults{
+	"US": {"en-US", "America/New_York"},
+	"CA": {"en-CA", "America/Toronto"},
+	"GB": {"en-GB", "Europe/London"},
+	"IE": {"en-IE", "Europe/Dublin"},
+	"AU": {"en-AU", "Australia/Sydney"},
+	"NZ": {"en-NZ", "Pacific/Auckland"},
+	"IN": {"en-IN", "Asia/Kolkata"},
+	"BD": {"bn-BD", "Asia/Dhaka"},
+	"PK": {"ur-PK", "Asia/Karachi"},
+	"DE": {"de-DE", "Europe/Berlin"},
+	"AT": {"de-AT", "Europe/Vienna"},
+	"CH": {"de-CH", "Europe/Zurich"},
+	"FR": {"fr-FR", "Europe/Paris"},
+	"ES": {"es-ES", "Europe/Madrid"},
+	"IT": {"it-IT", "Europe/Rome"},
+	"NL": {"nl-NL", "Europe/Amsterdam"},
+	"SE": {"sv-SE", "Europe/Stockholm"},
+	"PL": {"pl-PL", "Europe/Warsaw"},
+	"PT": {"pt-PT", "Europe/Lisbon"},
+	"BR": {"pt-BR", "America/Sao_Paulo"},
+	"MX": {"es-MX", "America/Mexico_City"},
+	"JP": {"ja-JP", "Asia/Tokyo"},
+	"KR": {"ko-KR", "Asia/Seoul"},
+	"SG": {"en-SG", "Asia/Singapore"},
+	"AE": {"ar-AE", "Asia/Dubai"},
+	"SA": {"ar-SA", "Asia/Riyadh"},
+	"TR": {"tr-TR", "Europe/Istanbul"},
+	"ID": {"id-ID", "Asia/Jakarta"},
+	"NG": {"en-NG", "Africa/Lagos"},
+	"ZA": {"en-ZA", "Africa/Johannesburg"},
+}
+
+// Defaults returns the default locale and time zone for a location. A time
+// zone reported by the CDN wins over the per-country guess.
+func Defaults(loc models.GeoLocation) (locale, timeZone string) {
+	d, ok := defaults[loc.Country]
+	if !ok {
+		d = countryDefaults{"en-US", "UTC"}
+	}
+	if loc.TimeZone != "" {
+		return d.locale, loc.TimeZone
+	}
+	return d.locale, d.timeZone
+}
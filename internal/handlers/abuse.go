@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/ratelimit"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AbuseHandler struct {
+	abuseRepo *repository.AbuseRepo
+	limiter   *ratelimit.Limiter
+}
+
+func NewAbuseHandler(abuseRepo *repository.AbuseRepo, limiter *ratelimit.Limiter) *AbuseHandler {
+	return &AbuseHandler{
+		abuseRepo: abuseRepo,
+		limiter:   limiter,
+	}
+}
+
+// --- GET /admin/abuse/blocks ---
+
+func (h *AbuseHandler) ListBlocks(w http.ResponseWriter, r *http.Request) {
+	blocks, err := h.abuseRepo.ListActiveBlocks(r.Context())
+	if err != nil {
+		log.Printf("Error listing IP blocks: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"blocks": blocks})
+}
+
+// --- DELETE /admin/abuse/blocks/{ip} ---
+// Also clears the IP's activity windows; otherwise the counts that caused
+// the block would re-block it on its next login request.
+
+func (h *AbuseHandler) LiftBlock(w http.ResponseWriter, r *http.Request) {
+	ip := chi.URLParam(r, "ip")
+	found, err := h.abuseRepo.Lift(r.Context(), ip)
+	if err != nil {
+		log.Printf("Error lifting IP block: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to lift block"})
+		return
+	}
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "IP is not blocked"})
+		return
+	}
+	if _, err := h.abuseRepo.ClearActivity(r.Context(), ip); err != nil {
+		log.Printf("Error clearing auth activity for %s: %v", ip, err)
+	}
+	log.Printf("🔓 IP block for %s lifted by %s", ip, middleware.GetAdminName(r.Context()))
+	writeJSON(w, http.StatusOK, map[string]string{"message": "block lifted"})
+}
+
+// --- GET /admin/abuse/activity/{ip} ---
+
+func (h *AbuseHandler) Activity(w http.ResponseWriter, r *http.Request) {
+	ip := chi.URLParam(r, "ip")
+	windows, err := h.abuseRepo.ListActivity(r.Context(), ip)
+	if err != nil {
+		log.Printf("Error listing auth activity: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	block, err := h.abuseRepo.FindActiveBlock(r.Context(), ip)
+	if err != nil {
+		log.Printf("Error finding IP block: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ip": ip, "block": block, "activity": windows})
+}
+
+// --- DELETE /admin/abuse/activity/{ip} ---
+// Resets the IP's counters without touching an existing block.
+
+func (h *AbuseHandler) ClearActivity(w http.ResponseWriter, r *http.Request) {
+	ip := chi.URLParam(r, "ip")
+	cleared, err := h.abuseRepo.ClearActivity(r.Context(), ip)
+	if err != nil {
+		log.Printf("Error clearing auth activity: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to clear activity"})
+		return
+	}
+	log.Printf("🧹 Auth activity for %s cleared by %s", ip, middleware.GetAdminName(r.Context()))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cleared": cleared})
+}
+
+// --- GET /admin/rate-limits?prefix=login:&limit=100 ---
+
+func (h *AbuseHandler) ListRateLimits(w http.ResponseWriter, r *http.Request) {
+	limit := int64(100)
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 1000 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = n
+	}
+	counters, err := h.limiter.List(r.Context(), r.URL.Query().Get("prefix"), limit)
+	if err != nil {
+		log.Printf("Error listing rate limit counters: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"counters": counters})
+}
+
+// --- DELETE /admin/rate-limits?key=login:email:someone@example.com ---
+
+func (h *AbuseHandler) ResetRateLimit(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "key is required"})
+		return
+	}
+	cleared, err := h.limiter.Reset(r.Context(), key)
+	if err != nil {
+		log.Printf("Error resetting rate limit: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to reset rate limit"})
+		return
+	}
+	if cleared == 0 {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no counters for key"})
+		return
+	}
+	log.Printf("🧹 Rate limit %s reset by %s", key, middleware.GetAdminName(r.Context()))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cleared": cleared})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ActivityHandler struct {
+	deviceRepo *repository.DeviceRepo
+	usageRepo  *repository.UsageRepo
+}
+
+func NewActivityHandler(deviceRepo *repository.DeviceRepo, usageRepo *repository.UsageRepo) *ActivityHandler {
+	return &ActivityHandler{
+		deviceRepo: deviceRepo,
+		usageRepo:  usageRepo,
+	}
+}
+
+// --- POST /user/heartbeat ---
+// Every authenticated request already updates last-seen (see
+// middleware.Presence); this is for app launches that make no other call.
+
+func (h *ActivityHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- GET /admin/users/{id}/devices ---
+
+func (h *ActivityHandler) ListDevices(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	devices, err := h.deviceRepo.ListForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing devices: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	active := 0
+	for i := range devices {
+		devices[i].Active = time.Since(devices[i].LastSeenAt) < models.ActiveDeviceWindow
+		if devices[i].Active {
+			active++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"devices":        devices,
+		"active_devices": active,
+	})
+}
+
+// --- GET /admin/analytics/active-users?days=30 ---
+
+func (h *ActivityHandler) ActiveUsers(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 90 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = n
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+
+	daily, err := h.usageRepo.DailyActiveUsers(r.Context(), since)
+	if err != nil {
+		log.Printf("Error counting active users: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since": since,
+		"daily": daily,
+	})
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type AdminKeyHandler struct {
+	adminKeyRepo *repository.AdminKeyRepo
+}
+
+func NewAdminKeyHandler(adminKeyRepo *repository.AdminKeyRepo) *AdminKeyHandler {
+	return &AdminKeyHandler{
+		adminKeyRepo: adminKeyRepo,
+	}
+}
+
+type AdminKeyRequest struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	// Creates a sandbox key, which takes no permissions
+	Sandbox bool `json:"sandbox"`
+}
+
+// --- GET /admin/api-keys ---
+
+func (h *AdminKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.adminKeyRepo.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing admin keys: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys":        keys,
+		"permissions": models.KnownPermissions,
+	})
+}
+
+// --- POST /admin/api-keys ---
+// The plaintext key is only returned once, in this response.
+
+func (h *AdminKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req AdminKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+	if req.Sandbox {
+		if len(req.Permissions) > 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "sandbox keys can't have permissions"})
+			return
+		}
+		req.Permissions = []string{}
+	} else if msg := validatePermissions(req.Permissions); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	plaintext := "rzk_" + hex.EncodeToString(buf)
+
+	key := &models.AdminKey{
+		Name:        req.Name,
+		KeyHash:     middleware.HashAdminKey(plaintext),
+		Prefix:      plaintext[:10],
+		Permissions: req.Permissions,
+		Sandbox:     req.Sandbox,
+	}
+	if err := h.adminKeyRepo.Create(r.Context(), key); err != nil {
+		log.Printf("Error creating admin key: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create key"})
+		return
+	}
+	log.Printf("🔑 Admin key %q created by %s with %v", key.Name, middleware.GetAdminName(r.Context()), key.Permissions)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"key":     plaintext,
+		"details": key,
+	})
+}
+
+// --- PUT /admin/api-keys/{id}/permissions ---
+
+func (h *AdminKeyHandler) UpdatePermissions(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid key ID"})
+		return
+	}
+
+	var req AdminKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if msg := validatePermissions(req.Permissions); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	found, err := h.adminKeyRepo.UpdatePermissions(r.Context(), id, req.Permissions)
+	if err != nil {
+		log.Printf("Error updating admin key: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update key"})
+		return
+	}
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "key not found"})
+		return
+	}
+	log.Printf("🔑 Admin key %s permissions set to %v by %s", id.Hex(), req.Permissions, middleware.GetAdminName(r.Context()))
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":     "permissions updated",
+		"permissions": req.Permissions,
+	})
+}
+
+// --- DELETE /admin/api-keys/{id} ---
+
+func (h *AdminKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid key ID"})
+		return
+	}
+
+	revoked, err := h.adminKeyRepo.Revoke(r.Context(), id)
+	if err != nil {
+		log.Printf("Error revoking admin key: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke key"})
+		return
+	}
+	if !revoked {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "key not found"})
+		return
+	}
+	log.Printf("🔑 Admin key %s revoked by %s", id.Hex(), middleware.GetAdminName(r.Context()))
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "key revoked"})
+}
+
+func validatePermissions(perms []string) string {
+	if len(perms) == 0 {
+		return "at least one permission is required"
+	}
+	for _, p := range perms {
+		if !models.IsValidPermission(p) {
+			return "unknown permission: " + p
+		}
+	}
+	return ""
+}
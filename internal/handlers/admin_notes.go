@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const maxAdminNoteLength = 2000
+
+type AdminNoteHandler struct {
+	userRepo *repository.UserRepo
+	noteRepo *repository.AdminNoteRepo
+}
+
+func NewAdminNoteHandler(userRepo *repository.UserRepo, noteRepo *repository.AdminNoteRepo) *AdminNoteHandler {
+	return &AdminNoteHandler{
+		userRepo: userRepo,
+		noteRepo: noteRepo,
+	}
+}
+
+// --- GET /admin/users/{id} ---
+// Support view of a user, including internal notes.
+
+func (h *AdminNoteHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if user == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+
+	notes, err := h.noteRepo.ListForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing admin notes: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user":        user,
+		"admin_notes": notes,
+	})
+}
+
+// --- GET /admin/users/{id}/notes ---
+
+func (h *AdminNoteHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	notes, err := h.noteRepo.ListForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing admin notes: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"admin_notes": notes,
+	})
+}
+
+// --- POST /admin/users/{id}/notes ---
+
+type CreateAdminNoteRequest struct {
+	Text string `json:"text"`
+}
+
+func (h *AdminNoteHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	var req CreateAdminNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" || utf8.RuneCountInString(req.Text) > maxAdminNoteLength {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "text must be 1-2000 characters"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if user == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+
+	note := &models.AdminNote{
+		UserID: userID,
+		Author: middleware.GetAdminName(r.Context()),
+		Text:   req.Text,
+	}
+	if err := h.noteRepo.Create(r.Context(), note); err != nil {
+		log.Printf("Error creating admin note: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, note)
+}
+
+// --- DELETE /admin/users/{id}/notes/{noteID} ---
+
+func (h *AdminNoteHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+	noteID, err := bson.ObjectIDFromHex(chi.URLParam(r, "noteID"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid note ID"})
+		return
+	}
+
+	deleted, err := h.noteRepo.Delete(r.Context(), userID, noteID)
+	if err != nil {
+		log.Printf("Error deleting admin note: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "note not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "note deleted"})
+}
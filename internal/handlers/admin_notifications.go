@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type AdminNotificationHandler struct {
+	repo *repository.AdminNotificationRepo
+}
+
+func NewAdminNotificationHandler(repo *repository.AdminNotificationRepo) *AdminNotificationHandler {
+	return &AdminNotificationHandler{
+		repo: repo,
+	}
+}
+
+// --- GET /admin/notifications?unread=true&kind=abuse&before=<id>&limit=50 ---
+// Read state is the calling admin key's; pass the last ID of a page as
+// before to get the next one.
+
+func (h *AdminNotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.AdminNotificationFilter{
+		Kind:       query.Get("kind"),
+		UnreadOnly: query.Get("unread") == "true",
+	}
+	if raw := query.Get("before"); raw != "" {
+		before, err := bson.ObjectIDFromHex(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid before ID"})
+			return
+		}
+		filter.Before = before
+	}
+	limit := int64(50)
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 200 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+
+	admin := middleware.GetAdminName(r.Context())
+	notifications, err := h.repo.List(r.Context(), admin, filter, limit)
+	if err != nil {
+		log.Printf("Error listing admin notifications: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	unread, err := h.repo.CountUnread(r.Context(), admin)
+	if err != nil {
+		log.Printf("Error counting unread admin notifications: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []string `json:"ids"` // empty marks everything read
+}
+
+// --- POST /admin/notifications/read ---
+
+func (h *AdminNotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	var req MarkNotificationsReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	ids := make([]bson.ObjectID, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := bson.ObjectIDFromHex(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid notification ID: " + raw})
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	marked, err := h.repo.MarkRead(r.Context(), middleware.GetAdminName(r.Context()), ids)
+	if err != nil {
+		log.Printf("Error marking admin notifications read: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to mark notifications read"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"marked": marked})
+}
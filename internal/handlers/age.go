@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/service"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type SetAgeRequest struct {
+	DateOfBirth string `json:"date_of_birth"` // YYYY-MM-DD; used to derive the band, never stored
+	AgeBand     string `json:"age_band"`      // alternative to date_of_birth
+	Country     string `json:"country"`       // ISO 3166-1 alpha-2
+}
+
+// --- POST /user/age ---
+
+func (h *UserHandler) SetAge(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var req SetAgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	result, err := h.users.SetAge(r.Context(), userID, service.AgeInput{
+		DateOfBirth: req.DateOfBirth,
+		AgeBand:     req.AgeBand,
+		Country:     req.Country,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Error saving age")
+		return
+	}
+
+	if !result.Allowed {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"error":       "you must be at least the minimum age to use Rizon",
+			"code":        "age_restricted",
+			"minimum_age": result.MinimumAge,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":  "age recorded",
+		"age_band": result.Band,
+	})
+}
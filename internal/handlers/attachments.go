@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/imaging"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/signedurl"
+	"rizon-backend/internal/virusscan"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxAttachmentBytes keeps each blob well below Mongo's 16MB document limit.
+const maxAttachmentBytes = 10 << 20
+
+// attachmentLinkTTL is how long download links in API responses stay valid.
+const attachmentLinkTTL = time.Hour
+
+type AttachmentHandler struct {
+	attachmentRepo *repository.AttachmentRepo
+	userRepo       *repository.UserRepo
+	signer         *signedurl.Signer
+	baseURL        string
+	quota          int64 // bytes per user
+
+	scanner      virusscan.Scanner
+	quarantine   *repository.QuarantineRepo
+	scanFailOpen bool // store files unscanned when the scanner is down
+}
+
+func NewAttachmentHandler(attachmentRepo *repository.AttachmentRepo, userRepo *repository.UserRepo, signer *signedurl.Signer, baseURL string, quota int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentRepo: attachmentRepo,
+		userRepo:       userRepo,
+		signer:         signer,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		quota:          quota,
+	}
+}
+
+// WithScanner scans every upload before it is stored. Infected files go to
+// quarantine for admin review. Unless failOpen is set, uploads are refused
+// while the scanner is unavailable.
+func (h *AttachmentHandler) WithScanner(scanner virusscan.Scanner, quarantine *repository.QuarantineRepo, failOpen bool) *AttachmentHandler {
+	h.scanner = scanner
+	h.quarantine = quarantine
+	h.scanFailOpen = failOpen
+	return h
+}
+
+// --- POST /user/attachments ---
+// Multipart upload in the "file" field. Identical files are stored once.
+// With a scanner configured, infected files are quarantined and rejected
+// with code "infected_file".
+
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	// Leave room for the multipart envelope around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentBytes+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "file upload is required"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAttachmentBytes+1))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read upload"})
+		return
+	}
+	if len(data) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "file is empty"})
+		return
+	}
+	if len(data) > maxAttachmentBytes {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "file is too large"})
+		return
+	}
+
+	used, err := h.attachmentRepo.UsageForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error loading attachment usage: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if used+int64(len(data)) > h.quota {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{
+			"error": "attachment quota exceeded",
+			"used":  used,
+			"quota": h.quota,
+		})
+		return
+	}
+
+	filename := cleanFilename(header.Filename)
+	if h.scanner != nil {
+		result, err := h.scanner.Scan(r.Context(), data)
+		switch {
+		case err != nil && !h.scanFailOpen:
+			log.Printf("Error scanning attachment: %v", err)
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+				"error": "file scanning is unavailable, please try again later",
+				"code":  "scan_unavailable",
+			})
+			return
+		case err != nil:
+			log.Printf("⚠️  Attachment stored unscanned: %v", err)
+		case result.Infected:
+			h.quarantineUpload(w, r, userID, filename, data, result)
+			return
+		}
+	}
+
+	attachment := &models.Attachment{UserID: userID, Filename: filename}
+	if err := h.save(r.Context(), attachment, data); err != nil {
+		if errors.Is(err, errInvalidImage) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		log.Printf("Error saving attachment: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	h.sign(attachment)
+	writeJSON(w, http.StatusCreated, attachment)
+}
+
+// --- GET /user/attachments ---
+
+func (h *AttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	attachments, err := h.attachmentRepo.ListForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing attachments: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	var used int64
+	for i := range attachments {
+		used += int64(attachments[i].Size)
+		h.sign(&attachments[i])
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"attachments": attachments,
+		"used":        used,
+		"quota":       h.quota,
+	})
+}
+
+// --- DELETE /user/attachments/{id} ---
+
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	attachmentID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid attachment ID"})
+		return
+	}
+
+	deleted, err := h.attachmentRepo.Delete(r.Context(), userID, attachmentID)
+	if err != nil {
+		log.Printf("Error deleting attachment: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "attachment not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- PUT /user/avatar ---
+
+type SetAvatarRequest struct {
+	AttachmentID string `json:"attachment_id"`
+}
+
+func (h *AttachmentHandler) SetAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var req SetAvatarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	attachmentID, err := bson.ObjectIDFromHex(req.AttachmentID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid attachment ID"})
+		return
+	}
+
+	attachment, err := h.attachmentRepo.FindByID(r.Context(), attachmentID)
+	if err != nil {
+		log.Printf("Error loading attachment: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if attachment == nil || attachment.UserID != userID {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "attachment not found"})
+		return
+	}
+	if len(attachment.Variants) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "avatar must be an image"})
+		return
+	}
+
+	if err := h.userRepo.SetAvatar(r.Context(), userID, attachmentID); err != nil {
+		log.Printf("Error setting avatar: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	h.sign(attachment)
+	writeJSON(w, http.StatusOK, attachment)
+}
+
+// --- GET /user/avatar ---
+
+func (h *AttachmentHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	user, err := h.userRepo.FindByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if user == nil || user.AvatarID == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no avatar set"})
+		return
+	}
+
+	attachment, err := h.attachmentRepo.FindByID(r.Context(), *user.AvatarID)
+	if err != nil {
+		log.Printf("Error loading avatar: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if attachment == nil {
+		// The attachment was deleted after being set as avatar
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no avatar set"})
+		return
+	}
+
+	h.sign(attachment)
+	writeJSON(w, http.StatusOK, attachment)
+}
+
+// DownloadSource serves attachments through /download. Names are
+// "<id>" for the attachment itself or "<id>/<variant>" for an image variant.
+func (h *AttachmentHandler) DownloadSource() DownloadSource {
+	return func(ctx context.Context, name string) (*Download, error) {
+		idHex, variant, _ := strings.Cut(name, "/")
+		attachmentID, err := bson.ObjectIDFromHex(idHex)
+		if err != nil {
+			return nil, nil
+		}
+		attachment, err := h.attachmentRepo.FindByID(ctx, attachmentID)
+		if err != nil || attachment == nil {
+			return nil, err
+		}
+
+		hash, contentType := attachment.SHA256, attachment.ContentType
+		if variant != "" {
+			found := false
+			for _, v := range attachment.Variants {
+				if v.Name == variant {
+					hash, contentType, found = v.SHA256, v.ContentType, true
+					break
+				}
+			}
+			if !found {
+				return nil, nil
+			}
+		}
+
+		blob, err := h.attachmentRepo.FindBlob(ctx, hash)
+		if err != nil || blob == nil {
+			return nil, err
+		}
+		return &Download{
+			Filename:    attachment.Filename,
+			ContentType: contentType,
+			Data:        blob.Data,
+		}, nil
+	}
+}
+
+// errInvalidImage is returned by save for image uploads imaging can't handle.
+var errInvalidImage = errors.New("unsupported or invalid image")
+
+// save stores data as the attachment's blobs and creates the attachment.
+// Images (JPEG, PNG) are decoded, stripped of metadata and stored as
+// thumbnail and full variants; the original bytes are never kept.
+func (h *AttachmentHandler) save(ctx context.Context, attachment *models.Attachment, data []byte) error {
+	attachment.ContentType = http.DetectContentType(data) // don't trust the client's header
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		outputs, err := imaging.Process(data, imaging.DefaultVariants)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errInvalidImage, err)
+		}
+		for _, out := range outputs {
+			hash, err := h.putBlob(ctx, out.Data)
+			if err != nil {
+				return fmt.Errorf("storing image variant: %w", err)
+			}
+			attachment.Variants = append(attachment.Variants, models.ImageVariant{
+				Name:        out.Name,
+				SHA256:      hash,
+				ContentType: out.ContentType,
+				Width:       out.Width,
+				Height:      out.Height,
+				Size:        len(out.Data),
+			})
+			// The largest variant stands in for the original
+			attachment.SHA256 = hash
+			attachment.ContentType = out.ContentType
+			attachment.Size = len(out.Data)
+		}
+	} else {
+		hash, err := h.putBlob(ctx, data)
+		if err != nil {
+			return fmt.Errorf("storing blob: %w", err)
+		}
+		attachment.SHA256 = hash
+		attachment.Size = len(data)
+	}
+
+	if err := h.attachmentRepo.Create(ctx, attachment); err != nil {
+		return fmt.Errorf("creating attachment: %w", err)
+	}
+	return nil
+}
+
+// putBlob stores data under its SHA-256 and returns the hash.
+func (h *AttachmentHandler) putBlob(ctx context.Context, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	_, err := h.attachmentRepo.PutBlob(ctx, hash, data)
+	return hash, err
+}
+
+// sign fills in signed download links for the attachment and its variants.
+func (h *AttachmentHandler) sign(attachment *models.Attachment) {
+	base := "attachments/" + attachment.ID.Hex()
+	attachment.URL = h.baseURL + "/download/" + h.signer.Sign(base, attachmentLinkTTL)
+	for i := range attachment.Variants {
+		v := &attachment.Variants[i]
+		v.URL = h.baseURL + "/download/" + h.signer.Sign(base+"/"+v.Name, attachmentLinkTTL)
+	}
+}
+
+// cleanFilename drops any client-side directories and caps the length.
+func cleanFilename(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
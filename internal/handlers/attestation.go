@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"rizon-backend/internal/attestation"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	attestationChallengeTTL = 5 * time.Minute
+	attestationPassTTL      = 10 * time.Minute
+)
+
+type AttestationHandler struct {
+	attestationRepo *repository.AttestationRepo
+	verifier        *attestation.Verifier
+}
+
+func NewAttestationHandler(attestationRepo *repository.AttestationRepo, verifier *attestation.Verifier) *AttestationHandler {
+	return &AttestationHandler{
+		attestationRepo: attestationRepo,
+		verifier:        verifier,
+	}
+}
+
+type AttestRequest struct {
+	Platform  string `json:"platform"`  // "android" or "ios"
+	Token     string `json:"token"`     // Play Integrity token or base64 App Attest object
+	KeyID     string `json:"key_id"`    // App Attest key ID (ios only)
+	Challenge string `json:"challenge"` // from GET /auth/attest/challenge
+}
+
+// --- GET /auth/attest/challenge ---
+
+func (h *AttestationHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := h.attestationRepo.CreateChallenge(r.Context(), challenge, attestationChallengeTTL); err != nil {
+		log.Printf("Error creating attestation challenge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"challenge":  challenge,
+		"expires_in": int(attestationChallengeTTL.Seconds()),
+	})
+}
+
+// --- POST /auth/attest ---
+
+func (h *AttestationHandler) Attest(w http.ResponseWriter, r *http.Request) {
+	var req AttestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Token == "" || req.Challenge == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "token and challenge are required"})
+		return
+	}
+
+	valid, err := h.attestationRepo.ConsumeChallenge(r.Context(), req.Challenge)
+	if err != nil {
+		log.Printf("Error consuming attestation challenge: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if !valid {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid or expired challenge"})
+		return
+	}
+
+	result, err := h.verifier.Verify(r.Context(), req.Platform, req.Token, req.KeyID, req.Challenge)
+	if err != nil {
+		if errors.Is(err, attestation.ErrNotConfigured) {
+			writeJSON(w, http.StatusNotImplemented, map[string]string{"error": err.Error()})
+			return
+		}
+		log.Printf("Attestation verification error (%s): %v", req.Platform, err)
+		result = &attestation.Result{Passed: false, Reasons: []string{err.Error()}}
+	}
+
+	record := &models.Attestation{
+		Platform:  req.Platform,
+		Passed:    result.Passed,
+		Reasons:   result.Reasons,
+		Details:   result.Details,
+		KeyID:     req.KeyID,
+		IP:        r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		ExpiresAt: time.Now().Add(attestationPassTTL),
+	}
+	if result.Passed {
+		record.PassToken = uuid.New().String()
+	}
+	if err := h.attestationRepo.Create(r.Context(), record); err != nil {
+		log.Printf("Error recording attestation: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if !result.Passed {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"error":   "attestation failed",
+			"reasons": result.Reasons,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"attestation_token": record.PassToken,
+		"expires_at":        record.ExpiresAt,
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"rizon-backend/internal/campaign"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type AudienceHandler struct {
+	audiences *service.AudienceService
+	queue     *jobs.Queue
+}
+
+func NewAudienceHandler(audiences *service.AudienceService, queue *jobs.Queue) *AudienceHandler {
+	return &AudienceHandler{
+		audiences: audiences,
+		queue:     queue,
+	}
+}
+
+type AudienceRequest struct {
+	Name   string                `json:"name"`
+	Filter models.AudienceFilter `json:"filter"`
+}
+
+// --- GET /admin/audiences ---
+
+func (h *AudienceHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.audiences.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing audiences: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"audiences": list})
+}
+
+// --- POST /admin/audiences ---
+
+func (h *AudienceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req AudienceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	audience, err := h.audiences.Create(r.Context(), req.Name, req.Filter, middleware.GetAdminName(r.Context()))
+	if err != nil {
+		writeServiceError(w, err, "Error creating audience")
+		return
+	}
+	writeJSON(w, http.StatusCreated, audience)
+}
+
+// --- PUT /admin/audiences/{id} ---
+
+func (h *AudienceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience ID"})
+		return
+	}
+	var req AudienceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	audience, err := h.audiences.Update(r.Context(), id, req.Name, req.Filter)
+	if err != nil {
+		writeServiceError(w, err, "Error updating audience")
+		return
+	}
+	writeJSON(w, http.StatusOK, audience)
+}
+
+// --- DELETE /admin/audiences/{id} ---
+
+func (h *AudienceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience ID"})
+		return
+	}
+	if err := h.audiences.Delete(r.Context(), id); err != nil {
+		writeServiceError(w, err, "Error deleting audience")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "audience deleted"})
+}
+
+// --- POST /admin/audiences/preview ---
+// Estimates the size of an unsaved filter, sent as the body.
+
+func (h *AudienceHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	var filter models.AudienceFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	h.writeEstimate(w, r, filter)
+}
+
+// --- GET /admin/audiences/{id}/preview ---
+
+func (h *AudienceHandler) PreviewSaved(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience ID"})
+		return
+	}
+	audience, err := h.audiences.Get(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err, "Error loading audience")
+		return
+	}
+	h.writeEstimate(w, r, audience.Filter)
+}
+
+func (h *AudienceHandler) writeEstimate(w http.ResponseWriter, r *http.Request, filter models.AudienceFilter) {
+	size, err := h.audiences.Estimate(r.Context(), filter)
+	if err != nil {
+		writeServiceError(w, err, "Error estimating audience")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"filter":         filter,
+		"estimated_size": size,
+	})
+}
+
+type CampaignRequest struct {
+	// Either a saved audience or an inline filter; neither means every
+	// active user.
+	AudienceID string                 `json:"audience_id"`
+	Filter     *models.AudienceFilter `json:"filter"`
+	Subject    string                 `json:"subject"`
+	Message    string                 `json:"message"`
+	LinkURL    string                 `json:"link_url"`
+	LinkLabel  string                 `json:"link_label"`
+}
+
+// --- POST /admin/campaigns ---
+// Emails an announcement to an audience in the background. Users who turned
+// product emails off are skipped. Push campaigns aren't supported yet:
+// devices don't register push tokens and there is no push provider.
+
+func (h *AudienceHandler) SendCampaign(w http.ResponseWriter, r *http.Request) {
+	var req CampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.Subject = strings.TrimSpace(req.Subject)
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Subject == "" || req.Message == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "subject and message are required"})
+		return
+	}
+	if req.LinkURL != "" {
+		if u, err := url.Parse(req.LinkURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "link_url must be an http(s) URL"})
+			return
+		}
+	}
+
+	var filter models.AudienceFilter
+	switch {
+	case req.AudienceID != "" && req.Filter != nil:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "send either audience_id or filter, not both"})
+		return
+	case req.AudienceID != "":
+		id, err := bson.ObjectIDFromHex(req.AudienceID)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid audience ID"})
+			return
+		}
+		audience, err := h.audiences.Get(r.Context(), id)
+		if err != nil {
+			writeServiceError(w, err, "Error loading audience")
+			return
+		}
+		filter = audience.Filter
+	case req.Filter != nil:
+		filter = *req.Filter
+	}
+	if err := service.NormalizeFilter(&filter); err != nil {
+		writeServiceError(w, err, "Error validating audience")
+		return
+	}
+
+	job, err := h.queue.Enqueue(r.Context(), campaign.JobType, campaign.Payload{
+		Audience:  filter,
+		Subject:   req.Subject,
+		Message:   req.Message,
+		LinkURL:   req.LinkURL,
+		LinkLabel: req.LinkLabel,
+	}, middleware.GetAdminName(r.Context()))
+	if err != nil {
+		log.Printf("Error enqueueing campaign: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start campaign"})
+		return
+	}
+	log.Printf("📣 Campaign %q queued by %s", req.Subject, middleware.GetAdminName(r.Context()))
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message": "campaign started",
+		"job":     job,
+	})
+}
@@ -1,44 +1,141 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
+	"rizon-backend/internal/abuse"
+	"rizon-backend/internal/geo"
+	"rizon-backend/internal/i18n"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/middleware"
 	"rizon-backend/internal/models"
+	"rizon-backend/internal/redact"
 	"rizon-backend/internal/repository"
+	"rizon-backend/internal/service"
+	"rizon-backend/internal/templates"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
-	"github.com/resend/resend-go/v2"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type AuthHandler struct {
-	tokenRepo *repository.AuthTokenRepo
-	userRepo  *repository.UserRepo
-	jwtSecret string
+	auth          *service.AuthService
+	users         *service.UserService
+	loginLinkRepo *repository.LoginLinkRepo
+	mailer        *mailer.Mailer
+	abuse         *abuse.Detector
+	geo           geo.Resolver
+	orgs          *service.OrgService
+	sso           *service.SSOService
+	passkeys      *service.PasskeyService
+	auditRepo     *repository.AuditRepo
+	// Enables /auth/test-login when set (never in production)
+	testLoginSecret string
+	legal           models.LegalVersions
+	appLinks        AppLinks
 }
 
-func NewAuthHandler(tokenRepo *repository.AuthTokenRepo, userRepo *repository.UserRepo, jwtSecret string) *AuthHandler {
+func NewAuthHandler(auth *service.AuthService, users *service.UserService, loginLinkRepo *repository.LoginLinkRepo, mail *mailer.Mailer, legal models.LegalVersions, appLinks AppLinks) *AuthHandler {
 	return &AuthHandler{
-		tokenRepo: tokenRepo,
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		auth:          auth,
+		users:         users,
+		loginLinkRepo: loginLinkRepo,
+		mailer:        mail,
+		legal:         legal,
+		appLinks:      appLinks,
+	}
+}
+
+// WithAbuseDetection feeds login requests and failed verifications to the
+// abuse detector.
+func (h *AuthHandler) WithAbuseDetection(detector *abuse.Detector) *AuthHandler {
+	h.abuse = detector
+	return h
+}
+
+// WithGeo resolves the signup location of new users.
+func (h *AuthHandler) WithGeo(resolver geo.Resolver) *AuthHandler {
+	h.geo = resolver
+	return h
+}
+
+// WithOrganizations brands login emails and the redirect page for users
+// whose email domain belongs to an organization.
+func (h *AuthHandler) WithOrganizations(orgs *service.OrgService) *AuthHandler {
+	h.orgs = orgs
+	return h
+}
+
+// WithSSO refuses magic links to users whose organization enforces single
+// sign-on, pointing them at its SSO start page instead.
+func (h *AuthHandler) WithSSO(sso *service.SSOService) *AuthHandler {
+	h.sso = sso
+	return h
+}
+
+// WithAudit lets support send login links, recording each in the audit log.
+func (h *AuthHandler) WithAudit(auditRepo *repository.AuditRepo) *AuthHandler {
+	h.auditRepo = auditRepo
+	return h
+}
+
+// orgForEmail returns the organization owning the email's domain, if any.
+// Lookup errors only cost the branding.
+func (h *AuthHandler) orgForEmail(ctx context.Context, email string) *models.Organization {
+	if h.orgs == nil {
+		return nil
+	}
+	org, err := h.orgs.ForEmail(ctx, email)
+	if err != nil {
+		log.Printf("Error finding organization for email: %v", err)
+	}
+	return org
+}
+
+// verifyFailed reports a bad login token to the abuse detector.
+func (h *AuthHandler) verifyFailed(r *http.Request) {
+	if h.abuse != nil {
+		h.abuse.VerifyFailed(r.Context(), middleware.ClientIP(r))
 	}
 }
 
 // --- Request / Response types ---
 
 type RequestLoginRequest struct {
-	Email string `json:"email"`
+	Email   string          `json:"email"`
+	Consent *ConsentRequest `json:"consent,omitempty"` // sent from the sign-up screen
+	// Also email a 6-digit code for /auth/verify-code, for when the link
+	// can't open the app
+	Code bool `json:"code,omitempty"`
 }
 
+// VerifyResponse carries a new session. Token is the access token sent as
+// the Bearer header; it expires after ExpiresIn seconds, and RefreshToken
+// gets the next one from /auth/refresh.
 type VerifyResponse struct {
-	Token string       `json:"token"`
-	User  *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int64        `json:"expires_in"`
+	User         *models.User `json:"user"`
+}
+
+func newVerifyResponse(session *service.Session, user *models.User) VerifyResponse {
+	return VerifyResponse{
+		Token:        session.AccessToken,
+		RefreshToken: session.RefreshToken,
+		ExpiresIn:    session.ExpiresIn,
+		User:         user,
+	}
 }
 
 // --- POST /auth/request ---
@@ -54,49 +151,28 @@ func (h *AuthHandler) RequestLogin(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email is required"})
 		return
 	}
-
-	// Rate limiting: max 5 requests per email in 10 minutes
-	count, err := h.tokenRepo.CountRecentByEmail(r.Context(), req.Email, 10*time.Minute)
-	if err != nil {
-		log.Printf("Error checking rate limit: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-		return
-	}
-	if count >= 5 {
-		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many login requests, please try again later"})
+	consent, msg := h.pendingConsent(r, req.Consent)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
 		return
 	}
 
-	// Generate unique token
-	tokenValue := uuid.New().String()
-
-	// Store token in DB with 15-minute expiry
-	authToken := &models.AuthToken{
-		Email:     req.Email,
-		Token:     tokenValue,
-		ExpiresAt: time.Now().Add(15 * time.Minute),
-		IsUsed:    false,
+	if h.abuse != nil {
+		h.abuse.LoginRequested(r.Context(), middleware.ClientIP(r), req.Email)
 	}
-	if err := h.tokenRepo.Create(r.Context(), authToken); err != nil {
-		log.Printf("Error creating auth token: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create login token"})
+
+	if h.requireSSO(w, r, req.Email) {
 		return
 	}
 
-	// Build the HTTPS redirect URL (email-safe) instead of rizon:// directly
-	// Gmail/Outlook strip custom URL schemes, so we link to our server first
-	// Dynamically detect the base URL from the incoming request
-	baseURL := os.Getenv("BASE_URL")
-	if baseURL == "" {
-		scheme := "http"
-		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
-			scheme = "https"
-		}
-		baseURL = fmt.Sprintf("%s://%s", scheme, r.Host)
+	authToken, err := h.auth.CreateLoginToken(r.Context(), req.Email, consent, req.Code)
+	if err != nil {
+		writeServiceError(w, err, "Error creating login token")
+		return
 	}
-	emailLink := fmt.Sprintf("%s/auth/redirect?token=%s", baseURL, tokenValue)
 
-	if err := sendLoginEmail(req.Email, emailLink); err != nil {
+	emailLink, brand := h.emailLink(r, req.Email, authToken.Token)
+	if err := h.sendLoginEmail(r.Context(), req.Email, emailLink, authToken.Code, brand, h.loginEmailOptions(r, req.Email)); err != nil {
 		log.Printf("Error sending email: %v", err)
 		// Don't fail the request — token is created, email sending is best-effort
 		writeJSON(w, http.StatusOK, map[string]string{
@@ -106,11 +182,58 @@ func (h *AuthHandler) RequestLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	message := "login link sent to your email"
+	if authToken.Code != "" {
+		message = "login link and code sent to your email"
+	}
 	writeJSON(w, http.StatusOK, map[string]string{
-		"message": "login link sent to your email",
+		"message": message,
 	})
 }
 
+// pendingConsent turns the consent sent from the sign-up screen into the
+// record stored on sign-in. It returns a message when the consent is for
+// outdated documents.
+func (h *AuthHandler) pendingConsent(r *http.Request, req *ConsentRequest) (*models.PendingConsent, string) {
+	if req == nil {
+		return nil, ""
+	}
+	if msg := req.validate(h.legal); msg != "" {
+		return nil, msg
+	}
+	return &models.PendingConsent{
+		TermsVersion:   req.TermsVersion,
+		PrivacyVersion: req.PrivacyVersion,
+		MarketingOptIn: req.MarketingOptIn,
+		IP:             middleware.ClientIP(r),
+		UserAgent:      r.UserAgent(),
+		GivenAt:        time.Now(),
+	}, ""
+}
+
+// requireSSO answers 403 with the organization's SSO URL when the email's
+// organization enforces single sign-on, and reports whether it did.
+func (h *AuthHandler) requireSSO(w http.ResponseWriter, r *http.Request, email string) bool {
+	if h.sso == nil {
+		return false
+	}
+	org, err := h.sso.Required(r.Context(), email)
+	if err != nil {
+		log.Printf("Error checking SSO enforcement: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return true
+	}
+	if org != nil {
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"error":   "your organization requires single sign-on",
+			"code":    "sso_required",
+			"sso_url": requestBaseURL(r) + "/auth/sso/" + url.PathEscape(org.Slug) + "/start",
+		})
+		return true
+	}
+	return false
+}
+
 // --- GET /auth/verify ---
 
 func (h *AuthHandler) VerifyToken(w http.ResponseWriter, r *http.Request) {
@@ -120,64 +243,210 @@ func (h *AuthHandler) VerifyToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Find token in DB
-	authToken, err := h.tokenRepo.FindByToken(r.Context(), tokenValue)
+	authToken, err := h.auth.RedeemLoginToken(r.Context(), tokenValue)
+	switch {
+	case errors.Is(err, service.ErrTokenInvalid), errors.Is(err, service.ErrTokenExpired),
+		errors.Is(err, service.ErrTokenSuperseded), errors.Is(err, service.ErrTokenUsed):
+		h.verifyFailed(r)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error redeeming token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	h.signIn(w, r, authToken.Email, authToken.Consent)
+}
+
+// --- POST /auth/verify-code ---
+// Signs in with the code from the login email, typed into the app by users
+// whose email client won't open the link. Answers like /auth/verify.
+
+type VerifyCodeRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+func (h *AuthHandler) VerifyCode(w http.ResponseWriter, r *http.Request) {
+	var req VerifyCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Email == "" || req.Code == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email and code are required"})
+		return
+	}
+
+	authToken, err := h.auth.RedeemLoginCode(r.Context(), req.Email, strings.TrimSpace(req.Code))
+	switch {
+	case errors.Is(err, service.ErrCodeAttempts):
+		h.verifyFailed(r)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error(), "code": "code_attempts_exceeded"})
+		return
+	case errors.Is(err, service.ErrCodeInvalid), errors.Is(err, service.ErrTokenExpired),
+		errors.Is(err, service.ErrTokenSuperseded), errors.Is(err, service.ErrTokenUsed):
+		h.verifyFailed(r)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error redeeming login code: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	h.signIn(w, r, authToken.Email, authToken.Consent)
+}
+
+// signIn finds or creates the user for an email the caller has proven they
+// own and starts a session, answering like /auth/verify.
+func (h *AuthHandler) signIn(w http.ResponseWriter, r *http.Request, email string, consent *models.PendingConsent) {
+	user, err := h.users.Provision(r.Context(), email)
 	if err != nil {
-		log.Printf("Error finding token: %v", err)
+		log.Printf("Error provisioning user: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
-	if authToken == nil {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+
+	if user.SignupGeo == nil && h.geo != nil {
+		loc := h.geo.Resolve(r)
+		locale, timeZone := geo.Defaults(loc)
+		if err := h.users.SetSignupGeo(r.Context(), user, loc, locale, timeZone); err != nil {
+			log.Printf("Error saving signup location: %v", err)
+		}
+	}
+
+	if restriction := middleware.AccountRestriction(user); restriction != nil {
+		writeJSON(w, http.StatusForbidden, restriction)
+		return
+	}
+
+	if err := h.auth.RecordSignupConsent(r.Context(), user, consent); err != nil {
+		log.Printf("Error recording sign-up consent: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
-	// Validate: not expired
-	if authToken.IsExpired() {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "token has expired"})
+	session, err := h.auth.StartSession(r.Context(), user, sessionClient(r))
+	if err != nil {
+		log.Printf("Error starting session: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newVerifyResponse(session, user))
+}
+
+// --- POST /auth/refresh ---
+// Exchanges a refresh token for a new access token and refresh token. The
+// presented refresh token stops working; presenting it again ends the
+// session.
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.RefreshToken == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
 		return
 	}
 
-	// Validate: not already used (single-use)
-	if authToken.IsUsed {
-		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "token has already been used"})
+	previous, err := h.auth.RedeemRefreshToken(r.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, service.ErrRefreshTokenInvalid):
+		h.verifyFailed(r)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrRefreshTokenExpired), errors.Is(err, service.ErrRefreshTokenRevoked):
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error redeeming refresh token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
-	// Mark token as used
-	if err := h.tokenRepo.MarkUsed(r.Context(), tokenValue); err != nil {
-		log.Printf("Error marking token as used: %v", err)
+	user, err := h.users.Get(r.Context(), previous.UserID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
+	if restriction := middleware.AccountRestriction(user); restriction != nil {
+		writeJSON(w, http.StatusForbidden, restriction)
+		return
+	}
 
-	// Find or create user
-	user, err := h.userRepo.FindOrCreate(r.Context(), authToken.Email)
+	session, err := h.auth.ContinueSession(r.Context(), user, previous, sessionClient(r))
 	if err != nil {
-		log.Printf("Error finding/creating user: %v", err)
+		log.Printf("Error refreshing session: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
 
-	// Generate JWT with 30-day expiry
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID.Hex(),
-		"email":   user.Email,
-		"exp":     time.Now().Add(30 * 24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
-	})
+	writeJSON(w, http.StatusOK, newVerifyResponse(session, user))
+}
+
+// --- POST /auth/logout ---
+// Revokes the access token the request was made with. Sending the refresh
+// token ends its session too, and all signs the user out on every device.
 
-	tokenString, err := jwtToken.SignedString([]byte(h.jwtSecret))
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+	All          bool   `json:"all,omitempty"`
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
 	if err != nil {
-		log.Printf("Error signing JWT: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.auth.RevokeAccessToken(r.Context(), userID, middleware.GetTokenID(r.Context()), middleware.GetTokenExpiry(r.Context())); err != nil {
+		log.Printf("Error revoking access token: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
+	switch {
+	case req.All:
+		revoked, err := h.auth.RevokeAllSessions(r.Context(), userID)
+		if err != nil {
+			log.Printf("Error revoking sessions: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		log.Printf("🚪 User %s signed out of all devices (%d refresh tokens revoked)", userID.Hex(), revoked)
+	case req.RefreshToken != "":
+		if err := h.auth.RevokeRefreshToken(r.Context(), userID, req.RefreshToken); err != nil {
+			log.Printf("Error revoking refresh token: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+	}
 
-	writeJSON(w, http.StatusOK, VerifyResponse{
-		Token: tokenString,
-		User:  user,
-	})
+	writeJSON(w, http.StatusOK, map[string]string{"message": "signed out"})
+}
+
+func sessionClient(r *http.Request) service.Client {
+	return service.Client{UserAgent: r.UserAgent(), IP: middleware.ClientIP(r)}
 }
 
 // --- GET /auth/redirect ---
@@ -191,88 +460,134 @@ func (h *AuthHandler) RedirectToApp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	deepLink := fmt.Sprintf("rizon://login?token=%s", token)
+	if err := h.loginLinkRepo.RecordClick(r.Context(), token, r.UserAgent(), classifyEmailClient(r.UserAgent())); err != nil {
+		log.Printf("Error recording login link click: %v", err)
+	}
+
+	var org *models.Organization
+	if slug := r.URL.Query().Get("org"); slug != "" && h.orgs != nil {
+		var err error
+		if org, err = h.orgs.BySlug(r.Context(), slug); err != nil {
+			log.Printf("Error loading organization branding: %v", err)
+		}
+	}
+
+	data := redirectPageData{
+		Brand:           service.Brand(org),
+		DeepLink:        template.URL("rizon://login?token=" + url.QueryEscape(token)),
+		IOSStoreURL:     h.appLinks.IOSStoreURL,
+		AndroidStoreURL: h.appLinks.AndroidStoreURL,
+		Platform:        detectPlatform(r.UserAgent()),
+		Locale:          i18n.Match(r.Header.Get("Accept-Language")),
+	}
+	if h.appLinks.WebLoginURL != "" {
+		data.WebLoginURL = h.appLinks.WebLoginURL + "?token=" + url.QueryEscape(token)
+	}
 
 	// Serve an HTML page that:
-	// 1. Immediately tries to open the app via deep link
-	// 2. Shows a fallback button if auto-redirect doesn't work
+	// 1. Immediately tries to open the app via deep link (mobile only)
+	// 2. Falls back to store links / web login if the app doesn't open
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprintf(w, `<!DOCTYPE html>
-<html>
-<head>
-	<meta charset="utf-8">
-	<meta name="viewport" content="width=device-width, initial-scale=1">
-	<title>Opening Rizon...</title>
-	<style>
-		body { font-family: -apple-system, sans-serif; display: flex; justify-content: center; align-items: center; min-height: 100vh; margin: 0; background: #f5f3ff; }
-		.card { text-align: center; padding: 40px; background: white; border-radius: 16px; box-shadow: 0 4px 24px rgba(0,0,0,0.1); max-width: 400px; }
-		h1 { color: #333; font-size: 24px; }
-		p { color: #666; font-size: 16px; line-height: 1.5; }
-		.btn { display: inline-block; background: #6366f1; color: white; padding: 14px 32px; border-radius: 10px; text-decoration: none; font-weight: 600; font-size: 16px; margin-top: 16px; }
-		.btn:hover { background: #4f46e5; }
-		.spinner { width: 40px; height: 40px; border: 4px solid #e5e7eb; border-top: 4px solid #6366f1; border-radius: 50%%; animation: spin 1s linear infinite; margin: 0 auto 20px; }
-		@keyframes spin { to { transform: rotate(360deg); } }
-	</style>
-</head>
-<body>
-	<div class="card">
-		<div class="spinner"></div>
-		<h1>Opening Rizon...</h1>
-		<p>You should be redirected to the app automatically.</p>
-		<p>If nothing happens, tap the button below:</p>
-		<a href="%s" class="btn">Open Rizon App</a>
-	</div>
-	<script>
-		// Auto-redirect to the app deep link
-		window.location.href = "%s";
-	</script>
-</body>
-</html>`, deepLink, deepLink)
+	if err := redirectPage(data.Locale).Execute(w, data); err != nil {
+		log.Printf("Error rendering redirect page: %v", err)
+	}
 }
 
 // --- Helpers ---
 
-func sendLoginEmail(to, link string) error {
-	apiKey := os.Getenv("RESEND_API_KEY")
-	fromEmail := os.Getenv("FROM_EMAIL")
+// emailLink builds the login link for an email with the branding of the
+// email's organization.
+func (h *AuthHandler) emailLink(r *http.Request, email, token string) (string, templates.Brand) {
+	// Build the HTTPS redirect URL (email-safe) instead of rizon:// directly
+	// Gmail/Outlook strip custom URL schemes, so we link to our server first
+	link := fmt.Sprintf("%s/auth/redirect?token=%s", requestBaseURL(r), token)
+	org := h.orgForEmail(r.Context(), email)
+	if org != nil {
+		// Only picks the redirect page's branding, so it needn't be signed
+		link += "&org=" + url.QueryEscape(org.Slug)
+	}
+	return link, service.Brand(org)
+}
 
-	if apiKey == "" {
-		log.Println("⚠️  RESEND_API_KEY not set, skipping email send")
-		log.Printf("📧 [Dev Mode] Login link for %s: %s", to, link)
-		return nil
+// requestBaseURL is BASE_URL, or else detected from the incoming request.
+func requestBaseURL(r *http.Request) string {
+	if baseURL := os.Getenv("BASE_URL"); baseURL != "" {
+		return baseURL
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
+
+// loginEmailOptions picks the language from the request, falling back to
+// the saved locale, and the format from the recipient's preferences if they
+// already have an account.
+func (h *AuthHandler) loginEmailOptions(r *http.Request, email string) templates.EmailOptions {
+	user, err := h.users.FindByEmail(r.Context(), email)
+	if err != nil {
+		log.Printf("Error loading user for email preferences: %v", err)
+	}
+	var saved string
+	if user != nil {
+		saved = user.Locale
+	}
+	return templates.EmailOptionsFor(user, i18n.Match(r.Header.Get("Accept-Language"), saved))
+}
+
+// sendLoginEmail sends the login link, and the code for /auth/verify-code if
+// there is one.
+func (h *AuthHandler) sendLoginEmail(ctx context.Context, to, link, code string, brand templates.Brand, opts templates.EmailOptions) error {
+	content, err := templates.RenderEmail("login_link", opts, map[string]interface{}{"Link": link, "Code": code, "Brand": brand})
+	if err != nil {
+		return fmt.Errorf("failed to render email: %w", err)
 	}
 
-	client := resend.NewClient(apiKey)
-
-	params := &resend.SendEmailRequest{
-		From:    fromEmail,
-		To:      []string{to},
-		Subject: "Your Rizon Login Link",
-		Html: fmt.Sprintf(`
-			<div style="font-family: sans-serif; max-width: 480px; margin: 0 auto; padding: 24px;">
-				<h2 style="color: #333;">Welcome to Rizon! 🚀</h2>
-				<p>Click the button below to log in to your account:</p>
-				<a href="%s" style="display: inline-block; background: #6366f1; color: white; padding: 12px 24px; border-radius: 8px; text-decoration: none; font-weight: 600;">
-					Open Rizon App
-				</a>
-				<p style="color: #888; font-size: 14px; margin-top: 16px;">
-					This link expires in 15 minutes and can only be used once.
-				</p>
-				<p style="color: #aaa; font-size: 12px;">
-					If you didn't request this, you can safely ignore this email.
-				</p>
-			</div>
-		`, link),
-	}
-
-	sent, err := client.Emails.Send(params)
+	sent, err := h.mailer.Send(ctx, mailer.FromRendered(to, content))
 	if err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
-	log.Printf("📧 Email sent successfully (ID: %s) — Link: %s", sent.Id, link)
+	if sent.DevMode {
+		log.Printf("📧 [Dev Mode] Login link for %s: %s", redact.Email(to), link)
+	} else if redact.Enabled() {
+		log.Printf("📧 Email sent successfully via %s (ID: %s) to %s", sent.Provider, sent.MessageID, redact.Email(to))
+	} else {
+		log.Printf("📧 Email sent successfully via %s (ID: %s) — Link: %s", sent.Provider, sent.MessageID, link)
+	}
 	return nil
 }
 
+// writeServiceError maps a service error to a response: validation and rate
+// limit errors go back to the client, anything else is logged under logMsg.
+func writeServiceError(w http.ResponseWriter, err error, logMsg string) {
+	var verr *service.ValidationError
+	var schemaErr *service.SchemaViolationError
+	switch {
+	case errors.As(err, &verr):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": verr.Message})
+	case errors.As(err, &schemaErr):
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":      err.Error(),
+			"code":       "schema_violation",
+			"schema":     schemaErr.Schema,
+			"violations": schemaErr.Violations,
+		})
+	case errors.Is(err, service.ErrRateLimited):
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrOrgNotFound), errors.Is(err, service.ErrInviteNotFound), errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrSchemaNotFound), errors.Is(err, service.ErrAudienceNotFound), errors.Is(err, service.ErrFeedbackNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrNotOrgOwner):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, service.ErrLastOwner), errors.Is(err, service.ErrFeedbackLocked), errors.Is(err, service.ErrIdempotencyKeyInUse):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		log.Printf("%s: %v", logMsg, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
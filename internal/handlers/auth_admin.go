@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/service"
+	"rizon-backend/internal/templates"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type SendLoginLinkRequest struct {
+	Reason string `json:"reason"` // e.g. the support ticket, kept in the audit log
+}
+
+// --- POST /admin/users/{id}/send-login-link ---
+// Emails the user a fresh login link on support's behalf. The link only goes
+// to the user's own address and is never returned to the caller, and every
+// link sent is recorded in the audit log first.
+
+func (h *AuthHandler) AdminSendLoginLink(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+	var req SendLoginLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) > 500 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "reason must be at most 500 characters"})
+		return
+	}
+
+	user, err := h.users.Get(r.Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, "Error finding user")
+		return
+	}
+	if user.Status == models.UserStatusMerged || user.IsRestricted(time.Now()) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "account is merged, suspended or banned"})
+		return
+	}
+	if h.sso != nil {
+		org, err := h.sso.Required(r.Context(), user.Email)
+		if err != nil {
+			writeServiceError(w, err, "Error checking SSO enforcement")
+			return
+		}
+		if org != nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "the user's organization requires single sign-on"})
+			return
+		}
+	}
+
+	authToken, err := h.auth.CreateLoginToken(r.Context(), user.Email, nil, false)
+	if err != nil {
+		writeServiceError(w, err, "Error creating login token")
+		return
+	}
+
+	admin := middleware.GetAdminName(r.Context())
+	if err := h.auditRepo.Record(r.Context(), &models.AuditEntry{
+		Action:   models.AuditLoginLinkSent,
+		Actor:    admin,
+		TargetID: user.ID.Hex(),
+		Details: bson.M{
+			"email":      user.Email,
+			"reason":     req.Reason,
+			"expires_at": authToken.ExpiresAt,
+			"ip":         middleware.ClientIP(r),
+		},
+	}); err != nil {
+		log.Printf("Error recording audit entry: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	link, brand := h.emailLink(r, user.Email, authToken.Token)
+	if err := h.sendLoginEmail(r.Context(), user.Email, link, "", brand, templates.EmailOptionsFor(user, "")); err != nil {
+		log.Printf("Error sending support login link: %v", err)
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "login link created but the email could not be sent"})
+		return
+	}
+
+	log.Printf("🔗 Login link sent to %s by %s", redact.Email(user.Email), admin)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "login link sent",
+		"expires_at": authToken.ExpiresAt,
+	})
+}
+
+// --- GET /admin/auth/jwt-keys ---
+// Tokens verified per JWT signing key on this instance since it started.
+// A rotated-out key can be removed once no instance reports new validations.
+
+func (h *AuthHandler) JWTKeys(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": h.auth.JWTKeys(),
+	})
+}
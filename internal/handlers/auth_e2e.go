@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/redact"
+)
+
+// WithTestLogin enables /auth/test-login for end-to-end suites. Callers must
+// present secret in the X-E2E-Secret header; an empty secret leaves the
+// endpoint answering 404. Never pass a secret in production.
+func (h *AuthHandler) WithTestLogin(secret string) *AuthHandler {
+	h.testLoginSecret = secret
+	return h
+}
+
+type TestLoginRequest struct {
+	Email string `json:"email"`
+}
+
+// --- POST /auth/test-login ---
+// Signs in as any email without the magic link round trip, so Detox and
+// Maestro suites don't have to intercept email. The user is provisioned as
+// on a first login.
+
+func (h *AuthHandler) TestLogin(w http.ResponseWriter, r *http.Request) {
+	if h.testLoginSecret == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-E2E-Secret")), []byte(h.testLoginSecret)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid test secret"})
+		return
+	}
+
+	var req TestLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Email == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email is required"})
+		return
+	}
+
+	user, err := h.users.Provision(r.Context(), req.Email)
+	if err != nil {
+		log.Printf("Error provisioning user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if restriction := middleware.AccountRestriction(user); restriction != nil {
+		writeJSON(w, http.StatusForbidden, restriction)
+		return
+	}
+
+	session, err := h.auth.StartSession(r.Context(), user, sessionClient(r))
+	if err != nil {
+		log.Printf("Error starting session: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	log.Printf("🧪 Test login for %s", redact.Email(user.Email))
+	writeJSON(w, http.StatusOK, newVerifyResponse(session, user))
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/service"
+)
+
+type GoogleLoginRequest struct {
+	IDToken string          `json:"id_token"`
+	Nonce   string          `json:"nonce,omitempty"`   // if the app set one on the Google prompt
+	Consent *ConsentRequest `json:"consent,omitempty"` // sent from the sign-up screen
+}
+
+// --- POST /auth/google ---
+// Signs in with an ID token from Google Sign-In on the device, skipping the
+// magic link email. The user is found or created by the token's verified
+// email and gets the same response as /auth/verify.
+
+func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
+	var req GoogleLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.IDToken == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id_token is required"})
+		return
+	}
+	consent, msg := h.pendingConsent(r, req.Consent)
+	if msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	email, err := h.auth.VerifyGoogleIDToken(r.Context(), req.IDToken, req.Nonce)
+	switch {
+	case errors.Is(err, service.ErrGoogleSignInDisabled):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, service.ErrGoogleTokenInvalid):
+		h.verifyFailed(r)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error verifying Google ID token: %v", err)
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "could not reach google, try again"})
+		return
+	}
+
+	if h.requireSSO(w, r, email) {
+		return
+	}
+	h.signIn(w, r, email, consent)
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/service"
+	"rizon-backend/internal/webauthn"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WithPasskeys lets signed-in users register passkeys and sign in with them
+// later. Without it the passkey endpoints answer 404.
+func (h *AuthHandler) WithPasskeys(passkeys *service.PasskeyService) *AuthHandler {
+	h.passkeys = passkeys
+	return h
+}
+
+type RegisterPasskeyRequest struct {
+	Name       string                         `json:"name,omitempty"` // e.g. "iPhone"; defaults to "Passkey"
+	Credential *webauthn.RegistrationResponse `json:"credential"`
+}
+
+type PasskeyLoginRequest struct {
+	Credential *webauthn.AssertionResponse `json:"credential"`
+}
+
+// currentUser loads the signed-in user, answering the request itself if it
+// can't.
+func (h *AuthHandler) currentUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return nil, false
+	}
+	user, err := h.users.Get(r.Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return nil, false
+	}
+	return user, true
+}
+
+// --- POST /user/passkeys/options ---
+// Starts adding a passkey. The response is the PublicKeyCredentialCreationOptions
+// to hand to the platform's passkey API.
+
+func (h *AuthHandler) PasskeyRegistrationOptions(w http.ResponseWriter, r *http.Request) {
+	if h.passkeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	options, err := h.passkeys.BeginRegistration(r.Context(), user)
+	if err != nil {
+		writeServiceError(w, err, "Error starting passkey registration")
+		return
+	}
+	writeJSON(w, http.StatusOK, options)
+}
+
+// --- POST /user/passkeys ---
+
+func (h *AuthHandler) RegisterPasskey(w http.ResponseWriter, r *http.Request) {
+	if h.passkeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var req RegisterPasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Credential == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "credential is required"})
+		return
+	}
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	cred, err := h.passkeys.FinishRegistration(r.Context(), user, req.Name, req.Credential)
+	switch {
+	case errors.Is(err, service.ErrPasskeyChallenge), errors.Is(err, service.ErrPasskeyInvalid):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrPasskeyExists):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		writeServiceError(w, err, "Error registering passkey")
+		return
+	}
+
+	log.Printf("🔑 User %s added a passkey (%s)", user.ID.Hex(), cred.Name)
+	writeJSON(w, http.StatusCreated, cred)
+}
+
+// --- GET /user/passkeys ---
+
+func (h *AuthHandler) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	if h.passkeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	creds, err := h.passkeys.List(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing passkeys: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"passkeys": creds})
+}
+
+// --- DELETE /user/passkeys/{id} ---
+
+func (h *AuthHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	if h.passkeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid passkey ID"})
+		return
+	}
+
+	err = h.passkeys.Delete(r.Context(), userID, id)
+	if errors.Is(err, service.ErrPasskeyNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting passkey: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- POST /auth/passkey/options ---
+// Starts a passkey sign-in. The response is the PublicKeyCredentialRequestOptions
+// to hand to the platform's passkey API.
+
+func (h *AuthHandler) PasskeyLoginOptions(w http.ResponseWriter, r *http.Request) {
+	if h.passkeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	options, err := h.passkeys.BeginLogin(r.Context())
+	if err != nil {
+		log.Printf("Error starting passkey sign-in: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, options)
+}
+
+// --- POST /auth/passkey ---
+// Signs in with a passkey registered earlier, skipping the magic link email.
+// Answers like /auth/verify.
+
+func (h *AuthHandler) PasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	if h.passkeys == nil {
+		http.NotFound(w, r)
+		return
+	}
+	var req PasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Credential == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "credential is required"})
+		return
+	}
+
+	cred, err := h.passkeys.FinishLogin(r.Context(), req.Credential)
+	switch {
+	case errors.Is(err, service.ErrPasskeyInvalid):
+		h.verifyFailed(r)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrPasskeyUnknown):
+		// The app should forget the passkey and fall back to a magic link
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error(), "code": "passkey_unknown"})
+		return
+	case errors.Is(err, service.ErrPasskeyChallenge):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error verifying passkey: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	user, err := h.users.Get(r.Context(), cred.UserID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "user not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	if h.requireSSO(w, r, user.Email) {
+		return
+	}
+	h.signIn(w, r, user.Email, nil)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/backup"
+	"rizon-backend/internal/signedurl"
+)
+
+// backupLinkTTL keeps backup links short-lived; they're single-use as well.
+const backupLinkTTL = 15 * time.Minute
+
+type BackupHandler struct {
+	store   *backup.S3Store // nil when backups aren't configured
+	signer  *signedurl.Signer
+	baseURL string
+}
+
+func NewBackupHandler(store *backup.S3Store, signer *signedurl.Signer, baseURL string) *BackupHandler {
+	return &BackupHandler{
+		store:   store,
+		signer:  signer,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+type BackupLinkRequest struct {
+	Key string `json:"key"` // S3 object key, e.g. backups/2025/01/02/150405.ndjson.gz
+}
+
+// --- POST /admin/backups/link ---
+// Mints a single-use download link for a backup so the bucket never has to
+// be public.
+
+func (h *BackupHandler) Link(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "backups not configured"})
+		return
+	}
+
+	var req BackupLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Key) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "key is required"})
+		return
+	}
+
+	token, err := h.signer.SignOnce("backups/"+req.Key, backupLinkTTL)
+	if err != nil {
+		log.Printf("Error signing backup link: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"url":        h.baseURL + "/download/" + token,
+		"expires_at": time.Now().Add(backupLinkTTL),
+		"single_use": true,
+	})
+}
+
+// DownloadSource serves backup objects through /download.
+func (h *BackupHandler) DownloadSource() DownloadSource {
+	return func(ctx context.Context, key string) (*Download, error) {
+		if h.store == nil {
+			return nil, nil
+		}
+		data, err := h.store.Get(ctx, key)
+		if errors.Is(err, backup.ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Download{
+			Filename:    path.Base(key),
+			ContentType: "application/gzip",
+			Data:        data,
+		}, nil
+	}
+}
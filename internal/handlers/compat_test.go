@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/service"
+)
+
+// TestServiceErrorStatus pins the v1 status code and body for each service
+// error clients handle.
+func TestServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		body   string
+	}{
+		{service.ErrRateLimited, 429, service.ErrRateLimited.Error()},
+		{service.ErrFeedbackNotFound, 404, service.ErrFeedbackNotFound.Error()},
+		{service.ErrOrgNotFound, 404, service.ErrOrgNotFound.Error()},
+		{service.ErrNotOrgOwner, 403, service.ErrNotOrgOwner.Error()},
+		{service.ErrFeedbackLocked, 409, service.ErrFeedbackLocked.Error()},
+		{service.ErrLastOwner, 409, service.ErrLastOwner.Error()},
+		{&service.ValidationError{Message: "email is required"}, 400, "email is required"},
+		{errors.New("connection reset"), 500, "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeServiceError(rec, tt.err, "test")
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body) != 1 || body["error"] != tt.body {
+				t.Errorf("body = %v, want only error %q", body, tt.body)
+			}
+		})
+	}
+}
+
+// TestRequestLoginRejectsBadInput pins the v1 validation responses of
+// POST /auth/request, which are answered before any service is called.
+func TestRequestLoginRejectsBadInput(t *testing.T) {
+	h := &AuthHandler{}
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"not json", "email=a@example.com", "invalid request body"},
+		{"no email", `{"code":true}`, "email is required"},
+	}
+	for _, tt := range tests {
+		for _, version := range []string{"1", "2"} {
+			t.Run(tt.name+"/v"+version, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodPost, "/auth/request", strings.NewReader(tt.body))
+				r.Header.Set("X-API-Version", version)
+				rec := httptest.NewRecorder()
+				middleware.APIVersion(http.HandlerFunc(h.RequestLogin)).ServeHTTP(rec, r)
+
+				if rec.Code != http.StatusBadRequest {
+					t.Errorf("status = %d, want 400", rec.Code)
+				}
+				if got, want := strings.TrimSpace(rec.Body.String()), `{"error":"`+tt.want+`"}`; got != want {
+					t.Errorf("body = %s, want %s", got, want)
+				}
+			})
+		}
+	}
+}
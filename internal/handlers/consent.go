@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type ConsentHandler struct {
+	consentRepo *repository.ConsentRepo
+	versions    models.LegalVersions
+}
+
+func NewConsentHandler(consentRepo *repository.ConsentRepo, versions models.LegalVersions) *ConsentHandler {
+	return &ConsentHandler{
+		consentRepo: consentRepo,
+		versions:    versions,
+	}
+}
+
+type ConsentRequest struct {
+	TermsVersion   string `json:"terms_version"`
+	PrivacyVersion string `json:"privacy_version"`
+	MarketingOptIn bool   `json:"marketing_opt_in"`
+}
+
+// validate checks that the user agreed to the documents currently in force.
+func (req *ConsentRequest) validate(versions models.LegalVersions) string {
+	if req.TermsVersion != versions.Terms {
+		return "terms_version must be " + versions.Terms
+	}
+	if req.PrivacyVersion != versions.Privacy {
+		return "privacy_version must be " + versions.Privacy
+	}
+	return ""
+}
+
+// --- GET /legal/versions ---
+
+func (h *ConsentHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.versions)
+}
+
+// --- GET /user/consents ---
+
+func (h *ConsentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	latest, err := h.consentRepo.Latest(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error loading consent: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"current":         h.versions,
+		"consent":         latest,
+		"needs_reconsent": !h.versions.Accepts(latest),
+	})
+}
+
+// --- POST /user/consents ---
+
+func (h *ConsentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var req ConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if msg := req.validate(h.versions); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	consent := &models.Consent{
+		UserID:         userID,
+		TermsVersion:   req.TermsVersion,
+		PrivacyVersion: req.PrivacyVersion,
+		MarketingOptIn: req.MarketingOptIn,
+		IP:             middleware.ClientIP(r),
+		UserAgent:      r.UserAgent(),
+		Source:         models.ConsentSourceApp,
+	}
+	if err := h.consentRepo.Create(r.Context(), consent); err != nil {
+		log.Printf("Error saving consent: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save consent"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "consent recorded",
+		"consent": consent,
+	})
+}
+
+// --- GET /admin/users/{id}/consents ---
+
+func (h *ConsentHandler) History(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	consents, err := h.consentRepo.ListForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing consents: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"current":  h.versions,
+		"consents": consents,
+	})
+}
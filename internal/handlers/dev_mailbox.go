@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"rizon-backend/internal/mailer"
+)
+
+// DevMailboxHandler shows the emails caught by the dev mailbox so the
+// magic-link flow can be completed locally without an email provider. With
+// no mailbox (anything but a dev environment) every route answers 404.
+type DevMailboxHandler struct {
+	box *mailer.Mailbox
+}
+
+func NewDevMailboxHandler(box *mailer.Mailbox) *DevMailboxHandler {
+	return &DevMailboxHandler{box: box}
+}
+
+type mailboxEntry struct {
+	*mailer.Captured
+	// Trusted in dev so rizon:// deep links stay clickable
+	Hrefs []template.URL
+}
+
+var mailboxPage = template.Must(template.New("mailbox").Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Dev mailbox</title>
+	<style>
+		body { font-family: -apple-system, sans-serif; margin: 32px; background: #f9fafb; color: #111; }
+		.mail { background: white; border-radius: 8px; padding: 16px 20px; margin-bottom: 12px; box-shadow: 0 1px 4px rgba(0,0,0,0.08); }
+		.meta { color: #6b7280; font-size: 13px; }
+		.links a { display: block; margin-top: 6px; word-break: break-all; }
+		form { display: inline; }
+	</style>
+</head>
+<body>
+	<h1>Dev mailbox</h1>
+	<p class="meta">Emails "sent" by this server, newest first. <a href="/dev/mailbox">Refresh</a> · <a href="/dev/mailbox?format=json">JSON</a></p>
+	{{range .}}
+	<div class="mail">
+		<strong>{{.Subject}}</strong> <a href="/dev/mailbox/{{.ID}}" class="meta">view</a>
+		<div class="meta">To {{.To}} · {{.SentAt.Format "15:04:05"}}</div>
+		<div class="links">{{range .Hrefs}}<a href="{{.}}">{{.}}</a>{{end}}</div>
+	</div>
+	{{else}}
+	<p>No emails yet. Request a login link and reload.</p>
+	{{end}}
+</body>
+</html>`))
+
+func (h *DevMailboxHandler) enabled(w http.ResponseWriter, r *http.Request) bool {
+	if h.box == nil {
+		http.NotFound(w, r)
+		return false
+	}
+	return true
+}
+
+// --- GET /dev/mailbox ---
+// An HTML page with clickable links; ?format=json for scripts.
+
+func (h *DevMailboxHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled(w, r) {
+		return
+	}
+	messages := h.box.List()
+	if r.URL.Query().Get("format") == "json" {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"messages": messages})
+		return
+	}
+
+	entries := make([]mailboxEntry, len(messages))
+	for i, m := range messages {
+		entries[i] = mailboxEntry{Captured: m}
+		for _, link := range m.Links {
+			entries[i].Hrefs = append(entries[i].Hrefs, template.URL(link))
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	mailboxPage.Execute(w, entries)
+}
+
+// --- GET /dev/mailbox/{id} ---
+// Renders the email's HTML body as the recipient would see it.
+
+func (h *DevMailboxHandler) Get(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled(w, r) {
+		return
+	}
+	m := h.box.Get(chi.URLParam(r, "id"))
+	if m == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Message not found"})
+		return
+	}
+	if m.HTML == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(m.Text))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(m.HTML))
+}
+
+// --- DELETE /dev/mailbox ---
+
+func (h *DevMailboxHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled(w, r) {
+		return
+	}
+	h.box.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
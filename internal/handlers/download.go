@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/signedurl"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Download is a file served through a signed link.
+type Download struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// DownloadSource loads the named file, returning nil if it doesn't exist.
+type DownloadSource func(ctx context.Context, name string) (*Download, error)
+
+type DownloadHandler struct {
+	signer  *signedurl.Signer
+	sources map[string]DownloadSource
+}
+
+func NewDownloadHandler(signer *signedurl.Signer) *DownloadHandler {
+	return &DownloadHandler{
+		signer:  signer,
+		sources: map[string]DownloadSource{},
+	}
+}
+
+// WithSource serves signed paths "<kind>/<name>" from source.
+func (h *DownloadHandler) WithSource(kind string, source DownloadSource) *DownloadHandler {
+	h.sources[kind] = source
+	return h
+}
+
+// --- GET /download/{token} ---
+// The token is the credential; see internal/signedurl.
+
+func (h *DownloadHandler) Download(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.signer.Verify(r.Context(), chi.URLParam(r, "token"))
+	switch {
+	case errors.Is(err, signedurl.ErrExpired), errors.Is(err, signedurl.ErrUsed):
+		writeJSON(w, http.StatusGone, map[string]string{"error": err.Error()})
+		return
+	case errors.Is(err, signedurl.ErrInvalid):
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error verifying download token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	kind, name, _ := strings.Cut(claims.Path, "/")
+	source, ok := h.sources[kind]
+	if !ok || name == "" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "file not found"})
+		return
+	}
+
+	file, err := source(r.Context(), name)
+	if err != nil {
+		log.Printf("Error loading %s download: %v", kind, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if file == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "file not found"})
+		return
+	}
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Write(file.Data)
+}
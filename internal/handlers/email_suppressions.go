@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type EmailSuppressionHandler struct {
+	repo *repository.EmailSuppressionRepo
+}
+
+func NewEmailSuppressionHandler(repo *repository.EmailSuppressionRepo) *EmailSuppressionHandler {
+	return &EmailSuppressionHandler{
+		repo: repo,
+	}
+}
+
+// --- GET /admin/email/suppressions?limit=100 ---
+
+func (h *EmailSuppressionHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := int64(100)
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 1000 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		limit = n
+	}
+	suppressions, err := h.repo.List(r.Context(), limit)
+	if err != nil {
+		log.Printf("Error listing email suppressions: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"suppressions": suppressions})
+}
+
+// --- POST /admin/email/suppressions ---
+// Stops queued email (campaigns, onboarding reminders) to an address, e.g.
+// after a bounce or a complaint. Login links are still sent.
+
+type AddEmailSuppressionRequest struct {
+	Email  string `json:"email"`
+	Reason string `json:"reason"`
+}
+
+func (h *EmailSuppressionHandler) Add(w http.ResponseWriter, r *http.Request) {
+	var req AddEmailSuppressionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "a valid email is required"})
+		return
+	}
+
+	suppression := &models.EmailSuppression{
+		Email:     addr.Address,
+		Reason:    req.Reason,
+		CreatedBy: middleware.GetAdminName(r.Context()),
+	}
+	if err := h.repo.Add(r.Context(), suppression); err != nil {
+		log.Printf("Error adding email suppression: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to suppress email"})
+		return
+	}
+	log.Printf("🔕 Queued email to %s suppressed by %s", redact.Email(suppression.Email), suppression.CreatedBy)
+	writeJSON(w, http.StatusCreated, suppression)
+}
+
+// --- DELETE /admin/email/suppressions/{email} ---
+
+func (h *EmailSuppressionHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	email := chi.URLParam(r, "email")
+	found, err := h.repo.Remove(r.Context(), email)
+	if err != nil {
+		log.Printf("Error removing email suppression: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to lift suppression"})
+		return
+	}
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "email is not suppressed"})
+		return
+	}
+	log.Printf("🔔 Email suppression for %s lifted by %s", redact.Email(email), middleware.GetAdminName(r.Context()))
+	writeJSON(w, http.StatusOK, map[string]string{"message": "suppression lifted"})
+}
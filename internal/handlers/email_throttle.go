@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"rizon-backend/internal/bulkmail"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+)
+
+type EmailThrottleHandler struct {
+	repo     *repository.EmailThrottleRepo
+	throttle *bulkmail.Throttle
+}
+
+func NewEmailThrottleHandler(repo *repository.EmailThrottleRepo, throttle *bulkmail.Throttle) *EmailThrottleHandler {
+	return &EmailThrottleHandler{
+		repo:     repo,
+		throttle: throttle,
+	}
+}
+
+// --- GET /admin/email/throttle ---
+
+func (h *EmailThrottleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.throttle.Settings())
+}
+
+// --- PUT /admin/email/throttle ---
+// Replaces the limits for queued email. Transactional email (login links,
+// exports) is never throttled.
+
+func (h *EmailThrottleHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var settings models.EmailThrottle
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := bulkmail.Validate(&settings); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if settings.MaxPerMinute == nil {
+		settings.MaxPerMinute = map[string]int{}
+	}
+	if settings.QuietHours == nil {
+		settings.QuietHours = []models.QuietHours{}
+	}
+	settings.UpdatedAt = time.Now()
+	settings.UpdatedBy = middleware.GetAdminName(r.Context())
+
+	if err := h.repo.Save(r.Context(), &settings); err != nil {
+		log.Printf("Error saving email throttle: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save settings"})
+		return
+	}
+	// Applies here at once; other replicas pick it up on their next refresh
+	if err := h.throttle.Refresh(r.Context()); err != nil {
+		log.Printf("Error refreshing email throttle: %v", err)
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"rizon-backend/internal/adminfeed"
+	"rizon-backend/internal/lifecycle"
+	"rizon-backend/internal/realtime"
+)
+
+type EventsHandler struct {
+	hub     *realtime.Hub
+	drainer *lifecycle.Drainer
+}
+
+func NewEventsHandler(hub *realtime.Hub, drainer *lifecycle.Drainer) *EventsHandler {
+	return &EventsHandler{
+		hub:     hub,
+		drainer: drainer,
+	}
+}
+
+// --- GET /admin/events/stream ---
+// Server-Sent Events feed of realtime events (feedback and announcement changes).
+
+func (h *EventsHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	h.stream(w, r, func(realtime.Event) bool { return true })
+}
+
+// --- GET /admin/notifications/stream ---
+// Server-Sent Events feed of new notification center entries only.
+
+func (h *EventsHandler) Notifications(w http.ResponseWriter, r *http.Request) {
+	h.stream(w, r, func(event realtime.Event) bool { return event.Type == adminfeed.EventType })
+}
+
+// stream relays the hub's events that match to the client until it
+// disconnects or the instance drains.
+func (h *EventsHandler) stream(w http.ResponseWriter, r *http.Request, match func(realtime.Event) bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "streaming not supported"})
+		return
+	}
+
+	done := h.drainer.TrackStream()
+	defer done()
+
+	events, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.drainer.Draining():
+			// Tell the client to reconnect (to another instance)
+			fmt.Fprint(w, "event: reconnect\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !match(event) {
+				continue
+			}
+			payload, err := json.Marshal(event.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/experiments"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ExperimentHandler struct {
+	engine         *experiments.Engine
+	experimentRepo *repository.ExperimentRepo
+	eventRepo      *repository.EventRepo
+}
+
+func NewExperimentHandler(engine *experiments.Engine, experimentRepo *repository.ExperimentRepo, eventRepo *repository.EventRepo) *ExperimentHandler {
+	return &ExperimentHandler{
+		engine:         engine,
+		experimentRepo: experimentRepo,
+		eventRepo:      eventRepo,
+	}
+}
+
+// --- GET /config/experiments ---
+// The caller's variant in each running experiment they're enrolled in.
+// Fetching counts as an exposure, so call it when the variants are applied.
+
+func (h *ExperimentHandler) Config(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"experiments": h.engine.Expose(middleware.GetUserID(r.Context())),
+	})
+}
+
+type SetExperimentRequest struct {
+	Description    string                     `json:"description"`
+	Status         string                     `json:"status"`
+	TrafficPercent int                        `json:"traffic_percent"`
+	Variants       []models.ExperimentVariant `json:"variants"`
+	FlagKey        string                     `json:"flag_key"`
+}
+
+// --- GET /admin/experiments ---
+
+func (h *ExperimentHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.experimentRepo.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing experiments: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"experiments": list})
+}
+
+// --- PUT /admin/experiments/{key} ---
+
+func (h *ExperimentHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req SetExperimentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Status == "" {
+		req.Status = models.ExperimentDraft
+	}
+
+	experiment := &models.Experiment{
+		Key:            chi.URLParam(r, "key"),
+		Description:    req.Description,
+		Status:         req.Status,
+		TrafficPercent: req.TrafficPercent,
+		Variants:       req.Variants,
+		FlagKey:        req.FlagKey,
+		UpdatedBy:      middleware.GetAdminName(r.Context()),
+	}
+	if err := h.engine.Save(r.Context(), experiment); err != nil {
+		if experiments.IsValidationError(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		log.Printf("Error saving experiment: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save experiment"})
+		return
+	}
+	writeJSON(w, http.StatusOK, experiment)
+}
+
+// --- DELETE /admin/experiments/{key} ---
+// Exposure events are kept for later analysis.
+
+func (h *ExperimentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.engine.Delete(r.Context(), chi.URLParam(r, "key"))
+	if err != nil {
+		log.Printf("Error deleting experiment: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete experiment"})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "experiment not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "experiment removed"})
+}
+
+// --- GET /admin/experiments/{key}/results ---
+// Exposed users per variant.
+
+func (h *ExperimentHandler) Results(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "key")
+	experiment, err := h.experimentRepo.Get(r.Context(), key)
+	if err != nil {
+		log.Printf("Error loading experiment: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if experiment == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "experiment not found"})
+		return
+	}
+	results, err := h.eventRepo.ExposureResults(r.Context(), key)
+	if err != nil {
+		log.Printf("Error counting experiment exposures: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"experiment": experiment,
+		"results":    results,
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/dataexport"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// exportCooldown limits users to one export per day.
+const exportCooldown = 24 * time.Hour
+
+type ExportHandler struct {
+	exportRepo *repository.ExportRepo
+	queue      *jobs.Queue
+	exporter   *dataexport.Exporter
+}
+
+func NewExportHandler(exportRepo *repository.ExportRepo, queue *jobs.Queue, exporter *dataexport.Exporter) *ExportHandler {
+	return &ExportHandler{
+		exportRepo: exportRepo,
+		queue:      queue,
+		exporter:   exporter,
+	}
+}
+
+// --- GET /user/export ---
+// Queues an export of the user's data; the download link is emailed when
+// it's ready.
+
+func (h *ExportHandler) Request(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	latest, err := h.latest(r, userID)
+	if err != nil {
+		log.Printf("Error loading latest export: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if latest != nil && latest.Status != models.ExportFailed {
+		if next := latest.CreatedAt.Add(exportCooldown); time.Now().Before(next) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(next).Seconds())+1))
+			writeJSON(w, http.StatusTooManyRequests, map[string]interface{}{
+				"error":           "one export per 24 hours",
+				"export":          latest,
+				"next_allowed_at": next,
+			})
+			return
+		}
+	}
+
+	export := &models.Export{
+		ID:        bson.NewObjectID(),
+		UserID:    userID,
+		Status:    models.ExportPending,
+		ExpiresAt: time.Now().Add(dataexport.LinkTTL),
+	}
+	job, err := h.queue.Enqueue(r.Context(), dataexport.JobType, dataexport.Payload{ExportID: export.ID}, "user:"+userID.Hex())
+	if err != nil {
+		log.Printf("Error enqueueing export: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	export.JobID = job.ID
+	if err := h.exportRepo.Create(r.Context(), export); err != nil {
+		log.Printf("Error creating export: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message": "export started, we'll email you a download link",
+		"export":  export,
+	})
+}
+
+// --- GET /user/export/status ---
+
+func (h *ExportHandler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	latest, err := h.latest(r, userID)
+	if err != nil {
+		log.Printf("Error loading latest export: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if latest == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no export requested"})
+		return
+	}
+
+	resp := map[string]interface{}{"export": latest}
+	if latest.Status == models.ExportReady {
+		resp["download_url"] = h.exporter.DownloadURL(latest.ID, latest.ExpiresAt)
+	}
+	if latest.Status != models.ExportFailed {
+		resp["next_allowed_at"] = latest.CreatedAt.Add(exportCooldown)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// latest returns the user's newest export, marking it failed if its job gave up.
+func (h *ExportHandler) latest(r *http.Request, userID bson.ObjectID) (*models.Export, error) {
+	export, err := h.exportRepo.Latest(r.Context(), userID)
+	if err != nil || export == nil || export.Status != models.ExportPending {
+		return export, err
+	}
+
+	job, err := h.queue.FindByID(r.Context(), export.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.Status == models.JobStatusFailed {
+		export.Status = models.ExportFailed
+		if err := h.exportRepo.SetStatus(r.Context(), export.ID, models.ExportFailed); err != nil {
+			log.Printf("Error marking export failed: %v", err)
+		}
+	}
+	return export, nil
+}
+
+// DownloadSource serves ready exports through /download.
+func (h *ExportHandler) DownloadSource() DownloadSource {
+	return func(ctx context.Context, name string) (*Download, error) {
+		exportID, err := bson.ObjectIDFromHex(name)
+		if err != nil {
+			return nil, nil
+		}
+		export, err := h.exportRepo.FindByID(ctx, exportID)
+		if err != nil || export == nil || export.Status != models.ExportReady {
+			return nil, err
+		}
+		return &Download{
+			Filename:    "rizon-export-" + export.CreatedAt.Format("2006-01-02") + ".json",
+			ContentType: "application/json",
+			Data:        export.Data,
+		}, nil
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/flags"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type FeatureFlagHandler struct {
+	flagRepo *repository.FeatureFlagRepo
+	store    *flags.Store
+}
+
+func NewFeatureFlagHandler(flagRepo *repository.FeatureFlagRepo, store *flags.Store) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagRepo: flagRepo,
+		store:    store,
+	}
+}
+
+type SetFeatureFlagRequest struct {
+	Enabled    bool     `json:"enabled"`
+	Percentage int      `json:"percentage"`
+	UserIDs    []string `json:"user_ids"`
+}
+
+// --- GET /admin/flags ---
+
+func (h *FeatureFlagHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.flagRepo.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing feature flags: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"flags": list})
+}
+
+// --- PUT /admin/flags/{key} ---
+
+func (h *FeatureFlagHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req SetFeatureFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Percentage < 0 || req.Percentage > 100 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "percentage must be between 0 and 100"})
+		return
+	}
+
+	flag := &models.FeatureFlag{
+		Key:        chi.URLParam(r, "key"),
+		Enabled:    req.Enabled,
+		Percentage: req.Percentage,
+		UserIDs:    req.UserIDs,
+	}
+	if err := h.flagRepo.Upsert(r.Context(), flag); err != nil {
+		log.Printf("Error saving feature flag: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save flag"})
+		return
+	}
+	h.refresh(r)
+
+	writeJSON(w, http.StatusOK, flag)
+}
+
+// --- DELETE /admin/flags/{key} ---
+
+func (h *FeatureFlagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.flagRepo.Delete(r.Context(), chi.URLParam(r, "key")); err != nil {
+		log.Printf("Error deleting feature flag: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete flag"})
+		return
+	}
+	h.refresh(r)
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "flag removed"})
+}
+
+// refresh applies the change on this replica immediately; others pick it up
+// on their next periodic refresh.
+func (h *FeatureFlagHandler) refresh(r *http.Request) {
+	if err := h.store.Refresh(r.Context()); err != nil {
+		log.Printf("Error refreshing feature flags: %v", err)
+	}
+}
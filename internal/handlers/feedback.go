@@ -5,31 +5,81 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 
+	"rizon-backend/internal/captcha"
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/issues"
 	"rizon-backend/internal/middleware"
 	"rizon-backend/internal/models"
+	"rizon-backend/internal/ratelimit"
+	"rizon-backend/internal/redact"
 	"rizon-backend/internal/repository"
+	"rizon-backend/internal/service"
 	"rizon-backend/internal/slack"
+	"rizon-backend/internal/templates"
 
+	"github.com/go-chi/chi/v5"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type FeedbackHandler struct {
+	feedback     *service.FeedbackService
 	feedbackRepo *repository.FeedbackRepo
+	orgs         *service.OrgService // files members' feedback under their organization
 	notifier     slack.Notifier
+	trackers     map[string]issues.Tracker
+	deliveries   *repository.IssueDeliveryRepo // logs each attempt to file an issue
+	captcha      *captcha.Verifier             // public form is disabled without one
+	limiter      *ratelimit.Limiter            // throttles the public form
+	autoTracker  issues.Tracker                // files bug reports automatically when set
+	dashboardURL string                        // base URL for "Open in dashboard" links
 }
 
-func NewFeedbackHandler(feedbackRepo *repository.FeedbackRepo, notifier slack.Notifier) *FeedbackHandler {
+func NewFeedbackHandler(feedback *service.FeedbackService, feedbackRepo *repository.FeedbackRepo, notifier slack.Notifier) *FeedbackHandler {
 	return &FeedbackHandler{
+		feedback:     feedback,
 		feedbackRepo: feedbackRepo,
 		notifier:     notifier,
+		trackers:     map[string]issues.Tracker{},
 	}
 }
 
+// WithOrganizations files members' feedback under their organization, where
+// its owners can read it.
+func (h *FeedbackHandler) WithOrganizations(orgs *service.OrgService) *FeedbackHandler {
+	h.orgs = orgs
+	return h
+}
+
+// slackTextLimit caps user-written text forwarded to Slack when PII redaction is on.
+const slackTextLimit = 500
+
 type SubmitFeedbackRequest struct {
-	Text           string `json:"text"`
-	Rating         int    `json:"rating"`
-	IdempotencyKey string `json:"idempotency_key"`
+	Text           string             `json:"text"`
+	Rating         int                `json:"rating"`
+	Ratings        map[string]int     `json:"ratings"`  // optional: ease_of_use, performance, design (1-5)
+	Category       string             `json:"category"` // optional: "bug", "idea" or "other"
+	IdempotencyKey string             `json:"idempotency_key"`
+	Client         *models.ClientInfo `json:"client"` // optional; falls back to the X-App-* headers
+	OrgID          string             `json:"org_id"` // optional; defaults to the user's only organization
+}
+
+type ReactionRequest struct {
+	Reaction string `json:"reaction"` // "up" or "down"
+	Comment  string `json:"comment"`
+}
+
+// EditFeedbackRequest changes the given fields of the author's feedback.
+type EditFeedbackRequest struct {
+	Text     *string        `json:"text"`
+	Rating   *int           `json:"rating"`
+	Ratings  map[string]int `json:"ratings"` // replaces all named ratings
+	Category *string        `json:"category"`
+}
+
+type UpdateFeedbackStatusRequest struct {
+	Status string `json:"status"`
 }
 
 // --- POST /feedback ---
@@ -47,72 +97,414 @@ func (h *FeedbackHandler) SubmitFeedback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if req.Text == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "feedback text is required"})
+	userID, err := bson.ObjectIDFromHex(userIDHex)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
 		return
 	}
 
-	if req.IdempotencyKey == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "idempotency_key is required"})
-		return
+	var orgID *bson.ObjectID
+	if h.orgs != nil {
+		var requested bson.ObjectID
+		if req.OrgID != "" {
+			if requested, err = bson.ObjectIDFromHex(req.OrgID); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid org_id"})
+				return
+			}
+		}
+		if orgID, err = h.orgs.FeedbackOrg(r.Context(), userID, requested); err != nil {
+			writeServiceError(w, err, "Error choosing feedback organization")
+			return
+		}
 	}
 
-	// Idempotency check — prevent duplicate submissions
-	existing, err := h.feedbackRepo.FindByIdempotencyKey(r.Context(), req.IdempotencyKey)
+	feedback, created, err := h.feedback.Submit(r.Context(), service.Submission{
+		UserID:         userID,
+		OrgID:          orgID,
+		Text:           req.Text,
+		Rating:         req.Rating,
+		Ratings:        req.Ratings,
+		Category:       req.Category,
+		Source:         models.FeedbackSourceApp,
+		IdempotencyKey: req.IdempotencyKey,
+		Client:         clientInfo(r, req.Client),
+	})
 	if err != nil {
-		log.Printf("Error checking idempotency: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		writeServiceError(w, err, "Error submitting feedback")
 		return
 	}
-	if existing != nil {
+	if !created {
 		// Already submitted — return the existing feedback (idempotent behavior)
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"message":  "feedback already submitted",
-			"feedback": existing,
+			"feedback": feedback,
 		})
 		return
 	}
 
-	userID, err := bson.ObjectIDFromHex(userIDHex)
+	// Sandbox feedback stays out of Slack and the issue tracker
+	if !database.InSandbox(r.Context()) {
+		bg := regionalBackground(r.Context())
+		// Fire Slack notification in a background goroutine (non-blocking)
+		go func() {
+			message := formatSlackMessage(userIDHex, req.Text, feedback.Rating, feedback.Client)
+			posted, err := h.notifier.PublishWithButtons(bg, message, h.feedbackButtons(feedback.ID))
+			if err != nil {
+				log.Printf("Error publishing to Slack: %v", err)
+				return
+			}
+			// Remember the message so follow-up events reply in its thread
+			thread := &models.SlackThread{Channel: posted.Channel, TS: posted.TS}
+			if err := h.feedbackRepo.SetSlackThread(bg, feedback.ID, thread); err != nil {
+				log.Printf("Error saving Slack thread: %v", err)
+			}
+		}()
+
+		if feedback.Category == models.FeedbackCategoryBug && h.autoTracker != nil {
+			go func() {
+				if _, _, err := h.createIssue(bg, h.autoTracker, feedback, models.IssueDelivery{Trigger: models.IssueDeliveryAuto}); err != nil {
+					log.Printf("Error filing %s issue for feedback %s: %v", h.autoTracker.Name(), feedback.ID.Hex(), err)
+				}
+			}()
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":  "feedback submitted successfully",
+		"feedback": feedback,
+	})
+}
+
+func formatSlackMessage(userID, text string, rating int, client *models.ClientInfo) string {
+	content, err := templates.Render("feedback_received", templates.ChannelSlack, map[string]interface{}{
+		"UserID": userID,
+		"Rating": rating,
+		"Text":   redact.Text(text, slackTextLimit),
+		"App":    client.Summary(),
+	})
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		log.Printf("Error rendering Slack message: %v", err)
+		return "📝 New Feedback Received from " + userID
+	}
+	return content.Text
+}
+
+// clientInfo takes the client details from the request body, filling gaps
+// from the X-App-* headers the app sends on every request.
+func clientInfo(r *http.Request, body *models.ClientInfo) *models.ClientInfo {
+	headers := middleware.ClientInfo(r)
+	if body.IsZero() {
+		return headers
+	}
+	if headers == nil {
+		headers = &models.ClientInfo{}
+	}
+	info := *body
+	for _, f := range []struct{ field, fallback *string }{
+		{&info.AppVersion, &headers.AppVersion},
+		{&info.BuildNumber, &headers.BuildNumber},
+		{&info.OS, &headers.OS},
+		{&info.OSVersion, &headers.OSVersion},
+		{&info.DeviceModel, &headers.DeviceModel},
+	} {
+		*f.field = strings.TrimSpace(*f.field)
+		if *f.field == "" {
+			*f.field = *f.fallback
+		}
+		// Free-form client input; keep it short enough to group on
+		if len(*f.field) > 64 {
+			*f.field = (*f.field)[:64]
+		}
+	}
+	info.OS = strings.ToLower(info.OS)
+	return &info
+}
+
+// --- GET /feedback/follow-ups ---
+// Resolved feedback the user hasn't answered "did this solve it?" for yet.
+
+func (h *FeedbackHandler) ListFollowUps(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 		return
 	}
 
-	feedback := &models.Feedback{
-		UserID:         userID,
-		Text:           req.Text,
-		Rating:         req.Rating,
-		IdempotencyKey: req.IdempotencyKey,
+	feedbacks, err := h.feedbackRepo.ListAwaitingReaction(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error listing follow-ups: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"follow_ups": feedbacks,
+	})
+}
+
+// --- POST /feedback/{id}/reaction ---
+
+func (h *FeedbackHandler) React(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	feedbackID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid feedback ID"})
+		return
+	}
+
+	var req ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Reaction != models.ReactionUp && req.Reaction != models.ReactionDown {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "reaction must be \"up\" or \"down\""})
+		return
+	}
+	if len(req.Comment) > 1000 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "comment is too long"})
+		return
+	}
+
+	reaction := &models.FeedbackReaction{Value: req.Reaction, Comment: req.Comment}
+	ok, err := h.feedbackRepo.SetReaction(r.Context(), feedbackID, userID, reaction)
+	if err != nil {
+		log.Printf("Error saving reaction: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if !ok {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "feedback is not awaiting a reaction"})
+		return
+	}
+
+	if !database.InSandbox(r.Context()) {
+		h.publishToThread(r.Context(), feedbackID, "feedback_reaction", map[string]interface{}{
+			"FeedbackID": feedbackID.Hex(),
+			"Reaction":   req.Reaction,
+			"Comment":    redact.Text(req.Comment, slackTextLimit),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "reaction recorded",
+		"reaction": reaction,
+	})
+}
+
+// --- PATCH /feedback/{id} ---
+// The author can edit their feedback for service.FeedbackEditWindow after
+// submitting it, until it's resolved. Earlier versions are kept in edits.
+
+func (h *FeedbackHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	feedbackID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid feedback ID"})
+		return
+	}
+
+	var req EditFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	_, feedback, err := h.feedback.Edit(r.Context(), feedbackID, userID, service.FeedbackChanges{
+		Text:     req.Text,
+		Rating:   req.Rating,
+		Ratings:  req.Ratings,
+		Category: req.Category,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Error editing feedback")
+		return
+	}
+
+	if !database.InSandbox(r.Context()) {
+		h.publishToThread(r.Context(), feedbackID, "feedback_edited", map[string]interface{}{
+			"FeedbackID": feedbackID.Hex(),
+			"Rating":     feedback.Rating,
+			"Text":       redact.Text(feedback.Text, slackTextLimit),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "feedback updated",
+		"feedback": feedback,
+	})
+}
+
+// --- DELETE /feedback/{id} ---
+// Same window as editing.
+
+func (h *FeedbackHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	feedbackID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid feedback ID"})
+		return
+	}
+
+	feedback, err := h.feedback.Delete(r.Context(), feedbackID, userID)
+	if err != nil {
+		writeServiceError(w, err, "Error deleting feedback")
+		return
+	}
+
+	if !database.InSandbox(r.Context()) {
+		// The document is gone, so reply in the thread it was posted to
+		thread := feedback.SlackThread
+		bg := regionalBackground(r.Context())
+		go func() {
+			content, err := templates.Render("feedback_deleted", templates.ChannelSlack, map[string]interface{}{
+				"FeedbackID": feedbackID.Hex(),
+			})
+			if err != nil {
+				log.Printf("Error rendering Slack message: %v", err)
+				return
+			}
+			h.postToThread(bg, thread, content.Text)
+		}()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "feedback deleted"})
+}
+
+// --- PATCH /admin/feedback/{id}/status ---
+
+func (h *FeedbackHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	feedbackID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid feedback ID"})
+		return
+	}
+
+	var req UpdateFeedbackStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Status != models.FeedbackStatusOpen && req.Status != models.FeedbackStatusResolved {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be \"open\" or \"resolved\""})
+		return
 	}
 
-	if err := h.feedbackRepo.Create(r.Context(), feedback); err != nil {
-		log.Printf("Error creating feedback: %v", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to submit feedback"})
+	ctx, err := inFeedbackRegion(r.Context(), h.feedbackRepo, feedbackID)
+	if err != nil {
+		log.Printf("Error locating feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update status"})
 		return
 	}
+	found, err := h.feedbackRepo.UpdateStatus(ctx, feedbackID, req.Status)
+	if err != nil {
+		log.Printf("Error updating feedback status: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update status"})
+		return
+	}
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "feedback not found"})
+		return
+	}
+
+	h.publishToThread(ctx, feedbackID, "feedback_status_changed", map[string]interface{}{
+		"FeedbackID": feedbackID.Hex(),
+		"Status":     req.Status,
+	})
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "feedback status updated",
+		"status":  req.Status,
+	})
+}
+
+// --- GET /admin/feedback/stats?app_version=&os= ---
 
-	// Fire Slack notification in a background goroutine (non-blocking)
+func (h *FeedbackHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	filter := models.FeedbackFilter{
+		AppVersion: r.URL.Query().Get("app_version"),
+		OS:         strings.ToLower(r.URL.Query().Get("os")),
+	}
+	stats, err := h.feedbackRepo.Stats(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error computing feedback stats: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// publishToThread renders a Slack template and posts it in the background as a
+// reply in the feedback's Slack thread, or as a new message if it has none.
+// ctx must carry the feedback's data residency region.
+func (h *FeedbackHandler) publishToThread(ctx context.Context, feedbackID bson.ObjectID, template string, data map[string]interface{}) {
+	ctx = regionalBackground(ctx)
 	go func() {
-		message := formatSlackMessage(userIDHex, req.Text, req.Rating)
-		if err := h.notifier.Publish(context.Background(), message); err != nil {
-			log.Printf("Error publishing to Slack: %v", err)
+		content, err := templates.Render(template, templates.ChannelSlack, data)
+		if err != nil {
+			log.Printf("Error rendering Slack message: %v", err)
+			return
+		}
+
+		feedback, err := h.feedbackRepo.FindByID(ctx, feedbackID)
+		if err != nil {
+			log.Printf("Error loading feedback for Slack thread: %v", err)
+			return
 		}
+		var thread *models.SlackThread
+		if feedback != nil {
+			thread = feedback.SlackThread
+		}
+		h.postToThread(ctx, thread, content.Text)
 	}()
+}
 
-	writeJSON(w, http.StatusCreated, map[string]interface{}{
-		"message":  "feedback submitted successfully",
-		"feedback": feedback,
-	})
+// regionalBackground is a context for work that outlives the request but
+// must still reach feedback in the request's data residency region.
+func regionalBackground(ctx context.Context) context.Context {
+	return database.WithRegion(context.Background(), database.RegionOf(ctx))
 }
 
-func formatSlackMessage(userID, text string, rating int) string {
-	stars := ""
-	for i := 0; i < rating; i++ {
-		stars += "⭐"
+// inFeedbackRegion marks ctx with the data residency region holding the
+// feedback. Admin and Slack requests have no signed-in member to take the
+// region from, so each region is looked in. ctx is returned as is when it
+// already has a region or no region has the feedback.
+func inFeedbackRegion(ctx context.Context, feedbackRepo *repository.FeedbackRepo, id bson.ObjectID) (context.Context, error) {
+	if database.RegionOf(ctx) != "" {
+		return ctx, nil
+	}
+	region, found, err := feedbackRepo.FindRegion(ctx, id)
+	if err != nil || !found {
+		return ctx, err
+	}
+	return database.WithRegion(ctx, region), nil
+}
+
+// postToThread replies in the feedback's Slack thread, or posts to the
+// channel if it was never announced.
+func (h *FeedbackHandler) postToThread(ctx context.Context, thread *models.SlackThread, text string) {
+	if thread != nil {
+		if _, err := h.notifier.Reply(ctx, &slack.Message{Channel: thread.Channel, TS: thread.TS}, text); err != nil {
+			log.Printf("Error replying in Slack thread: %v", err)
+		}
+		return
+	}
+	if _, err := h.notifier.Publish(ctx, text); err != nil {
+		log.Printf("Error publishing to Slack: %v", err)
 	}
-	return "📝 *New Feedback Received*\n" +
-		"User: `" + userID + "`\n" +
-		"Rating: " + stars + "\n" +
-		"Feedback: " + text
 }
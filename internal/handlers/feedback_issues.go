@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/issues"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// errAlreadyLinked is returned when feedback already has an issue in the tracker.
+var errAlreadyLinked = errors.New("feedback already linked to an issue")
+
+// errSandboxed is returned instead of filing issues for sandbox feedback.
+var errSandboxed = errors.New("issues aren't filed from a sandbox")
+
+// WithIssueTrackers enables promoting feedback to Linear/Jira issues. When
+// autoProvider names one of the trackers, bug reports are filed there as
+// soon as they are submitted. Every attempt is logged in deliveries.
+func (h *FeedbackHandler) WithIssueTrackers(trackers []issues.Tracker, autoProvider string, deliveries *repository.IssueDeliveryRepo) *FeedbackHandler {
+	for _, t := range trackers {
+		h.trackers[t.Name()] = t
+	}
+	h.autoTracker = h.trackers[autoProvider]
+	h.deliveries = deliveries
+	return h
+}
+
+type PromoteFeedbackRequest struct {
+	Provider string `json:"provider"` // "linear" or "jira"
+}
+
+// --- POST /admin/feedback/{id}/issues ---
+
+func (h *FeedbackHandler) PromoteToIssue(w http.ResponseWriter, r *http.Request) {
+	feedbackID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid feedback ID"})
+		return
+	}
+
+	var req PromoteFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	tracker, ok := h.trackers[req.Provider]
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": issues.ErrUnknownProvider.Error()})
+		return
+	}
+
+	ctx, err := inFeedbackRegion(r.Context(), h.feedbackRepo, feedbackID)
+	if err != nil {
+		log.Printf("Error locating feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	feedback, err := h.feedbackRepo.FindByID(ctx, feedbackID)
+	if err != nil {
+		log.Printf("Error finding feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if feedback == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "feedback not found"})
+		return
+	}
+
+	link, _, err := h.createIssue(ctx, tracker, feedback, models.IssueDelivery{
+		Trigger: models.IssueDeliveryManual,
+		Admin:   middleware.GetAdminName(r.Context()),
+	})
+	if errors.Is(err, errAlreadyLinked) {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error": err.Error(),
+			"issue": feedback.IssueLink(tracker.Name()),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error creating %s issue: %v", tracker.Name(), err)
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "failed to create issue"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "issue created",
+		"issue":   link,
+	})
+}
+
+// createIssue files the feedback in the tracker, stores the back-link on the
+// feedback document and announces it in the feedback's Slack thread. The
+// attempt is logged as a delivery filled in from attempt's trigger fields; it
+// is returned unless the feedback was already linked.
+func (h *FeedbackHandler) createIssue(ctx context.Context, tracker issues.Tracker, feedback *models.Feedback, attempt models.IssueDelivery) (*models.IssueLink, *models.IssueDelivery, error) {
+	if feedback.IssueLink(tracker.Name()) != nil {
+		return nil, nil, errAlreadyLinked
+	}
+	if database.InSandbox(ctx) {
+		return nil, nil, errSandboxed
+	}
+
+	start := time.Now()
+	created, resp, err := tracker.Create(ctx, issueFromFeedback(feedback))
+	delivery := &attempt
+	delivery.FeedbackID = feedback.ID
+	delivery.Provider = tracker.Name()
+	delivery.LatencyMS = time.Since(start).Milliseconds()
+	if resp != nil {
+		delivery.StatusCode = resp.StatusCode
+		delivery.ResponseExcerpt = resp.Body
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	} else {
+		delivery.Succeeded = true
+		delivery.IssueKey = created.Key
+	}
+	if h.deliveries != nil {
+		if err := h.deliveries.Create(context.WithoutCancel(ctx), delivery); err != nil {
+			log.Printf("Error logging %s issue delivery: %v", tracker.Name(), err)
+		}
+	}
+	if err != nil {
+		return nil, delivery, err
+	}
+
+	link := models.IssueLink{
+		Provider:  tracker.Name(),
+		Key:       created.Key,
+		URL:       created.URL,
+		CreatedAt: time.Now(),
+	}
+	added, err := h.feedbackRepo.AddIssueLink(ctx, feedback.ID, link)
+	if err != nil {
+		return nil, delivery, fmt.Errorf("issue %s created but not linked: %w", created.Key, err)
+	}
+	if !added {
+		// Lost a race with a concurrent promotion; the other issue is the canonical one
+		log.Printf("⚠️  Duplicate %s issue %s for feedback %s", tracker.Name(), created.Key, feedback.ID.Hex())
+		return nil, delivery, errAlreadyLinked
+	}
+
+	h.publishToThread(ctx, feedback.ID, "feedback_issue_created", map[string]interface{}{
+		"FeedbackID": feedback.ID.Hex(),
+		"Provider":   link.Provider,
+		"Key":        link.Key,
+		"URL":        link.URL,
+	})
+	return &link, delivery, nil
+}
+
+// --- GET /admin/issue-deliveries?feedback_id=<id>&provider=jira&failed=true&before=<id>&limit=50 ---
+// Attempts to file feedback in an issue tracker, newest first. Pass the last
+// ID of a page as before to get the next one.
+
+func (h *FeedbackHandler) ListIssueDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.IssueDeliveryFilter{
+		Provider:   query.Get("provider"),
+		FailedOnly: query.Get("failed") == "true",
+	}
+	for param, id := range map[string]*bson.ObjectID{"feedback_id": &filter.FeedbackID, "before": &filter.Before} {
+		if raw := query.Get(param); raw != "" {
+			parsed, err := bson.ObjectIDFromHex(raw)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid " + param})
+				return
+			}
+			*id = parsed
+		}
+	}
+	limit := int64(50)
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 200 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.deliveries.List(r.Context(), filter, limit)
+	if err != nil {
+		log.Printf("Error listing issue deliveries: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+// --- POST /admin/issue-deliveries/{id}/redeliver ---
+// Files the delivery's feedback in the same tracker again, logged as a new
+// delivery. Feedback that has since been linked answers 409 with the issue.
+
+func (h *FeedbackHandler) RedeliverIssue(w http.ResponseWriter, r *http.Request) {
+	deliveryID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid delivery ID"})
+		return
+	}
+	previous, err := h.deliveries.FindByID(r.Context(), deliveryID)
+	if err != nil {
+		log.Printf("Error finding issue delivery: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if previous == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "delivery not found"})
+		return
+	}
+	tracker, ok := h.trackers[previous.Provider]
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": issues.ErrUnknownProvider.Error()})
+		return
+	}
+
+	ctx, err := inFeedbackRegion(r.Context(), h.feedbackRepo, previous.FeedbackID)
+	if err != nil {
+		log.Printf("Error locating feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	feedback, err := h.feedbackRepo.FindByID(ctx, previous.FeedbackID)
+	if err != nil {
+		log.Printf("Error finding feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if feedback == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "feedback not found"})
+		return
+	}
+
+	link, delivery, err := h.createIssue(ctx, tracker, feedback, models.IssueDelivery{
+		Trigger:      models.IssueDeliveryRedeliver,
+		Admin:        middleware.GetAdminName(r.Context()),
+		RedeliveryOf: &previous.ID,
+	})
+	if errors.Is(err, errAlreadyLinked) {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error": err.Error(),
+			"issue": feedback.IssueLink(tracker.Name()),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Error redelivering %s issue: %v", tracker.Name(), err)
+		writeJSON(w, http.StatusBadGateway, map[string]interface{}{
+			"error":    "failed to create issue",
+			"delivery": delivery,
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message":  "issue created",
+		"issue":    link,
+		"delivery": delivery,
+	})
+}
+
+func issueFromFeedback(feedback *models.Feedback) issues.Issue {
+	text := redact.Text(feedback.Text, 0)
+	title := text
+	if runes := []rune(title); len(runes) > 80 {
+		title = string(runes[:80]) + "…"
+	}
+	if feedback.Category != "" {
+		title = "[" + feedback.Category + "] " + title
+	}
+	return issues.Issue{
+		Title: title,
+		Description: fmt.Sprintf("%s\n\nRating: %d\nFeedback ID: %s\nUser ID: %s\nSubmitted: %s",
+			text, feedback.Rating, feedback.ID.Hex(), feedback.UserID.Hex(), feedback.CreatedAt.UTC().Format(time.RFC3339)),
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type FeedbackPromptHandler struct {
+	promptRepo   *repository.FeedbackPromptRepo
+	userRepo     *repository.UserRepo
+	feedbackRepo *repository.FeedbackRepo
+}
+
+func NewFeedbackPromptHandler(promptRepo *repository.FeedbackPromptRepo, userRepo *repository.UserRepo, feedbackRepo *repository.FeedbackRepo) *FeedbackPromptHandler {
+	return &FeedbackPromptHandler{
+		promptRepo:   promptRepo,
+		userRepo:     userRepo,
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+type PromptEventRequest struct {
+	Event string `json:"event"` // "shown", "dismissed" or "negative"
+}
+
+// --- GET /feedback/prompt ---
+// Tells the app whether to show the rating prompt now. The app reports
+// back with POST /feedback/prompt/events when it does.
+
+func (h *FeedbackPromptHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	rules, err := h.promptRepo.Rules(r.Context())
+	if err != nil {
+		log.Printf("Error loading prompt rules: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	user, err := h.userRepo.FindByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if user == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	state, err := h.promptRepo.State(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error loading prompt state: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	latest, err := h.feedbackRepo.LatestForUser(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error loading latest feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	show, reason := decidePrompt(rules, user, state, latest, time.Now())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"show":   show,
+		"reason": reason,
+	})
+}
+
+// decidePrompt applies the rules in order and returns the first that
+// blocks the prompt, or "eligible".
+func decidePrompt(rules *models.FeedbackPromptRules, user *models.User, state *models.FeedbackPromptState, latest *models.Feedback, now time.Time) (bool, string) {
+	days := func(n int) time.Duration { return time.Duration(n) * 24 * time.Hour }
+	within := func(t *time.Time, n int) bool { return n > 0 && t != nil && now.Sub(*t) < days(n) }
+
+	if !rules.Enabled {
+		return false, "disabled"
+	}
+	if rules.MinDaysSinceInstall > 0 && now.Sub(user.CreatedAt) < days(rules.MinDaysSinceInstall) {
+		return false, "too_new"
+	}
+	if rules.MaxPrompts > 0 && state.PromptCount >= rules.MaxPrompts {
+		return false, "max_prompts"
+	}
+	if within(state.LastPromptedAt, rules.MinDaysBetweenPrompts) {
+		return false, "recently_prompted"
+	}
+	if within(state.LastNegativeAt, rules.NegativeCooldownDays) {
+		return false, "recent_negative_event"
+	}
+	if latest != nil {
+		if within(&latest.CreatedAt, rules.MinDaysSinceFeedback) {
+			return false, "recent_feedback"
+		}
+		if latest.Rating > 0 && latest.Rating <= 2 && within(&latest.CreatedAt, rules.NegativeCooldownDays) {
+			return false, "recent_negative_event"
+		}
+	}
+	return true, "eligible"
+}
+
+// --- POST /feedback/prompt/events ---
+
+func (h *FeedbackPromptHandler) RecordEvent(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	var req PromptEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	now := time.Now()
+	switch req.Event {
+	case models.PromptEventShown, models.PromptEventDismissed:
+		err = h.promptRepo.RecordPrompt(r.Context(), userID, now)
+	case models.PromptEventNegative:
+		err = h.promptRepo.RecordNegative(r.Context(), userID, now)
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "event must be \"shown\", \"dismissed\" or \"negative\""})
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording prompt event: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- GET /admin/feedback/prompt-rules ---
+
+func (h *FeedbackPromptHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.promptRepo.Rules(r.Context())
+	if err != nil {
+		log.Printf("Error loading prompt rules: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// --- PUT /admin/feedback/prompt-rules ---
+
+func (h *FeedbackPromptHandler) SetRules(w http.ResponseWriter, r *http.Request) {
+	var rules models.FeedbackPromptRules
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	for _, n := range []int{rules.MinDaysSinceInstall, rules.MinDaysBetweenPrompts, rules.MinDaysSinceFeedback, rules.NegativeCooldownDays, rules.MaxPrompts} {
+		if n < 0 || n > 3650 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "values must be between 0 and 3650"})
+			return
+		}
+	}
+	rules.UpdatedBy = middleware.GetAdminName(r.Context())
+
+	if err := h.promptRepo.SaveRules(r.Context(), &rules); err != nil {
+		log.Printf("Error saving prompt rules: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save rules"})
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
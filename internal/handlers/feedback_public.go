@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/captcha"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/ratelimit"
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/service"
+	"rizon-backend/internal/spam"
+	"rizon-backend/internal/templates"
+
+	"github.com/google/uuid"
+)
+
+// Public form limits: per IP, and overall so a botnet can't flood Slack.
+const (
+	publicFeedbackPerIP   = 5
+	publicFeedbackGlobal  = 300
+	publicFeedbackWindow  = time.Hour
+	publicFeedbackMaxText = 5000
+)
+
+// WithPublicForm enables POST /public/feedback. Without a captcha verifier
+// the endpoint answers 503.
+func (h *FeedbackHandler) WithPublicForm(verifier *captcha.Verifier, limiter *ratelimit.Limiter) *FeedbackHandler {
+	h.captcha = verifier
+	h.limiter = limiter
+	return h
+}
+
+type PublicFeedbackRequest struct {
+	Text           string         `json:"text"`
+	Rating         int            `json:"rating"`
+	Ratings        map[string]int `json:"ratings"` // optional, see SubmitFeedbackRequest
+	Category       string         `json:"category"`
+	Email          string         `json:"email"`           // optional, for a reply
+	IdempotencyKey string         `json:"idempotency_key"` // optional; generated if empty
+	CaptchaToken   string         `json:"captcha_token"`
+	Website        string         `json:"website"`     // honeypot: hidden field, must stay empty
+	RenderedAt     int64          `json:"rendered_at"` // unix ms when the form was shown; optional
+}
+
+// --- POST /public/feedback ---
+// Unauthenticated feedback from the marketing site's web form.
+
+func (h *FeedbackHandler) SubmitPublicFeedback(w http.ResponseWriter, r *http.Request) {
+	if h.captcha == nil || h.limiter == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "public feedback is not enabled"})
+		return
+	}
+
+	ip := middleware.ClientIP(r)
+	for _, key := range []string{"public_feedback:ip:" + ip, "public_feedback:all"} {
+		limit := int64(publicFeedbackPerIP)
+		if key == "public_feedback:all" {
+			limit = publicFeedbackGlobal
+		}
+		result, err := h.limiter.Allow(r.Context(), key, limit, publicFeedbackWindow)
+		if err != nil {
+			log.Printf("Error checking rate limit: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(result.ResetAt).Seconds())+1))
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many submissions, please try again later"})
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	var req PublicFeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "feedback text is required"})
+		return
+	}
+	if len([]rune(req.Text)) > publicFeedbackMaxText {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "feedback text is too long"})
+		return
+	}
+	if req.Rating < 0 || req.Rating > 5 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "rating must be between 0 and 5"})
+		return
+	}
+	if !service.ValidFeedbackCategory(req.Category) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "category must be \"bug\", \"idea\" or \"other\""})
+		return
+	}
+	if req.Email != "" {
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid email"})
+			return
+		}
+		req.Email = strings.ToLower(addr.Address)
+	}
+
+	if err := h.captcha.Verify(r.Context(), req.CaptchaToken, ip); err != nil {
+		if err != captcha.ErrFailed {
+			log.Printf("Error verifying captcha: %v", err)
+		}
+		writeJSON(w, http.StatusForbidden, map[string]string{"error": "captcha verification failed"})
+		return
+	}
+
+	if req.IdempotencyKey == "" {
+		req.IdempotencyKey = uuid.New().String()
+	}
+	var fillTime time.Duration
+	if req.RenderedAt > 0 {
+		fillTime = time.Since(time.UnixMilli(req.RenderedAt))
+	}
+	score := spam.Score(spam.Input{Text: req.Text, Honeypot: req.Website, FillTime: fillTime})
+
+	feedback, created, err := h.feedback.Submit(r.Context(), service.Submission{
+		Text:         req.Text,
+		Rating:       req.Rating,
+		Ratings:      req.Ratings,
+		Category:     req.Category,
+		Source:       models.FeedbackSourceWeb,
+		ContactEmail: req.Email,
+		SpamScore:    score.Score,
+		// Namespaced so web keys can't collide with (or probe) app submissions
+		IdempotencyKey: "web:" + req.IdempotencyKey,
+	})
+	if err != nil {
+		writeServiceError(w, err, "Error submitting web feedback")
+		return
+	}
+	if !created {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"message": "feedback already submitted", "id": feedback.ID})
+		return
+	}
+
+	if score.IsSpam() {
+		// Stored for review but kept out of Slack
+		log.Printf("🚫 Web feedback %s scored as spam (%d): %s", feedback.ID.Hex(), score.Score, strings.Join(score.Reasons, ", "))
+	} else {
+		bg := regionalBackground(r.Context())
+		go func() {
+			content, err := templates.Render("web_feedback_received", templates.ChannelSlack, map[string]interface{}{
+				"Email":  redact.Email(req.Email),
+				"Rating": feedback.Rating,
+				"Text":   redact.Text(req.Text, slackTextLimit),
+			})
+			if err != nil {
+				log.Printf("Error rendering Slack message: %v", err)
+				return
+			}
+			posted, err := h.notifier.PublishWithButtons(bg, content.Text, h.feedbackButtons(feedback.ID))
+			if err != nil {
+				log.Printf("Error publishing to Slack: %v", err)
+				return
+			}
+			thread := &models.SlackThread{Channel: posted.Channel, TS: posted.TS}
+			if err := h.feedbackRepo.SetSlackThread(bg, feedback.ID, thread); err != nil {
+				log.Printf("Error saving Slack thread: %v", err)
+			}
+		}()
+	}
+
+	// Spam gets the same answer so bots can't tune against the scorer
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": "feedback submitted successfully",
+		"id":      feedback.ID,
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/slack"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Action IDs of the buttons on feedback messages
+const (
+	slackActionResolve   = "feedback_resolve"
+	slackActionAssign    = "feedback_assign"
+	slackActionDashboard = "feedback_open"
+)
+
+// WithDashboardURL adds an "Open in dashboard" button linking to
+// <base>/feedback/<id> on feedback messages.
+func (h *FeedbackHandler) WithDashboardURL(base string) *FeedbackHandler {
+	h.dashboardURL = strings.TrimSuffix(base, "/")
+	return h
+}
+
+func (h *FeedbackHandler) feedbackButtons(feedbackID bson.ObjectID) []slack.Button {
+	buttons := []slack.Button{
+		{Text: "Resolve", ActionID: slackActionResolve, Value: feedbackID.Hex(), Style: "primary"},
+		{Text: "Assign to me", ActionID: slackActionAssign, Value: feedbackID.Hex()},
+	}
+	if h.dashboardURL != "" {
+		buttons = append(buttons, slack.Button{
+			Text:     "Open in dashboard",
+			ActionID: slackActionDashboard,
+			URL:      h.dashboardURL + "/feedback/" + feedbackID.Hex(),
+		})
+	}
+	return buttons
+}
+
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// --- POST /webhooks/slack/interactions ---
+// Button clicks on feedback messages (signature checked by middleware). The
+// outcome is posted in the message's thread; Slack only needs a quick 200.
+
+func (h *FeedbackHandler) SlackInteraction(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid form body"})
+		return
+	}
+	var payload slackInteraction
+	if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+	if payload.Type != "block_actions" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	actor := "<@" + payload.User.ID + ">"
+	for _, action := range payload.Actions {
+		feedbackID, err := bson.ObjectIDFromHex(action.Value)
+		if err != nil {
+			continue // URL buttons carry no feedback ID
+		}
+
+		ctx, err := inFeedbackRegion(r.Context(), h.feedbackRepo, feedbackID)
+		if err != nil {
+			log.Printf("Error locating feedback from Slack: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+
+		switch action.ActionID {
+		case slackActionResolve:
+			found, err := h.feedbackRepo.UpdateStatus(ctx, feedbackID, models.FeedbackStatusResolved)
+			if err != nil {
+				log.Printf("Error resolving feedback from Slack: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+			if found {
+				h.publishToThread(ctx, feedbackID, "feedback_status_changed", map[string]interface{}{
+					"FeedbackID": feedbackID.Hex(),
+					"Status":     models.FeedbackStatusResolved,
+					"Actor":      actor,
+				})
+			}
+
+		case slackActionAssign:
+			found, err := h.feedbackRepo.Assign(ctx, feedbackID, payload.User.ID)
+			if err != nil {
+				log.Printf("Error assigning feedback from Slack: %v", err)
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+				return
+			}
+			if found {
+				h.publishToThread(ctx, feedbackID, "feedback_assigned", map[string]interface{}{
+					"FeedbackID": feedbackID.Hex(),
+					"Assignee":   actor,
+				})
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"rizon-backend/internal/buildinfo"
+	"rizon-backend/internal/lifecycle"
+)
+
+type HealthHandler struct {
+	environment string
+	drainer     *lifecycle.Drainer
+	drainGrace  time.Duration
+}
+
+func NewHealthHandler(environment string, drainer *lifecycle.Drainer, drainGrace time.Duration) *HealthHandler {
+	return &HealthHandler{
+		environment: environment,
+		drainer:     drainer,
+		drainGrace:  drainGrace,
+	}
+}
+
+// --- GET /health ---
+
+func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
+	info := buildinfo.Get()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":         "ok",
+		"service":        "rizon-backend",
+		"environment":    h.environment,
+		"commit":         info.Commit,
+		"uptime_seconds": info.UptimeSeconds,
+	})
+}
+
+// --- GET /version ---
+
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"service":     "rizon-backend",
+		"environment": h.environment,
+		"build":       buildinfo.Get(),
+	})
+}
+
+// --- GET /ready ---
+// Readiness probe. Fails as soon as draining starts so the load balancer
+// stops routing new traffic to this instance.
+
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.drainer.IsDraining() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// --- GET|POST /internal/drain ---
+// preStop hook target. Flips readiness, waits for in-flight requests and
+// streams to finish (up to the configured grace), then asks the server to exit.
+
+func (h *HealthHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	h.drainer.StartDrain()
+	log.Println("🛑 Drain requested, waiting for in-flight requests")
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.drainGrace)
+	defer cancel()
+
+	// Exclude this request from the in-flight count
+	waitErr := h.drainer.Wait(ctx, 1)
+	inflight, streams := h.drainer.Stats()
+
+	h.drainer.RequestShutdown()
+
+	status := "drained"
+	if waitErr != nil {
+		status = "grace period elapsed"
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":   status,
+		"inflight": inflight - 1,
+		"streams":  streams,
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// IntegrationHandler serves polling endpoints for no-code automation tools.
+// Zapier-style triggers poll newest-first and deduplicate on "id".
+type IntegrationHandler struct {
+	feedbackRepo *repository.FeedbackRepo
+}
+
+func NewIntegrationHandler(feedbackRepo *repository.FeedbackRepo) *IntegrationHandler {
+	return &IntegrationHandler{
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+// IntegrationFeedback is the flat feedback shape exposed to integrations.
+// Fields are only ever added, never renamed, so existing zaps keep working.
+type IntegrationFeedback struct {
+	ID         string         `json:"id"`
+	UserID     string         `json:"user_id"`
+	Text       string         `json:"text"`
+	Rating     int            `json:"rating"`
+	Ratings    map[string]int `json:"ratings"`
+	Category   string         `json:"category"`
+	Status     string         `json:"status"`
+	CreatedAt  time.Time      `json:"created_at"`
+	ResolvedAt *time.Time     `json:"resolved_at"`
+}
+
+// --- GET /integrations/feedback?since=<RFC3339>&cursor=<next_cursor>&limit=50 ---
+
+func (h *IntegrationHandler) ListFeedback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var since time.Time
+	if raw := query.Get("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be an RFC 3339 timestamp"})
+			return
+		}
+		since = t
+	}
+
+	limit := int64(50)
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 100 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	var after *models.Feedback
+	if raw := query.Get("cursor"); raw != "" {
+		c, err := decodeFeedbackCursor(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+		after = c
+	}
+
+	feedbacks, err := h.feedbackRepo.ListNewestFirst(r.Context(), since, after, limit)
+	if err != nil {
+		log.Printf("Error listing feedback for integration: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	items := make([]IntegrationFeedback, len(feedbacks))
+	for i, f := range feedbacks {
+		items[i] = IntegrationFeedback{
+			ID:         f.ID.Hex(),
+			UserID:     f.UserID.Hex(),
+			Text:       f.Text,
+			Rating:     f.Rating,
+			Ratings:    f.Ratings,
+			Category:   f.Category,
+			Status:     f.Status,
+			CreatedAt:  f.CreatedAt,
+			ResolvedAt: f.ResolvedAt,
+		}
+	}
+
+	// A full page means there may be more; an empty cursor means the end
+	nextCursor := ""
+	if int64(len(feedbacks)) == limit {
+		nextCursor = encodeFeedbackCursor(&feedbacks[len(feedbacks)-1])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"feedback":    items,
+		"next_cursor": nextCursor,
+	})
+}
+
+// Cursors are opaque to clients: base64("<created_at unix nanos>:<id>").
+func encodeFeedbackCursor(f *models.Feedback) string {
+	raw := strconv.FormatInt(f.CreatedAt.UnixNano(), 10) + ":" + f.ID.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFeedbackCursor(cursor string) (*models.Feedback, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	nanos, idHex, _ := strings.Cut(string(raw), ":")
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	id, err := bson.ObjectIDFromHex(idHex)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Feedback{ID: id, CreatedAt: time.Unix(0, n)}, nil
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type JobHandler struct {
+	queue *jobs.Queue
+}
+
+func NewJobHandler(queue *jobs.Queue) *JobHandler {
+	return &JobHandler{
+		queue: queue,
+	}
+}
+
+// --- GET /admin/jobs?status=failed&type=&before=&limit=50 ---
+// Lists jobs newest first, without payloads or error history. Pass the
+// last job's ID as before to get the next page.
+
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := jobs.JobFilter{Status: q.Get("status"), Type: q.Get("type")}
+	switch filter.Status {
+	case "", models.JobStatusPending, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusFailed, models.JobStatusCanceled:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown status"})
+		return
+	}
+	if raw := q.Get("before"); raw != "" {
+		id, err := bson.ObjectIDFromHex(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid before"})
+			return
+		}
+		filter.Before = id
+	}
+	limit := int64(50)
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 200 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+
+	list, err := h.queue.List(r.Context(), filter, limit)
+	if err != nil {
+		log.Printf("Error listing jobs: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	counts, err := h.queue.CountByStatus(r.Context())
+	if err != nil {
+		log.Printf("Error counting jobs: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"jobs":   list,
+		"counts": counts,
+	})
+}
+
+// --- GET /admin/jobs/{id} ---
+
+func (h *JobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	jobID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+		return
+	}
+
+	job, err := h.queue.FindByID(r.Context(), jobID)
+	if err != nil {
+		log.Printf("Error finding job: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"job":     job,
+		"payload": job.PayloadJSON(),
+		"result":  job.ResultJSON(),
+	})
+}
+
+// --- POST /admin/jobs/{id}/cancel ---
+
+func (h *JobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	jobID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+		return
+	}
+
+	job, err := h.queue.Cancel(r.Context(), jobID)
+	if err != nil {
+		log.Printf("Error canceling job: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+		return
+	}
+	if job.IsFinished() && job.Status != models.JobStatusCanceled {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "job already finished"})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message": "cancellation requested",
+		"job":     job,
+	})
+}
+
+// --- POST /admin/jobs/{id}/retry ---
+// Requeues a failed or canceled job with a fresh set of attempts, e.g.
+// once a provider outage is over.
+
+func (h *JobHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	jobID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+		return
+	}
+
+	adminName := middleware.GetAdminName(r.Context())
+	job, retried, err := h.queue.Retry(r.Context(), jobID, adminName)
+	if err != nil {
+		log.Printf("Error retrying job: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if job == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+		return
+	}
+	if !retried {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "only failed or canceled jobs can be retried"})
+		return
+	}
+
+	log.Printf("🔁 Job %s (%s) retried by %s", job.ID.Hex(), job.Type, adminName)
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message": "job requeued",
+		"job":     job,
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/rollup"
+)
+
+type LoginAnalyticsHandler struct {
+	roller *rollup.Roller
+}
+
+func NewLoginAnalyticsHandler(roller *rollup.Roller) *LoginAnalyticsHandler {
+	return &LoginAnalyticsHandler{
+		roller: roller,
+	}
+}
+
+// --- GET /admin/analytics/login-links?days= ---
+// Counts whole hours, so since is rounded down to the hour.
+
+func (h *LoginAnalyticsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = n
+	}
+	now := time.Now()
+
+	totals, since, err := h.roller.Totals(r.Context(), models.MetricLoginLinks, now.AddDate(0, 0, -days), now,
+		[]string{"sent", "clicked", "verified", "time_to_click_ms"})
+	if err != nil {
+		log.Printf("Error computing login funnel: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	var sent, clicked, verified int64
+	byClient := []models.LoginLinkClientStats{}
+	for _, t := range totals {
+		sent += t.Values["sent"]
+		clicked += t.Values["clicked"]
+		verified += t.Values["verified"]
+		if t.Values["clicked"] == 0 {
+			continue
+		}
+		byClient = append(byClient, models.LoginLinkClientStats{
+			EmailClient:        t.Dimension,
+			Clicked:            t.Values["clicked"],
+			Verified:           t.Values["verified"],
+			AvgTimeToClickSecs: float64(t.Values["time_to_click_ms"]) / float64(t.Values["clicked"]) / 1000,
+		})
+	}
+	sort.Slice(byClient, func(i, j int) bool { return byClient[i].Clicked > byClient[j].Clicked })
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":           since,
+		"sent":            sent,
+		"clicked":         clicked,
+		"verified":        verified,
+		"click_rate":      ratio(clicked, sent),
+		"verify_rate":     ratio(verified, sent),
+		"click_to_verify": ratio(verified, clicked),
+		"by_email_client": byClient,
+	})
+}
+
+func ratio(num, den int64) float64 {
+	if den == 0 {
+		return 0
+	}
+	return float64(num) / float64(den)
+}
+
+// classifyEmailClient guesses which app opened the login link from its user agent.
+func classifyEmailClient(ua string) string {
+	lower := strings.ToLower(ua)
+	switch {
+	case ua == "":
+		return "unknown"
+	case strings.Contains(lower, "gmail") || strings.Contains(lower, "gsa/"):
+		return "gmail_app"
+	case strings.Contains(lower, "outlook") || strings.Contains(lower, "ms-office") || strings.Contains(lower, "microsoft"):
+		return "outlook"
+	case strings.Contains(lower, "yahoo"):
+		return "yahoo_mail"
+	case strings.Contains(lower, "fban") || strings.Contains(lower, "instagram"):
+		return "in_app_browser"
+	case (strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad")) && !strings.Contains(lower, "safari"):
+		return "ios_mail"
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad"):
+		return "ios_safari"
+	case strings.Contains(lower, "android"):
+		return "android_browser"
+	case strings.Contains(lower, "macintosh") || strings.Contains(lower, "windows") || strings.Contains(lower, "linux"):
+		return "desktop_browser"
+	default:
+		return "other"
+	}
+}
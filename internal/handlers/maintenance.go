@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/maintenance"
+	"rizon-backend/internal/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type MaintenanceHandler struct {
+	queue *jobs.Queue
+	tasks *maintenance.Registry
+}
+
+func NewMaintenanceHandler(queue *jobs.Queue, tasks *maintenance.Registry) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		queue: queue,
+		tasks: tasks,
+	}
+}
+
+// --- GET /admin/maintenance/tasks ---
+
+func (h *MaintenanceHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tasks": h.tasks.Tasks(),
+	})
+}
+
+// --- POST /admin/maintenance/tasks/{name} ---
+// Starts the task as a job; poll GET /admin/jobs/{id} for progress.
+
+func (h *MaintenanceHandler) Start(w http.ResponseWriter, r *http.Request) {
+	task, ok := h.tasks.Get(chi.URLParam(r, "name"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown maintenance task"})
+		return
+	}
+
+	job, err := h.queue.Enqueue(r.Context(), maintenance.JobType, maintenance.Payload{Task: task.Name}, middleware.GetAdminName(r.Context()))
+	if err != nil {
+		log.Printf("Error enqueueing maintenance task: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start task"})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message": "task started",
+		"job":     job,
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/i18n"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/templates"
+)
+
+type NotificationHandler struct {
+	mailer *mailer.Mailer
+}
+
+func NewNotificationHandler(mail *mailer.Mailer) *NotificationHandler {
+	return &NotificationHandler{
+		mailer: mail,
+	}
+}
+
+// --- GET /admin/notifications/templates ---
+
+func (h *NotificationHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"templates": templates.List(),
+	})
+}
+
+// --- GET /admin/notifications/preview?template=&channel=[&locale=][&email_format=][&format=html] ---
+// Renders a template with sample data without sending anything.
+
+func (h *NotificationHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("template")
+	channel := templates.Channel(r.URL.Query().Get("channel"))
+	if name == "" || channel == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "template and channel are required"})
+		return
+	}
+
+	emailFormat := r.URL.Query().Get("email_format")
+	if !validEmailFormat(emailFormat) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email_format must be one of " + strings.Join(models.EmailFormats, ", ")})
+		return
+	}
+
+	rendered, err := templates.Preview(name, channel, templates.EmailOptions{Locale: r.URL.Query().Get("locale"), Format: emailFormat})
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Let admins open email previews directly in the browser
+	if r.URL.Query().Get("format") == "html" && rendered.HTML != "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(rendered.HTML))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"template": name,
+		"channel":  channel,
+		"locale":   i18n.For(r.URL.Query().Get("locale")).Locale(),
+		"rendered": rendered,
+	})
+}
+
+// --- POST /admin/test-email ---
+// Sends an email template (login_link by default) to an address using the live
+// provider config and reports what the provider returned. Data overrides the
+// template's sample data; locale picks the translation and email_format the
+// look (standard, high_contrast or plain).
+
+type TestEmailRequest struct {
+	To          string                 `json:"to"`
+	Template    string                 `json:"template"`
+	Locale      string                 `json:"locale"`
+	EmailFormat string                 `json:"email_format"`
+	Data        map[string]interface{} `json:"data"`
+}
+
+func validEmailFormat(format string) bool {
+	if format == "" {
+		return true
+	}
+	for _, f := range models.EmailFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *NotificationHandler) TestEmail(w http.ResponseWriter, r *http.Request) {
+	var req TestEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if _, err := mail.ParseAddress(req.To); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "a valid to address is required"})
+		return
+	}
+	if req.Template == "" {
+		req.Template = "login_link"
+	}
+	if !validEmailFormat(req.EmailFormat) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "email_format must be one of " + strings.Join(models.EmailFormats, ", ")})
+		return
+	}
+
+	content, err := templates.PreviewWith(req.Template, templates.ChannelEmail, templates.EmailOptions{Locale: req.Locale, Format: req.EmailFormat}, req.Data)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	start := time.Now()
+	delivery, err := h.mailer.Send(r.Context(), mailer.FromRendered(req.To, content))
+	elapsed := time.Since(start).Milliseconds()
+
+	log.Printf("📧 Test email %q to %s requested by %s (err: %v)", req.Template, redact.Email(req.To), middleware.GetAdminName(r.Context()), err)
+
+	resp := map[string]interface{}{
+		"template":    req.Template,
+		"to":          req.To,
+		"subject":     content.Subject,
+		"delivery":    delivery,
+		"duration_ms": elapsed,
+	}
+	if err != nil {
+		resp["error"] = err.Error()
+		writeJSON(w, http.StatusBadGateway, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// --- GET /admin/email/stats ---
+// Provider circuit state and send counters for this instance.
+
+func (h *NotificationHandler) EmailStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.mailer.Stats())
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/rollup"
+)
+
+type OnboardingAnalyticsHandler struct {
+	roller *rollup.Roller
+}
+
+func NewOnboardingAnalyticsHandler(roller *rollup.Roller) *OnboardingAnalyticsHandler {
+	return &OnboardingAnalyticsHandler{
+		roller: roller,
+	}
+}
+
+// --- GET /admin/analytics/onboarding-reminders?days=30 ---
+// Reminders sent and the onboardings completed after one, per step, over
+// whole UTC days. A conversion counts on the day onboarding was completed,
+// so near the start of the range it may belong to an earlier reminder.
+
+func (h *OnboardingAnalyticsHandler) Reminders(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 365 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = n
+	}
+	now := time.Now().UTC()
+
+	totals, since, err := h.roller.Totals(r.Context(), models.MetricOnboardingReminders, now.AddDate(0, 0, -days), now, []string{"sent", "converted"})
+	if err != nil {
+		log.Printf("Error computing onboarding reminder funnel: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	var sent, converted int64
+	steps := make([]models.OnboardingReminderStats, 0, len(totals))
+	for _, t := range totals {
+		sent += t.Values["sent"]
+		converted += t.Values["converted"]
+		steps = append(steps, models.OnboardingReminderStats{
+			Step:           t.Dimension,
+			Sent:           t.Values["sent"],
+			Converted:      t.Values["converted"],
+			ConversionRate: ratio(t.Values["converted"], t.Values["sent"]),
+		})
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Step < steps[j].Step })
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":           since,
+		"sent":            sent,
+		"converted":       converted,
+		"conversion_rate": ratio(converted, sent),
+		"by_step":         steps,
+	})
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OrgMemberHandler serves organization membership to signed-in users:
+// their organizations and invites, and the owner-only member and feedback
+// views.
+type OrgMemberHandler struct {
+	orgs         *service.OrgService
+	userRepo     *repository.UserRepo
+	feedbackRepo *repository.FeedbackRepo
+}
+
+func NewOrgMemberHandler(orgs *service.OrgService, userRepo *repository.UserRepo, feedbackRepo *repository.FeedbackRepo) *OrgMemberHandler {
+	return &OrgMemberHandler{
+		orgs:         orgs,
+		userRepo:     userRepo,
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+type InviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // owner or member, defaults to member
+}
+
+// currentUser loads the signed-in user, writing the error response itself
+// when it returns nil.
+func (h *OrgMemberHandler) currentUser(w http.ResponseWriter, r *http.Request) *models.User {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return nil
+	}
+	user, err := h.userRepo.FindByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error finding user: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return nil
+	}
+	if user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return nil
+	}
+	return user
+}
+
+// ownedOrg parses the {id} param and checks the signed-in user owns that
+// organization. It writes the error response itself when ok is false.
+func ownedOrg(w http.ResponseWriter, r *http.Request, orgs *service.OrgService) (orgID, userID bson.ObjectID, ok bool) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return orgID, userID, false
+	}
+	orgID, err = bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return orgID, userID, false
+	}
+	if err := orgs.RequireOwner(r.Context(), orgID, userID); err != nil {
+		writeServiceError(w, err, "Error checking organization owner")
+		return orgID, userID, false
+	}
+	return orgID, userID, true
+}
+
+// --- GET /user/orgs ---
+
+func (h *OrgMemberHandler) MyOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	memberships, err := h.orgs.Memberships(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Error listing memberships")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"organizations": memberships})
+}
+
+// --- GET /user/org-invites ---
+
+func (h *OrgMemberHandler) MyInvites(w http.ResponseWriter, r *http.Request) {
+	user := h.currentUser(w, r)
+	if user == nil {
+		return
+	}
+	invites, err := h.orgs.PendingInvites(r.Context(), user)
+	if err != nil {
+		writeServiceError(w, err, "Error listing invites")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"invites": invites})
+}
+
+// --- POST /user/org-invites/{id}/accept ---
+
+func (h *OrgMemberHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	inviteID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid invite ID"})
+		return
+	}
+	user := h.currentUser(w, r)
+	if user == nil {
+		return
+	}
+	member, err := h.orgs.Accept(r.Context(), user, inviteID)
+	if err != nil {
+		writeServiceError(w, err, "Error accepting invite")
+		return
+	}
+	writeJSON(w, http.StatusOK, member)
+}
+
+// --- GET /orgs/{id}/members ---
+
+func (h *OrgMemberHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	orgID, _, ok := ownedOrg(w, r, h.orgs)
+	if !ok {
+		return
+	}
+	h.writeMembers(w, r, orgID)
+}
+
+func (h *OrgMemberHandler) writeMembers(w http.ResponseWriter, r *http.Request, orgID bson.ObjectID) {
+	members, err := h.orgs.Members(r.Context(), orgID)
+	if err != nil {
+		writeServiceError(w, err, "Error listing members")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"members": members})
+}
+
+// --- POST /orgs/{id}/invites ---
+
+func (h *OrgMemberHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	orgID, _, ok := ownedOrg(w, r, h.orgs)
+	if !ok {
+		return
+	}
+	user := h.currentUser(w, r)
+	if user == nil {
+		return
+	}
+	h.invite(w, r, orgID, user.Email)
+}
+
+func (h *OrgMemberHandler) invite(w http.ResponseWriter, r *http.Request, orgID bson.ObjectID, invitedBy string) {
+	var req InviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	invite, err := h.orgs.Invite(r.Context(), orgID, req.Email, req.Role, invitedBy)
+	if err != nil {
+		writeServiceError(w, err, "Error inviting to organization")
+		return
+	}
+	writeJSON(w, http.StatusCreated, invite)
+}
+
+// --- DELETE /orgs/{id}/members/{userID} ---
+// Owners can remove anyone; members can only remove themselves (leave).
+
+func (h *OrgMemberHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	orgID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	target, err := bson.ObjectIDFromHex(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	if target.Hex() != middleware.GetUserID(r.Context()) {
+		if _, _, ok := ownedOrg(w, r, h.orgs); !ok {
+			return
+		}
+	}
+
+	if err := h.orgs.RemoveMember(r.Context(), orgID, target); err != nil {
+		writeServiceError(w, err, "Error removing member")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- GET /orgs/{id}/feedback ---
+// Feedback members filed under the organization, newest first, paged like
+// the integrations feed. Their other feedback stays private.
+
+func (h *OrgMemberHandler) ListFeedback(w http.ResponseWriter, r *http.Request) {
+	orgID, _, ok := ownedOrg(w, r, h.orgs)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	limit := int64(50)
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 1 || n > 100 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	var after *models.Feedback
+	if raw := query.Get("cursor"); raw != "" {
+		c, err := decodeFeedbackCursor(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cursor"})
+			return
+		}
+		after = c
+	}
+
+	org, err := h.orgs.Get(r.Context(), orgID)
+	if err != nil {
+		writeServiceError(w, err, "Error loading organization")
+		return
+	}
+	// Members' feedback lives in the organization's region
+	ctx := database.WithRegion(r.Context(), org.Region)
+	feedbacks, err := h.feedbackRepo.ListNewestFirstForOrg(ctx, orgID, after, limit)
+	if err != nil {
+		log.Printf("Error listing organization feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	nextCursor := ""
+	if int64(len(feedbacks)) == limit {
+		nextCursor = encodeFeedbackCursor(&feedbacks[len(feedbacks)-1])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"feedback":    feedbacks,
+		"next_cursor": nextCursor,
+	})
+}
+
+// --- GET /admin/orgs/{id}/members ---
+
+func (h *OrgMemberHandler) AdminListMembers(w http.ResponseWriter, r *http.Request) {
+	orgID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	h.writeMembers(w, r, orgID)
+}
+
+// --- POST /admin/orgs/{id}/invites ---
+// Lets support seat an organization's first owner.
+
+func (h *OrgMemberHandler) AdminInvite(w http.ResponseWriter, r *http.Request) {
+	orgID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	h.invite(w, r, orgID, middleware.GetAdminName(r.Context()))
+}
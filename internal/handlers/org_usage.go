@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+
+	"rizon-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type OrgUsageHandler struct {
+	usage *service.OrgUsageService
+	orgs  *service.OrgService
+}
+
+func NewOrgUsageHandler(usage *service.OrgUsageService, orgs *service.OrgService) *OrgUsageHandler {
+	return &OrgUsageHandler{
+		usage: usage,
+		orgs:  orgs,
+	}
+}
+
+// --- GET /orgs/{id}/usage ---
+// Seats and API usage for ?month=YYYY-MM (default this month), for the
+// organization's owners.
+
+func (h *OrgUsageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, _, ok := ownedOrg(w, r, h.orgs)
+	if !ok {
+		return
+	}
+	h.writeUsage(w, r, orgID)
+}
+
+// --- GET /admin/orgs/{id}/usage ---
+
+func (h *OrgUsageHandler) AdminGet(w http.ResponseWriter, r *http.Request) {
+	orgID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	h.writeUsage(w, r, orgID)
+}
+
+func (h *OrgUsageHandler) writeUsage(w http.ResponseWriter, r *http.Request, orgID bson.ObjectID) {
+	usage, err := h.usage.Month(r.Context(), orgID, r.URL.Query().Get("month"))
+	if err != nil {
+		writeServiceError(w, err, "Error computing organization usage")
+		return
+	}
+	reports, err := h.usage.Reports(r.Context(), orgID)
+	if err != nil {
+		writeServiceError(w, err, "Error listing usage reports")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"usage":   usage,
+		"reports": reports,
+	})
+}
+
+// --- GET /admin/billing/org-usage ---
+// Every organization's saved report for ?month=YYYY-MM, for invoicing.
+
+func (h *OrgUsageHandler) ListForMonth(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.usage.ForMonth(r.Context(), r.URL.Query().Get("month"))
+	if err != nil {
+		writeServiceError(w, err, "Error listing usage reports")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"reports": reports})
+}
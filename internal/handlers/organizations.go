@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,38}[a-z0-9]$`)
+	domainPattern = regexp.MustCompile(`^([a-z0-9-]+\.)+[a-z]{2,}$`)
+	colorPattern  = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// freeMailDomains can't be claimed by an organization: their users are
+// the general public.
+var freeMailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "outlook.com": true, "hotmail.com": true,
+	"live.com": true, "yahoo.com": true, "icloud.com": true, "me.com": true,
+	"aol.com": true, "proton.me": true, "protonmail.com": true, "gmx.com": true,
+}
+
+type OrganizationHandler struct {
+	orgRepo *repository.OrganizationRepo
+}
+
+func NewOrganizationHandler(orgRepo *repository.OrganizationRepo) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgRepo: orgRepo,
+	}
+}
+
+type OrganizationRequest struct {
+	Slug     string           `json:"slug"`   // create only
+	Region   string           `json:"region"` // create only; stored data doesn't move
+	Name     string           `json:"name"`
+	Domains  []string         `json:"domains"`
+	Branding *models.Branding `json:"branding"`
+}
+
+// validate normalises the request and returns a client-facing error message.
+func (req *OrganizationRequest) validate() string {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return "name is required (max 100 characters)"
+	}
+
+	domains := make([]string, 0, len(req.Domains))
+	seen := map[string]bool{}
+	for _, d := range req.Domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if !domainPattern.MatchString(d) {
+			return "invalid domain: " + d
+		}
+		if freeMailDomains[d] {
+			return d + " is a public email provider and can't belong to an organization"
+		}
+		if !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+	req.Domains = domains
+
+	if b := req.Branding; b != nil {
+		b.DisplayName = strings.TrimSpace(b.DisplayName)
+		if len(b.DisplayName) > 60 {
+			return "branding.display_name must be at most 60 characters"
+		}
+		if b.PrimaryColor != "" && !colorPattern.MatchString(b.PrimaryColor) {
+			return "branding.primary_color must look like #1a2b3c"
+		}
+		if b.LogoURL != "" {
+			u, err := url.Parse(b.LogoURL)
+			if err != nil || u.Scheme != "https" || u.Host == "" {
+				return "branding.logo_url must be an https URL"
+			}
+		}
+		if *b == (models.Branding{}) {
+			req.Branding = nil
+		}
+	}
+	return ""
+}
+
+// --- GET /admin/orgs ---
+
+func (h *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.orgRepo.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing organizations: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"organizations": orgs})
+}
+
+// --- POST /admin/orgs ---
+
+func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req OrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(req.Slug) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "slug must be 3-40 lower-case letters, digits or dashes"})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+	if req.Region != "" && !database.HasRegion(req.Region) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "region must be one of: " + strings.Join(database.Regions(), ", ")})
+		return
+	}
+
+	org := &models.Organization{Slug: req.Slug, Name: req.Name, Domains: req.Domains, Region: req.Region, Branding: req.Branding}
+	if err := h.orgRepo.Create(r.Context(), org); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "slug or domain already belongs to an organization"})
+			return
+		}
+		log.Printf("Error creating organization: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create organization"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, org)
+}
+
+// --- PUT /admin/orgs/{id} ---
+// Replaces name, domains and branding; the slug can't change.
+
+func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	var req OrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": msg})
+		return
+	}
+
+	org, err := h.orgRepo.Update(r.Context(), &models.Organization{ID: id, Name: req.Name, Domains: req.Domains, Branding: req.Branding})
+	if mongo.IsDuplicateKeyError(err) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "domain already belongs to another organization"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating organization: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update organization"})
+		return
+	}
+	if org == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "organization not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, org)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"rizon-backend/internal/mergepatch"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/service"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// maxPatchBytes caps merge patch bodies; the schemas are a handful of fields.
+const maxPatchBytes = 16 << 10
+
+// --- PATCH /user/profile ---
+// Body is a JSON merge patch (application/merge-patch+json) of locale and
+// time_zone.
+
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.patch(w, r, service.ProfileFields)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"locale":    user.Locale,
+		"time_zone": user.TimeZone,
+	})
+}
+
+// --- PATCH /user/preferences ---
+// Body is a JSON merge patch of product_emails, feedback_updates and
+// email_format (standard, high_contrast or plain).
+
+func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.patch(w, r, service.PreferenceFields)
+	if !ok {
+		return
+	}
+
+	prefs := user.Preferences
+	if prefs == nil {
+		prefs = &models.Preferences{}
+	}
+	writeJSON(w, http.StatusOK, prefs)
+}
+
+// patch decodes a merge patch and applies it to the caller's account. It
+// writes the error response itself and reports whether to go on.
+func (h *UserHandler) patch(w http.ResponseWriter, r *http.Request, fields mergepatch.Schema) (*models.User, bool) {
+	userIDHex := middleware.GetUserID(r.Context())
+	if userIDHex == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return nil, false
+	}
+
+	userID, err := bson.ObjectIDFromHex(userIDHex)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return nil, false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPatchBytes)
+	patch, err := mergepatch.Decode(r)
+	if errors.Is(err, mergepatch.ErrUnsupportedMediaType) {
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+
+	user, err := h.users.Patch(r.Context(), userID, fields, patch)
+	if errors.Is(err, service.ErrUserNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return nil, false
+	}
+	if err != nil {
+		writeServiceError(w, err, "Error patching user")
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/virusscan"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// quarantineUpload keeps an infected upload for review and tells the
+// uploader why it was refused.
+func (h *AttachmentHandler) quarantineUpload(w http.ResponseWriter, r *http.Request, userID bson.ObjectID, filename string, data []byte, result *virusscan.Result) {
+	sum := sha256.Sum256(data)
+	file := &models.QuarantinedFile{
+		UserID:      userID,
+		Filename:    filename,
+		ContentType: http.DetectContentType(data),
+		Size:        len(data),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Scanner:     h.scanner.Name(),
+		Signature:   result.Signature,
+		Data:        data,
+	}
+	if err := h.quarantine.Create(r.Context(), file); err != nil {
+		// Still refuse the upload; losing the sample beats storing it
+		log.Printf("Error quarantining attachment: %v", err)
+	} else {
+		log.Printf("☣️  Quarantined upload %s from user %s: %s", file.ID.Hex(), userID.Hex(), result.Signature)
+	}
+
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"error":     "the file failed a virus scan and was not uploaded",
+		"code":      "infected_file",
+		"signature": result.Signature,
+	})
+}
+
+// --- GET /admin/quarantine?status=pending ---
+
+func (h *AttachmentHandler) ListQuarantine(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.QuarantinePending, models.QuarantineReleased, models.QuarantineDiscarded:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid status"})
+		return
+	}
+
+	files, err := h.quarantine.List(r.Context(), status, 200)
+	if err != nil {
+		log.Printf("Error listing quarantined files: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"files": files})
+}
+
+// --- POST /admin/quarantine/{id}/release ---
+// Marks a detection as a false positive and stores the file as the user's
+// attachment. Quota is not charged against the admin's decision.
+
+func (h *AttachmentHandler) ReleaseQuarantined(w http.ResponseWriter, r *http.Request) {
+	file, ok := h.pendingQuarantined(w, r)
+	if !ok {
+		return
+	}
+
+	attachment := &models.Attachment{UserID: file.UserID, Filename: file.Filename}
+	if err := h.save(r.Context(), attachment, file.Data); err != nil {
+		if errors.Is(err, errInvalidImage) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+			return
+		}
+		log.Printf("Error releasing quarantined file: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	resolved, err := h.quarantine.Resolve(r.Context(), file.ID, models.QuarantineReleased, middleware.GetAdminName(r.Context()), &attachment.ID)
+	if err != nil || !resolved {
+		// Another admin got there first; don't leave a second copy behind
+		if _, delErr := h.attachmentRepo.Delete(r.Context(), file.UserID, attachment.ID); delErr != nil {
+			log.Printf("Error removing duplicate released attachment: %v", delErr)
+		}
+		if err != nil {
+			log.Printf("Error resolving quarantined file: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "file was already reviewed"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "file released",
+		"attachment": attachment,
+	})
+}
+
+// --- POST /admin/quarantine/{id}/discard ---
+// Confirms a detection and deletes the file's contents.
+
+func (h *AttachmentHandler) DiscardQuarantined(w http.ResponseWriter, r *http.Request) {
+	file, ok := h.pendingQuarantined(w, r)
+	if !ok {
+		return
+	}
+
+	resolved, err := h.quarantine.Resolve(r.Context(), file.ID, models.QuarantineDiscarded, middleware.GetAdminName(r.Context()), nil)
+	if err != nil {
+		log.Printf("Error resolving quarantined file: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if !resolved {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "file was already reviewed"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "file discarded"})
+}
+
+// pendingQuarantined loads the file named in the URL, writing the error
+// response itself unless it is still pending review.
+func (h *AttachmentHandler) pendingQuarantined(w http.ResponseWriter, r *http.Request) (*models.QuarantinedFile, bool) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+		return nil, false
+	}
+
+	file, err := h.quarantine.FindByID(r.Context(), id)
+	if err != nil {
+		log.Printf("Error loading quarantined file: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return nil, false
+	}
+	if file == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "file not found"})
+		return nil, false
+	}
+	if file.Status != models.QuarantinePending {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "file was already reviewed"})
+		return nil, false
+	}
+	return file, true
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"html/template"
+	"strings"
+	"sync"
+
+	"rizon-backend/internal/i18n"
+	"rizon-backend/internal/templates"
+)
+
+// AppLinks configures where the redirect page sends users who don't have the
+// app installed. Empty URLs are hidden.
+type AppLinks struct {
+	IOSStoreURL     string
+	AndroidStoreURL string
+	WebLoginURL     string // receives ?token=<token> for a browser session
+}
+
+type redirectPageData struct {
+	Brand           templates.Brand
+	DeepLink        template.URL
+	WebLoginURL     string
+	IOSStoreURL     string
+	AndroidStoreURL string
+	Platform        string // "ios", "android" or "desktop"
+	Locale          string
+}
+
+// detectPlatform classifies the device opening the login link.
+func detectPlatform(ua string) string {
+	lower := strings.ToLower(ua)
+	switch {
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad") || strings.Contains(lower, "ipod"):
+		return "ios"
+	case strings.Contains(lower, "android"):
+		return "android"
+	default:
+		return "desktop"
+	}
+}
+
+// redirectPage tries the rizon:// deep link on mobile and, if the page is still
+// visible after a short timeout (app not installed), reveals store links and
+// the web login option. Desktop visitors get the fallback straight away.
+const redirectPageSource = `<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{t "redirect.title"}}</title>
+	<style>
+		body { font-family: -apple-system, sans-serif; display: flex; justify-content: center; align-items: center; min-height: 100vh; margin: 0; background: #f5f3ff; }
+		.card { text-align: center; padding: 40px; background: white; border-radius: 16px; box-shadow: 0 4px 24px rgba(0,0,0,0.1); max-width: 400px; }
+		h1 { color: #333; font-size: 24px; }
+		p { color: #666; font-size: 16px; line-height: 1.5; }
+		.btn { display: inline-block; background: {{.Brand.PrimaryColor}}; color: white; padding: 14px 32px; border-radius: 10px; text-decoration: none; font-weight: 600; font-size: 16px; margin-top: 16px; }
+		.btn:hover { opacity: 0.9; }
+		.btn.secondary { background: #eef2ff; color: #4338ca; }
+		.spinner { width: 40px; height: 40px; border: 4px solid #e5e7eb; border-top: 4px solid {{.Brand.PrimaryColor}}; border-radius: 50%; animation: spin 1s linear infinite; margin: 0 auto 20px; }
+		.hidden { display: none; }
+		@keyframes spin { to { transform: rotate(360deg); } }
+	</style>
+</head>
+<body>
+	<div class="card">
+		{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" style="max-height: 48px; margin-bottom: 16px;">{{end}}
+		<div id="opening"{{if eq .Platform "desktop"}} class="hidden"{{end}}>
+			<div class="spinner"></div>
+			<h1>{{t "redirect.title"}}</h1>
+			<p>{{t "redirect.auto"}}</p>
+			<p>{{t "redirect.tap"}}</p>
+			<a href="{{.DeepLink}}" class="btn">{{t "common.open_app"}}</a>
+		</div>
+		<div id="fallback"{{if ne .Platform "desktop"}} class="hidden"{{end}}>
+			{{if eq .Platform "desktop"}}
+			<h1>{{t "redirect.desktop_heading"}}</h1>
+			<p>{{t "redirect.desktop_body"}}</p>
+			{{else}}
+			<h1>{{t "redirect.missing_heading"}}</h1>
+			<p>{{t "redirect.missing_body"}}</p>
+			{{end}}
+			{{if and .IOSStoreURL (ne .Platform "android")}}<a href="{{.IOSStoreURL}}" class="btn">{{t "redirect.app_store"}}</a><br>{{end}}
+			{{if and .AndroidStoreURL (ne .Platform "ios")}}<a href="{{.AndroidStoreURL}}" class="btn">{{t "redirect.play_store"}}</a><br>{{end}}
+			{{if .WebLoginURL}}<a href="{{.WebLoginURL}}" class="btn secondary">{{t "redirect.browser"}}</a>{{end}}
+		</div>
+	</div>
+	{{if ne .Platform "desktop"}}
+	<script>
+		// Auto-redirect to the app deep link
+		window.location.href = "{{.DeepLink}}";
+
+		// If we're still visible after the attempt, the app probably isn't installed
+		setTimeout(function () {
+			if (!document.hidden) {
+				document.getElementById("opening").classList.add("hidden");
+				document.getElementById("fallback").classList.remove("hidden");
+			}
+		}, 2000);
+	</script>
+	{{end}}
+</body>
+</html>`
+
+// redirectPages caches the page compiled per catalog locale.
+var redirectPages sync.Map // locale -> *template.Template
+
+// redirectPage returns the page for the closest supported locale.
+func redirectPage(locale string) *template.Template {
+	loc := i18n.For(locale)
+	if page, ok := redirectPages.Load(loc.Locale()); ok {
+		return page.(*template.Template)
+	}
+	page := template.Must(template.New("redirect").Funcs(loc.Funcs()).Parse(redirectPageSource))
+	actual, _ := redirectPages.LoadOrStore(loc.Locale(), page)
+	return actual.(*template.Template)
+}
+
+// A broken page panics on startup rather than on the first click
+func init() { redirectPage(i18n.DefaultLocale) }
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/httpclient"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/resilience"
+	"rizon-backend/internal/slack"
+)
+
+type ResilienceHandler struct {
+	slackQueue *slack.Queue
+}
+
+func NewResilienceHandler() *ResilienceHandler {
+	return &ResilienceHandler{}
+}
+
+// WithSlackQueue reports the Slack queue's stats. queue is nil when Slack
+// isn't configured.
+func (h *ResilienceHandler) WithSlackQueue(queue *slack.Queue) *ResilienceHandler {
+	h.slackQueue = queue
+	return h
+}
+
+// --- GET /admin/breakers ---
+// Circuit breaker state for every external dependency on this instance.
+
+func (h *ResilienceHandler) Breakers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"breakers": resilience.Breakers(),
+	})
+}
+
+// --- GET /admin/outbound ---
+// Calls to third parties (status classes, errors, retries, latency) per
+// destination on this instance.
+
+func (h *ResilienceHandler) Outbound(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"destinations": httpclient.Destinations(),
+	})
+}
+
+// --- GET /admin/database/stats ---
+// Connection pool and per-collection command latencies on this instance.
+
+func (h *ResilienceHandler) DatabaseStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"connections": database.Stats(),
+	})
+}
+
+// --- GET /admin/cache/stats ---
+// Hit rates of the response caches on this instance.
+
+func (h *ResilienceHandler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"caches": middleware.ResponseCaches(),
+	})
+}
+
+// --- GET /admin/timeouts ---
+// Requests and timeouts per route on this instance since startup.
+
+func (h *ResilienceHandler) Timeouts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"routes": middleware.RequestTimeouts(),
+	})
+}
+
+// --- GET /admin/slack/queue ---
+// Held, summarized and dropped Slack messages on this instance since startup.
+
+func (h *ResilienceHandler) SlackQueue(w http.ResponseWriter, r *http.Request) {
+	if h.slackQueue == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"enabled": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled": true,
+		"queue":   h.slackQueue.Stats(),
+	})
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/roadmap"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	maxRoadmapTitleLength       = 120
+	maxRoadmapDescriptionLength = 2000
+	roadmapListLimit            = 100
+)
+
+type RoadmapHandler struct {
+	roadmap      *repository.RoadmapRepo
+	feedbackRepo *repository.FeedbackRepo
+	queue        *jobs.Queue
+}
+
+func NewRoadmapHandler(roadmap *repository.RoadmapRepo, feedbackRepo *repository.FeedbackRepo, queue *jobs.Queue) *RoadmapHandler {
+	return &RoadmapHandler{roadmap: roadmap, feedbackRepo: feedbackRepo, queue: queue}
+}
+
+// RoadmapEntry is a roadmap item as shown to a signed-in user.
+type RoadmapEntry struct {
+	models.RoadmapItem
+	Voted bool `json:"voted"`
+}
+
+// --- GET /roadmap ---
+// Public feature requests, most voted first.
+
+func (h *RoadmapHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	items, err := h.roadmap.List(r.Context(), roadmapListLimit)
+	if err != nil {
+		log.Printf("Error listing roadmap: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	ids := make([]bson.ObjectID, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	voted, err := h.roadmap.VotedFor(r.Context(), userID, ids)
+	if err != nil {
+		log.Printf("Error loading roadmap votes: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	entries := make([]RoadmapEntry, len(items))
+	for i, item := range items {
+		entries[i] = RoadmapEntry{RoadmapItem: item, Voted: voted[item.ID]}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": entries})
+}
+
+// --- POST /roadmap/{id}/vote ---
+// One vote per user; voting again is a no-op.
+
+func (h *RoadmapHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(middleware.GetUserID(r.Context()))
+	if err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	itemID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid roadmap item ID"})
+		return
+	}
+
+	item, voted, err := h.roadmap.Vote(r.Context(), itemID, userID)
+	if err != nil {
+		log.Printf("Error recording roadmap vote: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if item == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "roadmap item not found"})
+		return
+	}
+
+	status, message := http.StatusCreated, "vote recorded"
+	if !voted {
+		status, message = http.StatusOK, "already voted"
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"message": message,
+		"item":    RoadmapEntry{RoadmapItem: *item, Voted: true},
+	})
+}
+
+// --- POST /admin/feedback/{id}/roadmap ---
+// Publishes the feedback as a feature request under a public title.
+
+type PublishRoadmapRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func (h *RoadmapHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	feedbackID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid feedback ID"})
+		return
+	}
+
+	var req PublishRoadmapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > maxRoadmapTitleLength {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title must be 1-120 characters"})
+		return
+	}
+	if utf8.RuneCountInString(req.Description) > maxRoadmapDescriptionLength {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "description must be at most 2000 characters"})
+		return
+	}
+
+	ctx, err := inFeedbackRegion(r.Context(), h.feedbackRepo, feedbackID)
+	if err != nil {
+		log.Printf("Error locating feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	feedback, err := h.feedbackRepo.FindByID(ctx, feedbackID)
+	if err != nil {
+		log.Printf("Error finding feedback: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if feedback == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "feedback not found"})
+		return
+	}
+
+	item := &models.RoadmapItem{
+		FeedbackID:  feedbackID,
+		Title:       req.Title,
+		Description: req.Description,
+		CreatedBy:   middleware.GetAdminName(r.Context()),
+	}
+	if err := h.roadmap.Create(r.Context(), item); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "feedback is already on the roadmap"})
+			return
+		}
+		log.Printf("Error publishing roadmap item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// --- DELETE /admin/roadmap/{id} ---
+// Takes the item off the roadmap; its votes are discarded.
+
+func (h *RoadmapHandler) Unpublish(w http.ResponseWriter, r *http.Request) {
+	itemID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid roadmap item ID"})
+		return
+	}
+
+	deleted, err := h.roadmap.Delete(r.Context(), itemID)
+	if err != nil {
+		log.Printf("Error deleting roadmap item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if !deleted {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "roadmap item not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "roadmap item removed"})
+}
+
+// --- POST /admin/roadmap/{id}/shipped ---
+// Links the item to the changelog entry announcing it and emails everyone
+// who voted for it, in the background. An item only ships once.
+
+type ShipRoadmapRequest struct {
+	ChangelogURL   string `json:"changelog_url"`
+	ChangelogTitle string `json:"changelog_title"` // optional button label
+}
+
+func (h *RoadmapHandler) Ship(w http.ResponseWriter, r *http.Request) {
+	itemID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid roadmap item ID"})
+		return
+	}
+
+	var req ShipRoadmapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if u, err := url.Parse(req.ChangelogURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "changelog_url must be an http(s) URL"})
+		return
+	}
+	req.ChangelogTitle = strings.TrimSpace(req.ChangelogTitle)
+	if utf8.RuneCountInString(req.ChangelogTitle) > maxRoadmapTitleLength {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "changelog_title must be at most 120 characters"})
+		return
+	}
+
+	existing, err := h.roadmap.FindByID(r.Context(), itemID)
+	if err != nil {
+		log.Printf("Error finding roadmap item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if existing == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "roadmap item not found"})
+		return
+	}
+
+	item, err := h.roadmap.MarkShipped(r.Context(), itemID, &models.Changelog{Title: req.ChangelogTitle, URL: req.ChangelogURL})
+	if err != nil {
+		log.Printf("Error shipping roadmap item: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if item == nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "roadmap item already shipped"})
+		return
+	}
+
+	job, err := h.queue.Enqueue(r.Context(), roadmap.JobType, roadmap.Payload{ItemID: item.ID}, middleware.GetAdminName(r.Context()))
+	if err != nil {
+		log.Printf("Error enqueueing shipped notifications for roadmap item %s: %v", item.ID.Hex(), err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to notify voters"})
+		return
+	}
+	log.Printf("🚀 Roadmap item %q shipped by %s; notifying %d voters", item.Title, middleware.GetAdminName(r.Context()), item.Votes)
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"item": item,
+		"job":  job,
+	})
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"rizon-backend/internal/authz"
+	"rizon-backend/internal/buildinfo"
+)
+
+type RouteHandler struct {
+	registry *authz.Registry
+}
+
+func NewRouteHandler(registry *authz.Registry) *RouteHandler {
+	return &RouteHandler{
+		registry: registry,
+	}
+}
+
+// --- GET /admin/routes ---
+// Every mounted route with its handler, policy and rate limit.
+
+func (h *RouteHandler) List(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"routes": h.registry.Routes()})
+}
+
+// --- GET /admin/routes/openapi.json ---
+// OpenAPI skeleton generated from the same routes, for the API docs to
+// build on.
+
+func (h *RouteHandler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.registry.OpenAPI("Rizon API", buildinfo.Commit))
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/middleware"
+)
+
+type SandboxHandler struct {
+	prepare []func(ctx context.Context) error
+}
+
+// NewSandboxHandler takes what a fresh sandbox needs: the EnsureIndexes of
+// repositories with sandbox collections, and any seed data.
+func NewSandboxHandler(prepare ...func(ctx context.Context) error) *SandboxHandler {
+	return &SandboxHandler{
+		prepare: prepare,
+	}
+}
+
+// --- POST /sandbox/reset ---
+// Wipes the caller's sandbox and recreates its indexes and seed data. Test
+// runs should start with it, since a sandbox that was never reset has no
+// indexes (so no idempotency or one-vote-per-user guarantees).
+
+func (h *SandboxHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	sandbox := database.SandboxOf(r.Context())
+	if err := database.DropSandbox(r.Context(), sandbox); err != nil {
+		log.Printf("Error dropping sandbox %s: %v", sandbox, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to reset sandbox"})
+		return
+	}
+	for _, prepare := range h.prepare {
+		if err := prepare(r.Context()); err != nil {
+			log.Printf("Error preparing sandbox %s: %v", sandbox, err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to reset sandbox"})
+			return
+		}
+	}
+	log.Printf("🧪 Sandbox of %s reset", middleware.GetAdminName(r.Context()))
+	writeJSON(w, http.StatusOK, map[string]string{"message": "sandbox reset"})
+}
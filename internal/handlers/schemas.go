@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"rizon-backend/internal/jsonschema"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SchemaHandler lets admins manage the JSON Schemas that free-form documents
+// (onboarding answers and the like) are validated against at ingestion.
+type SchemaHandler struct {
+	schemas *service.SchemaService
+}
+
+func NewSchemaHandler(schemas *service.SchemaService) *SchemaHandler {
+	return &SchemaHandler{schemas: schemas}
+}
+
+type schemaResponse struct {
+	models.DocumentSchema
+	Schema json.RawMessage `json:"schema"`
+}
+
+func newSchemaResponse(s *models.DocumentSchema) schemaResponse {
+	return schemaResponse{DocumentSchema: *s, Schema: json.RawMessage(s.Schema)}
+}
+
+// --- GET /admin/schemas ---
+
+func (h *SchemaHandler) List(w http.ResponseWriter, r *http.Request) {
+	list, err := h.schemas.List(r.Context())
+	if err != nil {
+		writeServiceError(w, err, "Error listing schemas")
+		return
+	}
+	out := make([]schemaResponse, len(list))
+	for i := range list {
+		out[i] = newSchemaResponse(&list[i])
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schemas": out,
+		"known":   []string{models.SchemaOnboardingAnswers},
+	})
+}
+
+// --- GET /admin/schemas/{name} ---
+
+func (h *SchemaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	schema, err := h.schemas.Get(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		writeServiceError(w, err, "Error loading schema")
+		return
+	}
+	writeJSON(w, http.StatusOK, newSchemaResponse(schema))
+}
+
+// --- PUT /admin/schemas/{name} ---
+// The body is the JSON Schema itself. It applies to documents ingested from
+// now on (other replicas pick it up within a minute).
+
+func (h *SchemaHandler) Put(w http.ResponseWriter, r *http.Request) {
+	raw, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	schema, err := h.schemas.Put(r.Context(), chi.URLParam(r, "name"), raw, middleware.GetAdminName(r.Context()))
+	if err != nil {
+		writeServiceError(w, err, "Error saving schema")
+		return
+	}
+	writeJSON(w, http.StatusOK, newSchemaResponse(schema))
+}
+
+// --- DELETE /admin/schemas/{name} ---
+
+func (h *SchemaHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.schemas.Delete(r.Context(), chi.URLParam(r, "name")); err != nil {
+		writeServiceError(w, err, "Error deleting schema")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- POST /admin/schemas/{name}/validate ---
+// Checks a sample document against the saved schema without storing it.
+
+func (h *SchemaHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	var doc interface{}
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	name := chi.URLParam(r, "name")
+	if _, err := h.schemas.Get(r.Context(), name); err != nil {
+		writeServiceError(w, err, "Error loading schema")
+		return
+	}
+
+	violations := []jsonschema.Violation{}
+	err := h.schemas.Validate(r.Context(), name, doc)
+	var verr *service.SchemaViolationError
+	if errors.As(err, &verr) {
+		violations = verr.Violations
+	} else if err != nil {
+		writeServiceError(w, err, "Error validating document")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":      len(violations) == 0,
+		"violations": violations,
+	})
+}
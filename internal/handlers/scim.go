@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SCIM 2.0 schema URNs (RFC 7643/7644).
+const (
+	scimUserSchema      = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimListSchema      = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimPatchSchema     = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimErrorSchema     = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSPConfigSchema  = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimContentType     = "application/scim+json"
+	scimMaxResults      = 200
+	scimDefaultPageSize = 100
+)
+
+// scimUserNameFilter is the only filter supported: userName eq "value".
+var scimUserNameFilter = regexp.MustCompile(`(?i)^\s*userName\s+eq\s+"([^"]*)"\s*$`)
+
+// SCIMHandler serves the SCIM 2.0 Users subset identity providers use to
+// provision and deprovision an organization's users, and the admin routes
+// that issue SCIM tokens.
+type SCIMHandler struct {
+	scim    *service.SCIMService
+	orgRepo *repository.OrganizationRepo
+}
+
+func NewSCIMHandler(scim *service.SCIMService, orgRepo *repository.OrganizationRepo) *SCIMHandler {
+	return &SCIMHandler{
+		scim:    scim,
+		orgRepo: orgRepo,
+	}
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+type scimUser struct {
+	Schemas    []string    `json:"schemas"`
+	ID         string      `json:"id"`
+	ExternalID string      `json:"externalId,omitempty"`
+	UserName   string      `json:"userName"`
+	Active     bool        `json:"active"`
+	Emails     []scimEmail `json:"emails"`
+	Meta       scimMeta    `json:"meta"`
+}
+
+// SCIMUserRequest is the body of POST and PUT. Attributes other than
+// userName, emails, externalId and active are accepted and ignored.
+type SCIMUserRequest struct {
+	UserName   string      `json:"userName"`
+	ExternalID string      `json:"externalId"`
+	Emails     []scimEmail `json:"emails"`
+	Active     *bool       `json:"active"`
+}
+
+// SCIMPatchRequest is a PatchOp body. Only the active attribute is acted on.
+type SCIMPatchRequest struct {
+	Schemas    []string `json:"schemas"`
+	Operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	} `json:"Operations"`
+}
+
+func writeSCIM(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeSCIMError(w http.ResponseWriter, status int, scimType, detail string) {
+	body := map[string]interface{}{
+		"schemas": []string{scimErrorSchema},
+		"status":  strconv.Itoa(status),
+		"detail":  detail,
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	writeSCIM(w, status, body)
+}
+
+// writeSCIMServiceError is writeServiceError in SCIM's error format.
+func writeSCIMServiceError(w http.ResponseWriter, err error, logMsg string) {
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", verr.Message)
+	case errors.Is(err, service.ErrMemberNotFound), errors.Is(err, service.ErrOrgNotFound):
+		writeSCIMError(w, http.StatusNotFound, "", "user not found")
+	case errors.Is(err, service.ErrAlreadyMember):
+		writeSCIMError(w, http.StatusConflict, "uniqueness", err.Error())
+	default:
+		log.Printf("%s: %v", logMsg, err)
+		writeSCIMError(w, http.StatusInternalServerError, "", "internal server error")
+	}
+}
+
+func (h *SCIMHandler) resource(r *http.Request, u *service.SCIMUser) scimUser {
+	return scimUser{
+		Schemas:    []string{scimUserSchema},
+		ID:         u.User.ID.Hex(),
+		ExternalID: u.Member.ExternalID,
+		UserName:   u.User.Email,
+		Active:     u.Active(),
+		Emails:     []scimEmail{{Value: u.User.Email, Primary: true}},
+		Meta: scimMeta{
+			ResourceType: "User",
+			Created:      u.Member.JoinedAt,
+			LastModified: u.User.UpdatedAt,
+			Location:     requestBaseURL(r) + "/scim/v2/Users/" + u.User.ID.Hex(),
+		},
+	}
+}
+
+// scimUserID parses the {id} param, writing the error response itself when ok
+// is false.
+func scimUserID(w http.ResponseWriter, r *http.Request) (bson.ObjectID, bool) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSCIMError(w, http.StatusNotFound, "", "user not found")
+		return id, false
+	}
+	return id, true
+}
+
+// --- GET /scim/v2/ServiceProviderConfig ---
+
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	unsupported := map[string]bool{"supported": false}
+	writeSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{scimSPConfigSchema},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scimMaxResults},
+		"changePassword": unsupported,
+		"sort":           unsupported,
+		"etag":           unsupported,
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Organization SCIM token issued by Rizon support",
+		}},
+	})
+}
+
+// --- GET /scim/v2/Users ---
+
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	email := ""
+	if filter := query.Get("filter"); filter != "" {
+		m := scimUserNameFilter.FindStringSubmatch(filter)
+		if m == nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidFilter", `only 'userName eq "..."' filters are supported`)
+			return
+		}
+		email = m[1]
+	}
+	startIndex, count := 1, scimDefaultPageSize
+	if raw := query.Get("startIndex"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 1 {
+			startIndex = n
+		}
+	}
+	if raw := query.Get("count"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			count = min(n, scimMaxResults)
+		}
+	}
+
+	users, total, err := h.scim.List(r.Context(), middleware.GetSCIMOrg(r.Context()), email, startIndex, count)
+	if err != nil {
+		writeSCIMServiceError(w, err, "Error listing SCIM users")
+		return
+	}
+	resources := make([]scimUser, len(users))
+	for i := range users {
+		resources[i] = h.resource(r, &users[i])
+	}
+	writeSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":      []string{scimListSchema},
+		"totalResults": total,
+		"startIndex":   startIndex,
+		"itemsPerPage": len(resources),
+		"Resources":    resources,
+	})
+}
+
+// --- POST /scim/v2/Users ---
+
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req SCIMUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+		return
+	}
+	email := req.UserName
+	if !strings.Contains(email, "@") {
+		email = ""
+		for _, e := range req.Emails {
+			if e.Primary || email == "" {
+				email = e.Value
+			}
+		}
+	}
+	active := req.Active == nil || *req.Active
+
+	u, err := h.scim.Create(r.Context(), middleware.GetSCIMOrg(r.Context()), email, req.ExternalID, active)
+	if err != nil {
+		writeSCIMServiceError(w, err, "Error provisioning SCIM user")
+		return
+	}
+	writeSCIM(w, http.StatusCreated, h.resource(r, u))
+}
+
+// --- GET /scim/v2/Users/{id} ---
+
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimUserID(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.scim.Get(r.Context(), middleware.GetSCIMOrg(r.Context()), id)
+	if err != nil {
+		writeSCIMServiceError(w, err, "Error loading SCIM user")
+		return
+	}
+	writeSCIM(w, http.StatusOK, h.resource(r, u))
+}
+
+// --- PUT /scim/v2/Users/{id} ---
+// Emails can't change here (they identify the Rizon account), so a replace
+// only applies active.
+
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimUserID(w, r)
+	if !ok {
+		return
+	}
+	var req SCIMUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+		return
+	}
+	h.setActive(w, r, id, req.Active == nil || *req.Active)
+}
+
+// --- PATCH /scim/v2/Users/{id} ---
+
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimUserID(w, r)
+	if !ok {
+		return
+	}
+	var req SCIMPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "invalid request body")
+		return
+	}
+
+	var active *bool
+	for _, op := range req.Operations {
+		if kind := strings.ToLower(op.Op); kind != "replace" && kind != "add" {
+			continue
+		}
+		value := op.Value
+		if !strings.EqualFold(op.Path, "active") {
+			if op.Path != "" {
+				continue
+			}
+			// No path: the value is an object of attributes
+			var attrs map[string]json.RawMessage
+			if json.Unmarshal(op.Value, &attrs) != nil || attrs["active"] == nil {
+				continue
+			}
+			value = attrs["active"]
+		}
+		b, err := scimBool(value)
+		if err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+		active = &b
+	}
+
+	if active == nil {
+		h.getUser(w, r, id)
+		return
+	}
+	h.setActive(w, r, id, *active)
+}
+
+// scimBool reads a boolean, also accepting the "True"/"False" strings some
+// identity providers send.
+func scimBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("active must be a boolean")
+}
+
+func (h *SCIMHandler) getUser(w http.ResponseWriter, r *http.Request, id bson.ObjectID) {
+	u, err := h.scim.Get(r.Context(), middleware.GetSCIMOrg(r.Context()), id)
+	if err != nil {
+		writeSCIMServiceError(w, err, "Error loading SCIM user")
+		return
+	}
+	writeSCIM(w, http.StatusOK, h.resource(r, u))
+}
+
+func (h *SCIMHandler) setActive(w http.ResponseWriter, r *http.Request, id bson.ObjectID, active bool) {
+	u, err := h.scim.SetActive(r.Context(), middleware.GetSCIMOrg(r.Context()), id, active)
+	if err != nil {
+		writeSCIMServiceError(w, err, "Error updating SCIM user")
+		return
+	}
+	writeSCIM(w, http.StatusOK, h.resource(r, u))
+}
+
+// --- DELETE /scim/v2/Users/{id} ---
+
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := scimUserID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.scim.Delete(r.Context(), middleware.GetSCIMOrg(r.Context()), id); err != nil {
+		writeSCIMServiceError(w, err, "Error deprovisioning SCIM user")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- POST /admin/orgs/{id}/scim-token ---
+// Issues a new SCIM token, replacing any previous one. The token is only
+// shown in this response.
+
+func (h *SCIMHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	plaintext := "rzs_" + hex.EncodeToString(buf)
+
+	org, err := h.orgRepo.SetSCIMToken(r.Context(), id, &models.SCIMToken{
+		TokenHash: middleware.HashAdminKey(plaintext),
+		Prefix:    plaintext[:10],
+		CreatedAt: time.Now(),
+		CreatedBy: middleware.GetAdminName(r.Context()),
+	})
+	if err != nil {
+		log.Printf("Error saving SCIM token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create token"})
+		return
+	}
+	if org == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "organization not found"})
+		return
+	}
+	log.Printf("🔑 SCIM token for organization %s issued by %s", org.Slug, middleware.GetAdminName(r.Context()))
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"token":    plaintext,
+		"base_url": requestBaseURL(r) + "/scim/v2",
+		"details":  org.SCIM,
+	})
+}
+
+// --- DELETE /admin/orgs/{id}/scim-token ---
+
+func (h *SCIMHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	org, err := h.orgRepo.SetSCIMToken(r.Context(), id, nil)
+	if err != nil {
+		log.Printf("Error revoking SCIM token: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revoke token"})
+		return
+	}
+	if org == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "organization not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/sessionpolicy"
+)
+
+type SessionPolicyHandler struct {
+	statsRepo *repository.SessionPolicyRepo
+	sessions  *sessionpolicy.Selector
+}
+
+func NewSessionPolicyHandler(statsRepo *repository.SessionPolicyRepo, sessions *sessionpolicy.Selector) *SessionPolicyHandler {
+	return &SessionPolicyHandler{
+		statsRepo: statsRepo,
+		sessions:  sessions,
+	}
+}
+
+// SessionPolicyTotals aggregates a policy's counters over the requested window.
+type SessionPolicyTotals struct {
+	Policy    string `json:"policy"`
+	Issued    int64  `json:"issued"`
+	Refreshed int64  `json:"refreshed"`
+	Expired   int64  `json:"expired"`
+	// Share of issued tokens that later hit an endpoint after expiring,
+	// i.e. the client failed to refresh in time and the user was logged out.
+	ExpiryRate float64 `json:"expiry_rate"`
+}
+
+// --- GET /admin/analytics/session-policies?days=14 ---
+
+func (h *SessionPolicyHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	days := 14
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 90 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = n
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+
+	daily, err := h.statsRepo.ListSince(r.Context(), since)
+	if err != nil {
+		log.Printf("Error listing session policy stats: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	byPolicy := map[string]*SessionPolicyTotals{}
+	totals := []*SessionPolicyTotals{}
+	add := func(name string) *SessionPolicyTotals {
+		if t, ok := byPolicy[name]; ok {
+			return t
+		}
+		t := &SessionPolicyTotals{Policy: name}
+		byPolicy[name] = t
+		totals = append(totals, t)
+		return t
+	}
+	for _, p := range h.sessions.Policies() {
+		add(p.Name)
+	}
+	for _, row := range daily {
+		t := add(row.Policy)
+		t.Issued += row.Issued
+		t.Refreshed += row.Refreshed
+		t.Expired += row.Expired
+	}
+	for _, t := range totals {
+		t.ExpiryRate = ratio(t.Expired, t.Issued+t.Refreshed)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":    since,
+		"policies": h.sessions.Policies(),
+		"totals":   totals,
+		"daily":    daily,
+	})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/rollup"
+)
+
+type SignupAnalyticsHandler struct {
+	roller *rollup.Roller
+}
+
+func NewSignupAnalyticsHandler(roller *rollup.Roller) *SignupAnalyticsHandler {
+	return &SignupAnalyticsHandler{
+		roller: roller,
+	}
+}
+
+// --- GET /admin/analytics/signups?days=30 ---
+// Counts whole UTC days, so since is rounded down to midnight.
+
+func (h *SignupAnalyticsHandler) ByCountry(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 365 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = n
+	}
+	now := time.Now().UTC()
+
+	totals, since, err := h.roller.Totals(r.Context(), models.MetricSignups, now.AddDate(0, 0, -days), now, []string{"count"})
+	if err != nil {
+		log.Printf("Error computing signups by country: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	var total int64
+	countries := make([]models.SignupCountryCount, 0, len(totals))
+	for _, t := range totals {
+		total += t.Values["count"]
+		countries = append(countries, models.SignupCountryCount{Country: t.Dimension, Count: t.Values["count"]})
+	}
+	sort.Slice(countries, func(i, j int) bool {
+		if countries[i].Count != countries[j].Count {
+			return countries[i].Count > countries[j].Count
+		}
+		return countries[i].Country < countries[j].Country
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":      since,
+		"total":      total,
+		"by_country": countries,
+	})
+}
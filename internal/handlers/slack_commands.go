@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/repository"
+)
+
+// maxSlackFeedback caps "feedback latest N" so replies stay readable.
+const maxSlackFeedback = 20
+
+type SlackCommandHandler struct {
+	userRepo     *repository.UserRepo
+	feedbackRepo *repository.FeedbackRepo
+}
+
+func NewSlackCommandHandler(userRepo *repository.UserRepo, feedbackRepo *repository.FeedbackRepo) *SlackCommandHandler {
+	return &SlackCommandHandler{
+		userRepo:     userRepo,
+		feedbackRepo: feedbackRepo,
+	}
+}
+
+const slackCommandHelp = "Usage:\n" +
+	"• `/rizon user <email>` — look up an account\n" +
+	"• `/rizon feedback latest [n]` — newest feedback (max 20)"
+
+// --- POST /webhooks/slack/commands ---
+// Slash command endpoint (signature checked by middleware). Replies are
+// ephemeral, so only the person asking sees user data.
+
+func (h *SlackCommandHandler) Command(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid form body"})
+		return
+	}
+	args := strings.Fields(r.PostForm.Get("text"))
+	log.Printf("💬 Slack command from %s: %s", r.PostForm.Get("user_name"), redact.Text(r.PostForm.Get("text"), 100))
+
+	var reply string
+	var err error
+	switch {
+	case len(args) == 2 && args[0] == "user":
+		reply, err = h.userSummary(r.Context(), args[1])
+	case len(args) >= 2 && len(args) <= 3 && args[0] == "feedback" && args[1] == "latest":
+		n := 5
+		if len(args) == 3 {
+			if n, err = strconv.Atoi(args[2]); err != nil || n < 1 {
+				reply, err = slackCommandHelp, nil
+				break
+			}
+		}
+		reply, err = h.latestFeedback(r.Context(), min(n, maxSlackFeedback))
+	default:
+		reply = slackCommandHelp
+	}
+	if err != nil {
+		log.Printf("Error handling Slack command: %v", err)
+		reply = "Something went wrong, check the server logs."
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"response_type": "ephemeral",
+		"text":          reply,
+	})
+}
+
+func (h *SlackCommandHandler) userSummary(ctx context.Context, email string) (string, error) {
+	// Slack auto-links addresses as <mailto:a@b.c|a@b.c>
+	if strings.HasPrefix(email, "<mailto:") {
+		if _, label, ok := strings.Cut(strings.TrimSuffix(email, ">"), "|"); ok {
+			email = label
+		}
+	}
+
+	user, err := h.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return fmt.Sprintf("No user with email %s", email), nil
+	}
+
+	status := user.Status
+	if status == "" {
+		status = "active"
+	}
+	lines := []string{
+		fmt.Sprintf("*%s* `%s`", user.Email, user.ID.Hex()),
+		fmt.Sprintf("Status: %s", status),
+		fmt.Sprintf("Onboarded: %t", user.OnboardingCompleted),
+		fmt.Sprintf("Signed up: %s", user.CreatedAt.Format("2006-01-02 15:04 MST")),
+	}
+	if user.BanReason != "" {
+		lines = append(lines, fmt.Sprintf("Ban reason: %s", user.BanReason))
+	}
+	if user.SignupGeo != nil && user.SignupGeo.Country != "" {
+		lines = append(lines, fmt.Sprintf("Country: %s", user.SignupGeo.Country))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (h *SlackCommandHandler) latestFeedback(ctx context.Context, n int) (string, error) {
+	feedbacks, err := h.feedbackRepo.ListNewestFirst(ctx, time.Time{}, nil, int64(n))
+	if err != nil {
+		return "", err
+	}
+	if len(feedbacks) == 0 {
+		return "No feedback yet.", nil
+	}
+
+	lines := make([]string, 0, len(feedbacks))
+	for _, f := range feedbacks {
+		lines = append(lines, fmt.Sprintf("%s `%s` %s [%s] %s",
+			strings.Repeat("⭐", f.Rating), f.ID.Hex(), f.CreatedAt.Format("Jan 2 15:04"), f.Status, redact.Text(f.Text, 200)))
+	}
+	return strings.Join(lines, "\n"), nil
+}
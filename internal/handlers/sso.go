@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ssoStateCookie binds an SSO sign-in to the browser that started it, so a
+// callback URL can't be replayed into someone else's session.
+const ssoStateCookie = "rizon_sso_state"
+
+type SSOHandler struct {
+	sso *service.SSOService
+}
+
+func NewSSOHandler(sso *service.SSOService) *SSOHandler {
+	return &SSOHandler{
+		sso: sso,
+	}
+}
+
+type SSORequest struct {
+	Issuer       string `json:"issuer"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Enforced     bool   `json:"enforced"`
+}
+
+type LinkSSOSubjectRequest struct {
+	Subject string `json:"subject"` // the IdP's "sub" claim; empty unlinks
+}
+
+func ssoCallbackURL(r *http.Request, slug string) string {
+	return requestBaseURL(r) + "/auth/sso/" + url.PathEscape(slug) + "/callback"
+}
+
+// --- GET /auth/sso/{org}/start ---
+// Opened in the browser; sends the user to their organization's identity
+// provider.
+
+func (h *SSOHandler) Start(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "org")
+	authURL, state, err := h.sso.Start(r.Context(), slug, ssoCallbackURL(r, slug))
+	if errors.Is(err, service.ErrSSONotConfigured) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error starting SSO: %v", err)
+		http.Error(w, "Single sign-on is unavailable, please try again later", http.StatusBadGateway)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Value:    state,
+		Path:     "/auth/sso/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// --- GET /auth/sso/{org}/callback ---
+// The identity provider sends the browser back here. On success it continues
+// to the same redirect page as magic links, which opens the app.
+
+func (h *SSOHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "org")
+	query := r.URL.Query()
+	if msg := query.Get("error"); msg != "" {
+		http.Error(w, "Sign-in was cancelled or denied: "+msg, http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := r.Cookie(ssoStateCookie)
+	if err != nil || cookie.Value != query.Get("state") {
+		http.Error(w, service.ErrSSOState.Error(), http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: ssoStateCookie, Path: "/auth/sso/", MaxAge: -1})
+
+	authToken, org, err := h.sso.Finish(r.Context(), slug, query.Get("state"), query.Get("code"), ssoCallbackURL(r, slug))
+	switch {
+	case errors.Is(err, service.ErrSSOState):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrSSONotConfigured):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, service.ErrSSOForeignEmail):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		log.Printf("Error finishing SSO: %v", err)
+		http.Error(w, "Single sign-on failed, please try again", http.StatusBadGateway)
+		return
+	}
+
+	http.Redirect(w, r, "/auth/redirect?token="+url.QueryEscape(authToken.Token)+"&org="+url.QueryEscape(org.Slug), http.StatusFound)
+}
+
+// --- PUT /admin/orgs/{id}/sso ---
+
+func (h *SSOHandler) Configure(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	var req SSORequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	org, err := h.sso.Configure(r.Context(), id, &models.SSOConfig{
+		Issuer:       req.Issuer,
+		ClientID:     req.ClientID,
+		ClientSecret: req.ClientSecret,
+		Enforced:     req.Enforced,
+		UpdatedBy:    middleware.GetAdminName(r.Context()),
+	})
+	if err != nil {
+		writeServiceError(w, err, "Error saving SSO configuration")
+		return
+	}
+	writeJSON(w, http.StatusOK, org)
+}
+
+// --- DELETE /admin/orgs/{id}/sso ---
+
+func (h *SSOHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	id, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	if err := h.sso.Remove(r.Context(), id); err != nil {
+		writeServiceError(w, err, "Error removing SSO configuration")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- PUT /admin/orgs/{id}/members/{userID}/sso-subject ---
+// Links a member whose email is outside the organization's domains to their
+// identity at its IdP, so they can sign in through SSO.
+
+func (h *SSOHandler) LinkSubject(w http.ResponseWriter, r *http.Request) {
+	orgID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid organization ID"})
+		return
+	}
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+	var req LinkSSOSubjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	err = h.sso.LinkSubject(r.Context(), orgID, userID, req.Subject)
+	if errors.Is(err, service.ErrSSOSubjectTaken) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err, "Error linking SSO subject")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/resilience"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Component and overall statuses, from best to worst
+const (
+	StatusOperational   = "operational"
+	StatusNotConfigured = "not_configured"
+	StatusDegraded      = "degraded"
+	StatusOutage        = "outage"
+)
+
+var statusRank = map[string]int{
+	StatusOperational:   0,
+	StatusNotConfigured: 0,
+	StatusDegraded:      1,
+	StatusOutage:        2,
+}
+
+// Resolved incidents stay on the status page this long
+const incidentHistory = 7 * 24 * time.Hour
+
+type StatusHandler struct {
+	incidentRepo *repository.IncidentRepo
+	mailer       *mailer.Mailer
+}
+
+func NewStatusHandler(incidentRepo *repository.IncidentRepo, mail *mailer.Mailer) *StatusHandler {
+	return &StatusHandler{
+		incidentRepo: incidentRepo,
+		mailer:       mail,
+	}
+}
+
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// --- GET /status ---
+// Public summary for the app and status page. Only states are exposed,
+// never error details.
+
+func (h *StatusHandler) Status(w http.ResponseWriter, r *http.Request) {
+	components := []ComponentStatus{
+		{Name: "database", Status: h.databaseStatus(r.Context())},
+		{Name: "email", Status: h.emailStatus()},
+		{Name: "slack", Status: slackStatus()},
+	}
+
+	incidents, err := h.incidentRepo.ListRecent(r.Context(), time.Now().Add(-incidentHistory), 10)
+	if err != nil {
+		log.Printf("Error listing incidents: %v", err)
+		incidents = []models.Incident{}
+	}
+
+	overall := StatusOperational
+	worse := func(s string) {
+		if statusRank[s] > statusRank[overall] {
+			overall = s
+		}
+	}
+	for _, c := range components {
+		worse(c.Status)
+	}
+	for _, incident := range incidents {
+		if incident.Status == models.IncidentResolved {
+			continue
+		}
+		if incident.Impact == models.ImpactCritical {
+			worse(StatusOutage)
+		} else {
+			worse(StatusDegraded)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     overall,
+		"components": components,
+		"incidents":  incidents,
+		"checked_at": time.Now(),
+	})
+}
+
+func (h *StatusHandler) databaseStatus(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := database.Ping(ctx); err != nil {
+		log.Printf("Error pinging MongoDB for status: %v", err)
+		return StatusOutage
+	}
+	return StatusOperational
+}
+
+// emailStatus is degraded while failover is engaged and an outage only when
+// every provider's circuit is open.
+func (h *StatusHandler) emailStatus() string {
+	providers := h.mailer.Stats().Providers
+	if len(providers) == 0 {
+		return StatusNotConfigured
+	}
+	open := 0
+	for _, p := range providers {
+		if p.State != resilience.StateClosed {
+			open++
+		}
+	}
+	switch {
+	case open == len(providers):
+		return StatusOutage
+	case providers[0].State != resilience.StateClosed:
+		return StatusDegraded
+	}
+	return StatusOperational
+}
+
+func slackStatus() string {
+	stats, ok := resilience.Lookup("slack")
+	switch {
+	case !ok:
+		return StatusNotConfigured
+	case stats.State != resilience.StateClosed:
+		return StatusDegraded
+	}
+	return StatusOperational
+}
+
+// --- POST /admin/incidents ---
+
+type CreateIncidentRequest struct {
+	Title      string   `json:"title"`
+	Impact     string   `json:"impact"`
+	Status     string   `json:"status"` // defaults to investigating
+	Message    string   `json:"message"`
+	Components []string `json:"components"`
+}
+
+func (h *StatusHandler) CreateIncident(w http.ResponseWriter, r *http.Request) {
+	var req CreateIncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Status == "" {
+		req.Status = models.IncidentInvestigating
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	switch {
+	case req.Title == "" || strings.TrimSpace(req.Message) == "":
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title and message are required"})
+		return
+	case !models.ValidIncidentImpact(req.Impact):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "impact must be minor, major or critical"})
+		return
+	case !models.ValidIncidentStatus(req.Status):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid status"})
+		return
+	}
+
+	now := time.Now()
+	incident := &models.Incident{
+		Title:      req.Title,
+		Status:     req.Status,
+		Impact:     req.Impact,
+		Components: req.Components,
+		Updates:    []models.IncidentUpdate{{Status: req.Status, Message: req.Message, CreatedAt: now}},
+		CreatedBy:  middleware.GetAdminName(r.Context()),
+	}
+	if req.Status == models.IncidentResolved {
+		incident.ResolvedAt = &now
+	}
+	if err := h.incidentRepo.Create(r.Context(), incident); err != nil {
+		log.Printf("Error creating incident: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, incident)
+}
+
+// --- POST /admin/incidents/{id}/updates ---
+
+type IncidentUpdateRequest struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func (h *StatusHandler) AddIncidentUpdate(w http.ResponseWriter, r *http.Request) {
+	incidentID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid incident ID"})
+		return
+	}
+
+	var req IncidentUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if !models.ValidIncidentStatus(req.Status) || strings.TrimSpace(req.Message) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "a valid status and message are required"})
+		return
+	}
+
+	incident, err := h.incidentRepo.AddUpdate(r.Context(), incidentID, models.IncidentUpdate{Status: req.Status, Message: req.Message})
+	if err != nil {
+		log.Printf("Error updating incident: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if incident == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "incident not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, incident)
+}
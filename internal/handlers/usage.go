@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type UsageHandler struct {
+	usageRepo *repository.UsageRepo
+	quotaRepo *repository.QuotaRepo
+}
+
+func NewUsageHandler(usageRepo *repository.UsageRepo, quotaRepo *repository.QuotaRepo) *UsageHandler {
+	return &UsageHandler{
+		usageRepo: usageRepo,
+		quotaRepo: quotaRepo,
+	}
+}
+
+type SetQuotaRequest struct {
+	DailyLimit int64 `json:"daily_limit"`
+}
+
+// --- GET /user/usage ---
+
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	userIDHex := middleware.GetUserID(r.Context())
+	if userIDHex == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	userID, err := bson.ObjectIDFromHex(userIDHex)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	// Default to the last 7 days, capped at 90
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 90 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = n
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+
+	records, err := h.usageRepo.ListForUser(r.Context(), userID, since)
+	if err != nil {
+		log.Printf("Error listing usage: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	quotas, err := h.quotaRepo.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing quotas: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":  since,
+		"usage":  records,
+		"quotas": quotas,
+	})
+}
+
+// --- GET /admin/quotas ---
+
+func (h *UsageHandler) ListQuotas(w http.ResponseWriter, r *http.Request) {
+	quotas, err := h.quotaRepo.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing quotas: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"quotas": quotas})
+}
+
+// --- PUT /admin/quotas/{endpoint} ---
+
+func (h *UsageHandler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	endpoint := chi.URLParam(r, "endpoint")
+
+	var req SetQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.DailyLimit < 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "daily_limit must be positive"})
+		return
+	}
+
+	if err := h.quotaRepo.Upsert(r.Context(), endpoint, req.DailyLimit); err != nil {
+		log.Printf("Error saving quota: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save quota"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"endpoint":    endpoint,
+		"daily_limit": req.DailyLimit,
+	})
+}
+
+// --- DELETE /admin/quotas/{endpoint} ---
+
+func (h *UsageHandler) DeleteQuota(w http.ResponseWriter, r *http.Request) {
+	endpoint := chi.URLParam(r, "endpoint")
+	if err := h.quotaRepo.Delete(r.Context(), endpoint); err != nil {
+		log.Printf("Error deleting quota: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete quota"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "quota removed"})
+}
@@ -1,21 +1,27 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
 
 	"rizon-backend/internal/middleware"
 	"rizon-backend/internal/repository"
+	"rizon-backend/internal/service"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type UserHandler struct {
+	users    *service.UserService
 	userRepo *repository.UserRepo
 }
 
-func NewUserHandler(userRepo *repository.UserRepo) *UserHandler {
+func NewUserHandler(users *service.UserService, userRepo *repository.UserRepo) *UserHandler {
 	return &UserHandler{
+		users:    users,
 		userRepo: userRepo,
 	}
 }
@@ -35,23 +41,30 @@ func (h *UserHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userRepo.FindByID(r.Context(), userID)
+	user, err := h.users.Get(r.Context(), userID)
+	if errors.Is(err, service.ErrUserNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
 	if err != nil {
 		log.Printf("Error finding user: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
-	if user == nil {
-		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
-		return
-	}
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"onboarding_completed": user.OnboardingCompleted,
+		"age_required":         h.users.AgeRequired(user),
 	})
 }
 
 // --- PATCH /user/onboarding ---
+// Marks onboarding complete. The body is optional; its answers object is
+// stored after validation against the onboarding_answers schema.
+
+type CompleteOnboardingRequest struct {
+	Answers map[string]interface{} `json:"answers,omitempty"`
+}
 
 func (h *UserHandler) CompleteOnboarding(w http.ResponseWriter, r *http.Request) {
 	userIDHex := middleware.GetUserID(r.Context())
@@ -66,7 +79,26 @@ func (h *UserHandler) CompleteOnboarding(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.userRepo.UpdateOnboarding(r.Context(), userID, true); err != nil {
+	var req CompleteOnboardingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	err = h.users.CompleteOnboarding(r.Context(), userID, req.Answers)
+	var violation *service.SchemaViolationError
+	if errors.As(err, &violation) {
+		writeServiceError(w, err, "")
+		return
+	}
+	if errors.Is(err, service.ErrAgeRequired) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": err.Error(),
+			"code":  "age_required",
+		})
+		return
+	}
+	if err != nil {
 		log.Printf("Error updating onboarding: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update onboarding status"})
 		return
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"rizon-backend/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type SetUserStatusRequest struct {
+	Status string     `json:"status"` // "active", "suspended" or "banned"
+	Reason string     `json:"reason"` // required unless reactivating
+	Until  *time.Time `json:"until"`  // optional automatic unban
+}
+
+// --- PUT /admin/users/{id}/status ---
+
+func (h *UserHandler) SetStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := bson.ObjectIDFromHex(chi.URLParam(r, "id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+
+	var req SetUserStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	switch req.Status {
+	case models.UserStatusActive:
+		req.Reason, req.Until = "", nil
+	case models.UserStatusSuspended, models.UserStatusBanned:
+		if !slices.Contains(models.BanReasons, req.Reason) {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"error":         "reason must be a known reason code",
+				"valid_reasons": models.BanReasons,
+			})
+			return
+		}
+		if req.Until != nil && !req.Until.After(time.Now()) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "until must be in the future"})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "status must be \"active\", \"suspended\" or \"banned\""})
+		return
+	}
+
+	found, err := h.userRepo.SetStatus(r.Context(), userID, req.Status, req.Reason, req.Until)
+	if err != nil {
+		log.Printf("Error updating user status: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update status"})
+		return
+	}
+	if !found {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "user status updated",
+		"status":  req.Status,
+		"reason":  req.Reason,
+		"until":   req.Until,
+	})
+}
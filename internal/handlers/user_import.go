@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/userimport"
+)
+
+// maxImportBytes caps uploaded CSVs well below Mongo's 16MB document limit.
+const maxImportBytes = 8 << 20
+
+type UserImportHandler struct {
+	queue *jobs.Queue
+}
+
+func NewUserImportHandler(queue *jobs.Queue) *UserImportHandler {
+	return &UserImportHandler{
+		queue: queue,
+	}
+}
+
+// --- POST /admin/users/import ---
+// Accepts a CSV body (text/csv) or a multipart upload in the "file" field with
+// columns email, created_at (optional) and onboarding (optional).
+
+func (h *UserImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "file upload is required"})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "CSV is too large"})
+		return
+	}
+	if err := userimport.Validate(string(data)); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	job, err := h.queue.Enqueue(r.Context(), userimport.JobType, userimport.Payload{CSV: string(data)}, middleware.GetAdminName(r.Context()))
+	if err != nil {
+		log.Printf("Error enqueueing user import: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start import"})
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"message": "import started",
+		"job":     job,
+	})
+}
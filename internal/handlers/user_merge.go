@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var errSourceAlreadyMerged = errors.New("source account already merged")
+
+type UserMergeHandler struct {
+	userRepo     *repository.UserRepo
+	feedbackRepo *repository.FeedbackRepo
+	consentRepo  *repository.ConsentRepo
+	noteRepo     *repository.AdminNoteRepo
+	auditRepo    *repository.AuditRepo
+}
+
+func NewUserMergeHandler(userRepo *repository.UserRepo, feedbackRepo *repository.FeedbackRepo, consentRepo *repository.ConsentRepo, noteRepo *repository.AdminNoteRepo, auditRepo *repository.AuditRepo) *UserMergeHandler {
+	return &UserMergeHandler{
+		userRepo:     userRepo,
+		feedbackRepo: feedbackRepo,
+		consentRepo:  consentRepo,
+		noteRepo:     noteRepo,
+		auditRepo:    auditRepo,
+	}
+}
+
+type MergeUsersRequest struct {
+	SourceID string `json:"source_id"` // account folded away and deactivated
+	TargetID string `json:"target_id"` // account that keeps everything
+}
+
+// --- POST /admin/users/merge ---
+// Moves feedback, consents and admin notes from source to target, marks the
+// source as merged and writes an audit entry, all in one transaction. The
+// source is only marked merged once everything has moved, and every step
+// can be repeated, so a merge that fails partway (say, feedback in a
+// regional database outside the transaction) is finished by sending the
+// same request again. Sessions need no move: the source's JWTs stop working once it's merged,
+// and logging in with its email lands in the target. Usage counters stay
+// behind since they only drive daily quotas.
+
+func (h *UserMergeHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	var req MergeUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	sourceID, err := bson.ObjectIDFromHex(req.SourceID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid source_id"})
+		return
+	}
+	targetID, err := bson.ObjectIDFromHex(req.TargetID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid target_id"})
+		return
+	}
+	if sourceID == targetID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "source and target must differ"})
+		return
+	}
+
+	source, target, ok := h.loadPair(w, r, sourceID, targetID)
+	if !ok {
+		return
+	}
+	if target.Status == models.UserStatusMerged {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "target account was itself merged"})
+		return
+	}
+	// Merged into target already means an earlier attempt is being finished
+	if source.Status == models.UserStatusMerged && (source.MergedInto == nil || *source.MergedInto != targetID) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": errSourceAlreadyMerged.Error()})
+		return
+	}
+
+	moved := bson.M{}
+	err = database.WithTransaction(r.Context(), func(ctx context.Context) error {
+		var err error
+		if moved["feedback"], err = h.feedbackRepo.Reassign(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+		if moved["consents"], err = h.consentRepo.Reassign(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+		if moved["admin_notes"], err = h.noteRepo.Reassign(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+
+		merged, err := h.userRepo.MarkMerged(ctx, sourceID, targetID)
+		if err != nil {
+			return err
+		}
+		if !merged {
+			return errSourceAlreadyMerged
+		}
+
+		return h.auditRepo.Record(ctx, &models.AuditEntry{
+			Action:   models.AuditUserMerged,
+			Actor:    middleware.GetAdminName(r.Context()),
+			TargetID: targetID.Hex(),
+			Details: bson.M{
+				"source_id":    sourceID.Hex(),
+				"source_email": source.Email,
+				"target_email": target.Email,
+				"moved":        moved,
+			},
+		})
+	})
+	if errors.Is(err, errSourceAlreadyMerged) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Error merging users: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "merge failed, retry to finish it"})
+		return
+	}
+
+	log.Printf("🔀 Merged user %s into %s", sourceID.Hex(), targetID.Hex())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":   "accounts merged",
+		"source_id": sourceID.Hex(),
+		"target_id": targetID.Hex(),
+		"moved":     moved,
+	})
+}
+
+func (h *UserMergeHandler) loadPair(w http.ResponseWriter, r *http.Request, sourceID, targetID bson.ObjectID) (*models.User, *models.User, bool) {
+	users := make([]*models.User, 2)
+	for i, id := range []bson.ObjectID{sourceID, targetID} {
+		user, err := h.userRepo.FindByID(r.Context(), id)
+		if err != nil {
+			log.Printf("Error finding user: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return nil, nil, false
+		}
+		if user == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "user " + id.Hex() + " not found"})
+			return nil, nil, false
+		}
+		users[i] = user
+	}
+	return users[0], users[1], true
+}
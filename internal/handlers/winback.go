@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/middleware"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/rollup"
+	"rizon-backend/internal/winback"
+)
+
+type WinbackHandler struct {
+	repo      *repository.WinbackRepo
+	audiences *repository.AudienceRepo
+	roller    *rollup.Roller
+}
+
+func NewWinbackHandler(repo *repository.WinbackRepo, audiences *repository.AudienceRepo, roller *rollup.Roller) *WinbackHandler {
+	return &WinbackHandler{
+		repo:      repo,
+		audiences: audiences,
+		roller:    roller,
+	}
+}
+
+// --- GET /admin/winback ---
+
+func (h *WinbackHandler) Get(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.repo.Settings(r.Context())
+	if err != nil {
+		log.Printf("Error loading winback settings: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+	if settings == nil {
+		settings = &models.WinbackSettings{InactiveDays: 30, CooldownDays: 90, ExcludeDomains: []string{}}
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// --- PUT /admin/winback ---
+// Replaces the settings. Once enabled, an hourly job emails users in the
+// audience who haven't been seen for inactive_days, skipping anyone who
+// turned product emails off, is suppressed, or was emailed within
+// cooldown_days.
+
+func (h *WinbackHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var settings models.WinbackSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := winback.Validate(&settings); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if settings.AudienceID != nil {
+		audience, err := h.audiences.FindByID(r.Context(), *settings.AudienceID)
+		if err != nil {
+			log.Printf("Error loading audience: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+			return
+		}
+		if audience == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "audience not found"})
+			return
+		}
+	}
+	settings.UpdatedAt = time.Now()
+	settings.UpdatedBy = middleware.GetAdminName(r.Context())
+
+	if err := h.repo.SaveSettings(r.Context(), &settings); err != nil {
+		log.Printf("Error saving winback settings: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save settings"})
+		return
+	}
+	log.Printf("📬 Winback settings updated by %s (enabled: %t)", settings.UpdatedBy, settings.Enabled)
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// --- GET /admin/analytics/winback?days=30 ---
+// Winback emails sent and users who came back within 30 days of one, over
+// whole UTC days. A reactivation counts on the day the user came back.
+
+func (h *WinbackHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > 365 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = n
+	}
+	now := time.Now().UTC()
+
+	totals, since, err := h.roller.Totals(r.Context(), models.MetricWinback, now.AddDate(0, 0, -days), now, []string{"sent", "reactivated"})
+	if err != nil {
+		log.Printf("Error computing winback stats: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return
+	}
+
+	var sent, reactivated int64
+	for _, t := range totals {
+		sent += t.Values["sent"]
+		reactivated += t.Values["reactivated"]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"since":             since,
+		"sent":              sent,
+		"reactivated":       reactivated,
+		"reactivation_rate": ratio(reactivated, sent),
+	})
+}
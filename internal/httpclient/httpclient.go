@@ -0,0 +1,245 @@
+// Package httpclient builds the clients used for calls to third parties
+// (email, Slack, issue trackers, identity providers, ...). They share one
+// connection pool, honour HTTPS_PROXY/HTTP_PROXY/NO_PROXY, always have a
+// timeout, can retry idempotent requests, and record per-destination
+// metrics served at /admin/outbound.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/resilience"
+)
+
+// DefaultTimeout bounds a whole request, including reading the body.
+const DefaultTimeout = 10 * time.Second
+
+// Options tune a client for one destination.
+type Options struct {
+	Timeout time.Duration // DefaultTimeout when zero
+	// Retries of GET and HEAD requests after a network error or a 502, 503
+	// or 504. Integrations that retry on their own leave it at zero.
+	Retries int
+}
+
+// transport is the connection pool shared by every client.
+var transport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   5 * time.Second,
+	ExpectContinueTimeout: time.Second,
+}
+
+// New returns a client for the named destination, e.g. "slack". Clients
+// with the same name share metrics.
+func New(name string, opts Options) *http.Client {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &roundTripper{
+			next:    transport,
+			dest:    destination(name),
+			retries: opts.Retries,
+		},
+	}
+}
+
+// retryPolicy spaces out retries; Options.Retries sets how many.
+var retryPolicy = resilience.RetryPolicy{BaseDelay: 200 * time.Millisecond, MaxDelay: 2 * time.Second}
+
+type roundTripper struct {
+	next    http.RoundTripper
+	dest    *dest
+	retries int
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+	for attempt := 0; ; attempt++ {
+		started := time.Now()
+		resp, err := t.next.RoundTrip(req)
+		t.dest.record(req.Context(), time.Since(started), resp, err)
+
+		if !idempotent || attempt >= t.retries || !retryable(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		t.dest.retried()
+
+		timer := time.NewTimer(resilience.Backoff(retryPolicy, attempt+1))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// --- Metrics ---
+
+// latencyBuckets are the upper bounds of the latency histogram; percentiles
+// are reported as the bound of the bucket they fall in.
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond,
+	250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2500 * time.Millisecond,
+	5 * time.Second, 10 * time.Second,
+}
+
+type dest struct {
+	name string
+
+	mu       sync.Mutex
+	requests int64
+	statuses map[string]int64 // "2xx", "4xx", ...
+	errors   int64            // no response: timeouts, DNS, refused connections
+	timeouts int64
+	retries  int64
+	total    time.Duration
+	max      time.Duration
+	buckets  []int64 // len(latencyBuckets)+1; the last counts everything slower
+}
+
+var (
+	destsMu sync.Mutex
+	dests   = map[string]*dest{}
+)
+
+func destination(name string) *dest {
+	destsMu.Lock()
+	defer destsMu.Unlock()
+	d := dests[name]
+	if d == nil {
+		d = &dest{name: name, statuses: map[string]int64{}, buckets: make([]int64, len(latencyBuckets)+1)}
+		dests[name] = d
+	}
+	return d
+}
+
+func (d *dest) record(ctx context.Context, elapsed time.Duration, resp *http.Response, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests++
+	d.total += elapsed
+	d.max = max(d.max, elapsed)
+	d.buckets[sort.Search(len(latencyBuckets), func(i int) bool { return elapsed <= latencyBuckets[i] })]++
+	switch {
+	case err != nil:
+		d.errors++
+		var netErr net.Error
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			d.timeouts++
+		}
+	default:
+		d.statuses[statusClass(resp.StatusCode)]++
+	}
+}
+
+func (d *dest) retried() {
+	d.mu.Lock()
+	d.retries++
+	d.mu.Unlock()
+}
+
+func statusClass(code int) string {
+	return fmt.Sprintf("%dxx", code/100)
+}
+
+func (d *dest) percentile(p float64) time.Duration {
+	if d.requests == 0 {
+		return 0
+	}
+	rank := int64(float64(d.requests)*p + 0.5)
+	var seen int64
+	for i, n := range d.buckets {
+		seen += n
+		if seen >= rank {
+			if i == len(latencyBuckets) {
+				return d.max
+			}
+			return latencyBuckets[i]
+		}
+	}
+	return d.max
+}
+
+// Stats describe the calls to one destination since startup. Latencies are
+// per attempt, in milliseconds.
+type Stats struct {
+	Destination string           `json:"destination"`
+	Requests    int64            `json:"requests"`
+	Statuses    map[string]int64 `json:"statuses"`
+	Errors      int64            `json:"errors"`
+	Timeouts    int64            `json:"timeouts"`
+	Retries     int64            `json:"retries"`
+	MeanMS      float64          `json:"mean_ms"`
+	P95MS       float64          `json:"p95_ms"`
+	MaxMS       float64          `json:"max_ms"`
+}
+
+// Destinations returns the stats of every destination, by name.
+func Destinations() []Stats {
+	destsMu.Lock()
+	all := make([]*dest, 0, len(dests))
+	for _, d := range dests {
+		all = append(all, d)
+	}
+	destsMu.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].name < all[j].name })
+
+	out := make([]Stats, 0, len(all))
+	for _, d := range all {
+		d.mu.Lock()
+		s := Stats{
+			Destination: d.name,
+			Requests:    d.requests,
+			Statuses:    map[string]int64{},
+			Errors:      d.errors,
+			Timeouts:    d.timeouts,
+			Retries:     d.retries,
+			P95MS:       ms(d.percentile(0.95)),
+			MaxMS:       ms(d.max),
+		}
+		for class, n := range d.statuses {
+			s.Statuses[class] = n
+		}
+		if d.requests > 0 {
+			s.MeanMS = ms(d.total / time.Duration(d.requests))
+		}
+		d.mu.Unlock()
+		out = append(out, s)
+	}
+	return out
+}
+
+func ms(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
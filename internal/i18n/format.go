@@ -0,0 +1,130 @@
+package i18n
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatKeys are the catalog entries the formatters read. They aren't
+// referenced from code by name, so extraction keeps them regardless.
+var FormatKeys = func() []string {
+	keys := []string{"number.decimal", "number.group", "currency.pattern", "date.short", "date.medium", "date.long"}
+	for m := 1; m <= 12; m++ {
+		keys = append(keys, "date.month."+strconv.Itoa(m), "date.mon."+strconv.Itoa(m))
+	}
+	return keys
+}()
+
+// Number formats v with the given number of decimals and the locale's
+// separators.
+func (l *Localizer) Number(v float64, decimals int) string {
+	neg := v < 0
+	digits := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	whole, frac, _ := strings.Cut(digits, ".")
+
+	group := l.T("number.group")
+	var b strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(c)
+	}
+	if frac != "" {
+		b.WriteString(l.T("number.decimal"))
+		b.WriteString(frac)
+	}
+	if neg && strings.Trim(digits, "0.") != "" {
+		return "-" + b.String()
+	}
+	return b.String()
+}
+
+type currencyInfo struct {
+	symbol   string
+	local    string // shorter symbol used in the currency's home region
+	region   string
+	decimals int
+}
+
+var currencies = map[string]currencyInfo{
+	"USD": {"US$", "$", "US", 2},
+	"CAD": {"CA$", "$", "CA", 2},
+	"AUD": {"A$", "$", "AU", 2},
+	"NZD": {"NZ$", "$", "NZ", 2},
+	"SGD": {"S$", "$", "SG", 2},
+	"MXN": {"MX$", "$", "MX", 2},
+	"BRL": {"R$", "R$", "BR", 2},
+	"EUR": {"€", "€", "", 2},
+	"GBP": {"£", "£", "", 2},
+	"JPY": {"¥", "¥", "", 0},
+	"KRW": {"₩", "₩", "", 0},
+	"INR": {"₹", "₹", "", 2},
+	"BDT": {"৳", "৳", "", 2},
+	"TRY": {"₺", "₺", "", 2},
+	"NGN": {"₦", "₦", "", 2},
+	"CHF": {"CHF", "CHF", "", 2},
+	"SEK": {"SEK", "kr", "SE", 2},
+	"PLN": {"PLN", "zł", "PL", 2},
+}
+
+// Money formats an amount in minor units (cents) of an ISO 4217 currency.
+// Dollar currencies show a bare "$" only in their home region, and
+// currencies without a known symbol show their code.
+func (l *Localizer) Money(minor int64, currency string) string {
+	currency = strings.ToUpper(currency)
+	info, ok := currencies[currency]
+	if !ok {
+		info = currencyInfo{symbol: currency, local: currency, decimals: 2}
+	}
+	symbol := info.symbol
+	if info.region == "" || info.region == l.region || (l.region == "" && info.region == "US" && l.lang == "en") {
+		symbol = info.local
+	}
+	amount := l.Number(float64(minor)/math.Pow10(info.decimals), info.decimals)
+	neg := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(amount, "-")
+	out := fill(l.T("currency.pattern"), []interface{}{"amount", amount, "symbol", symbol})
+	if neg {
+		return "-" + out
+	}
+	return out
+}
+
+// Date formats t in the "short", "medium" or "long" style of the locale.
+// It doesn't change time zones; convert t first.
+func (l *Localizer) Date(t time.Time, style string) string {
+	if style != "short" && style != "long" {
+		style = "medium"
+	}
+	month := strconv.Itoa(int(t.Month()))
+	return strings.NewReplacer(
+		"{yyyy}", strconv.Itoa(t.Year()),
+		"{MMMM}", l.T("date.month."+month),
+		"{MMM}", l.T("date.mon."+month),
+		"{MM}", pad2(int(t.Month())),
+		"{M}", month,
+		"{dd}", pad2(t.Day()),
+		"{d}", strconv.Itoa(t.Day()),
+	).Replace(l.T("date." + style))
+}
+
+func pad2(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// InZone converts t to an IANA time zone for display, falling back to UTC
+// when the zone is empty or unknown.
+func InZone(t time.Time, timeZone string) time.Time {
+	if timeZone != "" {
+		if zone, err := time.LoadLocation(timeZone); err == nil {
+			return t.In(zone)
+		}
+	}
+	return t.UTC()
+}
@@ -0,0 +1,179 @@
+// Package i18n localizes user-facing text: message catalogs with plural
+// forms, and number, currency and date formatting. Catalogs live in
+// locales/<tag>.json; a region catalog (en-GB) only overrides its language's
+// (en), and anything missing falls back to English.
+//
+// Templates get the formatting functions through Localizer.Funcs:
+//
+//	{{t "login_link.heading" "brand" .Brand.Name}}
+//	{{tn "login_link.expiry" 15}}
+//	{{money 129900 "EUR"}} {{date .Expires "long"}}
+//
+// Run `make i18n-extract` after adding messages and `make i18n-check` in CI.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when nothing better matches; its catalog must hold
+// every message.
+const DefaultLocale = "en"
+
+// Dir is where the catalogs live, relative to the repository root.
+const Dir = "internal/i18n/locales"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Message is one catalog entry: plain text, or one text per plural category
+// (zero, one, two, few, many, other). Placeholders are written {name}.
+type Message struct {
+	Text   string
+	Plural map[string]string
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.Text); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &m.Plural); err != nil {
+		return fmt.Errorf("message must be a string or an object of plural forms")
+	}
+	if _, ok := m.Plural["other"]; !ok {
+		return fmt.Errorf("plural message needs an \"other\" form")
+	}
+	return nil
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	if m.Plural != nil {
+		return json.Marshal(m.Plural)
+	}
+	return json.Marshal(m.Text)
+}
+
+// Texts returns every form of the message.
+func (m Message) Texts() []string {
+	if m.Plural == nil {
+		return []string{m.Text}
+	}
+	texts := make([]string, 0, len(m.Plural))
+	for _, t := range m.Plural {
+		texts = append(texts, t)
+	}
+	return texts
+}
+
+// Catalog maps message IDs to messages.
+type Catalog map[string]Message
+
+// ParseCatalog decodes a catalog file.
+func ParseCatalog(data []byte) (Catalog, error) {
+	catalog := Catalog{}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+var catalogs = map[string]Catalog{}
+
+func init() {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		data, err := localeFS.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog, err := ParseCatalog(data)
+		if err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", f.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(f.Name(), ".json")] = catalog
+	}
+	if _, ok := catalogs[DefaultLocale]; !ok {
+		panic("i18n: missing " + DefaultLocale + " catalog")
+	}
+}
+
+// Locales lists the locales with a catalog, sorted.
+func Locales() []string {
+	tags := make([]string, 0, len(catalogs))
+	for tag := range catalogs {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// canonical turns "pt_br" or "PT-br" into "pt-BR" and returns the language.
+func canonical(tag string) (string, string) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+	lang := strings.ToLower(parts[0])
+	if len(parts) > 1 && len(parts[1]) == 2 {
+		return lang + "-" + strings.ToUpper(parts[1]), lang
+	}
+	return lang, lang
+}
+
+// supported returns the catalog locale for tag: the region catalog if there
+// is one, else the language's, else "".
+func supported(tag string) string {
+	full, lang := canonical(tag)
+	if _, ok := catalogs[full]; ok {
+		return full
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return ""
+}
+
+// Match picks the best catalog locale for an Accept-Language header, then
+// for each fallback in turn (say, the user's saved locale), then
+// DefaultLocale.
+func Match(acceptLanguage string, fallbacks ...string) string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var prefs []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			prefs = append(prefs, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+
+	for _, p := range prefs {
+		if locale := supported(p.tag); locale != "" {
+			return locale
+		}
+	}
+	for _, tag := range fallbacks {
+		if locale := supported(tag); locale != "" {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
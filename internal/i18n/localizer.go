@@ -0,0 +1,124 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Localizer formats text for one locale.
+type Localizer struct {
+	locale string
+	lang   string
+	region string
+	chain  []Catalog // most specific first, ending with DefaultLocale
+}
+
+// For returns the localizer for the closest catalog to tag ("pt-BR",
+// "de", ...); unknown tags get DefaultLocale.
+func For(tag string) *Localizer {
+	locale := supported(tag)
+	if locale == "" {
+		locale = DefaultLocale
+	}
+	lang, region, _ := strings.Cut(locale, "-")
+	l := &Localizer{locale: locale, lang: lang, region: region}
+	seen := map[string]bool{}
+	for _, t := range []string{locale, lang, DefaultLocale} {
+		if c, ok := catalogs[t]; ok && !seen[t] {
+			seen[t] = true
+			l.chain = append(l.chain, c)
+		}
+	}
+	return l
+}
+
+// Locale is the catalog locale in use, e.g. "pt-BR" or "en".
+func (l *Localizer) Locale() string { return l.locale }
+
+// Language is the locale's language, e.g. "pt".
+func (l *Localizer) Language() string { return l.lang }
+
+func (l *Localizer) lookup(id string) (Message, bool) {
+	for _, c := range l.chain {
+		if m, ok := c[id]; ok {
+			return m, true
+		}
+	}
+	return Message{}, false
+}
+
+// T returns the message with its placeholders filled from name/value pairs.
+// A missing message comes back as its ID, so gaps show up in review rather
+// than as blank text.
+func (l *Localizer) T(id string, args ...interface{}) string {
+	m, ok := l.lookup(id)
+	if !ok {
+		return id
+	}
+	text := m.Text
+	if m.Plural != nil {
+		text = m.Plural["other"]
+	}
+	return fill(text, args)
+}
+
+// N returns the plural form of the message for count, with {count} and the
+// name/value pairs filled in.
+func (l *Localizer) N(id string, count int, args ...interface{}) string {
+	m, ok := l.lookup(id)
+	if !ok {
+		return id
+	}
+	text := m.Text
+	if m.Plural != nil {
+		var found bool
+		if text, found = m.Plural[PluralCategory(l.lang, count)]; !found {
+			text = m.Plural["other"]
+		}
+	}
+	return fill(text, append([]interface{}{"count", l.Number(float64(count), 0)}, args...))
+}
+
+func fill(text string, args []interface{}) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Funcs returns the template functions for this locale: t, tn, number,
+// money and date.
+func (l *Localizer) Funcs() map[string]interface{} {
+	return map[string]interface{}{
+		"t":      l.T,
+		"tn":     l.N,
+		"number": l.Number,
+		"money":  l.Money,
+		"date":   l.Date,
+	}
+}
+
+// Placeholders lists the {name} placeholders in text, for checking that a
+// translation uses the same ones as the English.
+func Placeholders(text string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			return names
+		}
+		name := text[start+1 : start+end]
+		if name != "" && !strings.ContainsAny(name, " {") {
+			names = append(names, name)
+		}
+		text = text[start+end+1:]
+	}
+}
@@ -0,0 +1,161 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported image format")
+	ErrTooLarge    = errors.New("image dimensions too large")
+	ErrCorrupt     = errors.New("image could not be decoded")
+)
+
+// Variant is a standard rendition, scaled to fit within MaxSize×MaxSize.
+// Images smaller than MaxSize aren't upscaled.
+type Variant struct {
+	Name    string
+	MaxSize int
+}
+
+// DefaultVariants are generated for every uploaded image.
+var DefaultVariants = []Variant{
+	{Name: "thumbnail", MaxSize: 256},
+	{Name: "full", MaxSize: 2048},
+}
+
+// MaxPixels rejects decompression bombs before the pixel data is decoded.
+const MaxPixels = 50_000_000
+
+// Output is one encoded variant.
+type Output struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Sniff reports whether data looks like a supported image, returning its
+// format ("jpeg" or "png") without decoding the pixels.
+func Sniff(data []byte) (string, bool) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return "", false
+	}
+	return format, true
+}
+
+// Process validates and decodes an uploaded image, applies its EXIF
+// orientation and re-encodes each variant. Re-encoding drops all metadata
+// (EXIF, GPS, comments) along with anything smuggled after the image data.
+// JPEGs stay JPEG; PNGs stay PNG to keep transparency.
+func Process(data []byte, variants []Variant) ([]Output, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	src := toRGBA(img)
+	if format == "jpeg" {
+		src = orient(src, exifOrientation(data))
+	}
+
+	outputs := make([]Output, 0, len(variants))
+	for _, v := range variants {
+		scaled := fit(src, v.MaxSize)
+		var buf bytes.Buffer
+		contentType := "image/jpeg"
+		if format == "png" {
+			contentType = "image/png"
+			err = png.Encode(&buf, scaled)
+		} else {
+			err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", v.Name, err)
+		}
+		b := scaled.Bounds()
+		outputs = append(outputs, Output{
+			Name:        v.Name,
+			ContentType: contentType,
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+			Data:        buf.Bytes(),
+		})
+	}
+	return outputs, nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// fit downscales src to fit within max×max by averaging the source pixels
+// that cover each destination pixel (a box filter), which is sharp enough
+// for thumbnails and avoids the aliasing of nearest-neighbour.
+func fit(src *image.RGBA, max int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= max && sh <= max {
+		return src
+	}
+	dw, dh := max, max
+	if sw > sh {
+		dh = sh * max / sw
+	} else {
+		dw = sw * max / sh
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, (y+1)*sh/dh
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, (x+1)*sw/dw
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			o := dst.Pix[y*dst.Stride+x*4:]
+			o[0], o[1], o[2], o[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
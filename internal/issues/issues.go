@@ -0,0 +1,49 @@
+package issues
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Issue is the tracker-agnostic content of a new issue.
+type Issue struct {
+	Title       string
+	Description string
+}
+
+// Created identifies an issue in the external tracker.
+type Created struct {
+	Key string // e.g. "ENG-123"
+	URL string
+}
+
+// Response is what the tracker answered, kept for the delivery log.
+type Response struct {
+	StatusCode int
+	Body       string // the first ResponseExcerptLimit bytes
+}
+
+// ResponseExcerptLimit caps the response body kept in a Response.
+const ResponseExcerptLimit = 1024
+
+// Tracker creates issues in an external issue tracker. Create returns the
+// tracker's response whenever one was received, even with an error.
+type Tracker interface {
+	Name() string
+	Create(ctx context.Context, issue Issue) (*Created, *Response, error)
+}
+
+// readResponse reads a tracker's response body, up to 1 MiB.
+func readResponse(resp *http.Response) (*Response, []byte, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	excerpt := body
+	if len(excerpt) > ResponseExcerptLimit {
+		excerpt = excerpt[:ResponseExcerptLimit]
+	}
+	return &Response{StatusCode: resp.StatusCode, Body: string(excerpt)}, body, err
+}
+
+// ErrUnknownProvider is returned when no tracker is configured under a name.
+var ErrUnknownProvider = errors.New("issue tracker not configured")
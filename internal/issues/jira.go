@@ -0,0 +1,78 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/httpclient"
+)
+
+// Jira creates issues through the Jira Cloud REST API (v2, which accepts
+// plain-text descriptions) with basic auth using an API token.
+type Jira struct {
+	baseURL    string
+	email      string
+	apiToken   string
+	projectKey string
+	issueType  string
+	http       *http.Client
+}
+
+func NewJira(baseURL, email, apiToken, projectKey, issueType string) *Jira {
+	return &Jira{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		email:      email,
+		apiToken:   apiToken,
+		projectKey: projectKey,
+		issueType:  issueType,
+		http:       httpclient.New("jira", httpclient.Options{}),
+	}
+}
+
+func (j *Jira) Name() string { return "jira" }
+
+func (j *Jira) Create(ctx context.Context, issue Issue) (*Created, *Response, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.projectKey},
+			"summary":     issue.Title,
+			"description": issue.Description,
+			"issuetype":   map[string]string{"name": j.issueType},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.baseURL+"/rest/api/2/issue", bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.SetBasicAuth(j.email, j.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.http.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("jira request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	answer, raw, err := readResponse(resp)
+	if err != nil {
+		return nil, answer, fmt.Errorf("reading jira response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, answer, fmt.Errorf("jira error (status %d): %s", resp.StatusCode, answer.Body)
+	}
+	var result struct {
+		Key string `json:"key"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, answer, fmt.Errorf("invalid jira response: %w", err)
+	}
+	return &Created{Key: result.Key, URL: j.baseURL + "/browse/" + result.Key}, answer, nil
+}
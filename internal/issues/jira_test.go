@@ -0,0 +1,56 @@
+package issues
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJiraCreateReportsResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantKey  string
+		wantErr  bool
+		wantBody string
+	}{
+		{"created", http.StatusCreated, `{"key":"ENG-7"}`, "ENG-7", false, `{"key":"ENG-7"}`},
+		{"rejected", http.StatusBadRequest, `{"errors":{"summary":"required"}}`, "", true, `{"errors":{"summary":"required"}}`},
+		{"long error", http.StatusBadGateway, strings.Repeat("x", 3000), "", true, strings.Repeat("x", ResponseExcerptLimit)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			jira := NewJira(srv.URL, "bot@example.com", "token", "ENG", "Bug")
+			created, resp, err := jira.Create(context.Background(), Issue{Title: "t", Description: "d"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error = %v", err, tt.wantErr)
+			}
+			if resp == nil || resp.StatusCode != tt.status || resp.Body != tt.wantBody {
+				t.Fatalf("response = %+v", resp)
+			}
+			if !tt.wantErr && created.Key != tt.wantKey {
+				t.Errorf("key = %q, want %q", created.Key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestJiraCreateUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	jira := NewJira(srv.URL, "bot@example.com", "token", "ENG", "Bug")
+	_, resp, err := jira.Create(context.Background(), Issue{Title: "t"})
+	if err == nil || resp != nil {
+		t.Fatalf("resp = %+v, err = %v; want no response and an error", resp, err)
+	}
+}
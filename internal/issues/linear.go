@@ -0,0 +1,89 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"rizon-backend/internal/httpclient"
+)
+
+const linearGraphQLURL = "https://api.linear.app/graphql"
+
+// Linear creates issues through Linear's GraphQL API using a personal API key.
+type Linear struct {
+	apiKey string
+	teamID string
+	http   *http.Client
+}
+
+func NewLinear(apiKey, teamID string) *Linear {
+	return &Linear{
+		apiKey: apiKey,
+		teamID: teamID,
+		http:   httpclient.New("linear", httpclient.Options{}),
+	}
+}
+
+func (l *Linear) Name() string { return "linear" }
+
+func (l *Linear) Create(ctx context.Context, issue Issue) (*Created, *Response, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query": `mutation IssueCreate($input: IssueCreateInput!) {
+			issueCreate(input: $input) { success issue { identifier url } }
+		}`,
+		"variables": map[string]interface{}{
+			"input": map[string]string{
+				"teamId":      l.teamID,
+				"title":       issue.Title,
+				"description": issue.Description,
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, linearGraphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Authorization", l.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := l.http.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("linear request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	answer, raw, err := readResponse(resp)
+	if err != nil {
+		return nil, answer, fmt.Errorf("reading linear response: %w", err)
+	}
+
+	var result struct {
+		Data struct {
+			IssueCreate struct {
+				Success bool `json:"success"`
+				Issue   struct {
+					Identifier string `json:"identifier"`
+					URL        string `json:"url"`
+				} `json:"issue"`
+			} `json:"issueCreate"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, answer, fmt.Errorf("invalid linear response (status %d): %w", resp.StatusCode, err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, answer, fmt.Errorf("linear error: %s", result.Errors[0].Message)
+	}
+	if !result.Data.IssueCreate.Success {
+		return nil, answer, fmt.Errorf("linear error: issue not created (status %d)", resp.StatusCode)
+	}
+	return &Created{Key: result.Data.IssueCreate.Issue.Identifier, URL: result.Data.IssueCreate.Issue.URL}, answer, nil
+}
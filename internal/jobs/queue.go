@@ -0,0 +1,499 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Handler processes one job. The returned result is stored on the job; an
+// error schedules a retry until MaxAttempts is reached.
+type Handler func(ctx context.Context, job *models.Job) (bson.M, error)
+
+// ErrPermanent marks failures that retrying can't fix.
+var ErrPermanent = errors.New("permanent failure")
+
+// deferral is returned by handlers that can't run yet, e.g. because of a
+// rate limit.
+type deferral struct {
+	until time.Time
+}
+
+func (d *deferral) Error() string {
+	return "deferred until " + d.until.Format(time.RFC3339)
+}
+
+// Defer returns an error that puts the job back in the queue until the given
+// time without using up an attempt.
+func Defer(until time.Time) error {
+	return &deferral{until: until}
+}
+
+// maxJobErrors is how many failed attempts a job remembers.
+const maxJobErrors = 20
+
+// ErrCanceled is the cause of a job context cancelled on an admin's request.
+var ErrCanceled = errors.New("job canceled")
+
+// Queue is a Mongo-backed job queue shared by all replicas. Workers claim jobs
+// with a lease, so a job whose worker dies is picked up again once it expires.
+type Queue struct {
+	collection    *mongo.Collection
+	lease         time.Duration
+	watchInterval time.Duration // lease renewal and cancel checks
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	timeouts  map[string]time.Duration
+	onFailure []func(job *models.Job, err error)
+}
+
+func NewQueue() *Queue {
+	return &Queue{
+		collection:    database.GetCollection("jobs"),
+		lease:         5 * time.Minute,
+		watchInterval: 5 * time.Second,
+		handlers:      map[string]Handler{},
+		timeouts:      map[string]time.Duration{},
+	}
+}
+
+// Register sets the handler for a job type. Must be called before Run.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	q.handlers[jobType] = handler
+	q.mu.Unlock()
+}
+
+// RegisterLongRunning sets the handler for a job type allowed to run for up
+// to timeout instead of one lease. Must be called before Run.
+func (q *Queue) RegisterLongRunning(jobType string, handler Handler, timeout time.Duration) {
+	q.mu.Lock()
+	q.handlers[jobType] = handler
+	q.timeouts[jobType] = timeout
+	q.mu.Unlock()
+}
+
+// OnFailure registers fn to be called when a job fails for good, after its
+// last attempt or a permanent error. Must be called before Run.
+func (q *Queue) OnFailure(fn func(job *models.Job, err error)) {
+	q.mu.Lock()
+	q.onFailure = append(q.onFailure, fn)
+	q.mu.Unlock()
+}
+
+// Enqueue stores a new job to run as soon as a worker is free.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}, createdBy string) (*models.Job, error) {
+	raw, err := bson.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+	now := time.Now()
+	job := &models.Job{
+		Type:        jobType,
+		Status:      models.JobStatusPending,
+		Payload:     raw,
+		MaxAttempts: 3,
+		CreatedBy:   createdBy,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	result, err := q.collection.InsertOne(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	job.ID = result.InsertedID.(bson.ObjectID)
+	return job, nil
+}
+
+// Cancel stops a job. Pending jobs, and running ones whose worker died, are
+// canceled at once; a live running job is asked to stop and ends as canceled
+// when its handler returns. Finished jobs are returned unchanged, and nil
+// means the job doesn't exist.
+func (q *Queue) Cancel(ctx context.Context, id bson.ObjectID) (*models.Job, error) {
+	now := time.Now()
+	after := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var job models.Job
+	err := q.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "$or": bson.A{
+			bson.M{"status": models.JobStatusPending},
+			bson.M{"status": models.JobStatusRunning, "locked_until": bson.M{"$lte": now}},
+		}},
+		bson.M{
+			"$set":   bson.M{"status": models.JobStatusCanceled, "cancel_requested": true, "finished_at": now, "updated_at": now},
+			"$unset": bson.M{"locked_until": ""},
+		},
+		after,
+	).Decode(&job)
+	if err == nil {
+		return &job, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	err = q.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": models.JobStatusRunning},
+		bson.M{"$set": bson.M{"cancel_requested": true, "updated_at": now}},
+		after,
+	).Decode(&job)
+	if err == nil {
+		return &job, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	return q.FindByID(ctx, id)
+}
+
+// Retry puts a failed or canceled job back in the queue with a fresh set
+// of attempts. Its error history is kept. Other jobs are returned
+// unchanged with retried false, and nil means the job doesn't exist.
+func (q *Queue) Retry(ctx context.Context, id bson.ObjectID, retriedBy string) (job *models.Job, retried bool, err error) {
+	now := time.Now()
+	var updated models.Job
+	err = q.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": bson.A{models.JobStatusFailed, models.JobStatusCanceled}}},
+		bson.M{
+			"$set":   bson.M{"status": models.JobStatusPending, "attempts": 0, "run_at": now, "updated_at": now, "retried_by": retriedBy},
+			"$unset": bson.M{"finished_at": "", "cancel_requested": "", "locked_until": "", "progress": ""},
+			"$inc":   bson.M{"retries": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == nil {
+		return &updated, true, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, false, err
+	}
+	job, err = q.FindByID(ctx, id)
+	return job, false, err
+}
+
+// JobFilter narrows List. Zero fields match everything.
+type JobFilter struct {
+	Status string
+	Type   string
+	Before bson.ObjectID // continue after the last job of the previous page
+}
+
+// List returns jobs newest first.
+func (q *Queue) List(ctx context.Context, filter JobFilter, limit int64) ([]models.Job, error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if !filter.Before.IsZero() {
+		query["_id"] = bson.M{"$lt": filter.Before}
+	}
+	cursor, err := q.collection.Find(ctx, query, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"payload": 0, "result": 0, "errors": 0}))
+	if err != nil {
+		return nil, err
+	}
+	jobs := []models.Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CountByStatus returns how many jobs are in each status.
+func (q *Queue) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	cursor, err := q.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Status string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (q *Queue) FindByID(ctx context.Context, id bson.ObjectID) (*models.Job, error) {
+	var job models.Job
+	err := q.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Run polls for due jobs every interval until ctx is cancelled, processing
+// one at a time.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Drain everything that's due before sleeping again
+		for ctx.Err() == nil {
+			job, err := q.claim(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Error claiming job: %v", err)
+				}
+				break
+			}
+			if job == nil {
+				break
+			}
+			q.process(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim leases the oldest due job of a registered type, including running
+// jobs whose lease expired.
+func (q *Queue) claim(ctx context.Context) (*models.Job, error) {
+	q.mu.RLock()
+	types := make([]string, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	q.mu.RUnlock()
+
+	now := time.Now()
+	var job models.Job
+	err := q.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"type":             bson.M{"$in": types},
+			"cancel_requested": bson.M{"$ne": true},
+			"$or": bson.A{
+				bson.M{"status": models.JobStatusPending, "run_at": bson.M{"$lte": now}},
+				bson.M{"status": models.JobStatusRunning, "locked_until": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"status": models.JobStatusRunning, "locked_until": now.Add(q.lease), "updated_at": now},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "run_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (q *Queue) process(ctx context.Context, job *models.Job) {
+	q.mu.RLock()
+	handler := q.handlers[job.Type]
+	timeout, ok := q.timeouts[job.Type]
+	if !ok {
+		timeout = q.lease
+	}
+	q.mu.RUnlock()
+
+	cancelCtx, cancel := context.WithCancelCause(ctx)
+	jobCtx, cancelTimeout := context.WithTimeout(cancelCtx, timeout)
+	jobCtx = context.WithValue(jobCtx, progressKey{}, &progressReporter{queue: q, jobID: job.ID})
+	stopWatch := q.watch(jobCtx, job.ID, cancel)
+	result, err := safeRun(jobCtx, handler, job)
+	canceled := errors.Is(context.Cause(jobCtx), ErrCanceled)
+	stopWatch()
+	cancelTimeout()
+	cancel(nil)
+
+	now := time.Now()
+	update := bson.M{"updated_at": now}
+	unset := bson.M{"locked_until": ""}
+	change := bson.M{"$set": update, "$unset": unset}
+	var deferred *deferral
+	switch {
+	case canceled:
+		log.Printf("🛑 Job %s (%s) canceled", job.ID.Hex(), job.Type)
+		update["status"] = models.JobStatusCanceled
+		update["error"] = ErrCanceled.Error()
+		update["finished_at"] = now
+		if result != nil {
+			update["result"] = result
+		}
+	case errors.As(err, &deferred):
+		update["status"] = models.JobStatusPending
+		update["run_at"] = deferred.until
+		change["$inc"] = bson.M{"attempts": -1}
+	case err == nil:
+		update["status"] = models.JobStatusSucceeded
+		update["result"] = result
+		update["finished_at"] = now
+		unset["error"] = ""
+	case errors.Is(err, ErrPermanent) || job.Attempts >= job.MaxAttempts:
+		log.Printf("❌ Job %s (%s) failed: %v", job.ID.Hex(), job.Type, err)
+		change["$push"] = errorHistory(job, err, now)
+		update["status"] = models.JobStatusFailed
+		update["error"] = err.Error()
+		update["finished_at"] = now
+		if result != nil {
+			update["result"] = result
+		}
+	default:
+		// Exponential backoff: 30s, 60s, 120s...
+		backoff := 30 * time.Second << (job.Attempts - 1)
+		log.Printf("⚠️  Job %s (%s) attempt %d failed, retrying in %s: %v", job.ID.Hex(), job.Type, job.Attempts, backoff, err)
+		change["$push"] = errorHistory(job, err, now)
+		update["status"] = models.JobStatusPending
+		update["error"] = err.Error()
+		update["run_at"] = now.Add(backoff)
+	}
+
+	// Use a fresh context so results are saved even during shutdown
+	saveCtx, cancelSave := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelSave()
+	if _, err := q.collection.UpdateOne(saveCtx, bson.M{"_id": job.ID}, change); err != nil {
+		log.Printf("Error saving job %s: %v", job.ID.Hex(), err)
+	}
+
+	if update["status"] == models.JobStatusFailed {
+		q.mu.RLock()
+		hooks := q.onFailure
+		q.mu.RUnlock()
+		for _, fn := range hooks {
+			fn(job, err)
+		}
+	}
+}
+
+// errorHistory is the $push that records a failed attempt.
+func errorHistory(job *models.Job, err error, at time.Time) bson.M {
+	return bson.M{"errors": bson.M{
+		"$each":  bson.A{models.JobError{Attempt: job.Attempts, Error: err.Error(), At: at}},
+		"$slice": -maxJobErrors,
+	}}
+}
+
+// watch renews the lease of a running job, so handlers may outlive it, and
+// cancels the job when an admin asks to. The returned func stops watching.
+func (q *Queue) watch(ctx context.Context, id bson.ObjectID, cancel context.CancelCauseFunc) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(q.watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			var job models.Job
+			err := q.collection.FindOneAndUpdate(ctx,
+				bson.M{"_id": id, "status": models.JobStatusRunning},
+				bson.M{"$set": bson.M{"locked_until": time.Now().Add(q.lease)}},
+				options.FindOneAndUpdate().
+					SetProjection(bson.M{"cancel_requested": 1}).
+					SetReturnDocument(options.After),
+			).Decode(&job)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Error renewing lease of job %s: %v", id.Hex(), err)
+				}
+				continue
+			}
+			if job.CancelRequested {
+				cancel(ErrCanceled)
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+type progressKey struct{}
+
+type progressReporter struct {
+	queue *Queue
+	jobID bson.ObjectID
+
+	mu        sync.Mutex
+	lastWrite time.Time
+}
+
+// ReportProgress records how far the running job has got; total is 0 when
+// unknown. Writes are throttled to one a second, so handlers can call it for
+// every item. Outside a job it does nothing.
+func ReportProgress(ctx context.Context, done, total int64, message string) {
+	p, _ := ctx.Value(progressKey{}).(*progressReporter)
+	if p == nil {
+		return
+	}
+	now := time.Now()
+	p.mu.Lock()
+	if now.Sub(p.lastWrite) < time.Second && (total == 0 || done < total) {
+		p.mu.Unlock()
+		return
+	}
+	p.lastWrite = now
+	p.mu.Unlock()
+
+	progress := models.JobProgress{Done: done, Total: total, Message: message, UpdatedAt: now}
+	if total > 0 {
+		progress.Percent = int(min(done*100/total, 100))
+	}
+	if _, err := p.queue.collection.UpdateOne(ctx, bson.M{"_id": p.jobID}, bson.M{"$set": bson.M{"progress": progress}}); err != nil && ctx.Err() == nil {
+		log.Printf("Error saving progress of job %s: %v", p.jobID.Hex(), err)
+	}
+}
+
+// safeRun keeps a panicking handler from taking the worker down.
+func safeRun(ctx context.Context, handler Handler, job *models.Job) (result bson.M, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// EnsureIndexes creates necessary indexes for the jobs collection
+func (q *Queue) EnsureIndexes(ctx context.Context) error {
+	_, err := q.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "run_at", Value: 1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
@@ -0,0 +1,524 @@
+// Package jsonschema validates free-form JSON documents against the subset
+// of JSON Schema (draft 2020-12) that admin-managed schemas need: types,
+// object properties, arrays, enums, numeric and length bounds, patterns,
+// common formats and the allOf/anyOf/oneOf/not combinators. Keywords outside
+// the subset ($ref, if/then/else...) are rejected at compile time rather than
+// silently ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation is one way a document fails its schema. Path is a JSON Pointer
+// to the offending value ("" is the document itself).
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Schema is a compiled schema.
+type Schema struct {
+	always *bool // boolean schema: true accepts anything, false nothing
+
+	types      []string
+	properties map[string]*Schema
+	required   []string
+	// nil allows any extra property
+	additional *Schema
+	items      *Schema
+
+	enum     []interface{}
+	constVal interface{}
+	hasConst bool
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	minLength, maxLength         *int
+	minItems, maxItems           *int
+	minProperties, maxProperties *int
+	uniqueItems                  bool
+
+	pattern *regexp.Regexp
+	format  string
+
+	allOf, anyOf, oneOf []*Schema
+	not                 *Schema
+}
+
+// annotations carry no validation meaning and are accepted as-is.
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"default": true, "examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+var formats = map[string]func(string) bool{
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	},
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+}
+
+// Compile parses a schema document.
+func Compile(raw []byte) (*Schema, error) {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+	return compile(doc, "")
+}
+
+func compile(doc interface{}, at string) (*Schema, error) {
+	if b, ok := doc.(bool); ok {
+		return &Schema{always: &b}, nil
+	}
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: a schema must be an object or a boolean", where(at))
+	}
+
+	s := &Schema{}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := m[k]
+		path := at + "/" + k
+		var err error
+		switch k {
+		case "type":
+			s.types, err = stringList(v, path)
+			for _, t := range s.types {
+				if !isType(t) {
+					return nil, fmt.Errorf("%s: unknown type %q", path, t)
+				}
+			}
+		case "properties":
+			props, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s: must be an object", path)
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, sub := range props {
+				if s.properties[name], err = compile(sub, path+"/"+escape(name)); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			s.required, err = stringList(v, path)
+		case "additionalProperties":
+			s.additional, err = compile(v, path)
+		case "items":
+			s.items, err = compile(v, path)
+		case "enum":
+			list, ok := v.([]interface{})
+			if !ok || len(list) == 0 {
+				return nil, fmt.Errorf("%s: must be a non-empty array", path)
+			}
+			s.enum = list
+		case "const":
+			s.constVal, s.hasConst = v, true
+		case "minimum":
+			s.minimum, err = number(v, path)
+		case "maximum":
+			s.maximum, err = number(v, path)
+		case "exclusiveMinimum":
+			s.exclusiveMinimum, err = number(v, path)
+		case "exclusiveMaximum":
+			s.exclusiveMaximum, err = number(v, path)
+		case "multipleOf":
+			if s.multipleOf, err = number(v, path); err == nil && *s.multipleOf <= 0 {
+				err = fmt.Errorf("%s: must be greater than 0", path)
+			}
+		case "minLength":
+			s.minLength, err = count(v, path)
+		case "maxLength":
+			s.maxLength, err = count(v, path)
+		case "minItems":
+			s.minItems, err = count(v, path)
+		case "maxItems":
+			s.maxItems, err = count(v, path)
+		case "minProperties":
+			s.minProperties, err = count(v, path)
+		case "maxProperties":
+			s.maxProperties, err = count(v, path)
+		case "uniqueItems":
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a boolean", path)
+			}
+			s.uniqueItems = b
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a string", path)
+			}
+			if s.pattern, err = regexp.Compile(p); err != nil {
+				err = fmt.Errorf("%s: %w", path, err)
+			}
+		case "format":
+			f, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: must be a string", path)
+			}
+			if formats[f] == nil {
+				return nil, fmt.Errorf("%s: unsupported format %q", path, f)
+			}
+			s.format = f
+		case "allOf":
+			s.allOf, err = schemaList(v, path)
+		case "anyOf":
+			s.anyOf, err = schemaList(v, path)
+		case "oneOf":
+			s.oneOf, err = schemaList(v, path)
+		case "not":
+			s.not, err = compile(v, path)
+		default:
+			if !annotations[k] {
+				return nil, fmt.Errorf("%s: unsupported keyword", path)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Validate returns every violation in doc, which must be a value decoded by
+// encoding/json (maps, slices, strings, float64 or json.Number, bools, nil).
+func (s *Schema) Validate(doc interface{}) []Violation {
+	var out []Violation
+	s.validate(doc, "", &out)
+	return out
+}
+
+func (s *Schema) validate(v interface{}, path string, out *[]Violation) {
+	fail := func(format string, args ...interface{}) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.always != nil {
+		if !*s.always {
+			fail("no value is allowed here")
+		}
+		return
+	}
+
+	if len(s.types) > 0 && !s.matchesType(v) {
+		fail("must be %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+		return
+	}
+	if s.enum != nil && !contains(s.enum, v) {
+		fail("must be one of %s", render(s.enum))
+	}
+	if s.hasConst && !equal(s.constVal, v) {
+		fail("must be %s", render(s.constVal))
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		s.validateObject(val, path, out, fail)
+	case []interface{}:
+		s.validateArray(val, path, out, fail)
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.minLength != nil && n < *s.minLength {
+			fail("must be at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("must be at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			fail("must match %s", s.pattern)
+		}
+		if s.format != "" && !formats[s.format](val) {
+			fail("must be a valid %s", s.format)
+		}
+	default:
+		if f, ok := toFloat(v); ok {
+			s.validateNumber(f, fail)
+		}
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(v, path, out)
+	}
+	if s.anyOf != nil {
+		matched := false
+		for _, sub := range s.anyOf {
+			if len(sub.Validate(v)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one of the allowed schemas")
+		}
+	}
+	if s.oneOf != nil {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if len(sub.Validate(v)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			fail("must match exactly one of the allowed schemas (matched %d)", matched)
+		}
+	}
+	if s.not != nil && len(s.not.Validate(v)) == 0 {
+		fail("must not match the excluded schema")
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path string, out *[]Violation, fail func(string, ...interface{})) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			*out = append(*out, Violation{Path: path + "/" + escape(name), Message: "is required"})
+		}
+	}
+	if s.minProperties != nil && len(obj) < *s.minProperties {
+		fail("must have at least %d properties", *s.minProperties)
+	}
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		fail("must have at most %d properties", *s.maxProperties)
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := path + "/" + escape(name)
+		if sub, ok := s.properties[name]; ok {
+			sub.validate(obj[name], child, out)
+		} else if s.additional != nil {
+			if s.additional.always != nil && !*s.additional.always {
+				*out = append(*out, Violation{Path: child, Message: "is not an allowed property"})
+			} else {
+				s.additional.validate(obj[name], child, out)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateArray(list []interface{}, path string, out *[]Violation, fail func(string, ...interface{})) {
+	if s.minItems != nil && len(list) < *s.minItems {
+		fail("must have at least %d items", *s.minItems)
+	}
+	if s.maxItems != nil && len(list) > *s.maxItems {
+		fail("must have at most %d items", *s.maxItems)
+	}
+	if s.uniqueItems {
+		for i := range list {
+			for j := i + 1; j < len(list); j++ {
+				if equal(list[i], list[j]) {
+					fail("items %d and %d are duplicates", i, j)
+				}
+			}
+		}
+	}
+	if s.items != nil {
+		for i, item := range list {
+			s.items.validate(item, path+"/"+strconv.Itoa(i), out)
+		}
+	}
+}
+
+func (s *Schema) validateNumber(f float64, fail func(string, ...interface{})) {
+	if s.minimum != nil && f < *s.minimum {
+		fail("must be at least %v", *s.minimum)
+	}
+	if s.maximum != nil && f > *s.maximum {
+		fail("must be at most %v", *s.maximum)
+	}
+	if s.exclusiveMinimum != nil && f <= *s.exclusiveMinimum {
+		fail("must be greater than %v", *s.exclusiveMinimum)
+	}
+	if s.exclusiveMaximum != nil && f >= *s.exclusiveMaximum {
+		fail("must be less than %v", *s.exclusiveMaximum)
+	}
+	if s.multipleOf != nil {
+		if q := f / *s.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", *s.multipleOf)
+		}
+	}
+}
+
+func (s *Schema) matchesType(v interface{}) bool {
+	actual := typeOf(v)
+	for _, t := range s.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func isType(t string) bool {
+	switch t {
+	case "null", "boolean", "object", "array", "number", "integer", "string":
+		return true
+	}
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	default:
+		if f, ok := toFloat(val); ok {
+			if f == math.Trunc(f) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equal compares JSON values, treating numbers by value.
+func equal(a, b interface{}) bool {
+	fa, aNum := toFloat(a)
+	fb, bNum := toFloat(b)
+	if aNum || bNum {
+		return aNum && bNum && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func contains(list []interface{}, v interface{}) bool {
+	for _, item := range list {
+		if equal(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func render(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// escape encodes a property name as a JSON Pointer token.
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func where(path string) string {
+	if path == "" {
+		return "schema"
+	}
+	return path
+}
+
+func stringList(v interface{}, path string) ([]string, error) {
+	if s, ok := v.(string); ok {
+		return []string{s}, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a string or an array of strings", path)
+	}
+	out := make([]string, len(list))
+	for i, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be an array of strings", path)
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func schemaList(v interface{}, path string) ([]*Schema, error) {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("%s: must be a non-empty array of schemas", path)
+	}
+	out := make([]*Schema, len(list))
+	for i, item := range list {
+		sub, err := compile(item, path+"/"+strconv.Itoa(i))
+		if err != nil {
+			return nil, err
+		}
+		out[i] = sub
+	}
+	return out, nil
+}
+
+func number(v interface{}, path string) (*float64, error) {
+	f, ok := toFloat(v)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a number", path)
+	}
+	return &f, nil
+}
+
+func count(v interface{}, path string) (*int, error) {
+	f, ok := toFloat(v)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("%s: must be a non-negative integer", path)
+	}
+	n := int(f)
+	return &n, nil
+}
@@ -0,0 +1,133 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func mustCompile(t *testing.T, raw string) *Schema {
+	t.Helper()
+	s, err := Compile([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func decode(t *testing.T, raw string) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestCompileRejects(t *testing.T) {
+	for _, raw := range []string{
+		`not json`,
+		`"string"`,
+		`{"$ref": "#/defs/x"}`,
+		`{"if": true}`,
+		`{"type": "float"}`,
+		`{"enum": []}`,
+		`{"multipleOf": 0}`,
+		`{"minLength": -1}`,
+		`{"maxItems": 1.5}`,
+		`{"pattern": "("}`,
+		`{"format": "ipv4"}`,
+		`{"properties": {"a": {"type": 1}}}`,
+		`{"anyOf": []}`,
+		`{"not": "x"}`,
+	} {
+		if _, err := Compile([]byte(raw)); err == nil {
+			t.Errorf("Compile(%s) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := mustCompile(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Onboarding answers",
+		"type": "object",
+		"required": ["role", "team_size"],
+		"additionalProperties": false,
+		"properties": {
+			"role": {"enum": ["engineer", "designer", "other"]},
+			"team_size": {"type": "integer", "minimum": 1, "maximum": 500},
+			"email": {"type": "string", "format": "email"},
+			"nickname": {"type": "string", "minLength": 2, "maxLength": 4, "pattern": "^[a-z]+$"},
+			"interests": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+			"score": {"type": "number", "exclusiveMaximum": 1, "multipleOf": 0.25},
+			"contact": {"oneOf": [{"type": "string"}, {"type": "null"}]},
+			"plan": {"not": {"const": "legacy"}}
+		}
+	}`)
+
+	tests := []struct {
+		name string
+		doc  string
+		want []Violation
+	}{
+		{"valid", `{"role": "engineer", "team_size": 12, "email": "a@example.com", "nickname": "jo", "interests": ["ai"], "score": 0.75, "contact": null, "plan": "pro"}`, nil},
+		{"missing required", `{"role": "engineer"}`, []Violation{{"/team_size", "is required"}}},
+		{"extra property", `{"role": "other", "team_size": 1, "shoe_size": 9}`, []Violation{{"/shoe_size", "is not an allowed property"}}},
+		{"wrong type", `{"role": "other", "team_size": "ten"}`, []Violation{{"/team_size", "must be integer, got string"}}},
+		{"not an integer", `{"role": "other", "team_size": 1.5}`, []Violation{{"/team_size", "must be integer, got number"}}},
+		{"out of range", `{"role": "other", "team_size": 501}`, []Violation{{"/team_size", "must be at most 500"}}},
+		{"not in enum", `{"role": "ceo", "team_size": 1}`, []Violation{{"/role", `must be one of ["engineer","designer","other"]`}}},
+		{"bad format", `{"role": "other", "team_size": 1, "email": "Jo <a@example.com>"}`, []Violation{{"/email", "must be a valid email"}}},
+		{"string bounds", `{"role": "other", "team_size": 1, "nickname": "Jordan"}`, []Violation{
+			{"/nickname", "must be at most 4 characters"},
+			{"/nickname", "must match ^[a-z]+$"},
+		}},
+		{"array rules", `{"role": "other", "team_size": 1, "interests": ["ai", "ai", 3]}`, []Violation{
+			{"/interests", "must have at most 2 items"},
+			{"/interests", "items 0 and 1 are duplicates"},
+			{"/interests/2", "must be string, got integer"},
+		}},
+		{"number rules", `{"role": "other", "team_size": 1, "score": 1.1}`, []Violation{
+			{"/score", "must be less than 1"},
+			{"/score", "must be a multiple of 0.25"},
+		}},
+		{"oneOf", `{"role": "other", "team_size": 1, "contact": 5}`, []Violation{{"/contact", "must match exactly one of the allowed schemas (matched 0)"}}},
+		{"not", `{"role": "other", "team_size": 1, "plan": "legacy"}`, []Violation{{"/plan", "must not match the excluded schema"}}},
+		{"not an object", `[]`, []Violation{{"", "must be object, got array"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schema.Validate(decode(t, tt.doc)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBooleanSchemas(t *testing.T) {
+	if v := mustCompile(t, `true`).Validate(decode(t, `{"anything": 1}`)); len(v) != 0 {
+		t.Errorf("true schema: %+v", v)
+	}
+	if v := mustCompile(t, `false`).Validate(nil); len(v) != 1 {
+		t.Errorf("false schema: %+v, want one violation", v)
+	}
+}
+
+func TestNumbersCompareByValue(t *testing.T) {
+	schema := mustCompile(t, `{"enum": [1, 2], "const": 2}`)
+	if v := schema.Validate(json.Number("2.0")); len(v) != 0 {
+		t.Errorf("json.Number 2.0: %+v", v)
+	}
+	if v := schema.Validate(int64(2)); len(v) != 0 {
+		t.Errorf("int64 2: %+v", v)
+	}
+}
+
+func TestPointerEscaping(t *testing.T) {
+	schema := mustCompile(t, `{"required": ["a/b", "c~d"]}`)
+	want := []Violation{{"/a~1b", "is required"}, {"/c~0d", "is required"}}
+	if got := schema.Validate(map[string]interface{}{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate = %+v, want %+v", got, want)
+	}
+}
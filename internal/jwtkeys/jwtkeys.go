@@ -0,0 +1,132 @@
+// Package jwtkeys holds the secrets session JWTs are signed and verified
+// with, so JWT_SECRET can be rotated without logging everyone out:
+//
+//  1. Mint a new secret and deploy it as an accepted secret. Every replica
+//     now verifies tokens signed with it, but still issues with the old one.
+//  2. Flip: deploy the new secret as JWT_SECRET and the old one as accepted.
+//     New tokens use the new secret; existing ones keep working.
+//  3. Once the old key's validations drop to zero (or the longest session
+//     lifetime has passed), remove it. Tokens still signed with it stop
+//     working.
+//
+// cmd/jwtsecret prints the configuration for each step. Tokens name their
+// key in the "kid" header; the ID is derived from the secret, never the
+// secret itself.
+package jwtkeys
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownKey is returned for tokens naming a key that isn't configured.
+var ErrUnknownKey = errors.New("token signed with an unknown key")
+
+// Key is one configured secret.
+type Key struct {
+	ID        string
+	secret    []byte
+	validated atomic.Int64
+}
+
+// KeyStats is one key's use on this instance since startup.
+type KeyStats struct {
+	ID        string `json:"id"`
+	Issuing   bool   `json:"issuing"`
+	Validated int64  `json:"validated"`
+}
+
+// Keyring signs with one secret and verifies with any configured secret.
+type Keyring struct {
+	issuing *Key
+	keys    []*Key // issuing first
+	byID    map[string]*Key
+}
+
+// New creates a keyring issuing with secret and also accepting the accepted
+// secrets. Duplicates and empty entries are ignored.
+func New(secret string, accepted []string) (*Keyring, error) {
+	if secret == "" {
+		return nil, errors.New("an issuing secret is required")
+	}
+	k := &Keyring{byID: map[string]*Key{}}
+	for _, s := range append([]string{secret}, accepted...) {
+		if s == "" {
+			continue
+		}
+		key := &Key{ID: KeyID(s), secret: []byte(s)}
+		if _, dup := k.byID[key.ID]; dup {
+			continue
+		}
+		k.byID[key.ID] = key
+		k.keys = append(k.keys, key)
+	}
+	k.issuing = k.keys[0]
+	return k, nil
+}
+
+// KeyID derives the public ID of a secret.
+func KeyID(secret string) string {
+	sum := sha256.Sum256([]byte("jwtkeys:" + secret))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// IssuingID is the ID of the key new tokens are signed with.
+func (k *Keyring) IssuingID() string {
+	return k.issuing.ID
+}
+
+// Sign signs claims with the issuing secret.
+func (k *Keyring) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = k.issuing.ID
+	return token.SignedString(k.issuing.secret)
+}
+
+// Parse verifies tokenString and counts the key that verified it. Tokens
+// without a "kid" header predate rotation support and are tried against
+// every key. As with jwt.Parse, an expired but correctly signed token is
+// returned along with jwt.ErrTokenExpired.
+func (k *Keyring) Parse(tokenString string) (*jwt.Token, error) {
+	candidates := k.keys
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+	if kid, ok := unverified.Header["kid"].(string); ok {
+		key, found := k.byID[kid]
+		if !found {
+			return nil, ErrUnknownKey
+		}
+		candidates = []*Key{key}
+	}
+
+	var token *jwt.Token
+	for _, key := range candidates {
+		token, err = jwt.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return key.secret, nil
+		}, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			continue
+		}
+		if err == nil {
+			key.validated.Add(1)
+		}
+		return token, err
+	}
+	return token, fmt.Errorf("verifying token: %w", err)
+}
+
+// Stats returns every key's validation count, issuing key first.
+func (k *Keyring) Stats() []KeyStats {
+	stats := make([]KeyStats, len(k.keys))
+	for i, key := range k.keys {
+		stats[i] = KeyStats{ID: key.ID, Issuing: key == k.issuing, Validated: key.validated.Load()}
+	}
+	return stats
+}
@@ -0,0 +1,101 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Drainer coordinates zero-downtime shutdown: it tracks in-flight requests and
+// long-lived streams (SSE/WebSocket), flips readiness to failing once draining
+// starts, and lets callers wait for traffic to settle.
+type Drainer struct {
+	inflight atomic.Int64
+	streams  atomic.Int64
+
+	drainOnce sync.Once
+	draining  chan struct{}
+
+	shutdownOnce sync.Once
+	shutdown     chan struct{}
+}
+
+func NewDrainer() *Drainer {
+	return &Drainer{
+		draining: make(chan struct{}),
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Middleware counts in-flight requests.
+func (d *Drainer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.inflight.Add(1)
+		defer d.inflight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TrackStream registers a long-lived connection. Stream handlers should call
+// the returned func when they finish and close the stream once Draining() fires.
+func (d *Drainer) TrackStream() func() {
+	d.streams.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { d.streams.Add(-1) })
+	}
+}
+
+// StartDrain marks the instance as draining. Safe to call more than once.
+func (d *Drainer) StartDrain() {
+	d.drainOnce.Do(func() { close(d.draining) })
+}
+
+// Draining is closed once draining has started.
+func (d *Drainer) Draining() <-chan struct{} {
+	return d.draining
+}
+
+func (d *Drainer) IsDraining() bool {
+	select {
+	case <-d.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// RequestShutdown asks the server process to exit once the current request completes.
+func (d *Drainer) RequestShutdown() {
+	d.shutdownOnce.Do(func() { close(d.shutdown) })
+}
+
+// ShutdownRequested is closed once RequestShutdown has been called.
+func (d *Drainer) ShutdownRequested() <-chan struct{} {
+	return d.shutdown
+}
+
+// Stats reports the current in-flight request and stream counts.
+func (d *Drainer) Stats() (inflight, streams int64) {
+	return d.inflight.Load(), d.streams.Load()
+}
+
+// Wait blocks until no requests (other than `exclude` of the caller's own) and
+// no streams remain, or ctx expires.
+func (d *Drainer) Wait(ctx context.Context, exclude int64) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if d.inflight.Load() <= exclude && d.streams.Load() == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/database"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Locker provides lease-based distributed locks stored in Mongo, so work that
+// must happen once per cluster (scheduled jobs, digests) isn't repeated by
+// every replica. Leases expire on their own if the holder dies.
+type Locker struct {
+	collection *mongo.Collection
+	owner      string
+	clock      clock.Clock
+}
+
+func NewLocker() *Locker {
+	host, _ := os.Hostname()
+	return &Locker{
+		collection: database.GetCollection("locks"),
+		owner:      fmt.Sprintf("%s-%s", host, uuid.New().String()[:8]),
+		clock:      clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake.
+func (l *Locker) WithClock(c clock.Clock) *Locker {
+	l.clock = c
+	return l
+}
+
+// Owner identifies this process as a lock holder.
+func (l *Locker) Owner() string {
+	return l.owner
+}
+
+// Acquire takes (or renews) the named lock for ttl. It returns false without
+// error if another owner holds an unexpired lease.
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	now := l.clock.Now()
+	_, err := l.collection.UpdateOne(ctx,
+		bson.M{
+			"_id": name,
+			"$or": []bson.M{
+				{"expires_at": bson.M{"$lte": now}},
+				{"owner": l.owner},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"owner":       l.owner,
+			"acquired_at": now,
+			"expires_at":  now.Add(ttl),
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		// The filter didn't match an expired/owned lease, so the upsert collided
+		// with the live lease held by someone else
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Release gives up the named lock if this process holds it.
+func (l *Locker) Release(ctx context.Context, name string) error {
+	_, err := l.collection.DeleteOne(ctx, bson.M{"_id": name, "owner": l.owner})
+	return err
+}
+
+// EnsureIndexes creates necessary indexes for the locks collection
+func (l *Locker) EnsureIndexes(ctx context.Context) error {
+	_, err := l.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0), // TTL index — clean up abandoned leases
+	})
+	return err
+}
@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Captured is an email kept by the dev mailbox instead of being delivered.
+type Captured struct {
+	ID      string    `json:"id"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	HTML    string    `json:"html,omitempty"`
+	Text    string    `json:"text,omitempty"`
+	Links   []string  `json:"links"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Mailbox is a provider for local development: it keeps the most recent
+// messages in memory so they can be read at /dev/mailbox rather than going
+// out through a real email service.
+type Mailbox struct {
+	mu       sync.Mutex
+	capacity int
+	seq      int64
+	messages []*Captured // oldest first
+}
+
+func NewMailbox(capacity int) *Mailbox {
+	if capacity <= 0 {
+		capacity = 50
+	}
+	return &Mailbox{capacity: capacity}
+}
+
+func (b *Mailbox) Name() string { return "mailbox" }
+
+func (b *Mailbox) Send(ctx context.Context, from string, msg *Message) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	captured := &Captured{
+		ID:      strconv.FormatInt(b.seq, 10),
+		From:    from,
+		To:      msg.To,
+		Subject: msg.Subject,
+		HTML:    msg.HTML,
+		Text:    msg.Text,
+		Links:   extractLinks(msg),
+		SentAt:  time.Now(),
+	}
+	b.messages = append(b.messages, captured)
+	if len(b.messages) > b.capacity {
+		b.messages = b.messages[len(b.messages)-b.capacity:]
+	}
+	return "mailbox-" + captured.ID, nil
+}
+
+// List returns the captured messages, newest first.
+func (b *Mailbox) List() []*Captured {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]*Captured, len(b.messages))
+	for i, m := range b.messages {
+		out[len(b.messages)-1-i] = m
+	}
+	return out
+}
+
+// Get returns a captured message, or nil once it has been pushed out.
+func (b *Mailbox) Get(id string) *Captured {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range b.messages {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// Clear empties the mailbox.
+func (b *Mailbox) Clear() {
+	b.mu.Lock()
+	b.messages = nil
+	b.mu.Unlock()
+}
+
+var (
+	textLinkPattern = regexp.MustCompile(`(?:https?|rizon)://[^\s"'<>]+`)
+	hrefPattern     = regexp.MustCompile(`href="([^"]+)"`)
+)
+
+// extractLinks pulls the URLs out of a message, preferring the plain-text
+// body since templates always repeat their links there.
+func extractLinks(msg *Message) []string {
+	var found []string
+	if msg.Text != "" {
+		found = textLinkPattern.FindAllString(msg.Text, -1)
+	} else {
+		for _, m := range hrefPattern.FindAllStringSubmatch(msg.HTML, -1) {
+			found = append(found, html.UnescapeString(m[1]))
+		}
+	}
+	seen := make(map[string]bool, len(found))
+	links := make([]string, 0, len(found))
+	for _, link := range found {
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
@@ -0,0 +1,297 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/resilience"
+	"rizon-backend/internal/slack"
+	"rizon-backend/internal/templates"
+)
+
+// Message is a rendered email ready to hand to a provider.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// FromRendered builds a message from an email template rendering.
+func FromRendered(to string, content *templates.Rendered) *Message {
+	return &Message{To: to, Subject: content.Subject, HTML: content.HTML, Text: content.Text}
+}
+
+// Provider delivers email through one upstream service.
+type Provider interface {
+	Name() string
+	// Send delivers the message and returns the provider's message ID.
+	Send(ctx context.Context, from string, msg *Message) (string, error)
+}
+
+// Attempt records one provider call made for a send.
+type Attempt struct {
+	Provider   string `json:"provider"`
+	Skipped    bool   `json:"skipped,omitempty"` // circuit open
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Delivery describes how a message was handled.
+type Delivery struct {
+	Provider  string    `json:"provider,omitempty"`
+	From      string    `json:"from"`
+	MessageID string    `json:"message_id,omitempty"`
+	DevMode   bool      `json:"dev_mode,omitempty"`
+	Sandboxed bool      `json:"sandboxed,omitempty"` // sent from a sandbox, so dropped
+	Failover  bool      `json:"failover,omitempty"`
+	Attempts  []Attempt `json:"attempts,omitempty"`
+}
+
+// Config tunes the per-provider circuit breakers.
+type Config struct {
+	// Consecutive failures that open a provider's circuit
+	FailureThreshold int
+	// How long an open circuit is skipped before a trial send
+	Cooldown time.Duration
+	// Upper bound for a single provider call
+	Timeout time.Duration
+}
+
+var DefaultConfig = Config{
+	FailureThreshold: 3,
+	Cooldown:         time.Minute,
+	Timeout:          10 * time.Second,
+}
+
+// ErrNoProvider is returned when every configured provider failed.
+var ErrNoProvider = errors.New("all email providers failed")
+
+// Mailer sends through providers in priority order. A provider whose
+// circuit is open is skipped, so sends fail over to the next one until a
+// trial send after the cooldown succeeds. Ops are alerted on Slack when the
+// primary's circuit opens and again when it recovers.
+type Mailer struct {
+	from      string
+	providers []*tracked
+	notifier  slack.Notifier
+	cfg       Config
+
+	mu        sync.Mutex
+	failovers int64
+}
+
+type tracked struct {
+	Provider
+	breaker *resilience.Breaker
+	sent    int64
+	failed  int64
+}
+
+// New creates a mailer. With no providers it runs in dev mode and only
+// reports sends as skipped; pass a Mailbox to keep them for inspection.
+func New(from string, providers []Provider, notifier slack.Notifier, cfg Config) *Mailer {
+	m := &Mailer{from: from, notifier: notifier, cfg: cfg}
+	for _, p := range providers {
+		m.providers = append(m.providers, &tracked{Provider: p, breaker: resilience.NewBreaker("email:"+p.Name(), resilience.BreakerConfig{
+			Threshold: cfg.FailureThreshold,
+			Cooldown:  cfg.Cooldown,
+		})})
+	}
+	return m
+}
+
+// Providers returns the configured provider names in priority order.
+func (m *Mailer) Providers() []string {
+	names := make([]string, len(m.providers))
+	for i, p := range m.providers {
+		names[i] = p.Name()
+	}
+	return names
+}
+
+// Active returns the provider the next send will try first, or "" in dev mode.
+func (m *Mailer) Active() string {
+	if len(m.providers) == 0 {
+		return ""
+	}
+	for _, p := range m.providers {
+		if p.breaker.Stats().State == resilience.StateClosed {
+			return p.Name()
+		}
+	}
+	return m.providers[0].Name()
+}
+
+// Send delivers msg with the first healthy provider. If every circuit is
+// open the providers are tried anyway — a late login email beats none.
+// Sandbox traffic doesn't send email.
+// A message without a text part gets one derived from its HTML, so clients
+// that strip HTML don't show an empty email.
+func (m *Mailer) Send(ctx context.Context, msg *Message) (*Delivery, error) {
+	if msg.Text == "" && msg.HTML != "" {
+		msg.Text = templates.PlainText(msg.HTML)
+	}
+	delivery := &Delivery{From: m.from}
+	if database.InSandbox(ctx) {
+		log.Println("🧪 Not sending email from a sandbox")
+		delivery.Sandboxed = true
+		return delivery, nil
+	}
+	if len(m.providers) == 0 {
+		log.Println("⚠️  No email provider configured, skipping email send")
+		delivery.DevMode = true
+		return delivery, nil
+	}
+
+	candidates := make([]*tracked, 0, len(m.providers))
+	for _, p := range m.providers {
+		if p.breaker.Allow() {
+			candidates = append(candidates, p)
+		} else {
+			delivery.Attempts = append(delivery.Attempts, Attempt{Provider: p.Name(), Skipped: true})
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = m.providers
+	}
+
+	var errs []string
+	for _, p := range candidates {
+		id, elapsed, err := m.attempt(ctx, p, msg)
+		attempt := Attempt{Provider: p.Name(), DurationMs: elapsed.Milliseconds()}
+		if err != nil {
+			attempt.Error = err.Error()
+			delivery.Attempts = append(delivery.Attempts, attempt)
+			errs = append(errs, fmt.Sprintf("%s: %v", p.Name(), err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		delivery.Provider = p.Name()
+		delivery.MessageID = id
+		if p != m.providers[0] {
+			delivery.Failover = true
+			m.mu.Lock()
+			m.failovers++
+			m.mu.Unlock()
+		}
+		return delivery, nil
+	}
+	return delivery, fmt.Errorf("%w: %s", ErrNoProvider, strings.Join(errs, "; "))
+}
+
+func (m *Mailer) attempt(ctx context.Context, p *tracked, msg *Message) (string, time.Duration, error) {
+	callCtx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	id, err := p.Send(callCtx, m.from, msg)
+	elapsed := time.Since(start)
+
+	m.mu.Lock()
+	if err != nil {
+		p.failed++
+	} else {
+		p.sent++
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		// A caller giving up isn't the provider's fault
+		if ctx.Err() == nil {
+			if opened := p.breaker.Failure(); opened {
+				m.alertOpened(p, err)
+			}
+		}
+		return "", elapsed, err
+	}
+	if recovered := p.breaker.Success(); recovered {
+		m.alertRecovered(p)
+	}
+	return id, elapsed, nil
+}
+
+func (m *Mailer) alertOpened(p *tracked, cause error) {
+	log.Printf("⚠️  Email provider %s circuit opened: %v", p.Name(), cause)
+	if p != m.providers[0] || m.notifier == nil {
+		return
+	}
+	fallback := "none"
+	if len(m.providers) > 1 {
+		fallback = m.providers[1].Name()
+	}
+	m.alert("email_failover_engaged", map[string]interface{}{
+		"Provider": p.Name(),
+		"Fallback": fallback,
+		"Failures": m.cfg.FailureThreshold,
+		"Error":    cause.Error(),
+		"Cooldown": m.cfg.Cooldown.String(),
+	})
+}
+
+func (m *Mailer) alertRecovered(p *tracked) {
+	log.Printf("✅ Email provider %s recovered", p.Name())
+	if p != m.providers[0] || m.notifier == nil {
+		return
+	}
+	m.alert("email_provider_recovered", map[string]interface{}{"Provider": p.Name()})
+}
+
+func (m *Mailer) alert(template string, data map[string]interface{}) {
+	content, err := templates.Render(template, templates.ChannelSlack, data)
+	if err != nil {
+		log.Printf("Error rendering email alert: %v", err)
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := m.notifier.Publish(ctx, content.Text); err != nil {
+			log.Printf("Error sending email alert to Slack: %v", err)
+		}
+	}()
+}
+
+// ProviderStats is the live state of one provider.
+type ProviderStats struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	Sent                int64      `json:"sent"`
+	Failed              int64      `json:"failed"`
+}
+
+// Stats are process-local counters since startup.
+type Stats struct {
+	Providers []ProviderStats `json:"providers"`
+	Failovers int64           `json:"failovers"`
+}
+
+func (m *Mailer) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := Stats{Providers: []ProviderStats{}, Failovers: m.failovers}
+	for _, p := range m.providers {
+		breaker := p.breaker.Stats()
+		stats.Providers = append(stats.Providers, ProviderStats{
+			Name:                p.Name(),
+			State:               breaker.State,
+			ConsecutiveFailures: breaker.ConsecutiveFailures,
+			OpenedAt:            breaker.OpenedAt,
+			Sent:                p.sent,
+			Failed:              p.failed,
+		})
+	}
+	return stats
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+
+	"rizon-backend/internal/httpclient"
+
+	"github.com/resend/resend-go/v2"
+)
+
+// Resend sends through the Resend API.
+type Resend struct {
+	client *resend.Client
+}
+
+func NewResend(apiKey string) *Resend {
+	return &Resend{client: resend.NewCustomClient(httpclient.New("resend", httpclient.Options{}), apiKey)}
+}
+
+func (p *Resend) Name() string { return "resend" }
+
+func (p *Resend) Send(ctx context.Context, from string, msg *Message) (string, error) {
+	sent, err := p.client.Emails.SendWithContext(ctx, &resend.SendEmailRequest{
+		From:    from,
+		To:      []string{msg.To},
+		Subject: msg.Subject,
+		Html:    msg.HTML,
+		Text:    msg.Text,
+	})
+	if err != nil {
+		return "", err
+	}
+	return sent.Id, nil
+}
@@ -0,0 +1,149 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SMTP sends through any SMTP relay, including Amazon SES's SMTP interface.
+// Port 465 uses implicit TLS; other ports upgrade with STARTTLS.
+type SMTP struct {
+	name     string
+	host     string
+	port     string
+	username string
+	password string
+}
+
+func NewSMTP(name, host, port, username, password string) *SMTP {
+	if port == "" {
+		port = "587"
+	}
+	return &SMTP{name: name, host: host, port: port, username: username, password: password}
+}
+
+func (p *SMTP) Name() string { return p.name }
+
+func (p *SMTP) Send(ctx context.Context, from string, msg *Message) (string, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid from address: %w", err)
+	}
+
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	if _, isTLS := conn.(*tls.Conn); !isTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: p.host}); err != nil {
+				return "", err
+			}
+		}
+	}
+	if p.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.username, p.password, p.host)); err != nil {
+			return "", err
+		}
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.NewString(), p.host)
+	body, err := buildMIME(from, messageID, msg)
+	if err != nil {
+		return "", err
+	}
+
+	if err := client.Mail(fromAddr.Address); err != nil {
+		return "", err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return "", err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(body); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return messageID, client.Quit()
+}
+
+func (p *SMTP) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(p.host, p.port)
+	if p.port == "465" {
+		d := &tls.Dialer{Config: &tls.Config{ServerName: p.host}}
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// buildMIME renders a multipart/alternative message with text and HTML
+// parts, or a single text/plain body for plain-format email.
+func buildMIME(from, messageID string, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", messageID)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	if msg.HTML == "" {
+		fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: 8bit\r\n\r\n")
+		buf.WriteString(msg.Text)
+		return buf.Bytes(), nil
+	}
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", parts.Boundary())
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
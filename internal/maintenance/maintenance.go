@@ -0,0 +1,128 @@
+// Package maintenance runs admin-triggered upkeep tasks (index rebuilds,
+// backfills) on the job queue, with progress and cancellation.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"rizon-backend/internal/backfill"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// JobType is the job queue type for maintenance tasks.
+const JobType = "maintenance"
+
+// Payload is stored on the maintenance job.
+type Payload struct {
+	Task string `bson:"task"`
+}
+
+// Task is one named maintenance operation. Run should call
+// jobs.ReportProgress as it goes and stop when ctx is cancelled.
+type Task struct {
+	Name        string                                    `json:"name"`
+	Description string                                    `json:"description"`
+	Run         func(ctx context.Context) (bson.M, error) `json:"-"`
+}
+
+// Registry holds the tasks admins can start.
+type Registry struct {
+	tasks map[string]Task
+}
+
+func NewRegistry(tasks ...Task) *Registry {
+	r := &Registry{tasks: map[string]Task{}}
+	for _, task := range tasks {
+		r.Add(task)
+	}
+	return r
+}
+
+// Add registers a task, replacing any with the same name.
+func (r *Registry) Add(task Task) {
+	r.tasks[task.Name] = task
+}
+
+// Get returns the named task.
+func (r *Registry) Get(name string) (Task, bool) {
+	task, ok := r.tasks[name]
+	return task, ok
+}
+
+// Tasks lists the registered tasks by name.
+func (r *Registry) Tasks() []Task {
+	tasks := make([]Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+// Handle is the job handler for JobType.
+func (r *Registry) Handle(ctx context.Context, job *models.Job) (bson.M, error) {
+	var payload Payload
+	if err := job.DecodePayload(&payload); err != nil {
+		return nil, fmt.Errorf("%w: invalid payload: %v", jobs.ErrPermanent, err)
+	}
+	task, ok := r.Get(payload.Task)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown task %q", jobs.ErrPermanent, payload.Task)
+	}
+	return task.Run(ctx)
+}
+
+// Index is a collection's index setup, as run at startup.
+type Index struct {
+	Name   string
+	Ensure func(ctx context.Context) error
+}
+
+// RebuildIndexes re-runs every collection's index setup, e.g. after indexes
+// were dropped by hand or a deploy's startup timed out before creating them.
+func RebuildIndexes(indexes []Index) Task {
+	return Task{
+		Name:        "rebuild-indexes",
+		Description: "Create any missing indexes on every collection",
+		Run: func(ctx context.Context) (bson.M, error) {
+			failed := bson.M{}
+			for i, index := range indexes {
+				if err := ctx.Err(); err != nil {
+					return bson.M{"failed": failed}, err
+				}
+				jobs.ReportProgress(ctx, int64(i), int64(len(indexes)), index.Name)
+				if err := index.Ensure(ctx); err != nil {
+					failed[index.Name] = err.Error()
+				}
+			}
+			jobs.ReportProgress(ctx, int64(len(indexes)), int64(len(indexes)), "done")
+			if len(failed) > 0 {
+				return bson.M{"failed": failed}, fmt.Errorf("%d of %d index sets failed", len(failed), len(indexes))
+			}
+			return bson.M{"collections": len(indexes)}, nil
+		},
+	}
+}
+
+// Backfill runs a registered backfill from the admin API, resuming from its
+// checkpoint like the CLI does.
+func Backfill(b backfill.Backfill) Task {
+	return Task{
+		Name:        "backfill-" + b.Name,
+		Description: b.Description,
+		Run: func(ctx context.Context) (bson.M, error) {
+			stats, err := backfill.Run(ctx, b, backfill.Options{
+				Progress: func(stats backfill.Stats, total int64) {
+					jobs.ReportProgress(ctx, stats.Scanned, total, "")
+				},
+			})
+			result := bson.M{"scanned": stats.Scanned, "updated": stats.Updated, "skipped": stats.Skipped}
+			return result, err
+		},
+	}
+}
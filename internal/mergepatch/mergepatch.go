@@ -0,0 +1,182 @@
+// Package mergepatch applies RFC 7396 JSON Merge Patch documents to Mongo
+// documents: a patch is validated against a whitelist of fields and turned
+// into a single $set/$unset update.
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ContentType is the media type of a merge patch. Plain application/json is
+// accepted too and treated the same way.
+const ContentType = "application/merge-patch+json"
+
+// ErrUnsupportedMediaType is returned by Decode for bodies that aren't JSON.
+var ErrUnsupportedMediaType = errors.New("content type must be " + ContentType)
+
+// Error is a patch that doesn't fit the schema; its message is safe to show
+// to the client.
+type Error struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Field describes one patchable member.
+type Field struct {
+	// Path is the Mongo field name; defaults to the JSON member name.
+	Path string
+	// Nullable allows null, which removes the field ($unset).
+	Nullable bool
+	// Validate checks a non-null value and returns what to store. Required
+	// unless Fields is set.
+	Validate func(v interface{}) (interface{}, error)
+	// Fields makes this member an object that is merged member by member
+	// instead of replaced.
+	Fields Schema
+}
+
+// Schema is the set of members a patch may touch, keyed by JSON name.
+type Schema map[string]Field
+
+// Decode reads a merge patch from the request body. The body must be a JSON
+// object; unknown members are left for Update to reject.
+func Decode(r *http.Request) (map[string]interface{}, error) {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != ContentType && mediaType != "application/json") {
+			return nil, ErrUnsupportedMediaType
+		}
+	}
+
+	var patch map[string]interface{}
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&patch); err != nil {
+		return nil, &Error{Field: "body", Message: "must be a JSON object"}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &Error{Field: "body", Message: "must contain a single JSON object"}
+	}
+	if patch == nil {
+		return nil, &Error{Field: "body", Message: "must be a JSON object"}
+	}
+	return patch, nil
+}
+
+// Update validates patch against s and returns the Mongo update document.
+// It returns an empty update (len 0) for an empty patch.
+func (s Schema) Update(patch map[string]interface{}) (bson.M, error) {
+	set, unset := bson.M{}, bson.M{}
+	if err := s.apply("", "", patch, set, unset); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	return update, nil
+}
+
+func (s Schema) apply(jsonPrefix, pathPrefix string, patch map[string]interface{}, set, unset bson.M) error {
+	// Sorted so the first error reported is stable
+	names := make([]string, 0, len(patch))
+	for name := range patch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := patch[name]
+		field, ok := s[name]
+		if !ok {
+			return &Error{Field: jsonPrefix + name, Message: "cannot be changed"}
+		}
+		path := field.Path
+		if path == "" {
+			path = name
+		}
+		path = pathPrefix + path
+
+		if value == nil {
+			if !field.Nullable {
+				return &Error{Field: jsonPrefix + name, Message: "cannot be removed"}
+			}
+			unset[path] = ""
+			continue
+		}
+
+		if field.Fields != nil {
+			nested, ok := value.(map[string]interface{})
+			if !ok {
+				return &Error{Field: jsonPrefix + name, Message: "must be an object"}
+			}
+			if err := field.Fields.apply(jsonPrefix+name+".", path+".", nested, set, unset); err != nil {
+				return err
+			}
+			continue
+		}
+
+		stored, err := field.Validate(value)
+		if err != nil {
+			return &Error{Field: jsonPrefix + name, Message: err.Error()}
+		}
+		set[path] = stored
+	}
+	return nil
+}
+
+// String accepts a string of at most maxLen characters after trimming.
+func String(maxLen int) func(v interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, errors.New("must be a string")
+		}
+		s = strings.TrimSpace(s)
+		if len([]rune(s)) > maxLen {
+			return nil, fmt.Errorf("must be at most %d characters", maxLen)
+		}
+		return s, nil
+	}
+}
+
+// OneOf accepts one of the given strings.
+func OneOf(values ...string) func(v interface{}) (interface{}, error) {
+	return func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if ok {
+			for _, allowed := range values {
+				if s == allowed {
+					return s, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+	}
+}
+
+// Bool accepts true or false.
+func Bool(v interface{}) (interface{}, error) {
+	b, ok := v.(bool)
+	if !ok {
+		return nil, errors.New("must be true or false")
+	}
+	return b, nil
+}
@@ -0,0 +1,114 @@
+package mergepatch
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var profile = Schema{
+	"name":  {Validate: String(5)},
+	"theme": {Validate: OneOf("light", "dark"), Nullable: true},
+	"notifications": {Path: "prefs", Fields: Schema{
+		"email":  {Validate: Bool},
+		"digest": {Path: "digest_day", Validate: OneOf("mon", "fri"), Nullable: true},
+	}},
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch map[string]interface{}
+		want  bson.M
+	}{
+		{"empty", map[string]interface{}{}, bson.M{}},
+		{"set", map[string]interface{}{"name": "  Jo  ", "theme": "dark"}, bson.M{"$set": bson.M{"name": "Jo", "theme": "dark"}}},
+		{"unset", map[string]interface{}{"theme": nil}, bson.M{"$unset": bson.M{"theme": ""}}},
+		{"nested", map[string]interface{}{"notifications": map[string]interface{}{"email": false, "digest": nil}}, bson.M{
+			"$set":   bson.M{"prefs.email": false},
+			"$unset": bson.M{"prefs.digest_day": ""},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := profile.Update(tt.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Update = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch map[string]interface{}
+		want  Error
+	}{
+		{"unknown field", map[string]interface{}{"role": "admin"}, Error{"role", "cannot be changed"}},
+		{"not nullable", map[string]interface{}{"name": nil}, Error{"name", "cannot be removed"}},
+		{"too long", map[string]interface{}{"name": "Jordan"}, Error{"name", "must be at most 5 characters"}},
+		{"wrong type", map[string]interface{}{"name": 5}, Error{"name", "must be a string"}},
+		{"not allowed", map[string]interface{}{"theme": "blue"}, Error{"theme", "must be one of light, dark"}},
+		{"nested not an object", map[string]interface{}{"notifications": true}, Error{"notifications", "must be an object"}},
+		{"nested unknown field", map[string]interface{}{"notifications": map[string]interface{}{"sms": true}}, Error{"notifications.sms", "cannot be changed"}},
+		{"nested wrong type", map[string]interface{}{"notifications": map[string]interface{}{"email": "yes"}}, Error{"notifications.email", "must be true or false"}},
+		// Errors are reported in name order
+		{"first by name", map[string]interface{}{"theme": "blue", "name": nil}, Error{"name", "cannot be removed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := profile.Update(tt.patch)
+			var perr *Error
+			if !errors.As(err, &perr) || *perr != tt.want {
+				t.Errorf("err = %v, want %v", err, &tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"merge patch", ContentType, `{"name": "Jo"}`, false},
+		{"json", "application/json; charset=utf-8", `{"name": "Jo"}`, false},
+		{"no content type", "", `{"name": "Jo"}`, false},
+		{"form", "application/x-www-form-urlencoded", `name=Jo`, true},
+		{"array", ContentType, `[]`, true},
+		{"null", ContentType, `null`, true},
+		{"two objects", ContentType, `{} {}`, true},
+		{"malformed", ContentType, `{"name"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/me", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			patch, err := Decode(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && patch["name"] != "Jo" {
+				t.Errorf("patch = %v", patch)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("PATCH", "/me", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "text/plain")
+	if _, err := Decode(r); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("text/plain: err = %v, want ErrUnsupportedMediaType", err)
+	}
+}
@@ -0,0 +1,119 @@
+package metering
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type counterKey struct {
+	userID   string
+	day      string
+	endpoint string
+}
+
+// Meter aggregates per-user request counts in memory and periodically flushes
+// them to the usage_daily collection, so the request path never waits on Mongo.
+type Meter struct {
+	usageRepo *repository.UsageRepo
+	quotaRepo *repository.QuotaRepo
+
+	mu      sync.Mutex
+	pending map[counterKey]int64
+}
+
+func NewMeter(usageRepo *repository.UsageRepo, quotaRepo *repository.QuotaRepo) *Meter {
+	return &Meter{
+		usageRepo: usageRepo,
+		quotaRepo: quotaRepo,
+		pending:   make(map[counterKey]int64),
+	}
+}
+
+// Record counts one request by the user against the endpoint key.
+func (m *Meter) Record(userID, endpoint string) {
+	key := counterKey{userID: userID, day: Today(), endpoint: endpoint}
+	m.mu.Lock()
+	m.pending[key]++
+	m.mu.Unlock()
+}
+
+// Run flushes pending counters every interval until ctx is cancelled,
+// then performs a final flush.
+func (m *Meter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			m.flush(flushCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+func (m *Meter) flush(ctx context.Context) {
+	m.mu.Lock()
+	batch := m.pending
+	m.pending = make(map[counterKey]int64)
+	m.mu.Unlock()
+
+	for key, count := range batch {
+		userID, err := bson.ObjectIDFromHex(key.userID)
+		if err != nil {
+			continue
+		}
+		if err := m.usageRepo.Increment(ctx, userID, key.day, key.endpoint, count); err != nil {
+			log.Printf("Error flushing usage for %s: %v", key.userID, err)
+			// Put the counts back so they are retried on the next flush
+			m.mu.Lock()
+			m.pending[key] += count
+			m.mu.Unlock()
+		}
+	}
+}
+
+// Allow reports whether the user is still under the admin-configured daily quota
+// for the endpoint key. Endpoints without a quota are always allowed.
+func (m *Meter) Allow(ctx context.Context, userID, endpoint string) (bool, int64, error) {
+	quota, err := m.quotaRepo.FindByEndpoint(ctx, endpoint)
+	if err != nil {
+		return false, 0, err
+	}
+	if quota == nil {
+		return true, 0, nil
+	}
+
+	oid, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, quota.DailyLimit, err
+	}
+
+	day := Today()
+	persisted, err := m.usageRepo.CountForDay(ctx, oid, day, endpoint)
+	if err != nil {
+		return false, quota.DailyLimit, err
+	}
+
+	// Include counts that have not been flushed yet
+	m.mu.Lock()
+	unflushed := m.pending[counterKey{userID: userID, day: day, endpoint: endpoint}]
+	m.mu.Unlock()
+
+	return persisted+unflushed < quota.DailyLimit, quota.DailyLimit, nil
+}
+
+// Today returns the current UTC day in the format used for usage records.
+func Today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UserLookup loads the authenticated user's account.
+type UserLookup interface {
+	FindByID(ctx context.Context, id bson.ObjectID) (*models.User, error)
+}
+
+// Restriction explains, in machine-readable form, why an account may not use the API.
+type Restriction struct {
+	Error  string     `json:"error"`
+	Code   string     `json:"code"`             // "age_restricted", "account_suspended", "account_banned" or "account_merged"
+	Reason string     `json:"reason,omitempty"` // ban reason code
+	Until  *time.Time `json:"until,omitempty"`  // when a suspension or ban lifts
+}
+
+// AccountRestriction returns why the account may not use the API, or nil if it may.
+func AccountRestriction(user *models.User) *Restriction {
+	if user.Status == models.UserStatusMerged {
+		return &Restriction{Error: "account merged", Code: "account_merged"}
+	}
+	if user.AgeBlocked {
+		return &Restriction{Error: "account restricted", Code: "age_restricted"}
+	}
+	if user.IsRestricted(time.Now()) {
+		return &Restriction{
+			Error:  "account " + user.Status,
+			Code:   "account_" + user.Status,
+			Reason: user.BanReason,
+			Until:  user.BannedUntil,
+		}
+	}
+	return nil
+}
+
+// AccountGuard rejects requests from restricted accounts with a code the app
+// uses to pick the right screen. Must be mounted after JWTAuth.
+func AccountGuard(users UserLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := bson.ObjectIDFromHex(GetUserID(r.Context()))
+			if err != nil {
+				http.Error(w, `{"error":"invalid user_id in token"}`, http.StatusUnauthorized)
+				return
+			}
+
+			user, err := users.FindByID(r.Context(), userID)
+			if err != nil {
+				log.Printf("Error loading account: %v", err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if user == nil {
+				http.Error(w, `{"error":"account not found","code":"account_not_found"}`, http.StatusUnauthorized)
+				return
+			}
+			if restriction := AccountRestriction(user); restriction != nil {
+				body, _ := json.Marshal(restriction)
+				http.Error(w, string(body), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
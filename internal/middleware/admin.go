@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/models"
+)
+
+const (
+	AdminNameKey        contextKey = "admin_name"
+	AdminPermissionsKey contextKey = "admin_permissions"
+)
+
+// AdminKeyLookup resolves scoped admin API keys by their SHA-256 hash.
+type AdminKeyLookup interface {
+	FindActiveByHash(ctx context.Context, hash string) (*models.AdminKey, error)
+}
+
+// AdminAuth authenticates admin routes with an API key sent in the X-Admin-Key header.
+// The root key (ADMIN_API_KEY) has every permission; other keys carry scoped grants.
+// If no root key is configured, all admin routes are disabled.
+func AdminAuth(rootKey string, keys AdminKeyLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rootKey == "" {
+				http.Error(w, `{"error":"admin API is disabled"}`, http.StatusForbidden)
+				return
+			}
+
+			provided := r.Header.Get("X-Admin-Key")
+			if provided == "" {
+				http.Error(w, `{"error":"invalid admin key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			name, permissions := "root", []string{"*"}
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(rootKey)) != 1 {
+				key, err := keys.FindActiveByHash(r.Context(), HashAdminKey(provided))
+				if err != nil {
+					log.Printf("Error looking up admin key: %v", err)
+					http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+					return
+				}
+				if key == nil {
+					http.Error(w, `{"error":"invalid admin key"}`, http.StatusUnauthorized)
+					return
+				}
+				name, permissions = key.Name, key.Permissions
+			}
+
+			ctx := context.WithValue(r.Context(), AdminNameKey, name)
+			ctx = context.WithValue(ctx, AdminPermissionsKey, permissions)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IntegrationAuth authenticates third-party automation tools (Zapier, Make)
+// with a scoped API key in the X-API-Key header. Unlike AdminAuth the root
+// key is not accepted, so integrations only ever hold the grants they need.
+func IntegrationAuth(keys AdminKeyLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-API-Key")
+			if provided == "" {
+				http.Error(w, `{"error":"missing API key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			key, err := keys.FindActiveByHash(r.Context(), HashAdminKey(provided))
+			if err != nil {
+				log.Printf("Error looking up API key: %v", err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if key == nil {
+				http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), AdminNameKey, key.Name)
+			ctx = context.WithValue(ctx, AdminPermissionsKey, key.Permissions)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission rejects admin requests whose key lacks perm. Must be mounted after AdminAuth.
+func RequirePermission(perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !models.HasPermission(GetAdminPermissions(r.Context()), perm) {
+				http.Error(w, `{"error":"missing permission `+perm+`"}`, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HashAdminKey returns the stored form of an admin API key.
+func HashAdminKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetAdminName extracts the authenticated admin key name from the request context.
+func GetAdminName(ctx context.Context) string {
+	if name, ok := ctx.Value(AdminNameKey).(string); ok {
+		return name
+	}
+	return ""
+}
+
+// GetAdminPermissions extracts the authenticated admin key's grants from the request context.
+func GetAdminPermissions(ctx context.Context) []string {
+	if perms, ok := ctx.Value(AdminPermissionsKey).([]string); ok {
+		return perms
+	}
+	return nil
+}
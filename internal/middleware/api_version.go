@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/apiversion"
+)
+
+// APIVersion negotiates the response format and, for camelCase clients,
+// rewrites JSON response bodies. Non-JSON responses (event streams,
+// downloads) pass straight through. The chosen version is echoed in the
+// X-API-Version response header.
+func APIVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept, X-API-Version")
+		n, err := apiversion.Negotiate(r)
+		if err != nil {
+			http.Error(w, `{"error":"unsupported API version"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-API-Version", n.Version)
+		if n.Casing == apiversion.SnakeCase {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &casingWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		cw.finish()
+	})
+}
+
+// casingWriter buffers JSON responses so their keys can be rewritten once the
+// handler is done.
+type casingWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	buffering   bool
+}
+
+func (cw *casingWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	if strings.HasPrefix(cw.Header().Get("Content-Type"), "application/json") {
+		cw.buffering = true
+		cw.Header().Del("Content-Length")
+		return
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *casingWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.buffering {
+		return cw.buf.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses working; buffered JSON is sent by finish.
+func (cw *casingWriter) Flush() {
+	if cw.buffering {
+		return
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *casingWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
+
+func (cw *casingWriter) finish() {
+	if !cw.buffering {
+		return
+	}
+	body := cw.buf.Bytes()
+	if len(body) > 0 {
+		if out, err := apiversion.ToCamelCase(body); err != nil {
+			log.Printf("Error converting response to camelCase: %v", err)
+		} else {
+			body = out
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.ResponseWriter.Write(body)
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"rizon-backend/internal/apiversion"
+	"rizon-backend/internal/contracts"
+)
+
+// TestAPIVersionContracts serves every published sample through the
+// middleware and checks each client gets its version's fixture with the
+// handler's status code.
+func TestAPIVersionContracts(t *testing.T) {
+	clients := []struct {
+		name    string
+		headers map[string]string
+		version string
+	}{
+		{"no headers", nil, apiversion.V1},
+		{"explicit v1", map[string]string{"X-API-Version": "1"}, apiversion.V1},
+		{"prefixed v1", map[string]string{"X-API-Version": "v1"}, apiversion.V1},
+		{"v2", map[string]string{"X-API-Version": "2"}, apiversion.V2},
+		{"camelCase profile", map[string]string{"Accept": `application/json; profile="camelCase"`}, apiversion.V2},
+	}
+	for _, c := range contracts.All {
+		handler := APIVersion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(c.Status)
+			json.NewEncoder(w).Encode(c.Sample())
+		}))
+		for _, client := range clients {
+			t.Run(c.Name+"/"+client.name, func(t *testing.T) {
+				r := httptest.NewRequest(c.Method, "/", nil)
+				for k, v := range client.headers {
+					r.Header.Set(k, v)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, r)
+
+				if rec.Code != c.Status {
+					t.Errorf("status = %d, want %d", rec.Code, c.Status)
+				}
+				fixture, err := contracts.Fixture(client.version, c.Name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var want bytes.Buffer
+				if err := json.Compact(&want, fixture); err != nil {
+					t.Fatal(err)
+				}
+				if got := bytes.TrimSpace(rec.Body.Bytes()); !bytes.Equal(got, want.Bytes()) {
+					t.Errorf("body = %s\nwant %s", got, want.Bytes())
+				}
+			})
+		}
+	}
+}
+
+func TestAPIVersionRejectsUnknownVersion(t *testing.T) {
+	handler := APIVersion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-API-Version", "9")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAPIVersionPassesThroughNonJSON(t *testing.T) {
+	handler := APIVersion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"feedback_id\":\"x\"}\n\n"))
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-API-Version", "2")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+
+	if got := rec.Body.String(); got != "data: {\"feedback_id\":\"x\"}\n\n" {
+		t.Errorf("body = %q", got)
+	}
+}
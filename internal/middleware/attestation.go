@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// AttestationConsumer redeems single-use attestation pass tokens.
+type AttestationConsumer interface {
+	ConsumePass(ctx context.Context, passToken string) (bool, error)
+}
+
+// RequireAttestation rejects requests without a valid X-Attestation-Token from
+// POST /auth/attest. When disabled it is a no-op, so the feature can be
+// flagged on once enough app installs send attestations.
+func RequireAttestation(consumer AttestationConsumer, enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.Header.Get("X-Attestation-Token")
+			if token == "" {
+				http.Error(w, `{"error":"attestation required"}`, http.StatusForbidden)
+				return
+			}
+
+			ok, err := consumer.ConsumePass(r.Context(), token)
+			if err != nil {
+				log.Printf("Error checking attestation: %v", err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.Error(w, `{"error":"invalid or expired attestation"}`, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
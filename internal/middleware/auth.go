@@ -2,19 +2,40 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
+
+	"rizon-backend/internal/jwtkeys"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type contextKey string
 
-const UserIDKey contextKey = "user_id"
+const (
+	UserIDKey      contextKey = "user_id"
+	TokenIDKey     contextKey = "token_id"
+	TokenExpiryKey contextKey = "token_expiry"
+)
+
+// ExpiryObserver is told the issuance policy ("pol" claim) of every correctly
+// signed token rejected for being expired.
+type ExpiryObserver interface {
+	Expired(policy string)
+}
+
+// RevocationList reports whether a token ID ("jti" claim) was signed out.
+type RevocationList interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
 
-// JWTAuth middleware validates the JWT token from the Authorization header
-// and injects the user_id into the request context.
-func JWTAuth(jwtSecret string) func(http.Handler) http.Handler {
+// JWTAuth middleware validates the JWT token from the Authorization header,
+// rejects revoked tokens and injects the user_id, token ID and expiry into
+// the request context. observer and revoked may be nil.
+func JWTAuth(keys *jwtkeys.Keyring, observer ExpiryObserver, revoked RevocationList) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -30,13 +51,14 @@ func JWTAuth(jwtSecret string) func(http.Handler) http.Handler {
 			}
 
 			tokenString := parts[1]
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(jwtSecret), nil
-			})
+			token, err := keys.Parse(tokenString)
 
+			if errors.Is(err, jwt.ErrTokenExpired) && observer != nil {
+				if claims, ok := token.Claims.(jwt.MapClaims); ok {
+					policy, _ := claims["pol"].(string)
+					observer.Expired(policy)
+				}
+			}
 			if err != nil || !token.Valid {
 				http.Error(w, `{"error":"invalid or expired token"}`, http.StatusUnauthorized)
 				return
@@ -54,7 +76,26 @@ func JWTAuth(jwtSecret string) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Tokens issued before jti was added can't be revoked
+			jti, _ := claims["jti"].(string)
+			if jti != "" && revoked != nil {
+				isRevoked, err := revoked.IsRevoked(r.Context(), jti)
+				if err != nil {
+					log.Printf("Error checking token revocation: %v", err)
+					http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+					return
+				}
+				if isRevoked {
+					http.Error(w, `{"error":"token has been revoked","code":"token_revoked"}`, http.StatusUnauthorized)
+					return
+				}
+			}
+
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, TokenIDKey, jti)
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				ctx = context.WithValue(ctx, TokenExpiryKey, exp.Time)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -67,3 +108,20 @@ func GetUserID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// GetTokenID extracts the access token's jti from the request context. It is
+// empty for tokens issued before jti was added.
+func GetTokenID(ctx context.Context) string {
+	if id, ok := ctx.Value(TokenIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// GetTokenExpiry extracts the access token's expiry from the request context.
+func GetTokenExpiry(ctx context.Context) time.Time {
+	if exp, ok := ctx.Value(TokenExpiryKey).(time.Time); ok {
+		return exp
+	}
+	return time.Time{}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/database"
+)
+
+// ConsistencyHeader carries the time (unix ms) of the client's last write.
+// It is returned on every write and the app echoes it on following reads.
+const ConsistencyHeader = "X-Consistency-Token"
+
+// ReadYourWrites stamps write requests with a consistency token and routes
+// reads carrying a token younger than window to the primary, so a GET right
+// after a PATCH never sees a lagging secondary.
+func ReadYourWrites(window time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				if recentWrite(r.Header.Get(ConsistencyHeader), now, window) {
+					r = r.WithContext(database.WithPrimaryReads(r.Context()))
+				}
+			default:
+				// Stamped up front: headers can't be added once the handler
+				// has written, and a failed write only costs a primary read.
+				w.Header().Set(ConsistencyHeader, strconv.FormatInt(now.UnixMilli(), 10))
+				r = r.WithContext(database.WithPrimaryReads(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// recentWrite reports whether token is a write time within window of now.
+// Tokens from the future (clock skew, tampering) are capped at one window.
+func recentWrite(token string, now time.Time, window time.Duration) bool {
+	if token == "" || window <= 0 {
+		return false
+	}
+	ms, err := strconv.ParseInt(token, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := now.Sub(time.UnixMilli(ms))
+	return age < window && age > -window
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import "net/http"
+
+// FlagChecker decides whether a feature flag is on for a user.
+type FlagChecker interface {
+	Enabled(key, userID string) bool
+}
+
+// FeatureFlag hides a route unless the flag is on for the caller. Disabled
+// routes answer 404 so dark-launched endpoints are indistinguishable from
+// missing ones. Place after JWTAuth to enable per-user rollout.
+func FeatureFlag(checker FlagChecker, key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checker.Enabled(key, GetUserID(r.Context())) {
+				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"rizon-backend/internal/redact"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// redactingFormatter wraps chi's default access log formatter so login tokens
+// and other credentials in query strings never reach the logs.
+type redactingFormatter struct {
+	inner chimiddleware.LogFormatter
+}
+
+func (f redactingFormatter) NewLogEntry(r *http.Request) chimiddleware.LogEntry {
+	clone := r.Clone(r.Context())
+	clone.RequestURI = redact.URL(r.URL)
+	return f.inner.NewLogEntry(clone)
+}
+
+// Logger is chi's request logger with PII redaction applied.
+func Logger(next http.Handler) http.Handler {
+	return chimiddleware.RequestLogger(redactingFormatter{
+		inner: &chimiddleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags), NoColor: false},
+	})(next)
+}
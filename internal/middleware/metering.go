@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/database"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UsageRecorder records a request by a user against an endpoint key.
+type UsageRecorder interface {
+	Record(userID, endpoint string)
+}
+
+// QuotaChecker decides whether a user may call a quota-limited endpoint.
+type QuotaChecker interface {
+	UsageRecorder
+	Allow(ctx context.Context, userID, endpoint string) (bool, int64, error)
+}
+
+// Metering counts every authenticated request under "METHOD /route/pattern".
+// Sandbox requests aren't counted. Must be mounted after JWTAuth.
+func Metering(recorder UsageRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			userID := GetUserID(r.Context())
+			if userID == "" || database.InSandbox(r.Context()) {
+				return
+			}
+			pattern := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				pattern = rctx.RoutePattern()
+			}
+			recorder.Record(userID, r.Method+" "+pattern)
+		})
+	}
+}
+
+// Quota enforces the admin-configured daily limit for the named endpoint key
+// (e.g. "export", "sync") and responds 429 once the user has used it up.
+// Sandbox requests neither count nor are limited.
+func Quota(checker QuotaChecker, endpoint string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if database.InSandbox(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			userID := GetUserID(r.Context())
+			if userID == "" {
+				http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+
+			allowed, _, err := checker.Allow(r.Context(), userID, endpoint)
+			if err != nil {
+				log.Printf("Error checking quota for %s: %v", endpoint, err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, `{"error":"daily quota exceeded for this endpoint"}`, http.StatusTooManyRequests)
+				return
+			}
+
+			checker.Record(userID, endpoint)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
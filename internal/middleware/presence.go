@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+)
+
+// PresenceRecorder is told about every authenticated request.
+type PresenceRecorder interface {
+	Seen(userID, deviceID string, client *models.ClientInfo)
+}
+
+// Presence records last-seen activity per user and device, except for
+// sandbox requests. Must be mounted after JWTAuth.
+func Presence(recorder PresenceRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if userID := GetUserID(r.Context()); userID != "" && !database.InSandbox(r.Context()) {
+				recorder.Seen(userID, clip(r.Header.Get("X-Device-ID")), ClientInfo(r))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientInfo reads the X-App-Version, X-App-Build, X-OS, X-OS-Version and
+// X-Device-Model headers the app sends on every request. Returns nil if none
+// are set.
+func ClientInfo(r *http.Request) *models.ClientInfo {
+	info := &models.ClientInfo{
+		AppVersion:  clip(r.Header.Get("X-App-Version")),
+		BuildNumber: clip(r.Header.Get("X-App-Build")),
+		OS:          strings.ToLower(clip(r.Header.Get("X-OS"))),
+		OSVersion:   clip(r.Header.Get("X-OS-Version")),
+		DeviceModel: clip(r.Header.Get("X-Device-Model")),
+	}
+	if info.IsZero() {
+		return nil
+	}
+	return info
+}
+
+// clip trims free-form client input and keeps it short enough to group on.
+func clip(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 64 {
+		s = s[:64]
+	}
+	return s
+}
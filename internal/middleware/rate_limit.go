@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/ratelimit"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PlanLookup resolves the plan a user is on (models.Plan*).
+type PlanLookup interface {
+	PlanForUser(ctx context.Context, userID bson.ObjectID) (string, error)
+}
+
+// planCacheTTL bounds how long a user's plan is remembered, so joining an
+// organization takes up to a minute to raise the limit.
+const planCacheTTL = time.Minute
+
+type cachedPlan struct {
+	plan    string
+	expires time.Time
+}
+
+// RateLimit enforces the caller's tier limit with the shared limiter and
+// reports it in X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset
+// (Unix seconds) and X-RateLimit-Tier. Users are limited by plan, integration
+// keys by key. If the limiter can't be reached the request is let through.
+// Must be mounted after JWTAuth or IntegrationAuth.
+func RateLimit(limiter *ratelimit.Limiter, tiers ratelimit.Tiers, plans PlanLookup) func(http.Handler) http.Handler {
+	var cache sync.Map // user ID -> cachedPlan
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tier, key string
+			if userID := GetUserID(r.Context()); userID != "" {
+				entry, ok := cache.Load(userID)
+				if !ok || time.Now().After(entry.(cachedPlan).expires) {
+					id, err := bson.ObjectIDFromHex(userID)
+					if err != nil {
+						http.Error(w, `{"error":"invalid user_id in token"}`, http.StatusUnauthorized)
+						return
+					}
+					plan, err := plans.PlanForUser(r.Context(), id)
+					if err != nil {
+						log.Printf("Error resolving plan for rate limit: %v", err)
+						plan = models.PlanIndividual
+					}
+					entry = cachedPlan{plan: plan, expires: time.Now().Add(planCacheTTL)}
+					cache.Store(userID, entry)
+				}
+				tier, key = ratelimit.TierFree, "api:user:"+userID
+				if entry.(cachedPlan).plan == models.PlanOrganization {
+					tier = ratelimit.TierPro
+				}
+			} else if name := GetAdminName(r.Context()); name != "" {
+				tier, key = ratelimit.TierAPIKey, "api:key:"+name
+			}
+
+			limit, ok := tiers[tier]
+			if !ok || limit.Limit == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			result, err := limiter.Allow(r.Context(), key, limit.Limit, limit.Window)
+			if err != nil {
+				log.Printf("Error checking rate limit: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+			w.Header().Set("X-RateLimit-Tier", tier)
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(result.ResetAt).Seconds())+1))
+				http.Error(w, `{"error":"rate limit exceeded","code":"rate_limited"}`, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
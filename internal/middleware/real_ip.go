@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultTrustedProxies covers loopback and private ranges, where our load
+// balancer forwards from.
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// ParseCIDRs parses proxy ranges; bare IPs are treated as single hosts.
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", v)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// RealIP replaces chi's RealIP, which believes any X-Forwarded-For. Forwarding
+// headers are only honoured when the connection comes from a trusted proxy,
+// and X-Forwarded-For is read right to left, skipping trusted hops, so a
+// client can't spoof its address by prepending entries. RemoteAddr is
+// rewritten to the resolved client IP for rate limiting, abuse detection,
+// geo and logs.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	isTrusted := func(ip net.IP) bool {
+		for _, n := range trusted {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := clientIPFromProxies(r, isTrusted); ip != "" {
+				r.RemoteAddr = ip
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIPFromProxies(r *http.Request, isTrusted func(net.IP) bool) string {
+	peer := net.ParseIP(ClientIP(r))
+	if peer == nil || !isTrusted(peer) {
+		return ""
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break // garbage from an untrusted hop; stop at the last good one
+			}
+			if !isTrusted(ip) || i == 0 {
+				return ip.String()
+			}
+		}
+		return ""
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
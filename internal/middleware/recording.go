@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestRecorder captures served requests for later replay.
+type RequestRecorder interface {
+	// Sample reports whether to record the request, before its body is read.
+	Sample(r *http.Request) bool
+	Capture(r *http.Request, route string, body []byte, status int, elapsed time.Duration)
+}
+
+// RecordRequests hands a sample of requests, with their bodies and response
+// statuses, to recorder. At most maxBody+1 bytes of a body are buffered, so
+// the recorder can tell an oversized body from a complete one; the handler
+// still reads the whole body.
+func RecordRequests(recorder RequestRecorder, maxBody int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !recorder.Sample(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				head, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+				if err != nil {
+					http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+					return
+				}
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+				body = head
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+			next.ServeHTTP(ww, r)
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			recorder.Capture(r, route, body, status, time.Since(start))
+		})
+	}
+}
+
+// readCloser re-attaches the original body's Close to a replacement reader.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"rizon-backend/internal/redact"
+	"rizon-backend/internal/sentry"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Recoverer replaces chi's Recoverer: a panic is logged with its stack and
+// request context, reported to Sentry, and answered with the usual JSON error
+// plus the request ID so clients can quote it. reporter may be nil.
+// It must run after chi's RequestID middleware.
+func Recoverer(reporter *sentry.Client) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					// Deliberate abort of the response; let net/http handle it
+					panic(rec)
+				}
+
+				requestID := chimiddleware.GetReqID(r.Context())
+				log.Printf("🔥 Panic serving %s %s (request_id=%s, ip=%s): %v\n%s",
+					r.Method, redact.URL(r.URL), requestID, ClientIP(r), rec, debug.Stack())
+
+				reporter.Capture(sentry.Event{
+					Type:    "panic",
+					Message: fmt.Sprint(rec),
+					Frames:  panicFrames(),
+					Request: r,
+					Tags:    map[string]string{"request_id": requestID},
+				})
+
+				if r.Header.Get("Connection") == "Upgrade" {
+					// Hijacked connections can't take a response
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{
+					"error":      "internal server error",
+					"request_id": requestID,
+				})
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// panicFrames returns the stack from the panicking call outwards, skipping
+// the runtime's panic machinery and this middleware.
+func panicFrames() []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []runtime.Frame
+	for {
+		f, more := frames.Next()
+		if f.Function != "" && !strings.HasPrefix(f.Function, "runtime.") {
+			out = append(out, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
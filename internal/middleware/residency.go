@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RegionLookup resolves the data residency region a user's data belongs in.
+type RegionLookup interface {
+	RegionForUser(ctx context.Context, userID bson.ObjectID) (string, error)
+}
+
+// regionCacheTTL bounds how long a user's region is remembered. Regions are
+// fixed per organization, so this only delays joining or leaving one.
+const regionCacheTTL = time.Minute
+
+type cachedRegion struct {
+	region  string
+	expires time.Time
+}
+
+// DataResidency routes the signed-in user's regional data to their
+// organization's region (see database.WithRegion). Requests are refused
+// while that region isn't configured, rather than stored elsewhere. Must be
+// mounted after JWTAuth.
+func DataResidency(lookup RegionLookup) func(http.Handler) http.Handler {
+	var cache sync.Map // user ID -> cachedRegion
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := GetUserID(r.Context())
+
+			entry, ok := cache.Load(userID)
+			if !ok || time.Now().After(entry.(cachedRegion).expires) {
+				id, err := bson.ObjectIDFromHex(userID)
+				if err != nil {
+					http.Error(w, `{"error":"invalid user_id in token"}`, http.StatusUnauthorized)
+					return
+				}
+				region, err := lookup.RegionForUser(r.Context(), id)
+				if err != nil {
+					log.Printf("Error resolving data region: %v", err)
+					http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+					return
+				}
+				entry = cachedRegion{region: region, expires: time.Now().Add(regionCacheTTL)}
+				cache.Store(userID, entry)
+			}
+
+			region := entry.(cachedRegion).region
+			if region == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !database.HasRegion(region) {
+				log.Printf("⚠️  Data region %q is not configured", region)
+				http.Error(w, `{"error":"service unavailable","code":"region_unavailable"}`, http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(database.WithRegion(r.Context(), region)))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rizon-backend/internal/database"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// ResponseCache keeps the signed-in user's successful response to one GET
+// route for a short time. Only the body and content type are kept; headers
+// set by outer middleware are fresh on every request. It is in-process: Invalidate only reaches this
+// instance, so the TTL bounds how stale another instance can be, and reads
+// after the client's own write (see ReadYourWrites) always skip it.
+type ResponseCache struct {
+	name    string
+	ttl     time.Duration
+	entries sync.Map // user ID -> cachedResponse
+
+	// epoch moves on every invalidation so a response computed before a
+	// write can't be stored after it.
+	epoch         atomic.Uint64
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+}
+
+type cachedResponse struct {
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+// ResponseCacheStats is one cache's counters since startup.
+type ResponseCacheStats struct {
+	Name          string  `json:"name"`
+	TTLSeconds    float64 `json:"ttl_seconds"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`
+	Invalidations int64   `json:"invalidations"`
+}
+
+var (
+	responseCachesMu sync.Mutex
+	responseCaches   = map[string]*ResponseCache{}
+)
+
+// NewResponseCache creates a cache named for its route in ResponseCaches.
+// A ttl of zero or less disables caching.
+func NewResponseCache(name string, ttl time.Duration) *ResponseCache {
+	c := &ResponseCache{name: name, ttl: ttl}
+	responseCachesMu.Lock()
+	responseCaches[name] = c
+	responseCachesMu.Unlock()
+	return c
+}
+
+// Handler serves cached responses. Must be mounted after JWTAuth.
+func (c *ResponseCache) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := GetUserID(r.Context())
+		if c.ttl <= 0 || userID == "" || r.Method != http.MethodGet || database.InSandbox(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := time.Now()
+		if !database.PrimaryReads(r.Context()) {
+			if entry, ok := c.entries.Load(userID); ok && now.Before(entry.(cachedResponse).expires) {
+				c.hits.Add(1)
+				cached := entry.(cachedResponse)
+				w.Header().Set("Content-Type", cached.contentType)
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(http.StatusOK)
+				w.Write(cached.body)
+				return
+			}
+		}
+		c.misses.Add(1)
+
+		epoch := c.epoch.Load()
+		var buf bytes.Buffer
+		w.Header().Set("X-Cache", "MISS")
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(&buf)
+		next.ServeHTTP(ww, r)
+
+		if ww.Status() != http.StatusOK || c.epoch.Load() != epoch {
+			return
+		}
+		c.entries.Store(userID, cachedResponse{
+			contentType: w.Header().Get("Content-Type"),
+			body:        buf.Bytes(),
+			expires:     now.Add(c.ttl),
+		})
+	})
+}
+
+// Invalidate drops the user's cached response.
+func (c *ResponseCache) Invalidate(userID string) {
+	c.epoch.Add(1)
+	c.invalidations.Add(1)
+	c.entries.Delete(userID)
+}
+
+// Stats returns the cache's counters.
+func (c *ResponseCache) Stats() ResponseCacheStats {
+	hits, misses := c.hits.Load(), c.misses.Load()
+	stats := ResponseCacheStats{
+		Name:          c.name,
+		TTLSeconds:    c.ttl.Seconds(),
+		Hits:          hits,
+		Misses:        misses,
+		Invalidations: c.invalidations.Load(),
+	}
+	if hits+misses > 0 {
+		stats.HitRate = float64(hits) / float64(hits+misses)
+	}
+	return stats
+}
+
+// ResponseCaches returns the counters of every response cache on this
+// instance, by name.
+func ResponseCaches() []ResponseCacheStats {
+	responseCachesMu.Lock()
+	defer responseCachesMu.Unlock()
+	out := make([]ResponseCacheStats, 0, len(responseCaches))
+	for _, c := range responseCaches {
+		out = append(out, c.Stats())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+)
+
+// SandboxKeyLookup resolves sandbox keys by their SHA-256 hash.
+type SandboxKeyLookup interface {
+	FindSandboxByHash(ctx context.Context, hash string) (*models.AdminKey, error)
+}
+
+// Sandbox moves requests carrying a sandbox key in X-Sandbox-Key into that
+// key's sandbox, so the rest of the chain (user auth included) runs as
+// usual but writes land in the sandbox collections. Requests without the
+// header are untouched; an unknown key is refused rather than silently
+// hitting real data.
+func Sandbox(keys SandboxKeyLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-Sandbox-Key")
+			if provided == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := keys.FindSandboxByHash(r.Context(), HashAdminKey(provided))
+			if err != nil {
+				log.Printf("Error looking up sandbox key: %v", err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if key == nil {
+				http.Error(w, `{"error":"invalid sandbox key","code":"invalid_sandbox_key"}`, http.StatusUnauthorized)
+				return
+			}
+
+			w.Header().Set("X-Sandbox", key.Prefix)
+			ctx := context.WithValue(r.Context(), AdminNameKey, key.Name)
+			next.ServeHTTP(w, r.WithContext(database.WithSandbox(ctx, key.ID.Hex())))
+		})
+	}
+}
+
+// RequireSandbox authenticates routes that only make sense inside a
+// sandbox. Must be mounted after Sandbox.
+func RequireSandbox(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !database.InSandbox(r.Context()) {
+			http.Error(w, `{"error":"missing sandbox key"}`, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const SCIMOrgKey contextKey = "scim_org"
+
+// SCIMTokenLookup resolves organizations by the SHA-256 hash of their SCIM token.
+type SCIMTokenLookup interface {
+	FindBySCIMTokenHash(ctx context.Context, hash string) (*models.Organization, error)
+}
+
+// SCIMAuth authenticates an organization's identity provider by the bearer
+// token in the Authorization header. Handlers only see that organization's
+// users.
+func SCIMAuth(orgs SCIMTokenLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				http.Error(w, `{"error":"missing bearer token"}`, http.StatusUnauthorized)
+				return
+			}
+
+			org, err := orgs.FindBySCIMTokenHash(r.Context(), HashAdminKey(token))
+			if err != nil {
+				log.Printf("Error looking up SCIM token: %v", err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if org == nil {
+				http.Error(w, `{"error":"invalid bearer token"}`, http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), SCIMOrgKey, org.ID)))
+		})
+	}
+}
+
+// GetSCIMOrg extracts the organization authenticated by SCIMAuth.
+func GetSCIMOrg(ctx context.Context) bson.ObjectID {
+	id, _ := ctx.Value(SCIMOrgKey).(bson.ObjectID)
+	return id
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ConsentLookup returns a user's most recent consent.
+type ConsentLookup interface {
+	Latest(ctx context.Context, userID bson.ObjectID) (*models.Consent, error)
+}
+
+// RequireTerms blocks users whose accepted terms version is older than
+// required with 451 and code "terms_update_required" until they accept the
+// current terms via POST /user/consents. An empty required version disables
+// the check. Must be mounted after JWTAuth; keep the consent routes outside it.
+func RequireTerms(consents ConsentLookup, required string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if required == "" {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := bson.ObjectIDFromHex(GetUserID(r.Context()))
+			if err != nil {
+				http.Error(w, `{"error":"invalid user_id in token"}`, http.StatusUnauthorized)
+				return
+			}
+
+			latest, err := consents.Latest(r.Context(), userID)
+			if err != nil {
+				log.Printf("Error loading consent: %v", err)
+				http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+				return
+			}
+			if latest == nil || models.CompareVersions(latest.TermsVersion, required) < 0 {
+				http.Error(w, `{"error":"updated terms must be accepted","code":"terms_update_required"}`, http.StatusUnavailableForLegalReasons)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
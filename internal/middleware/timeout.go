@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const timeoutBody = `{"error":"the request took too long, try again","code":"timeout"}`
+
+// RequestTimeout bounds how long one route may take. Handlers see the
+// deadline on their request context, so Mongo queries and outbound calls
+// give up, and a handler that hasn't answered by then gets a 503 with code
+// "timeout" instead of its own error. Responses already started are left
+// alone; routes that stream should not have a timeout.
+type RequestTimeout struct {
+	route    string
+	timeout  time.Duration
+	requests atomic.Int64
+	timeouts atomic.Int64
+}
+
+// RequestTimeoutStats is one route's counters since startup.
+type RequestTimeoutStats struct {
+	Route          string  `json:"route"`
+	TimeoutSeconds float64 `json:"timeout_seconds"`
+	Requests       int64   `json:"requests"`
+	Timeouts       int64   `json:"timeouts"`
+}
+
+var (
+	requestTimeoutsMu sync.Mutex
+	requestTimeouts   = map[string]*RequestTimeout{}
+)
+
+// NewRequestTimeout creates the timeout for route and lists it in
+// RequestTimeouts.
+func NewRequestTimeout(route string, timeout time.Duration) *RequestTimeout {
+	t := &RequestTimeout{route: route, timeout: timeout}
+	requestTimeoutsMu.Lock()
+	requestTimeouts[route] = t
+	requestTimeoutsMu.Unlock()
+	return t
+}
+
+func (t *RequestTimeout) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.requests.Add(1)
+		ctx, cancel := context.WithTimeout(r.Context(), t.timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{ResponseWriter: w, ctx: ctx}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+
+		if !tw.wroteHeader && ctx.Err() == context.DeadlineExceeded {
+			tw.WriteHeader(http.StatusOK) // answers with the timeout instead
+		}
+		if tw.timedOut {
+			t.timeouts.Add(1)
+			log.Printf("⏱️  %s timed out after %s", t.route, t.timeout)
+		}
+	})
+}
+
+// Stats returns the route's counters.
+func (t *RequestTimeout) Stats() RequestTimeoutStats {
+	return RequestTimeoutStats{
+		Route:          t.route,
+		TimeoutSeconds: t.timeout.Seconds(),
+		Requests:       t.requests.Load(),
+		Timeouts:       t.timeouts.Load(),
+	}
+}
+
+// RequestTimeouts returns the counters of every route with a timeout on this
+// instance, most timeouts first.
+func RequestTimeouts() []RequestTimeoutStats {
+	requestTimeoutsMu.Lock()
+	defer requestTimeoutsMu.Unlock()
+	out := make([]RequestTimeoutStats, 0, len(requestTimeouts))
+	for _, t := range requestTimeouts {
+		out = append(out, t.Stats())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Timeouts != out[j].Timeouts {
+			return out[i].Timeouts > out[j].Timeouts
+		}
+		return out[i].Route < out[j].Route
+	})
+	return out
+}
+
+// timeoutWriter swaps the handler's response for the timeout error when the
+// deadline passed before the handler started answering, typically with the
+// 500 it wrote after its query was cancelled.
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	if tw.ctx.Err() == context.DeadlineExceeded {
+		tw.timedOut = true
+		h := tw.ResponseWriter.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Type", "application/json")
+		h.Set("Retry-After", "1")
+		tw.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+		tw.ResponseWriter.Write([]byte(timeoutBody))
+		return
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		return 0, context.DeadlineExceeded
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Admin permissions, granted per API key as "<resource>:<action>".
+// "<resource>:*" grants every action on a resource and "*" grants everything.
+const (
+	PermFeedbackRead       = "feedback:read"
+	PermFeedbackWrite      = "feedback:write"
+	PermUsersRead          = "users:read"
+	PermUsersWrite         = "users:write"
+	PermBillingRead        = "billing:read"
+	PermQuotasRead         = "quotas:read"
+	PermQuotasWrite        = "quotas:write"
+	PermNotificationsRead  = "notifications:read"
+	PermNotificationsWrite = "notifications:write"
+	PermOpsWrite           = "ops:write"
+	PermFlagsRead          = "flags:read"
+	PermFlagsWrite         = "flags:write"
+	PermIntegrationsRead   = "integrations:read"
+	PermKeysManage         = "keys:manage"
+)
+
+// KnownPermissions lists every grantable permission.
+var KnownPermissions = []string{
+	PermFeedbackRead, PermFeedbackWrite,
+	PermUsersRead, PermUsersWrite,
+	PermBillingRead,
+	PermQuotasRead, PermQuotasWrite,
+	PermNotificationsRead, PermNotificationsWrite,
+	PermOpsWrite,
+	PermFlagsRead, PermFlagsWrite,
+	PermIntegrationsRead,
+	PermKeysManage,
+}
+
+// AdminKey is a scoped admin API key, or a sandbox key. Only the SHA-256 of the key is stored.
+type AdminKey struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string        `bson:"name" json:"name"`
+	KeyHash     string        `bson:"key_hash" json:"-"`
+	Prefix      string        `bson:"prefix" json:"prefix"` // first characters, to identify keys in the UI
+	Permissions []string      `bson:"permissions" json:"permissions"`
+	// Sandbox keys carry no permissions; they only send requests to the
+	// sandbox collections (see database.WithSandbox) via X-Sandbox-Key
+	Sandbox   bool       `bson:"sandbox,omitempty" json:"sandbox,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// HasPermission reports whether the granted set allows perm.
+func HasPermission(granted []string, perm string) bool {
+	resource, _, _ := strings.Cut(perm, ":")
+	for _, g := range granted {
+		if g == "*" || g == perm || g == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidPermission reports whether perm is a known permission or wildcard.
+func IsValidPermission(perm string) bool {
+	if perm == "*" {
+		return true
+	}
+	for _, known := range KnownPermissions {
+		resource, _, _ := strings.Cut(known, ":")
+		if perm == known || perm == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// AdminNote is internal support context about a user ("refund issued",
+// "beta tester"). Notes live in their own collection and are only served
+// from admin routes.
+type AdminNote struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	Author    string        `bson:"author" json:"author"` // admin key name
+	Text      string        `bson:"text" json:"text"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
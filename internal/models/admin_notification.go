@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Admin notification kinds
+const (
+	AdminNotificationFeedback       = "feedback"        // new feedback was submitted
+	AdminNotificationAbuse          = "abuse"           // abuse detection blocked an IP
+	AdminNotificationJobFailed      = "job_failed"      // a background job ran out of attempts
+	AdminNotificationDeliveryFailed = "delivery_failed" // a Slack message couldn't be delivered
+)
+
+// AdminNotification is an entry in the dashboard's notification center.
+// Read state is per admin, by key name.
+type AdminNotification struct {
+	ID    bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind  string        `bson:"kind" json:"kind"`
+	Title string        `bson:"title" json:"title"`
+	Body  string        `bson:"body,omitempty" json:"body,omitempty"`
+	// IDs the dashboard links to, e.g. feedback_id, job_id or ip
+	Data      map[string]string `bson:"data,omitempty" json:"data,omitempty"`
+	ReadBy    []string          `bson:"read_by,omitempty" json:"-"`
+	Read      bool              `bson:"-" json:"read"`
+	CreatedAt time.Time         `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Blob is stored file content, keyed by the SHA-256 of its bytes so identical
+// uploads are stored once and referenced by many attachments.
+type Blob struct {
+	SHA256           string    `bson:"_id"`
+	Data             []byte    `bson:"data"`
+	Size             int       `bson:"size"`
+	CreatedAt        time.Time `bson:"created_at"`
+	LastReferencedAt time.Time `bson:"last_referenced_at"` // bumped on every upload; protects new blobs from GC
+}
+
+// Attachment is a user's reference to a blob. Quota is charged per
+// attachment, so dedupe saves storage without changing what users see.
+type Attachment struct {
+	ID          bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      bson.ObjectID  `bson:"user_id" json:"-"`
+	SHA256      string         `bson:"sha256" json:"sha256"`
+	Filename    string         `bson:"filename" json:"filename"`
+	ContentType string         `bson:"content_type" json:"content_type"`
+	Size        int            `bson:"size" json:"size"`
+	Variants    []ImageVariant `bson:"variants,omitempty" json:"variants,omitempty"` // images only
+	CreatedAt   time.Time      `bson:"created_at" json:"created_at"`
+
+	URL string `bson:"-" json:"url,omitempty"` // signed download link
+}
+
+// ImageVariant is a resized rendition of an image attachment, stored as its
+// own blob.
+type ImageVariant struct {
+	Name        string `bson:"name" json:"name"` // thumbnail, full
+	SHA256      string `bson:"sha256" json:"-"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	Width       int    `bson:"width" json:"width"`
+	Height      int    `bson:"height" json:"height"`
+	Size        int    `bson:"size" json:"size"`
+
+	URL string `bson:"-" json:"url,omitempty"`
+}
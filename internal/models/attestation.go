@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Attestation records the outcome of a Play Integrity / App Attest check.
+// Passing attestations carry a single-use PassToken that gates /auth/request.
+type Attestation struct {
+	ID        bson.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Platform  string            `bson:"platform" json:"platform"`
+	Passed    bool              `bson:"passed" json:"passed"`
+	Reasons   []string          `bson:"reasons,omitempty" json:"reasons,omitempty"`
+	Details   map[string]string `bson:"details,omitempty" json:"details,omitempty"`
+	KeyID     string            `bson:"key_id,omitempty" json:"key_id,omitempty"`
+	IP        string            `bson:"ip" json:"ip"`
+	UserAgent string            `bson:"user_agent" json:"user_agent"`
+	PassToken string            `bson:"pass_token,omitempty" json:"-"`
+	IsUsed    bool              `bson:"is_used" json:"is_used"`
+	ExpiresAt time.Time         `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time         `bson:"created_at" json:"created_at"`
+}
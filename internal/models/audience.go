@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Plans an audience can target. Rizon has no paid tiers for individuals, so
+// the plan is whether the user belongs to an organization.
+const (
+	PlanIndividual   = "individual"
+	PlanOrganization = "organization"
+)
+
+// Plans lists every plan an audience can target.
+var Plans = []string{PlanIndividual, PlanOrganization}
+
+// AudienceFilter selects users for announcements and campaigns. Empty fields
+// don't filter; set fields must all match. Only active accounts are ever
+// selected.
+type AudienceFilter struct {
+	SignedUpAfter  *time.Time `bson:"signed_up_after,omitempty" json:"signed_up_after,omitempty"`
+	SignedUpBefore *time.Time `bson:"signed_up_before,omitempty" json:"signed_up_before,omitempty"`
+	Plans          []string   `bson:"plans,omitempty" json:"plans,omitempty"`
+	// ISO 3166-1 alpha-2; the self-reported age country, else the signup country
+	Countries []string `bson:"countries,omitempty" json:"countries,omitempty"`
+	// Inclusive bounds on the app version last seen, e.g. "2.4.0"
+	MinAppVersion string `bson:"min_app_version,omitempty" json:"min_app_version,omitempty"`
+	MaxAppVersion string `bson:"max_app_version,omitempty" json:"max_app_version,omitempty"`
+}
+
+// Audience is a saved, named filter.
+type Audience struct {
+	ID        bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Name      string         `bson:"name" json:"name"`
+	Filter    AudienceFilter `bson:"filter" json:"filter"`
+	CreatedBy string         `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time      `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `bson:"updated_at" json:"updated_at"`
+}
+
+// AppVersionParts splits "major.minor.patch" into three numbers, padding
+// missing parts with zero. A build or pre-release suffix ("2.4.0-beta") is
+// ignored.
+func AppVersionParts(version string) ([]int, bool) {
+	version, _, _ = strings.Cut(strings.TrimPrefix(strings.TrimSpace(version), "v"), "-")
+	fields := strings.Split(version, ".")
+	if version == "" || len(fields) > 3 {
+		return nil, false
+	}
+	parts := []int{0, 0, 0}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
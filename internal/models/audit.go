@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Audit actions
+const (
+	AuditUserMerged    = "user.merged"
+	AuditLoginLinkSent = "user.login_link_sent"
+)
+
+// AuditEntry records a sensitive admin operation.
+type AuditEntry struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Action    string        `bson:"action" json:"action"`
+	Actor     string        `bson:"actor" json:"actor"` // admin key name
+	TargetID  string        `bson:"target_id" json:"target_id"`
+	Details   bson.M        `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
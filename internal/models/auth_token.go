@@ -13,8 +13,18 @@ type AuthToken struct {
 	ExpiresAt time.Time     `bson:"expires_at" json:"expires_at"`
 	IsUsed    bool          `bson:"is_used" json:"is_used"`
 	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	// Set when a newer link for the same email replaced this one
+	SupersededAt *time.Time `bson:"superseded_at,omitempty" json:"superseded_at,omitempty"`
+	// Consent given on the sign-up screen, recorded once the link is verified
+	Consent *PendingConsent `bson:"consent,omitempty" json:"-"`
+	// Numeric code sent alongside the link, for typing into the app when the
+	// link won't open it. Empty if the app didn't ask for one. Only set on a
+	// token just created, for the email; the database keeps CodeHash.
+	Code         string `bson:"-" json:"-"`
+	CodeHash     string `bson:"code_hash,omitempty" json:"-"`
+	CodeAttempts int    `bson:"code_attempts,omitempty" json:"-"` // codes entered so far
 }
 
-func (t *AuthToken) IsExpired() bool {
-	return time.Now().After(t.ExpiresAt)
+func (t *AuthToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Consent sources
+const (
+	ConsentSourceSignup = "signup"
+	ConsentSourceApp    = "app"
+)
+
+// Consent is an append-only record of what a user agreed to. Records are never
+// updated, so the history proves which document versions were accepted when.
+type Consent struct {
+	ID             bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID         bson.ObjectID `bson:"user_id" json:"user_id"`
+	TermsVersion   string        `bson:"terms_version" json:"terms_version"`
+	PrivacyVersion string        `bson:"privacy_version" json:"privacy_version"`
+	MarketingOptIn bool          `bson:"marketing_opt_in" json:"marketing_opt_in"`
+	IP             string        `bson:"ip" json:"ip"`
+	UserAgent      string        `bson:"user_agent" json:"user_agent"`
+	Source         string        `bson:"source" json:"source"`
+	CreatedAt      time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// PendingConsent is captured with a login request and recorded against the
+// account once the login link is verified.
+type PendingConsent struct {
+	TermsVersion   string    `bson:"terms_version"`
+	PrivacyVersion string    `bson:"privacy_version"`
+	MarketingOptIn bool      `bson:"marketing_opt_in"`
+	IP             string    `bson:"ip"`
+	UserAgent      string    `bson:"user_agent"`
+	GivenAt        time.Time `bson:"given_at"`
+}
+
+// LegalVersions are the current versions of the terms and privacy policy.
+type LegalVersions struct {
+	Terms   string `json:"terms_version"`
+	Privacy string `json:"privacy_version"`
+}
+
+// Accepts reports whether the consent covers the current versions.
+func (v LegalVersions) Accepts(c *Consent) bool {
+	return c != nil && c.TermsVersion == v.Terms && c.PrivacyVersion == v.Privacy
+}
+
+// CompareVersions orders document versions. Dotted numeric versions ("2",
+// "2.1") compare numerically; anything else (e.g. ISO dates) lexically.
+func CompareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+		if sa == "" {
+			na, errA = 0, nil
+		}
+		if sb == "" {
+			nb, errB = 0, nil
+		}
+		if errA != nil || errB != nil {
+			return strings.Compare(a, b)
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Challenge purposes.
+const (
+	CredentialChallengeRegister = "register"
+	CredentialChallengeLogin    = "login"
+)
+
+// Credential is a passkey (WebAuthn public key credential) a signed-in user
+// registered, so they can sign in again with Face ID or a fingerprint
+// instead of a magic link.
+type Credential struct {
+	ID     bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID bson.ObjectID `bson:"user_id" json:"-"`
+	// Base64url, as the authenticator reports it on sign-in
+	CredentialID string     `bson:"credential_id" json:"credential_id"`
+	PublicKey    []byte     `bson:"public_key" json:"-"` // COSE_Key
+	Algorithm    int64      `bson:"algorithm" json:"algorithm"`
+	SignCount    int64      `bson:"sign_count" json:"-"`
+	AAGUID       string     `bson:"aaguid,omitempty" json:"aaguid,omitempty"` // authenticator model, hex
+	Transports   []string   `bson:"transports,omitempty" json:"transports,omitempty"`
+	BackedUp     bool       `bson:"backed_up" json:"backed_up"` // synced across the user's devices
+	Name         string     `bson:"name" json:"name"`           // chosen by the user, e.g. "iPhone"
+	LastUsedAt   *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `bson:"created_at" json:"created_at"`
+}
+
+// CredentialChallenge is an outstanding passkey ceremony. Registration
+// challenges belong to the signed-in user; sign-in challenges to nobody
+// until a passkey answers them.
+type CredentialChallenge struct {
+	Challenge string         `bson:"_id" json:"challenge"` // base64url
+	Purpose   string         `bson:"purpose" json:"purpose"`
+	UserID    *bson.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	ExpiresAt time.Time      `bson:"expires_at" json:"expires_at"`
+}
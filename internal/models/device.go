@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ActiveDeviceWindow is how recently a device must have been seen to count
+// as active.
+const ActiveDeviceWindow = 30 * 24 * time.Hour
+
+// Device is one app install of a user, identified by the X-Device-ID header
+// the app generates on first launch.
+type Device struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      bson.ObjectID `bson:"user_id" json:"-"`
+	DeviceID    string        `bson:"device_id" json:"device_id"`
+	Client      ClientInfo    `bson:"client" json:"client"` // as of the last request
+	FirstSeenAt time.Time     `bson:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt  time.Time     `bson:"last_seen_at" json:"last_seen_at"`
+
+	Active bool `bson:"-" json:"active"`
+}
+
+// DailyActiveUsers counts distinct users with at least one authenticated
+// request on a day.
+type DailyActiveUsers struct {
+	Day   string `bson:"_id" json:"day"` // YYYY-MM-DD (UTC)
+	Users int64  `bson:"users" json:"users"`
+}
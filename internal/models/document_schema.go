@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Names of the admin-managed schemas checked at ingestion. A document kind
+// with no saved schema is accepted as-is.
+const (
+	SchemaOnboardingAnswers = "onboarding_answers"
+)
+
+// DocumentSchema is a JSON Schema an admin has set for a kind of free-form
+// document. The schema is kept as JSON text since its keywords ("$schema",
+// "$id") aren't safe Mongo field names.
+type DocumentSchema struct {
+	Name      string    `bson:"_id" json:"name"`
+	Schema    string    `bson:"schema" json:"-"`
+	Version   int       `bson:"version" json:"version"`
+	UpdatedBy string    `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// EmailSuppression stops queued email (campaigns, reminders and the like) to
+// an address. Transactional email such as login links still goes out.
+type EmailSuppression struct {
+	Email     string    `bson:"_id" json:"email"` // lowercased
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// EmailThrottle limits queued (non-transactional) email so bulk sends stay
+// inside provider rate limits and out of recipients' nights. Login and other
+// transactional emails are never throttled.
+type EmailThrottle struct {
+	// Sends per minute keyed by provider name; "*" covers providers not
+	// listed. Zero or missing means unlimited.
+	MaxPerMinute map[string]int `bson:"max_per_minute" json:"max_per_minute"`
+	QuietHours   []QuietHours   `bson:"quiet_hours" json:"quiet_hours"`
+	UpdatedAt    time.Time      `bson:"updated_at" json:"updated_at"`
+	UpdatedBy    string         `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+}
+
+// QuietHours is a daily window, in the recipient's local time, during which
+// queued email is held until the window ends.
+type QuietHours struct {
+	Region string `bson:"region" json:"region"` // ISO 3166-1 alpha-2, or "*" for everywhere else
+	Start  string `bson:"start" json:"start"`   // HH:MM, may be after End to span midnight
+	End    string `bson:"end" json:"end"`       // HH:MM
+}
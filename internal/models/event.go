@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Event types
+const (
+	// EventExperimentExposure is kept once per user and variant; repeat
+	// exposures only move LastAt.
+	EventExperimentExposure = "experiment_exposure"
+)
+
+// Event is a product analytics event about a user.
+type Event struct {
+	ID         bson.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Type       string            `bson:"type" json:"type"`
+	UserID     bson.ObjectID     `bson:"user_id" json:"user_id"`
+	Properties map[string]string `bson:"properties,omitempty" json:"properties,omitempty"`
+	CreatedAt  time.Time         `bson:"created_at" json:"created_at"`
+	LastAt     time.Time         `bson:"last_at,omitempty" json:"last_at,omitempty"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Experiment statuses
+const (
+	ExperimentDraft   = "draft"   // defined, nobody assigned
+	ExperimentRunning = "running" // assigning and logging exposures
+	ExperimentStopped = "stopped" // everyone gets the app's default again
+)
+
+// ExperimentStatuses lists every valid experiment status.
+var ExperimentStatuses = []string{ExperimentDraft, ExperimentRunning, ExperimentStopped}
+
+// Experiment is a server-side A/B test. Users are assigned deterministically
+// from their ID, so an assignment holds across devices and restarts.
+type Experiment struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Key         string        `bson:"key" json:"key"`
+	Description string        `bson:"description,omitempty" json:"description,omitempty"`
+	Status      string        `bson:"status" json:"status"`
+	// Share of eligible users enrolled, 0–100. Raising it only adds users.
+	TrafficPercent int                 `bson:"traffic_percent" json:"traffic_percent"`
+	Variants       []ExperimentVariant `bson:"variants" json:"variants"`
+	// Only users the feature flag is on for are eligible (optional)
+	FlagKey   string    `bson:"flag_key,omitempty" json:"flag_key,omitempty"`
+	UpdatedBy string    `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ExperimentVariant is one arm of an experiment. Changing weights reassigns
+// enrolled users, so set them before starting.
+type ExperimentVariant struct {
+	Key    string                 `bson:"key" json:"key"`
+	Weight int                    `bson:"weight" json:"weight"`
+	Config map[string]interface{} `bson:"config,omitempty" json:"config,omitempty"` // handed to the client as-is
+}
+
+// ExperimentAssignment is the variant a user gets in a running experiment.
+type ExperimentAssignment struct {
+	Experiment string                 `json:"experiment"`
+	Variant    string                 `json:"variant"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+}
+
+// ExperimentResult counts the users exposed to one variant.
+type ExperimentResult struct {
+	Variant         string    `bson:"_id" json:"variant"`
+	Users           int64     `bson:"users" json:"users"`
+	FirstExposureAt time.Time `bson:"first_exposure_at" json:"first_exposure_at"`
+	LastExposureAt  time.Time `bson:"last_exposure_at" json:"last_exposure_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Export statuses
+const (
+	ExportPending = "pending" // queued
+	ExportReady   = "ready"   // download link emailed
+	ExportFailed  = "failed"  // job gave up
+)
+
+// Export is a user's personal data export. The generated file is stored on
+// the document and removed by a TTL index when the download link expires.
+type Export struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectID `bson:"user_id" json:"-"`
+	JobID     bson.ObjectID `bson:"job_id" json:"-"`
+	Status    string        `bson:"status" json:"status"`
+	Data      []byte        `bson:"data,omitempty" json:"-"`
+	Size      int           `bson:"size,omitempty" json:"size,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	ReadyAt   *time.Time    `bson:"ready_at,omitempty" json:"ready_at,omitempty"`
+	ExpiresAt time.Time     `bson:"expires_at" json:"expires_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FeatureFlag gates a set of routes. A disabled flag is off for everyone;
+// an enabled flag is on for listed users plus a stable percentage of the rest.
+type FeatureFlag struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Key        string        `bson:"key" json:"key"`
+	Enabled    bool          `bson:"enabled" json:"enabled"`
+	Percentage int           `bson:"percentage" json:"percentage"` // 0–100
+	UserIDs    []string      `bson:"user_ids" json:"user_ids"`     // internal testers, always on
+	UpdatedAt  time.Time     `bson:"updated_at" json:"updated_at"`
+}
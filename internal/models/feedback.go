@@ -1,16 +1,175 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+const (
+	FeedbackStatusOpen     = "open"
+	FeedbackStatusResolved = "resolved"
+)
+
+const (
+	ReactionUp   = "up"
+	ReactionDown = "down"
+)
+
+// Feedback sources. Documents without a source came from the app.
+const (
+	FeedbackSourceApp = "app"
+	FeedbackSourceWeb = "web" // public widget; UserID is zero
+)
+
+const (
+	FeedbackCategoryBug   = "bug"
+	FeedbackCategoryIdea  = "idea"
+	FeedbackCategoryOther = "other"
+)
+
+// Named ratings a submission can carry alongside the overall rating.
+const (
+	RatingEaseOfUse   = "ease_of_use"
+	RatingPerformance = "performance"
+	RatingDesign      = "design"
+)
+
+// FeedbackRatingNames lists the named ratings, in display order.
+var FeedbackRatingNames = []string{RatingEaseOfUse, RatingPerformance, RatingDesign}
+
+// MaxRating is the top of every rating scale. Named ratings start at 1; the
+// overall rating is 0 when none was given.
+const MaxRating = 5
+
 type Feedback struct {
-	ID             bson.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID         bson.ObjectID `bson:"user_id" json:"user_id"`
-	Text           string        `bson:"text" json:"text"`
-	Rating         int           `bson:"rating" json:"rating"`
-	IdempotencyKey string        `bson:"idempotency_key" json:"idempotency_key"`
-	CreatedAt      time.Time     `bson:"created_at" json:"created_at"`
+	ID     bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID bson.ObjectID `bson:"user_id" json:"user_id"`
+	// The organization the author filed it under; its owners can read it
+	OrgID          *bson.ObjectID    `bson:"org_id,omitempty" json:"org_id,omitempty"`
+	Text           string            `bson:"text" json:"text"`
+	Rating         int               `bson:"rating" json:"rating"`
+	Ratings        map[string]int    `bson:"ratings,omitempty" json:"ratings,omitempty"` // by name, see FeedbackRatingNames
+	Category       string            `bson:"category,omitempty" json:"category,omitempty"`
+	Source         string            `bson:"source,omitempty" json:"source,omitempty"`
+	ContactEmail   string            `bson:"contact_email,omitempty" json:"contact_email,omitempty"` // optional, web only
+	SpamScore      int               `bson:"spam_score,omitempty" json:"spam_score,omitempty"`
+	Client         *ClientInfo       `bson:"client,omitempty" json:"client,omitempty"`
+	IdempotencyKey string            `bson:"idempotency_key" json:"idempotency_key"`
+	Status         string            `bson:"status" json:"status"`
+	ResolvedAt     *time.Time        `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	Reaction       *FeedbackReaction `bson:"reaction,omitempty" json:"reaction,omitempty"`
+	SlackThread    *SlackThread      `bson:"slack_thread,omitempty" json:"-"`
+	Assignee       string            `bson:"assignee,omitempty" json:"assignee,omitempty"` // Slack user ID
+	AssignedAt     *time.Time        `bson:"assigned_at,omitempty" json:"assigned_at,omitempty"`
+	IssueLinks     []IssueLink       `bson:"issue_links,omitempty" json:"issue_links,omitempty"`
+	Edits          []FeedbackEdit    `bson:"edits,omitempty" json:"edits,omitempty"` // earlier versions, oldest first
+	EditedAt       *time.Time        `bson:"edited_at,omitempty" json:"edited_at,omitempty"`
+	CreatedAt      time.Time         `bson:"created_at" json:"created_at"`
+}
+
+// FeedbackEdit is a version of the feedback as it was before the author
+// edited it at EditedAt.
+type FeedbackEdit struct {
+	Text     string         `bson:"text" json:"text"`
+	Rating   int            `bson:"rating" json:"rating"`
+	Ratings  map[string]int `bson:"ratings,omitempty" json:"ratings,omitempty"`
+	Category string         `bson:"category,omitempty" json:"category,omitempty"`
+	EditedAt time.Time      `bson:"edited_at" json:"edited_at"`
+}
+
+// ClientInfo describes the app build and device that sent the feedback.
+type ClientInfo struct {
+	AppVersion  string `bson:"app_version,omitempty" json:"app_version,omitempty"` // e.g. "2.3.1"
+	BuildNumber string `bson:"build_number,omitempty" json:"build_number,omitempty"`
+	OS          string `bson:"os,omitempty" json:"os,omitempty"` // "ios" or "android"
+	OSVersion   string `bson:"os_version,omitempty" json:"os_version,omitempty"`
+	DeviceModel string `bson:"device_model,omitempty" json:"device_model,omitempty"`
+}
+
+// IsZero reports whether no client details were sent.
+func (c *ClientInfo) IsZero() bool {
+	return c == nil || *c == ClientInfo{}
+}
+
+// Summary formats the client for humans, e.g. "2.3.1 (451) · ios 17.4 · iPhone15,2".
+func (c *ClientInfo) Summary() string {
+	if c.IsZero() {
+		return ""
+	}
+	var parts []string
+	if c.AppVersion != "" {
+		version := c.AppVersion
+		if c.BuildNumber != "" {
+			version += " (" + c.BuildNumber + ")"
+		}
+		parts = append(parts, version)
+	}
+	if c.OS != "" {
+		parts = append(parts, strings.TrimSpace(c.OS+" "+c.OSVersion))
+	}
+	if c.DeviceModel != "" {
+		parts = append(parts, c.DeviceModel)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// FeedbackReaction is the author's answer to "did this solve it?" after resolution.
+type FeedbackReaction struct {
+	Value     string    `bson:"value" json:"value"` // "up" or "down"
+	Comment   string    `bson:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// SlackThread points at the Slack message announcing the feedback, so follow-up
+// events are posted as replies in its thread.
+type SlackThread struct {
+	Channel string `bson:"channel"`
+	TS      string `bson:"ts"`
+}
+
+// IssueLink points at an issue created from the feedback in an external tracker.
+type IssueLink struct {
+	Provider  string    `bson:"provider" json:"provider"` // "linear" or "jira"
+	Key       string    `bson:"key" json:"key"`
+	URL       string    `bson:"url" json:"url"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+// IssueLink returns the feedback's link for provider, or nil.
+func (f *Feedback) IssueLink(provider string) *IssueLink {
+	for i := range f.IssueLinks {
+		if f.IssueLinks[i].Provider == provider {
+			return &f.IssueLinks[i]
+		}
+	}
+	return nil
+}
+
+// FeedbackStats is the admin summary of feedback and follow-up reactions.
+type FeedbackStats struct {
+	Total          int64              `json:"total"`
+	ByStatus       map[string]int64   `json:"by_status"`
+	AverageRating  float64            `json:"average_rating"`
+	AverageRatings map[string]float64 `json:"average_ratings"` // by name, over submissions that rated it
+	ReactionsUp    int64              `json:"reactions_up"`
+	ReactionsDown  int64              `json:"reactions_down"`
+	AwaitingAnswer int64              `json:"awaiting_reaction"`
+	ByAppVersion   []AppVersionStats  `json:"by_app_version"`
+}
+
+// AppVersionStats breaks feedback down by the app version that sent it.
+// Feedback from before version tracking is counted under "unknown".
+type AppVersionStats struct {
+	AppVersion    string  `bson:"_id" json:"app_version"`
+	Count         int64   `bson:"count" json:"count"`
+	AverageRating float64 `bson:"avg_rating" json:"average_rating"`
+	BugReports    int64   `bson:"bugs" json:"bug_reports"`
+}
+
+// FeedbackFilter narrows stats to a client segment. Empty fields match all.
+type FeedbackFilter struct {
+	AppVersion string
+	OS         string
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Client-reported prompt events
+const (
+	PromptEventShown     = "shown"     // the rating prompt was displayed
+	PromptEventNegative  = "negative"  // something went wrong for the user (crash, failed sync...)
+	PromptEventDismissed = "dismissed" // the user closed the prompt without rating
+)
+
+// FeedbackPromptRules decide when the app asks for a rating. A single
+// document edited by admins, so cadence changes don't need an app release.
+// Zero values disable the corresponding check.
+type FeedbackPromptRules struct {
+	Enabled               bool      `bson:"enabled" json:"enabled"`
+	MinDaysSinceInstall   int       `bson:"min_days_since_install" json:"min_days_since_install"`
+	MinDaysBetweenPrompts int       `bson:"min_days_between_prompts" json:"min_days_between_prompts"`
+	MinDaysSinceFeedback  int       `bson:"min_days_since_feedback" json:"min_days_since_feedback"`
+	NegativeCooldownDays  int       `bson:"negative_cooldown_days" json:"negative_cooldown_days"` // quiet period after a negative event or a 1–2 star rating
+	MaxPrompts            int       `bson:"max_prompts" json:"max_prompts"`                       // lifetime cap per user
+	UpdatedBy             string    `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt             time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// DefaultFeedbackPromptRules apply until an admin saves rules.
+var DefaultFeedbackPromptRules = FeedbackPromptRules{
+	Enabled:               true,
+	MinDaysSinceInstall:   7,
+	MinDaysBetweenPrompts: 30,
+	MinDaysSinceFeedback:  30,
+	NegativeCooldownDays:  3,
+	MaxPrompts:            5,
+}
+
+// FeedbackPromptState tracks one user's prompt history.
+type FeedbackPromptState struct {
+	UserID         bson.ObjectID `bson:"_id" json:"-"`
+	PromptCount    int           `bson:"prompt_count" json:"prompt_count"`
+	LastPromptedAt *time.Time    `bson:"last_prompted_at,omitempty" json:"last_prompted_at,omitempty"`
+	LastNegativeAt *time.Time    `bson:"last_negative_at,omitempty" json:"last_negative_at,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// GeoLocation is the coarse location resolved for a request.
+type GeoLocation struct {
+	Country  string `bson:"country,omitempty" json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Region   string `bson:"region,omitempty" json:"region,omitempty"`
+	City     string `bson:"city,omitempty" json:"city,omitempty"`
+	TimeZone string `bson:"time_zone,omitempty" json:"time_zone,omitempty"` // IANA name
+}
+
+// SignupCountryCount is one row of the signups-by-country breakdown.
+type SignupCountryCount struct {
+	Country string `bson:"_id" json:"country"` // empty when unknown
+	Count   int64  `bson:"count" json:"count"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Incident statuses, in the order an incident usually moves through them
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentMonitoring    = "monitoring"
+	IncidentResolved      = "resolved"
+)
+
+// Incident impacts
+const (
+	ImpactMinor    = "minor"
+	ImpactMajor    = "major"
+	ImpactCritical = "critical"
+)
+
+// Incident is a public status page entry managed by ops.
+type Incident struct {
+	ID         bson.ObjectID    `bson:"_id,omitempty" json:"id"`
+	Title      string           `bson:"title" json:"title"`
+	Status     string           `bson:"status" json:"status"`
+	Impact     string           `bson:"impact" json:"impact"`
+	Components []string         `bson:"components" json:"components"`
+	Updates    []IncidentUpdate `bson:"updates" json:"updates"` // oldest first
+	CreatedBy  string           `bson:"created_by" json:"-"`
+	CreatedAt  time.Time        `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time        `bson:"updated_at" json:"updated_at"`
+	ResolvedAt *time.Time       `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+}
+
+type IncidentUpdate struct {
+	Status    string    `bson:"status" json:"status"`
+	Message   string    `bson:"message" json:"message"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
+
+func ValidIncidentStatus(s string) bool {
+	switch s {
+	case IncidentInvestigating, IncidentIdentified, IncidentMonitoring, IncidentResolved:
+		return true
+	}
+	return false
+}
+
+func ValidIncidentImpact(s string) bool {
+	switch s {
+	case ImpactMinor, ImpactMajor, ImpactCritical:
+		return true
+	}
+	return false
+}
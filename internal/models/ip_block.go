@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// IP block reasons
+const (
+	BlockReasonManyEmails    = "many_emails"
+	BlockReasonSequential    = "sequential_emails"
+	BlockReasonInvalidVerify = "invalid_verify_attempts"
+	BlockReasonManual        = "manual"
+)
+
+// IPBlock temporarily keeps an IP address away from the auth endpoints.
+type IPBlock struct {
+	IP        string    `bson:"_id" json:"ip"`
+	Reason    string    `bson:"reason" json:"reason"`
+	Details   string    `bson:"details" json:"details"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// IPActivity is one IP's auth activity within an abuse detection window.
+// Windows expire on their own; admins can clear them early alongside a
+// block so the IP isn't re-blocked on its next request.
+type IPActivity struct {
+	IP              string    `bson:"ip" json:"ip"`
+	Emails          []string  `bson:"emails" json:"emails"`
+	InvalidVerifies int64     `bson:"invalid_verifies" json:"invalid_verifies"`
+	ExpiresAt       time.Time `bson:"expires_at" json:"expires_at"`
+}
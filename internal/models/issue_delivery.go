@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// What started an issue delivery
+const (
+	IssueDeliveryAuto      = "auto"      // bug report filed on submission
+	IssueDeliveryManual    = "manual"    // promoted by an admin
+	IssueDeliveryRedeliver = "redeliver" // an admin retried an earlier delivery
+)
+
+// IssueDelivery is one attempt to file feedback in an issue tracker.
+type IssueDelivery struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	FeedbackID bson.ObjectID `bson:"feedback_id" json:"feedback_id"`
+	Provider   string        `bson:"provider" json:"provider"` // "linear" or "jira"
+	Trigger    string        `bson:"trigger" json:"trigger"`
+	Admin      string        `bson:"admin,omitempty" json:"admin,omitempty"` // key name for manual and redelivered attempts
+	// The delivery this one retried
+	RedeliveryOf *bson.ObjectID `bson:"redelivery_of,omitempty" json:"redelivery_of,omitempty"`
+	Succeeded    bool           `bson:"succeeded" json:"succeeded"`
+	IssueKey     string         `bson:"issue_key,omitempty" json:"issue_key,omitempty"`
+	// Zero when the tracker couldn't be reached
+	StatusCode      int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	ResponseExcerpt string    `bson:"response_excerpt,omitempty" json:"response_excerpt,omitempty"`
+	Error           string    `bson:"error,omitempty" json:"error,omitempty"`
+	LatencyMS       int64     `bson:"latency_ms" json:"latency_ms"`
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// Job is a unit of background work in the Mongo-backed job queue.
+type Job struct {
+	ID       bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Type     string        `bson:"type" json:"type"`
+	Status   string        `bson:"status" json:"status"`
+	Payload  bson.Raw      `bson:"payload,omitempty" json:"-"`
+	Result   bson.Raw      `bson:"result,omitempty" json:"-"`
+	Error    string        `bson:"error,omitempty" json:"error,omitempty"`
+	Errors   []JobError    `bson:"errors,omitempty" json:"errors,omitempty"`
+	Progress *JobProgress  `bson:"progress,omitempty" json:"progress,omitempty"`
+	// CancelRequested asks the worker running the job to stop.
+	CancelRequested bool       `bson:"cancel_requested,omitempty" json:"cancel_requested,omitempty"`
+	Attempts        int        `bson:"attempts" json:"attempts"`
+	MaxAttempts     int        `bson:"max_attempts" json:"max_attempts"`
+	Retries         int        `bson:"retries,omitempty" json:"retries,omitempty"`
+	CreatedBy       string     `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	RetriedBy       string     `bson:"retried_by,omitempty" json:"retried_by,omitempty"`
+	RunAt           time.Time  `bson:"run_at" json:"run_at"`
+	LockedUntil     *time.Time `bson:"locked_until,omitempty" json:"-"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at" json:"updated_at"`
+	FinishedAt      *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+// JobError is one failed attempt of a job. Jobs keep their most recent
+// errors across retries.
+type JobError struct {
+	Attempt int       `bson:"attempt" json:"attempt"`
+	Error   string    `bson:"error" json:"error"`
+	At      time.Time `bson:"at" json:"at"`
+}
+
+// JobProgress is reported by long-running handlers.
+type JobProgress struct {
+	Done      int64     `bson:"done" json:"done"`
+	Total     int64     `bson:"total" json:"total"` // 0 when unknown
+	Percent   int       `bson:"percent" json:"percent"`
+	Message   string    `bson:"message,omitempty" json:"message,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// IsFinished reports whether the job reached a final status.
+func (j *Job) IsFinished() bool {
+	switch j.Status {
+	case JobStatusSucceeded, JobStatusFailed, JobStatusCanceled:
+		return true
+	}
+	return false
+}
+
+// DecodePayload unmarshals the job payload into v.
+func (j *Job) DecodePayload(v interface{}) error {
+	return bson.Unmarshal(j.Payload, v)
+}
+
+// PayloadJSON renders the job payload as relaxed Extended JSON for API
+// responses.
+func (j *Job) PayloadJSON() json.RawMessage {
+	return extJSON(j.Payload)
+}
+
+// ResultJSON renders the job result as relaxed Extended JSON for API responses.
+func (j *Job) ResultJSON() json.RawMessage {
+	return extJSON(j.Result)
+}
+
+func extJSON(raw bson.Raw) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	out, err := bson.MarshalExtJSON(raw, false, false)
+	if err != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// LoginLinkEvent tracks one magic link through the funnel: sent, clicked, verified.
+// Kept separately from auth_tokens, which are TTL-deleted on expiry.
+type LoginLinkEvent struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	TokenHash     string        `bson:"token_hash" json:"-"`
+	EmailDomain   string        `bson:"email_domain" json:"email_domain"`
+	SentAt        time.Time     `bson:"sent_at" json:"sent_at"`
+	ClickedAt     *time.Time    `bson:"clicked_at,omitempty" json:"clicked_at,omitempty"`
+	ClickCount    int           `bson:"click_count" json:"click_count"`
+	UserAgent     string        `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	EmailClient   string        `bson:"email_client,omitempty" json:"email_client,omitempty"`
+	TimeToClickMs int64         `bson:"time_to_click_ms,omitempty" json:"time_to_click_ms,omitempty"`
+	VerifiedAt    *time.Time    `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+}
+
+// LoginLinkClientStats is the conversion funnel for one email client.
+type LoginLinkClientStats struct {
+	EmailClient        string  `bson:"_id" json:"email_client"`
+	Clicked            int64   `bson:"clicked" json:"clicked"`
+	Verified           int64   `bson:"verified" json:"verified"`
+	AvgTimeToClickSecs float64 `bson:"avg_time_to_click_secs" json:"avg_time_to_click_secs"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Rolled-up metrics. Each is counted per bucket and one dimension.
+const (
+	MetricLoginLinks = "login_links" // hourly, by email client
+	MetricSignups    = "signups"     // daily, by signup country
+	// Daily, by step; sent counts on the sending day and converted on the
+	// day onboarding was completed
+	MetricOnboardingReminders = "onboarding_reminders"
+	// Daily; sent on the sending day, reactivated on the day the user
+	// came back
+	MetricWinback = "winback"
+)
+
+// Rollup bucket sizes
+const (
+	RollupHourly = "hour"
+	RollupDaily  = "day"
+)
+
+// RollupMeta identifies a series; it is the time-series meta field.
+type RollupMeta struct {
+	Metric      string `bson:"metric" json:"metric"`
+	Granularity string `bson:"granularity" json:"granularity"`
+	Dimension   string `bson:"dimension" json:"dimension"` // empty when unknown
+}
+
+// MetricsRollup is the counts of one metric for one bucket and dimension.
+type MetricsRollup struct {
+	Bucket time.Time        `bson:"bucket" json:"bucket"`
+	Meta   RollupMeta       `bson:"meta" json:"meta"`
+	Values map[string]int64 `bson:"values" json:"values"`
+}
+
+// RollupState records how far a metric has been rolled up: every bucket
+// before RolledUntil is final.
+type RollupState struct {
+	Metric      string    `bson:"_id" json:"metric"`
+	RolledUntil time.Time `bson:"rolled_until" json:"rolled_until"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// RollupTotal sums a metric's values over a range for one dimension.
+type RollupTotal struct {
+	Dimension string           `bson:"_id" json:"dimension"`
+	Values    map[string]int64 `bson:"values" json:"values"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Onboarding reminder steps, named for how long after signup each is sent.
+const (
+	OnboardingReminder24h = "24h"
+	OnboardingReminder72h = "72h"
+)
+
+// Why a due reminder or winback email wasn't sent
+const (
+	ReminderSkippedPreferences = "preferences" // product emails are off
+	ReminderSkippedSuppressed  = "suppressed"  // on the email suppression list
+)
+
+// OnboardingReminder records one reminder step for a user, sent or skipped,
+// so no step goes out twice. ConvertedAt is set when the user completes
+// onboarding after this reminder and before the next one.
+type OnboardingReminder struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      bson.ObjectID `bson:"user_id" json:"user_id"`
+	Step        string        `bson:"step" json:"step"`
+	Skipped     string        `bson:"skipped,omitempty" json:"skipped,omitempty"`
+	JobID       bson.ObjectID `bson:"job_id,omitempty" json:"job_id,omitempty"` // the bulkmail job
+	SentAt      time.Time     `bson:"sent_at" json:"sent_at"`                   // or skipped at
+	ConvertedAt *time.Time    `bson:"converted_at,omitempty" json:"converted_at,omitempty"`
+}
+
+// OnboardingReminderStats is the funnel for one reminder step.
+type OnboardingReminderStats struct {
+	Step           string  `json:"step"`
+	Sent           int64   `json:"sent"`
+	Converted      int64   `json:"converted"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Organization is an enterprise customer. Users whose email domain is listed
+// in Domains belong to it.
+type Organization struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Slug      string        `bson:"slug" json:"slug"` // URL-safe, unique
+	Name      string        `bson:"name" json:"name"`
+	Domains   []string      `bson:"domains" json:"domains"`                   // lower case, unique across organizations
+	Region    string        `bson:"region,omitempty" json:"region,omitempty"` // data residency; members' feedback is stored there
+	Branding  *Branding     `bson:"branding,omitempty" json:"branding,omitempty"`
+	SSO       *SSOConfig    `bson:"sso,omitempty" json:"sso,omitempty"`
+	SCIM      *SCIMToken    `bson:"scim,omitempty" json:"scim,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// Branding overrides the look of login emails and the login redirect page.
+// Empty fields keep the Rizon default.
+type Branding struct {
+	DisplayName  string `bson:"display_name,omitempty" json:"display_name,omitempty"`
+	LogoURL      string `bson:"logo_url,omitempty" json:"logo_url,omitempty"`           // https only
+	PrimaryColor string `bson:"primary_color,omitempty" json:"primary_color,omitempty"` // #rrggbb
+}
+
+// SSOConfig is an organization's OpenID Connect identity provider. When
+// Enforced, its members can't sign in with magic links.
+type SSOConfig struct {
+	Issuer       string    `bson:"issuer" json:"issuer"`
+	ClientID     string    `bson:"client_id" json:"client_id"`
+	ClientSecret string    `bson:"client_secret" json:"-"` // encrypted when ENCRYPTION_KEYS is set
+	Enforced     bool      `bson:"enforced" json:"enforced"`
+	UpdatedAt    time.Time `bson:"updated_at" json:"updated_at"`
+	UpdatedBy    string    `bson:"updated_by" json:"updated_by"`
+}
+
+// SCIMToken is the bearer token the organization's identity provider uses
+// to provision users. Only its hash is stored.
+type SCIMToken struct {
+	TokenHash string    `bson:"token_hash" json:"-"`
+	Prefix    string    `bson:"prefix" json:"prefix"` // first characters, to tell tokens apart
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	CreatedBy string    `bson:"created_by" json:"created_by"`
+}
+
+// Organization member roles. Owners manage members and see the
+// organization's feedback.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleMember = "member"
+)
+
+// OrgMember links a user to an organization.
+type OrgMember struct {
+	ID       bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrgID    bson.ObjectID `bson:"org_id" json:"org_id"`
+	UserID   bson.ObjectID `bson:"user_id" json:"user_id"`
+	Email    string        `bson:"email" json:"email"`
+	Role     string        `bson:"role" json:"role"`
+	JoinedAt time.Time     `bson:"joined_at" json:"joined_at"`
+
+	ExternalID string `bson:"external_id,omitempty" json:"external_id,omitempty"` // the identity provider's ID, when provisioned over SCIM
+	// The OpenID subject an admin linked the member to, letting them sign in
+	// through the organization's SSO with an email outside its domains
+	SSOSubject string `bson:"sso_subject,omitempty" json:"sso_subject,omitempty"`
+	// When the organization's identity provider deactivated a member whose
+	// email is outside its domains. Their account is left alone, but they
+	// stop counting as a member until reactivated.
+	DeactivatedAt *time.Time `bson:"deactivated_at,omitempty" json:"deactivated_at,omitempty"`
+}
+
+// Active reports whether the membership is in effect.
+func (m *OrgMember) Active() bool {
+	return m.DeactivatedAt == nil
+}
+
+// OrgInvite is an emailed invitation. The invitee accepts it in the app once
+// signed in with the invited address, which proves they own it.
+type OrgInvite struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrgID      bson.ObjectID `bson:"org_id" json:"org_id"`
+	Email      string        `bson:"email" json:"email"` // lower case
+	Role       string        `bson:"role" json:"role"`
+	InvitedBy  string        `bson:"invited_by" json:"invited_by"` // owner's email or admin name
+	ExpiresAt  time.Time     `bson:"expires_at" json:"expires_at"`
+	AcceptedAt *time.Time    `bson:"accepted_at,omitempty" json:"accepted_at,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at" json:"created_at"`
+}
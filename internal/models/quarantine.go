@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Quarantine review outcomes
+const (
+	QuarantinePending   = "pending"
+	QuarantineReleased  = "released"  // false positive, stored as an attachment
+	QuarantineDiscarded = "discarded" // confirmed, contents deleted
+)
+
+// QuarantinedFile is an upload the virus scanner flagged. Its contents are
+// kept, out of reach of download links, until an admin reviews it.
+type QuarantinedFile struct {
+	ID           bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID       bson.ObjectID  `bson:"user_id" json:"user_id"`
+	Filename     string         `bson:"filename" json:"filename"`
+	ContentType  string         `bson:"content_type" json:"content_type"`
+	Size         int            `bson:"size" json:"size"`
+	SHA256       string         `bson:"sha256" json:"sha256"`
+	Scanner      string         `bson:"scanner" json:"scanner"`
+	Signature    string         `bson:"signature" json:"signature"`
+	Status       string         `bson:"status" json:"status"`
+	Data         []byte         `bson:"data,omitempty" json:"-"` // removed once reviewed
+	AttachmentID *bson.ObjectID `bson:"attachment_id,omitempty" json:"attachment_id,omitempty"`
+	ReviewedBy   string         `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time     `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time      `bson:"created_at" json:"created_at"`
+}
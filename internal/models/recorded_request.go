@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Credentials a recorded request was sent with. The credential itself is
+// never stored; cmd/replay substitutes its own.
+const (
+	RecordedAuthNone        = ""
+	RecordedAuthUser        = "user"        // Authorization: Bearer <jwt>
+	RecordedAuthAdmin       = "admin"       // X-Admin-Key
+	RecordedAuthIntegration = "integration" // X-API-Key
+)
+
+// RecordedRequest is an anonymized request captured on staging so it can be
+// replayed against a new build.
+type RecordedRequest struct {
+	ID     bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Method string        `bson:"method" json:"method"`
+	// Request URI with credentials and emails in the query anonymized
+	URI     string            `bson:"uri" json:"uri"`
+	Route   string            `bson:"route" json:"route"` // chi pattern, e.g. "/feedback/{id}"
+	Auth    string            `bson:"auth,omitempty" json:"auth,omitempty"`
+	Headers map[string]string `bson:"headers,omitempty" json:"headers,omitempty"`
+	// Anonymized JSON body; non-JSON and oversized bodies aren't kept
+	Body        string    `bson:"body,omitempty" json:"body,omitempty"`
+	BodyOmitted bool      `bson:"body_omitted,omitempty" json:"body_omitted,omitempty"`
+	Status      int       `bson:"status" json:"status"`
+	DurationMs  int64     `bson:"duration_ms" json:"duration_ms"`
+	RecordedAt  time.Time `bson:"recorded_at" json:"recorded_at"`
+	ExpiresAt   time.Time `bson:"expires_at" json:"-"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RefreshToken lets the app get a new access token without signing in again.
+// Only a hash of the token is stored. Each token is used once: refreshing
+// replaces it with a new token in the same family, and presenting a used
+// token again revokes the whole family.
+type RefreshToken struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	TokenHash string        `bson:"token_hash" json:"-"`
+	// Family is shared by every token rotated from the same sign-in
+	Family    string     `bson:"family" json:"family"`
+	UserAgent string     `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	IP        string     `bson:"ip,omitempty" json:"ip,omitempty"`
+	ExpiresAt time.Time  `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+}
+
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return now.After(t.ExpiresAt)
+}
+
+// RevokedToken blocks an access token (by its jti claim) until it would
+// have expired anyway.
+type RevokedToken struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	JTI       string        `bson:"jti" json:"jti"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	ExpiresAt time.Time     `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RoadmapItem is a feature request an admin made public from a piece of
+// feedback. The title and description are written for everyone to read;
+// the feedback itself stays private.
+type RoadmapItem struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	FeedbackID  bson.ObjectID `bson:"feedback_id" json:"-"`
+	Title       string        `bson:"title" json:"title"`
+	Description string        `bson:"description,omitempty" json:"description,omitempty"`
+	Votes       int64         `bson:"votes" json:"votes"`
+	Changelog   *Changelog    `bson:"changelog,omitempty" json:"changelog,omitempty"`
+	ShippedAt   *time.Time    `bson:"shipped_at,omitempty" json:"shipped_at,omitempty"`
+	CreatedBy   string        `bson:"created_by" json:"-"` // admin key name
+	CreatedAt   time.Time     `bson:"created_at" json:"created_at"`
+}
+
+// Changelog points at the changelog entry announcing a shipped feature.
+type Changelog struct {
+	Title string `bson:"title,omitempty" json:"title,omitempty"`
+	URL   string `bson:"url" json:"url"`
+}
+
+// RoadmapVote is one user's upvote of a roadmap item.
+type RoadmapVote struct {
+	ItemID    bson.ObjectID `bson:"item_id" json:"item_id"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
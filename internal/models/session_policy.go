@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SessionPolicyStats counts JWT lifecycle events for one issuance policy on one day.
+type SessionPolicyStats struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	Day       string        `bson:"day" json:"day"` // YYYY-MM-DD (UTC)
+	Policy    string        `bson:"policy" json:"policy"`
+	Issued    int64         `bson:"issued" json:"issued"`
+	Refreshed int64         `bson:"refreshed" json:"refreshed"`
+	Expired   int64         `bson:"expired" json:"expired"` // requests rejected because the JWT had expired
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
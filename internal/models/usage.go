@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UsageRecord holds the number of requests a user made to one endpoint on one day.
+type UsageRecord struct {
+	ID        bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+	Day       string        `bson:"day" json:"day"` // YYYY-MM-DD (UTC)
+	Endpoint  string        `bson:"endpoint" json:"endpoint"`
+	Count     int64         `bson:"count" json:"count"`
+	UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// UsageTotals sums usage records over a set of users and days.
+type UsageTotals struct {
+	ActiveUsers int              `json:"active_users"` // users with any metered request
+	Requests    map[string]int64 `json:"requests"`     // by endpoint key
+	Total       int64            `json:"total"`
+}
+
+// OrgUsage is an organization's usage for one calendar month (UTC), the
+// basis for invoicing. Months are kept as reports once they are over.
+type OrgUsage struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	OrgID       bson.ObjectID `bson:"org_id" json:"org_id"`
+	OrgSlug     string        `bson:"org_slug" json:"org_slug"`
+	Month       string        `bson:"month" json:"month"` // YYYY-MM
+	Seats       int           `bson:"seats" json:"seats"` // members when the report was made
+	UsageTotals `bson:",inline"`
+	GeneratedAt time.Time `bson:"generated_at" json:"generated_at"`
+}
+
+// Quota is an admin-configured daily request limit for an endpoint key.
+type Quota struct {
+	ID         bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	Endpoint   string        `bson:"endpoint" json:"endpoint"`
+	DailyLimit int64         `bson:"daily_limit" json:"daily_limit"`
+	UpdatedAt  time.Time     `bson:"updated_at" json:"updated_at"`
+}
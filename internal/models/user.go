@@ -6,10 +6,89 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// Account statuses
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+	UserStatusMerged    = "merged" // folded into another account by an admin
+)
+
+// Ban reason codes, returned to the app so it can show the right screen.
+const (
+	BanReasonSpam        = "spam"
+	BanReasonAbuse       = "abuse"
+	BanReasonFraud       = "fraud"
+	BanReasonTermsBreach = "terms_violation"
+	BanReasonUserRequest = "user_request"
+	BanReasonOther       = "other"
+)
+
+// BanReasonDeprovisioned is set on users suspended by their organization's
+// identity provider over SCIM. Admins can't choose it.
+const BanReasonDeprovisioned = "deprovisioned"
+
+// BanReasons lists every valid ban reason code.
+var BanReasons = []string{BanReasonSpam, BanReasonAbuse, BanReasonFraud, BanReasonTermsBreach, BanReasonUserRequest, BanReasonOther}
+
 type User struct {
 	ID                  bson.ObjectID `bson:"_id,omitempty" json:"id"`
 	Email               string        `bson:"email" json:"email"`
 	OnboardingCompleted bool          `bson:"onboarding_completed" json:"onboarding_completed"`
-	CreatedAt           time.Time     `bson:"created_at" json:"created_at"`
-	UpdatedAt           time.Time     `bson:"updated_at" json:"updated_at"`
+	// Free-form, validated against the "onboarding_answers" schema if one is set
+	OnboardingAnswers map[string]interface{} `bson:"onboarding_answers,omitempty" json:"onboarding_answers,omitempty"`
+	AgeBand           string                 `bson:"age_band,omitempty" json:"age_band,omitempty"`
+	AgeCountry        string                 `bson:"age_country,omitempty" json:"age_country,omitempty"`
+	AgeBlocked        bool                   `bson:"age_blocked,omitempty" json:"age_blocked,omitempty"` // under the regional minimum age
+	SignupGeo         *GeoLocation           `bson:"signup_geo,omitempty" json:"signup_geo,omitempty"`
+	Locale            string                 `bson:"locale,omitempty" json:"locale,omitempty"`
+	TimeZone          string                 `bson:"time_zone,omitempty" json:"time_zone,omitempty"`
+	Status            string                 `bson:"status,omitempty" json:"status,omitempty"` // empty means active
+	BanReason         string                 `bson:"ban_reason,omitempty" json:"ban_reason,omitempty"`
+	BannedUntil       *time.Time             `bson:"banned_until,omitempty" json:"banned_until,omitempty"` // automatic unban; nil means indefinite
+	MergedInto        *bson.ObjectID         `bson:"merged_into,omitempty" json:"merged_into,omitempty"`
+	ImportJobID       *bson.ObjectID         `bson:"import_job_id,omitempty" json:"-"` // set on users created by a CSV import
+	LastSeenAt        *time.Time             `bson:"last_seen_at,omitempty" json:"last_seen_at,omitempty"`
+	LastAppVersion    string                 `bson:"last_app_version,omitempty" json:"last_app_version,omitempty"`
+	AvatarID          *bson.ObjectID         `bson:"avatar_id,omitempty" json:"avatar_id,omitempty"` // an image attachment
+	Preferences       *Preferences           `bson:"preferences,omitempty" json:"preferences,omitempty"`
+	CreatedAt         time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+// Preferences are the user's notification settings. Unset fields fall back to
+// the defaults, so only choices the user made are stored.
+type Preferences struct {
+	ProductEmails   *bool `bson:"product_emails,omitempty" json:"product_emails,omitempty"`     // changelog and product news
+	FeedbackUpdates *bool `bson:"feedback_updates,omitempty" json:"feedback_updates,omitempty"` // status changes on the user's feedback
+	// How emails look; empty means EmailFormatStandard
+	EmailFormat string `bson:"email_format,omitempty" json:"email_format,omitempty"`
+}
+
+// Email formats a user can choose. Plain sends text only, for clients that
+// strip HTML and for screen readers that handle it badly.
+const (
+	EmailFormatStandard     = "standard"
+	EmailFormatHighContrast = "high_contrast"
+	EmailFormatPlain        = "plain"
+)
+
+// EmailFormats lists every email format.
+var EmailFormats = []string{EmailFormatStandard, EmailFormatHighContrast, EmailFormatPlain}
+
+// EmailFormat returns the user's chosen email format, or EmailFormatStandard.
+func (u *User) EmailFormat() string {
+	if u.Preferences == nil || u.Preferences.EmailFormat == "" {
+		return EmailFormatStandard
+	}
+	return u.Preferences.EmailFormat
+}
+
+// IsRestricted reports whether the account is suspended or banned at time now.
+// Expired suspensions count as lifted even before the unban job runs.
+func (u *User) IsRestricted(now time.Time) bool {
+	if u.Status != UserStatusSuspended && u.Status != UserStatusBanned {
+		return false
+	}
+	return u.BannedUntil == nil || now.Before(*u.BannedUntil)
 }
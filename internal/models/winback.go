@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// WinbackSettings configure the email sent to users who stopped using the
+// app. The message renders through the announcement template.
+type WinbackSettings struct {
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Users last seen at least this many days ago are emailed
+	InactiveDays int `bson:"inactive_days" json:"inactive_days"`
+	// A saved audience narrowing who is emailed; nil means every active user
+	AudienceID *bson.ObjectID `bson:"audience_id,omitempty" json:"audience_id,omitempty"`
+	// A user gets at most one winback email per this many days
+	CooldownDays int `bson:"cooldown_days" json:"cooldown_days"`
+	// Never emailed, e.g. staff and test accounts
+	ExcludeDomains []string  `bson:"exclude_domains" json:"exclude_domains"`
+	Subject        string    `bson:"subject" json:"subject"`
+	Message        string    `bson:"message" json:"message"`
+	LinkURL        string    `bson:"link_url,omitempty" json:"link_url,omitempty"`
+	LinkLabel      string    `bson:"link_label,omitempty" json:"link_label,omitempty"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+	UpdatedBy      string    `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+}
+
+// WinbackSend records one winback email, or why a due one was skipped
+// (see ReminderSkipped*). ReactivatedAt is when the user was next seen, if
+// within the attribution window.
+type WinbackSend struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        bson.ObjectID `bson:"user_id" json:"user_id"`
+	Skipped       string        `bson:"skipped,omitempty" json:"skipped,omitempty"`
+	JobID         bson.ObjectID `bson:"job_id,omitempty" json:"job_id,omitempty"`
+	LastSeenAt    time.Time     `bson:"last_seen_at" json:"last_seen_at"` // the user's, when emailed
+	SentAt        time.Time     `bson:"sent_at" json:"sent_at"`
+	ReactivatedAt *time.Time    `bson:"reactivated_at,omitempty" json:"reactivated_at,omitempty"`
+}
@@ -0,0 +1,324 @@
+// Package oidc signs users in with an OpenID Connect identity provider using
+// the authorization code flow, or verifies ID tokens the app got from the
+// provider's native sign-in.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/httpclient"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrNonceMismatch  = errors.New("id token nonce does not match")
+	ErrNoEmail        = errors.New("identity provider did not return a verified email")
+)
+
+// GoogleIssuer is Google's issuer. Its ID tokens carry either this or the
+// bare host as iss.
+const GoogleIssuer = "https://accounts.google.com"
+
+var issuerAliases = map[string][]string{
+	GoogleIssuer: {"accounts.google.com"},
+}
+
+// Config is one identity provider registration.
+type Config struct {
+	Issuer       string // discovery is at Issuer + "/.well-known/openid-configuration"
+	ClientID     string
+	ClientSecret string
+	// Audiences are other client IDs whose ID tokens are accepted, e.g. the
+	// iOS and Android clients of a native sign-in
+	Audiences []string
+}
+
+// Identity is what a verified ID token says about the user.
+type Identity struct {
+	Subject string
+	Email   string
+}
+
+// discovery is the subset of the provider metadata the code flow needs.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type provider struct {
+	meta      discovery
+	keys      map[string]*rsa.PublicKey // by kid
+	fetchedAt time.Time
+}
+
+// metadataTTL is how long discovery documents and signing keys are cached.
+// Unknown key IDs refresh the keys sooner, at most every minKeyRefresh.
+const (
+	metadataTTL   = time.Hour
+	minKeyRefresh = time.Minute
+)
+
+// Client runs the code flow against any number of providers, caching their
+// metadata and signing keys.
+type Client struct {
+	http *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*provider // by issuer
+}
+
+func New() *Client {
+	return &Client{
+		http:      httpclient.New("oidc", httpclient.Options{Retries: 2}),
+		providers: map[string]*provider{},
+	}
+}
+
+// Discover fetches (or returns the cached) provider metadata. Admins call it
+// when saving a configuration to catch typos early.
+func (c *Client) Discover(ctx context.Context, issuer string) error {
+	_, err := c.provider(ctx, issuer, false)
+	return err
+}
+
+// AuthURL returns the provider's sign-in page URL.
+func (c *Client) AuthURL(ctx context.Context, cfg Config, redirectURI, state, nonce string) (string, error) {
+	p, err := c.provider(ctx, cfg.Issuer, false)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {cfg.ClientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {"openid email"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	sep := "?"
+	if strings.Contains(p.meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.meta.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange redeems an authorization code and verifies the returned ID token:
+// signature, issuer, audience, expiry and nonce. Only verified emails are
+// accepted.
+func (c *Client) Exchange(ctx context.Context, cfg Config, redirectURI, code, nonce string) (*Identity, error) {
+	p, err := c.provider(ctx, cfg.Issuer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint: status %d", resp.StatusCode)
+	}
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("token endpoint: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token endpoint: no id_token in response")
+	}
+
+	return c.verify(ctx, cfg, p, tokens.IDToken, nonce)
+}
+
+// VerifyIDToken checks an ID token the app got from the provider itself,
+// e.g. from a native Sign in with Google prompt. nonce is empty when the app
+// didn't send one. Token problems wrap ErrInvalidIDToken; other errors mean
+// the provider couldn't be reached.
+func (c *Client) VerifyIDToken(ctx context.Context, cfg Config, raw, nonce string) (*Identity, error) {
+	p, err := c.provider(ctx, cfg.Issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	return c.verify(ctx, cfg, p, raw, nonce)
+}
+
+type idClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"` // some providers omit it for managed accounts
+}
+
+func (c *Client) verify(ctx context.Context, cfg Config, p *provider, raw, nonce string) (*Identity, error) {
+	var claims idClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.key(ctx, cfg.Issuer, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(append([]string{cfg.ClientID}, cfg.Audiences...)...),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+	if claims.Issuer != p.meta.Issuer && !slices.Contains(issuerAliases[p.meta.Issuer], claims.Issuer) {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+	if claims.Email == "" || (claims.EmailVerified != nil && !*claims.EmailVerified) {
+		return nil, ErrNoEmail
+	}
+	return &Identity{Subject: claims.Subject, Email: strings.ToLower(claims.Email)}, nil
+}
+
+// key returns the provider's signing key with the ID, refreshing the keys
+// once if it isn't known (the provider may have rotated them).
+func (c *Client) key(ctx context.Context, issuer, kid string) (*rsa.PublicKey, error) {
+	p, err := c.provider(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+	if k := lookup(p.keys, kid); k != nil {
+		return k, nil
+	}
+	if p, err = c.provider(ctx, issuer, true); err != nil {
+		return nil, err
+	}
+	if k := lookup(p.keys, kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookup finds the key by ID. Tokens without a kid match a lone key.
+func lookup(keys map[string]*rsa.PublicKey, kid string) *rsa.PublicKey {
+	if k, ok := keys[kid]; ok {
+		return k
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k
+		}
+	}
+	return nil
+}
+
+func (c *Client) provider(ctx context.Context, issuer string, refreshKeys bool) (*provider, error) {
+	c.mu.Lock()
+	cached := c.providers[issuer]
+	c.mu.Unlock()
+
+	age := time.Duration(1<<63 - 1)
+	if cached != nil {
+		age = time.Since(cached.fetchedAt)
+	}
+	if cached != nil && age < metadataTTL && (!refreshKeys || age < minKeyRefresh) {
+		return cached, nil
+	}
+
+	var meta discovery
+	if err := c.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("discovery: incomplete provider metadata")
+	}
+	keys, err := c.fetchKeys(ctx, meta.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &provider{meta: meta, keys: keys, fetchedAt: time.Now()}
+	c.mu.Lock()
+	c.providers[issuer] = p
+	c.mu.Unlock()
+	return p, nil
+}
+
+func (c *Client) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks: no RSA signing keys")
+	}
+	return keys, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,200 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeProvider is an identity provider serving discovery, JWKS and a token
+// endpoint that hands out whatever ID token is queued.
+type fakeProvider struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	idToken string
+	code    string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+	p := &fakeProvider{keys: map[string]*rsa.PrivateKey{}}
+	p.rotate(t, "k1")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(discovery{
+			Issuer:                p.URL,
+			AuthorizationEndpoint: p.URL + "/authorize",
+			TokenEndpoint:         p.URL + "/token",
+			JWKSURI:               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		var set struct {
+			Keys []map[string]string `json:"keys"`
+		}
+		for kid, key := range p.keys {
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "s3cret" || r.FormValue("code") != p.code {
+			http.Error(w, "invalid_grant", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": p.idToken})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// rotate replaces the signing keys with a new one.
+func (p *fakeProvider) rotate(t *testing.T, kid string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	p.keys = map[string]*rsa.PrivateKey{kid: key}
+	p.mu.Unlock()
+}
+
+func (p *fakeProvider) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	p.mu.Lock()
+	key := p.keys[kid]
+	p.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func (p *fakeProvider) claims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            p.URL,
+		"sub":            "user-123",
+		"aud":            "client",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          "n0nce",
+		"email":          "Jo@Example.com",
+		"email_verified": true,
+	}
+}
+
+func TestExchange(t *testing.T) {
+	p := newFakeProvider(t)
+	c := New()
+	ctx := context.Background()
+	cfg := Config{Issuer: p.URL, ClientID: "client", ClientSecret: "s3cret"}
+
+	authURL, err := c.AuthURL(ctx, cfg, "https://rizon.app/callback", "st4te", "n0nce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	if q := u.Query(); u.Path != "/authorize" || q.Get("state") != "st4te" || q.Get("nonce") != "n0nce" || q.Get("client_id") != "client" {
+		t.Errorf("AuthURL = %s", authURL)
+	}
+
+	p.code, p.idToken = "c0de", p.sign(t, "k1", p.claims())
+	id, err := c.Exchange(ctx, cfg, "https://rizon.app/callback", "c0de", "n0nce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Subject != "user-123" || id.Email != "jo@example.com" {
+		t.Errorf("identity = %+v", id)
+	}
+	if _, err := c.Exchange(ctx, cfg, "https://rizon.app/callback", "wrong", "n0nce"); err == nil {
+		t.Error("exchanged an unknown code")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	p := newFakeProvider(t)
+	c := New()
+	ctx := context.Background()
+	cfg := Config{Issuer: p.URL, ClientID: "client", Audiences: []string{"ios-client"}}
+
+	tests := []struct {
+		name    string
+		change  func(jwt.MapClaims)
+		wantErr error
+	}{
+		{"valid", func(jwt.MapClaims) {}, nil},
+		{"native client audience", func(c jwt.MapClaims) { c["aud"] = "ios-client" }, nil},
+		{"email_verified omitted", func(c jwt.MapClaims) { delete(c, "email_verified") }, nil},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "someone-else" }, ErrInvalidIDToken},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, ErrInvalidIDToken},
+		{"no expiry", func(c jwt.MapClaims) { delete(c, "exp") }, ErrInvalidIDToken},
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, ErrInvalidIDToken},
+		{"nonce mismatch", func(c jwt.MapClaims) { c["nonce"] = "replayed" }, ErrNonceMismatch},
+		{"unverified email", func(c jwt.MapClaims) { c["email_verified"] = false }, ErrNoEmail},
+		{"no email", func(c jwt.MapClaims) { delete(c, "email") }, ErrNoEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := p.claims()
+			tt.change(claims)
+			_, err := c.VerifyIDToken(ctx, cfg, p.sign(t, "k1", claims), "n0nce")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	hs256, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, p.claims()).SignedString([]byte("guess"))
+	if _, err := c.VerifyIDToken(ctx, cfg, hs256, "n0nce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("HS256 token: err = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestVerifyIDTokenAfterKeyRotation(t *testing.T) {
+	p := newFakeProvider(t)
+	c := New()
+	ctx := context.Background()
+	cfg := Config{Issuer: p.URL, ClientID: "client"}
+	if err := c.Discover(ctx, p.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	p.rotate(t, "k2")
+	token := p.sign(t, "k2", p.claims())
+
+	// Keys were just fetched, so an unknown kid doesn't refetch them yet
+	if _, err := c.VerifyIDToken(ctx, cfg, token, "n0nce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("before the refresh interval: err = %v, want ErrInvalidIDToken", err)
+	}
+	c.providers[p.URL].fetchedAt = time.Now().Add(-minKeyRefresh)
+	if _, err := c.VerifyIDToken(ctx, cfg, token, "n0nce"); err != nil {
+		t.Fatalf("after the refresh interval: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package onboarding nudges users who signed in but never finished
+// onboarding. Each reminder step goes out at most once per user, through the
+// bulkmail queue so throttling and quiet hours apply.
+package onboarding
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"rizon-backend/internal/bulkmail"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/templates"
+)
+
+// step is sent to users who signed up at least After ago and less than
+// Until ago; past Until the next step (or nothing) applies.
+type step struct {
+	Name  string
+	After time.Duration
+	Until time.Duration
+}
+
+var steps = []step{
+	{Name: models.OnboardingReminder24h, After: 24 * time.Hour, Until: 72 * time.Hour},
+	{Name: models.OnboardingReminder72h, After: 72 * time.Hour, Until: 7 * 24 * time.Hour},
+}
+
+// MaxPerRun caps the reminders queued by one run, per step.
+const MaxPerRun = 500
+
+type Reminders struct {
+	users        *repository.UserRepo
+	reminders    *repository.OnboardingReminderRepo
+	suppressions *repository.EmailSuppressionRepo
+	queue        *jobs.Queue
+}
+
+func NewReminders(users *repository.UserRepo, reminders *repository.OnboardingReminderRepo, suppressions *repository.EmailSuppressionRepo, queue *jobs.Queue) *Reminders {
+	return &Reminders{
+		users:        users,
+		reminders:    reminders,
+		suppressions: suppressions,
+		queue:        queue,
+	}
+}
+
+// Run queues every reminder due at time now and returns how many it queued.
+// Meant to run from the scheduler.
+func (r *Reminders) Run(ctx context.Context, now time.Time) (int, error) {
+	queued := 0
+	for _, s := range steps {
+		users, err := r.users.AwaitingOnboardingReminder(ctx, s.Name, now.Add(-s.Until), now.Add(-s.After), MaxPerRun)
+		if err != nil {
+			return queued, fmt.Errorf("finding users due the %s reminder: %w", s.Name, err)
+		}
+		for i := range users {
+			sent, err := r.remind(ctx, &users[i], s.Name)
+			if err != nil {
+				return queued, err
+			}
+			if sent {
+				queued++
+			}
+		}
+	}
+	return queued, nil
+}
+
+// remind sends one step to the user, or records why it was skipped so the
+// user isn't picked again.
+func (r *Reminders) remind(ctx context.Context, user *models.User, step string) (bool, error) {
+	reminder := &models.OnboardingReminder{UserID: user.ID, Step: step}
+	if p := user.Preferences; p != nil && p.ProductEmails != nil && !*p.ProductEmails {
+		reminder.Skipped = models.ReminderSkippedPreferences
+	} else if suppressed, err := r.suppressions.IsSuppressed(ctx, user.Email); err != nil {
+		return false, fmt.Errorf("checking email suppression: %w", err)
+	} else if suppressed {
+		reminder.Skipped = models.ReminderSkippedSuppressed
+	}
+
+	claimed, err := r.reminders.Claim(ctx, reminder)
+	if err != nil || !claimed || reminder.Skipped != "" {
+		return false, err
+	}
+
+	content, err := templates.RenderEmail("onboarding_reminder", templates.EmailOptionsFor(user, ""), map[string]interface{}{
+		"Step":  step,
+		"Brand": templates.DefaultBrand,
+	})
+	var job *models.Job
+	if err == nil {
+		job, err = bulkmail.Enqueue(ctx, r.queue, user, mailer.FromRendered(user.Email, content), "onboarding-reminder:"+step)
+	}
+	if err != nil {
+		if releaseErr := r.reminders.Release(ctx, reminder.ID); releaseErr != nil {
+			log.Printf("Error releasing onboarding reminder: %v", releaseErr)
+		}
+		return false, fmt.Errorf("queueing %s onboarding reminder: %w", step, err)
+	}
+	if err := r.reminders.SetJob(ctx, reminder.ID, job.ID); err != nil {
+		log.Printf("Error recording onboarding reminder job: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package presence
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type deviceKey struct {
+	userID   string
+	deviceID string
+}
+
+type sighting struct {
+	at     time.Time
+	client *models.ClientInfo
+}
+
+// Tracker keeps the latest sighting of every active user and device in
+// memory and periodically writes them to Mongo, so busy users cost one write
+// per flush instead of one per request.
+type Tracker struct {
+	deviceRepo *repository.DeviceRepo
+	userRepo   *repository.UserRepo
+
+	mu      sync.Mutex
+	pending map[deviceKey]sighting
+}
+
+func NewTracker(deviceRepo *repository.DeviceRepo, userRepo *repository.UserRepo) *Tracker {
+	return &Tracker{
+		deviceRepo: deviceRepo,
+		userRepo:   userRepo,
+		pending:    make(map[deviceKey]sighting),
+	}
+}
+
+// Seen records a request from the user's device. deviceID may be empty for
+// clients that don't send one.
+func (t *Tracker) Seen(userID, deviceID string, client *models.ClientInfo) {
+	key := deviceKey{userID: userID, deviceID: deviceID}
+	t.mu.Lock()
+	if client.IsZero() {
+		// Keep details from an earlier request in the same window
+		client = t.pending[key].client
+	}
+	t.pending[key] = sighting{at: time.Now(), client: client}
+	t.mu.Unlock()
+}
+
+// Run flushes sightings every interval until ctx is cancelled, then
+// performs a final flush.
+func (t *Tracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			t.flush(flushCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+func (t *Tracker) flush(ctx context.Context) {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[deviceKey]sighting)
+	t.mu.Unlock()
+
+	latest := map[string]sighting{}
+	for key, s := range batch {
+		userID, err := bson.ObjectIDFromHex(key.userID)
+		if err != nil {
+			continue
+		}
+		if prev, ok := latest[key.userID]; !ok || s.at.After(prev.at) {
+			latest[key.userID] = s
+		}
+		if key.deviceID == "" {
+			continue
+		}
+		if err := t.deviceRepo.Touch(ctx, userID, key.deviceID, s.client, s.at); err != nil {
+			log.Printf("Error saving device %s for %s: %v", key.deviceID, key.userID, err)
+		}
+	}
+
+	for id, s := range latest {
+		userID, _ := bson.ObjectIDFromHex(id)
+		appVersion := ""
+		if s.client != nil {
+			appVersion = s.client.AppVersion
+		}
+		if err := t.userRepo.SetLastSeen(ctx, userID, s.at, appVersion); err != nil {
+			log.Printf("Error saving last seen for %s: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Result describes the outcome of a rate limit check.
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	ResetAt   time.Time
+}
+
+// Limiter is a fixed-window rate limiter backed by atomic counters in Mongo,
+// so limits hold across replicas and restarts.
+type Limiter struct {
+	collection *mongo.Collection
+	clock      clock.Clock
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		collection: database.GetCollection("rate_limits"),
+		clock:      clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake.
+func (l *Limiter) WithClock(c clock.Clock) *Limiter {
+	l.clock = c
+	return l
+}
+
+// Allow counts a hit against key and reports whether it is within limit for
+// the current window.
+func (l *Limiter) Allow(ctx context.Context, key string, limit int64, window time.Duration) (Result, error) {
+	windowStart, resetAt := l.window(window)
+	id := key + ":" + strconv.FormatInt(windowStart.Unix(), 10)
+
+	var counter struct {
+		Count int64 `bson:"count"`
+	}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"key": key, "expires_at": resetAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	err := l.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&counter)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two replicas raced to create the window; the document exists now
+		err = l.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&counter)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	remaining := limit - counter.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Result{
+		Allowed:   counter.Count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// window returns the bounds of the current fixed window. Windows are
+// aligned to multiples of their length, so every replica agrees on them.
+func (l *Limiter) window(window time.Duration) (start, resetAt time.Time) {
+	start = l.clock.Now().Truncate(window)
+	return start, start.Add(window)
+}
+
+// Counter is one key's count within its current window.
+type Counter struct {
+	Key       string    `bson:"key" json:"key"`
+	Count     int64     `bson:"count" json:"count"`
+	ExpiresAt time.Time `bson:"expires_at" json:"reset_at"`
+}
+
+// List returns the live counters whose key starts with prefix, busiest
+// first. An empty prefix lists every key.
+func (l *Limiter) List(ctx context.Context, prefix string, limit int64) ([]Counter, error) {
+	filter := bson.M{"expires_at": bson.M{"$gt": l.clock.Now()}}
+	if prefix != "" {
+		filter["key"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	cursor, err := l.collection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "count", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	counters := []Counter{}
+	if err := cursor.All(ctx, &counters); err != nil {
+		return nil, err
+	}
+	return counters, nil
+}
+
+// Reset drops every window counted against key, so the next request starts
+// from zero. Returns the number of windows removed.
+func (l *Limiter) Reset(ctx context.Context, key string) (int64, error) {
+	result, err := l.collection.DeleteMany(ctx, bson.M{"key": key})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureIndexes creates necessary indexes for the rate_limits collection
+func (l *Limiter) EnsureIndexes(ctx context.Context) error {
+	_, err := l.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index — drop finished windows
+		},
+		{Keys: bson.D{{Key: "key", Value: 1}}},
+	})
+	return err
+}
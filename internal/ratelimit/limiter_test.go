@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"rizon-backend/internal/clock"
+)
+
+func TestWindowBoundaries(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	l := &Limiter{clock: fake}
+
+	gotStart, gotReset := l.window(time.Minute)
+	if !gotStart.Equal(start) || !gotReset.Equal(start.Add(time.Minute)) {
+		t.Fatalf("window = [%v, %v), want [%v, %v)", gotStart, gotReset, start, start.Add(time.Minute))
+	}
+
+	// The last instant of the window still counts against it
+	fake.Advance(time.Minute - time.Nanosecond)
+	if s, _ := l.window(time.Minute); !s.Equal(start) {
+		t.Errorf("just before reset: window starts %v, want %v", s, start)
+	}
+
+	// At resetAt a fresh window begins
+	fake.Advance(time.Nanosecond)
+	if s, r := l.window(time.Minute); !s.Equal(gotReset) || !r.Equal(gotReset.Add(time.Minute)) {
+		t.Errorf("at reset: window = [%v, %v), want [%v, %v)", s, r, gotReset, gotReset.Add(time.Minute))
+	}
+
+	// Windows are aligned, not started by the first hit
+	fake.Set(start.Add(90 * time.Second))
+	if s, r := l.window(time.Hour); !s.Equal(start) || !r.Equal(start.Add(time.Hour)) {
+		t.Errorf("hour window = [%v, %v), want [%v, %v)", s, r, start, start.Add(time.Hour))
+	}
+}
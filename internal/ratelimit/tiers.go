@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API rate limit tiers. Users on the individual plan are free, members of
+// an organization are pro, and integration API keys have a tier of their
+// own.
+const (
+	TierFree   = "free"
+	TierPro    = "pro"
+	TierAPIKey = "api_key"
+)
+
+// TierLimit is the number of requests allowed per window.
+type TierLimit struct {
+	Limit  int64
+	Window time.Duration
+}
+
+// Tiers maps tier names to their limits.
+type Tiers map[string]TierLimit
+
+// DefaultTiers apply to tiers RATE_LIMIT_TIERS doesn't mention.
+var DefaultTiers = Tiers{
+	TierFree:   {Limit: 120, Window: time.Minute},
+	TierPro:    {Limit: 600, Window: time.Minute},
+	TierAPIKey: {Limit: 1200, Window: time.Minute},
+}
+
+// ParseTiers reads "tier=limit/window" pairs, e.g. "free=60/1m,pro=1000/1m",
+// over DefaultTiers. A limit of 0 turns limiting off for the tier.
+func ParseTiers(spec string) (Tiers, error) {
+	tiers := Tiers{}
+	for name, limit := range DefaultTiers {
+		tiers[name] = limit
+	}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(part, "=")
+		if _, known := DefaultTiers[name]; !ok || !known {
+			return nil, fmt.Errorf("invalid tier %q, want one of free, pro, api_key as tier=limit/window", part)
+		}
+		count, window, ok := strings.Cut(raw, "/")
+		limit, err := strconv.ParseInt(count, 10, 64)
+		if !ok || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid limit for tier %q: %q", name, raw)
+		}
+		d, err := time.ParseDuration(window)
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid window for tier %q: %q", name, raw)
+		}
+		tiers[name] = TierLimit{Limit: limit, Window: d}
+	}
+	return tiers, nil
+}
@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"sync"
+)
+
+// Event is a message fanned out to realtime subscribers (SSE/WebSocket clients).
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Hub is an in-process fan-out of events to connected clients. Events should be
+// fed from the change stream watcher so every replica sees every write.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Subscribe registers a client. Call the returned func to unsubscribe.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 32)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers, ch)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers the event to every subscriber. Slow subscribers whose
+// buffer is full miss the event rather than blocking the publisher.
+func (h *Hub) Publish(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
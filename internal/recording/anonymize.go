@@ -0,0 +1,142 @@
+package recording
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-().]{5,}\d`)
+)
+
+// secretParams are query parameters and body fields whose values are
+// credentials; they are replaced outright.
+var secretParams = map[string]bool{
+	"token": true, "code": true, "sig": true, "secret": true, "password": true,
+	"refresh_token": true, "id_token": true, "state": true, "nonce": true,
+}
+
+// anonymizeURL masks credentials and emails in the query string.
+func (rec *Recorder) anonymizeURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+	query := u.Query()
+	for key, values := range query {
+		for i, v := range values {
+			if secretParams[strings.ToLower(key)] {
+				values[i] = "REDACTED"
+			} else {
+				values[i] = rec.anonymizeString(v)
+			}
+		}
+	}
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.RequestURI()
+}
+
+// anonymizeBody rewrites every string in a JSON body, keeping its structure,
+// numbers and booleans so replays exercise the same validation paths.
+// Non-JSON bodies aren't kept.
+func (rec *Recorder) anonymizeBody(contentType string, body []byte) (string, bool) {
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return "", false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return "", false
+	}
+	out, err := json.Marshal(rec.anonymizeValue("", doc))
+	if err != nil {
+		return "", false
+	}
+	return string(out), true
+}
+
+func (rec *Recorder) anonymizeValue(key string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = rec.anonymizeValue(k, child)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = rec.anonymizeValue(key, child)
+		}
+		return val
+	case string:
+		if secretParams[strings.ToLower(key)] {
+			return "REDACTED"
+		}
+		return rec.anonymizeString(val)
+	default:
+		return val
+	}
+}
+
+// anonymizeString swaps emails for stable placeholders and phone numbers for
+// zeros. Free text (anything with spaces or longer than an identifier) has
+// its letters and digits masked, keeping length and punctuation; short
+// tokens such as categories and IDs pass through.
+func (rec *Recorder) anonymizeString(s string) string {
+	s = emailPattern.ReplaceAllStringFunc(s, rec.anonymizeEmail)
+	s = phonePattern.ReplaceAllStringFunc(s, func(phone string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return '0'
+			}
+			return r
+		}, phone)
+	})
+	if !strings.ContainsFunc(s, unicode.IsSpace) && len([]rune(s)) <= 40 {
+		return s
+	}
+	return maskText(s)
+}
+
+// anonymizeEmail maps an address to one that is still valid, so login and
+// invite requests pass validation on replay.
+func (rec *Recorder) anonymizeEmail(email string) string {
+	mac := hmac.New(sha256.New, rec.salt)
+	mac.Write([]byte(strings.ToLower(email)))
+	return "anon-" + hex.EncodeToString(mac.Sum(nil))[:12] + "@example.com"
+}
+
+// maskText replaces letters with "x" and digits with "0", leaving emails
+// already anonymized intact.
+func maskText(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range emailPattern.FindAllStringIndex(s, -1) {
+		b.WriteString(maskRunes(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(maskRunes(s[last:]))
+	return b.String()
+}
+
+func maskRunes(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r):
+			return 'x'
+		case unicode.IsDigit(r):
+			return '0'
+		default:
+			return r
+		}
+	}, s)
+}
@@ -0,0 +1,171 @@
+// Package recording captures anonymized staging traffic so cmd/replay can
+// run it against a new build.
+package recording
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+)
+
+// Config controls what gets recorded.
+type Config struct {
+	// Fraction of requests recorded, 0 to 1
+	SampleRate float64
+	// How long recordings are kept
+	Retention time.Duration
+	// Requests whose path starts with one of these are never recorded
+	SkipPrefixes []string
+}
+
+// DefaultSkipPrefixes leaves out probes and development helpers.
+var DefaultSkipPrefixes = []string{"/health", "/ready", "/version", "/dev/", "/__fixtures__", "/auth/test-login"}
+
+// maxPending bounds the in-memory buffer if Mongo falls behind; requests
+// over it are dropped rather than slowing the request path.
+const maxPending = 5000
+
+// MaxBodyBytes is the largest request body kept.
+const MaxBodyBytes = 64 << 10
+
+// Recorder anonymizes sampled requests and writes them to Mongo in batches.
+type Recorder struct {
+	repo *repository.RecordedRequestRepo
+	cfg  Config
+	salt []byte
+
+	mu      sync.Mutex
+	pending []*models.RecordedRequest
+	dropped int64
+}
+
+func NewRecorder(repo *repository.RecordedRequestRepo, cfg Config) (*Recorder, error) {
+	if cfg.Retention <= 0 {
+		cfg.Retention = 7 * 24 * time.Hour
+	}
+	// A fresh salt per process keeps anonymized emails consistent within a
+	// recording session without being reversible by hashing guesses
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	return &Recorder{repo: repo, cfg: cfg, salt: salt}, nil
+}
+
+// Sample decides whether to record r; it is called before the body is read.
+func (rec *Recorder) Sample(r *http.Request) bool {
+	for _, prefix := range rec.cfg.SkipPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return rec.cfg.SampleRate >= 1 || mathrand.Float64() < rec.cfg.SampleRate
+}
+
+// Capture anonymizes a served request and queues it for the next flush.
+func (rec *Recorder) Capture(r *http.Request, route string, body []byte, status int, elapsed time.Duration) {
+	now := time.Now()
+	req := &models.RecordedRequest{
+		Method:     r.Method,
+		URI:        rec.anonymizeURL(r.URL),
+		Route:      route,
+		Auth:       authKind(r),
+		Headers:    keptHeaders(r.Header),
+		Status:     status,
+		DurationMs: elapsed.Milliseconds(),
+		RecordedAt: now,
+		ExpiresAt:  now.Add(rec.cfg.Retention),
+	}
+	if len(body) > MaxBodyBytes {
+		req.BodyOmitted = true
+	} else if len(body) > 0 {
+		anonymized, ok := rec.anonymizeBody(r.Header.Get("Content-Type"), body)
+		if ok {
+			req.Body = anonymized
+		} else {
+			req.BodyOmitted = true
+		}
+	}
+
+	rec.mu.Lock()
+	if len(rec.pending) < maxPending {
+		rec.pending = append(rec.pending, req)
+	} else {
+		rec.dropped++
+	}
+	rec.mu.Unlock()
+}
+
+// Run flushes captured requests every interval until ctx is cancelled,
+// then performs a final flush.
+func (rec *Recorder) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rec.flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			rec.flush(flushCtx)
+			cancel()
+			return
+		}
+	}
+}
+
+func (rec *Recorder) flush(ctx context.Context) {
+	rec.mu.Lock()
+	batch, dropped := rec.pending, rec.dropped
+	rec.pending, rec.dropped = nil, 0
+	rec.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("⚠️  Request recording dropped %d requests (buffer full)", dropped)
+	}
+	// Recordings are best-effort; a failed batch is not retried
+	if err := rec.repo.InsertMany(ctx, batch); err != nil {
+		log.Printf("Error saving %d recorded requests: %v", len(batch), err)
+	}
+}
+
+// authKind names the credential a request carried.
+func authKind(r *http.Request) string {
+	switch {
+	case r.Header.Get("X-Admin-Key") != "":
+		return models.RecordedAuthAdmin
+	case r.Header.Get("X-API-Key") != "":
+		return models.RecordedAuthIntegration
+	case strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "):
+		return models.RecordedAuthUser
+	default:
+		return models.RecordedAuthNone
+	}
+}
+
+// replayedHeaders are the request headers that shape a response without
+// identifying the caller.
+var replayedHeaders = []string{
+	"Accept", "Accept-Language", "Content-Type", "User-Agent", "X-API-Version",
+	"X-App-Version", "X-App-Build", "X-OS", "X-OS-Version", "X-Device-Model",
+}
+
+func keptHeaders(h http.Header) map[string]string {
+	kept := map[string]string{}
+	for _, name := range replayedHeaders {
+		if v := h.Get(name); v != "" {
+			kept[name] = v
+		}
+	}
+	return kept
+}
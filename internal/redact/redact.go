@@ -0,0 +1,84 @@
+package redact
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// enabled is governed by the REDACT_PII setting; redaction is on by default.
+var enabled atomic.Bool
+
+func init() {
+	enabled.Store(true)
+}
+
+// SetEnabled turns PII redaction on or off process-wide.
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether PII redaction is on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// Phone-like runs of 7+ digits, allowing common separators and a leading +
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s\-().]{5,}\d`)
+)
+
+// Email masks the local part of an address: "jane.doe@example.com" → "ja***@example.com".
+func Email(email string) string {
+	if !Enabled() {
+		return email
+	}
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return "***"
+	}
+	if len(local) > 2 {
+		local = local[:2]
+	}
+	return local + "***@" + domain
+}
+
+// Text strips emails and phone numbers from free-form text and truncates it
+// to maxRunes characters (0 means no limit).
+func Text(text string, maxRunes int) string {
+	if !Enabled() {
+		return text
+	}
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	text = phonePattern.ReplaceAllString(text, "[phone]")
+	if runes := []rune(text); maxRunes > 0 && len(runes) > maxRunes {
+		text = string(runes[:maxRunes]) + "…"
+	}
+	return text
+}
+
+// sensitiveParams are query parameters that carry credentials.
+var sensitiveParams = []string{"token", "code", "email"}
+
+// URL returns the request URI with credential-bearing query parameters masked.
+func URL(u *url.URL) string {
+	if !Enabled() || u.RawQuery == "" {
+		return u.RequestURI()
+	}
+	query := u.Query()
+	changed := false
+	for _, key := range sensitiveParams {
+		if query.Has(key) {
+			query.Set(key, "REDACTED")
+			changed = true
+		}
+	}
+	if !changed {
+		return u.RequestURI()
+	}
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.RequestURI()
+}
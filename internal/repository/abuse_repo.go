@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// maxTrackedEmails caps the emails remembered per IP and window.
+const maxTrackedEmails = 50
+
+// AbuseRepo stores per-IP auth activity windows and the resulting IP blocks.
+type AbuseRepo struct {
+	activity *mongo.Collection
+	blocks   *mongo.Collection
+}
+
+func NewAbuseRepo() *AbuseRepo {
+	return &AbuseRepo{
+		activity: database.GetCollection("auth_ip_activity"),
+		blocks:   database.GetCollection("ip_blocks"),
+	}
+}
+
+// ipActivity is one IP's auth activity within a fixed window.
+type ipActivity struct {
+	Emails          []string `bson:"emails"`
+	InvalidVerifies int64    `bson:"invalid_verifies"`
+}
+
+func activityID(ip string, windowStart time.Time) string {
+	return ip + "|" + windowStart.UTC().Format(time.RFC3339)
+}
+
+// RecordEmail adds an email requested from ip to the current window and
+// returns the distinct emails seen so far in it.
+func (r *AbuseRepo) RecordEmail(ctx context.Context, ip, email string, windowStart time.Time, window time.Duration) ([]string, error) {
+	var doc ipActivity
+	err := r.activity.FindOneAndUpdate(ctx,
+		bson.M{"_id": activityID(ip, windowStart)},
+		bson.M{
+			"$push":        bson.M{"emails": bson.M{"$each": bson.A{email}, "$slice": -maxTrackedEmails}},
+			"$setOnInsert": bson.M{"ip": ip, "expires_at": windowStart.Add(window)},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	// $addToSet can't be combined with $slice, so de-duplicate here
+	seen := map[string]bool{}
+	distinct := []string{}
+	for _, e := range doc.Emails {
+		if !seen[e] {
+			seen[e] = true
+			distinct = append(distinct, e)
+		}
+	}
+	return distinct, nil
+}
+
+// RecordInvalidVerify counts a failed login link verification from ip and
+// returns the count for the current window.
+func (r *AbuseRepo) RecordInvalidVerify(ctx context.Context, ip string, windowStart time.Time, window time.Duration) (int64, error) {
+	var doc ipActivity
+	err := r.activity.FindOneAndUpdate(ctx,
+		bson.M{"_id": activityID(ip, windowStart)},
+		bson.M{
+			"$inc":         bson.M{"invalid_verifies": 1},
+			"$setOnInsert": bson.M{"ip": ip, "expires_at": windowStart.Add(window)},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return 0, err
+	}
+	return doc.InvalidVerifies, nil
+}
+
+// Block stores or extends a block for ip. Returns true if the IP wasn't
+// already blocked, so callers only notify once.
+func (r *AbuseRepo) Block(ctx context.Context, block *models.IPBlock) (bool, error) {
+	result, err := r.blocks.UpdateOne(ctx,
+		bson.M{"_id": block.IP},
+		bson.M{
+			"$set": bson.M{
+				"reason":     block.Reason,
+				"details":    block.Details,
+				"expires_at": block.ExpiresAt,
+			},
+			"$setOnInsert": bson.M{"created_at": block.CreatedAt},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// FindActiveBlock returns the unexpired block for ip, or nil.
+func (r *AbuseRepo) FindActiveBlock(ctx context.Context, ip string) (*models.IPBlock, error) {
+	var block models.IPBlock
+	err := r.blocks.FindOne(ctx, bson.M{"_id": ip, "expires_at": bson.M{"$gt": time.Now()}}).Decode(&block)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *AbuseRepo) ListActiveBlocks(ctx context.Context) ([]models.IPBlock, error) {
+	cursor, err := r.blocks.Find(ctx,
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	blocks := []models.IPBlock{}
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Lift removes the block for ip. Returns false if it wasn't blocked.
+func (r *AbuseRepo) Lift(ctx context.Context, ip string) (bool, error) {
+	result, err := r.blocks.DeleteOne(ctx, bson.M{"_id": ip})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// ListActivity returns the unexpired activity windows for ip, newest first.
+func (r *AbuseRepo) ListActivity(ctx context.Context, ip string) ([]models.IPActivity, error) {
+	cursor, err := r.activity.Find(ctx,
+		bson.M{"ip": ip, "expires_at": bson.M{"$gt": time.Now()}},
+		options.Find().SetSort(bson.D{{Key: "expires_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	windows := []models.IPActivity{}
+	if err := cursor.All(ctx, &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// ClearActivity deletes every activity window for ip and returns how many
+// there were.
+func (r *AbuseRepo) ClearActivity(ctx context.Context, ip string) (int64, error) {
+	result, err := r.activity.DeleteMany(ctx, bson.M{"ip": ip})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureIndexes creates necessary indexes for the auth_ip_activity and ip_blocks collections
+func (r *AbuseRepo) EnsureIndexes(ctx context.Context) error {
+	ttl := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := r.activity.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		ttl,
+		{Keys: bson.D{{Key: "ip", Value: 1}}},
+	}); err != nil {
+		return err
+	}
+	_, err := r.blocks.Indexes().CreateOne(ctx, ttl)
+	return err
+}
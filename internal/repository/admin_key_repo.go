@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type AdminKeyRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAdminKeyRepo() *AdminKeyRepo {
+	return &AdminKeyRepo{
+		collection: database.GetCollection("admin_api_keys"),
+	}
+}
+
+func (r *AdminKeyRepo) Create(ctx context.Context, key *models.AdminKey) error {
+	key.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, key)
+	if err != nil {
+		return err
+	}
+	key.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// FindActiveByHash returns the unrevoked admin key with the given hash, or
+// nil. Sandbox keys are never returned.
+func (r *AdminKeyRepo) FindActiveByHash(ctx context.Context, hash string) (*models.AdminKey, error) {
+	return r.findActive(ctx, bson.M{"key_hash": hash, "sandbox": bson.M{"$ne": true}})
+}
+
+// FindSandboxByHash returns the unrevoked sandbox key with the given hash,
+// or nil.
+func (r *AdminKeyRepo) FindSandboxByHash(ctx context.Context, hash string) (*models.AdminKey, error) {
+	return r.findActive(ctx, bson.M{"key_hash": hash, "sandbox": true})
+}
+
+func (r *AdminKeyRepo) findActive(ctx context.Context, filter bson.M) (*models.AdminKey, error) {
+	filter["revoked_at"] = bson.M{"$exists": false}
+	var key models.AdminKey
+	err := r.collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *AdminKeyRepo) List(ctx context.Context) ([]models.AdminKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []models.AdminKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// UpdatePermissions replaces the grants on an unrevoked admin key. Returns
+// false if not found, or if it is a sandbox key.
+func (r *AdminKeyRepo) UpdatePermissions(ctx context.Context, id bson.ObjectID, permissions []string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}, "sandbox": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"permissions": permissions}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Revoke disables a key. Returns false if not found or already revoked.
+func (r *AdminKeyRepo) Revoke(ctx context.Context, id bson.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// EnsureIndexes creates necessary indexes for the admin_api_keys collection
+func (r *AdminKeyRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
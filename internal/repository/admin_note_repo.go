@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type AdminNoteRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAdminNoteRepo() *AdminNoteRepo {
+	return &AdminNoteRepo{
+		collection: database.GetCollection("admin_notes"),
+	}
+}
+
+func (r *AdminNoteRepo) Create(ctx context.Context, note *models.AdminNote) error {
+	note.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, note)
+	if err != nil {
+		return err
+	}
+	note.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// ListForUser returns a user's notes, newest first.
+func (r *AdminNoteRepo) ListForUser(ctx context.Context, userID bson.ObjectID) ([]models.AdminNote, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	notes := []models.AdminNote{}
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// Reassign moves every note from one user to another.
+func (r *AdminNoteRepo) Reassign(ctx context.Context, from, to bson.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"user_id": from}, bson.M{"$set": bson.M{"user_id": to}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// Delete removes a note from a user. Returns false if no such note exists.
+func (r *AdminNoteRepo) Delete(ctx context.Context, userID, noteID bson.ObjectID) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": noteID, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// EnsureIndexes creates necessary indexes for the admin_notes collection
+func (r *AdminNoteRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
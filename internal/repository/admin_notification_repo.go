@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// adminNotificationRetention is how long the notification center keeps
+// entries, read or not.
+const adminNotificationRetention = 30 * 24 * time.Hour
+
+type AdminNotificationRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAdminNotificationRepo() *AdminNotificationRepo {
+	return &AdminNotificationRepo{
+		collection: database.GetCollection("notifications"),
+	}
+}
+
+func (r *AdminNotificationRepo) Create(ctx context.Context, n *models.AdminNotification) error {
+	n.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, n)
+	if err != nil {
+		return err
+	}
+	n.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// AdminNotificationFilter narrows List. Zero fields match everything.
+type AdminNotificationFilter struct {
+	Kind       string
+	UnreadOnly bool
+	Before     bson.ObjectID // continue after the last entry of the previous page
+}
+
+func (f AdminNotificationFilter) query(admin string) bson.M {
+	query := bson.M{}
+	if f.Kind != "" {
+		query["kind"] = f.Kind
+	}
+	if f.UnreadOnly {
+		query["read_by"] = bson.M{"$ne": admin}
+	}
+	if !f.Before.IsZero() {
+		query["_id"] = bson.M{"$lt": f.Before}
+	}
+	return query
+}
+
+// List returns notifications newest first, with Read set for admin.
+func (r *AdminNotificationRepo) List(ctx context.Context, admin string, filter AdminNotificationFilter, limit int64) ([]models.AdminNotification, error) {
+	cursor, err := r.collection.Find(ctx, filter.query(admin), options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	notifications := []models.AdminNotification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	for i := range notifications {
+		for _, reader := range notifications[i].ReadBy {
+			if reader == admin {
+				notifications[i].Read = true
+				break
+			}
+		}
+	}
+	return notifications, nil
+}
+
+// CountUnread counts the notifications admin hasn't read.
+func (r *AdminNotificationRepo) CountUnread(ctx context.Context, admin string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"read_by": bson.M{"$ne": admin}})
+}
+
+// MarkRead marks the notifications as read by admin, or all of them when
+// ids is empty. Returns how many were newly marked.
+func (r *AdminNotificationRepo) MarkRead(ctx context.Context, admin string, ids []bson.ObjectID) (int64, error) {
+	filter := bson.M{"read_by": bson.M{"$ne": admin}}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"read_by": admin}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// EnsureIndexes creates necessary indexes for the notifications collection
+func (r *AdminNotificationRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(adminNotificationRetention.Seconds())),
+		},
+		{
+			Keys: bson.D{{Key: "kind", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	})
+	return err
+}
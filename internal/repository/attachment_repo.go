@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type AttachmentRepo struct {
+	collection *mongo.Collection
+	blobs      *mongo.Collection
+}
+
+func NewAttachmentRepo() *AttachmentRepo {
+	return &AttachmentRepo{
+		collection: database.GetCollection("attachments"),
+		blobs:      database.GetCollection("blobs"),
+	}
+}
+
+// PutBlob stores data under its hash unless it's already there. Returns
+// true if the blob was new.
+func (r *AttachmentRepo) PutBlob(ctx context.Context, sha256 string, data []byte) (bool, error) {
+	now := time.Now()
+	result, err := r.blobs.UpdateOne(ctx,
+		bson.M{"_id": sha256},
+		bson.M{
+			"$setOnInsert": bson.M{"data": data, "size": len(data), "created_at": now},
+			"$set":         bson.M{"last_referenced_at": now},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// FindBlob returns a blob including its data, or nil if it doesn't exist.
+func (r *AttachmentRepo) FindBlob(ctx context.Context, sha256 string) (*models.Blob, error) {
+	var blob models.Blob
+	err := r.blobs.FindOne(ctx, bson.M{"_id": sha256}).Decode(&blob)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &blob, nil
+}
+
+func (r *AttachmentRepo) Create(ctx context.Context, attachment *models.Attachment) error {
+	attachment.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, attachment)
+	if err != nil {
+		return err
+	}
+	attachment.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (r *AttachmentRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&attachment)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// ListForUser returns a user's attachments, newest first.
+func (r *AttachmentRepo) ListForUser(ctx context.Context, userID bson.ObjectID) ([]models.Attachment, error) {
+	cursor, err := database.Reader(ctx, r.collection).Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	attachments := []models.Attachment{}
+	if err := cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// UsageForUser returns the total size of a user's attachments in bytes.
+func (r *AttachmentRepo) UsageForUser(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "total": bson.M{"$sum": "$size"}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var results []struct {
+		Total int64 `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0].Total, nil
+}
+
+// Delete removes a user's attachment. The blob stays until GC finds it
+// unreferenced. Returns false if no such attachment exists.
+func (r *AttachmentRepo) Delete(ctx context.Context, userID, id bson.ObjectID) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// DeleteOrphanBlobs removes blobs no attachment or image variant references that haven't
+// been uploaded since cutoff. The cutoff gives an upload time to create its
+// attachment after storing the blob.
+func (r *AttachmentRepo) DeleteOrphanBlobs(ctx context.Context, cutoff time.Time) (int64, error) {
+	cursor, err := r.blobs.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"last_referenced_at": bson.M{"$lt": cutoff}}}},
+		{{Key: "$project", Value: bson.M{"_id": 1}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "attachments",
+			"localField":   "_id",
+			"foreignField": "sha256",
+			"pipeline":     bson.A{bson.M{"$limit": 1}, bson.M{"$project": bson.M{"_id": 1}}},
+			"as":           "refs",
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "attachments",
+			"localField":   "_id",
+			"foreignField": "variants.sha256",
+			"pipeline":     bson.A{bson.M{"$limit": 1}, bson.M{"$project": bson.M{"_id": 1}}},
+			"as":           "variant_refs",
+		}}},
+		{{Key: "$match", Value: bson.M{"refs": bson.M{"$size": 0}, "variant_refs": bson.M{"$size": 0}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var orphans []struct {
+		SHA256 string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &orphans); err != nil {
+		return 0, err
+	}
+	if len(orphans) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(orphans))
+	for i, o := range orphans {
+		ids[i] = o.SHA256
+	}
+	// Re-check the cutoff so a blob re-uploaded since the lookup survives
+	result, err := r.blobs.DeleteMany(ctx, bson.M{
+		"_id":                bson.M{"$in": ids},
+		"last_referenced_at": bson.M{"$lt": cutoff},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureIndexes creates necessary indexes for the attachments and blobs collections
+func (r *AttachmentRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "sha256", Value: 1}}},
+		{Keys: bson.D{{Key: "variants.sha256", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.blobs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "last_referenced_at", Value: 1}},
+	})
+	return err
+}
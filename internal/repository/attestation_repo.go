@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type AttestationRepo struct {
+	collection *mongo.Collection
+	challenges *mongo.Collection
+}
+
+func NewAttestationRepo() *AttestationRepo {
+	return &AttestationRepo{
+		collection: database.GetCollection("attestations"),
+		challenges: database.GetCollection("attestation_challenges"),
+	}
+}
+
+// CreateChallenge stores a one-time challenge the client must embed in its attestation.
+func (r *AttestationRepo) CreateChallenge(ctx context.Context, challenge string, ttl time.Duration) error {
+	_, err := r.challenges.InsertOne(ctx, bson.M{
+		"_id":        challenge,
+		"expires_at": time.Now().Add(ttl),
+	})
+	return err
+}
+
+// ConsumeChallenge deletes the challenge and reports whether it existed and was unexpired.
+func (r *AttestationRepo) ConsumeChallenge(ctx context.Context, challenge string) (bool, error) {
+	err := r.challenges.FindOneAndDelete(ctx, bson.M{
+		"_id":        challenge,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *AttestationRepo) Create(ctx context.Context, attestation *models.Attestation) error {
+	attestation.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, attestation)
+	if err != nil {
+		return err
+	}
+	attestation.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// ConsumePass marks a passing, unexpired attestation as used. Returns false if
+// the pass token is unknown, failed, expired or already used.
+func (r *AttestationRepo) ConsumePass(ctx context.Context, passToken string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"pass_token": passToken,
+		"passed":     true,
+		"is_used":    false,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, bson.M{"$set": bson.M{"is_used": true}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// EnsureIndexes creates necessary indexes for the attestation collections
+func (r *AttestationRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "pass_token", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "platform", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.challenges.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0), // TTL index — drop stale challenges
+	})
+	return err
+}
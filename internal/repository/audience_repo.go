@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type AudienceRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAudienceRepo() *AudienceRepo {
+	return &AudienceRepo{
+		collection: database.GetCollection("audiences"),
+	}
+}
+
+func (r *AudienceRepo) List(ctx context.Context) ([]models.Audience, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	audiences := []models.Audience{}
+	if err := cursor.All(ctx, &audiences); err != nil {
+		return nil, err
+	}
+	return audiences, nil
+}
+
+// FindByID returns the audience, or nil if there is none.
+func (r *AudienceRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.Audience, error) {
+	var audience models.Audience
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&audience)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &audience, nil
+}
+
+func (r *AudienceRepo) Create(ctx context.Context, audience *models.Audience) error {
+	now := time.Now()
+	audience.CreatedAt = now
+	audience.UpdatedAt = now
+	result, err := r.collection.InsertOne(ctx, audience)
+	if err != nil {
+		return err
+	}
+	audience.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// Update replaces the audience's name and filter. Returns nil, nil if the
+// audience doesn't exist.
+func (r *AudienceRepo) Update(ctx context.Context, id bson.ObjectID, name string, filter models.AudienceFilter) (*models.Audience, error) {
+	var audience models.Audience
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"name": name, "filter": filter, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&audience)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &audience, nil
+}
+
+// Delete removes the audience, reporting whether it existed.
+func (r *AudienceRepo) Delete(ctx context.Context, id bson.ObjectID) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// EnsureIndexes creates necessary indexes for the audiences collection
+func (r *AudienceRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "name", Value: 1}},
+	})
+	return err
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type AuditRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAuditRepo() *AuditRepo {
+	return &AuditRepo{
+		collection: database.GetCollection("audit_log"),
+	}
+}
+
+func (r *AuditRepo) Record(ctx context.Context, entry *models.AuditEntry) error {
+	entry.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+	entry.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// EnsureIndexes creates necessary indexes for the audit_log collection
+func (r *AuditRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"rizon-backend/internal/clock"
 	"rizon-backend/internal/database"
 	"rizon-backend/internal/models"
 
@@ -14,16 +15,24 @@ import (
 
 type AuthTokenRepo struct {
 	collection *mongo.Collection
+	clock      clock.Clock
 }
 
 func NewAuthTokenRepo() *AuthTokenRepo {
 	return &AuthTokenRepo{
 		collection: database.GetCollection("auth_tokens"),
+		clock:      clock.Real,
 	}
 }
 
+// WithClock replaces the wall clock, e.g. with a clock.Fake.
+func (r *AuthTokenRepo) WithClock(c clock.Clock) *AuthTokenRepo {
+	r.clock = c
+	return r
+}
+
 func (r *AuthTokenRepo) Create(ctx context.Context, token *models.AuthToken) error {
-	token.CreatedAt = time.Now()
+	token.CreatedAt = r.clock.Now()
 	result, err := r.collection.InsertOne(ctx, token)
 	if err != nil {
 		return err
@@ -44,17 +53,81 @@ func (r *AuthTokenRepo) FindByToken(ctx context.Context, token string) (*models.
 	return &authToken, nil
 }
 
-func (r *AuthTokenRepo) MarkUsed(ctx context.Context, token string) error {
-	_, err := r.collection.UpdateOne(ctx, bson.M{"token": token}, bson.M{
-		"$set": bson.M{"is_used": true},
-	})
-	return err
+// FindLatestWithCode returns the newest token for the email that was sent
+// with a code, used or not, or nil if there is none.
+func (r *AuthTokenRepo) FindLatestWithCode(ctx context.Context, email string) (*models.AuthToken, error) {
+	var authToken models.AuthToken
+	err := r.collection.FindOne(ctx,
+		bson.M{"email": email, "code_hash": bson.M{"$exists": true}},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&authToken)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &authToken, nil
+}
+
+// CountCodeAttempt records an attempt at a token's code. It returns false
+// without recording it once max attempts have been made.
+func (r *AuthTokenRepo) CountCodeAttempt(ctx context.Context, id bson.ObjectID, max int) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "code_attempts": bson.M{"$not": bson.M{"$gte": max}}},
+		bson.M{"$inc": bson.M{"code_attempts": 1}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Claim marks an unused, unexpired token used and returns it, or nil if
+// there is no such token. Only one of several concurrent claims of the same
+// token gets it.
+func (r *AuthTokenRepo) Claim(ctx context.Context, token string) (*models.AuthToken, error) {
+	var authToken models.AuthToken
+	err := r.collection.FindOneAndUpdate(ctx,
+		// Expired once past expires_at, as in AuthToken.IsExpired
+		bson.M{"token": token, "is_used": false, "expires_at": bson.M{"$gte": r.clock.Now()}},
+		bson.M{"$set": bson.M{"is_used": true}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&authToken)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &authToken, nil
+}
+
+// InvalidateAllForEmail retires every unused, unexpired token for the email
+// created before the given time, so only the newest link works. Comparing
+// creation times (rather than excluding one token) keeps the newest link
+// valid when two requests race. Returns the number invalidated.
+func (r *AuthTokenRepo) InvalidateAllForEmail(ctx context.Context, email string, before time.Time) (int64, error) {
+	now := r.clock.Now()
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{
+			"email":      email,
+			"is_used":    false,
+			"expires_at": bson.M{"$gt": now},
+			"created_at": bson.M{"$lt": before},
+		},
+		bson.M{"$set": bson.M{"is_used": true, "superseded_at": now}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
 }
 
 // CountRecentByEmail counts how many tokens were created for an email in the given duration.
 // Used for rate limiting.
 func (r *AuthTokenRepo) CountRecentByEmail(ctx context.Context, email string, duration time.Duration) (int64, error) {
-	since := time.Now().Add(-duration)
+	since := r.clock.Now().Add(-duration)
 	count, err := r.collection.CountDocuments(ctx, bson.M{
 		"email":      email,
 		"created_at": bson.M{"$gte": since},
@@ -66,14 +139,14 @@ func (r *AuthTokenRepo) CountRecentByEmail(ctx context.Context, email string, du
 func (r *AuthTokenRepo) EnsureIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
-			Keys: bson.D{{Key: "token", Value: 1}},
+			Keys:    bson.D{{Key: "token", Value: 1}},
 			Options: options.Index().SetUnique(true),
 		},
 		{
 			Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}},
 		},
 		{
-			Keys: bson.D{{Key: "expires_at", Value: 1}},
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
 			Options: options.Index().SetExpireAfterSeconds(0), // TTL index — auto-delete expired tokens
 		},
 	}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Benchmarks for the repository calls on the sign-in and feedback paths. They
+// need a MongoDB to run against, given in BENCH_MONGODB_URI, and use a
+// throwaway database that is dropped afterwards. Besides ns/op each reports
+// p95-ms, which `make bench-gate` checks against the budgets in the Makefile.
+
+var benchDB string
+
+func TestMain(m *testing.M) {
+	if uri := os.Getenv("BENCH_MONGODB_URI"); uri != "" {
+		benchDB = "rizon_bench_" + uuid.New().String()[:8]
+		if err := database.Connect(uri, benchDB); err != nil {
+			log.Fatalf("❌ Failed to connect to BENCH_MONGODB_URI: %v", err)
+		}
+	}
+	code := m.Run()
+	if benchDB != "" {
+		if err := database.DB.Drop(context.Background()); err != nil {
+			log.Printf("Error dropping %s: %v", benchDB, err)
+		}
+	}
+	os.Exit(code)
+}
+
+func requireMongo(b *testing.B) {
+	b.Helper()
+	if benchDB == "" {
+		b.Skip("BENCH_MONGODB_URI not set")
+	}
+}
+
+// measure runs op b.N times, timing each call, and reports the p95.
+func measure(b *testing.B, op func(ctx context.Context, i int) error) {
+	b.Helper()
+	ctx := context.Background()
+	latencies := make([]time.Duration, 0, b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := time.Now()
+		if err := op(ctx, i); err != nil {
+			b.Fatal(err)
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+	b.StopTimer()
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p95 := latencies[(len(latencies)*95)/100]
+	b.ReportMetric(float64(p95.Microseconds())/1000, "p95-ms")
+}
+
+func seedFeedback(b *testing.B, repo *FeedbackRepo, userID bson.ObjectID, n int) []string {
+	b.Helper()
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = uuid.New().String()
+		if _, err := repo.Create(context.Background(), &models.Feedback{
+			UserID:         userID,
+			Text:           fmt.Sprintf("Seeded feedback %d", i),
+			Rating:         4,
+			IdempotencyKey: keys[i],
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return keys
+}
+
+func newBenchFeedbackRepo(b *testing.B) *FeedbackRepo {
+	b.Helper()
+	repo := NewFeedbackRepo()
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	return repo
+}
+
+func BenchmarkFeedbackCreate(b *testing.B) {
+	requireMongo(b)
+	repo := newBenchFeedbackRepo(b)
+	userID := bson.NewObjectID()
+	measure(b, func(ctx context.Context, i int) error {
+		_, err := repo.Create(ctx, &models.Feedback{
+			UserID:         userID,
+			Text:           "Benchmark feedback",
+			Rating:         5,
+			IdempotencyKey: uuid.New().String(),
+		})
+		return err
+	})
+}
+
+func BenchmarkFeedbackFindByIdempotencyKey(b *testing.B) {
+	requireMongo(b)
+	repo := newBenchFeedbackRepo(b)
+	userID := bson.NewObjectID()
+	keys := seedFeedback(b, repo, userID, 500)
+	measure(b, func(ctx context.Context, i int) error {
+		_, err := repo.FindByIdempotencyKey(ctx, userID, keys[i%len(keys)])
+		return err
+	})
+}
+
+func BenchmarkFeedbackListForUser(b *testing.B) {
+	requireMongo(b)
+	repo := newBenchFeedbackRepo(b)
+	userID := bson.NewObjectID()
+	seedFeedback(b, repo, userID, 50)
+	measure(b, func(ctx context.Context, i int) error {
+		_, err := repo.ListForUser(ctx, userID)
+		return err
+	})
+}
+
+func BenchmarkFeedbackListNewestFirst(b *testing.B) {
+	requireMongo(b)
+	repo := newBenchFeedbackRepo(b)
+	seedFeedback(b, repo, bson.NewObjectID(), 500)
+	since := time.Now().Add(-time.Hour)
+	measure(b, func(ctx context.Context, i int) error {
+		_, err := repo.ListNewestFirst(ctx, since, nil, 50)
+		return err
+	})
+}
+
+func BenchmarkAuthTokenFindByToken(b *testing.B) {
+	requireMongo(b)
+	repo := NewAuthTokenRepo()
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	tokens := make([]string, 500)
+	for i := range tokens {
+		tokens[i] = uuid.New().String()
+		if err := repo.Create(context.Background(), &models.AuthToken{
+			Email:     fmt.Sprintf("bench%d@example.com", i),
+			Token:     tokens[i],
+			ExpiresAt: time.Now().Add(15 * time.Minute),
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	measure(b, func(ctx context.Context, i int) error {
+		_, err := repo.FindByToken(ctx, tokens[i%len(tokens)])
+		return err
+	})
+}
+
+func BenchmarkUserFindOrCreate(b *testing.B) {
+	requireMongo(b)
+	repo := NewUserRepo()
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	// Mostly returning users, as on sign-in
+	measure(b, func(ctx context.Context, i int) error {
+		_, err := repo.FindOrCreate(ctx, fmt.Sprintf("bench%d@example.com", i%200))
+		return err
+	})
+}
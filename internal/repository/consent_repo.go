@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ConsentRepo struct {
+	collection *mongo.Collection
+}
+
+func NewConsentRepo() *ConsentRepo {
+	return &ConsentRepo{
+		collection: database.GetCollection("consents"),
+	}
+}
+
+func (r *ConsentRepo) Create(ctx context.Context, consent *models.Consent) error {
+	if consent.CreatedAt.IsZero() {
+		consent.CreatedAt = time.Now()
+	}
+	result, err := r.collection.InsertOne(ctx, consent)
+	if err != nil {
+		return err
+	}
+	consent.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// Reassign moves every consent record from one user to another.
+func (r *ConsentRepo) Reassign(ctx context.Context, from, to bson.ObjectID) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"user_id": from}, bson.M{"$set": bson.M{"user_id": to}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// Latest returns the user's most recent consent, or nil if they never gave one.
+func (r *ConsentRepo) Latest(ctx context.Context, userID bson.ObjectID) (*models.Consent, error) {
+	var consent models.Consent
+	err := database.Reader(ctx, r.collection).FindOne(ctx,
+		bson.M{"user_id": userID},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&consent)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// ListForUser returns the user's full consent history, newest first.
+func (r *ConsentRepo) ListForUser(ctx context.Context, userID bson.ObjectID) ([]models.Consent, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	consents := []models.Consent{}
+	if err := cursor.All(ctx, &consents); err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+// EnsureIndexes creates necessary indexes for the consents collection
+func (r *ConsentRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
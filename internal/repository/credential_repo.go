@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CredentialRepo stores users' passkeys and the challenges of passkey
+// ceremonies in progress.
+type CredentialRepo struct {
+	collection *mongo.Collection
+	challenges *mongo.Collection
+	clock      clock.Clock
+}
+
+func NewCredentialRepo() *CredentialRepo {
+	return &CredentialRepo{
+		collection: database.GetCollection("credentials"),
+		challenges: database.GetCollection("credential_challenges"),
+		clock:      clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake.
+func (r *CredentialRepo) WithClock(c clock.Clock) *CredentialRepo {
+	r.clock = c
+	return r
+}
+
+// Create stores a new passkey. It returns a duplicate key error if the
+// credential ID is already registered.
+func (r *CredentialRepo) Create(ctx context.Context, cred *models.Credential) error {
+	cred.CreatedAt = r.clock.Now()
+	result, err := r.collection.InsertOne(ctx, cred)
+	if err != nil {
+		return err
+	}
+	cred.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (r *CredentialRepo) FindByCredentialID(ctx context.Context, credentialID string) (*models.Credential, error) {
+	var cred models.Credential
+	err := r.collection.FindOne(ctx, bson.M{"credential_id": credentialID}).Decode(&cred)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cred, nil
+}
+
+// ListForUser returns a user's passkeys, oldest first.
+func (r *CredentialRepo) ListForUser(ctx context.Context, userID bson.ObjectID) ([]models.Credential, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	creds := []models.Credential{}
+	if err := cursor.All(ctx, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+func (r *CredentialRepo) CountForUser(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"user_id": userID})
+}
+
+// RecordUse saves the signature counter after a sign-in. It only applies if
+// the counter is still at the value the assertion was checked against, so
+// two concurrent sign-ins can't both move it.
+func (r *CredentialRepo) RecordUse(ctx context.Context, id bson.ObjectID, previousCount, signCount int64) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "sign_count": previousCount},
+		bson.M{"$set": bson.M{"sign_count": signCount, "last_used_at": r.clock.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Delete removes one of a user's passkeys and reports whether it existed.
+func (r *CredentialRepo) Delete(ctx context.Context, userID, id bson.ObjectID) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// CreateChallenge stores a one-time challenge for a passkey ceremony.
+func (r *CredentialRepo) CreateChallenge(ctx context.Context, challenge *models.CredentialChallenge, ttl time.Duration) error {
+	challenge.ExpiresAt = r.clock.Now().Add(ttl)
+	_, err := r.challenges.InsertOne(ctx, challenge)
+	return err
+}
+
+// ConsumeChallenge deletes an unexpired challenge issued for purpose and
+// returns it, or nil if there is none.
+func (r *CredentialRepo) ConsumeChallenge(ctx context.Context, challenge, purpose string) (*models.CredentialChallenge, error) {
+	var found models.CredentialChallenge
+	err := r.challenges.FindOneAndDelete(ctx, bson.M{
+		"_id":        challenge,
+		"purpose":    purpose,
+		"expires_at": bson.M{"$gt": r.clock.Now()},
+	}).Decode(&found)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &found, nil
+}
+
+// EnsureIndexes creates necessary indexes for the passkey collections
+func (r *CredentialRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "credential_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.challenges.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0), // TTL index — drop abandoned ceremonies
+	})
+	return err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/crypto"
+	"rizon-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DataKeyRepo stores per-user wrapped data keys for envelope encryption.
+type DataKeyRepo struct {
+	collection *mongo.Collection
+}
+
+func NewDataKeyRepo() *DataKeyRepo {
+	return &DataKeyRepo{
+		collection: database.GetCollection("data_keys"),
+	}
+}
+
+type dataKeyDoc struct {
+	OwnerID   string    `bson:"_id"`
+	Version   string    `bson:"version"`
+	Wrapped   []byte    `bson:"wrapped"`
+	CreatedAt time.Time `bson:"created_at"`
+	RotatedAt time.Time `bson:"rotated_at,omitempty"`
+}
+
+func (r *DataKeyRepo) GetDataKey(ctx context.Context, ownerID string) (*crypto.WrappedKey, error) {
+	var doc dataKeyDoc
+	err := r.collection.FindOne(ctx, bson.M{"_id": ownerID}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &crypto.WrappedKey{Version: doc.Version, Ciphertext: doc.Wrapped}, nil
+}
+
+func (r *DataKeyRepo) CreateDataKey(ctx context.Context, ownerID string, key *crypto.WrappedKey) (*crypto.WrappedKey, error) {
+	_, err := r.collection.InsertOne(ctx, dataKeyDoc{
+		OwnerID:   ownerID,
+		Version:   key.Version,
+		Wrapped:   key.Ciphertext,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return r.GetDataKey(ctx, ownerID)
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *DataKeyRepo) ListDataKeysNotWrappedWith(ctx context.Context, version string, limit int) (map[string]*crypto.WrappedKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"version": bson.M{"$ne": version}}, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	var docs []dataKeyDoc
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*crypto.WrappedKey, len(docs))
+	for _, doc := range docs {
+		keys[doc.OwnerID] = &crypto.WrappedKey{Version: doc.Version, Ciphertext: doc.Wrapped}
+	}
+	return keys, nil
+}
+
+func (r *DataKeyRepo) ReplaceDataKey(ctx context.Context, ownerID string, key *crypto.WrappedKey) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": ownerID}, bson.M{
+		"$set": bson.M{"version": key.Version, "wrapped": key.Ciphertext, "rotated_at": time.Now()},
+	})
+	return err
+}
+
+// EnsureIndexes creates necessary indexes for the data_keys collection
+func (r *DataKeyRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "version", Value: 1}},
+	})
+	return err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type DeviceRepo struct {
+	collection *mongo.Collection
+}
+
+func NewDeviceRepo() *DeviceRepo {
+	return &DeviceRepo{
+		collection: database.GetCollection("devices"),
+	}
+}
+
+// Touch records that the device was seen at the given time, creating it on
+// first sight. Client details are only overwritten when sent.
+func (r *DeviceRepo) Touch(ctx context.Context, userID bson.ObjectID, deviceID string, client *models.ClientInfo, at time.Time) error {
+	set := bson.M{"last_seen_at": at}
+	if !client.IsZero() {
+		set["client"] = client
+	}
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "device_id": deviceID, "last_seen_at": bson.M{"$not": bson.M{"$gt": at}}},
+		bson.M{
+			"$set":         set,
+			"$setOnInsert": bson.M{"first_seen_at": at},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		// A newer sighting was already saved (e.g. by another replica)
+		return nil
+	}
+	return err
+}
+
+// ListForUser returns a user's devices, most recently seen first.
+func (r *DeviceRepo) ListForUser(ctx context.Context, userID bson.ObjectID) ([]models.Device, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	devices := []models.Device{}
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// EnsureIndexes creates necessary indexes for the devices collection
+func (r *DeviceRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "device_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "last_seen_at", Value: -1}}},
+	})
+	return err
+}
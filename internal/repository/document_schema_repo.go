@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DocumentSchemaRepo stores the JSON Schemas for free-form documents, one
+// document per schema name.
+type DocumentSchemaRepo struct {
+	collection *mongo.Collection
+}
+
+func NewDocumentSchemaRepo() *DocumentSchemaRepo {
+	return &DocumentSchemaRepo{
+		collection: database.GetCollection("document_schemas"),
+	}
+}
+
+func (r *DocumentSchemaRepo) List(ctx context.Context) ([]models.DocumentSchema, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	schemas := []models.DocumentSchema{}
+	if err := cursor.All(ctx, &schemas); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+// Get returns the named schema, or nil if none is set.
+func (r *DocumentSchemaRepo) Get(ctx context.Context, name string) (*models.DocumentSchema, error) {
+	var schema models.DocumentSchema
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&schema)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// Put saves the schema text under name, bumping its version, and returns the
+// stored document.
+func (r *DocumentSchemaRepo) Put(ctx context.Context, name, schema, updatedBy string) (*models.DocumentSchema, error) {
+	var saved models.DocumentSchema
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": name},
+		bson.M{
+			"$set": bson.M{"schema": schema, "updated_by": updatedBy, "updated_at": time.Now()},
+			"$inc": bson.M{"version": 1},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&saved)
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// Delete removes the schema, reporting whether it existed.
+func (r *DocumentSchemaRepo) Delete(ctx context.Context, name string) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type EmailSuppressionRepo struct {
+	collection *mongo.Collection
+}
+
+func NewEmailSuppressionRepo() *EmailSuppressionRepo {
+	return &EmailSuppressionRepo{
+		collection: database.GetCollection("email_suppressions"),
+	}
+}
+
+// List returns suppressions newest first.
+func (r *EmailSuppressionRepo) List(ctx context.Context, limit int64) ([]models.EmailSuppression, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	suppressions := []models.EmailSuppression{}
+	if err := cursor.All(ctx, &suppressions); err != nil {
+		return nil, err
+	}
+	return suppressions, nil
+}
+
+// IsSuppressed reports whether queued email to the address is suppressed.
+func (r *EmailSuppressionRepo) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": strings.ToLower(email)}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Add suppresses the address, replacing the reason of an existing entry.
+func (r *EmailSuppressionRepo) Add(ctx context.Context, s *models.EmailSuppression) error {
+	s.Email = strings.ToLower(s.Email)
+	s.CreatedAt = time.Now()
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": s.Email}, s, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Remove lifts the suppression, reporting whether there was one.
+func (r *EmailSuppressionRepo) Remove(ctx context.Context, email string) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": strings.ToLower(email)})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// EnsureIndexes creates necessary indexes for the email_suppressions collection
+func (r *EmailSuppressionRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}},
+	})
+	return err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// emailThrottleID is the _id of the single throttle settings document.
+const emailThrottleID = "throttle"
+
+type EmailThrottleRepo struct {
+	collection *mongo.Collection
+}
+
+func NewEmailThrottleRepo() *EmailThrottleRepo {
+	return &EmailThrottleRepo{
+		collection: database.GetCollection("email_settings"),
+	}
+}
+
+// Get returns the saved throttle settings, or nil if an admin never set any.
+func (r *EmailThrottleRepo) Get(ctx context.Context) (*models.EmailThrottle, error) {
+	var throttle models.EmailThrottle
+	err := r.collection.FindOne(ctx, bson.M{"_id": emailThrottleID}).Decode(&throttle)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// Save replaces the throttle settings.
+func (r *EmailThrottleRepo) Save(ctx context.Context, throttle *models.EmailThrottle) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": emailThrottleID},
+		bson.M{"$set": throttle},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
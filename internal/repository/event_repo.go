@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EventRepo stores product analytics events.
+type EventRepo struct {
+	collection *mongo.Collection
+}
+
+func NewEventRepo() *EventRepo {
+	return &EventRepo{
+		collection: database.GetCollection("events"),
+	}
+}
+
+// Exposure is one user seeing one experiment variant.
+type Exposure struct {
+	UserID     bson.ObjectID
+	Experiment string
+	Variant    string
+	At         time.Time
+}
+
+// RecordExposures upserts one event per user, experiment and variant,
+// keeping the first exposure time and moving the last.
+func (r *EventRepo) RecordExposures(ctx context.Context, exposures []Exposure) error {
+	if len(exposures) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(exposures))
+	for i, e := range exposures {
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"type":                  models.EventExperimentExposure,
+				"user_id":               e.UserID,
+				"properties.experiment": e.Experiment,
+				"properties.variant":    e.Variant,
+			}).
+			SetUpdate(bson.M{
+				"$setOnInsert": bson.M{"created_at": e.At},
+				"$max":         bson.M{"last_at": e.At},
+			}).
+			SetUpsert(true)
+	}
+	_, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// ExposureResults counts exposed users per variant of an experiment.
+func (r *EventRepo) ExposureResults(ctx context.Context, experiment string) ([]models.ExperimentResult, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"type": models.EventExperimentExposure, "properties.experiment": experiment}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":               "$properties.variant",
+			"users":             bson.M{"$sum": 1},
+			"first_exposure_at": bson.M{"$min": "$created_at"},
+			"last_exposure_at":  bson.M{"$max": "$last_at"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := []models.ExperimentResult{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// EnsureIndexes creates necessary indexes for the events collection
+func (r *EventRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "properties.experiment", Value: 1}, {Key: "properties.variant", Value: 1}, {Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
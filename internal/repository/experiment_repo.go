@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ExperimentRepo struct {
+	collection *mongo.Collection
+}
+
+func NewExperimentRepo() *ExperimentRepo {
+	return &ExperimentRepo{
+		collection: database.GetCollection("experiments"),
+	}
+}
+
+func (r *ExperimentRepo) List(ctx context.Context) ([]models.Experiment, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	experiments := []models.Experiment{}
+	if err := cursor.All(ctx, &experiments); err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// Get returns the experiment, or nil if there is none.
+func (r *ExperimentRepo) Get(ctx context.Context, key string) (*models.Experiment, error) {
+	var experiment models.Experiment
+	err := r.collection.FindOne(ctx, bson.M{"key": key}).Decode(&experiment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// Upsert saves an experiment's definition, creating it if needed.
+func (r *ExperimentRepo) Upsert(ctx context.Context, experiment *models.Experiment) error {
+	experiment.UpdatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"key": experiment.Key},
+		bson.M{"$set": bson.M{
+			"description":     experiment.Description,
+			"status":          experiment.Status,
+			"traffic_percent": experiment.TrafficPercent,
+			"variants":        experiment.Variants,
+			"flag_key":        experiment.FlagKey,
+			"updated_by":      experiment.UpdatedBy,
+			"updated_at":      experiment.UpdatedAt,
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// Delete removes the experiment, reporting whether it existed.
+func (r *ExperimentRepo) Delete(ctx context.Context, key string) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"key": key})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// EnsureIndexes creates necessary indexes for the experiments collection
+func (r *ExperimentRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ExportRepo struct {
+	collection *mongo.Collection
+}
+
+func NewExportRepo() *ExportRepo {
+	return &ExportRepo{
+		collection: database.GetCollection("exports"),
+	}
+}
+
+func (r *ExportRepo) Create(ctx context.Context, export *models.Export) error {
+	export.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, export)
+	if err != nil {
+		return err
+	}
+	export.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// FindByID returns the export including its data, or nil if it doesn't exist.
+func (r *ExportRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.Export, error) {
+	var export models.Export
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&export)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Latest returns the user's most recent export without its data.
+func (r *ExportRepo) Latest(ctx context.Context, userID bson.ObjectID) (*models.Export, error) {
+	var export models.Export
+	err := r.collection.FindOne(ctx,
+		bson.M{"user_id": userID},
+		options.FindOne().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetProjection(bson.M{"data": 0}),
+	).Decode(&export)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// SetReady stores the generated file.
+func (r *ExportRepo) SetReady(ctx context.Context, id bson.ObjectID, data []byte) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"status":   models.ExportReady,
+		"data":     data,
+		"size":     len(data),
+		"ready_at": time.Now(),
+	}})
+	return err
+}
+
+func (r *ExportRepo) SetStatus(ctx context.Context, id bson.ObjectID, status string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
+
+// EnsureIndexes creates necessary indexes for the exports collection
+func (r *ExportRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
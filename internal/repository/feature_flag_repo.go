@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type FeatureFlagRepo struct {
+	collection *mongo.Collection
+}
+
+func NewFeatureFlagRepo() *FeatureFlagRepo {
+	return &FeatureFlagRepo{
+		collection: database.GetCollection("feature_flags"),
+	}
+}
+
+func (r *FeatureFlagRepo) List(ctx context.Context) ([]models.FeatureFlag, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	flags := []models.FeatureFlag{}
+	if err := cursor.All(ctx, &flags); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// Upsert saves a flag's rollout settings, creating the flag if needed.
+func (r *FeatureFlagRepo) Upsert(ctx context.Context, flag *models.FeatureFlag) error {
+	flag.UpdatedAt = time.Now()
+	if flag.UserIDs == nil {
+		flag.UserIDs = []string{}
+	}
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"key": flag.Key},
+		bson.M{"$set": bson.M{
+			"enabled":    flag.Enabled,
+			"percentage": flag.Percentage,
+			"user_ids":   flag.UserIDs,
+			"updated_at": flag.UpdatedAt,
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *FeatureFlagRepo) Delete(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"key": key})
+	return err
+}
+
+// EnsureIndexes creates necessary indexes for the feature_flags collection
+func (r *FeatureFlagRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
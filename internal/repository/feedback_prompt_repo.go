@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// rulesID is the _id of the single rules document.
+const rulesID = "default"
+
+type FeedbackPromptRepo struct {
+	rules  *mongo.Collection
+	states *mongo.Collection
+}
+
+func NewFeedbackPromptRepo() *FeedbackPromptRepo {
+	return &FeedbackPromptRepo{
+		rules:  database.GetCollection("feedback_prompt_rules"),
+		states: database.GetCollection("feedback_prompt_states"),
+	}
+}
+
+// Rules returns the saved rules, or the defaults if none were saved.
+func (r *FeedbackPromptRepo) Rules(ctx context.Context) (*models.FeedbackPromptRules, error) {
+	var rules models.FeedbackPromptRules
+	err := r.rules.FindOne(ctx, bson.M{"_id": rulesID}).Decode(&rules)
+	if err == mongo.ErrNoDocuments {
+		defaults := models.DefaultFeedbackPromptRules
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (r *FeedbackPromptRepo) SaveRules(ctx context.Context, rules *models.FeedbackPromptRules) error {
+	rules.UpdatedAt = time.Now()
+	_, err := r.rules.ReplaceOne(ctx, bson.M{"_id": rulesID}, rules, options.Replace().SetUpsert(true))
+	return err
+}
+
+// State returns a user's prompt history; users never prompted get an empty state.
+func (r *FeedbackPromptRepo) State(ctx context.Context, userID bson.ObjectID) (*models.FeedbackPromptState, error) {
+	var state models.FeedbackPromptState
+	err := r.states.FindOne(ctx, bson.M{"_id": userID}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return &models.FeedbackPromptState{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// RecordPrompt counts a prompt shown to the user.
+func (r *FeedbackPromptRepo) RecordPrompt(ctx context.Context, userID bson.ObjectID, at time.Time) error {
+	_, err := r.states.UpdateOne(ctx, bson.M{"_id": userID},
+		bson.M{"$inc": bson.M{"prompt_count": 1}, "$set": bson.M{"last_prompted_at": at}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// RecordNegative notes a bad experience, which holds prompts back for a while.
+func (r *FeedbackPromptRepo) RecordNegative(ctx context.Context, userID bson.ObjectID, at time.Time) error {
+	_, err := r.states.UpdateOne(ctx, bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"last_negative_at": at}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"rizon-backend/internal/crypto"
 	"rizon-backend/internal/database"
 	"rizon-backend/internal/models"
 
@@ -14,6 +16,7 @@ import (
 
 type FeedbackRepo struct {
 	collection *mongo.Collection
+	envelope   *crypto.Envelope
 }
 
 func NewFeedbackRepo() *FeedbackRepo {
@@ -22,40 +25,553 @@ func NewFeedbackRepo() *FeedbackRepo {
 	}
 }
 
-func (r *FeedbackRepo) Create(ctx context.Context, feedback *models.Feedback) error {
+// coll is the feedbacks collection of ctx's sandbox or data residency
+// region.
+func (r *FeedbackRepo) coll(ctx context.Context) *mongo.Collection {
+	return database.Scoped(ctx, r.collection)
+}
+
+// WithEncryption enables at-rest encryption of feedback text with the author's data key.
+// Existing plaintext documents remain readable.
+func (r *FeedbackRepo) WithEncryption(envelope *crypto.Envelope) *FeedbackRepo {
+	r.envelope = envelope
+	return r
+}
+
+// Create stores the feedback. If a concurrent request by the same user
+// already stored one with the same idempotency key, the unique index
+// rejects this one and the stored feedback is returned instead. Any other
+// duplicate key error is returned as is.
+func (r *FeedbackRepo) Create(ctx context.Context, feedback *models.Feedback) (existing *models.Feedback, err error) {
 	feedback.CreatedAt = time.Now()
-	result, err := r.collection.InsertOne(ctx, feedback)
+	if feedback.Status == "" {
+		feedback.Status = models.FeedbackStatusOpen
+	}
+
+	// Encrypt a copy so the caller keeps the plaintext
+	doc := *feedback
+	doc.Text, err = r.encrypt(ctx, feedback.UserID, feedback.Text)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	result, err := r.coll(ctx).InsertOne(ctx, doc)
+	if mongo.IsDuplicateKeyError(err) && feedback.IdempotencyKey != "" {
+		existing, findErr := r.FindByIdempotencyKey(ctx, feedback.UserID, feedback.IdempotencyKey)
+		if findErr != nil {
+			return nil, findErr
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 	feedback.ID = result.InsertedID.(bson.ObjectID)
+	return nil, nil
+}
+
+// decrypt restores plaintext fields on a feedback document read from Mongo.
+func (r *FeedbackRepo) decrypt(ctx context.Context, feedback *models.Feedback) error {
+	if r.envelope == nil {
+		return nil
+	}
+	texts := []*string{&feedback.Text}
+	for i := range feedback.Edits {
+		texts = append(texts, &feedback.Edits[i].Text)
+	}
+	for _, text := range texts {
+		if !crypto.IsEncrypted(*text) {
+			continue
+		}
+		plain, err := r.envelope.Decrypt(ctx, feedback.UserID.Hex(), *text)
+		if err != nil {
+			return err
+		}
+		*text = plain
+	}
 	return nil
 }
 
-// FindByIdempotencyKey checks if feedback with this key already exists (duplicate prevention)
-func (r *FeedbackRepo) FindByIdempotencyKey(ctx context.Context, key string) (*models.Feedback, error) {
+// encrypt returns text as stored for the author: encrypted when at-rest
+// encryption is on.
+func (r *FeedbackRepo) encrypt(ctx context.Context, userID bson.ObjectID, text string) (string, error) {
+	if r.envelope == nil {
+		return text, nil
+	}
+	return r.envelope.Encrypt(ctx, userID.Hex(), text)
+}
+
+// FindByIdempotencyKey returns the user's feedback submitted with key, or
+// nil. Keys are only unique per user, so one user's key never finds
+// another's feedback.
+func (r *FeedbackRepo) FindByIdempotencyKey(ctx context.Context, userID bson.ObjectID, key string) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := r.coll(ctx).FindOne(ctx, bson.M{"user_id": userID, "idempotency_key": key}).Decode(&feedback)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.decrypt(ctx, &feedback); err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+func (r *FeedbackRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.Feedback, error) {
 	var feedback models.Feedback
-	err := r.collection.FindOne(ctx, bson.M{"idempotency_key": key}).Decode(&feedback)
+	err := database.Reader(ctx, r.coll(ctx)).FindOne(ctx, bson.M{"_id": id}).Decode(&feedback)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
 		return nil, err
 	}
+	if err := r.decrypt(ctx, &feedback); err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+// FindRegion returns the data residency region whose collection holds the
+// feedback, "" being the default one. found is false if none does.
+func (r *FeedbackRepo) FindRegion(ctx context.Context, id bson.ObjectID) (region string, found bool, err error) {
+	for _, region := range append([]string{""}, database.Regions()...) {
+		regional := database.WithRegion(ctx, region)
+		n, err := r.coll(regional).CountDocuments(regional, bson.M{"_id": id}, options.Count().SetLimit(1))
+		if err != nil {
+			return "", false, err
+		}
+		if n > 0 {
+			return region, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// UpdateStatus sets the feedback status, stamping resolved_at when resolving.
+// Returns false if the feedback doesn't exist.
+func (r *FeedbackRepo) UpdateStatus(ctx context.Context, id bson.ObjectID, status string) (bool, error) {
+	update := bson.M{"$set": bson.M{"status": status}}
+	if status == models.FeedbackStatusResolved {
+		update["$set"].(bson.M)["resolved_at"] = time.Now()
+	} else {
+		update["$unset"] = bson.M{"resolved_at": ""}
+	}
+	result, err := r.coll(ctx).UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Assign sets who is handling the feedback. Returns false if it doesn't exist.
+func (r *FeedbackRepo) Assign(ctx context.Context, id bson.ObjectID, assignee string) (bool, error) {
+	result, err := r.coll(ctx).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"assignee": assignee, "assigned_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// ListForUser returns all of a user's feedback, oldest first.
+func (r *FeedbackRepo) ListForUser(ctx context.Context, userID bson.ObjectID) ([]models.Feedback, error) {
+	cursor, err := database.Reader(ctx, r.coll(ctx)).Find(ctx, bson.M{"user_id": userID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	feedbacks := []models.Feedback{}
+	if err := cursor.All(ctx, &feedbacks); err != nil {
+		return nil, err
+	}
+	for i := range feedbacks {
+		if err := r.decrypt(ctx, &feedbacks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return feedbacks, nil
+}
+
+// LatestForUser returns the user's most recent feedback without its text,
+// or nil if they never sent any.
+func (r *FeedbackRepo) LatestForUser(ctx context.Context, userID bson.ObjectID) (*models.Feedback, error) {
+	var feedback models.Feedback
+	err := database.Reader(ctx, r.coll(ctx)).FindOne(ctx, bson.M{"user_id": userID},
+		options.FindOne().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetProjection(bson.M{"text": 0}),
+	).Decode(&feedback)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 	return &feedback, nil
 }
 
+// Reassign moves a user's feedback to another user. Encrypted text is
+// re-encrypted under the new owner's data key.
+func (r *FeedbackRepo) Reassign(ctx context.Context, from, to bson.ObjectID) (int64, error) {
+	if r.envelope == nil {
+		result, err := r.coll(ctx).UpdateMany(ctx, bson.M{"user_id": from}, bson.M{"$set": bson.M{"user_id": to}})
+		if err != nil {
+			return 0, err
+		}
+		return result.ModifiedCount, nil
+	}
+
+	cursor, err := r.coll(ctx).Find(ctx, bson.M{"user_id": from})
+	if err != nil {
+		return 0, err
+	}
+	feedbacks := []models.Feedback{}
+	if err := cursor.All(ctx, &feedbacks); err != nil {
+		return 0, err
+	}
+
+	for i := range feedbacks {
+		set := bson.M{"user_id": to}
+		if crypto.IsEncrypted(feedbacks[i].Text) {
+			if err := r.decrypt(ctx, &feedbacks[i]); err != nil {
+				return 0, err
+			}
+			encrypted, err := r.envelope.Encrypt(ctx, to.Hex(), feedbacks[i].Text)
+			if err != nil {
+				return 0, err
+			}
+			set["text"] = encrypted
+		}
+		if _, err := r.coll(ctx).UpdateOne(ctx, bson.M{"_id": feedbacks[i].ID}, bson.M{"$set": set}); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(feedbacks)), nil
+}
+
+// SetReaction records the author's reaction on resolved feedback. Returns false
+// if the feedback isn't theirs, isn't resolved, or already has a reaction.
+func (r *FeedbackRepo) SetReaction(ctx context.Context, id, userID bson.ObjectID, reaction *models.FeedbackReaction) (bool, error) {
+	reaction.CreatedAt = time.Now()
+	result, err := r.coll(ctx).UpdateOne(ctx, bson.M{
+		"_id":      id,
+		"user_id":  userID,
+		"status":   models.FeedbackStatusResolved,
+		"reaction": bson.M{"$exists": false},
+	}, bson.M{"$set": bson.M{"reaction": reaction}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// Edit replaces the author's feedback with edited, keeping previous as the
+// newest entry of its edit history. It returns false if the feedback isn't
+// the author's, was created before since, is no longer open, or was edited
+// again since previous was read.
+func (r *FeedbackRepo) Edit(ctx context.Context, previous, edited *models.Feedback, since time.Time) (bool, error) {
+	now := time.Now()
+	oldText, err := r.encrypt(ctx, previous.UserID, previous.Text)
+	if err != nil {
+		return false, err
+	}
+	newText, err := r.encrypt(ctx, previous.UserID, edited.Text)
+	if err != nil {
+		return false, err
+	}
+
+	filter := bson.M{
+		"_id":        previous.ID,
+		"user_id":    previous.UserID,
+		"status":     models.FeedbackStatusOpen,
+		"created_at": bson.M{"$gte": since},
+		"edited_at":  bson.M{"$exists": false},
+	}
+	if previous.EditedAt != nil {
+		filter["edited_at"] = *previous.EditedAt
+	}
+	set := bson.M{
+		"text":      newText,
+		"rating":    edited.Rating,
+		"category":  edited.Category,
+		"edited_at": now,
+	}
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{"edits": models.FeedbackEdit{
+			Text:     oldText,
+			Rating:   previous.Rating,
+			Ratings:  previous.Ratings,
+			Category: previous.Category,
+			EditedAt: now,
+		}},
+	}
+	if len(edited.Ratings) > 0 {
+		set["ratings"] = edited.Ratings
+	} else {
+		update["$unset"] = bson.M{"ratings": ""}
+	}
+
+	result, err := r.coll(ctx).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	if result.ModifiedCount == 1 {
+		edited.EditedAt = &now
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// DeleteByAuthor deletes the author's feedback if it was created at or
+// after since and is still open. Returns false if nothing was deleted.
+func (r *FeedbackRepo) DeleteByAuthor(ctx context.Context, id, userID bson.ObjectID, since time.Time) (bool, error) {
+	result, err := r.coll(ctx).DeleteOne(ctx, bson.M{
+		"_id":        id,
+		"user_id":    userID,
+		"status":     models.FeedbackStatusOpen,
+		"created_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// SetSlackThread stores the Slack message that announced the feedback.
+func (r *FeedbackRepo) SetSlackThread(ctx context.Context, id bson.ObjectID, thread *models.SlackThread) error {
+	_, err := r.coll(ctx).UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"slack_thread": thread},
+	})
+	return err
+}
+
+// AddIssueLink records an issue created from the feedback. It returns false
+// if the feedback is missing or already linked to an issue in that tracker.
+func (r *FeedbackRepo) AddIssueLink(ctx context.Context, id bson.ObjectID, link models.IssueLink) (bool, error) {
+	result, err := r.coll(ctx).UpdateOne(ctx,
+		bson.M{"_id": id, "issue_links.provider": bson.M{"$ne": link.Provider}},
+		bson.M{"$push": bson.M{"issue_links": link}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ListAwaitingReaction returns the user's resolved feedback that hasn't been reacted to yet.
+func (r *FeedbackRepo) ListAwaitingReaction(ctx context.Context, userID bson.ObjectID) ([]models.Feedback, error) {
+	cursor, err := database.Reader(ctx, r.coll(ctx)).Find(ctx, bson.M{
+		"user_id":  userID,
+		"status":   models.FeedbackStatusResolved,
+		"reaction": bson.M{"$exists": false},
+	}, options.Find().SetSort(bson.D{{Key: "resolved_at", Value: -1}}).SetLimit(20))
+	if err != nil {
+		return nil, err
+	}
+	feedbacks := []models.Feedback{}
+	if err := cursor.All(ctx, &feedbacks); err != nil {
+		return nil, err
+	}
+	for i := range feedbacks {
+		if err := r.decrypt(ctx, &feedbacks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return feedbacks, nil
+}
+
+// Stats aggregates feedback counts, average ratings and reaction outcomes.
+// Named ratings are averaged over the submissions that gave them.
+func (r *FeedbackRepo) Stats(ctx context.Context, filter models.FeedbackFilter) (*models.FeedbackStats, error) {
+	match := bson.M{}
+	if filter.AppVersion != "" {
+		match["client.app_version"] = filter.AppVersion
+	}
+	if filter.OS != "" {
+		match["client.os"] = filter.OS
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$facet", Value: bson.M{
+			"overall": bson.A{
+				bson.M{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": 1}, "avg_rating": bson.M{"$avg": "$rating"}}},
+			},
+			"named_ratings": bson.A{
+				bson.M{"$match": bson.M{"ratings": bson.M{"$exists": true}}},
+				bson.M{"$project": bson.M{"rating": bson.M{"$objectToArray": "$ratings"}}},
+				bson.M{"$unwind": "$rating"},
+				bson.M{"$group": bson.M{"_id": "$rating.k", "avg": bson.M{"$avg": "$rating.v"}}},
+			},
+			"by_status": bson.A{
+				bson.M{"$group": bson.M{"_id": bson.M{"$ifNull": bson.A{"$status", models.FeedbackStatusOpen}}, "count": bson.M{"$sum": 1}}},
+			},
+			"reactions": bson.A{
+				bson.M{"$match": bson.M{"status": models.FeedbackStatusResolved}},
+				bson.M{"$group": bson.M{"_id": bson.M{"$ifNull": bson.A{"$reaction.value", "none"}}, "count": bson.M{"$sum": 1}}},
+			},
+			"by_app_version": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":        bson.M{"$ifNull": bson.A{"$client.app_version", "unknown"}},
+					"count":      bson.M{"$sum": 1},
+					"avg_rating": bson.M{"$avg": "$rating"},
+					"bugs":       bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$category", models.FeedbackCategoryBug}}, 1, 0}}},
+				}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}}},
+				bson.M{"$limit": 50},
+			},
+		}}},
+	}
+
+	cursor, err := r.coll(ctx).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Overall []struct {
+			Total     int64   `bson:"total"`
+			AvgRating float64 `bson:"avg_rating"`
+		} `bson:"overall"`
+		NamedRatings []struct {
+			ID  string  `bson:"_id"`
+			Avg float64 `bson:"avg"`
+		} `bson:"named_ratings"`
+		ByStatus []struct {
+			ID    string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"by_status"`
+		Reactions []struct {
+			ID    string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"reactions"`
+		ByAppVersion []models.AppVersionStats `bson:"by_app_version"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	stats := &models.FeedbackStats{
+		ByStatus:       map[string]int64{},
+		AverageRatings: map[string]float64{},
+		ByAppVersion:   []models.AppVersionStats{},
+	}
+	if len(results) == 0 {
+		return stats, nil
+	}
+	if results[0].ByAppVersion != nil {
+		stats.ByAppVersion = results[0].ByAppVersion
+	}
+	if len(results[0].Overall) > 0 {
+		stats.Total = results[0].Overall[0].Total
+		stats.AverageRating = results[0].Overall[0].AvgRating
+	}
+	for _, rating := range results[0].NamedRatings {
+		stats.AverageRatings[rating.ID] = rating.Avg
+	}
+	for _, s := range results[0].ByStatus {
+		stats.ByStatus[s.ID] = s.Count
+	}
+	for _, reaction := range results[0].Reactions {
+		switch reaction.ID {
+		case models.ReactionUp:
+			stats.ReactionsUp = reaction.Count
+		case models.ReactionDown:
+			stats.ReactionsDown = reaction.Count
+		default:
+			stats.AwaitingAnswer = reaction.Count
+		}
+	}
+	return stats, nil
+}
+
+// ListNewestFirst pages through feedback ordered by (created_at, _id) descending,
+// which is stable even when several documents share a timestamp. since bounds
+// the oldest item returned (inclusive); pass the last item of the previous page
+// as after to continue from it.
+func (r *FeedbackRepo) ListNewestFirst(ctx context.Context, since time.Time, after *models.Feedback, limit int64) ([]models.Feedback, error) {
+	filter := bson.M{}
+	if !since.IsZero() {
+		filter["created_at"] = bson.M{"$gte": since}
+	}
+	return r.listNewestFirst(ctx, filter, after, limit)
+}
+
+// ListNewestFirstForOrg pages through the feedback filed under the
+// organization like ListNewestFirst.
+func (r *FeedbackRepo) ListNewestFirstForOrg(ctx context.Context, orgID bson.ObjectID, after *models.Feedback, limit int64) ([]models.Feedback, error) {
+	return r.listNewestFirst(ctx, bson.M{"org_id": orgID}, after, limit)
+}
+
+func (r *FeedbackRepo) listNewestFirst(ctx context.Context, filter bson.M, after *models.Feedback, limit int64) ([]models.Feedback, error) {
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": after.CreatedAt}},
+			bson.M{"created_at": after.CreatedAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	cursor, err := r.coll(ctx).Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	feedbacks := []models.Feedback{}
+	if err := cursor.All(ctx, &feedbacks); err != nil {
+		return nil, err
+	}
+	for i := range feedbacks {
+		if err := r.decrypt(ctx, &feedbacks[i]); err != nil {
+			return nil, err
+		}
+	}
+	return feedbacks, nil
+}
+
 // EnsureIndexes creates necessary indexes for the feedbacks collection
 func (r *FeedbackRepo) EnsureIndexes(ctx context.Context) error {
 	indexes := []mongo.IndexModel{
 		{
-			Keys:    bson.D{{Key: "idempotency_key", Value: 1}},
-			Options: options.Index().SetUnique(true).SetSparse(true),
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "idempotency_key", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"idempotency_key": bson.M{"$exists": true}}),
 		},
 		{
 			Keys: bson.D{{Key: "user_id", Value: 1}},
 		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "org_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "client.app_version", Value: 1}, {Key: "created_at", Value: -1}},
+		},
 	}
-	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
-	return err
+	if _, err := r.coll(ctx).Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+	// Keys used to be unique across all users
+	if err := r.coll(ctx).Indexes().DropOne(ctx, "idempotency_key_1"); err != nil && !isIndexNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// isIndexNotFound reports whether err is Mongo's IndexNotFound.
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == 27
 }
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type IncidentRepo struct {
+	collection *mongo.Collection
+}
+
+func NewIncidentRepo() *IncidentRepo {
+	return &IncidentRepo{
+		collection: database.GetCollection("incidents"),
+	}
+}
+
+func (r *IncidentRepo) Create(ctx context.Context, incident *models.Incident) error {
+	now := time.Now()
+	incident.CreatedAt = now
+	incident.UpdatedAt = now
+	if incident.Components == nil {
+		incident.Components = []string{}
+	}
+	result, err := r.collection.InsertOne(ctx, incident)
+	if err != nil {
+		return err
+	}
+	incident.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// AddUpdate appends a status update and moves the incident to its status.
+// Returns nil, nil if the incident doesn't exist.
+func (r *IncidentRepo) AddUpdate(ctx context.Context, id bson.ObjectID, update models.IncidentUpdate) (*models.Incident, error) {
+	update.CreatedAt = time.Now()
+	set := bson.M{"status": update.Status, "updated_at": update.CreatedAt}
+	change := bson.M{"$push": bson.M{"updates": update}, "$set": set}
+	if update.Status == models.IncidentResolved {
+		set["resolved_at"] = update.CreatedAt
+	} else {
+		change["$unset"] = bson.M{"resolved_at": ""}
+	}
+
+	var incident models.Incident
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, change,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&incident)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// ListRecent returns open incidents plus those resolved since the cutoff,
+// newest first.
+func (r *IncidentRepo) ListRecent(ctx context.Context, resolvedSince time.Time, limit int64) ([]models.Incident, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": bson.M{"$ne": models.IncidentResolved}},
+		bson.M{"resolved_at": bson.M{"$gte": resolvedSince}},
+	}}
+	cursor, err := r.collection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	incidents := []models.Incident{}
+	if err := cursor.All(ctx, &incidents); err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// EnsureIndexes creates necessary indexes for the incidents collection
+func (r *IncidentRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "resolved_at", Value: -1}},
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// issueDeliveryRetention is how long issue tracker delivery attempts are
+// kept for debugging and redelivery.
+const issueDeliveryRetention = 90 * 24 * time.Hour
+
+type IssueDeliveryRepo struct {
+	collection *mongo.Collection
+}
+
+func NewIssueDeliveryRepo() *IssueDeliveryRepo {
+	return &IssueDeliveryRepo{
+		collection: database.GetCollection("issue_deliveries"),
+	}
+}
+
+func (r *IssueDeliveryRepo) Create(ctx context.Context, d *models.IssueDelivery) error {
+	d.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, d)
+	if err != nil {
+		return err
+	}
+	d.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// FindByID returns the delivery, or nil if there is none.
+func (r *IssueDeliveryRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.IssueDelivery, error) {
+	var d models.IssueDelivery
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// IssueDeliveryFilter narrows List. Zero fields match everything.
+type IssueDeliveryFilter struct {
+	FeedbackID bson.ObjectID
+	Provider   string
+	FailedOnly bool
+	Before     bson.ObjectID // continue after the last entry of the previous page
+}
+
+func (f IssueDeliveryFilter) query() bson.M {
+	query := bson.M{}
+	if !f.FeedbackID.IsZero() {
+		query["feedback_id"] = f.FeedbackID
+	}
+	if f.Provider != "" {
+		query["provider"] = f.Provider
+	}
+	if f.FailedOnly {
+		query["succeeded"] = false
+	}
+	if !f.Before.IsZero() {
+		query["_id"] = bson.M{"$lt": f.Before}
+	}
+	return query
+}
+
+// List returns deliveries newest first.
+func (r *IssueDeliveryRepo) List(ctx context.Context, filter IssueDeliveryFilter, limit int64) ([]models.IssueDelivery, error) {
+	cursor, err := r.collection.Find(ctx, filter.query(), options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []models.IssueDelivery{}
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// EnsureIndexes creates necessary indexes for the issue_deliveries collection
+func (r *IssueDeliveryRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(issueDeliveryRetention.Seconds())),
+		},
+		{
+			Keys: bson.D{{Key: "feedback_id", Value: 1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "succeeded", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	})
+	return err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type LoginLinkRepo struct {
+	collection *mongo.Collection
+}
+
+func NewLoginLinkRepo() *LoginLinkRepo {
+	return &LoginLinkRepo{
+		collection: database.GetCollection("login_link_events"),
+	}
+}
+
+// hashToken keeps raw login tokens out of the analytics collection.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RecordSent starts tracking a newly emailed login link.
+func (r *LoginLinkRepo) RecordSent(ctx context.Context, token, emailDomain string) error {
+	_, err := r.collection.InsertOne(ctx, models.LoginLinkEvent{
+		TokenHash:   hashToken(token),
+		EmailDomain: emailDomain,
+		SentAt:      time.Now(),
+	})
+	return err
+}
+
+// RecordClick stamps the first click (user agent, client, time-to-click) and
+// counts repeat clicks.
+func (r *LoginLinkRepo) RecordClick(ctx context.Context, token, userAgent, emailClient string) error {
+	var event models.LoginLinkEvent
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"token_hash": hashToken(token)},
+		bson.M{"$inc": bson.M{"click_count": 1}},
+	).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+	if event.ClickedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": event.ID, "clicked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"clicked_at":       now,
+			"user_agent":       userAgent,
+			"email_client":     emailClient,
+			"time_to_click_ms": now.Sub(event.SentAt).Milliseconds(),
+		}},
+	)
+	return err
+}
+
+// RecordVerified marks the link's token as successfully exchanged for a session.
+func (r *LoginLinkRepo) RecordVerified(ctx context.Context, token string) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"token_hash": hashToken(token), "verified_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"verified_at": time.Now()}},
+	)
+	return err
+}
+
+// Rollup counts the links sent in [from, to) per hour and email client
+// (empty until clicked): sent, clicked, verified and the summed time to
+// click, for averages.
+func (r *LoginLinkRepo) Rollup(ctx context.Context, from, to time.Time) ([]models.MetricsRollup, error) {
+	ifSet := func(field string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$ifNull": bson.A{"$" + field, false}}, 1, 0}}}
+	}
+	return aggregateRollups(ctx, r.collection, rollupPipeline(
+		models.MetricLoginLinks, models.RollupHourly, "sent_at", from, to, nil,
+		bson.M{"$ifNull": bson.A{"$email_client", ""}},
+		bson.M{
+			"sent":             bson.M{"$sum": 1},
+			"clicked":          ifSet("clicked_at"),
+			"verified":         ifSet("verified_at"),
+			"time_to_click_ms": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$time_to_click_ms", 0}}},
+		},
+	))
+}
+
+// EnsureIndexes creates necessary indexes for the login_link_events collection
+func (r *LoginLinkRepo) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "sent_at", Value: -1}},
+		},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// codeNamespaceExists is returned when creating a collection that exists.
+const codeNamespaceExists = 48
+
+type MetricsRollupRepo struct {
+	collection *mongo.Collection
+	state      *mongo.Collection
+}
+
+func NewMetricsRollupRepo() *MetricsRollupRepo {
+	return &MetricsRollupRepo{
+		collection: database.GetCollection("metrics_rollups"),
+		state:      database.GetCollection("metrics_rollup_state"),
+	}
+}
+
+// RolledUntil returns where the metric's final buckets end, or the zero time
+// if it has never been rolled up.
+func (r *MetricsRollupRepo) RolledUntil(ctx context.Context, metric string) (time.Time, error) {
+	var state models.RollupState
+	err := r.state.FindOne(ctx, bson.M{"_id": metric}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return state.RolledUntil, nil
+}
+
+// Save stores the final buckets of [from, to) and moves the metric's
+// checkpoint to to. Buckets left behind by a run that died before moving the
+// checkpoint are replaced; on a time-series collection that needs MongoDB 7.0.
+func (r *MetricsRollupRepo) Save(ctx context.Context, metric string, from, to time.Time, rollups []models.MetricsRollup) error {
+	stale := bson.M{"meta.metric": metric, "bucket": bson.M{"$gte": from}}
+	if n, err := r.collection.CountDocuments(ctx, stale); err != nil {
+		return err
+	} else if n > 0 {
+		if _, err := r.collection.DeleteMany(ctx, stale); err != nil {
+			return err
+		}
+	}
+	if len(rollups) > 0 {
+		if _, err := r.collection.InsertMany(ctx, rollups); err != nil {
+			return err
+		}
+	}
+	_, err := r.state.UpdateOne(ctx,
+		bson.M{"_id": metric},
+		bson.M{"$set": bson.M{"rolled_until": to, "updated_at": time.Now()}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// Totals sums the named values of a metric's buckets in [from, to) per
+// dimension.
+func (r *MetricsRollupRepo) Totals(ctx context.Context, metric string, from, to time.Time, fields []string) ([]models.RollupTotal, error) {
+	group := bson.M{"_id": "$meta.dimension"}
+	values := bson.M{}
+	for _, f := range fields {
+		group[f] = bson.M{"$sum": "$values." + f}
+		values[f] = "$" + f
+	}
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"meta.metric": metric, "bucket": bson.M{"$gte": from, "$lt": to}}}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$project", Value: bson.M{"values": values}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	totals := []models.RollupTotal{}
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// rollupPipeline groups the documents whose timeField is in [from, to) into
+// buckets of the metric's granularity, decoding as models.MetricsRollup.
+// values maps each value name to its $group accumulator.
+func rollupPipeline(metric, granularity, timeField string, from, to time.Time, match bson.M, dimension interface{}, values bson.M) mongo.Pipeline {
+	if match == nil {
+		match = bson.M{}
+	}
+	match[timeField] = bson.M{"$gte": from, "$lt": to}
+
+	group := bson.M{"_id": bson.M{
+		"bucket":    bson.M{"$dateTrunc": bson.M{"date": "$" + timeField, "unit": granularity, "timezone": "UTC"}},
+		"dimension": dimension,
+	}}
+	projected := bson.M{}
+	for name, acc := range values {
+		group[name] = acc
+		projected[name] = "$" + name
+	}
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$project", Value: bson.M{
+			"_id":    0,
+			"bucket": "$_id.bucket",
+			"meta": bson.M{
+				"metric":      bson.M{"$literal": metric},
+				"granularity": bson.M{"$literal": granularity},
+				"dimension":   "$_id.dimension",
+			},
+			"values": projected,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "bucket", Value: 1}, {Key: "meta.dimension", Value: 1}}}},
+	}
+}
+
+func aggregateRollups(ctx context.Context, coll *mongo.Collection, pipeline mongo.Pipeline) ([]models.MetricsRollup, error) {
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	rollups := []models.MetricsRollup{}
+	if err := cursor.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+// EnsureIndexes creates the rollups as a time-series collection, falling back
+// to a plain one on servers without time-series support (before MongoDB 5.0).
+func (r *MetricsRollupRepo) EnsureIndexes(ctx context.Context) error {
+	err := r.collection.Database().CreateCollection(ctx, r.collection.Name(),
+		options.CreateCollection().SetTimeSeriesOptions(options.TimeSeries().
+			SetTimeField("bucket").
+			SetMetaField("meta").
+			SetGranularity("hours")),
+	)
+	var serverErr mongo.ServerError
+	switch {
+	case err == nil:
+		log.Printf("📈 Created time-series collection %s", r.collection.Name())
+	case errors.As(err, &serverErr) && serverErr.HasErrorCode(codeNamespaceExists):
+	default:
+		log.Printf("⚠️  Time-series collections unavailable, storing %s as a plain collection: %v", r.collection.Name(), err)
+	}
+
+	_, err = r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "meta.metric", Value: 1}, {Key: "bucket", Value: 1}},
+	})
+	return err
+}
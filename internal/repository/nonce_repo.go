@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// NonceRepo records request nonces so signed requests can't be replayed.
+type NonceRepo struct {
+	collection *mongo.Collection
+}
+
+func NewNonceRepo() *NonceRepo {
+	return &NonceRepo{
+		collection: database.GetCollection("request_nonces"),
+	}
+}
+
+// Use records the nonce and reports whether it was unused. A nonce is
+// remembered for ttl, after which the timestamp check rejects it anyway.
+func (r *NonceRepo) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	_, err := r.collection.InsertOne(ctx, bson.M{
+		"_id":        nonce,
+		"expires_at": time.Now().Add(ttl),
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureIndexes creates necessary indexes for the request_nonces collection
+func (r *NonceRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0), // TTL index — forget old nonces
+	})
+	return err
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// OnboardingReminderRepo stores onboarding_reminders. It must live in the
+// same database as users, which look it up to find who is due a reminder.
+type OnboardingReminderRepo struct {
+	collection *mongo.Collection
+}
+
+func NewOnboardingReminderRepo() *OnboardingReminderRepo {
+	return &OnboardingReminderRepo{
+		collection: database.GetCollection("onboarding_reminders"),
+	}
+}
+
+// Claim records the reminder step for its user. It returns false if the step
+// was already claimed, so concurrent runs can't send it twice.
+func (r *OnboardingReminderRepo) Claim(ctx context.Context, reminder *models.OnboardingReminder) (bool, error) {
+	reminder.SentAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, reminder)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	reminder.ID = result.InsertedID.(bson.ObjectID)
+	return true, nil
+}
+
+// SetJob records the email job sending a claimed reminder.
+func (r *OnboardingReminderRepo) SetJob(ctx context.Context, id, jobID bson.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"job_id": jobID}})
+	return err
+}
+
+// Release deletes a claim whose email couldn't be queued, so the next run
+// tries again.
+func (r *OnboardingReminderRepo) Release(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// MarkConverted credits the user's latest sent reminder with completing
+// onboarding at time at. Users who finish without a reminder are left alone.
+func (r *OnboardingReminderRepo) MarkConverted(ctx context.Context, userID bson.ObjectID, at time.Time) error {
+	var latest models.OnboardingReminder
+	err := r.collection.FindOne(ctx,
+		bson.M{"user_id": userID, "skipped": bson.M{"$exists": false}},
+		options.FindOne().SetSort(bson.D{{Key: "sent_at", Value: -1}}),
+	).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.UpdateOne(ctx,
+		bson.M{"_id": latest.ID, "converted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"converted_at": at}},
+	)
+	return err
+}
+
+// Rollup computes the daily funnel per step for [from, to): reminders sent
+// in each day and conversions made in it.
+func (r *OnboardingReminderRepo) Rollup(ctx context.Context, from, to time.Time) ([]models.MetricsRollup, error) {
+	sent, err := aggregateRollups(ctx, r.collection, rollupPipeline(
+		models.MetricOnboardingReminders, models.RollupDaily, "sent_at", from, to,
+		bson.M{"skipped": bson.M{"$exists": false}},
+		"$step",
+		bson.M{"sent": bson.M{"$sum": 1}},
+	))
+	if err != nil {
+		return nil, err
+	}
+	converted, err := aggregateRollups(ctx, r.collection, rollupPipeline(
+		models.MetricOnboardingReminders, models.RollupDaily, "converted_at", from, to, nil,
+		"$step",
+		bson.M{"converted": bson.M{"$sum": 1}},
+	))
+	if err != nil {
+		return nil, err
+	}
+	return append(sent, converted...), nil
+}
+
+// EnsureIndexes creates necessary indexes for the onboarding_reminders collection
+func (r *OnboardingReminderRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "step", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "sent_at", Value: -1}}},
+		{Keys: bson.D{{Key: "sent_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "converted_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// OrgUsageRepo keeps monthly organization usage reports.
+type OrgUsageRepo struct {
+	collection *mongo.Collection
+}
+
+func NewOrgUsageRepo() *OrgUsageRepo {
+	return &OrgUsageRepo{
+		collection: database.GetCollection("org_usage_reports"),
+	}
+}
+
+// Save stores the report, replacing an earlier one for the same month.
+func (r *OrgUsageRepo) Save(ctx context.Context, report *models.OrgUsage) error {
+	_, err := r.collection.ReplaceOne(ctx,
+		bson.M{"org_id": report.OrgID, "month": report.Month},
+		report,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// Exists reports whether the organization has a report for the month.
+func (r *OrgUsageRepo) Exists(ctx context.Context, orgID bson.ObjectID, month string) (bool, error) {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"org_id": orgID, "month": month})
+	return n > 0, err
+}
+
+// ListForMonth returns every organization's report for the month.
+func (r *OrgUsageRepo) ListForMonth(ctx context.Context, month string) ([]models.OrgUsage, error) {
+	return r.list(ctx, bson.M{"month": month})
+}
+
+// ListForOrg returns the organization's reports, newest first.
+func (r *OrgUsageRepo) ListForOrg(ctx context.Context, orgID bson.ObjectID) ([]models.OrgUsage, error) {
+	return r.list(ctx, bson.M{"org_id": orgID})
+}
+
+func (r *OrgUsageRepo) list(ctx context.Context, filter bson.M) ([]models.OrgUsage, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "month", Value: -1}, {Key: "org_slug", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	reports := []models.OrgUsage{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// EnsureIndexes creates necessary indexes for the org_usage_reports collection
+func (r *OrgUsageRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "org_id", Value: 1}, {Key: "month", Value: -1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "month", Value: 1}}},
+	})
+	return err
+}
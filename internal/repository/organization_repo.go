@@ -0,0 +1,423 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/crypto"
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OrganizationRepo struct {
+	collection *mongo.Collection
+	members    *mongo.Collection
+	invites    *mongo.Collection
+	envelope   *crypto.Envelope
+	onMember   []func(userID bson.ObjectID)
+}
+
+func NewOrganizationRepo() *OrganizationRepo {
+	return &OrganizationRepo{
+		collection: database.GetCollection("organizations"),
+		members:    database.GetCollection("org_members"),
+		invites:    database.GetCollection("org_invites"),
+	}
+}
+
+// WithEncryption encrypts SSO client secrets at rest. Secrets saved before
+// it was enabled remain readable.
+func (r *OrganizationRepo) WithEncryption(envelope *crypto.Envelope) *OrganizationRepo {
+	r.envelope = envelope
+	return r
+}
+
+// OnMembershipChange registers fn to be called with the user whenever a
+// membership is added or removed, which changes their plan. Hooks must be
+// registered before the repo is used.
+func (r *OrganizationRepo) OnMembershipChange(fn func(userID bson.ObjectID)) *OrganizationRepo {
+	r.onMember = append(r.onMember, fn)
+	return r
+}
+
+func (r *OrganizationRepo) membershipChanged(userID bson.ObjectID) {
+	for _, fn := range r.onMember {
+		fn(userID)
+	}
+}
+
+// Create inserts an organization. A taken slug or domain is a duplicate key
+// error (see mongo.IsDuplicateKeyError).
+func (r *OrganizationRepo) Create(ctx context.Context, org *models.Organization) error {
+	now := time.Now()
+	org.CreatedAt = now
+	org.UpdatedAt = now
+	if org.Domains == nil {
+		org.Domains = []string{}
+	}
+	result, err := r.collection.InsertOne(ctx, org)
+	if err != nil {
+		return err
+	}
+	org.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (r *OrganizationRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.Organization, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *OrganizationRepo) FindBySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	return r.findOne(ctx, bson.M{"slug": slug})
+}
+
+// FindByEmail returns the organization owning the email's domain, or nil.
+func (r *OrganizationRepo) FindByEmail(ctx context.Context, email string) (*models.Organization, error) {
+	_, domain, ok := strings.Cut(email, "@")
+	if !ok || domain == "" {
+		return nil, nil
+	}
+	return r.findOne(ctx, bson.M{"domains": strings.ToLower(domain)})
+}
+
+func (r *OrganizationRepo) findOne(ctx context.Context, filter bson.M) (*models.Organization, error) {
+	var org models.Organization
+	err := r.collection.FindOne(ctx, filter).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepo) List(ctx context.Context) ([]models.Organization, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	orgs := []models.Organization{}
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// Update saves name, domains and branding and returns the updated
+// organization, or nil if it doesn't exist.
+func (r *OrganizationRepo) Update(ctx context.Context, org *models.Organization) (*models.Organization, error) {
+	set := bson.M{
+		"name":       org.Name,
+		"domains":    org.Domains,
+		"updated_at": time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if org.Branding != nil {
+		set["branding"] = org.Branding
+	} else {
+		update["$unset"] = bson.M{"branding": ""}
+	}
+
+	var updated models.Organization
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": org.ID}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SetSSO saves the organization's identity provider, or removes it when sso
+// is nil. It returns the updated organization, or nil if it doesn't exist.
+func (r *OrganizationRepo) SetSSO(ctx context.Context, id bson.ObjectID, sso *models.SSOConfig) (*models.Organization, error) {
+	update := bson.M{"$unset": bson.M{"sso": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	if sso != nil {
+		doc := *sso
+		if r.envelope != nil {
+			secret, err := r.envelope.Encrypt(ctx, ssoKeyOwner(id), sso.ClientSecret)
+			if err != nil {
+				return nil, err
+			}
+			doc.ClientSecret = secret
+		}
+		update = bson.M{"$set": bson.M{"sso": doc, "updated_at": time.Now()}}
+	}
+
+	var updated models.Organization
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SSOSecret returns the organization's decrypted SSO client secret. Only the
+// sign-in flow needs it, so lookups don't decrypt it.
+func (r *OrganizationRepo) SSOSecret(ctx context.Context, org *models.Organization) (string, error) {
+	if org.SSO == nil {
+		return "", nil
+	}
+	if r.envelope == nil || !crypto.IsEncrypted(org.SSO.ClientSecret) {
+		return org.SSO.ClientSecret, nil
+	}
+	return r.envelope.Decrypt(ctx, ssoKeyOwner(org.ID), org.SSO.ClientSecret)
+}
+
+// ssoKeyOwner keys SSO secrets per organization, apart from user data keys.
+func ssoKeyOwner(orgID bson.ObjectID) string {
+	return "org:" + orgID.Hex()
+}
+
+// SetSCIMToken replaces the organization's SCIM token, or revokes it when
+// token is nil. It returns the updated organization, or nil if it doesn't
+// exist.
+func (r *OrganizationRepo) SetSCIMToken(ctx context.Context, id bson.ObjectID, token *models.SCIMToken) (*models.Organization, error) {
+	update := bson.M{"$unset": bson.M{"scim": ""}, "$set": bson.M{"updated_at": time.Now()}}
+	if token != nil {
+		update = bson.M{"$set": bson.M{"scim": token, "updated_at": time.Now()}}
+	}
+
+	var updated models.Organization
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// FindBySCIMTokenHash returns the organization holding the SCIM token, or nil.
+func (r *OrganizationRepo) FindBySCIMTokenHash(ctx context.Context, hash string) (*models.Organization, error) {
+	return r.findOne(ctx, bson.M{"scim.token_hash": hash})
+}
+
+// AddMember adds the user to the organization. An existing membership is
+// kept as is; the returned member is the stored one.
+func (r *OrganizationRepo) AddMember(ctx context.Context, member *models.OrgMember) (*models.OrgMember, error) {
+	insert := bson.M{
+		"email":     member.Email,
+		"role":      member.Role,
+		"joined_at": time.Now(),
+	}
+	if member.ExternalID != "" {
+		insert["external_id"] = member.ExternalID
+	}
+
+	var stored models.OrgMember
+	err := r.members.FindOneAndUpdate(ctx,
+		bson.M{"org_id": member.OrgID, "user_id": member.UserID},
+		bson.M{"$setOnInsert": insert},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&stored)
+	if mongo.IsDuplicateKeyError(err) {
+		// Raced with another accept; the membership exists now
+		return r.FindMember(ctx, member.OrgID, member.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.membershipChanged(member.UserID)
+	return &stored, nil
+}
+
+// FindMember returns the user's membership of the organization, or nil.
+func (r *OrganizationRepo) FindMember(ctx context.Context, orgID, userID bson.ObjectID) (*models.OrgMember, error) {
+	var member models.OrgMember
+	err := r.members.FindOne(ctx, bson.M{"org_id": orgID, "user_id": userID}).Decode(&member)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// FindMemberBySSOSubject returns the member linked to the identity provider
+// subject, or nil.
+func (r *OrganizationRepo) FindMemberBySSOSubject(ctx context.Context, orgID bson.ObjectID, subject string) (*models.OrgMember, error) {
+	var member models.OrgMember
+	err := r.members.FindOne(ctx, bson.M{"org_id": orgID, "sso_subject": subject}).Decode(&member)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SetMemberSSOSubject links the member to an identity provider subject, or
+// unlinks them when subject is empty. It returns false if the user isn't a
+// member, and a duplicate key error if another member has the subject.
+func (r *OrganizationRepo) SetMemberSSOSubject(ctx context.Context, orgID, userID bson.ObjectID, subject string) (bool, error) {
+	update := bson.M{"$set": bson.M{"sso_subject": subject}}
+	if subject == "" {
+		update = bson.M{"$unset": bson.M{"sso_subject": ""}}
+	}
+	result, err := r.members.UpdateOne(ctx, bson.M{"org_id": orgID, "user_id": userID}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// SetMemberDeactivated deactivates or reactivates a membership. It returns
+// false if the user isn't a member.
+func (r *OrganizationRepo) SetMemberDeactivated(ctx context.Context, orgID, userID bson.ObjectID, deactivated bool) (bool, error) {
+	update := bson.M{"$unset": bson.M{"deactivated_at": ""}}
+	if deactivated {
+		update = bson.M{"$set": bson.M{"deactivated_at": time.Now()}}
+	}
+	result, err := r.members.UpdateOne(ctx, bson.M{"org_id": orgID, "user_id": userID}, update)
+	if err != nil {
+		return false, err
+	}
+	r.membershipChanged(userID)
+	return result.MatchedCount == 1, nil
+}
+
+// ListMembers returns the organization's members, oldest first, including
+// deactivated ones.
+func (r *OrganizationRepo) ListMembers(ctx context.Context, orgID bson.ObjectID) ([]models.OrgMember, error) {
+	return r.listMembers(ctx, bson.M{"org_id": orgID})
+}
+
+// ListMemberships returns every active organization membership of the user.
+func (r *OrganizationRepo) ListMemberships(ctx context.Context, userID bson.ObjectID) ([]models.OrgMember, error) {
+	return r.listMembers(ctx, bson.M{"user_id": userID, "deactivated_at": bson.M{"$exists": false}})
+}
+
+func (r *OrganizationRepo) listMembers(ctx context.Context, filter bson.M) ([]models.OrgMember, error) {
+	cursor, err := r.members.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "joined_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	members := []models.OrgMember{}
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// CountOwners counts the organization's owners.
+func (r *OrganizationRepo) CountOwners(ctx context.Context, orgID bson.ObjectID) (int64, error) {
+	return r.members.CountDocuments(ctx, bson.M{"org_id": orgID, "role": models.OrgRoleOwner})
+}
+
+// RemoveMember deletes a membership. Returns false if there was none.
+func (r *OrganizationRepo) RemoveMember(ctx context.Context, orgID, userID bson.ObjectID) (bool, error) {
+	result, err := r.members.DeleteOne(ctx, bson.M{"org_id": orgID, "user_id": userID})
+	if err != nil {
+		return false, err
+	}
+	r.membershipChanged(userID)
+	return result.DeletedCount == 1, nil
+}
+
+// SaveInvite creates an invite, or renews the pending one for the same
+// organization and email.
+func (r *OrganizationRepo) SaveInvite(ctx context.Context, invite *models.OrgInvite) error {
+	now := time.Now()
+	var stored models.OrgInvite
+	err := r.invites.FindOneAndUpdate(ctx,
+		bson.M{"org_id": invite.OrgID, "email": invite.Email, "accepted_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"role":       invite.Role,
+			"invited_by": invite.InvitedBy,
+			"expires_at": invite.ExpiresAt,
+			"created_at": now,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&stored)
+	if err != nil {
+		return err
+	}
+	*invite = stored
+	return nil
+}
+
+// PendingInvites returns unexpired, unaccepted invites for the email.
+func (r *OrganizationRepo) PendingInvites(ctx context.Context, email string) ([]models.OrgInvite, error) {
+	cursor, err := r.invites.Find(ctx, bson.M{
+		"email":       email,
+		"accepted_at": bson.M{"$exists": false},
+		"expires_at":  bson.M{"$gt": time.Now()},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	invites := []models.OrgInvite{}
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// AcceptInvite marks a pending invite for the email as accepted and returns
+// it, or nil if there is no such invite.
+func (r *OrganizationRepo) AcceptInvite(ctx context.Context, id bson.ObjectID, email string) (*models.OrgInvite, error) {
+	now := time.Now()
+	var invite models.OrgInvite
+	err := r.invites.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "email": email, "accepted_at": bson.M{"$exists": false}, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"accepted_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&invite)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// EnsureIndexes creates necessary indexes for the organization collections
+func (r *OrganizationRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// Partial, as empty domain lists would otherwise collide
+		{Keys: bson.D{{Key: "domains", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"domains.0": bson.M{"$exists": true}})},
+		{Keys: bson.D{{Key: "scim.token_hash", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"scim.token_hash": bson.M{"$exists": true}})},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.members.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "org_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "org_id", Value: 1}, {Key: "sso_subject", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"sso_subject": bson.M{"$exists": true}})},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.invites.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "org_id", Value: 1}}},
+		// Expired invites are cleaned up by Mongo
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type QuarantineRepo struct {
+	collection *mongo.Collection
+}
+
+func NewQuarantineRepo() *QuarantineRepo {
+	return &QuarantineRepo{
+		collection: database.GetCollection("quarantined_files"),
+	}
+}
+
+func (r *QuarantineRepo) Create(ctx context.Context, file *models.QuarantinedFile) error {
+	file.Status = models.QuarantinePending
+	file.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, file)
+	if err != nil {
+		return err
+	}
+	file.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// FindByID returns the file including its contents, or nil.
+func (r *QuarantineRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.QuarantinedFile, error) {
+	var file models.QuarantinedFile
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&file)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// List returns files with the given status (all if empty), newest first,
+// without their contents.
+func (r *QuarantineRepo) List(ctx context.Context, status string, limit int64) ([]models.QuarantinedFile, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	cursor, err := r.collection.Find(ctx, filter,
+		options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}}).
+			SetLimit(limit).
+			SetProjection(bson.M{"data": 0}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	files := []models.QuarantinedFile{}
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// Resolve records the review of a pending file and drops its contents.
+// Returns false if the file was already reviewed.
+func (r *QuarantineRepo) Resolve(ctx context.Context, id bson.ObjectID, status, admin string, attachmentID *bson.ObjectID) (bool, error) {
+	set := bson.M{"status": status, "reviewed_by": admin, "reviewed_at": time.Now()}
+	if attachmentID != nil {
+		set["attachment_id"] = attachmentID
+	}
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.QuarantinePending},
+		bson.M{"$set": set, "$unset": bson.M{"data": ""}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// EnsureIndexes creates necessary indexes for the quarantined_files collection
+func (r *QuarantineRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type QuotaRepo struct {
+	collection *mongo.Collection
+}
+
+func NewQuotaRepo() *QuotaRepo {
+	return &QuotaRepo{
+		collection: database.GetCollection("quotas"),
+	}
+}
+
+func (r *QuotaRepo) FindByEndpoint(ctx context.Context, endpoint string) (*models.Quota, error) {
+	var quota models.Quota
+	err := r.collection.FindOne(ctx, bson.M{"endpoint": endpoint}).Decode(&quota)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (r *QuotaRepo) List(ctx context.Context) ([]models.Quota, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "endpoint", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	quotas := []models.Quota{}
+	if err := cursor.All(ctx, &quotas); err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+// Upsert sets the daily limit for an endpoint, creating the quota if needed.
+func (r *QuotaRepo) Upsert(ctx context.Context, endpoint string, dailyLimit int64) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"endpoint": endpoint},
+		bson.M{"$set": bson.M{"daily_limit": dailyLimit, "updated_at": time.Now()}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *QuotaRepo) Delete(ctx context.Context, endpoint string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"endpoint": endpoint})
+	return err
+}
+
+// EnsureIndexes creates necessary indexes for the quotas collection
+func (r *QuotaRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "endpoint", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RecordedRequestRepo stores staging traffic captured for replay.
+type RecordedRequestRepo struct {
+	collection *mongo.Collection
+}
+
+func NewRecordedRequestRepo() *RecordedRequestRepo {
+	return &RecordedRequestRepo{
+		collection: database.GetCollection("recorded_requests"),
+	}
+}
+
+// InsertMany stores a batch of recorded requests.
+func (r *RecordedRequestRepo) InsertMany(ctx context.Context, requests []*models.RecordedRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(requests))
+	for i, req := range requests {
+		docs[i] = req
+	}
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+// Each calls fn for recorded requests since the given time in the order they
+// were recorded, optionally only those whose route starts with routePrefix,
+// stopping after limit (0 means all) or at fn's first error.
+func (r *RecordedRequestRepo) Each(ctx context.Context, since time.Time, routePrefix string, limit int64, fn func(*models.RecordedRequest) error) error {
+	filter := bson.M{"recorded_at": bson.M{"$gte": since}}
+	if routePrefix != "" {
+		filter["route"] = bson.M{"$regex": "^" + regexp.QuoteMeta(routePrefix)}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "recorded_at", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var req models.RecordedRequest
+		if err := cursor.Decode(&req); err != nil {
+			return err
+		}
+		if err := fn(&req); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// EnsureIndexes creates necessary indexes for the recorded_requests collection
+func (r *RecordedRequestRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "recorded_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index — recordings age out
+		},
+	})
+	return err
+}
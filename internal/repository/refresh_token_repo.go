@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type RefreshTokenRepo struct {
+	collection *mongo.Collection
+	clock      clock.Clock
+}
+
+func NewRefreshTokenRepo() *RefreshTokenRepo {
+	return &RefreshTokenRepo{
+		collection: database.GetCollection("refresh_tokens"),
+		clock:      clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake.
+func (r *RefreshTokenRepo) WithClock(c clock.Clock) *RefreshTokenRepo {
+	r.clock = c
+	return r
+}
+
+func (r *RefreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	token.CreatedAt = r.clock.Now()
+	result, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return err
+	}
+	token.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (r *RefreshTokenRepo) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed claims a token for rotation. Only one of several concurrent
+// requests with the same token gets true.
+func (r *RefreshTokenRepo) MarkUsed(ctx context.Context, id bson.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "used_at": nil, "revoked_at": nil},
+		bson.M{"$set": bson.M{"used_at": r.clock.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeFamily revokes every token rotated from the same sign-in.
+func (r *RefreshTokenRepo) RevokeFamily(ctx context.Context, family string) (int64, error) {
+	return r.revoke(ctx, bson.M{"family": family})
+}
+
+// RevokeAllForUser signs the user out of every device.
+func (r *RefreshTokenRepo) RevokeAllForUser(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	return r.revoke(ctx, bson.M{"user_id": userID})
+}
+
+func (r *RefreshTokenRepo) revoke(ctx context.Context, filter bson.M) (int64, error) {
+	filter["revoked_at"] = nil
+	result, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": r.clock.Now()}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// EnsureIndexes creates necessary indexes for the refresh_tokens collection
+func (r *RefreshTokenRepo) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "family", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0), // TTL index — auto-delete expired tokens
+		},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ResumeTokenRepo persists change stream resume tokens so watchers can pick up
+// where they left off after a restart.
+type ResumeTokenRepo struct {
+	collection *mongo.Collection
+}
+
+func NewResumeTokenRepo() *ResumeTokenRepo {
+	return &ResumeTokenRepo{
+		collection: database.GetCollection("changestream_tokens"),
+	}
+}
+
+type resumeTokenDoc struct {
+	Name      string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// Get returns the stored token for a stream, or nil if there is none.
+func (r *ResumeTokenRepo) Get(ctx context.Context, name string) (bson.Raw, error) {
+	var doc resumeTokenDoc
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&doc)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return doc.Token, nil
+}
+
+func (r *ResumeTokenRepo) Save(ctx context.Context, name string, token bson.Raw) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": name},
+		bson.M{"$set": bson.M{"token": token, "updated_at": time.Now()}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *ResumeTokenRepo) Delete(ctx context.Context, name string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	return err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RevokedTokenRepo is the deny list of access tokens signed out before
+// they expired. Entries are deleted once the token would have expired.
+type RevokedTokenRepo struct {
+	collection *mongo.Collection
+	clock      clock.Clock
+}
+
+func NewRevokedTokenRepo() *RevokedTokenRepo {
+	return &RevokedTokenRepo{
+		collection: database.GetCollection("revoked_tokens"),
+		clock:      clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake.
+func (r *RevokedTokenRepo) WithClock(c clock.Clock) *RevokedTokenRepo {
+	r.clock = c
+	return r
+}
+
+// Revoke adds a token ID to the deny list. Revoking it twice is a no-op.
+func (r *RevokedTokenRepo) Revoke(ctx context.Context, token *models.RevokedToken) error {
+	token.CreatedAt = r.clock.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"jti": token.JTI},
+		bson.M{"$setOnInsert": bson.M{
+			"jti":        token.JTI,
+			"user_id":    token.UserID,
+			"expires_at": token.ExpiresAt,
+			"created_at": token.CreatedAt,
+		}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// IsRevoked implements the JWT middleware's revocation check.
+func (r *RevokedTokenRepo) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	err := r.collection.FindOne(ctx, bson.M{"jti": jti}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureIndexes creates necessary indexes for the revoked_tokens collection
+func (r *RevokedTokenRepo) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "jti", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
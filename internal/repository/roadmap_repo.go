@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type RoadmapRepo struct {
+	itemsCollection *mongo.Collection
+	votesCollection *mongo.Collection
+}
+
+func NewRoadmapRepo() *RoadmapRepo {
+	return &RoadmapRepo{
+		itemsCollection: database.GetCollection("roadmap_items"),
+		votesCollection: database.GetCollection("roadmap_votes"),
+	}
+}
+
+// items and votes are the roadmap collections of ctx's sandbox, if any.
+func (r *RoadmapRepo) items(ctx context.Context) *mongo.Collection {
+	return database.Scoped(ctx, r.itemsCollection)
+}
+
+func (r *RoadmapRepo) votes(ctx context.Context) *mongo.Collection {
+	return database.Scoped(ctx, r.votesCollection)
+}
+
+// Create publishes a roadmap item. Publishing the same feedback twice is a
+// duplicate key error (see mongo.IsDuplicateKeyError).
+func (r *RoadmapRepo) Create(ctx context.Context, item *models.RoadmapItem) error {
+	item.Votes = 0
+	item.CreatedAt = time.Now()
+	result, err := r.items(ctx).InsertOne(ctx, item)
+	if err != nil {
+		return err
+	}
+	item.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+func (r *RoadmapRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.RoadmapItem, error) {
+	var item models.RoadmapItem
+	err := r.items(ctx).FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// List returns up to limit items, most voted first.
+func (r *RoadmapRepo) List(ctx context.Context, limit int64) ([]models.RoadmapItem, error) {
+	cursor, err := r.items(ctx).Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "votes", Value: -1}, {Key: "created_at", Value: -1}}).
+		SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	items := []models.RoadmapItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Delete unpublishes an item along with its votes. Returns false if there
+// was no such item.
+func (r *RoadmapRepo) Delete(ctx context.Context, id bson.ObjectID) (bool, error) {
+	result, err := r.items(ctx).DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.votes(ctx).DeleteMany(ctx, bson.M{"item_id": id}); err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+// MarkShipped links the item to its changelog entry. Returns nil if there
+// is no such item or it already shipped.
+func (r *RoadmapRepo) MarkShipped(ctx context.Context, id bson.ObjectID, changelog *models.Changelog) (*models.RoadmapItem, error) {
+	var item models.RoadmapItem
+	err := r.items(ctx).FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "shipped_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"changelog": changelog, "shipped_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&item)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// EachVoter calls fn with every user who voted for the item, in vote order.
+func (r *RoadmapRepo) EachVoter(ctx context.Context, itemID bson.ObjectID, fn func(userID bson.ObjectID) error) error {
+	cursor, err := r.votes(ctx).Find(ctx, bson.M{"item_id": itemID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetProjection(bson.M{"user_id": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var vote models.RoadmapVote
+		if err := cursor.Decode(&vote); err != nil {
+			return err
+		}
+		if err := fn(vote.UserID); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// CountVotes counts the item's votes.
+func (r *RoadmapRepo) CountVotes(ctx context.Context, itemID bson.ObjectID) (int64, error) {
+	return r.votes(ctx).CountDocuments(ctx, bson.M{"item_id": itemID})
+}
+
+// Vote records the user's upvote and returns the item with its new vote
+// count, or nil if there is no such item. voted is false if they had
+// already voted for it.
+func (r *RoadmapRepo) Vote(ctx context.Context, itemID, userID bson.ObjectID) (item *models.RoadmapItem, voted bool, err error) {
+	_, err = r.votes(ctx).InsertOne(ctx, models.RoadmapVote{ItemID: itemID, UserID: userID, CreatedAt: time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		item, err = r.FindByID(ctx, itemID)
+		return item, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	item = &models.RoadmapItem{}
+	err = r.items(ctx).FindOneAndUpdate(ctx,
+		bson.M{"_id": itemID},
+		bson.M{"$inc": bson.M{"votes": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(item)
+	if err == mongo.ErrNoDocuments {
+		// No such item, or it was unpublished while voting
+		_, err = r.votes(ctx).DeleteOne(ctx, bson.M{"item_id": itemID, "user_id": userID})
+		return nil, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return item, true, nil
+}
+
+// VotedFor returns which of the items the user has voted for.
+func (r *RoadmapRepo) VotedFor(ctx context.Context, userID bson.ObjectID, itemIDs []bson.ObjectID) (map[bson.ObjectID]bool, error) {
+	voted := map[bson.ObjectID]bool{}
+	if len(itemIDs) == 0 {
+		return voted, nil
+	}
+	cursor, err := r.votes(ctx).Find(ctx, bson.M{"user_id": userID, "item_id": bson.M{"$in": itemIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var votes []models.RoadmapVote
+	if err := cursor.All(ctx, &votes); err != nil {
+		return nil, err
+	}
+	for _, v := range votes {
+		voted[v.ItemID] = true
+	}
+	return voted, nil
+}
+
+// SeedSandbox copies the published roadmap into ctx's sandbox with no
+// votes, so tests have items to vote on.
+func (r *RoadmapRepo) SeedSandbox(ctx context.Context) error {
+	cursor, err := r.itemsCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var items []interface{}
+	for cursor.Next(ctx) {
+		var item models.RoadmapItem
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		item.Votes = 0
+		items = append(items, item)
+	}
+	if err := cursor.Err(); err != nil || len(items) == 0 {
+		return err
+	}
+	_, err = r.items(ctx).InsertMany(ctx, items)
+	return err
+}
+
+// EnsureIndexes creates necessary indexes for the roadmap collections
+func (r *RoadmapRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.items(ctx).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "feedback_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "votes", Value: -1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = r.votes(ctx).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "item_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
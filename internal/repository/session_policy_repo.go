@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type SessionPolicyRepo struct {
+	collection *mongo.Collection
+}
+
+func NewSessionPolicyRepo() *SessionPolicyRepo {
+	return &SessionPolicyRepo{
+		collection: database.GetCollection("session_policy_stats"),
+	}
+}
+
+// Increment adds to a counter ("issued", "refreshed" or "expired") for a policy and day.
+func (r *SessionPolicyRepo) Increment(ctx context.Context, day, policy, field string, delta int64) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"day": day, "policy": policy},
+		bson.M{
+			"$inc": bson.M{field: delta},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// ListSince returns daily stats for all policies from sinceDay onwards.
+func (r *SessionPolicyRepo) ListSince(ctx context.Context, sinceDay string) ([]models.SessionPolicyStats, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"day": bson.M{"$gte": sinceDay}},
+		options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "policy", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	stats := []models.SessionPolicyStats{}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// EnsureIndexes creates necessary indexes for the session_policy_stats collection
+func (r *SessionPolicyRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "day", Value: 1}, {Key: "policy", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type UsageRepo struct {
+	collection *mongo.Collection
+}
+
+func NewUsageRepo() *UsageRepo {
+	return &UsageRepo{
+		collection: database.GetCollection("usage_daily"),
+	}
+}
+
+// Increment adds delta to the user's counter for the endpoint on the given day.
+func (r *UsageRepo) Increment(ctx context.Context, userID bson.ObjectID, day, endpoint string, delta int64) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "day": day, "endpoint": endpoint},
+		bson.M{
+			"$inc": bson.M{"count": delta},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// CountForDay returns the user's request count for an endpoint on a single day.
+func (r *UsageRepo) CountForDay(ctx context.Context, userID bson.ObjectID, day, endpoint string) (int64, error) {
+	var record models.UsageRecord
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID, "day": day, "endpoint": endpoint}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return record.Count, nil
+}
+
+// ListForUser returns all usage records for a user since the given day (inclusive), newest first.
+func (r *UsageRepo) ListForUser(ctx context.Context, userID bson.ObjectID, sinceDay string) ([]models.UsageRecord, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{"user_id": userID, "day": bson.M{"$gte": sinceDay}},
+		options.Find().SetSort(bson.D{{Key: "day", Value: -1}, {Key: "endpoint", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	records := []models.UsageRecord{}
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// DailyActiveUsers counts distinct users with any metered request per day
+// since the given day (inclusive), oldest first.
+func (r *UsageRepo) DailyActiveUsers(ctx context.Context, sinceDay string) ([]models.DailyActiveUsers, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"day": bson.M{"$gte": sinceDay}}}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"day": "$day", "user_id": "$user_id"}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$_id.day", "users": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	days := []models.DailyActiveUsers{}
+	if err := cursor.All(ctx, &days); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// TotalsForUsers sums the users' usage per endpoint between two days
+// (inclusive).
+func (r *UsageRepo) TotalsForUsers(ctx context.Context, userIDs []bson.ObjectID, fromDay, toDay string) (*models.UsageTotals, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": bson.M{"$in": userIDs}, "day": bson.M{"$gte": fromDay, "$lte": toDay}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$endpoint",
+			"count": bson.M{"$sum": "$count"},
+			"users": bson.M{"$addToSet": "$user_id"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Endpoint string          `bson:"_id"`
+		Count    int64           `bson:"count"`
+		Users    []bson.ObjectID `bson:"users"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	totals := &models.UsageTotals{Requests: map[string]int64{}}
+	active := map[bson.ObjectID]bool{}
+	for _, row := range rows {
+		totals.Requests[row.Endpoint] = row.Count
+		totals.Total += row.Count
+		for _, id := range row.Users {
+			active[id] = true
+		}
+	}
+	totals.ActiveUsers = len(active)
+	return totals, nil
+}
+
+// DeleteBefore removes usage records older than the given day. Returns the number deleted.
+func (r *UsageRepo) DeleteBefore(ctx context.Context, day string) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"day": bson.M{"$lt": day}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureIndexes creates necessary indexes for the usage_daily collection
+func (r *UsageRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "day", Value: -1}, {Key: "endpoint", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "day", Value: 1}, {Key: "user_id", Value: 1}}},
+	})
+	return err
+}
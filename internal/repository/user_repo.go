@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"rizon-backend/internal/database"
@@ -14,6 +17,7 @@ import (
 
 type UserRepo struct {
 	collection *mongo.Collection
+	onChange   []func(id bson.ObjectID)
 }
 
 func NewUserRepo() *UserRepo {
@@ -22,6 +26,20 @@ func NewUserRepo() *UserRepo {
 	}
 }
 
+// OnChange registers fn to be called after every write to a user's
+// onboarding, profile, age or account status. Last-seen updates aren't
+// reported. Hooks must be registered before the repo is used.
+func (r *UserRepo) OnChange(fn func(id bson.ObjectID)) *UserRepo {
+	r.onChange = append(r.onChange, fn)
+	return r
+}
+
+func (r *UserRepo) changed(id bson.ObjectID) {
+	for _, fn := range r.onChange {
+		fn(id)
+	}
+}
+
 func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
@@ -36,7 +54,7 @@ func (r *UserRepo) FindByEmail(ctx context.Context, email string) (*models.User,
 
 func (r *UserRepo) FindByID(ctx context.Context, id bson.ObjectID) (*models.User, error) {
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	err := database.Reader(ctx, r.collection).FindOne(ctx, bson.M{"_id": id}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -57,6 +75,18 @@ func (r *UserRepo) Create(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// Import inserts a migrated user, keeping its original created_at.
+// Returns a duplicate key error if the email is already registered.
+func (r *UserRepo) Import(ctx context.Context, user *models.User) error {
+	user.UpdatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, user)
+	if err != nil {
+		return err
+	}
+	user.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
 func (r *UserRepo) FindOrCreate(ctx context.Context, email string) (*models.User, error) {
 	user, err := r.FindByEmail(ctx, email)
 	if err != nil {
@@ -76,21 +106,393 @@ func (r *UserRepo) FindOrCreate(ctx context.Context, email string) (*models.User
 	return newUser, nil
 }
 
-func (r *UserRepo) UpdateOnboarding(ctx context.Context, id bson.ObjectID, completed bool) error {
+// UpdateOnboarding sets the onboarding flag and, when answers is non-nil,
+// replaces the stored answers.
+func (r *UserRepo) UpdateOnboarding(ctx context.Context, id bson.ObjectID, completed bool, answers map[string]interface{}) error {
+	set := bson.M{
+		"onboarding_completed": completed,
+		"updated_at":           time.Now(),
+	}
+	if answers != nil {
+		set["onboarding_answers"] = answers
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err == nil {
+		r.changed(id)
+	}
+	return err
+}
+
+// Patch applies a $set/$unset update (see mergepatch) and returns the updated
+// user, or nil if there is none.
+func (r *UserRepo) Patch(ctx context.Context, id bson.ObjectID, update bson.M) (*models.User, error) {
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+	}
+	set["updated_at"] = time.Now()
+	update["$set"] = set
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.changed(id)
+	return &user, nil
+}
+
+// SetLastSeen moves the user's last-seen time forward. appVersion is only
+// stored when set.
+func (r *UserRepo) SetLastSeen(ctx context.Context, id bson.ObjectID, at time.Time, appVersion string) error {
+	set := bson.M{"last_seen_at": at}
+	if appVersion != "" {
+		set["last_app_version"] = appVersion
+	}
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "last_seen_at": bson.M{"$not": bson.M{"$gt": at}}},
+		bson.M{"$set": set},
+	)
+	return err
+}
+
+// SetAvatar points the user's avatar at an image attachment.
+func (r *UserRepo) SetAvatar(ctx context.Context, id, attachmentID bson.ObjectID) error {
 	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
 		"$set": bson.M{
-			"onboarding_completed": completed,
-			"updated_at":           time.Now(),
+			"avatar_id":  attachmentID,
+			"updated_at": time.Now(),
 		},
 	})
+	if err == nil {
+		r.changed(id)
+	}
 	return err
 }
 
+// SetAge stores the user's age band and region. Blocking is one-way: a blocked
+// account is never unblocked by a later age submission.
+func (r *UserRepo) SetAge(ctx context.Context, id bson.ObjectID, band, country string, blocked bool) error {
+	set := bson.M{
+		"age_band":    band,
+		"age_country": country,
+		"updated_at":  time.Now(),
+	}
+	if blocked {
+		set["age_blocked"] = true
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err == nil {
+		r.changed(id)
+	}
+	return err
+}
+
+// SetStatus changes the account status. Reason and until are cleared when
+// reactivating. Returns false if the user doesn't exist.
+func (r *UserRepo) SetStatus(ctx context.Context, id bson.ObjectID, status, reason string, until *time.Time) (bool, error) {
+	update := bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}}
+	if status == models.UserStatusActive {
+		update["$unset"] = bson.M{"ban_reason": "", "banned_until": ""}
+	} else {
+		set := update["$set"].(bson.M)
+		set["ban_reason"] = reason
+		if until != nil {
+			set["banned_until"] = *until
+		} else {
+			update["$unset"] = bson.M{"banned_until": ""}
+		}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return false, err
+	}
+	r.changed(id)
+	return result.MatchedCount == 1, nil
+}
+
+// MarkMerged deactivates source in favour of target. Returns false if source
+// doesn't exist or was already merged into another account; marking it
+// merged into target again is a no-op that returns true.
+func (r *UserRepo) MarkMerged(ctx context.Context, source, target bson.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": source, "$or": bson.A{
+			bson.M{"status": bson.M{"$ne": models.UserStatusMerged}},
+			bson.M{"merged_into": target},
+		}},
+		bson.M{
+			"$set":   bson.M{"status": models.UserStatusMerged, "merged_into": target, "updated_at": time.Now()},
+			"$unset": bson.M{"ban_reason": "", "banned_until": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	r.changed(source)
+	return result.MatchedCount == 1, nil
+}
+
+// LiftExpiredRestrictions reactivates accounts whose suspension or ban has run out.
+func (r *UserRepo) LiftExpiredRestrictions(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{
+			"status":       bson.M{"$in": bson.A{models.UserStatusSuspended, models.UserStatusBanned}},
+			"banned_until": bson.M{"$lte": now},
+		},
+		bson.M{
+			"$set":   bson.M{"status": models.UserStatusActive, "updated_at": now},
+			"$unset": bson.M{"ban_reason": "", "banned_until": ""},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// SetSignupGeo stores where the user signed up along with the derived locale
+// and time zone. Only the first call per user has any effect.
+func (r *UserRepo) SetSignupGeo(ctx context.Context, id bson.ObjectID, loc *models.GeoLocation, locale, timeZone string) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "signup_geo": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"signup_geo": loc,
+			"locale":     locale,
+			"time_zone":  timeZone,
+			"updated_at": time.Now(),
+		}},
+	)
+	if err == nil {
+		r.changed(id)
+	}
+	return err
+}
+
+// SignupRollup counts users created in [from, to) per day and signup
+// country.
+func (r *UserRepo) SignupRollup(ctx context.Context, from, to time.Time) ([]models.MetricsRollup, error) {
+	return aggregateRollups(ctx, r.collection, rollupPipeline(
+		models.MetricSignups, models.RollupDaily, "created_at", from, to, nil,
+		bson.M{"$ifNull": bson.A{"$signup_geo.country", ""}},
+		bson.M{"count": bson.M{"$sum": 1}},
+	))
+}
+
+// audiencePipeline selects the active users matching filter. The app
+// version is compared numerically, part by part.
+func audiencePipeline(filter models.AudienceFilter) mongo.Pipeline {
+	match := bson.M{
+		"status":      bson.M{"$in": bson.A{nil, ""}},
+		"age_blocked": bson.M{"$ne": true},
+	}
+	created := bson.M{}
+	if filter.SignedUpAfter != nil {
+		created["$gte"] = *filter.SignedUpAfter
+	}
+	if filter.SignedUpBefore != nil {
+		created["$lt"] = *filter.SignedUpBefore
+	}
+	if len(created) > 0 {
+		match["created_at"] = created
+	}
+	if len(filter.Countries) > 0 {
+		match["$or"] = bson.A{
+			bson.M{"age_country": bson.M{"$in": filter.Countries}},
+			bson.M{"age_country": bson.M{"$in": bson.A{nil, ""}}, "signup_geo.country": bson.M{"$in": filter.Countries}},
+		}
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+
+	var versionChecks bson.A
+	if parts, ok := models.AppVersionParts(filter.MinAppVersion); ok {
+		versionChecks = append(versionChecks, bson.M{"$gte": bson.A{"$_app_version", parts}})
+	}
+	if parts, ok := models.AppVersionParts(filter.MaxAppVersion); ok {
+		versionChecks = append(versionChecks, bson.M{"$lte": bson.A{"$_app_version", parts}})
+	}
+	if len(versionChecks) > 0 {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$match", Value: bson.M{"last_app_version": bson.M{"$nin": bson.A{nil, ""}}}}},
+			bson.D{{Key: "$addFields", Value: bson.M{"_app_version": bson.M{"$slice": bson.A{
+				bson.M{"$concatArrays": bson.A{
+					bson.M{"$map": bson.M{
+						"input": bson.M{"$split": bson.A{bson.M{"$arrayElemAt": bson.A{bson.M{"$split": bson.A{"$last_app_version", "-"}}, 0}}, "."}},
+						"as":    "part",
+						"in":    bson.M{"$convert": bson.M{"input": "$$part", "to": "int", "onError": 0, "onNull": 0}},
+					}},
+					bson.A{0, 0, 0},
+				}},
+				3,
+			}}}}},
+			bson.D{{Key: "$match", Value: bson.M{"$expr": bson.M{"$and": versionChecks}}}},
+		)
+	}
+
+	wantIndividual := slices.Contains(filter.Plans, models.PlanIndividual)
+	wantOrganization := slices.Contains(filter.Plans, models.PlanOrganization)
+	if wantIndividual != wantOrganization {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$lookup", Value: bson.M{
+				"from":         "org_members",
+				"localField":   "_id",
+				"foreignField": "user_id",
+				"as":           "_memberships",
+			}}},
+			bson.D{{Key: "$match", Value: bson.M{"_memberships.0": bson.M{"$exists": wantOrganization}}}},
+		)
+	}
+	return pipeline
+}
+
+// CountAudience counts the active users matching filter.
+func (r *UserRepo) CountAudience(ctx context.Context, filter models.AudienceFilter) (int64, error) {
+	pipeline := append(audiencePipeline(filter), bson.D{{Key: "$count", Value: "users"}})
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	var rows []struct {
+		Users int64 `bson:"users"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	return rows[0].Users, nil
+}
+
+// EachInAudience calls fn for every active user matching filter, stopping at
+// fn's first error.
+func (r *UserRepo) EachInAudience(ctx context.Context, filter models.AudienceFilter, fn func(*models.User) error) error {
+	pipeline := append(audiencePipeline(filter),
+		bson.D{{Key: "$sort", Value: bson.M{"_id": 1}}},
+		bson.D{{Key: "$project", Value: bson.M{"_app_version": 0, "_memberships": 0}}},
+	)
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// AwaitingOnboardingReminder returns up to limit active users who signed up
+// in [from, to), haven't completed onboarding and have no record of the
+// reminder step. Imported users never signed in, so they're left out.
+func (r *UserRepo) AwaitingOnboardingReminder(ctx context.Context, step string, from, to time.Time, limit int64) ([]models.User, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"created_at":           bson.M{"$gte": from, "$lt": to},
+			"onboarding_completed": false,
+			"status":               bson.M{"$in": bson.A{nil, ""}},
+			"age_blocked":          bson.M{"$ne": true},
+			"import_job_id":        bson.M{"$exists": false},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "onboarding_reminders",
+			"let":  bson.M{"user": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$user_id", "$$user"}},
+					bson.M{"$eq": bson.A{"$step", step}},
+				}}}},
+				bson.M{"$limit": 1},
+			},
+			"as": "_reminded",
+		}}},
+		{{Key: "$match", Value: bson.M{"_reminded": bson.M{"$size": 0}}}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_reminded": 0}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// InactiveForWinback returns up to limit users in the audience last seen
+// before seenBefore who had no winback email (sent or skipped) since
+// cooldownSince. Addresses at excluded domains are left out, as are users
+// never seen at all.
+func (r *UserRepo) InactiveForWinback(ctx context.Context, filter models.AudienceFilter, seenBefore, cooldownSince time.Time, excludeDomains []string, limit int64) ([]models.User, error) {
+	seen := bson.M{"last_seen_at": bson.M{"$lt": seenBefore}}
+	if len(excludeDomains) > 0 {
+		quoted := make([]string, len(excludeDomains))
+		for i, d := range excludeDomains {
+			quoted[i] = regexp.QuoteMeta(d)
+		}
+		seen["email"] = bson.M{"$not": bson.Regex{Pattern: "@(" + strings.Join(quoted, "|") + ")$", Options: "i"}}
+	}
+	pipeline := append(audiencePipeline(filter),
+		bson.D{{Key: "$match", Value: seen}},
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from": "winback_sends",
+			"let":  bson.M{"user": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$user_id", "$$user"}},
+					bson.M{"$gte": bson.A{"$sent_at", cooldownSince}},
+				}}}},
+				bson.M{"$limit": 1},
+			},
+			"as": "_winback",
+		}}},
+		bson.D{{Key: "$match", Value: bson.M{"_winback": bson.M{"$size": 0}}}},
+		bson.D{{Key: "$sort", Value: bson.M{"last_seen_at": 1}}},
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$project", Value: bson.M{"_app_version": 0, "_memberships": 0, "_winback": 0}}},
+	)
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 // EnsureIndexes creates necessary indexes for the users collection
 func (r *UserRepo) EnsureIndexes(ctx context.Context) error {
-	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    bson.D{{Key: "email", Value: 1}},
-		Options: options.Index().SetUnique(true),
-	})
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "banned_until", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		// Onboarding reminders
+		{Keys: bson.D{{Key: "onboarding_completed", Value: 1}, {Key: "created_at", Value: 1}}},
+		// Winback
+		{
+			Keys:    bson.D{{Key: "last_seen_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
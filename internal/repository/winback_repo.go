@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/database"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// winbackSettingsID is the _id of the winback settings in email_settings.
+const winbackSettingsID = "winback"
+
+// WinbackRepo stores the winback settings and winback_sends. Sends must live
+// in the same database as users, which look them up for the cooldown.
+type WinbackRepo struct {
+	settings *mongo.Collection
+	sends    *mongo.Collection
+}
+
+func NewWinbackRepo() *WinbackRepo {
+	return &WinbackRepo{
+		settings: database.GetCollection("email_settings"),
+		sends:    database.GetCollection("winback_sends"),
+	}
+}
+
+// Settings returns the saved settings, or nil if an admin never set any.
+func (r *WinbackRepo) Settings(ctx context.Context) (*models.WinbackSettings, error) {
+	var settings models.WinbackSettings
+	err := r.settings.FindOne(ctx, bson.M{"_id": winbackSettingsID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveSettings replaces the winback settings.
+func (r *WinbackRepo) SaveSettings(ctx context.Context, settings *models.WinbackSettings) error {
+	_, err := r.settings.UpdateOne(ctx,
+		bson.M{"_id": winbackSettingsID},
+		bson.M{"$set": settings},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// Record stores a send or a skip.
+func (r *WinbackRepo) Record(ctx context.Context, send *models.WinbackSend) error {
+	send.SentAt = time.Now()
+	result, err := r.sends.InsertOne(ctx, send)
+	if err != nil {
+		return err
+	}
+	send.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// SetJob records the email job delivering a send.
+func (r *WinbackRepo) SetJob(ctx context.Context, id, jobID bson.ObjectID) error {
+	_, err := r.sends.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"job_id": jobID}})
+	return err
+}
+
+// Delete removes a send whose email couldn't be queued, so the next run
+// tries again.
+func (r *WinbackRepo) Delete(ctx context.Context, id bson.ObjectID) error {
+	_, err := r.sends.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// MarkReactivated sets reactivated_at on sends since the given time whose
+// user has been seen after being emailed, and returns how many it marked.
+func (r *WinbackRepo) MarkReactivated(ctx context.Context, since time.Time) (int, error) {
+	cursor, err := r.sends.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"sent_at":        bson.M{"$gte": since},
+			"skipped":        bson.M{"$exists": false},
+			"reactivated_at": bson.M{"$exists": false},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "user_id",
+			"foreignField": "_id",
+			"as":           "user",
+		}}},
+		{{Key: "$unwind", Value: "$user"}},
+		{{Key: "$match", Value: bson.M{"$expr": bson.M{"$gt": bson.A{"$user.last_seen_at", "$last_seen_at"}}}}},
+		{{Key: "$project", Value: bson.M{"seen": "$user.last_seen_at"}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var back []struct {
+		ID   bson.ObjectID `bson:"_id"`
+		Seen time.Time     `bson:"seen"`
+	}
+	if err := cursor.All(ctx, &back); err != nil {
+		return 0, err
+	}
+	for _, b := range back {
+		if _, err := r.sends.UpdateOne(ctx, bson.M{"_id": b.ID}, bson.M{"$set": bson.M{"reactivated_at": b.Seen}}); err != nil {
+			return 0, err
+		}
+	}
+	return len(back), nil
+}
+
+// Rollup computes the daily winback funnel for [from, to): emails sent in
+// each day and users who came back in it.
+func (r *WinbackRepo) Rollup(ctx context.Context, from, to time.Time) ([]models.MetricsRollup, error) {
+	sent, err := aggregateRollups(ctx, r.sends, rollupPipeline(
+		models.MetricWinback, models.RollupDaily, "sent_at", from, to,
+		bson.M{"skipped": bson.M{"$exists": false}},
+		"",
+		bson.M{"sent": bson.M{"$sum": 1}},
+	))
+	if err != nil {
+		return nil, err
+	}
+	reactivated, err := aggregateRollups(ctx, r.sends, rollupPipeline(
+		models.MetricWinback, models.RollupDaily, "reactivated_at", from, to, nil,
+		"",
+		bson.M{"reactivated": bson.M{"$sum": 1}},
+	))
+	if err != nil {
+		return nil, err
+	}
+	return append(sent, reactivated...), nil
+}
+
+// EnsureIndexes creates necessary indexes for the winback_sends collection
+func (r *WinbackRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.sends.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "sent_at", Value: -1}}},
+		{Keys: bson.D{{Key: "sent_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "reactivated_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	return err
+}
@@ -0,0 +1,192 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// ErrOpen is returned by Breaker.Do while the circuit rejects calls.
+var ErrOpen = errors.New("circuit open")
+
+// BreakerConfig tunes when a circuit opens and how long it stays open.
+type BreakerConfig struct {
+	// Consecutive failures that open the circuit
+	Threshold int
+	// How long an open circuit rejects calls before allowing a trial
+	Cooldown time.Duration
+}
+
+var DefaultBreakerConfig = BreakerConfig{
+	Threshold: 5,
+	Cooldown:  30 * time.Second,
+}
+
+// Breaker is a circuit breaker for calls to one external service. It opens
+// after Threshold consecutive failures and lets a single trial call through
+// per cooldown until one succeeds.
+type Breaker struct {
+	name string
+	cfg  BreakerConfig
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	calls    int64
+	failed   int64
+	rejected int64
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Breaker{}
+)
+
+// NewBreaker creates a breaker and registers it under name for Breakers().
+// A later breaker with the same name replaces the earlier one.
+func NewBreaker(name string, cfg BreakerConfig) *Breaker {
+	if cfg.Threshold < 1 {
+		cfg.Threshold = 1
+	}
+	b := &Breaker{name: name, cfg: cfg, state: StateClosed}
+
+	registryMu.Lock()
+	registry[name] = b
+	registryMu.Unlock()
+	return b
+}
+
+func (b *Breaker) Name() string { return b.name }
+
+// Allow reports whether a call may proceed, moving an open circuit to
+// half-open once the cooldown has passed.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateClosed {
+		// One trial per cooldown, in case a trial was abandoned without a result
+		if time.Since(b.openedAt) < b.cfg.Cooldown {
+			b.rejected++
+			return false
+		}
+		b.state = StateHalfOpen
+		b.openedAt = time.Now()
+	}
+	return true
+}
+
+// Failure records a failed call and reports whether the circuit just opened.
+func (b *Breaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls++
+	b.failed++
+	b.failures++
+	wasOpen := b.state != StateClosed
+	if b.state == StateHalfOpen || b.failures >= b.cfg.Threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+	return !wasOpen && b.state == StateOpen
+}
+
+// Success records a successful call and reports whether the circuit was
+// open until now.
+func (b *Breaker) Success() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.calls++
+	recovered := b.state != StateClosed
+	b.state = StateClosed
+	b.failures = 0
+	return recovered
+}
+
+// Do runs fn if the circuit allows it and records the outcome. Permanent
+// errors mean the service answered, so they don't count as failures, and
+// neither does the caller's own context ending.
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !b.Allow() {
+		return ErrOpen
+	}
+	err := fn(ctx)
+	switch {
+	case err == nil || IsPermanent(err):
+		b.Success()
+	case ctx.Err() != nil:
+	default:
+		b.Failure()
+	}
+	return err
+}
+
+// BreakerStats is a point-in-time view of a breaker, counted since startup.
+type BreakerStats struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	Calls               int64      `json:"calls"`
+	Failed              int64      `json:"failed"`
+	Rejected            int64      `json:"rejected"`
+}
+
+func (b *Breaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BreakerStats{
+		Name:                b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Calls:               b.calls,
+		Failed:              b.failed,
+		Rejected:            b.rejected,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
+
+// Breakers returns the stats of every registered breaker sorted by name.
+func Breakers() []BreakerStats {
+	registryMu.Lock()
+	breakers := make([]*Breaker, 0, len(registry))
+	for _, b := range registry {
+		breakers = append(breakers, b)
+	}
+	registryMu.Unlock()
+
+	stats := make([]BreakerStats, len(breakers))
+	for i, b := range breakers {
+		stats[i] = b.Stats()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Lookup returns the stats of the named breaker, if one is registered.
+func Lookup(name string) (BreakerStats, bool) {
+	registryMu.Lock()
+	b, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return BreakerStats{}, false
+	}
+	return b.Stats(), true
+}
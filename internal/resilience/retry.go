@@ -0,0 +1,80 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy bounds Retry. Delays grow exponentially from BaseDelay up to
+// MaxDelay with full jitter, so retrying clients don't stampede a
+// recovering service.
+type RetryPolicy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  2 * time.Second,
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error as not worth retrying (bad request, invalid
+// credentials). The original error stays reachable via errors.Is/As.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Retry calls fn until it succeeds, returns a permanent error, the attempts
+// run out or ctx ends. It returns fn's last error.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	attempts := max(policy.Attempts, 1)
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(Backoff(policy, attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+		if err = fn(ctx); err == nil || IsPermanent(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Backoff returns a jittered delay before the given retry (1-based).
+func Backoff(policy RetryPolicy, retry int) time.Duration {
+	ceiling := policy.MaxDelay
+	if shift := retry - 1; shift < 30 {
+		if d := policy.BaseDelay << shift; d > 0 && d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
@@ -0,0 +1,102 @@
+// Package roadmap tells users when a feature they voted for ships. The job
+// only fans out: each voter gets their own bulkmail job, as for campaigns.
+// Push isn't sent: devices don't register push tokens yet.
+package roadmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rizon-backend/internal/bulkmail"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/templates"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// JobType is the job queue type for shipped-feature fan-out.
+const JobType = "roadmap_shipped"
+
+// Payload is stored on the job.
+type Payload struct {
+	ItemID bson.ObjectID `bson:"item_id"`
+}
+
+// Handler returns the job handler. Voters who turned product emails off,
+// or whose accounts are restricted, are skipped. Once any email has been
+// queued a failure is permanent, so a retry never emails a voter twice.
+func Handler(roadmap *repository.RoadmapRepo, users *repository.UserRepo, queue *jobs.Queue) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) (bson.M, error) {
+		var payload Payload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid payload: %v", jobs.ErrPermanent, err)
+		}
+
+		item, err := roadmap.FindByID(ctx, payload.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil || item.Changelog == nil {
+			// Unpublished since
+			return bson.M{"queued": 0}, nil
+		}
+		total, err := roadmap.CountVotes(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		createdBy := "roadmap:" + item.ID.Hex()
+		now := time.Now()
+		var queued, skipped int64
+		err = roadmap.EachVoter(ctx, item.ID, func(userID bson.ObjectID) error {
+			defer func() {
+				jobs.ReportProgress(ctx, queued+skipped, total, fmt.Sprintf("%d emails queued", queued))
+			}()
+			user, err := users.FindByID(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if !notifiable(user, now) {
+				skipped++
+				return nil
+			}
+			content, err := templates.RenderEmail("roadmap_shipped", templates.EmailOptionsFor(user, ""), map[string]interface{}{
+				"Title":          item.Title,
+				"ChangelogURL":   item.Changelog.URL,
+				"ChangelogTitle": item.Changelog.Title,
+				"Brand":          templates.DefaultBrand,
+			})
+			if err != nil {
+				return fmt.Errorf("%w: %v", jobs.ErrPermanent, err)
+			}
+			if _, err := bulkmail.Enqueue(ctx, queue, user, mailer.FromRendered(user.Email, content), createdBy); err != nil {
+				return err
+			}
+			queued++
+			return nil
+		})
+		if err != nil {
+			if queued > 0 && !errors.Is(err, jobs.ErrPermanent) {
+				err = fmt.Errorf("%w: stopped after queueing %d emails: %v", jobs.ErrPermanent, queued, err)
+			}
+			return nil, err
+		}
+		return bson.M{"voters": total, "queued": queued, "skipped": skipped}, nil
+	}
+}
+
+// notifiable reports whether the voter should hear about the release.
+func notifiable(user *models.User, now time.Time) bool {
+	if user == nil || user.Status == models.UserStatusMerged || user.IsRestricted(now) || user.AgeBlocked {
+		return false
+	}
+	if p := user.Preferences; p != nil && p.ProductEmails != nil && !*p.ProductEmails {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+// Package rollup keeps hourly and daily aggregates of raw analytics events in
+// the metrics_rollups collection, so admin metrics read a few documents per
+// bucket instead of scanning every event.
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+)
+
+// Horizon is how far back a metric is first rolled up; admin metrics look
+// back a year at most.
+const Horizon = 366 * 24 * time.Hour
+
+// maxSpan bounds the raw events aggregated at once while catching up.
+const maxSpan = 30 * 24 * time.Hour
+
+// Source computes a metric's buckets from the raw events in [from, to).
+type Source struct {
+	Metric      string
+	Granularity string // models.RollupHourly or models.RollupDaily
+	// Settle is how long after a bucket ends its events may still change
+	// (a login link can be clicked until it expires).
+	Settle  time.Duration
+	Compute func(ctx context.Context, from, to time.Time) ([]models.MetricsRollup, error)
+}
+
+func (s Source) truncate(t time.Time) time.Time {
+	t = t.UTC()
+	if s.Granularity == models.RollupDaily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// Roller rolls up its sources and answers queries from the rollups, reading
+// raw events only for buckets not final yet.
+type Roller struct {
+	repo    *repository.MetricsRollupRepo
+	sources map[string]Source
+	order   []string
+}
+
+func NewRoller(repo *repository.MetricsRollupRepo, sources ...Source) *Roller {
+	r := &Roller{repo: repo, sources: map[string]Source{}}
+	for _, s := range sources {
+		r.sources[s.Metric] = s
+		r.order = append(r.order, s.Metric)
+	}
+	return r
+}
+
+// Run rolls every metric up to its last settled bucket. Meant to run from
+// the scheduler, so only one replica rolls up at a time.
+func (r *Roller) Run(ctx context.Context, now time.Time) error {
+	for _, metric := range r.order {
+		if err := r.roll(ctx, r.sources[metric], now); err != nil {
+			return fmt.Errorf("rolling up %s: %w", metric, err)
+		}
+	}
+	return nil
+}
+
+func (r *Roller) roll(ctx context.Context, source Source, now time.Time) error {
+	from, err := r.repo.RolledUntil(ctx, source.Metric)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		from = source.truncate(now.Add(-Horizon))
+	}
+	until := source.truncate(now.Add(-source.Settle))
+
+	for from.Before(until) {
+		to := until
+		if to.Sub(from) > maxSpan {
+			to = source.truncate(from.Add(maxSpan))
+		}
+		rollups, err := source.Compute(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if err := r.repo.Save(ctx, source.Metric, from, to, rollups); err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+// Totals sums a metric's values per dimension from the start of the bucket
+// containing since until now, returning that start. Final buckets come from
+// the rollups; later ones are computed from raw events.
+func (r *Roller) Totals(ctx context.Context, metric string, since, now time.Time, fields []string) ([]models.RollupTotal, time.Time, error) {
+	source, ok := r.sources[metric]
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("unknown metric %q", metric)
+	}
+	since = source.truncate(since)
+
+	split, err := r.repo.RolledUntil(ctx, metric)
+	if err != nil {
+		return nil, since, err
+	}
+	if split.Before(since) {
+		split = since
+	}
+	if split.After(now) {
+		split = now
+	}
+
+	sums := map[string]map[string]int64{}
+	var dims []string
+	add := func(dimension string, values map[string]int64) {
+		sum, ok := sums[dimension]
+		if !ok {
+			sum = map[string]int64{}
+			sums[dimension] = sum
+			dims = append(dims, dimension)
+		}
+		for _, f := range fields {
+			sum[f] += values[f]
+		}
+	}
+
+	if split.After(since) {
+		totals, err := r.repo.Totals(ctx, metric, since, split, fields)
+		if err != nil {
+			return nil, since, err
+		}
+		for _, t := range totals {
+			add(t.Dimension, t.Values)
+		}
+	}
+	live, err := source.Compute(ctx, split, now)
+	if err != nil {
+		return nil, since, err
+	}
+	for _, b := range live {
+		add(b.Meta.Dimension, b.Values)
+	}
+
+	out := make([]models.RollupTotal, 0, len(dims))
+	for _, d := range dims {
+		out = append(out, models.RollupTotal{Dimension: d, Values: sums[d]})
+	}
+	return out, since, nil
+}
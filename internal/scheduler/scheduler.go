@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/clock"
+)
+
+// Locker hands out leases, so a job runs on one replica per interval.
+// *lock.Locker implements it.
+type Locker interface {
+	Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error)
+}
+
+// Job is a named unit of periodic work. Run is passed the scheduler's
+// clock reading, which jobs should use instead of time.Now.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) error
+}
+
+// Scheduler runs periodic jobs exactly once per interval across all replicas.
+// Every replica ticks, but only the one that wins the job's lease runs it; the
+// lease is held for (almost) the whole interval so offset tickers on other
+// replicas can't fire it again.
+type Scheduler struct {
+	locker Locker
+	jobs   []Job
+	clock  clock.Clock
+}
+
+func New(locker Locker) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		clock:  clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a clock.Fake whose Advance
+// fires the jobs' tickers. Must be called before Run.
+func (s *Scheduler) WithClock(c clock.Clock) *Scheduler {
+	s.clock = c
+	return s
+}
+
+// Every registers a job. Must be called before Run.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context, now time.Time) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Run starts all registered jobs and blocks until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := s.clock.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	lease := job.Interval - job.Interval/10
+	acquired, err := s.locker.Acquire(ctx, "scheduler:"+job.Name, lease)
+	if err != nil {
+		log.Printf("Error acquiring lock for job %s: %v", job.Name, err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	start := s.clock.Now()
+	if err := job.Run(ctx, start); err != nil {
+		log.Printf("❌ Scheduled job %s failed: %v", job.Name, err)
+		return
+	}
+	log.Printf("⏱️  Scheduled job %s finished in %s", job.Name, s.clock.Now().Sub(start).Round(time.Millisecond))
+}
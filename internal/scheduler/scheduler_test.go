@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"rizon-backend/internal/clock"
+)
+
+// fakeLocker grants leases that expire on a clock, like lock.Locker.
+type fakeLocker struct {
+	clock    clock.Clock
+	mu       sync.Mutex
+	leases   map[string]time.Time
+	attempts int
+}
+
+func (l *fakeLocker) Acquire(_ context.Context, name string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts++
+	now := l.clock.Now()
+	if until, held := l.leases[name]; held && now.Before(until) {
+		return false, nil
+	}
+	l.leases[name] = now.Add(ttl)
+	return true, nil
+}
+
+func TestSchedulerRunsOncePerIntervalAcrossReplicas(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	locker := &fakeLocker{clock: fake, leases: map[string]time.Time{}}
+
+	runs := make(chan time.Time, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 2 {
+		s := New(locker).WithClock(fake)
+		s.Every("cleanup", time.Hour, func(_ context.Context, now time.Time) error {
+			runs <- now
+			return nil
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Run(ctx)
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	eventually(t, "tickers started", func() bool { return fake.Tickers() == 2 })
+
+	fake.Advance(time.Hour - time.Second)
+	expectNoRun(t, runs)
+
+	for i := 1; i <= 3; i++ {
+		fake.Advance(time.Second)
+		want := start.Add(time.Duration(i) * time.Hour)
+		select {
+		case got := <-runs:
+			if !got.Equal(want) {
+				t.Errorf("run %d saw now = %v, want %v", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("run %d: job didn't run at %v", i, want)
+		}
+		// The other replica ticked too but the lease was taken
+		eventually(t, "both replicas tried", func() bool { return locker.tried() == 2*i })
+		expectNoRun(t, runs)
+		fake.Advance(time.Hour - time.Second)
+	}
+}
+
+func (l *fakeLocker) tried() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.attempts
+}
+
+// eventually waits for the job goroutines to catch up with the fake clock.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting: %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func expectNoRun(t *testing.T, runs <-chan time.Time) {
+	t.Helper()
+	select {
+	case got := <-runs:
+		t.Fatalf("unexpected run at %v", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,152 @@
+// Package sentry reports errors to Sentry through its HTTP store API. It
+// covers what the server needs (panics with a stack trace and request
+// context) without pulling in the full SDK.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/httpclient"
+)
+
+// Client sends events to one Sentry project. A nil *Client drops events, so
+// callers don't need to check whether reporting is configured.
+type Client struct {
+	storeURL    string
+	auth        string
+	environment string
+	release     string
+	http        *http.Client
+}
+
+// New parses a DSN of the form https://<key>@<host>/<project>.
+func New(dsn, environment, release string) (*Client, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	project := strings.Trim(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || project == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid DSN: expected https://<key>@<host>/<project>")
+	}
+	return &Client{
+		storeURL:    fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=rizon-backend/1.0, sentry_key=%s", u.User.Username()),
+		environment: environment,
+		release:     release,
+		http:        httpclient.New("sentry", httpclient.Options{}),
+	}, nil
+}
+
+// Event is one error occurrence.
+type Event struct {
+	Type    string // exception type, e.g. "panic"
+	Message string
+	Frames  []runtime.Frame // innermost first, as returned by runtime.CallersFrames
+	Request *http.Request   // optional
+	Tags    map[string]string
+}
+
+// Capture sends the event in the background. Delivery is best-effort: a
+// failure is logged and the event is dropped.
+func (c *Client) Capture(e Event) {
+	if c == nil {
+		return
+	}
+	body, err := json.Marshal(c.payload(e))
+	if err != nil {
+		log.Printf("Error encoding Sentry event: %v", err)
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := c.send(ctx, body); err != nil {
+			log.Printf("Error reporting to Sentry: %v", err)
+		}
+	}()
+}
+
+func (c *Client) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", c.auth)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+type frame struct {
+	Filename string `json:"filename"`
+	Function string `json:"function"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func (c *Client) payload(e Event) map[string]interface{} {
+	// Sentry wants frames oldest call first
+	frames := make([]frame, 0, len(e.Frames))
+	for i := len(e.Frames) - 1; i >= 0; i-- {
+		f := e.Frames[i]
+		frames = append(frames, frame{
+			Filename: f.File,
+			Function: f.Function,
+			Lineno:   f.Line,
+			InApp:    strings.HasPrefix(f.Function, "rizon-backend/"),
+		})
+	}
+
+	event := map[string]interface{}{
+		"event_id":    eventID(),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"level":       "error",
+		"platform":    "go",
+		"environment": c.environment,
+		"release":     c.release,
+		"tags":        e.Tags,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       e.Type,
+				"value":      e.Message,
+				"stacktrace": map[string]interface{}{"frames": frames},
+			}},
+		},
+	}
+	if r := e.Request; r != nil {
+		// Headers and bodies are left out; they may carry credentials
+		event["request"] = map[string]interface{}{
+			"method": r.Method,
+			"url":    r.URL.Path,
+		}
+	}
+	return event
+}
+
+func eventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var ErrAudienceNotFound = errors.New("audience not found")
+
+// AudienceService manages the admin-defined audiences that announcements and
+// campaigns are sent to.
+type AudienceService struct {
+	repo     *repository.AudienceRepo
+	userRepo *repository.UserRepo
+}
+
+func NewAudienceService(repo *repository.AudienceRepo, userRepo *repository.UserRepo) *AudienceService {
+	return &AudienceService{repo: repo, userRepo: userRepo}
+}
+
+// NormalizeFilter validates the filter in place, upper-casing countries and
+// lower-casing plans.
+func NormalizeFilter(filter *models.AudienceFilter) error {
+	if filter.SignedUpAfter != nil && filter.SignedUpBefore != nil && !filter.SignedUpAfter.Before(*filter.SignedUpBefore) {
+		return invalid("signed_up_after must be before signed_up_before")
+	}
+	for i, plan := range filter.Plans {
+		plan = strings.ToLower(strings.TrimSpace(plan))
+		if !slices.Contains(models.Plans, plan) {
+			return invalid(fmt.Sprintf("unknown plan %q; use one of %s", plan, strings.Join(models.Plans, ", ")))
+		}
+		filter.Plans[i] = plan
+	}
+	for i, country := range filter.Countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 {
+			return invalid(fmt.Sprintf("country %q must be an ISO 3166-1 alpha-2 code", country))
+		}
+		filter.Countries[i] = country
+	}
+	var min, max []int
+	if filter.MinAppVersion != "" {
+		parts, ok := models.AppVersionParts(filter.MinAppVersion)
+		if !ok {
+			return invalid("min_app_version must look like 1.2.3")
+		}
+		min = parts
+	}
+	if filter.MaxAppVersion != "" {
+		parts, ok := models.AppVersionParts(filter.MaxAppVersion)
+		if !ok {
+			return invalid("max_app_version must look like 1.2.3")
+		}
+		max = parts
+	}
+	if min != nil && max != nil && slices.Compare(min, max) > 0 {
+		return invalid("min_app_version must not be above max_app_version")
+	}
+	return nil
+}
+
+// Estimate counts the active users the filter currently selects.
+func (s *AudienceService) Estimate(ctx context.Context, filter models.AudienceFilter) (int64, error) {
+	if err := NormalizeFilter(&filter); err != nil {
+		return 0, err
+	}
+	return s.userRepo.CountAudience(ctx, filter)
+}
+
+func (s *AudienceService) List(ctx context.Context) ([]models.Audience, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *AudienceService) Get(ctx context.Context, id bson.ObjectID) (*models.Audience, error) {
+	audience, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if audience == nil {
+		return nil, ErrAudienceNotFound
+	}
+	return audience, nil
+}
+
+func (s *AudienceService) Create(ctx context.Context, name string, filter models.AudienceFilter, createdBy string) (*models.Audience, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalid("name is required")
+	}
+	if err := NormalizeFilter(&filter); err != nil {
+		return nil, err
+	}
+	audience := &models.Audience{Name: name, Filter: filter, CreatedBy: createdBy}
+	if err := s.repo.Create(ctx, audience); err != nil {
+		return nil, err
+	}
+	return audience, nil
+}
+
+func (s *AudienceService) Update(ctx context.Context, id bson.ObjectID, name string, filter models.AudienceFilter) (*models.Audience, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, invalid("name is required")
+	}
+	if err := NormalizeFilter(&filter); err != nil {
+		return nil, err
+	}
+	audience, err := s.repo.Update(ctx, id, name, filter)
+	if err != nil {
+		return nil, err
+	}
+	if audience == nil {
+		return nil, ErrAudienceNotFound
+	}
+	return audience, nil
+}
+
+func (s *AudienceService) Delete(ctx context.Context, id bson.ObjectID) error {
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrAudienceNotFound
+	}
+	return nil
+}
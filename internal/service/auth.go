@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/jwtkeys"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/oidc"
+	"rizon-backend/internal/ratelimit"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/sessionpolicy"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Login link limits.
+const (
+	loginTokenTTL      = 15 * time.Minute
+	loginRequestLimit  = 5 // per email per loginRequestWindow
+	loginRequestWindow = 10 * time.Minute
+	loginCodeAttempts  = 5 // per code, then a new one must be requested
+)
+
+// DefaultAccessTokenTTL is how long an access token works before the app has
+// to use its refresh token.
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+var (
+	ErrRateLimited     = errors.New("too many login requests, please try again later")
+	ErrTokenInvalid    = errors.New("invalid token")
+	ErrTokenExpired    = errors.New("token has expired")
+	ErrTokenSuperseded = errors.New("a newer login link was sent; please use the latest email")
+	ErrTokenUsed       = errors.New("token has already been used")
+	ErrCodeInvalid     = errors.New("invalid code")
+	ErrCodeAttempts    = errors.New("too many incorrect codes; please request a new one")
+
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+
+	ErrGoogleSignInDisabled = errors.New("google sign-in is not enabled")
+	ErrGoogleTokenInvalid   = errors.New("invalid google id token")
+)
+
+// Session is the token pair handed to the app when it signs in or refreshes.
+type Session struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // access token lifetime in seconds
+}
+
+// Client identifies the device a session was started or refreshed from.
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// AuthService owns the login token lifecycle, session tokens and their
+// revocation.
+type AuthService struct {
+	tokens        authTokenStore
+	loginLinks    loginLinkStore
+	consents      consentStore
+	refreshTokens refreshTokenStore
+	revokedTokens revokedTokenStore
+	limiter       rateLimiter
+	sessions      *sessionpolicy.Selector
+	jwtKeys       *jwtkeys.Keyring
+
+	singleActiveLink bool // new links invalidate older unused ones
+	accessTokenTTL   time.Duration
+	codeSecret       []byte // keys the login code hashes
+	clock            clock.Clock
+
+	oidc   *oidc.Client
+	google *oidc.Config // nil disables Google sign-in
+}
+
+func NewAuthService(tokens *repository.AuthTokenRepo, loginLinks *repository.LoginLinkRepo, consents *repository.ConsentRepo, refreshTokens *repository.RefreshTokenRepo, revokedTokens *repository.RevokedTokenRepo, limiter *ratelimit.Limiter, sessions *sessionpolicy.Selector, jwtKeys *jwtkeys.Keyring) *AuthService {
+	return &AuthService{
+		tokens:         tokens,
+		loginLinks:     loginLinks,
+		consents:       consents,
+		refreshTokens:  refreshTokens,
+		revokedTokens:  revokedTokens,
+		limiter:        limiter,
+		sessions:       sessions,
+		jwtKeys:        jwtKeys,
+		accessTokenTTL: DefaultAccessTokenTTL,
+		clock:          clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock used for token lifetimes, e.g. with a
+// clock.Fake. The token repositories take their own.
+func (s *AuthService) WithClock(c clock.Clock) *AuthService {
+	s.clock = c
+	return s
+}
+
+// WithAccessTokenLifetime overrides DefaultAccessTokenTTL. A user's session
+// policy still caps it.
+func (s *AuthService) WithAccessTokenLifetime(d time.Duration) *AuthService {
+	s.accessTokenTTL = d
+	return s
+}
+
+// WithLoginCodeSecret sets the secret login codes are hashed with, so a
+// leaked auth_tokens collection doesn't give the codes away.
+func (s *AuthService) WithLoginCodeSecret(secret string) *AuthService {
+	s.codeSecret = []byte("login-code:" + secret)
+	return s
+}
+
+// WithSingleActiveLink makes each new login link invalidate the earlier
+// unused links for the same email.
+func (s *AuthService) WithSingleActiveLink(on bool) *AuthService {
+	s.singleActiveLink = on
+	return s
+}
+
+// WithGoogleSignIn accepts Google ID tokens issued to any of clientIDs (the
+// web, iOS and Android OAuth clients).
+func (s *AuthService) WithGoogleSignIn(client *oidc.Client, clientIDs []string) *AuthService {
+	if len(clientIDs) == 0 {
+		return s
+	}
+	s.oidc = client
+	s.google = &oidc.Config{Issuer: oidc.GoogleIssuer, ClientID: clientIDs[0], Audiences: clientIDs[1:]}
+	return s
+}
+
+// VerifyGoogleIDToken returns the verified email in a Google ID token. It
+// returns ErrGoogleTokenInvalid when the token can't be used to sign in.
+func (s *AuthService) VerifyGoogleIDToken(ctx context.Context, idToken, nonce string) (string, error) {
+	if s.google == nil {
+		return "", ErrGoogleSignInDisabled
+	}
+	identity, err := s.oidc.VerifyIDToken(ctx, *s.google, idToken, nonce)
+	switch {
+	case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, oidc.ErrNonceMismatch), errors.Is(err, oidc.ErrNoEmail):
+		log.Printf("⚠️  Google sign-in rejected: %v", err)
+		return "", ErrGoogleTokenInvalid
+	case err != nil:
+		return "", fmt.Errorf("verifying google id token: %w", err)
+	}
+	return identity.Email, nil
+}
+
+// CreateLoginToken rate-limits the email and stores a new single-use login
+// token for it. consent is the sign-up consent to record once the token is
+// redeemed, or nil. withCode adds a 6-digit code that can be redeemed with
+// RedeemLoginCode instead of the link.
+func (s *AuthService) CreateLoginToken(ctx context.Context, email string, consent *models.PendingConsent, withCode bool) (*models.AuthToken, error) {
+	if email == "" {
+		return nil, invalid("email is required")
+	}
+
+	// Shared across replicas
+	limit, err := s.limiter.Allow(ctx, "login:email:"+email, loginRequestLimit, loginRequestWindow)
+	if err != nil {
+		return nil, fmt.Errorf("checking rate limit: %w", err)
+	}
+	if !limit.Allowed {
+		return nil, ErrRateLimited
+	}
+
+	var code string
+	if withCode {
+		if code, err = newLoginCode(); err != nil {
+			return nil, fmt.Errorf("generating login code: %w", err)
+		}
+	}
+	authToken, err := s.createToken(ctx, email, consent, code)
+	if err != nil {
+		return nil, err
+	}
+	if s.singleActiveLink {
+		// Older links stop working so only the newest email can sign in
+		if _, err := s.tokens.InvalidateAllForEmail(ctx, email, authToken.CreatedAt); err != nil {
+			log.Printf("Error invalidating previous login tokens: %v", err)
+		}
+	}
+
+	// Click/verify analytics (best-effort)
+	if _, domain, ok := strings.Cut(email, "@"); ok {
+		if err := s.loginLinks.RecordSent(ctx, authToken.Token, strings.ToLower(domain)); err != nil {
+			log.Printf("Error recording login link analytics: %v", err)
+		}
+	}
+	return authToken, nil
+}
+
+// CreateSSOToken stores a login token for a user the organization's identity
+// provider has just authenticated. It is handed straight to the app, so it
+// isn't rate limited or counted as a sent link.
+func (s *AuthService) CreateSSOToken(ctx context.Context, email string) (*models.AuthToken, error) {
+	return s.createToken(ctx, email, nil, "")
+}
+
+func (s *AuthService) createToken(ctx context.Context, email string, consent *models.PendingConsent, code string) (*models.AuthToken, error) {
+	authToken := &models.AuthToken{
+		Email:     email,
+		Token:     uuid.New().String(),
+		ExpiresAt: s.clock.Now().Add(loginTokenTTL),
+		Consent:   consent,
+		Code:      code,
+	}
+	if code != "" {
+		authToken.CodeHash = s.hashCode(authToken.Token, code)
+	}
+	if err := s.tokens.Create(ctx, authToken); err != nil {
+		return nil, fmt.Errorf("creating auth token: %w", err)
+	}
+	return authToken, nil
+}
+
+// RedeemLoginToken checks a login token and marks it used. It returns one of
+// the ErrToken* errors when the token can't be used to sign in. Of several
+// concurrent redemptions only one succeeds.
+func (s *AuthService) RedeemLoginToken(ctx context.Context, token string) (*models.AuthToken, error) {
+	authToken, err := s.tokens.FindByToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("finding token: %w", err)
+	}
+	switch {
+	case authToken == nil:
+		return nil, ErrTokenInvalid
+	case authToken.IsExpired(s.clock.Now()):
+		return nil, ErrTokenExpired
+	case authToken.SupersededAt != nil:
+		return nil, ErrTokenSuperseded
+	case authToken.IsUsed:
+		return nil, ErrTokenUsed
+	}
+
+	authToken, err = s.tokens.Claim(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("marking token as used: %w", err)
+	}
+	if authToken == nil {
+		// Redeemed or expired since it was read
+		return nil, ErrTokenInvalid
+	}
+	if err := s.loginLinks.RecordVerified(ctx, token); err != nil {
+		log.Printf("Error recording login link verification: %v", err)
+	}
+	return authToken, nil
+}
+
+// RedeemLoginCode checks a code typed into the app against the newest code
+// sent to the email and, if it matches, redeems its login token. Only
+// loginCodeAttempts tries are allowed per code. It returns ErrCodeInvalid,
+// ErrCodeAttempts or one of the ErrToken* errors when the code can't be
+// used to sign in.
+func (s *AuthService) RedeemLoginCode(ctx context.Context, email, code string) (*models.AuthToken, error) {
+	authToken, err := s.tokens.FindLatestWithCode(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("finding token: %w", err)
+	}
+	switch {
+	case authToken == nil:
+		return nil, ErrCodeInvalid
+	case authToken.IsExpired(s.clock.Now()):
+		return nil, ErrTokenExpired
+	case authToken.SupersededAt != nil:
+		return nil, ErrTokenSuperseded
+	case authToken.IsUsed:
+		return nil, ErrTokenUsed
+	}
+
+	allowed, err := s.tokens.CountCodeAttempt(ctx, authToken.ID, loginCodeAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("counting code attempt: %w", err)
+	}
+	if !allowed {
+		return nil, ErrCodeAttempts
+	}
+	if !hmac.Equal([]byte(s.hashCode(authToken.Token, code)), []byte(authToken.CodeHash)) {
+		return nil, ErrCodeInvalid
+	}
+	return s.RedeemLoginToken(ctx, authToken.Token)
+}
+
+// hashCode is the stored form of a token's code. The token is mixed in so
+// equal codes on different tokens don't hash alike.
+func (s *AuthService) hashCode(token, code string) string {
+	mac := hmac.New(sha256.New, s.codeSecret)
+	mac.Write([]byte(token + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newLoginCode returns a uniformly random 6-digit code.
+func newLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// RecordSignupConsent stores the consent given on the sign-up screen, if
+// any: the one carried by a redeemed login token, or sent with a Google
+// sign-in.
+func (s *AuthService) RecordSignupConsent(ctx context.Context, user *models.User, pending *models.PendingConsent) error {
+	if pending == nil {
+		return nil
+	}
+	consent := &models.Consent{
+		UserID:         user.ID,
+		TermsVersion:   pending.TermsVersion,
+		PrivacyVersion: pending.PrivacyVersion,
+		MarketingOptIn: pending.MarketingOptIn,
+		IP:             pending.IP,
+		UserAgent:      pending.UserAgent,
+		Source:         models.ConsentSourceSignup,
+		CreatedAt:      pending.GivenAt,
+	}
+	if err := s.consents.Create(ctx, consent); err != nil {
+		return fmt.Errorf("recording sign-up consent: %w", err)
+	}
+	return nil
+}
+
+// JWTKeys reports how often each JWT signing key was used to verify a
+// token on this instance, to tell when a rotated-out secret can be removed.
+func (s *AuthService) JWTKeys() []jwtkeys.KeyStats {
+	return s.jwtKeys.Stats()
+}
+
+// StartSession signs the user in: it issues a short-lived access token and a
+// refresh token that starts a new session family.
+func (s *AuthService) StartSession(ctx context.Context, user *models.User, client Client) (*Session, error) {
+	return s.issueSession(ctx, user, uuid.New().String(), client, sessionpolicy.StatIssued)
+}
+
+// ContinueSession issues the next pair of tokens for a session whose refresh
+// token was just redeemed.
+func (s *AuthService) ContinueSession(ctx context.Context, user *models.User, previous *models.RefreshToken, client Client) (*Session, error) {
+	return s.issueSession(ctx, user, previous.Family, client, sessionpolicy.StatRefreshed)
+}
+
+// issueSession signs an access token and stores a refresh token. The refresh
+// token lives as long as the user's session policy allows, so an app that
+// keeps refreshing stays signed in; the access token is capped at the
+// access token lifetime.
+func (s *AuthService) issueSession(ctx context.Context, user *models.User, family string, client Client, stat string) (*Session, error) {
+	policy := s.sessions.For(user.ID.Hex())
+	now := s.clock.Now()
+	accessTTL := min(s.accessTokenTTL, policy.Lifetime)
+	accessToken, err := s.jwtKeys.Sign(jwt.MapClaims{
+		"user_id": user.ID.Hex(),
+		"email":   user.Email,
+		"pol":     policy.Name,
+		"jti":     uuid.New().String(),
+		"exp":     now.Add(accessTTL).Unix(),
+		"iat":     now.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("signing access token: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generating refresh token: %w", err)
+	}
+	refreshToken := "rzr_" + base64.RawURLEncoding.EncodeToString(buf)
+	if err := s.refreshTokens.Create(ctx, &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refreshToken),
+		Family:    family,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: now.Add(policy.Lifetime),
+	}); err != nil {
+		return nil, fmt.Errorf("storing refresh token: %w", err)
+	}
+
+	s.sessions.Record(policy.Name, stat)
+	return &Session{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTTL / time.Second),
+	}, nil
+}
+
+// RedeemRefreshToken checks a refresh token and uses it up. A token that was
+// already used has leaked or been replayed, so the whole session family is
+// revoked and the user has to sign in again. It returns one of the
+// ErrRefreshToken* errors when the token can't be used.
+func (s *AuthService) RedeemRefreshToken(ctx context.Context, token string) (*models.RefreshToken, error) {
+	refreshToken, err := s.refreshTokens.FindByHash(ctx, hashRefreshToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("finding refresh token: %w", err)
+	}
+	switch {
+	case refreshToken == nil:
+		return nil, ErrRefreshTokenInvalid
+	case refreshToken.RevokedAt != nil:
+		return nil, ErrRefreshTokenRevoked
+	case refreshToken.IsExpired(s.clock.Now()):
+		return nil, ErrRefreshTokenExpired
+	}
+
+	claimed, err := s.refreshTokens.MarkUsed(ctx, refreshToken.ID)
+	if err != nil {
+		return nil, fmt.Errorf("marking refresh token as used: %w", err)
+	}
+	if !claimed {
+		log.Printf("⚠️  Refresh token reused for user %s, revoking its session", refreshToken.UserID.Hex())
+		if _, err := s.refreshTokens.RevokeFamily(ctx, refreshToken.Family); err != nil {
+			return nil, fmt.Errorf("revoking session: %w", err)
+		}
+		return nil, ErrRefreshTokenRevoked
+	}
+	return refreshToken, nil
+}
+
+// RevokeAccessToken blocks an access token until it expires. Tokens issued
+// before access tokens carried a jti can't be revoked and are skipped.
+func (s *AuthService) RevokeAccessToken(ctx context.Context, userID bson.ObjectID, jti string, expiresAt time.Time) error {
+	if jti == "" || s.clock.Now().After(expiresAt) {
+		return nil
+	}
+	return s.revokedTokens.Revoke(ctx, &models.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt})
+}
+
+// RevokeRefreshToken ends the session a refresh token belongs to. Tokens of
+// other users are ignored.
+func (s *AuthService) RevokeRefreshToken(ctx context.Context, userID bson.ObjectID, token string) error {
+	refreshToken, err := s.refreshTokens.FindByHash(ctx, hashRefreshToken(token))
+	if err != nil {
+		return fmt.Errorf("finding refresh token: %w", err)
+	}
+	if refreshToken == nil || refreshToken.UserID != userID {
+		return nil
+	}
+	_, err = s.refreshTokens.RevokeFamily(ctx, refreshToken.Family)
+	return err
+}
+
+// RevokeAllSessions signs the user out on every device. Access tokens
+// already issued stay valid until they expire.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID bson.ObjectID) (int64, error) {
+	return s.refreshTokens.RevokeAllForUser(ctx, userID)
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
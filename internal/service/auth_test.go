@@ -0,0 +1,428 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/jwtkeys"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/sessionpolicy"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type authFixture struct {
+	svc           *AuthService
+	clock         *clock.Fake
+	tokens        *fakeAuthTokens
+	refreshTokens *fakeRefreshTokens
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	clk := clock.NewFake(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	keys, err := jwtkeys.New("test-secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &authFixture{
+		clock:         clk,
+		tokens:        &fakeAuthTokens{clock: clk},
+		refreshTokens: &fakeRefreshTokens{clock: clk},
+	}
+	f.svc = &AuthService{
+		tokens:         f.tokens,
+		loginLinks:     fakeLoginLinks{},
+		consents:       &fakeConsents{},
+		refreshTokens:  f.refreshTokens,
+		revokedTokens:  &fakeRevokedTokens{},
+		limiter:        &fakeLimiter{clock: clk},
+		sessions:       sessionpolicy.NewSelector(30*24*time.Hour, nil, nil, nil),
+		jwtKeys:        keys,
+		accessTokenTTL: DefaultAccessTokenTTL,
+		codeSecret:     []byte("test-code-secret"),
+		clock:          clk,
+	}
+	return f
+}
+
+func TestCreateLoginToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("requires an email", func(t *testing.T) {
+		f := newAuthFixture(t)
+		_, err := f.svc.CreateLoginToken(ctx, "", nil, false)
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("err = %v, want a ValidationError", err)
+		}
+	})
+
+	t.Run("rate limits each email", func(t *testing.T) {
+		f := newAuthFixture(t)
+		for i := 0; i < loginRequestLimit; i++ {
+			if _, err := f.svc.CreateLoginToken(ctx, "a@example.com", nil, false); err != nil {
+				t.Fatalf("request %d: %v", i+1, err)
+			}
+		}
+		if _, err := f.svc.CreateLoginToken(ctx, "a@example.com", nil, false); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("err = %v, want ErrRateLimited", err)
+		}
+		if _, err := f.svc.CreateLoginToken(ctx, "b@example.com", nil, false); err != nil {
+			t.Fatalf("other email: %v", err)
+		}
+
+		f.clock.Advance(loginRequestWindow)
+		if _, err := f.svc.CreateLoginToken(ctx, "a@example.com", nil, false); err != nil {
+			t.Fatalf("after the window: %v", err)
+		}
+	})
+
+	t.Run("adds a code on request", func(t *testing.T) {
+		f := newAuthFixture(t)
+		withCode, err := f.svc.CreateLoginToken(ctx, "a@example.com", nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(`^\d{6}$`).MatchString(withCode.Code) {
+			t.Errorf("code = %q, want 6 digits", withCode.Code)
+		}
+		linkOnly, err := f.svc.CreateLoginToken(ctx, "a@example.com", nil, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if linkOnly.Code != "" {
+			t.Errorf("code = %q, want none", linkOnly.Code)
+		}
+	})
+}
+
+func TestRedeemLoginToken(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		single bool
+		// setup returns the token to redeem
+		setup   func(t *testing.T, f *authFixture) string
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			setup: func(t *testing.T, f *authFixture) string { return createLogin(t, f, false).Token },
+		},
+		{
+			name:    "unknown",
+			setup:   func(t *testing.T, f *authFixture) string { return "no-such-token" },
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "just before expiry",
+			setup: func(t *testing.T, f *authFixture) string {
+				token := createLogin(t, f, false).Token
+				f.clock.Advance(loginTokenTTL)
+				return token
+			},
+		},
+		{
+			name: "expired",
+			setup: func(t *testing.T, f *authFixture) string {
+				token := createLogin(t, f, false).Token
+				f.clock.Advance(loginTokenTTL + time.Second)
+				return token
+			},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name: "already used",
+			setup: func(t *testing.T, f *authFixture) string {
+				token := createLogin(t, f, false).Token
+				if _, err := f.svc.RedeemLoginToken(ctx, token); err != nil {
+					t.Fatal(err)
+				}
+				return token
+			},
+			wantErr: ErrTokenUsed,
+		},
+		{
+			name:   "superseded by a newer link",
+			single: true,
+			setup: func(t *testing.T, f *authFixture) string {
+				token := createLogin(t, f, false).Token
+				f.clock.Advance(time.Minute)
+				createLogin(t, f, false)
+				return token
+			},
+			wantErr: ErrTokenSuperseded,
+		},
+		{
+			name: "older link still works without single active links",
+			setup: func(t *testing.T, f *authFixture) string {
+				token := createLogin(t, f, false).Token
+				f.clock.Advance(time.Minute)
+				createLogin(t, f, false)
+				return token
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			f.svc.WithSingleActiveLink(tt.single)
+			token := tt.setup(t, f)
+
+			got, err := f.svc.RedeemLoginToken(ctx, token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Email != "a@example.com" {
+				t.Errorf("email = %q", got.Email)
+			}
+		})
+	}
+}
+
+// racingTokens lets a concurrent redemption claim each token right after
+// it is read.
+type racingTokens struct {
+	*fakeAuthTokens
+}
+
+func (r racingTokens) FindByToken(ctx context.Context, token string) (*models.AuthToken, error) {
+	found, err := r.fakeAuthTokens.FindByToken(ctx, token)
+	if found != nil {
+		r.fakeAuthTokens.Claim(ctx, token)
+	}
+	return found, err
+}
+
+func TestRedeemLoginTokenRace(t *testing.T) {
+	f := newAuthFixture(t)
+	token := createLogin(t, f, false).Token
+	f.svc.tokens = racingTokens{f.tokens}
+
+	if _, err := f.svc.RedeemLoginToken(context.Background(), token); !errors.Is(err, ErrTokenInvalid) {
+		t.Errorf("err = %v, want ErrTokenInvalid for a token claimed concurrently", err)
+	}
+}
+
+func TestLoginCodeIsStoredHashed(t *testing.T) {
+	f := newAuthFixture(t)
+	token := createLogin(t, f, true)
+
+	stored := f.tokens.tokens[len(f.tokens.tokens)-1]
+	if len(stored.CodeHash) != 64 || stored.CodeHash == token.Code {
+		t.Errorf("code hash = %q for code %q", stored.CodeHash, token.Code)
+	}
+	other := newAuthFixture(t)
+	other.svc.WithLoginCodeSecret("another-secret")
+	if other.svc.hashCode(token.Token, token.Code) == stored.CodeHash {
+		t.Error("code hash doesn't depend on the secret")
+	}
+}
+
+func TestRedeemLoginCode(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, f *authFixture) string // returns the code to enter
+		wantErr error
+	}{
+		{
+			name:  "correct code",
+			setup: func(t *testing.T, f *authFixture) string { return createLogin(t, f, true).Code },
+		},
+		{
+			name: "wrong code",
+			setup: func(t *testing.T, f *authFixture) string {
+				return wrongCode(createLogin(t, f, true).Code)
+			},
+			wantErr: ErrCodeInvalid,
+		},
+		{
+			name: "no code was sent",
+			setup: func(t *testing.T, f *authFixture) string {
+				createLogin(t, f, false)
+				return "123456"
+			},
+			wantErr: ErrCodeInvalid,
+		},
+		{
+			name: "correct code after too many wrong ones",
+			setup: func(t *testing.T, f *authFixture) string {
+				code := createLogin(t, f, true).Code
+				for i := 0; i < loginCodeAttempts; i++ {
+					if _, err := f.svc.RedeemLoginCode(ctx, "a@example.com", wrongCode(code)); !errors.Is(err, ErrCodeInvalid) {
+						t.Fatalf("attempt %d: err = %v", i+1, err)
+					}
+				}
+				return code
+			},
+			wantErr: ErrCodeAttempts,
+		},
+		{
+			name: "expired",
+			setup: func(t *testing.T, f *authFixture) string {
+				code := createLogin(t, f, true).Code
+				f.clock.Advance(loginTokenTTL + time.Second)
+				return code
+			},
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name: "link already used",
+			setup: func(t *testing.T, f *authFixture) string {
+				token := createLogin(t, f, true)
+				if _, err := f.svc.RedeemLoginToken(ctx, token.Token); err != nil {
+					t.Fatal(err)
+				}
+				return token.Code
+			},
+			wantErr: ErrTokenUsed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(t)
+			code := tt.setup(t, f)
+
+			_, err := f.svc.RedeemLoginCode(ctx, "a@example.com", code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenLifecycle(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: bson.NewObjectID(), Email: "a@example.com"}
+
+	t.Run("rotates", func(t *testing.T) {
+		f := newAuthFixture(t)
+		session := startSession(t, f, user)
+		if session.ExpiresIn != int64(DefaultAccessTokenTTL/time.Second) {
+			t.Errorf("expires_in = %d", session.ExpiresIn)
+		}
+
+		previous, err := f.svc.RedeemRefreshToken(ctx, session.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err := f.svc.ContinueSession(ctx, user, previous, Client{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.svc.RedeemRefreshToken(ctx, next.RefreshToken); err != nil {
+			t.Fatalf("next token: %v", err)
+		}
+	})
+
+	t.Run("reuse revokes the session", func(t *testing.T) {
+		f := newAuthFixture(t)
+		session := startSession(t, f, user)
+		previous, err := f.svc.RedeemRefreshToken(ctx, session.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err := f.svc.ContinueSession(ctx, user, previous, Client{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := f.svc.RedeemRefreshToken(ctx, session.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+			t.Fatalf("replayed token: err = %v, want ErrRefreshTokenRevoked", err)
+		}
+		if _, err := f.svc.RedeemRefreshToken(ctx, next.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+			t.Fatalf("rest of the session: err = %v, want ErrRefreshTokenRevoked", err)
+		}
+	})
+
+	t.Run("expires with the session policy", func(t *testing.T) {
+		f := newAuthFixture(t)
+		session := startSession(t, f, user)
+		f.clock.Advance(30*24*time.Hour + time.Second)
+		if _, err := f.svc.RedeemRefreshToken(ctx, session.RefreshToken); !errors.Is(err, ErrRefreshTokenExpired) {
+			t.Fatalf("err = %v, want ErrRefreshTokenExpired", err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		f := newAuthFixture(t)
+		if _, err := f.svc.RedeemRefreshToken(ctx, "rzr_nope"); !errors.Is(err, ErrRefreshTokenInvalid) {
+			t.Fatalf("err = %v, want ErrRefreshTokenInvalid", err)
+		}
+	})
+
+	t.Run("revoke all", func(t *testing.T) {
+		f := newAuthFixture(t)
+		first := startSession(t, f, user)
+		second := startSession(t, f, user)
+		if n, err := f.svc.RevokeAllSessions(ctx, user.ID); err != nil || n != 2 {
+			t.Fatalf("revoked %d, err = %v", n, err)
+		}
+		for _, s := range []*Session{first, second} {
+			if _, err := f.svc.RedeemRefreshToken(ctx, s.RefreshToken); !errors.Is(err, ErrRefreshTokenRevoked) {
+				t.Fatalf("err = %v, want ErrRefreshTokenRevoked", err)
+			}
+		}
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	ctx := context.Background()
+	f := newAuthFixture(t)
+	revoked := &fakeRevokedTokens{}
+	f.svc.revokedTokens = revoked
+	userID := bson.NewObjectID()
+
+	tests := []struct {
+		name      string
+		jti       string
+		expiresAt time.Time
+		stored    bool
+	}{
+		{"live token", "jti-1", f.clock.Now().Add(time.Minute), true},
+		{"already expired", "jti-2", f.clock.Now().Add(-time.Second), false},
+		{"no jti", "", f.clock.Now().Add(time.Minute), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(revoked.revoked)
+			if err := f.svc.RevokeAccessToken(ctx, userID, tt.jti, tt.expiresAt); err != nil {
+				t.Fatal(err)
+			}
+			if stored := len(revoked.revoked) > before; stored != tt.stored {
+				t.Errorf("stored = %v, want %v", stored, tt.stored)
+			}
+		})
+	}
+}
+
+func createLogin(t *testing.T, f *authFixture, withCode bool) *models.AuthToken {
+	t.Helper()
+	token, err := f.svc.CreateLoginToken(context.Background(), "a@example.com", nil, withCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func startSession(t *testing.T, f *authFixture, user *models.User) *Session {
+	t.Helper()
+	session, err := f.svc.StartSession(context.Background(), user, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/ratelimit"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// In-memory stand-ins for the repositories. They return copies, as a
+// decoded document would be, so services can't change what is stored.
+
+type fakeAuthTokens struct {
+	clock  clock.Clock
+	tokens []*models.AuthToken
+}
+
+func (f *fakeAuthTokens) Create(_ context.Context, token *models.AuthToken) error {
+	token.ID = bson.NewObjectID()
+	token.CreatedAt = f.clock.Now()
+	stored := *token
+	f.tokens = append(f.tokens, &stored)
+	return nil
+}
+
+func (f *fakeAuthTokens) FindByToken(_ context.Context, token string) (*models.AuthToken, error) {
+	for _, t := range f.tokens {
+		if t.Token == token {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeAuthTokens) FindLatestWithCode(_ context.Context, email string) (*models.AuthToken, error) {
+	for i := len(f.tokens) - 1; i >= 0; i-- {
+		if t := f.tokens[i]; t.Email == email && t.CodeHash != "" {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeAuthTokens) CountCodeAttempt(_ context.Context, id bson.ObjectID, max int) (bool, error) {
+	for _, t := range f.tokens {
+		if t.ID == id && t.CodeAttempts < max {
+			t.CodeAttempts++
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeAuthTokens) Claim(_ context.Context, token string) (*models.AuthToken, error) {
+	for _, t := range f.tokens {
+		if t.Token == token && !t.IsUsed && !t.IsExpired(f.clock.Now()) {
+			t.IsUsed = true
+			claimed := *t
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeAuthTokens) InvalidateAllForEmail(_ context.Context, email string, before time.Time) (int64, error) {
+	var n int64
+	now := f.clock.Now()
+	for _, t := range f.tokens {
+		if t.Email == email && !t.IsUsed && t.SupersededAt == nil && t.CreatedAt.Before(before) {
+			t.SupersededAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+type fakeLoginLinks struct{}
+
+func (fakeLoginLinks) RecordSent(context.Context, string, string) error { return nil }
+func (fakeLoginLinks) RecordVerified(context.Context, string) error     { return nil }
+
+type fakeConsents struct{ consents []*models.Consent }
+
+func (f *fakeConsents) Create(_ context.Context, consent *models.Consent) error {
+	f.consents = append(f.consents, consent)
+	return nil
+}
+
+type fakeRefreshTokens struct {
+	clock  clock.Clock
+	tokens []*models.RefreshToken
+}
+
+func (f *fakeRefreshTokens) Create(_ context.Context, token *models.RefreshToken) error {
+	token.ID = bson.NewObjectID()
+	stored := *token
+	f.tokens = append(f.tokens, &stored)
+	return nil
+}
+
+func (f *fakeRefreshTokens) FindByHash(_ context.Context, hash string) (*models.RefreshToken, error) {
+	for _, t := range f.tokens {
+		if t.TokenHash == hash {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRefreshTokens) MarkUsed(_ context.Context, id bson.ObjectID) (bool, error) {
+	for _, t := range f.tokens {
+		if t.ID == id && t.UsedAt == nil && t.RevokedAt == nil {
+			now := f.clock.Now()
+			t.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRefreshTokens) RevokeFamily(_ context.Context, family string) (int64, error) {
+	return f.revoke(func(t *models.RefreshToken) bool { return t.Family == family }), nil
+}
+
+func (f *fakeRefreshTokens) RevokeAllForUser(_ context.Context, userID bson.ObjectID) (int64, error) {
+	return f.revoke(func(t *models.RefreshToken) bool { return t.UserID == userID }), nil
+}
+
+func (f *fakeRefreshTokens) revoke(match func(*models.RefreshToken) bool) int64 {
+	var n int64
+	now := f.clock.Now()
+	for _, t := range f.tokens {
+		if match(t) && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			n++
+		}
+	}
+	return n
+}
+
+type fakeRevokedTokens struct{ revoked []*models.RevokedToken }
+
+func (f *fakeRevokedTokens) Revoke(_ context.Context, token *models.RevokedToken) error {
+	f.revoked = append(f.revoked, token)
+	return nil
+}
+
+// fakeLimiter is a fixed-window limiter on the given clock.
+type fakeLimiter struct {
+	clock   clock.Clock
+	mu      sync.Mutex
+	windows map[string]*fakeWindow
+}
+
+type fakeWindow struct {
+	count   int64
+	resetAt time.Time
+}
+
+func (f *fakeLimiter) Allow(_ context.Context, key string, limit int64, window time.Duration) (ratelimit.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.clock.Now()
+	if f.windows == nil {
+		f.windows = map[string]*fakeWindow{}
+	}
+	w := f.windows[key]
+	if w == nil || !now.Before(w.resetAt) {
+		w = &fakeWindow{resetAt: now.Add(window)}
+		f.windows[key] = w
+	}
+	w.count++
+	return ratelimit.Result{
+		Allowed:   w.count <= limit,
+		Limit:     limit,
+		Remaining: max(limit-w.count, 0),
+		ResetAt:   w.resetAt,
+	}, nil
+}
+
+type fakeUsers struct{ users []*models.User }
+
+func (f *fakeUsers) add(user *models.User) *models.User {
+	if user.ID.IsZero() {
+		user.ID = bson.NewObjectID()
+	}
+	f.users = append(f.users, user)
+	return user
+}
+
+func (f *fakeUsers) find(match func(*models.User) bool) *models.User {
+	for _, u := range f.users {
+		if match(u) {
+			found := *u
+			return &found
+		}
+	}
+	return nil
+}
+
+func (f *fakeUsers) FindByID(_ context.Context, id bson.ObjectID) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.ID == id }), nil
+}
+
+func (f *fakeUsers) FindByEmail(_ context.Context, email string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.Email == email }), nil
+}
+
+func (f *fakeUsers) FindOrCreate(ctx context.Context, email string) (*models.User, error) {
+	if user, _ := f.FindByEmail(ctx, email); user != nil {
+		return user, nil
+	}
+	created := *f.add(&models.User{Email: email, Status: models.UserStatusActive})
+	return &created, nil
+}
+
+func (f *fakeUsers) UpdateOnboarding(_ context.Context, id bson.ObjectID, completed bool, answers map[string]interface{}) error {
+	for _, u := range f.users {
+		if u.ID == id {
+			u.OnboardingCompleted = completed
+		}
+	}
+	return nil
+}
+
+func (f *fakeUsers) SetAge(_ context.Context, id bson.ObjectID, band, country string, blocked bool) error {
+	for _, u := range f.users {
+		if u.ID == id {
+			u.AgeBand = band
+		}
+	}
+	return nil
+}
+
+func (f *fakeUsers) SetSignupGeo(context.Context, bson.ObjectID, *models.GeoLocation, string, string) error {
+	return nil
+}
+
+func (f *fakeUsers) SetStatus(_ context.Context, id bson.ObjectID, status, reason string, until *time.Time) (bool, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			u.Status = status
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeUsers) Patch(ctx context.Context, id bson.ObjectID, update bson.M) (*models.User, error) {
+	return f.FindByID(ctx, id)
+}
+
+type fakeFeedback struct {
+	clock    clock.Clock
+	feedback []*models.Feedback
+}
+
+func (f *fakeFeedback) Create(ctx context.Context, feedback *models.Feedback) (*models.Feedback, error) {
+	if existing, _ := f.FindByIdempotencyKey(ctx, feedback.UserID, feedback.IdempotencyKey); existing != nil {
+		return existing, nil
+	}
+	feedback.ID = bson.NewObjectID()
+	feedback.Status = models.FeedbackStatusOpen
+	feedback.CreatedAt = f.clock.Now()
+	stored := *feedback
+	f.feedback = append(f.feedback, &stored)
+	return nil, nil
+}
+
+func (f *fakeFeedback) FindByIdempotencyKey(_ context.Context, userID bson.ObjectID, key string) (*models.Feedback, error) {
+	for _, fb := range f.feedback {
+		if fb.UserID == userID && fb.IdempotencyKey == key {
+			found := *fb
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeFeedback) FindByID(_ context.Context, id bson.ObjectID) (*models.Feedback, error) {
+	for _, fb := range f.feedback {
+		if fb.ID == id {
+			found := *fb
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeFeedback) Edit(_ context.Context, previous, edited *models.Feedback, since time.Time) (bool, error) {
+	for i, fb := range f.feedback {
+		if fb.ID == previous.ID && fb.CreatedAt.After(since) {
+			now := f.clock.Now()
+			edited.EditedAt = &now
+			stored := *edited
+			f.feedback[i] = &stored
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeFeedback) DeleteByAuthor(_ context.Context, id, userID bson.ObjectID, since time.Time) (bool, error) {
+	for i, fb := range f.feedback {
+		if fb.ID == id && fb.UserID == userID && fb.CreatedAt.After(since) {
+			f.feedback = append(f.feedback[:i], f.feedback[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
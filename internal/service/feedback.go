@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/adminfeed"
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+var (
+	ErrFeedbackNotFound = errors.New("feedback not found")
+	ErrFeedbackLocked   = errors.New("feedback can no longer be changed")
+	// The idempotency key is held by feedback the caller doesn't own
+	ErrIdempotencyKeyInUse = errors.New("idempotency_key is already in use")
+)
+
+// FeedbackEditWindow is how long after submitting the author can still edit
+// or delete their feedback, as long as it hasn't been resolved.
+const FeedbackEditWindow = 24 * time.Hour
+
+// FeedbackService validates and stores feedback submissions.
+type FeedbackService struct {
+	feedback feedbackStore
+	feed     *adminfeed.Feed
+	clock    clock.Clock
+}
+
+func NewFeedbackService(feedback *repository.FeedbackRepo) *FeedbackService {
+	return &FeedbackService{feedback: feedback, clock: clock.Real}
+}
+
+// WithClock replaces the wall clock the edit window is measured on, e.g.
+// with a clock.Fake.
+func (s *FeedbackService) WithClock(c clock.Clock) *FeedbackService {
+	s.clock = c
+	return s
+}
+
+// WithAdminFeed adds new feedback to the dashboard's notification center.
+func (s *FeedbackService) WithAdminFeed(feed *adminfeed.Feed) *FeedbackService {
+	s.feed = feed
+	return s
+}
+
+// Submission is a piece of feedback as sent by a client.
+type Submission struct {
+	UserID         bson.ObjectID  // zero for the public web form
+	OrgID          *bson.ObjectID // see OrgService.FeedbackOrg
+	Text           string
+	Rating         int
+	Ratings        map[string]int // optional named ratings
+	Category       string
+	Source         string
+	ContactEmail   string
+	SpamScore      int
+	IdempotencyKey string
+	Client         *models.ClientInfo
+}
+
+// Submit stores the feedback unless the user already submitted one with the
+// same idempotency key, in which case that one is returned with created set
+// to false. Another user's feedback is never returned.
+func (s *FeedbackService) Submit(ctx context.Context, sub Submission) (feedback *models.Feedback, created bool, err error) {
+	if sub.Text == "" {
+		return nil, false, invalid("feedback text is required")
+	}
+	if sub.IdempotencyKey == "" {
+		return nil, false, invalid("idempotency_key is required")
+	}
+	if !ValidFeedbackCategory(sub.Category) {
+		return nil, false, invalid(`category must be "bug", "idea" or "other"`)
+	}
+	if sub.Rating < 0 || sub.Rating > models.MaxRating {
+		return nil, false, invalid("rating must be between 0 and 5")
+	}
+	if err := validateRatings(sub.Ratings); err != nil {
+		return nil, false, err
+	}
+	if sub.Rating == 0 && len(sub.Ratings) > 0 {
+		// Clients that only send named ratings still get an overall one, so
+		// stats, the rating prompt and older readers keep working
+		sub.Rating = overallRating(sub.Ratings)
+	}
+
+	// Prevent duplicate submissions from client retries. Concurrent retries
+	// can both get past this check; Create settles that race.
+	existing, err := s.feedback.FindByIdempotencyKey(ctx, sub.UserID, sub.IdempotencyKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("checking idempotency: %w", err)
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	feedback = &models.Feedback{
+		UserID:         sub.UserID,
+		OrgID:          sub.OrgID,
+		Text:           sub.Text,
+		Rating:         sub.Rating,
+		Ratings:        sub.Ratings,
+		Category:       sub.Category,
+		Source:         sub.Source,
+		ContactEmail:   sub.ContactEmail,
+		SpamScore:      sub.SpamScore,
+		IdempotencyKey: sub.IdempotencyKey,
+		Client:         sub.Client,
+	}
+	existing, err = s.feedback.Create(ctx, feedback)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, false, ErrIdempotencyKeyInUse
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("creating feedback: %w", err)
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	title := fmt.Sprintf("New %s feedback", feedback.Category)
+	if feedback.Rating > 0 {
+		title += fmt.Sprintf(" (%d/%d)", feedback.Rating, models.MaxRating)
+	}
+	s.feed.Notify(ctx, models.AdminNotificationFeedback, title, "", map[string]string{
+		"feedback_id": feedback.ID.Hex(),
+		"source":      feedback.Source,
+	})
+	return feedback, true, nil
+}
+
+// FeedbackChanges are the fields an author edits. Nil fields are kept.
+type FeedbackChanges struct {
+	Text     *string
+	Rating   *int
+	Ratings  map[string]int // replaces all named ratings when non-nil
+	Category *string
+}
+
+// Edit applies the author's changes to their feedback, keeping the previous
+// version in its edit history. It returns the feedback as it was before and
+// after the edit, ErrFeedbackNotFound if it isn't theirs, or
+// ErrFeedbackLocked once the edit window has passed or it was resolved.
+func (s *FeedbackService) Edit(ctx context.Context, id, userID bson.ObjectID, changes FeedbackChanges) (before, after *models.Feedback, err error) {
+	before, err = s.authored(ctx, id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edited := *before
+	if changes.Text != nil {
+		edited.Text = strings.TrimSpace(*changes.Text)
+		if edited.Text == "" {
+			return nil, nil, invalid("feedback text is required")
+		}
+	}
+	if changes.Category != nil {
+		if !ValidFeedbackCategory(*changes.Category) {
+			return nil, nil, invalid(`category must be "bug", "idea" or "other"`)
+		}
+		edited.Category = *changes.Category
+	}
+	if changes.Ratings != nil {
+		if err := validateRatings(changes.Ratings); err != nil {
+			return nil, nil, err
+		}
+		edited.Ratings = changes.Ratings
+	}
+	if changes.Rating != nil {
+		if *changes.Rating < 0 || *changes.Rating > models.MaxRating {
+			return nil, nil, invalid("rating must be between 0 and 5")
+		}
+		edited.Rating = *changes.Rating
+	} else if len(changes.Ratings) > 0 {
+		edited.Rating = overallRating(changes.Ratings)
+	}
+
+	ok, err := s.feedback.Edit(ctx, before, &edited, s.clock.Now().Add(-FeedbackEditWindow))
+	if err != nil {
+		return nil, nil, fmt.Errorf("editing feedback: %w", err)
+	}
+	if !ok {
+		// Raced with another edit, a resolution or the window closing
+		return nil, nil, ErrFeedbackLocked
+	}
+	edited.Edits = append(edited.Edits[:len(edited.Edits):len(edited.Edits)], models.FeedbackEdit{
+		Text:     before.Text,
+		Rating:   before.Rating,
+		Ratings:  before.Ratings,
+		Category: before.Category,
+		EditedAt: *edited.EditedAt,
+	})
+	return before, &edited, nil
+}
+
+// Delete deletes the author's feedback, returning it as it was. Errors are
+// as for Edit.
+func (s *FeedbackService) Delete(ctx context.Context, id, userID bson.ObjectID) (*models.Feedback, error) {
+	feedback, err := s.authored(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.feedback.DeleteByAuthor(ctx, id, userID, s.clock.Now().Add(-FeedbackEditWindow))
+	if err != nil {
+		return nil, fmt.Errorf("deleting feedback: %w", err)
+	}
+	if !ok {
+		return nil, ErrFeedbackLocked
+	}
+	return feedback, nil
+}
+
+// authored loads the user's own feedback, checking it can still be changed.
+func (s *FeedbackService) authored(ctx context.Context, id, userID bson.ObjectID) (*models.Feedback, error) {
+	feedback, err := s.feedback.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("loading feedback: %w", err)
+	}
+	if feedback == nil || feedback.UserID != userID {
+		return nil, ErrFeedbackNotFound
+	}
+	if feedback.Status != models.FeedbackStatusOpen || s.clock.Now().Sub(feedback.CreatedAt) > FeedbackEditWindow {
+		return nil, ErrFeedbackLocked
+	}
+	return feedback, nil
+}
+
+// validateRatings checks that every named rating is known and on the 1–5
+// scale.
+func validateRatings(ratings map[string]int) error {
+	for name, value := range ratings {
+		if !slices.Contains(models.FeedbackRatingNames, name) {
+			return invalid("ratings must be named " + strings.Join(models.FeedbackRatingNames, ", "))
+		}
+		if value < 1 || value > models.MaxRating {
+			return invalid(fmt.Sprintf("ratings.%s must be between 1 and 5", name))
+		}
+	}
+	return nil
+}
+
+// overallRating is the mean of the named ratings, rounded.
+func overallRating(ratings map[string]int) int {
+	sum := 0
+	for _, value := range ratings {
+		sum += value
+	}
+	return int(math.Round(float64(sum) / float64(len(ratings))))
+}
+
+// ValidFeedbackCategory reports whether category is empty or a known category.
+func ValidFeedbackCategory(category string) bool {
+	switch category {
+	case "", models.FeedbackCategoryBug, models.FeedbackCategoryIdea, models.FeedbackCategoryOther:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSubmitValidation(t *testing.T) {
+	valid := Submission{Text: "Love it", IdempotencyKey: "k1", Category: models.FeedbackCategoryIdea, Rating: 4}
+	tests := []struct {
+		name    string
+		change  func(s *Submission)
+		invalid bool
+	}{
+		{"valid", func(s *Submission) {}, false},
+		{"no text", func(s *Submission) { s.Text = "" }, true},
+		{"no idempotency key", func(s *Submission) { s.IdempotencyKey = "" }, true},
+		{"unknown category", func(s *Submission) { s.Category = "rant" }, true},
+		{"no category", func(s *Submission) { s.Category = "" }, false},
+		{"rating too high", func(s *Submission) { s.Rating = models.MaxRating + 1 }, true},
+		{"negative rating", func(s *Submission) { s.Rating = -1 }, true},
+		{"unknown named rating", func(s *Submission) { s.Ratings = map[string]int{"vibes": 3} }, true},
+		{"named rating out of range", func(s *Submission) { s.Ratings = map[string]int{models.RatingDesign: 0} }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &FeedbackService{feedback: &fakeFeedback{clock: clock.Real}, clock: clock.Real}
+			sub := valid
+			tt.change(&sub)
+
+			_, _, err := svc.Submit(context.Background(), sub)
+			var verr *ValidationError
+			if got := errors.As(err, &verr); got != tt.invalid {
+				t.Fatalf("err = %v, want invalid = %v", err, tt.invalid)
+			}
+			if !tt.invalid && err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSubmitDerivesOverallRating(t *testing.T) {
+	svc := &FeedbackService{feedback: &fakeFeedback{clock: clock.Real}, clock: clock.Real}
+	feedback, _, err := svc.Submit(context.Background(), Submission{
+		Text:           "Fast but ugly",
+		IdempotencyKey: "k1",
+		Ratings:        map[string]int{models.RatingPerformance: 5, models.RatingDesign: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feedback.Rating != 4 {
+		t.Errorf("rating = %d, want 4", feedback.Rating)
+	}
+}
+
+func TestSubmitIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeFeedback{clock: clock.Real}
+	svc := &FeedbackService{feedback: store, clock: clock.Real}
+	sub := Submission{Text: "Crashes on launch", IdempotencyKey: "retry-me", Category: models.FeedbackCategoryBug}
+
+	first, created, err := svc.Submit(ctx, sub)
+	if err != nil || !created {
+		t.Fatalf("first submit: created = %v, err = %v", created, err)
+	}
+	again, created, err := svc.Submit(ctx, sub)
+	if err != nil || created {
+		t.Fatalf("retry: created = %v, err = %v", created, err)
+	}
+	if again.ID != first.ID {
+		t.Errorf("retry returned %s, want %s", again.ID.Hex(), first.ID.Hex())
+	}
+	if len(store.feedback) != 1 {
+		t.Errorf("stored %d feedbacks, want 1", len(store.feedback))
+	}
+}
+
+func TestSubmitScopesIdempotencyKeysToTheUser(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeFeedback{clock: clock.Real}
+	svc := &FeedbackService{feedback: store, clock: clock.Real}
+	victim, attacker := bson.NewObjectID(), bson.NewObjectID()
+
+	mine, _, err := svc.Submit(ctx, Submission{UserID: victim, Text: "Private", IdempotencyKey: "shared-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, created, err := svc.Submit(ctx, Submission{UserID: attacker, Text: "Probe", IdempotencyKey: "shared-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !created || theirs.ID == mine.ID || theirs.Text != "Probe" {
+		t.Errorf("another user's key returned %+v (created %v), want their own new feedback", theirs, created)
+	}
+}
+
+func TestEditAndDeleteOwnership(t *testing.T) {
+	ctx := context.Background()
+	author := bson.NewObjectID()
+	text := "Edited"
+	tests := []struct {
+		name    string
+		userID  bson.ObjectID
+		status  string
+		wantErr error
+	}{
+		{"author", author, models.FeedbackStatusOpen, nil},
+		{"someone else", bson.NewObjectID(), models.FeedbackStatusOpen, ErrFeedbackNotFound},
+		{"resolved", author, models.FeedbackStatusResolved, ErrFeedbackLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeFeedback{clock: clock.Real}
+			svc := &FeedbackService{feedback: store, clock: clock.Real}
+			feedback, _, err := svc.Submit(ctx, Submission{UserID: author, Text: "Original", IdempotencyKey: "k1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			store.feedback[0].Status = tt.status
+
+			if _, after, err := svc.Edit(ctx, feedback.ID, tt.userID, FeedbackChanges{Text: &text}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("edit: err = %v, want %v", err, tt.wantErr)
+			} else if err == nil && (after.Text != text || len(after.Edits) != 1) {
+				t.Errorf("edit: text = %q with %d edits", after.Text, len(after.Edits))
+			}
+			if _, err := svc.Delete(ctx, feedback.ID, tt.userID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("delete: err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubmitStampsOrganization(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeFeedback{clock: clock.Real}
+	svc := &FeedbackService{feedback: store, clock: clock.Real}
+	orgID := bson.NewObjectID()
+
+	for _, tt := range []struct {
+		key   string
+		orgID *bson.ObjectID
+	}{{"personal", nil}, {"work", &orgID}} {
+		feedback, _, err := svc.Submit(ctx, Submission{UserID: bson.NewObjectID(), Text: "Hi", IdempotencyKey: tt.key, OrgID: tt.orgID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored, _ := store.FindByID(ctx, feedback.ID); stored.OrgID != tt.orgID {
+			t.Errorf("%s: org = %v, want %v", tt.key, stored.OrgID, tt.orgID)
+		}
+	}
+}
+
+func TestEditWindowCloses(t *testing.T) {
+	ctx := context.Background()
+	now := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	store := &fakeFeedback{clock: now}
+	svc := &FeedbackService{feedback: store, clock: now}
+	author := bson.NewObjectID()
+	text := "Edited"
+
+	feedback, _, err := svc.Submit(ctx, Submission{UserID: author, Text: "Original", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now.Advance(FeedbackEditWindow - time.Second)
+	if _, _, err := svc.Edit(ctx, feedback.ID, author, FeedbackChanges{Text: &text}); err != nil {
+		t.Fatalf("edit just inside the window: %v", err)
+	}
+
+	now.Advance(2 * time.Second)
+	if _, _, err := svc.Edit(ctx, feedback.ID, author, FeedbackChanges{Text: &text}); !errors.Is(err, ErrFeedbackLocked) {
+		t.Errorf("edit after the window: err = %v, want ErrFeedbackLocked", err)
+	}
+	if _, err := svc.Delete(ctx, feedback.ID, author); !errors.Is(err, ErrFeedbackLocked) {
+		t.Errorf("delete after the window: err = %v, want ErrFeedbackLocked", err)
+	}
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/i18n"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/templates"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrOrgNotFound    = errors.New("organization not found")
+	ErrNotOrgOwner    = errors.New("only organization owners can do this")
+	ErrLastOwner      = errors.New("an organization needs at least one owner")
+	ErrInviteNotFound = errors.New("invite not found or expired")
+	ErrMemberNotFound = errors.New("member not found")
+)
+
+// InviteTTL is how long an organization invite stays valid.
+const InviteTTL = 7 * 24 * time.Hour
+
+// OrgService runs organization membership: invites, accepting them, and the
+// owner-only views.
+type OrgService struct {
+	orgs  *repository.OrganizationRepo
+	users *repository.UserRepo
+	mail  *mailer.Mailer
+}
+
+func NewOrgService(orgs *repository.OrganizationRepo, users *repository.UserRepo, mail *mailer.Mailer) *OrgService {
+	return &OrgService{
+		orgs:  orgs,
+		users: users,
+		mail:  mail,
+	}
+}
+
+// Brand returns the organization's branding over the Rizon defaults; a nil
+// organization gets the defaults.
+func Brand(org *models.Organization) templates.Brand {
+	brand := templates.DefaultBrand
+	if org == nil || org.Branding == nil {
+		return brand
+	}
+	if org.Branding.DisplayName != "" {
+		brand.Name = org.Branding.DisplayName
+	}
+	if org.Branding.LogoURL != "" {
+		brand.LogoURL = org.Branding.LogoURL
+	}
+	if org.Branding.PrimaryColor != "" {
+		brand.PrimaryColor = org.Branding.PrimaryColor
+	}
+	return brand
+}
+
+// ForEmail returns the organization owning the email's domain, or nil.
+func (s *OrgService) ForEmail(ctx context.Context, email string) (*models.Organization, error) {
+	return s.orgs.FindByEmail(ctx, email)
+}
+
+// BySlug returns the organization with the slug, or nil.
+func (s *OrgService) BySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	return s.orgs.FindBySlug(ctx, slug)
+}
+
+// Get returns the organization, or ErrOrgNotFound.
+func (s *OrgService) Get(ctx context.Context, id bson.ObjectID) (*models.Organization, error) {
+	org, err := s.orgs.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrgNotFound
+	}
+	return org, nil
+}
+
+// RegionForUser returns the data residency region of the user's
+// organization, or "" if none of their organizations has one.
+func (s *OrgService) RegionForUser(ctx context.Context, userID bson.ObjectID) (string, error) {
+	members, err := s.orgs.ListMemberships(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("loading memberships: %w", err)
+	}
+	for _, m := range members {
+		org, err := s.orgs.FindByID(ctx, m.OrgID)
+		if err != nil {
+			return "", fmt.Errorf("loading organization: %w", err)
+		}
+		if org != nil && org.Region != "" {
+			return org.Region, nil
+		}
+	}
+	return "", nil
+}
+
+// PlanForUser returns models.PlanOrganization for members of any
+// organization and models.PlanIndividual for everyone else.
+func (s *OrgService) PlanForUser(ctx context.Context, userID bson.ObjectID) (string, error) {
+	members, err := s.orgs.ListMemberships(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("loading memberships: %w", err)
+	}
+	if len(members) > 0 {
+		return models.PlanOrganization, nil
+	}
+	return models.PlanIndividual, nil
+}
+
+// Invite invites email to the organization and emails them. Inviting an
+// address again renews the pending invite.
+func (s *OrgService) Invite(ctx context.Context, orgID bson.ObjectID, email, role, invitedBy string) (*models.OrgInvite, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, invalid("a valid email is required")
+	}
+	if role == "" {
+		role = models.OrgRoleMember
+	}
+	if role != models.OrgRoleOwner && role != models.OrgRoleMember {
+		return nil, invalid("role must be owner or member")
+	}
+
+	org, err := s.orgs.FindByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrgNotFound
+	}
+
+	invite := &models.OrgInvite{
+		OrgID:     orgID,
+		Email:     strings.ToLower(addr.Address),
+		Role:      role,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(InviteTTL),
+	}
+	if err := s.orgs.SaveInvite(ctx, invite); err != nil {
+		return nil, fmt.Errorf("saving invite: %w", err)
+	}
+
+	// The invite stands even if the email doesn't go out; it can be resent
+	if err := s.sendInvite(ctx, org, invite); err != nil {
+		log.Printf("Error sending organization invite: %v", err)
+	}
+	return invite, nil
+}
+
+// sendInvite emails the invite, in the invitee's language and email format
+// if they already have an account.
+func (s *OrgService) sendInvite(ctx context.Context, org *models.Organization, invite *models.OrgInvite) error {
+	invitee, err := s.users.FindByEmail(ctx, invite.Email)
+	if err != nil {
+		log.Printf("Error loading invitee for locale: %v", err)
+	}
+	var locale, timeZone string
+	if invitee != nil {
+		locale, timeZone = invitee.Locale, invitee.TimeZone
+	}
+	loc := i18n.For(locale)
+	content, err := templates.RenderEmail("org_invite", templates.EmailOptionsFor(invitee, loc.Locale()), map[string]interface{}{
+		"OrgName":   org.Name,
+		"InvitedBy": invite.InvitedBy,
+		"Email":     invite.Email,
+		"Expires":   loc.Date(i18n.InZone(invite.ExpiresAt, timeZone), "medium"),
+		"Brand":     Brand(org),
+	})
+	if err != nil {
+		return fmt.Errorf("rendering invite: %w", err)
+	}
+	_, err = s.mail.Send(ctx, mailer.FromRendered(invite.Email, content))
+	return err
+}
+
+// PendingInvites returns the invites waiting for the user.
+func (s *OrgService) PendingInvites(ctx context.Context, user *models.User) ([]models.OrgInvite, error) {
+	return s.orgs.PendingInvites(ctx, strings.ToLower(user.Email))
+}
+
+// Accept accepts one of the user's pending invites. Invites are only ever
+// accepted this way, by the user, never just by signing in.
+func (s *OrgService) Accept(ctx context.Context, user *models.User, inviteID bson.ObjectID) (*models.OrgMember, error) {
+	invite, err := s.orgs.AcceptInvite(ctx, inviteID, strings.ToLower(user.Email))
+	if err != nil {
+		return nil, fmt.Errorf("accepting invite: %w", err)
+	}
+	if invite == nil {
+		return nil, ErrInviteNotFound
+	}
+	return s.orgs.AddMember(ctx, &models.OrgMember{OrgID: invite.OrgID, UserID: user.ID, Email: user.Email, Role: invite.Role})
+}
+
+// Membership is an organization the user belongs to, with their role.
+type Membership struct {
+	Org  *models.Organization `json:"organization"`
+	Role string               `json:"role"`
+}
+
+// Memberships lists the organizations the user belongs to.
+func (s *OrgService) Memberships(ctx context.Context, userID bson.ObjectID) ([]Membership, error) {
+	members, err := s.orgs.ListMemberships(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("loading memberships: %w", err)
+	}
+	out := make([]Membership, 0, len(members))
+	for _, m := range members {
+		org, err := s.orgs.FindByID(ctx, m.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("loading organization: %w", err)
+		}
+		if org != nil {
+			out = append(out, Membership{Org: org, Role: m.Role})
+		}
+	}
+	return out, nil
+}
+
+// RequireOwner returns ErrOrgNotFound unless the user is a member of the
+// organization, and ErrNotOrgOwner unless they own it. Non-members can't
+// tell an organization exists.
+func (s *OrgService) RequireOwner(ctx context.Context, orgID, userID bson.ObjectID) error {
+	member, err := s.orgs.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("loading membership: %w", err)
+	}
+	if member == nil || !member.Active() {
+		return ErrOrgNotFound
+	}
+	if member.Role != models.OrgRoleOwner {
+		return ErrNotOrgOwner
+	}
+	return nil
+}
+
+// Members lists the organization's members.
+func (s *OrgService) Members(ctx context.Context, orgID bson.ObjectID) ([]models.OrgMember, error) {
+	return s.orgs.ListMembers(ctx, orgID)
+}
+
+// RemoveMember takes a user out of the organization. The last owner can't
+// be removed.
+func (s *OrgService) RemoveMember(ctx context.Context, orgID, userID bson.ObjectID) error {
+	member, err := s.orgs.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("loading membership: %w", err)
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+	if member.Role == models.OrgRoleOwner {
+		owners, err := s.orgs.CountOwners(ctx, orgID)
+		if err != nil {
+			return fmt.Errorf("counting owners: %w", err)
+		}
+		if owners <= 1 {
+			return ErrLastOwner
+		}
+	}
+	if _, err := s.orgs.RemoveMember(ctx, orgID, userID); err != nil {
+		return fmt.Errorf("removing member: %w", err)
+	}
+	return nil
+}
+
+// FeedbackOrg picks the organization a member's feedback is filed under and
+// shown to the owners of: requested if set, which must be one of theirs, or
+// else their only organization. It returns nil for feedback that belongs to
+// no organization, and ErrOrgNotFound for an organization they aren't in.
+func (s *OrgService) FeedbackOrg(ctx context.Context, userID, requested bson.ObjectID) (*bson.ObjectID, error) {
+	if !requested.IsZero() {
+		member, err := s.orgs.FindMember(ctx, requested, userID)
+		if err != nil {
+			return nil, fmt.Errorf("loading membership: %w", err)
+		}
+		if member == nil || !member.Active() {
+			return nil, ErrOrgNotFound
+		}
+		return &requested, nil
+	}
+	members, err := s.orgs.ListMemberships(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("loading memberships: %w", err)
+	}
+	if len(members) != 1 {
+		return nil, nil
+	}
+	return &members[0].OrgID, nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/slack"
+	"rizon-backend/internal/templates"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OrgUsageService reports organizations' seats and metered API usage per
+// calendar month (UTC). Finished months are saved as reports by the
+// monthly summary, since daily usage records are only kept for 90 days.
+type OrgUsageService struct {
+	orgs     *repository.OrganizationRepo
+	usage    *repository.UsageRepo
+	reports  *repository.OrgUsageRepo
+	notifier slack.Notifier
+}
+
+func NewOrgUsageService(orgs *repository.OrganizationRepo, usage *repository.UsageRepo, reports *repository.OrgUsageRepo, notifier slack.Notifier) *OrgUsageService {
+	return &OrgUsageService{
+		orgs:     orgs,
+		usage:    usage,
+		reports:  reports,
+		notifier: notifier,
+	}
+}
+
+// parseMonth checks a YYYY-MM month; empty means the current one.
+func parseMonth(month string, now time.Time) (time.Time, error) {
+	if month == "" {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, invalid("month must look like 2026-01")
+	}
+	if start.After(now) {
+		return time.Time{}, invalid("month is in the future")
+	}
+	return start, nil
+}
+
+// Month returns the organization's usage for month (YYYY-MM, default the
+// current month): the saved report if there is one, or else computed now.
+func (s *OrgUsageService) Month(ctx context.Context, orgID bson.ObjectID, month string) (*models.OrgUsage, error) {
+	start, err := parseMonth(month, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.orgs.FindByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrgNotFound
+	}
+
+	reports, err := s.reports.ListForOrg(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading reports: %w", err)
+	}
+	for i := range reports {
+		if reports[i].Month == start.Format("2006-01") {
+			return &reports[i], nil
+		}
+	}
+	return s.compute(ctx, org, start)
+}
+
+// Reports lists the organization's saved monthly reports, newest first.
+func (s *OrgUsageService) Reports(ctx context.Context, orgID bson.ObjectID) ([]models.OrgUsage, error) {
+	return s.reports.ListForOrg(ctx, orgID)
+}
+
+// ForMonth lists every organization's saved report for month, for invoicing.
+func (s *OrgUsageService) ForMonth(ctx context.Context, month string) ([]models.OrgUsage, error) {
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return nil, invalid("month must look like 2026-01")
+	}
+	return s.reports.ListForMonth(ctx, month)
+}
+
+func (s *OrgUsageService) compute(ctx context.Context, org *models.Organization, start time.Time) (*models.OrgUsage, error) {
+	members, err := s.orgs.ListMembers(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing members: %w", err)
+	}
+	userIDs := make([]bson.ObjectID, len(members))
+	for i, m := range members {
+		userIDs[i] = m.UserID
+	}
+
+	end := start.AddDate(0, 1, -1)
+	totals, err := s.usage.TotalsForUsers(ctx, userIDs, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("summing usage: %w", err)
+	}
+	return &models.OrgUsage{
+		OrgID:       org.ID,
+		OrgSlug:     org.Slug,
+		Month:       start.Format("2006-01"),
+		Seats:       len(members),
+		UsageTotals: *totals,
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// SummarizeLastMonth saves last month's report for every organization that
+// doesn't have one yet and posts a summary to Slack. Safe to run daily;
+// returns the number of reports made.
+func (s *OrgUsageService) SummarizeLastMonth(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	month := start.Format("2006-01")
+
+	orgs, err := s.orgs.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing organizations: %w", err)
+	}
+	var made []models.OrgUsage
+	for i := range orgs {
+		done, err := s.reports.Exists(ctx, orgs[i].ID, month)
+		if err != nil {
+			return len(made), fmt.Errorf("checking report: %w", err)
+		}
+		if done {
+			continue
+		}
+		report, err := s.compute(ctx, &orgs[i], start)
+		if err != nil {
+			return len(made), err
+		}
+		if err := s.reports.Save(ctx, report); err != nil {
+			return len(made), fmt.Errorf("saving report: %w", err)
+		}
+		made = append(made, *report)
+	}
+
+	if len(made) > 0 {
+		s.publish(ctx, month, made)
+	}
+	return len(made), nil
+}
+
+func (s *OrgUsageService) publish(ctx context.Context, month string, reports []models.OrgUsage) {
+	content, err := templates.Render("org_usage_summary", templates.ChannelSlack, map[string]interface{}{
+		"Month":   month,
+		"Reports": reports,
+	})
+	if err != nil {
+		log.Printf("Error rendering Slack message: %v", err)
+		return
+	}
+	if _, err := s.notifier.Publish(ctx, content.Text); err != nil {
+		log.Printf("Error publishing to Slack: %v", err)
+	}
+}
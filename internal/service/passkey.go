@@ -0,0 +1,229 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/webauthn"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Passkey limits.
+const (
+	maxPasskeysPerUser = 10
+	maxPasskeyName     = 64
+)
+
+var (
+	ErrPasskeyChallenge = errors.New("passkey challenge expired, please start again")
+	ErrPasskeyInvalid   = errors.New("passkey could not be verified")
+	ErrPasskeyUnknown   = errors.New("passkey is not registered")
+	ErrPasskeyExists    = errors.New("passkey is already registered")
+	ErrPasskeyNotFound  = errors.New("passkey not found")
+)
+
+// PasskeyService registers passkeys for signed-in users and signs them in
+// with one later, so returning users don't need a magic link every time.
+// Every account has proven its email, so a passkey is only ever added to a
+// verified address.
+type PasskeyService struct {
+	credentials *repository.CredentialRepo
+	rp          *webauthn.RelyingParty
+}
+
+func NewPasskeyService(credentials *repository.CredentialRepo, rp *webauthn.RelyingParty) *PasskeyService {
+	return &PasskeyService{
+		credentials: credentials,
+		rp:          rp,
+	}
+}
+
+// BeginRegistration starts adding a passkey for user. The options go to the
+// platform's passkey API as they are.
+func (s *PasskeyService) BeginRegistration(ctx context.Context, user *models.User) (*webauthn.CreationOptions, error) {
+	existing, err := s.credentials.ListForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("listing passkeys: %w", err)
+	}
+	if len(existing) >= maxPasskeysPerUser {
+		return nil, invalid(fmt.Sprintf("at most %d passkeys can be registered, remove one first", maxPasskeysPerUser))
+	}
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(existing))
+	for _, cred := range existing {
+		id, err := base64.RawURLEncoding.DecodeString(cred.CredentialID)
+		if err != nil {
+			continue
+		}
+		exclude = append(exclude, webauthn.CredentialDescriptor{Type: "public-key", ID: id, Transports: cred.Transports})
+	}
+
+	challenge, err := s.newChallenge(ctx, models.CredentialChallengeRegister, &user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.rp.CreationOptions(challenge, webauthn.UserEntity{
+		ID:          user.ID[:],
+		Name:        user.Email,
+		DisplayName: user.Email,
+	}, exclude), nil
+}
+
+// FinishRegistration verifies the platform's response to BeginRegistration
+// and saves the passkey under name.
+func (s *PasskeyService) FinishRegistration(ctx context.Context, user *models.User, name string, resp *webauthn.RegistrationResponse) (*models.Credential, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if utf8.RuneCountInString(name) > maxPasskeyName {
+		return nil, invalid(fmt.Sprintf("name must be at most %d characters", maxPasskeyName))
+	}
+
+	challenge, issued, err := s.consumeChallenge(ctx, resp.Response.ClientDataJSON, models.CredentialChallengeRegister)
+	if err != nil {
+		return nil, err
+	}
+	if issued.UserID == nil || *issued.UserID != user.ID {
+		return nil, ErrPasskeyChallenge
+	}
+	verified, err := s.rp.VerifyRegistration(challenge, resp)
+	if err != nil {
+		log.Printf("⚠️  Passkey registration rejected for user %s: %v", user.ID.Hex(), err)
+		return nil, ErrPasskeyInvalid
+	}
+
+	cred := &models.Credential{
+		UserID:       user.ID,
+		CredentialID: verified.ID.String(),
+		PublicKey:    verified.PublicKey,
+		Algorithm:    verified.Algorithm,
+		SignCount:    int64(verified.SignCount),
+		Transports:   resp.Response.Transports,
+		BackedUp:     verified.BackedUp,
+		Name:         name,
+	}
+	if !bytes.Equal(verified.AAGUID, make([]byte, len(verified.AAGUID))) {
+		cred.AAGUID = hex.EncodeToString(verified.AAGUID)
+	}
+	if err := s.credentials.Create(ctx, cred); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrPasskeyExists
+		}
+		return nil, fmt.Errorf("saving passkey: %w", err)
+	}
+	return cred, nil
+}
+
+// BeginLogin starts a passkey sign-in. The user picks any of their passkeys
+// for this app, so no email is needed.
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*webauthn.RequestOptions, error) {
+	challenge, err := s.newChallenge(ctx, models.CredentialChallengeLogin, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.rp.RequestOptions(challenge), nil
+}
+
+// FinishLogin verifies the platform's response to BeginLogin and returns the
+// passkey that signed it. The caller signs its user in.
+func (s *PasskeyService) FinishLogin(ctx context.Context, resp *webauthn.AssertionResponse) (*models.Credential, error) {
+	challenge, _, err := s.consumeChallenge(ctx, resp.Response.ClientDataJSON, models.CredentialChallengeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	rawID := resp.RawID
+	if len(rawID) == 0 {
+		if rawID, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(resp.ID, "=")); err != nil {
+			return nil, ErrPasskeyUnknown
+		}
+	}
+	cred, err := s.credentials.FindByCredentialID(ctx, webauthn.Bytes(rawID).String())
+	if err != nil {
+		return nil, fmt.Errorf("finding passkey: %w", err)
+	}
+	if cred == nil {
+		// Deleted here but still on the device
+		return nil, ErrPasskeyUnknown
+	}
+	if len(resp.Response.UserHandle) > 0 && !bytes.Equal(resp.Response.UserHandle, cred.UserID[:]) {
+		log.Printf("⚠️  Passkey %s answered for another user handle", cred.ID.Hex())
+		return nil, ErrPasskeyInvalid
+	}
+
+	signCount, err := s.rp.VerifyAssertion(challenge, resp, cred.PublicKey, uint32(cred.SignCount))
+	if err != nil {
+		log.Printf("⚠️  Passkey sign-in rejected for user %s: %v", cred.UserID.Hex(), err)
+		return nil, ErrPasskeyInvalid
+	}
+	updated, err := s.credentials.RecordUse(ctx, cred.ID, cred.SignCount, int64(signCount))
+	if err != nil {
+		return nil, fmt.Errorf("recording passkey use: %w", err)
+	}
+	if !updated {
+		// Another sign-in with the same passkey moved the counter first
+		return nil, ErrPasskeyInvalid
+	}
+	return cred, nil
+}
+
+// List returns the user's passkeys.
+func (s *PasskeyService) List(ctx context.Context, userID bson.ObjectID) ([]models.Credential, error) {
+	return s.credentials.ListForUser(ctx, userID)
+}
+
+// Delete removes one of the user's passkeys. It keeps working on the device
+// until the user removes it there, but can no longer sign in.
+func (s *PasskeyService) Delete(ctx context.Context, userID, id bson.ObjectID) error {
+	deleted, err := s.credentials.Delete(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("deleting passkey: %w", err)
+	}
+	if !deleted {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (s *PasskeyService) newChallenge(ctx context.Context, purpose string, userID *bson.ObjectID) (webauthn.Bytes, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, fmt.Errorf("generating passkey challenge: %w", err)
+	}
+	if err := s.credentials.CreateChallenge(ctx, &models.CredentialChallenge{
+		Challenge: challenge.String(),
+		Purpose:   purpose,
+		UserID:    userID,
+	}, webauthn.ChallengeTimeout); err != nil {
+		return nil, fmt.Errorf("storing passkey challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// consumeChallenge uses up the challenge a response answers, so each can be
+// answered once, and returns it with its stored record.
+func (s *PasskeyService) consumeChallenge(ctx context.Context, clientDataJSON []byte, purpose string) (webauthn.Bytes, *models.CredentialChallenge, error) {
+	challenge, err := webauthn.Challenge(clientDataJSON)
+	if err != nil {
+		return nil, nil, ErrPasskeyInvalid
+	}
+	issued, err := s.credentials.ConsumeChallenge(ctx, challenge.String(), purpose)
+	if err != nil {
+		return nil, nil, fmt.Errorf("consuming passkey challenge: %w", err)
+	}
+	if issued == nil {
+		return nil, nil, ErrPasskeyChallenge
+	}
+	return challenge, issued, nil
+}
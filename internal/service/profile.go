@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+	_ "time/tzdata" // the runtime image ships without zoneinfo
+
+	"rizon-backend/internal/mergepatch"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// localePattern is a loose BCP 47 tag: language plus optional subtags.
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// ProfileFields are the members PATCH /user/profile may change. null clears
+// a field.
+var ProfileFields = mergepatch.Schema{
+	"locale":    {Nullable: true, Validate: validLocale},
+	"time_zone": {Nullable: true, Validate: validTimeZone},
+}
+
+// PreferenceFields are the members PATCH /user/preferences may change. null
+// goes back to the default.
+var PreferenceFields = mergepatch.Schema{
+	"product_emails":   {Path: "preferences.product_emails", Nullable: true, Validate: mergepatch.Bool},
+	"feedback_updates": {Path: "preferences.feedback_updates", Nullable: true, Validate: mergepatch.Bool},
+	"email_format":     {Path: "preferences.email_format", Nullable: true, Validate: mergepatch.OneOf(models.EmailFormats...)},
+}
+
+func validLocale(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || len(s) > 35 || !localePattern.MatchString(s) {
+		return nil, errors.New("must be a language tag such as en or pt-BR")
+	}
+	return s, nil
+}
+
+func validTimeZone(v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || s == "" || s == "Local" {
+		return nil, errors.New("must be an IANA time zone such as Europe/Berlin")
+	}
+	if _, err := time.LoadLocation(s); err != nil {
+		return nil, errors.New("must be an IANA time zone such as Europe/Berlin")
+	}
+	return s, nil
+}
+
+// Patch applies a merge patch limited to fields and returns the updated user.
+// A patch outside the schema is a ValidationError.
+func (s *UserService) Patch(ctx context.Context, id bson.ObjectID, fields mergepatch.Schema, patch map[string]interface{}) (*models.User, error) {
+	update, err := fields.Update(patch)
+	var patchErr *mergepatch.Error
+	if errors.As(err, &patchErr) {
+		return nil, invalid(patchErr.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(update) == 0 {
+		return s.Get(ctx, id)
+	}
+
+	user, err := s.users.Patch(ctx, id, update)
+	if err != nil {
+		return nil, fmt.Errorf("patching user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/jsonschema"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+)
+
+var ErrSchemaNotFound = errors.New("schema not found")
+
+// SchemaViolationError is returned when a document doesn't match the schema
+// set for its kind. The violations are safe to show to the client.
+type SchemaViolationError struct {
+	Schema     string
+	Violations []jsonschema.Violation
+}
+
+func (e *SchemaViolationError) Error() string {
+	first := e.Violations[0]
+	path := first.Path
+	if path == "" {
+		path = "document"
+	}
+	return fmt.Sprintf("%s does not match its schema: %s %s", e.Schema, path, first.Message)
+}
+
+const (
+	// Schemas are re-read this often, so edits reach every replica
+	schemaCacheTTL = time.Minute
+	maxSchemaBytes = 64 << 10
+)
+
+var schemaNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+
+// SchemaService manages the admin-set JSON Schemas for free-form documents
+// (onboarding answers and the like) and validates documents against them
+// at ingestion.
+type SchemaService struct {
+	repo *repository.DocumentSchemaRepo
+
+	mu    sync.Mutex
+	cache map[string]cachedSchema
+}
+
+type cachedSchema struct {
+	schema   *jsonschema.Schema // nil when no schema is set
+	loadedAt time.Time
+}
+
+func NewSchemaService(repo *repository.DocumentSchemaRepo) *SchemaService {
+	return &SchemaService{repo: repo, cache: map[string]cachedSchema{}}
+}
+
+// Validate checks doc against the named schema, returning a
+// *SchemaViolationError listing every problem. Kinds without a schema accept
+// any document.
+func (s *SchemaService) Validate(ctx context.Context, name string, doc interface{}) error {
+	schema, err := s.compiled(ctx, name)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return nil
+	}
+	if violations := schema.Validate(doc); len(violations) > 0 {
+		return &SchemaViolationError{Schema: name, Violations: violations}
+	}
+	return nil
+}
+
+func (s *SchemaService) compiled(ctx context.Context, name string) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[name]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < schemaCacheTTL {
+		return cached.schema, nil
+	}
+
+	stored, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s schema: %w", name, err)
+	}
+	var schema *jsonschema.Schema
+	if stored != nil {
+		// Saved schemas compiled when they were put; a failure here means
+		// the document was edited by hand
+		if schema, err = jsonschema.Compile([]byte(stored.Schema)); err != nil {
+			return nil, fmt.Errorf("compiling %s schema: %w", name, err)
+		}
+	}
+	s.remember(name, schema)
+	return schema, nil
+}
+
+func (s *SchemaService) remember(name string, schema *jsonschema.Schema) {
+	s.mu.Lock()
+	s.cache[name] = cachedSchema{schema: schema, loadedAt: time.Now()}
+	s.mu.Unlock()
+}
+
+func (s *SchemaService) List(ctx context.Context) ([]models.DocumentSchema, error) {
+	return s.repo.List(ctx)
+}
+
+// Get returns the named schema or ErrSchemaNotFound.
+func (s *SchemaService) Get(ctx context.Context, name string) (*models.DocumentSchema, error) {
+	stored, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("loading schema: %w", err)
+	}
+	if stored == nil {
+		return nil, ErrSchemaNotFound
+	}
+	return stored, nil
+}
+
+// Put compiles and saves a schema. Documents already stored aren't
+// re-checked; the schema applies from the next ingestion.
+func (s *SchemaService) Put(ctx context.Context, name string, raw json.RawMessage, updatedBy string) (*models.DocumentSchema, error) {
+	if !schemaNamePattern.MatchString(name) {
+		return nil, invalid("schema names are lowercase letters, digits and _.:- (at most 64)")
+	}
+	if len(raw) > maxSchemaBytes {
+		return nil, invalid(fmt.Sprintf("schema is over %d KB", maxSchemaBytes>>10))
+	}
+	schema, err := jsonschema.Compile(raw)
+	if err != nil {
+		return nil, invalid(err.Error())
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return nil, invalid("schema is not valid JSON")
+	}
+
+	saved, err := s.repo.Put(ctx, name, compact.String(), updatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("saving schema: %w", err)
+	}
+	s.remember(name, schema)
+	return saved, nil
+}
+
+// Delete removes a schema; its documents are accepted unchecked from then on.
+func (s *SchemaService) Delete(ctx context.Context, name string) error {
+	deleted, err := s.repo.Delete(ctx, name)
+	if err != nil {
+		return fmt.Errorf("deleting schema: %w", err)
+	}
+	if !deleted {
+		return ErrSchemaNotFound
+	}
+	s.remember(name, nil)
+	return nil
+}
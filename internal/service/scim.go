@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var ErrAlreadyMember = errors.New("user is already provisioned in this organization")
+
+// SCIMUser is a provisioned organization member with their account.
+type SCIMUser struct {
+	Member *models.OrgMember
+	User   *models.User
+}
+
+// Active reports whether the user can sign in as a member. Deprovisioned
+// and banned users, and deactivated memberships, are inactive.
+func (u *SCIMUser) Active() bool {
+	return u.Member.Active() && u.User.Status != models.UserStatusMerged && !u.User.IsRestricted(time.Now())
+}
+
+// SCIMService provisions and deprovisions an organization's users for its
+// identity provider. Deactivating a user on one of the organization's
+// domains suspends the account, so their sessions stop working, and
+// reactivating only lifts that suspension. Members with other emails, such
+// as those linked to an SSO subject, have accounts the organization doesn't
+// own: for them only the membership is deactivated.
+type SCIMService struct {
+	orgs  *repository.OrganizationRepo
+	users *UserService
+}
+
+func NewSCIMService(orgs *repository.OrganizationRepo, users *UserService) *SCIMService {
+	return &SCIMService{
+		orgs:  orgs,
+		users: users,
+	}
+}
+
+// List returns the organization's users, optionally only the one with
+// email. startIndex is 1-based, as in SCIM.
+func (s *SCIMService) List(ctx context.Context, orgID bson.ObjectID, email string, startIndex, count int) ([]SCIMUser, int, error) {
+	members, err := s.orgs.ListMembers(ctx, orgID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("listing members: %w", err)
+	}
+	if email != "" {
+		matched := members[:0]
+		for _, m := range members {
+			if strings.EqualFold(m.Email, email) {
+				matched = append(matched, m)
+			}
+		}
+		members = matched
+	}
+
+	total := len(members)
+	start := min(max(startIndex-1, 0), total)
+	end := min(start+max(count, 0), total)
+
+	out := make([]SCIMUser, 0, end-start)
+	for i := start; i < end; i++ {
+		u, err := s.load(ctx, &members[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		if u != nil {
+			out = append(out, *u)
+		}
+	}
+	return out, total, nil
+}
+
+// Get returns one of the organization's users.
+func (s *SCIMService) Get(ctx context.Context, orgID, userID bson.ObjectID) (*SCIMUser, error) {
+	member, err := s.orgs.FindMember(ctx, orgID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("loading membership: %w", err)
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+	u, err := s.load(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrMemberNotFound
+	}
+	return u, nil
+}
+
+// Create provisions a user into the organization, creating their account if
+// needed. The email must be on one of the organization's domains.
+func (s *SCIMService) Create(ctx context.Context, orgID bson.ObjectID, email, externalID string, active bool) (*SCIMUser, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return nil, invalid("userName must be an email address")
+	}
+	email = strings.ToLower(addr.Address)
+
+	org, err := s.orgs.FindByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrgNotFound
+	}
+	if !ownsDomain(org, email) {
+		return nil, invalid("userName must be on one of the organization's domains")
+	}
+
+	user, err := s.users.Provision(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.orgs.FindMember(ctx, orgID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loading membership: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrAlreadyMember
+	}
+	if _, err := s.orgs.AddMember(ctx, &models.OrgMember{OrgID: orgID, UserID: user.ID, Email: user.Email, Role: models.OrgRoleMember, ExternalID: externalID}); err != nil {
+		return nil, fmt.Errorf("adding member: %w", err)
+	}
+	return s.SetActive(ctx, orgID, user.ID, active)
+}
+
+// SetActive deactivates or reactivates one of the organization's users.
+func (s *SCIMService) SetActive(ctx context.Context, orgID, userID bson.ObjectID, active bool) (*SCIMUser, error) {
+	u, err := s.Get(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	owned, err := s.ownsAccount(ctx, orgID, u.User)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		if active == u.Member.Active() {
+			return u, nil
+		}
+		if _, err := s.orgs.SetMemberDeactivated(ctx, orgID, userID, !active); err != nil {
+			return nil, fmt.Errorf("updating membership: %w", err)
+		}
+		return s.Get(ctx, orgID, userID)
+	}
+	switch status := u.User.Status; {
+	case !active && (status == "" || status == models.UserStatusActive):
+		if _, err := s.users.users.SetStatus(ctx, userID, models.UserStatusSuspended, models.BanReasonDeprovisioned, nil); err != nil {
+			return nil, fmt.Errorf("deactivating user: %w", err)
+		}
+	case active && status == models.UserStatusSuspended && u.User.BanReason == models.BanReasonDeprovisioned:
+		// Suspensions and bans by Rizon admins stay in place
+		if _, err := s.users.users.SetStatus(ctx, userID, models.UserStatusActive, "", nil); err != nil {
+			return nil, fmt.Errorf("reactivating user: %w", err)
+		}
+	default:
+		return u, nil
+	}
+	return s.Get(ctx, orgID, userID)
+}
+
+// Delete removes the user from the organization, deactivating their
+// account first if it's on one of the organization's domains. The account
+// itself is kept, with its feedback.
+func (s *SCIMService) Delete(ctx context.Context, orgID, userID bson.ObjectID) error {
+	u, err := s.Get(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	owned, err := s.ownsAccount(ctx, orgID, u.User)
+	if err != nil {
+		return err
+	}
+	if owned {
+		if _, err := s.SetActive(ctx, orgID, userID, false); err != nil {
+			return err
+		}
+	}
+	if _, err := s.orgs.RemoveMember(ctx, orgID, userID); err != nil {
+		return fmt.Errorf("removing member: %w", err)
+	}
+	return nil
+}
+
+// ownsAccount reports whether the user's email is on one of the
+// organization's domains, making the account the organization's to suspend.
+func (s *SCIMService) ownsAccount(ctx context.Context, orgID bson.ObjectID, user *models.User) (bool, error) {
+	org, err := s.orgs.FindByID(ctx, orgID)
+	if err != nil {
+		return false, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil {
+		return false, ErrOrgNotFound
+	}
+	return ownsDomain(org, user.Email), nil
+}
+
+func (s *SCIMService) load(ctx context.Context, member *models.OrgMember) (*SCIMUser, error) {
+	user, err := s.users.users.FindByID(ctx, member.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("loading user: %w", err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+	return &SCIMUser{Member: member, User: user}, nil
+}
@@ -0,0 +1,14 @@
+// Package service holds the business rules shared by the HTTP handlers and
+// any other transport (CLI, gRPC). Services work with repositories and plain
+// values; they never see an http.Request.
+package service
+
+// ValidationError is returned when the caller's input is rejected. Its
+// message is safe to show to the client.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+func invalid(msg string) error { return &ValidationError{Message: msg} }
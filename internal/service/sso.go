@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/oidc"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/signedurl"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ssoStateTTL is how long a user has to sign in at the identity provider.
+const ssoStateTTL = 10 * time.Minute
+
+var (
+	ErrSSONotConfigured = errors.New("single sign-on is not set up for this organization")
+	ErrSSOState         = errors.New("sign-in session expired, please start again")
+	ErrSSOForeignEmail  = errors.New("this account's email isn't part of the organization")
+	ErrSSOSubjectTaken  = errors.New("another member is already linked to this identity")
+)
+
+// SSOService signs organization members in through their organization's
+// OpenID Connect identity provider, creating accounts on first sign-in.
+type SSOService struct {
+	orgs   *repository.OrganizationRepo
+	users  *UserService
+	auth   *AuthService
+	oidc   *oidc.Client
+	signer *signedurl.Signer
+}
+
+func NewSSOService(orgs *repository.OrganizationRepo, users *UserService, auth *AuthService, client *oidc.Client, signer *signedurl.Signer) *SSOService {
+	return &SSOService{
+		orgs:   orgs,
+		users:  users,
+		auth:   auth,
+		oidc:   client,
+		signer: signer,
+	}
+}
+
+// Configure validates and saves an organization's identity provider. The
+// issuer's discovery document must be reachable.
+func (s *SSOService) Configure(ctx context.Context, id bson.ObjectID, sso *models.SSOConfig) (*models.Organization, error) {
+	sso.Issuer = strings.TrimSuffix(strings.TrimSpace(sso.Issuer), "/")
+	sso.ClientID = strings.TrimSpace(sso.ClientID)
+	if !strings.HasPrefix(sso.Issuer, "https://") {
+		return nil, invalid("issuer must be an https URL")
+	}
+	if sso.ClientID == "" || sso.ClientSecret == "" {
+		return nil, invalid("client_id and client_secret are required")
+	}
+	if err := s.oidc.Discover(ctx, sso.Issuer); err != nil {
+		return nil, invalid(fmt.Sprintf("could not load the issuer's OpenID configuration: %v", err))
+	}
+	sso.UpdatedAt = time.Now()
+
+	org, err := s.orgs.SetSSO(ctx, id, sso)
+	if err != nil {
+		return nil, fmt.Errorf("saving sso config: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrgNotFound
+	}
+	return org, nil
+}
+
+// Remove turns single sign-on off for the organization.
+func (s *SSOService) Remove(ctx context.Context, id bson.ObjectID) error {
+	org, err := s.orgs.SetSSO(ctx, id, nil)
+	if err != nil {
+		return fmt.Errorf("removing sso config: %w", err)
+	}
+	if org == nil {
+		return ErrOrgNotFound
+	}
+	return nil
+}
+
+// Start returns the identity provider URL to send the browser to, and the
+// state to bind to the browser and hand back to Finish.
+func (s *SSOService) Start(ctx context.Context, slug, redirectURI string) (authURL, state string, err error) {
+	org, cfg, err := s.config(ctx, slug)
+	if err != nil {
+		return "", "", err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("generating nonce: %w", err)
+	}
+	nonce := hex.EncodeToString(b)
+
+	if state, err = s.signer.SignOnce(ssoStatePath(org.Slug, nonce), ssoStateTTL); err != nil {
+		return "", "", fmt.Errorf("signing state: %w", err)
+	}
+	authURL, err = s.oidc.AuthURL(ctx, cfg, redirectURI, state, nonce)
+	if err != nil {
+		return "", "", fmt.Errorf("building sign-in URL: %w", err)
+	}
+	return authURL, state, nil
+}
+
+// Finish completes the sign-in: it checks state, redeems the code, creates
+// the user and their membership if needed, and returns a login token for the
+// app. Returns the organization for branding the redirect page.
+func (s *SSOService) Finish(ctx context.Context, slug, state, code, redirectURI string) (*models.AuthToken, *models.Organization, error) {
+	claims, err := s.signer.Verify(ctx, state)
+	if err != nil {
+		return nil, nil, ErrSSOState
+	}
+	stateSlug, nonce, _ := strings.Cut(strings.TrimPrefix(claims.Path, "sso/"), "/")
+	if stateSlug != slug || nonce == "" {
+		return nil, nil, ErrSSOState
+	}
+
+	org, cfg, err := s.config(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+	identity, err := s.oidc.Exchange(ctx, cfg, redirectURI, code, nonce)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc exchange: %w", err)
+	}
+
+	user, err := s.identityUser(ctx, org, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authToken, err := s.auth.CreateSSOToken(ctx, user.Email)
+	if err != nil {
+		return nil, nil, err
+	}
+	return authToken, org, nil
+}
+
+// identityUser returns the account the organization's IdP may sign in as.
+// The IdP only vouches for emails on the organization's own domains, whose
+// accounts are provisioned just in time, and for members an admin linked to
+// the identity's subject. An email from elsewhere matching a member's is not
+// enough: the IdP could assert any address.
+func (s *SSOService) identityUser(ctx context.Context, org *models.Organization, identity *oidc.Identity) (*models.User, error) {
+	if ownsDomain(org, identity.Email) {
+		user, err := s.users.Provision(ctx, identity.Email)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.orgs.AddMember(ctx, &models.OrgMember{OrgID: org.ID, UserID: user.ID, Email: user.Email, Role: models.OrgRoleMember}); err != nil {
+			return nil, fmt.Errorf("adding member: %w", err)
+		}
+		return user, nil
+	}
+
+	if identity.Subject == "" {
+		return nil, ErrSSOForeignEmail
+	}
+	member, err := s.orgs.FindMemberBySSOSubject(ctx, org.ID, identity.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("loading membership: %w", err)
+	}
+	if member == nil || !member.Active() {
+		return nil, ErrSSOForeignEmail
+	}
+	user, err := s.users.Get(ctx, member.UserID)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrSSOForeignEmail
+	}
+	return user, err
+}
+
+// LinkSubject lets a member whose email is outside the organization's
+// domains sign in through its identity provider as subject. An empty subject
+// removes the link. It returns ErrMemberNotFound for non-members and
+// ErrSSOSubjectTaken if another member is linked to subject.
+func (s *SSOService) LinkSubject(ctx context.Context, orgID, userID bson.ObjectID, subject string) error {
+	ok, err := s.orgs.SetMemberSSOSubject(ctx, orgID, userID, strings.TrimSpace(subject))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSSOSubjectTaken
+	}
+	if err != nil {
+		return fmt.Errorf("linking sso subject: %w", err)
+	}
+	if !ok {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// Required returns the organization whose enforced SSO the email must sign
+// in through, or nil if magic links are allowed: either the email's domain
+// belongs to such an organization or the user is a member of one.
+func (s *SSOService) Required(ctx context.Context, email string) (*models.Organization, error) {
+	org, err := s.orgs.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if enforced(org) {
+		return org, nil
+	}
+
+	user, err := s.users.users.FindByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	members, err := s.orgs.ListMemberships(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		org, err := s.orgs.FindByID(ctx, m.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		if enforced(org) {
+			return org, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *SSOService) config(ctx context.Context, slug string) (*models.Organization, oidc.Config, error) {
+	org, err := s.orgs.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, oidc.Config{}, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil || org.SSO == nil {
+		return nil, oidc.Config{}, ErrSSONotConfigured
+	}
+	secret, err := s.orgs.SSOSecret(ctx, org)
+	if err != nil {
+		return nil, oidc.Config{}, fmt.Errorf("decrypting client secret: %w", err)
+	}
+	return org, oidc.Config{Issuer: org.SSO.Issuer, ClientID: org.SSO.ClientID, ClientSecret: secret}, nil
+}
+
+func ssoStatePath(slug, nonce string) string {
+	return "sso/" + slug + "/" + nonce
+}
+
+func enforced(org *models.Organization) bool {
+	return org != nil && org.SSO != nil && org.SSO.Enforced
+}
+
+func ownsDomain(org *models.Organization, email string) bool {
+	_, domain, _ := strings.Cut(email, "@")
+	return slices.Contains(org.Domains, strings.ToLower(domain))
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/ratelimit"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// The storage the services depend on, narrowed to the methods they call so
+// tests can swap in fakes. The repository package implements them against
+// MongoDB.
+
+type authTokenStore interface {
+	Create(ctx context.Context, token *models.AuthToken) error
+	FindByToken(ctx context.Context, token string) (*models.AuthToken, error)
+	FindLatestWithCode(ctx context.Context, email string) (*models.AuthToken, error)
+	CountCodeAttempt(ctx context.Context, id bson.ObjectID, max int) (bool, error)
+	Claim(ctx context.Context, token string) (*models.AuthToken, error)
+	InvalidateAllForEmail(ctx context.Context, email string, before time.Time) (int64, error)
+}
+
+type loginLinkStore interface {
+	RecordSent(ctx context.Context, token, emailDomain string) error
+	RecordVerified(ctx context.Context, token string) error
+}
+
+type consentStore interface {
+	Create(ctx context.Context, consent *models.Consent) error
+}
+
+type refreshTokenStore interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	MarkUsed(ctx context.Context, id bson.ObjectID) (bool, error)
+	RevokeFamily(ctx context.Context, family string) (int64, error)
+	RevokeAllForUser(ctx context.Context, userID bson.ObjectID) (int64, error)
+}
+
+type revokedTokenStore interface {
+	Revoke(ctx context.Context, token *models.RevokedToken) error
+}
+
+type rateLimiter interface {
+	Allow(ctx context.Context, key string, limit int64, window time.Duration) (ratelimit.Result, error)
+}
+
+type userStore interface {
+	FindByID(ctx context.Context, id bson.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindOrCreate(ctx context.Context, email string) (*models.User, error)
+	UpdateOnboarding(ctx context.Context, id bson.ObjectID, completed bool, answers map[string]interface{}) error
+	SetAge(ctx context.Context, id bson.ObjectID, band, country string, blocked bool) error
+	SetSignupGeo(ctx context.Context, id bson.ObjectID, loc *models.GeoLocation, locale, timeZone string) error
+	SetStatus(ctx context.Context, id bson.ObjectID, status, reason string, until *time.Time) (bool, error)
+	Patch(ctx context.Context, id bson.ObjectID, update bson.M) (*models.User, error)
+}
+
+type feedbackStore interface {
+	Create(ctx context.Context, feedback *models.Feedback) (existing *models.Feedback, err error)
+	FindByIdempotencyKey(ctx context.Context, userID bson.ObjectID, key string) (*models.Feedback, error)
+	FindByID(ctx context.Context, id bson.ObjectID) (*models.Feedback, error)
+	Edit(ctx context.Context, previous, edited *models.Feedback, since time.Time) (bool, error)
+	DeleteByAuthor(ctx context.Context, id, userID bson.ObjectID, since time.Time) (bool, error)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/agegate"
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrAgeRequired  = errors.New("age must be provided before completing onboarding")
+)
+
+// UserService provisions accounts and runs the onboarding and age-gate rules.
+type UserService struct {
+	users       userStore
+	ageRules    agegate.Rules
+	ageRequired bool // onboarding can't complete without an age
+	schemas     *SchemaService
+	reminders   *repository.OnboardingReminderRepo
+	clock       clock.Clock
+}
+
+func NewUserService(users *repository.UserRepo, ageRules agegate.Rules, ageRequired bool) *UserService {
+	return &UserService{
+		users:       users,
+		ageRules:    ageRules,
+		ageRequired: ageRequired,
+		clock:       clock.Real,
+	}
+}
+
+// WithClock replaces the wall clock ages are worked out on, e.g. with a
+// clock.Fake.
+func (s *UserService) WithClock(c clock.Clock) *UserService {
+	s.clock = c
+	return s
+}
+
+// WithSchemas validates onboarding answers against the admin-set
+// "onboarding_answers" schema.
+func (s *UserService) WithSchemas(schemas *SchemaService) *UserService {
+	s.schemas = schemas
+	return s
+}
+
+// WithOnboardingReminders credits the last onboarding reminder a user got
+// when they complete onboarding.
+func (s *UserService) WithOnboardingReminders(reminders *repository.OnboardingReminderRepo) *UserService {
+	s.reminders = reminders
+	return s
+}
+
+// Get returns the user or ErrUserNotFound.
+func (s *UserService) Get(ctx context.Context, id bson.ObjectID) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("finding user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// FindByEmail returns the user with the email, or nil if there is none.
+func (s *UserService) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("finding user: %w", err)
+	}
+	return user, nil
+}
+
+// Provision finds or creates the account for a verified email. Signing in
+// with a merged account's email lands in the account it was merged into.
+func (s *UserService) Provision(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.users.FindOrCreate(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("finding/creating user: %w", err)
+	}
+
+	if user.Status == models.UserStatusMerged && user.MergedInto != nil {
+		target, err := s.users.FindByID(ctx, *user.MergedInto)
+		if err != nil {
+			return nil, fmt.Errorf("loading merge target: %w", err)
+		}
+		if target != nil {
+			user = target
+		}
+	}
+	return user, nil
+}
+
+// SetSignupGeo stores where a new user signed up from, with the locale and
+// time zone defaults for that place.
+func (s *UserService) SetSignupGeo(ctx context.Context, user *models.User, loc models.GeoLocation, locale, timeZone string) error {
+	if user.SignupGeo != nil {
+		return nil
+	}
+	return s.users.SetSignupGeo(ctx, user.ID, &loc, locale, timeZone)
+}
+
+// AgeRequired reports whether the user still has to provide an age.
+func (s *UserService) AgeRequired(user *models.User) bool {
+	return s.ageRequired && user.AgeBand == ""
+}
+
+// CompleteOnboarding marks onboarding done and saves the answers, if any,
+// returning ErrAgeRequired while the age gate is on and the user hasn't given
+// an age, or a *SchemaViolationError when the answers don't fit the schema.
+func (s *UserService) CompleteOnboarding(ctx context.Context, id bson.ObjectID, answers map[string]interface{}) error {
+	if answers != nil && s.schemas != nil {
+		if err := s.schemas.Validate(ctx, models.SchemaOnboardingAnswers, answers); err != nil {
+			return err
+		}
+	}
+	if s.ageRequired {
+		user, err := s.users.FindByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("finding user: %w", err)
+		}
+		if user == nil || user.AgeBand == "" {
+			return ErrAgeRequired
+		}
+	}
+
+	if err := s.users.UpdateOnboarding(ctx, id, true, answers); err != nil {
+		return fmt.Errorf("updating onboarding: %w", err)
+	}
+	if s.reminders != nil {
+		// Only analytics depend on it
+		if err := s.reminders.MarkConverted(ctx, id, s.clock.Now()); err != nil {
+			log.Printf("Error recording onboarding reminder conversion: %v", err)
+		}
+	}
+	return nil
+}
+
+// AgeInput is either a date of birth or a self-reported band, plus the country
+// whose minimum age applies.
+type AgeInput struct {
+	DateOfBirth string // YYYY-MM-DD; used to derive the band, never stored
+	AgeBand     string
+	Country     string // ISO 3166-1 alpha-2
+}
+
+// AgeResult is the stored band and whether it clears the country's minimum.
+type AgeResult struct {
+	Band       string
+	Allowed    bool
+	MinimumAge int
+}
+
+// SetAge records the user's age band. Under-age users are saved as blocked;
+// the result says so rather than returning an error.
+func (s *UserService) SetAge(ctx context.Context, id bson.ObjectID, in AgeInput) (*AgeResult, error) {
+	country := strings.ToUpper(strings.TrimSpace(in.Country))
+	if len(country) != 2 {
+		return nil, invalid("country must be a two-letter code")
+	}
+
+	result := &AgeResult{MinimumAge: s.ageRules.MinimumAge(country)}
+	switch {
+	case in.DateOfBirth != "":
+		dob, err := time.Parse("2006-01-02", in.DateOfBirth)
+		now := s.clock.Now().UTC()
+		if err != nil || dob.After(now) || agegate.AgeOn(dob, now) > 120 {
+			return nil, invalid("invalid date_of_birth")
+		}
+		age := agegate.AgeOn(dob, now)
+		result.Band = agegate.BandFor(age)
+		result.Allowed = age >= result.MinimumAge
+	case agegate.ValidBand(in.AgeBand):
+		result.Band = in.AgeBand
+		result.Allowed = s.ageRules.Allowed(in.AgeBand, country)
+	default:
+		return nil, invalid("date_of_birth or a valid age_band is required")
+	}
+
+	if err := s.users.SetAge(ctx, id, result.Band, country, !result.Allowed); err != nil {
+		return nil, fmt.Errorf("saving age: %w", err)
+	}
+	return result, nil
+}
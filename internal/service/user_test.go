@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rizon-backend/internal/agegate"
+	"rizon-backend/internal/clock"
+	"rizon-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestProvision(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		setup func(users *fakeUsers)
+		email string
+		want  string // email of the account signed into
+	}{
+		{
+			name:  "creates a new account",
+			setup: func(users *fakeUsers) {},
+			email: "new@example.com",
+			want:  "new@example.com",
+		},
+		{
+			name: "finds an existing account",
+			setup: func(users *fakeUsers) {
+				users.add(&models.User{Email: "old@example.com", Status: models.UserStatusActive})
+			},
+			email: "old@example.com",
+			want:  "old@example.com",
+		},
+		{
+			name: "follows a merge",
+			setup: func(users *fakeUsers) {
+				target := users.add(&models.User{Email: "main@example.com", Status: models.UserStatusActive})
+				users.add(&models.User{Email: "alt@example.com", Status: models.UserStatusMerged, MergedInto: &target.ID})
+			},
+			email: "alt@example.com",
+			want:  "main@example.com",
+		},
+		{
+			name: "keeps a merged account whose target is gone",
+			setup: func(users *fakeUsers) {
+				gone := bson.NewObjectID()
+				users.add(&models.User{Email: "alt@example.com", Status: models.UserStatusMerged, MergedInto: &gone})
+			},
+			email: "alt@example.com",
+			want:  "alt@example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsers{}
+			tt.setup(users)
+			svc := &UserService{users: users, clock: clock.Real}
+
+			user, err := svc.Provision(ctx, tt.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.Email != tt.want {
+				t.Errorf("signed into %q, want %q", user.Email, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUsers{}
+	existing := users.add(&models.User{Email: "a@example.com"})
+	svc := &UserService{users: users, clock: clock.Real}
+
+	if _, err := svc.Get(ctx, existing.ID); err != nil {
+		t.Fatalf("existing user: %v", err)
+	}
+	if _, err := svc.Get(ctx, bson.NewObjectID()); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("err = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestCompleteOnboardingAgeGate(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		ageRequired bool
+		ageBand     string
+		wantErr     error
+	}{
+		{"gate off", false, "", nil},
+		{"gate on without an age", true, "", ErrAgeRequired},
+		{"gate on with an age", true, "18-24", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsers{}
+			user := users.add(&models.User{Email: "a@example.com", AgeBand: tt.ageBand})
+			svc := &UserService{users: users, ageRequired: tt.ageRequired, clock: clock.Real}
+
+			err := svc.CompleteOnboarding(ctx, user.ID, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			stored, _ := users.FindByID(ctx, user.ID)
+			if stored.OnboardingCompleted != (tt.wantErr == nil) {
+				t.Errorf("onboarding completed = %v", stored.OnboardingCompleted)
+			}
+		})
+	}
+}
+
+func TestSetAgeOnBirthday(t *testing.T) {
+	ctx := context.Background()
+	users := &fakeUsers{}
+	user := users.add(&models.User{Email: "a@example.com"})
+	now := clock.NewFake(time.Date(2026, 6, 14, 23, 0, 0, 0, time.UTC))
+	svc := &UserService{users: users, ageRules: agegate.Rules{Default: 13}, clock: now}
+	in := AgeInput{DateOfBirth: "2013-06-15", Country: "gb"}
+
+	result, err := svc.SetAge(ctx, user.ID, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed {
+		t.Errorf("the day before their 13th birthday: allowed, want blocked")
+	}
+
+	now.Advance(time.Hour)
+	if result, err = svc.SetAge(ctx, user.ID, in); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Errorf("on their 13th birthday: blocked, want allowed")
+	}
+}
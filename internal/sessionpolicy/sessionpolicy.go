@@ -0,0 +1,163 @@
+package sessionpolicy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"rizon-backend/internal/repository"
+)
+
+// DefaultName is the policy used for users outside every cohort.
+const DefaultName = "default"
+
+// FlagPrefix namespaces the feature flags that assign users to a policy:
+// policy "short" applies to users for whom flag "jwt-policy:short" is on.
+const FlagPrefix = "jwt-policy:"
+
+// Stat counters recorded per policy.
+const (
+	StatIssued    = "issued"
+	StatRefreshed = "refreshed"
+	StatExpired   = "expired"
+)
+
+// Policy is a session issuance policy. Lifetime is how long a refresh token
+// lasts; access tokens are capped at the auth service's shorter lifetime.
+type Policy struct {
+	Name     string        `json:"name"`
+	Lifetime time.Duration `json:"-"`
+	Flag     string        `json:"flag,omitempty"`
+	Seconds  int64         `json:"lifetime_seconds"`
+}
+
+// Parse reads "name=duration" pairs, e.g. "7d-canary=168h,1d-canary=24h".
+// Order matters: a user in several cohorts gets the first matching policy.
+func Parse(spec string) ([]Policy, error) {
+	var policies []Policy
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(part, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid policy %q, want name=duration", part)
+		}
+		lifetime, err := time.ParseDuration(raw)
+		if err != nil || lifetime <= 0 {
+			return nil, fmt.Errorf("invalid lifetime for policy %q: %q", name, raw)
+		}
+		if name == DefaultName {
+			return nil, fmt.Errorf("policy name %q is reserved", DefaultName)
+		}
+		policies = append(policies, newPolicy(name, lifetime, FlagPrefix+name))
+	}
+	return policies, nil
+}
+
+func newPolicy(name string, lifetime time.Duration, flag string) Policy {
+	return Policy{Name: name, Lifetime: lifetime, Flag: flag, Seconds: int64(lifetime / time.Second)}
+}
+
+// FlagChecker decides whether a feature flag is on for a user.
+type FlagChecker interface {
+	Enabled(key, userID string) bool
+}
+
+type counterKey struct {
+	day    string
+	policy string
+	field  string
+}
+
+// Selector picks the JWT lifetime for a user and counts issued, refreshed and
+// expired tokens per policy. Counts are aggregated in memory and flushed
+// periodically, like usage metering.
+type Selector struct {
+	fallback Policy
+	policies []Policy
+	flags    FlagChecker
+	repo     *repository.SessionPolicyRepo
+
+	mu      sync.Mutex
+	pending map[counterKey]int64
+}
+
+func NewSelector(defaultLifetime time.Duration, policies []Policy, flags FlagChecker, repo *repository.SessionPolicyRepo) *Selector {
+	return &Selector{
+		fallback: newPolicy(DefaultName, defaultLifetime, ""),
+		policies: policies,
+		flags:    flags,
+		repo:     repo,
+		pending:  map[counterKey]int64{},
+	}
+}
+
+// For returns the policy that applies to userID.
+func (s *Selector) For(userID string) Policy {
+	for _, p := range s.policies {
+		if s.flags.Enabled(p.Flag, userID) {
+			return p
+		}
+	}
+	return s.fallback
+}
+
+// Policies lists the default policy followed by every cohort policy.
+func (s *Selector) Policies() []Policy {
+	return append([]Policy{s.fallback}, s.policies...)
+}
+
+// Record counts one event for a policy. Tokens issued before policies were
+// introduced carry no policy claim and are counted under the default.
+func (s *Selector) Record(policy, field string) {
+	if policy == "" {
+		policy = DefaultName
+	}
+	key := counterKey{day: time.Now().UTC().Format("2006-01-02"), policy: policy, field: field}
+	s.mu.Lock()
+	s.pending[key]++
+	s.mu.Unlock()
+}
+
+// Expired implements the JWT middleware's observer for expired tokens.
+func (s *Selector) Expired(policy string) {
+	s.Record(policy, StatExpired)
+}
+
+// Run flushes counters every interval until ctx is cancelled, then flushes once more.
+func (s *Selector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+func (s *Selector) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[counterKey]int64{}
+	s.mu.Unlock()
+
+	for key, n := range pending {
+		if err := s.repo.Increment(ctx, key.day, key.policy, key.field, n); err != nil {
+			log.Printf("Error flushing session policy stats: %v", err)
+			s.mu.Lock()
+			s.pending[key] += n
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package signedurl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rizon-backend/internal/clock"
+)
+
+type memoryNonces map[string]bool
+
+func (m memoryNonces) Use(_ context.Context, nonce string, _ time.Duration) (bool, error) {
+	if m[nonce] {
+		return false, nil
+	}
+	m[nonce] = true
+	return true, nil
+}
+
+func TestVerify(t *testing.T) {
+	signer := New("secret", memoryNonces{})
+	ctx := context.Background()
+
+	claims, err := signer.Verify(ctx, signer.Sign("/exports/1", time.Hour))
+	if err != nil || claims.Path != "/exports/1" {
+		t.Fatalf("Verify = %+v, %v; want the signed path", claims, err)
+	}
+	if _, err := signer.Verify(ctx, signer.Sign("/exports/1", -time.Minute)); !errors.Is(err, ErrExpired) {
+		t.Errorf("expired token: err = %v, want ErrExpired", err)
+	}
+	if _, err := New("other", nil).Verify(ctx, signer.Sign("/exports/1", time.Hour)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("token from another secret: err = %v, want ErrInvalid", err)
+	}
+
+	once, err := signer.SignOnce("/exports/2", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Verify(ctx, once); err != nil {
+		t.Fatalf("single-use token: %v", err)
+	}
+	if _, err := signer.Verify(ctx, once); !errors.Is(err, ErrUsed) {
+		t.Errorf("reused token: err = %v, want ErrUsed", err)
+	}
+}
+
+func TestVerifyAcceptedSecrets(t *testing.T) {
+	ctx := context.Background()
+	token := New("old", nil).Sign("/exports/1", time.Hour)
+
+	// After the flip, the old secret is only accepted
+	rotated := New("new", nil).WithAccepted([]string{"", "old"})
+	if _, err := rotated.Verify(ctx, token); err != nil {
+		t.Errorf("token signed with an accepted secret: %v", err)
+	}
+	// Once it's removed, its links stop working
+	if _, err := New("new", nil).Verify(ctx, token); !errors.Is(err, ErrInvalid) {
+		t.Errorf("token signed with a removed secret: err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestVerifyExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	signer := New("secret", memoryNonces{}).WithClock(now)
+	token := signer.Sign("/exports/1", time.Hour)
+
+	now.Advance(time.Hour - time.Second)
+	if _, err := signer.Verify(ctx, token); err != nil {
+		t.Fatalf("a second before expiry: %v", err)
+	}
+	now.Advance(time.Second)
+	if _, err := signer.Verify(ctx, token); !errors.Is(err, ErrExpired) {
+		t.Errorf("at expiry: err = %v, want ErrExpired", err)
+	}
+}
@@ -0,0 +1,39 @@
+package slack
+
+// Button is an interactive message button. Clicks are delivered to the
+// app's interactivity URL with ActionID and Value; URL buttons also open the
+// link in the browser.
+type Button struct {
+	Text     string
+	ActionID string
+	Value    string
+	URL      string
+	Style    string // "", "primary" or "danger"
+}
+
+// buttonBlocks lays out a message as a text section followed by its buttons.
+func buttonBlocks(message string, buttons []Button) []map[string]interface{} {
+	elements := make([]map[string]interface{}, 0, len(buttons))
+	for _, b := range buttons {
+		element := map[string]interface{}{
+			"type":      "button",
+			"text":      map[string]interface{}{"type": "plain_text", "text": b.Text},
+			"action_id": b.ActionID,
+		}
+		if b.Value != "" {
+			element["value"] = b.Value
+		}
+		if b.URL != "" {
+			element["url"] = b.URL
+		}
+		if b.Style != "" {
+			element["style"] = b.Style
+		}
+		elements = append(elements, element)
+	}
+
+	return []map[string]interface{}{
+		{"type": "section", "text": map[string]interface{}{"type": "mrkdwn", "text": message}},
+		{"type": "actions", "elements": elements},
+	}
+}
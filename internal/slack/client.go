@@ -0,0 +1,136 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"rizon-backend/internal/httpclient"
+	"rizon-backend/internal/resilience"
+)
+
+const postMessageURL = "https://slack.com/api/chat.postMessage"
+
+// defaultRetryAfter is the pause after a 429 without a Retry-After header.
+const defaultRetryAfter = 30 * time.Second
+
+// RateLimitedError means Slack answered 429. It is not retried: callers
+// should hold messages for RetryAfter (see Queue).
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("slack rate limited, retry after %s", e.RetryAfter)
+}
+
+// Client implements Notifier with the Slack Web API (chat.postMessage), which
+// returns message timestamps needed for threading. Incoming webhooks don't.
+// Transient failures are retried with jitter behind a circuit breaker; rate
+// limits are returned as RateLimitedError.
+type Client struct {
+	token   string
+	channel string
+	http    *http.Client
+	breaker *resilience.Breaker
+}
+
+func NewClient(botToken, channelID string) *Client {
+	return &Client{
+		token:   botToken,
+		channel: channelID,
+		http:    httpclient.New("slack", httpclient.Options{}),
+		breaker: resilience.NewBreaker("slack", resilience.DefaultBreakerConfig),
+	}
+}
+
+func (c *Client) Publish(ctx context.Context, message string) (*Message, error) {
+	return c.post(ctx, map[string]interface{}{
+		"channel": c.channel,
+		"text":    message,
+	})
+}
+
+func (c *Client) PublishWithButtons(ctx context.Context, message string, buttons []Button) (*Message, error) {
+	return c.post(ctx, map[string]interface{}{
+		"channel": c.channel,
+		"text":    message, // notification fallback
+		"blocks":  buttonBlocks(message, buttons),
+	})
+}
+
+func (c *Client) Reply(ctx context.Context, thread *Message, message string) (*Message, error) {
+	return c.post(ctx, map[string]interface{}{
+		"channel":   thread.Channel,
+		"thread_ts": thread.TS,
+		"text":      message,
+	})
+}
+
+func (c *Client) post(ctx context.Context, payload map[string]interface{}) (*Message, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg *Message
+	err = c.breaker.Do(ctx, func(ctx context.Context) error {
+		return resilience.Retry(ctx, resilience.DefaultRetryPolicy, func(ctx context.Context) error {
+			var err error
+			msg, err = c.send(ctx, body)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (c *Client) send(ctx context.Context, body []byte) (*Message, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, postMessageURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Slack answered, so this doesn't count against the breaker
+	if resp.StatusCode == http.StatusTooManyRequests {
+		io.Copy(io.Discard, resp.Body)
+		retryAfter := defaultRetryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, resilience.Permanent(&RateLimitedError{RetryAfter: retryAfter})
+	}
+
+	var result struct {
+		OK      bool   `json:"ok"`
+		Error   string `json:"error"`
+		Channel string `json:"channel"`
+		TS      string `json:"ts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid slack response (status %d): %w", resp.StatusCode, err)
+	}
+	if !result.OK {
+		err := fmt.Errorf("slack error: %s", result.Error)
+		if result.Error == "ratelimited" || result.Error == "service_unavailable" || result.Error == "internal_error" {
+			return nil, err
+		}
+		return nil, resilience.Permanent(err)
+	}
+	return &Message{Channel: result.Channel, TS: result.TS}, nil
+}
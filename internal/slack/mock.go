@@ -2,7 +2,9 @@ package slack
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 )
 
 // MockSlack implements the Notifier interface by logging messages to stdout.
@@ -13,7 +15,27 @@ func NewMockSlack() *MockSlack {
 	return &MockSlack{}
 }
 
-func (m *MockSlack) Publish(ctx context.Context, message string) error {
+func (m *MockSlack) Publish(ctx context.Context, message string) (*Message, error) {
 	log.Printf("📨 [MockSlack] Published to Slack channel: %s", message)
-	return nil
+	return &Message{Channel: "mock", TS: mockTS()}, nil
+}
+
+func (m *MockSlack) Reply(ctx context.Context, thread *Message, message string) (*Message, error) {
+	log.Printf("📨 [MockSlack] Replied in thread %s: %s", thread.TS, message)
+	return &Message{Channel: thread.Channel, TS: mockTS()}, nil
+}
+
+func (m *MockSlack) PublishWithButtons(ctx context.Context, message string, buttons []Button) (*Message, error) {
+	actions := make([]string, len(buttons))
+	for i, b := range buttons {
+		actions[i] = b.Text
+	}
+	log.Printf("📨 [MockSlack] Published to Slack channel: %s %v", message, actions)
+	return &Message{Channel: "mock", TS: mockTS()}, nil
+}
+
+// mockTS mimics Slack's "seconds.micros" message timestamps.
+func mockTS() string {
+	now := time.Now()
+	return fmt.Sprintf("%d.%06d", now.Unix(), now.Nanosecond()/1000)
 }
@@ -2,8 +2,19 @@ package slack
 
 import "context"
 
+// Message identifies a posted Slack message so later events can reply in its thread.
+type Message struct {
+	Channel string
+	TS      string
+}
+
 // Notifier defines the interface for publishing messages to a notification channel.
 // This abstraction allows swapping mock with real Slack integration without refactoring.
 type Notifier interface {
-	Publish(ctx context.Context, message string) error
+	// Publish posts a new top-level message.
+	Publish(ctx context.Context, message string) (*Message, error)
+	// Reply posts a message in the thread of a previously published message.
+	Reply(ctx context.Context, thread *Message, message string) (*Message, error)
+	// PublishWithButtons posts a new top-level message with interactive buttons.
+	PublishWithButtons(ctx context.Context, message string, buttons []Button) (*Message, error)
 }
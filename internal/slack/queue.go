@@ -0,0 +1,282 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueued means the message was held and will be posted later,
+	// possibly as part of a summary. There is no Message to thread on.
+	ErrQueued = errors.New("slack message queued")
+	// ErrQueueFull means the message was dropped.
+	ErrQueueFull = errors.New("slack queue full, message dropped")
+)
+
+// QueueConfig bounds how fast a Queue posts.
+type QueueConfig struct {
+	Burst    int           // messages posted right away per Window
+	Window   time.Duration // the rest are held and summarized when it ends
+	Capacity int           // held messages beyond this are dropped
+}
+
+var DefaultQueueConfig = QueueConfig{
+	Burst:    10,
+	Window:   time.Minute,
+	Capacity: 500,
+}
+
+// Summaries quote up to summaryLimit held messages, each flattened to one
+// line of at most summaryLineLength characters.
+const (
+	summaryLimit      = 20
+	summaryLineLength = 200
+)
+
+// held is a message waiting for the window or a rate limit to pass.
+type held struct {
+	thread  *Message // nil for top-level messages
+	text    string
+	buttons []Button
+}
+
+// Queue wraps a Notifier so bursts and Slack rate limits don't lose
+// messages. Up to Burst messages per Window are posted directly; after that,
+// or while Slack asks us to back off, messages are held and Run posts them
+// in one go: held top-level messages become a single summary and held
+// replies are joined per thread.
+type Queue struct {
+	inner Notifier
+	cfg   QueueConfig
+
+	mu          sync.Mutex
+	pending     []held
+	windowStart time.Time
+	windowSent  int
+	pausedUntil time.Time
+	stats       QueueStats
+}
+
+// QueueStats are counted since startup.
+type QueueStats struct {
+	Pending     int        `json:"pending"`
+	Posted      int64      `json:"posted"`
+	Held        int64      `json:"held"`
+	Summarized  int64      `json:"summarized"` // held messages posted as part of a summary
+	Dropped     int64      `json:"dropped"`
+	RateLimited int64      `json:"rate_limited"` // 429s from Slack
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+}
+
+func NewQueue(inner Notifier, cfg QueueConfig) *Queue {
+	return &Queue{inner: inner, cfg: cfg}
+}
+
+func (q *Queue) Publish(ctx context.Context, message string) (*Message, error) {
+	return q.post(ctx, held{text: message})
+}
+
+func (q *Queue) PublishWithButtons(ctx context.Context, message string, buttons []Button) (*Message, error) {
+	return q.post(ctx, held{text: message, buttons: buttons})
+}
+
+func (q *Queue) Reply(ctx context.Context, thread *Message, message string) (*Message, error) {
+	return q.post(ctx, held{thread: thread, text: message})
+}
+
+func (q *Queue) post(ctx context.Context, msg held) (*Message, error) {
+	if !q.admit() {
+		return nil, q.hold(msg)
+	}
+	posted, err := q.send(ctx, msg)
+	var limited *RateLimitedError
+	if errors.As(err, &limited) {
+		return nil, q.hold(msg)
+	}
+	return posted, err
+}
+
+// admit reports whether a message may be posted now, counting it against
+// the window if so.
+func (q *Queue) admit() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	if now.Before(q.pausedUntil) || len(q.pending) > 0 {
+		return false
+	}
+	if now.Sub(q.windowStart) >= q.cfg.Window {
+		q.windowStart, q.windowSent = now, 0
+	}
+	if q.windowSent >= q.cfg.Burst {
+		return false
+	}
+	q.windowSent++
+	return true
+}
+
+func (q *Queue) hold(msg held) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= q.cfg.Capacity {
+		q.stats.Dropped++
+		return ErrQueueFull
+	}
+	q.pending = append(q.pending, msg)
+	q.stats.Held++
+	return ErrQueued
+}
+
+// send posts one message, pausing the queue if Slack rate limits it.
+func (q *Queue) send(ctx context.Context, msg held) (*Message, error) {
+	var posted *Message
+	var err error
+	switch {
+	case msg.thread != nil:
+		posted, err = q.inner.Reply(ctx, msg.thread, msg.text)
+	case len(msg.buttons) > 0:
+		posted, err = q.inner.PublishWithButtons(ctx, msg.text, msg.buttons)
+	default:
+		posted, err = q.inner.Publish(ctx, msg.text)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var limited *RateLimitedError
+	if errors.As(err, &limited) {
+		q.stats.RateLimited++
+		if until := time.Now().Add(limited.RetryAfter); until.After(q.pausedUntil) {
+			q.pausedUntil = until
+		}
+		log.Printf("⏸️  Slack rate limited, holding messages for %s", limited.RetryAfter)
+	} else if err == nil {
+		q.stats.Posted++
+	}
+	return posted, err
+}
+
+// Run posts held messages whenever the window and any rate limit allow,
+// until ctx is cancelled.
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.flush(ctx)
+		}
+	}
+}
+
+func (q *Queue) flush(ctx context.Context) {
+	q.mu.Lock()
+	now := time.Now()
+	if len(q.pending) == 0 || now.Before(q.pausedUntil) || now.Sub(q.windowStart) < q.cfg.Window {
+		q.mu.Unlock()
+		return
+	}
+	pending := q.pending
+	q.pending = nil
+	q.windowStart, q.windowSent = now, 0
+	q.mu.Unlock()
+
+	batch, summarized := coalesce(pending)
+	for i, msg := range batch {
+		_, err := q.send(ctx, msg)
+		var limited *RateLimitedError
+		if errors.As(err, &limited) {
+			q.requeue(batch[i:])
+			return
+		}
+		if err != nil {
+			log.Printf("Error posting held Slack message: %v", err)
+		}
+	}
+	q.mu.Lock()
+	q.windowSent = len(batch)
+	q.stats.Summarized += int64(summarized)
+	q.mu.Unlock()
+}
+
+// requeue puts messages that couldn't be posted back in front of the queue.
+func (q *Queue) requeue(msgs []held) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(msgs, q.pending...)
+	if over := len(q.pending) - q.cfg.Capacity; over > 0 {
+		q.pending = q.pending[:q.cfg.Capacity]
+		q.stats.Dropped += int64(over)
+	}
+}
+
+// coalesce turns held messages into what to post: one summary for the
+// top-level messages (a lone one is posted as is) and one reply per thread.
+// It also returns how many messages went into the summary.
+func coalesce(pending []held) ([]held, int) {
+	var top []held
+	var threads []*Message
+	replies := map[Message][]string{}
+	for _, msg := range pending {
+		if msg.thread == nil {
+			top = append(top, msg)
+			continue
+		}
+		key := *msg.thread
+		if _, ok := replies[key]; !ok {
+			threads = append(threads, msg.thread)
+		}
+		replies[key] = append(replies[key], msg.text)
+	}
+
+	var batch []held
+	summarized := 0
+	switch len(top) {
+	case 0:
+	case 1:
+		batch = append(batch, top[0])
+	default:
+		batch = append(batch, held{text: summary(top)})
+		summarized = len(top)
+	}
+	for _, thread := range threads {
+		batch = append(batch, held{thread: thread, text: strings.Join(replies[*thread], "\n\n")})
+	}
+	return batch, summarized
+}
+
+func summary(msgs []held) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 *%d notifications* arrived in a burst:\n", len(msgs))
+	for i, msg := range msgs {
+		if i == summaryLimit {
+			fmt.Fprintf(&b, "…and %d more", len(msgs)-summaryLimit)
+			break
+		}
+		line := strings.Join(strings.Fields(strings.ReplaceAll(msg.text, "\n", " · ")), " ")
+		if runes := []rune(line); len(runes) > summaryLineLength {
+			line = string(runes[:summaryLineLength]) + "…"
+		}
+		fmt.Fprintf(&b, "• %s\n", line)
+	}
+	return b.String()
+}
+
+// Stats returns a snapshot of the queue.
+func (q *Queue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	stats.Pending = len(q.pending)
+	if time.Now().Before(q.pausedUntil) {
+		until := q.pausedUntil
+		stats.PausedUntil = &until
+	}
+	return stats
+}
@@ -0,0 +1,42 @@
+package slack
+
+import (
+	"context"
+	"errors"
+
+	"rizon-backend/internal/database"
+)
+
+// ErrSandboxed means the message came from a sandbox and was dropped.
+var ErrSandboxed = errors.New("slack message from a sandbox dropped")
+
+// SkipSandbox wraps a notifier so sandbox traffic never reaches Slack, even
+// from code paths that don't check for a sandbox themselves.
+func SkipSandbox(inner Notifier) Notifier {
+	return &sandboxGuard{inner: inner}
+}
+
+type sandboxGuard struct {
+	inner Notifier
+}
+
+func (g *sandboxGuard) Publish(ctx context.Context, message string) (*Message, error) {
+	if database.InSandbox(ctx) {
+		return nil, ErrSandboxed
+	}
+	return g.inner.Publish(ctx, message)
+}
+
+func (g *sandboxGuard) Reply(ctx context.Context, thread *Message, message string) (*Message, error) {
+	if database.InSandbox(ctx) {
+		return nil, ErrSandboxed
+	}
+	return g.inner.Reply(ctx, thread, message)
+}
+
+func (g *sandboxGuard) PublishWithButtons(ctx context.Context, message string, buttons []Button) (*Message, error) {
+	if database.InSandbox(ctx) {
+		return nil, ErrSandboxed
+	}
+	return g.inner.PublishWithButtons(ctx, message, buttons)
+}
@@ -0,0 +1,108 @@
+package spam
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Threshold is the score at which a submission is treated as spam.
+const Threshold = 50
+
+// Input is what the heuristics look at.
+type Input struct {
+	Text     string
+	Honeypot string        // hidden form field only bots fill in
+	FillTime time.Duration // time between the form rendering and submit; 0 if unknown
+}
+
+// Result is a score from 0 (clean) to 100 with the reasons that added to it.
+type Result struct {
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+func (r Result) IsSpam() bool { return r.Score >= Threshold }
+
+var (
+	urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+	spamWords  = []string{"viagra", "casino", "crypto investment", "bitcoin", "seo services", "backlinks", "loan offer", "forex", "porn"}
+)
+
+// Score rates a submission with cheap heuristics. It's meant to keep Slack
+// quiet, not to be a perfect classifier, so spam is still stored.
+func Score(in Input) Result {
+	var r Result
+	add := func(points int, reason string) {
+		r.Score += points
+		r.Reasons = append(r.Reasons, reason)
+	}
+
+	if in.Honeypot != "" {
+		add(100, "honeypot")
+	}
+	if in.FillTime > 0 && in.FillTime < 3*time.Second {
+		add(40, "submitted too fast")
+	}
+
+	text := strings.TrimSpace(in.Text)
+	lower := strings.ToLower(text)
+	if links := len(urlPattern.FindAllString(text, -1)); links >= 3 {
+		add(40, "many links")
+	} else if links > 0 {
+		add(15, "contains link")
+	}
+	for _, word := range spamWords {
+		if strings.Contains(lower, word) {
+			add(30, "spam keyword")
+			break
+		}
+	}
+	if hasRun(text, 10) {
+		add(15, "repeated characters")
+	}
+	if isShouting(text) {
+		add(10, "all caps")
+	}
+	if len([]rune(text)) < 3 {
+		add(20, "too short")
+	}
+
+	if r.Score > 100 {
+		r.Score = 100
+	}
+	return r
+}
+
+// isShouting reports whether a reasonably long text is almost all upper case.
+func isShouting(text string) bool {
+	var letters, upper int
+	for _, c := range text {
+		if unicode.IsLetter(c) {
+			letters++
+			if unicode.IsUpper(c) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && upper*10 >= letters*9
+}
+
+// hasRun reports whether text repeats one character n or more times in a
+// row. (RE2 has no backreferences, so this can't be a regexp.)
+func hasRun(text string, n int) bool {
+	var prev rune
+	count := 0
+	for _, c := range text {
+		if c == prev {
+			count++
+		} else {
+			prev, count = c, 1
+		}
+		if count >= n {
+			return true
+		}
+	}
+	return false
+}
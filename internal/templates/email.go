@@ -0,0 +1,207 @@
+package templates
+
+// Email copy lives in the i18n catalogs (internal/i18n/locales); render with
+// RenderEmail to pick the recipient's language and format.
+
+func init() {
+	register(Template{
+		Name:    "login_link",
+		Channel: ChannelEmail,
+		Subject: `{{t "login_link.subject"}}`,
+		HTML: `
+			<div style="{{css "container"}}">
+				{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" style="{{css "logo"}}">{{end}}
+				<h2 style="{{css "heading"}}">{{t "login_link.heading" "brand" .Brand.Name}} 🚀</h2>
+				<p>{{t "login_link.click_below"}}</p>
+				<a href="{{.Link}}" style="{{css "button" .Brand.PrimaryColor}}">
+					{{t "common.open_app"}}
+				</a>
+				{{if .Code}}
+				<p>{{t "login_link.code"}}</p>
+				<p style="{{css "code"}}">{{.Code}}</p>
+				{{end}}
+				<p style="{{css "note"}}">
+					{{tn "login_link.expiry" 15}}
+				</p>
+				<p style="{{css "fine"}}">
+					{{t "common.ignore_email"}}
+				</p>
+			</div>
+		`,
+		Text: `{{t "login_link.heading" "brand" .Brand.Name}}
+
+{{t "login_link.open_link"}}
+{{.Link}}
+{{if .Code}}
+{{t "login_link.code"}}
+{{.Code}}
+{{end}}
+{{tn "login_link.expiry" 15}}
+{{t "common.ignore_email"}}
+`,
+		Sample: map[string]interface{}{
+			"Link":  "https://api.example.com/auth/redirect?token=00000000-0000-0000-0000-000000000000",
+			"Code":  "123456",
+			"Brand": DefaultBrand,
+		},
+	})
+	register(Template{
+		Name:    "org_invite",
+		Channel: ChannelEmail,
+		Subject: `{{t "org_invite.subject" "org" .OrgName}}`,
+		HTML: `
+			<div style="{{css "container"}}">
+				{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" style="{{css "logo"}}">{{end}}
+				<h2 style="{{css "heading"}}">{{t "org_invite.heading" "org" .OrgName}}</h2>
+				<p>{{t "org_invite.invited" "inviter" .InvitedBy "org" .OrgName}}</p>
+				<p>{{t "org_invite.accept" "email" .Email}}</p>
+				<p style="{{css "note"}}">
+					{{t "org_invite.expires" "date" .Expires}}
+				</p>
+				<p style="{{css "fine"}}">
+					{{t "org_invite.unexpected"}}
+				</p>
+			</div>
+		`,
+		Text: `{{t "org_invite.heading" "org" .OrgName}}
+
+{{t "org_invite.invited" "inviter" .InvitedBy "org" .OrgName}}
+{{t "org_invite.accept" "email" .Email}}
+
+{{t "org_invite.expires" "date" .Expires}}
+{{t "org_invite.unexpected"}}
+`,
+		Sample: map[string]interface{}{
+			"OrgName":   "Acme Inc.",
+			"InvitedBy": "jane@acme.example",
+			"Email":     "sam@acme.example",
+			"Expires":   "Jan 1, 2026",
+			"Brand":     DefaultBrand,
+		},
+	})
+	register(Template{
+		Name:    "data_export_ready",
+		Channel: ChannelEmail,
+		Subject: `{{t "data_export.subject"}}`,
+		HTML: `
+			<div style="{{css "container"}}">
+				<h2 style="{{css "heading"}}">{{t "data_export.heading"}} 📦</h2>
+				<p>{{t "data_export.intro"}}</p>
+				<a href="{{.Link}}" style="{{css "button"}}">
+					{{t "data_export.download"}}
+				</a>
+				<p style="{{css "note"}}">
+					{{t "data_export.expires" "date" .Expires}}
+				</p>
+				<p style="{{css "fine"}}">
+					{{t "data_export.unexpected"}}
+				</p>
+			</div>
+		`,
+		Text: `{{t "data_export.heading"}}
+
+{{t "data_export.intro"}}
+{{.Link}}
+
+{{t "data_export.expires" "date" .Expires}}
+{{t "data_export.unexpected"}}
+`,
+		Sample: map[string]interface{}{
+			"Link":    "https://api.example.com/user/export/download?id=665f1c2e9b1d4a0087654321&expires=1767225600&sig=0000",
+			"Expires": "Jan 1, 2026",
+		},
+	})
+	register(Template{
+		Name:    "announcement",
+		Channel: ChannelEmail,
+		Subject: "{{.Subject}}",
+		HTML: `
+			<div style="{{css "container"}}">
+				{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" style="{{css "logo"}}">{{end}}
+				<h2 style="{{css "heading"}}">{{.Subject}}</h2>
+				<p style="{{css "message"}}">{{.Message}}</p>
+				{{if .LinkURL}}<a href="{{.LinkURL}}" style="{{css "button" .Brand.PrimaryColor}}">
+					{{if .LinkLabel}}{{.LinkLabel}}{{else}}{{t "announcement.learn_more"}}{{end}}
+				</a>{{end}}
+				<p style="{{css "footer"}}">
+					{{t "announcement.footer"}}
+				</p>
+			</div>
+		`,
+		Text: `{{.Subject}}
+
+{{.Message}}
+{{if .LinkURL}}
+{{if .LinkLabel}}{{.LinkLabel}}{{else}}{{t "announcement.learn_more"}}{{end}}: {{.LinkURL}}
+{{end}}
+{{t "announcement.footer"}}
+`,
+		Sample: map[string]interface{}{
+			"Subject":   "Shared workspaces are here",
+			"Message":   "You can now invite your team to a shared Rizon workspace.",
+			"LinkURL":   "https://rizon.example/changelog",
+			"LinkLabel": "See what's new",
+			"Brand":     DefaultBrand,
+		},
+	})
+	register(Template{
+		Name:    "roadmap_shipped",
+		Channel: ChannelEmail,
+		Subject: `{{t "roadmap_shipped.subject" "title" .Title}}`,
+		HTML: `
+			<div style="{{css "container"}}">
+				{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" style="{{css "logo"}}">{{end}}
+				<h2 style="{{css "heading"}}">{{t "roadmap_shipped.heading"}}</h2>
+				<p style="{{css "message"}}">{{t "roadmap_shipped.body" "title" .Title}}</p>
+				<a href="{{.ChangelogURL}}" style="{{css "button" .Brand.PrimaryColor}}">
+					{{if .ChangelogTitle}}{{.ChangelogTitle}}{{else}}{{t "roadmap_shipped.changelog"}}{{end}}
+				</a>
+				<p style="{{css "footer"}}">
+					{{t "announcement.footer"}}
+				</p>
+			</div>
+		`,
+		Text: `{{t "roadmap_shipped.heading"}}
+
+{{t "roadmap_shipped.body" "title" .Title}}
+
+{{if .ChangelogTitle}}{{.ChangelogTitle}}{{else}}{{t "roadmap_shipped.changelog"}}{{end}}: {{.ChangelogURL}}
+
+{{t "announcement.footer"}}
+`,
+		Sample: map[string]interface{}{
+			"Title":          "Dark mode",
+			"ChangelogURL":   "https://rizon.example/changelog/dark-mode",
+			"ChangelogTitle": "",
+			"Brand":          DefaultBrand,
+		},
+	})
+	register(Template{
+		Name:    "onboarding_reminder",
+		Channel: ChannelEmail,
+		Subject: `{{if eq .Step "72h"}}{{t "onboarding_reminder.subject_last"}}{{else}}{{t "onboarding_reminder.subject"}}{{end}}`,
+		HTML: `
+			<div style="{{css "container"}}">
+				{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" style="{{css "logo"}}">{{end}}
+				<h2 style="{{css "heading"}}">{{t "onboarding_reminder.heading"}}</h2>
+				<p>{{t "onboarding_reminder.body" "brand" .Brand.Name}}</p>
+				{{if eq .Step "72h"}}<p style="{{css "note"}}">{{t "onboarding_reminder.last"}}</p>{{end}}
+				<p style="{{css "footer"}}">
+					{{t "announcement.footer"}}
+				</p>
+			</div>
+		`,
+		Text: `{{t "onboarding_reminder.heading"}}
+
+{{t "onboarding_reminder.body" "brand" .Brand.Name}}
+{{if eq .Step "72h"}}
+{{t "onboarding_reminder.last"}}
+{{end}}
+{{t "announcement.footer"}}
+`,
+		Sample: map[string]interface{}{
+			"Step":  "24h",
+			"Brand": DefaultBrand,
+		},
+	})
+}
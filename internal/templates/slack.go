@@ -0,0 +1,149 @@
+package templates
+
+func init() {
+	register(Template{
+		Name:    "feedback_received",
+		Channel: ChannelSlack,
+		Text: "📝 *New Feedback Received*\n" +
+			"User: `{{.UserID}}`\n" +
+			"Rating: {{stars .Rating}}\n" +
+			"{{if .App}}App: {{.App}}\n{{end}}" +
+			"Feedback: {{.Text}}",
+		Sample: map[string]interface{}{
+			"UserID": "665f1c2e9b1d4a0012345678",
+			"Rating": 4,
+			"Text":   "Love the new onboarding flow, but the reminder screen is a bit slow.",
+			"App":    "2.3.1 (451) · ios 17.4 · iPhone15,2",
+		},
+	})
+}
+
+func init() {
+	register(Template{
+		Name:    "web_feedback_received",
+		Channel: ChannelSlack,
+		Text: "🌐 *New Web Feedback*\n" +
+			"From: {{if .Email}}{{.Email}}{{else}}anonymous{{end}}\n" +
+			"Rating: {{stars .Rating}}\n" +
+			"Feedback: {{.Text}}",
+		Sample: map[string]interface{}{
+			"Email":  "v***@example.com",
+			"Rating": 5,
+			"Text":   "Found you through the blog — does the app work offline?",
+		},
+	})
+}
+
+func init() {
+	register(Template{
+		Name:    "feedback_status_changed",
+		Channel: ChannelSlack,
+		Text:    "🔄 Feedback `{{.FeedbackID}}` marked as *{{.Status}}*{{if .Actor}} by {{.Actor}}{{end}}",
+		Sample: map[string]interface{}{
+			"FeedbackID": "665f1c2e9b1d4a0087654321",
+			"Status":     "resolved",
+			"Actor":      "<@U024BE7LH>",
+		},
+	})
+	register(Template{
+		Name:    "feedback_assigned",
+		Channel: ChannelSlack,
+		Text:    "👤 Feedback `{{.FeedbackID}}` assigned to {{.Assignee}}",
+		Sample: map[string]interface{}{
+			"FeedbackID": "665f1c2e9b1d4a0087654321",
+			"Assignee":   "<@U024BE7LH>",
+		},
+	})
+
+	register(Template{
+		Name:    "feedback_reaction",
+		Channel: ChannelSlack,
+		Text: "{{if eq .Reaction \"up\"}}👍{{else}}👎{{end}} *Follow-up on feedback* `{{.FeedbackID}}`\n" +
+			"Did this solve it? {{if eq .Reaction \"up\"}}Yes{{else}}No{{end}}" +
+			"{{if .Comment}}\nComment: {{.Comment}}{{end}}",
+		Sample: map[string]interface{}{
+			"FeedbackID": "665f1c2e9b1d4a0087654321",
+			"Reaction":   "down",
+			"Comment":    "Still crashes when I open the reminders tab.",
+		},
+	})
+	register(Template{
+		Name:    "feedback_edited",
+		Channel: ChannelSlack,
+		Text: "✏️ Feedback `{{.FeedbackID}}` edited by its author\n" +
+			"Rating: {{stars .Rating}}\n" +
+			"Feedback: {{.Text}}",
+		Sample: map[string]interface{}{
+			"FeedbackID": "665f1c2e9b1d4a0087654321",
+			"Rating":     3,
+			"Text":       "Love the new onboarding flow, but the reminder screen takes ~5s to load on LTE.",
+		},
+	})
+	register(Template{
+		Name:    "feedback_deleted",
+		Channel: ChannelSlack,
+		Text:    "🗑️ Feedback `{{.FeedbackID}}` deleted by its author",
+		Sample: map[string]interface{}{
+			"FeedbackID": "665f1c2e9b1d4a0087654321",
+		},
+	})
+	register(Template{
+		Name:    "feedback_issue_created",
+		Channel: ChannelSlack,
+		Text:    "🎫 Feedback `{{.FeedbackID}}` promoted to {{.Provider}} issue <{{.URL}}|{{.Key}}>",
+		Sample: map[string]interface{}{
+			"FeedbackID": "665f1c2e9b1d4a0087654321",
+			"Provider":   "linear",
+			"Key":        "ENG-123",
+			"URL":        "https://linear.app/rizon/issue/ENG-123",
+		},
+	})
+	register(Template{
+		Name:    "abuse_ip_blocked",
+		Channel: ChannelSlack,
+		Text: "🚫 *Blocked IP* `{{.IP}}` for {{.Duration}}\n" +
+			"Reason: {{.Reason}} ({{.Details}})\n" +
+			"Lift with `DELETE /admin/abuse/blocks/{{.IP}}`",
+		Sample: map[string]interface{}{
+			"IP":       "203.0.113.7",
+			"Reason":   "sequential_emails",
+			"Details":  "5 numbered variants of one address within 1h0m0s",
+			"Duration": "1h0m0s",
+		},
+	})
+	register(Template{
+		Name:    "email_failover_engaged",
+		Channel: ChannelSlack,
+		Text: "📮 *Email failover engaged* — {{.Provider}} failed {{.Failures}} times in a row, sending via {{.Fallback}}\n" +
+			"Last error: {{.Error}}\n" +
+			"Retrying {{.Provider}} every {{.Cooldown}}",
+		Sample: map[string]interface{}{
+			"Provider": "resend",
+			"Fallback": "smtp",
+			"Failures": 3,
+			"Error":    "context deadline exceeded",
+			"Cooldown": "1m0s",
+		},
+	})
+	register(Template{
+		Name:    "email_provider_recovered",
+		Channel: ChannelSlack,
+		Text:    "✅ Email provider {{.Provider}} recovered, failover disengaged",
+		Sample: map[string]interface{}{
+			"Provider": "resend",
+		},
+	})
+	register(Template{
+		Name:    "org_usage_summary",
+		Channel: ChannelSlack,
+		Text: "🧾 *Organization usage for {{.Month}}*\n" +
+			"{{range .Reports}}• `{{.OrgSlug}}`: {{.Seats}} seats, {{.ActiveUsers}} active, {{.Total}} API requests\n{{end}}" +
+			"Full reports: `GET /admin/billing/org-usage?month={{.Month}}`",
+		Sample: map[string]interface{}{
+			"Month": "2026-01",
+			"Reports": []map[string]interface{}{
+				{"OrgSlug": "acme", "Seats": 42, "ActiveUsers": 31, "Total": 1804},
+			},
+		},
+	})
+}
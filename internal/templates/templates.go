@@ -0,0 +1,213 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"rizon-backend/internal/i18n"
+)
+
+// Channel is the delivery channel a template renders for.
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSlack Channel = "slack"
+	ChannelPush  Channel = "push"
+)
+
+// Template is the source of a notification. Email uses Subject + HTML (+ Text),
+// Slack uses Text, push uses Subject as the title and Text as the body.
+type Template struct {
+	Name    string
+	Channel Channel
+	Subject string
+	HTML    string
+	Text    string
+	// Sample data used by the admin preview endpoint
+	Sample map[string]interface{}
+}
+
+// Rendered is a template rendered with concrete data.
+type Rendered struct {
+	Subject string `json:"subject,omitempty"`
+	HTML    string `json:"html,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+// Brand is the look of login emails and pages. Organizations can override
+// it; templates that take a Brand always get a complete one.
+type Brand struct {
+	Name         string
+	LogoURL      string // optional
+	PrimaryColor string // #rrggbb
+}
+
+// DefaultBrand is Rizon's own look.
+var DefaultBrand = Brand{Name: "Rizon", PrimaryColor: "#6366f1"}
+
+// Info describes a registered template.
+type Info struct {
+	Name    string  `json:"name"`
+	Channel Channel `json:"channel"`
+}
+
+type compiled struct {
+	source  Template
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+type key struct {
+	name    string
+	channel Channel
+}
+
+// registry holds every template compiled for i18n.DefaultLocale and the
+// standard theme.
+var registry = map[key]*compiled{}
+
+// variants caches templates compiled for other locales and themes.
+var variants sync.Map // variantKey -> *compiled
+
+type variantKey struct {
+	key
+	locale string
+	theme  string
+}
+
+var funcs = map[string]interface{}{
+	"stars": func(n int) string { return strings.Repeat("⭐", n) },
+	"upper": strings.ToUpper,
+}
+
+// compile parses a template with the base functions, the locale's t, tn,
+// number, money and date, and the theme's css.
+func compile(t Template, loc *i18n.Localizer, theme string) *compiled {
+	fm := map[string]interface{}{}
+	for name, fn := range funcs {
+		fm[name] = fn
+	}
+	for name, fn := range loc.Funcs() {
+		fm[name] = fn
+	}
+	fm["css"] = themes[theme].css
+
+	c := &compiled{source: t}
+	id := fmt.Sprintf("%s/%s", t.Channel, t.Name)
+	if t.Subject != "" {
+		c.subject = texttemplate.Must(texttemplate.New(id + "/subject").Funcs(fm).Parse(t.Subject))
+	}
+	if t.HTML != "" {
+		c.html = htmltemplate.Must(htmltemplate.New(id + "/html").Funcs(fm).Parse(t.HTML))
+	}
+	if t.Text != "" {
+		c.text = texttemplate.Must(texttemplate.New(id + "/text").Funcs(fm).Parse(t.Text))
+	}
+	return c
+}
+
+// register compiles a template at init time; a broken template panics on startup
+// rather than at send time.
+func register(t Template) {
+	registry[key{t.Name, t.Channel}] = compile(t, i18n.For(i18n.DefaultLocale), themeStandard)
+}
+
+// Render renders the named template for a channel with the given data, in
+// i18n.DefaultLocale.
+func Render(name string, channel Channel, data interface{}) (*Rendered, error) {
+	return RenderLocale(name, channel, i18n.DefaultLocale, data)
+}
+
+// RenderLocale renders the named template in the closest supported locale
+// to the given tag (see i18n.For).
+func RenderLocale(name string, channel Channel, locale string, data interface{}) (*Rendered, error) {
+	return render(name, channel, locale, themeStandard, data)
+}
+
+func render(name string, channel Channel, locale, theme string, data interface{}) (*Rendered, error) {
+	c, ok := registry[key{name, channel}]
+	if !ok {
+		return nil, fmt.Errorf("template %q not found for channel %q", name, channel)
+	}
+	if loc := i18n.For(locale); loc.Locale() != i18n.DefaultLocale || theme != themeStandard {
+		k := variantKey{key{name, channel}, loc.Locale(), theme}
+		if cached, ok := variants.Load(k); ok {
+			c = cached.(*compiled)
+		} else {
+			cached, _ := variants.LoadOrStore(k, compile(c.source, loc, theme))
+			c = cached.(*compiled)
+		}
+	}
+
+	out := &Rendered{}
+	var buf bytes.Buffer
+	if c.subject != nil {
+		if err := c.subject.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		out.Subject = buf.String()
+		buf.Reset()
+	}
+	if c.html != nil {
+		if err := c.html.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		out.HTML = buf.String()
+		buf.Reset()
+	}
+	if c.text != nil {
+		if err := c.text.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		out.Text = buf.String()
+	}
+	return out, nil
+}
+
+// Preview renders the named template with its built-in sample data. The
+// format only applies to email.
+func Preview(name string, channel Channel, opts EmailOptions) (*Rendered, error) {
+	return PreviewWith(name, channel, opts, nil)
+}
+
+// PreviewWith renders the named template with its sample data, overriding
+// the given keys.
+func PreviewWith(name string, channel Channel, opts EmailOptions, overrides map[string]interface{}) (*Rendered, error) {
+	c, ok := registry[key{name, channel}]
+	if !ok {
+		return nil, fmt.Errorf("template %q not found for channel %q", name, channel)
+	}
+	data := make(map[string]interface{}, len(c.source.Sample)+len(overrides))
+	for k, v := range c.source.Sample {
+		data[k] = v
+	}
+	for k, v := range overrides {
+		data[k] = v
+	}
+	if channel == ChannelEmail {
+		return RenderEmail(name, opts, data)
+	}
+	return RenderLocale(name, channel, opts.Locale, data)
+}
+
+// List returns all registered templates sorted by channel and name.
+func List() []Info {
+	infos := make([]Info, 0, len(registry))
+	for k := range registry {
+		infos = append(infos, Info{Name: k.name, Channel: k.channel})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Channel != infos[j].Channel {
+			return infos[i].Channel < infos[j].Channel
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"html"
+	htmltemplate "html/template"
+	"regexp"
+	"strings"
+
+	"rizon-backend/internal/models"
+)
+
+// Email HTML takes its inline styles from the theme through
+// {{css "name"}}, or {{css "button" .Brand.PrimaryColor}} for an accent
+// color, so one template serves every theme.
+const (
+	themeStandard     = "standard"
+	themeHighContrast = "high_contrast"
+)
+
+type theme struct {
+	styles map[string]string
+	// Whether the accent color replaces {accent}; high contrast ignores
+	// brand colors
+	accent bool
+}
+
+var themes = map[string]theme{
+	themeStandard: {accent: true, styles: map[string]string{
+		"container": "font-family: sans-serif; max-width: 480px; margin: 0 auto; padding: 24px;",
+		"logo":      "max-height: 48px; margin-bottom: 16px;",
+		"heading":   "color: #333;",
+		"message":   "white-space: pre-line;",
+		"button":    "display: inline-block; background: {accent}; color: white; padding: 12px 24px; border-radius: 8px; text-decoration: none; font-weight: 600;",
+		"code":      "font-family: monospace; font-size: 28px; font-weight: 700; letter-spacing: 6px; color: #333;",
+		"note":      "color: #888; font-size: 14px; margin-top: 16px;",
+		"fine":      "color: #aaa; font-size: 12px;",
+		"footer":    "color: #aaa; font-size: 12px; margin-top: 16px;",
+	}},
+	// Black on white, larger type and underlined links (WCAG AAA contrast)
+	themeHighContrast: {styles: map[string]string{
+		"container": "font-family: Arial, Helvetica, sans-serif; max-width: 560px; margin: 0 auto; padding: 24px; background: #ffffff; color: #000000; font-size: 18px; line-height: 1.6;",
+		"logo":      "max-height: 64px; margin-bottom: 16px;",
+		"heading":   "color: #000000; font-size: 26px;",
+		"message":   "color: #000000; white-space: pre-line;",
+		"button":    "display: inline-block; background: #000000; color: #ffffff; padding: 16px 28px; border: 3px solid #000000; border-radius: 4px; text-decoration: underline; font-weight: 700; font-size: 18px;",
+		"code":      "font-family: 'Courier New', monospace; font-size: 32px; font-weight: 700; letter-spacing: 8px; color: #000000;",
+		"note":      "color: #000000; font-size: 18px; margin-top: 16px;",
+		"fine":      "color: #000000; font-size: 16px;",
+		"footer":    "color: #000000; font-size: 16px; margin-top: 16px;",
+	}},
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{3}([0-9a-fA-F]{3})?$`)
+
+// css returns the theme's inline style. The accent must be a hex color;
+// anything else falls back to the default brand color, since the result is
+// trusted as CSS.
+func (t theme) css(name string, accent ...string) htmltemplate.CSS {
+	style := t.styles[name]
+	color := DefaultBrand.PrimaryColor
+	if len(accent) > 0 && colorPattern.MatchString(accent[0]) {
+		color = accent[0]
+	}
+	return htmltemplate.CSS(strings.ReplaceAll(style, "{accent}", color))
+}
+
+// EmailOptions are the recipient's choices for how an email looks.
+type EmailOptions struct {
+	Locale string
+	Format string // a models.EmailFormat*; empty means standard
+}
+
+// EmailOptionsFor returns the options a user chose, preferring locale when
+// it's set (say, from the request) over the user's saved one. user may be
+// nil for someone without an account.
+func EmailOptionsFor(user *models.User, locale string) EmailOptions {
+	if user == nil {
+		return EmailOptions{Locale: locale}
+	}
+	if locale == "" {
+		locale = user.Locale
+	}
+	return EmailOptions{Locale: locale, Format: user.EmailFormat()}
+}
+
+// RenderEmail renders an email template for a recipient. The plain format
+// has no HTML part; every format gets a text part, derived from the HTML if
+// the template has no text of its own.
+func RenderEmail(name string, opts EmailOptions, data interface{}) (*Rendered, error) {
+	theme := themeStandard
+	if opts.Format == models.EmailFormatHighContrast {
+		theme = themeHighContrast
+	}
+	out, err := render(name, ChannelEmail, opts.Locale, theme, data)
+	if err != nil {
+		return nil, err
+	}
+	if out.Text == "" {
+		out.Text = PlainText(out.HTML)
+	}
+	if opts.Format == models.EmailFormatPlain {
+		out.HTML = ""
+	}
+	return out, nil
+}
+
+var (
+	linkPattern      = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	blockEndPattern  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr)>`)
+	tagPattern       = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacePattern     = regexp.MustCompile(`[ \t]+`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// PlainText turns email HTML into readable text: links become
+// "label: url", block ends become line breaks and other tags are dropped.
+func PlainText(body string) string {
+	if body == "" {
+		return ""
+	}
+	body = linkPattern.ReplaceAllStringFunc(body, func(a string) string {
+		m := linkPattern.FindStringSubmatch(a)
+		label := strings.TrimSpace(tagPattern.ReplaceAllString(m[2], ""))
+		if label == "" || label == m[1] {
+			return m[1]
+		}
+		return label + ": " + m[1]
+	})
+	body = blockEndPattern.ReplaceAllString(body, "\n")
+	body = html.UnescapeString(tagPattern.ReplaceAllString(body, ""))
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")) + "\n"
+}
@@ -0,0 +1,219 @@
+package userimport
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// JobType is the job queue type for CSV user imports.
+const JobType = "user_import"
+
+// MaxRows bounds a single import so its report fits in one job document.
+const MaxRows = 50000
+
+// Payload is stored on the import job.
+type Payload struct {
+	CSV string `bson:"csv"`
+}
+
+// Row outcomes
+const (
+	RowCreated   = "created"
+	RowDuplicate = "duplicate"
+	RowInvalid   = "invalid"
+)
+
+// RowResult reports what happened to one CSV row (1-based, header excluded).
+type RowResult struct {
+	Row    int    `bson:"row" json:"row"`
+	Email  string `bson:"email" json:"email"`
+	Status string `bson:"status" json:"status"`
+	Error  string `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+type columns struct {
+	email, createdAt, onboarding int
+}
+
+// ParseHeader validates the CSV header and locates the known columns. Only
+// "email" is required; "created_at" and "onboarding" are optional.
+func ParseHeader(header []string) (columns, error) {
+	cols := columns{email: -1, createdAt: -1, onboarding: -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))) {
+		case "email":
+			cols.email = i
+		case "created_at":
+			cols.createdAt = i
+		case "onboarding", "onboarding_completed":
+			cols.onboarding = i
+		}
+	}
+	if cols.email < 0 {
+		return cols, errors.New("CSV header must include an email column")
+	}
+	return cols, nil
+}
+
+// Validate checks the CSV is readable, has a usable header and isn't too large.
+func Validate(data string) error {
+	r := csv.NewReader(strings.NewReader(data))
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("invalid CSV: %w", err)
+	}
+	if _, err := ParseHeader(header); err != nil {
+		return err
+	}
+	if rows := strings.Count(data, "\n"); rows > MaxRows+1 {
+		return fmt.Errorf("CSV has more than %d rows", MaxRows)
+	}
+	return nil
+}
+
+// Handler returns the job handler that creates users row by row. Rows that
+// can't be imported are reported rather than failing the job, and users
+// created by an earlier attempt of the same job count as created on retry.
+func Handler(userRepo *repository.UserRepo) jobs.Handler {
+	return func(ctx context.Context, job *models.Job) (bson.M, error) {
+		var payload Payload
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, fmt.Errorf("%w: invalid payload: %v", jobs.ErrPermanent, err)
+		}
+
+		r := csv.NewReader(strings.NewReader(payload.CSV))
+		r.FieldsPerRecord = -1
+		header, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", jobs.ErrPermanent, err)
+		}
+		cols, err := ParseHeader(header)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", jobs.ErrPermanent, err)
+		}
+
+		results := []RowResult{}
+		counts := map[string]int{RowCreated: 0, RowDuplicate: 0, RowInvalid: 0}
+		seen := map[string]int{}
+
+		for row := 1; ; row++ {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			result := RowResult{Row: row}
+			if err != nil {
+				result.Status, result.Error = RowInvalid, err.Error()
+			} else {
+				result = importRow(ctx, userRepo, job.ID, cols, record, row, seen)
+				if ctx.Err() != nil {
+					return nil, ctx.Err() // retried; rows done so far are recognised next time
+				}
+			}
+			counts[result.Status]++
+			results = append(results, result)
+		}
+
+		return bson.M{
+			"total":      len(results),
+			"created":    counts[RowCreated],
+			"duplicates": counts[RowDuplicate],
+			"invalid":    counts[RowInvalid],
+			"rows":       results,
+		}, nil
+	}
+}
+
+func importRow(ctx context.Context, userRepo *repository.UserRepo, jobID bson.ObjectID, cols columns, record []string, row int, seen map[string]int) RowResult {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	email := field(cols.email)
+	result := RowResult{Row: row, Email: email}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		result.Status, result.Error = RowInvalid, "invalid email"
+		return result
+	}
+	key := strings.ToLower(email)
+	if first, ok := seen[key]; ok {
+		result.Status, result.Error = RowDuplicate, fmt.Sprintf("same email as row %d", first)
+		return result
+	}
+	seen[key] = row
+
+	createdAt := time.Now()
+	if raw := field(cols.createdAt); raw != "" {
+		t, err := parseTime(raw)
+		if err != nil {
+			result.Status, result.Error = RowInvalid, "invalid created_at"
+			return result
+		}
+		createdAt = t
+	}
+
+	onboarded := false
+	if raw := field(cols.onboarding); raw != "" {
+		switch strings.ToLower(raw) {
+		case "yes", "y":
+			onboarded = true
+		case "no", "n":
+		default:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				result.Status, result.Error = RowInvalid, "invalid onboarding flag"
+				return result
+			}
+			onboarded = b
+		}
+	}
+
+	err = userRepo.Import(ctx, &models.User{
+		Email:               email,
+		OnboardingCompleted: onboarded,
+		CreatedAt:           createdAt,
+		ImportJobID:         &jobID,
+	})
+	switch {
+	case err == nil:
+		result.Status = RowCreated
+	case mongo.IsDuplicateKeyError(err):
+		existing, findErr := userRepo.FindByEmail(ctx, email)
+		if findErr == nil && existing != nil && existing.ImportJobID != nil && *existing.ImportJobID == jobID {
+			result.Status = RowCreated // created by an earlier attempt of this job
+		} else {
+			result.Status, result.Error = RowDuplicate, "user already exists"
+		}
+	default:
+		result.Status, result.Error = RowInvalid, err.Error()
+	}
+	return result
+}
+
+func parseTime(raw string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognised time format")
+}
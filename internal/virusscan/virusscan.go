@@ -0,0 +1,150 @@
+// Package virusscan checks uploaded files for malware before they are
+// stored, using a clamd daemon or an HTTP scanning service.
+package virusscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/httpclient"
+)
+
+// Result is the verdict for one file.
+type Result struct {
+	Infected  bool
+	Signature string // name of the detected threat
+}
+
+// Scanner scans file contents. An error means no verdict was reached, not
+// that the file is infected.
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, data []byte) (*Result, error)
+}
+
+// New creates a scanner for provider: "clamav" (addr is clamd's host:port)
+// or "http" (addr is the scan endpoint URL, apiKey its bearer token).
+func New(provider, addr, apiKey string) (Scanner, error) {
+	switch provider {
+	case "clamav":
+		return &ClamAV{addr: addr, timeout: 30 * time.Second}, nil
+	case "http":
+		return &HTTP{url: addr, apiKey: apiKey, client: httpclient.New("virusscan", httpclient.Options{Timeout: 30 * time.Second})}, nil
+	}
+	return nil, fmt.Errorf("unknown virus scanner %q", provider)
+}
+
+// ClamAV streams files to clamd over TCP with the INSTREAM command.
+type ClamAV struct {
+	addr    string
+	timeout time.Duration
+}
+
+func (c *ClamAV) Name() string { return "clamav" }
+
+// clamChunk is the size of each INSTREAM chunk; clamd's StreamMaxLength
+// still caps the whole file.
+const clamChunk = 64 << 10
+
+func (c *ClamAV) Scan(ctx context.Context, data []byte) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	var size [4]byte
+	for start := 0; start < len(data); start += clamChunk {
+		chunk := data[start:min(start+clamChunk, len(data))]
+		binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+		if _, err := conn.Write(size[:]); err != nil {
+			return nil, fmt.Errorf("clamd: %w", err)
+		}
+		if _, err := conn.Write(chunk); err != nil {
+			return nil, fmt.Errorf("clamd: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := conn.Write(size[:]); err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := io.ReadAll(io.LimitReader(conn, 4<<10))
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+	return parseClamReply(string(reply))
+}
+
+// parseClamReply reads "stream: OK", "stream: <name> FOUND" or
+// "... ERROR" replies.
+func parseClamReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	_, verdict, _ := strings.Cut(reply, ": ")
+	switch {
+	case verdict == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return nil, fmt.Errorf("clamd: %s", reply)
+}
+
+// HTTP posts the raw file to a scanning service that answers
+// {"infected": bool, "signature": "..."}.
+type HTTP struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func (h *HTTP) Name() string { return "http" }
+
+func (h *HTTP) Scan(ctx context.Context, data []byte) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scan service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scan service: status %d", resp.StatusCode)
+	}
+
+	var verdict struct {
+		Infected  *bool  `json:"infected"`
+		Signature string `json:"signature"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("scan service: %w", err)
+	}
+	if verdict.Infected == nil {
+		return nil, fmt.Errorf("scan service: response has no verdict")
+	}
+	return &Result{Infected: *verdict.Infected, Signature: verdict.Signature}, nil
+}
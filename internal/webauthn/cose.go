@@ -0,0 +1,107 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithms offered to authenticators, in order of preference.
+const (
+	AlgES256 = -7   // ECDSA P-256 with SHA-256, used by Apple and Google passkeys
+	AlgEdDSA = -8   // Ed25519, used by some security keys
+	AlgRS256 = -257 // RSA PKCS#1 v1.5 with SHA-256, used by Windows Hello
+)
+
+// SupportedAlgorithms are the COSE algorithms credentials may use.
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE_Key labels and values (RFC 9053).
+const (
+	coseKty = 1
+	coseAlg = 3
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// publicKey is a credential public key decoded from its COSE_Key form.
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parsePublicKey(cose []byte) (*publicKey, error) {
+	decoded, err := decodeCBOR(cose)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("public key is not a COSE key")
+	}
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+	crv, _ := m[int64(-1)].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256 && crv == coseCrvP256:
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 public key")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		// Rejects points that aren't on the curve
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, errors.New("invalid P-256 public key")
+		}
+		return &publicKey{alg: alg, key: &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}}, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA && crv == coseCrvEd25519:
+		x, _ := m[int64(-2)].([]byte)
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n)*8 < 2048 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA public key")
+		}
+		return &publicKey{alg: alg, key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	}
+	return nil, errors.New("unsupported public key algorithm")
+}
+
+// verify checks sig over message.
+func (k *publicKey) verify(message, sig []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+	return false
+}
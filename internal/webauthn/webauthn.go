@@ -0,0 +1,433 @@
+// Package webauthn implements the relying party side of WebAuthn for
+// passkeys: it builds the options for navigator.credentials.create/get (or
+// the native passkey APIs) and verifies what the authenticator returns.
+// Only "none" attestation is requested, so attestation statements are not
+// checked.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidResponse is returned when an authenticator response can't be
+// used, wrapped with the reason.
+var ErrInvalidResponse = errors.New("invalid passkey response")
+
+// ChallengeTimeout is how long the user has to complete a ceremony.
+const ChallengeTimeout = 5 * time.Minute
+
+// Config is the relying party, i.e. this backend.
+type Config struct {
+	RPID   string // domain the passkeys are bound to, e.g. "rizon.app"
+	RPName string // shown by some authenticators
+	// Origins are the accepted clientDataJSON origins: "https://<RPID>" by
+	// default. Android apps sign with "android:apk-key-hash:<hash>".
+	Origins []string
+}
+
+// RelyingParty builds ceremony options and verifies responses.
+type RelyingParty struct {
+	cfg      Config
+	rpIDHash [32]byte
+}
+
+func New(cfg Config) (*RelyingParty, error) {
+	if cfg.RPID == "" {
+		return nil, errors.New("webauthn: RPID is required")
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = cfg.RPID
+	}
+	if len(cfg.Origins) == 0 {
+		cfg.Origins = []string{"https://" + cfg.RPID}
+	}
+	return &RelyingParty{cfg: cfg, rpIDHash: sha256.Sum256([]byte(cfg.RPID))}, nil
+}
+
+// Bytes is binary data sent as unpadded base64url, as in the WebAuthn JSON
+// encoding.
+type Bytes []byte
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return fmt.Errorf("invalid base64url: %w", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// String returns the base64url form, which is how challenges and
+// credential IDs are stored.
+func (b Bytes) String() string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// NewChallenge returns 32 random bytes.
+func NewChallenge() (Bytes, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// --- Options ---
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          Bytes  `json:"id"` // the user handle returned on sign-in
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         Bytes    `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions are PublicKeyCredentialCreationOptions in their JSON form.
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"` // milliseconds
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are PublicKeyCredentialRequestOptions in their JSON form.
+type RequestOptions struct {
+	Challenge        Bytes                  `json:"challenge"`
+	Timeout          int64                  `json:"timeout"` // milliseconds
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// CreationOptions asks for a discoverable, user-verified credential (a
+// passkey) for user. exclude lists the user's existing credentials so the
+// same authenticator isn't registered twice.
+func (rp *RelyingParty) CreationOptions(challenge Bytes, user UserEntity, exclude []CredentialDescriptor) *CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+	return &CreationOptions{
+		Challenge:          challenge,
+		RP:                 RelyingPartyEntity{ID: rp.cfg.RPID, Name: rp.cfg.RPName},
+		User:               user,
+		PubKeyCredParams:   params,
+		Timeout:            ChallengeTimeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions asks for any passkey of this relying party; the user picks
+// one and the response says whose it is.
+func (rp *RelyingParty) RequestOptions(challenge Bytes) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          ChallengeTimeout.Milliseconds(),
+		RPID:             rp.cfg.RPID,
+		UserVerification: "required",
+	}
+}
+
+// --- Responses ---
+
+// RegistrationResponse is a PublicKeyCredential from a create() call in its
+// JSON form.
+type RegistrationResponse struct {
+	ID       string          `json:"id"`
+	RawID    Bytes           `json:"rawId"`
+	Type     string          `json:"type"`
+	Response AttestationData `json:"response"`
+}
+
+type AttestationData struct {
+	ClientDataJSON    Bytes    `json:"clientDataJSON"`
+	AttestationObject Bytes    `json:"attestationObject"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// AssertionResponse is a PublicKeyCredential from a get() call in its JSON
+// form.
+type AssertionResponse struct {
+	ID       string        `json:"id"`
+	RawID    Bytes         `json:"rawId"`
+	Type     string        `json:"type"`
+	Response AssertionData `json:"response"`
+}
+
+type AssertionData struct {
+	ClientDataJSON    Bytes `json:"clientDataJSON"`
+	AuthenticatorData Bytes `json:"authenticatorData"`
+	Signature         Bytes `json:"signature"`
+	UserHandle        Bytes `json:"userHandle,omitempty"`
+}
+
+// Credential is a newly registered passkey.
+type Credential struct {
+	ID        Bytes
+	PublicKey []byte // COSE_Key, passed back to VerifyAssertion
+	Algorithm int64
+	SignCount uint32
+	AAGUID    Bytes // authenticator model; all zeros for most passkey providers
+	BackedUp  bool  // synced to the user's cloud account (iCloud Keychain, Google Password Manager)
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// Challenge returns the challenge embedded in a response's clientDataJSON,
+// to look up the ceremony it answers. The response still has to be
+// verified against it.
+func Challenge(clientDataJSON []byte) (Bytes, error) {
+	var cd clientData
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil {
+		return nil, fmt.Errorf("%w: malformed client data", ErrInvalidResponse)
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(cd.Challenge, "="))
+	if err != nil || len(challenge) == 0 {
+		return nil, fmt.Errorf("%w: malformed challenge", ErrInvalidResponse)
+	}
+	return challenge, nil
+}
+
+func (rp *RelyingParty) checkClientData(raw []byte, ceremony string, challenge Bytes) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return errors.New("malformed client data")
+	}
+	if cd.Type != ceremony {
+		return fmt.Errorf("client data type is %q", cd.Type)
+	}
+	if strings.TrimRight(cd.Challenge, "=") != challenge.String() {
+		return errors.New("challenge mismatch")
+	}
+	if !slices.Contains(rp.cfg.Origins, cd.Origin) {
+		return fmt.Errorf("origin %q is not allowed", cd.Origin)
+	}
+	return nil
+}
+
+// Authenticator data flags.
+const (
+	flagUserPresent   = 0x01
+	flagUserVerified  = 0x04
+	flagBackedUp      = 0x10
+	flagAttestedData  = 0x40
+	flagHasExtensions = 0x80
+)
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte // COSE_Key
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	ad := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+	if ad.flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data too short")
+		}
+		ad.aaguid = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, errors.New("credential id truncated")
+		}
+		ad.credentialID, rest = rest[:idLen], rest[idLen:]
+		_, after, err := cborItem(rest, 0)
+		if err != nil {
+			return nil, errors.New("malformed credential public key")
+		}
+		ad.publicKey, rest = rest[:len(rest)-len(after)], after
+	}
+	if ad.flags&flagHasExtensions != 0 {
+		if _, after, err := cborItem(rest, 0); err != nil || len(after) != 0 {
+			return nil, errors.New("malformed extensions")
+		}
+		rest = nil
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing authenticator data")
+	}
+	return ad, nil
+}
+
+func (rp *RelyingParty) checkAuthenticatorData(ad *authenticatorData) error {
+	if !bytes.Equal(ad.rpIDHash, rp.rpIDHash[:]) {
+		return errors.New("rp id mismatch")
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return errors.New("user not present")
+	}
+	if ad.flags&flagUserVerified == 0 {
+		return errors.New("user not verified")
+	}
+	return nil
+}
+
+// VerifyRegistration checks a create() response against the challenge that
+// was issued for it and returns the new credential.
+func (rp *RelyingParty) VerifyRegistration(challenge Bytes, resp *RegistrationResponse) (*Credential, error) {
+	cred, err := rp.verifyRegistration(challenge, resp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return cred, nil
+}
+
+func (rp *RelyingParty) verifyRegistration(challenge Bytes, resp *RegistrationResponse) (*Credential, error) {
+	if resp.Type != "public-key" {
+		return nil, errors.New("not a public key credential")
+	}
+	if err := rp.checkClientData(resp.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, errCBOR
+	}
+	raw, _ := obj["authData"].([]byte)
+	if raw == nil {
+		return nil, errors.New("attestation object has no authenticator data")
+	}
+	ad, err := parseAuthenticatorData(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.checkAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+	if ad.flags&flagAttestedData == 0 {
+		return nil, errors.New("no attested credential data")
+	}
+	if len(ad.credentialID) == 0 || len(ad.credentialID) > 1023 {
+		return nil, errors.New("invalid credential id")
+	}
+	if len(resp.RawID) > 0 && !bytes.Equal(resp.RawID, ad.credentialID) {
+		return nil, errors.New("credential id mismatch")
+	}
+	key, err := parsePublicKey(ad.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        ad.credentialID,
+		PublicKey: ad.publicKey,
+		Algorithm: key.alg,
+		SignCount: ad.signCount,
+		AAGUID:    ad.aaguid,
+		BackedUp:  ad.flags&flagBackedUp != 0,
+	}, nil
+}
+
+// VerifyAssertion checks a get() response against the challenge that was
+// issued for it and the stored credential's public key and signature
+// counter. It returns the new counter.
+func (rp *RelyingParty) VerifyAssertion(challenge Bytes, resp *AssertionResponse, publicKey []byte, signCount uint32) (uint32, error) {
+	count, err := rp.verifyAssertion(challenge, resp, publicKey, signCount)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidResponse, err)
+	}
+	return count, nil
+}
+
+func (rp *RelyingParty) verifyAssertion(challenge Bytes, resp *AssertionResponse, publicKey []byte, signCount uint32) (uint32, error) {
+	if resp.Type != "public-key" {
+		return 0, errors.New("not a public key credential")
+	}
+	if err := rp.checkClientData(resp.Response.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+	ad, err := parseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.checkAuthenticatorData(ad); err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(append([]byte{}, resp.Response.AuthenticatorData...), clientDataHash[:]...)
+	if !key.verify(signed, resp.Response.Signature) {
+		return 0, errors.New("signature mismatch")
+	}
+
+	// Synced passkeys always report 0. A counter that goes backwards means
+	// the authenticator was cloned.
+	if (ad.signCount != 0 || signCount != 0) && ad.signCount <= signCount {
+		return 0, fmt.Errorf("signature counter went from %d to %d", signCount, ad.signCount)
+	}
+	return ad.signCount, nil
+}
@@ -0,0 +1,322 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// Test vectors are built the way an authenticator would: a CBOR-encoded
+// attestation object or authenticator data, signed with a fresh P-256 key.
+
+type cborMap [][2]interface{} // ordered, so encodings are stable
+
+func cborHead(major byte, n int) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	}
+}
+
+func encodeCBOR(v interface{}) []byte {
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return cborHead(1, -1-v)
+		}
+		return cborHead(0, v)
+	case []byte:
+		return append(cborHead(2, len(v)), v...)
+	case string:
+		return append(cborHead(3, len(v)), v...)
+	case cborMap:
+		out := cborHead(5, len(v))
+		for _, kv := range v {
+			out = append(out, encodeCBOR(kv[0])...)
+			out = append(out, encodeCBOR(kv[1])...)
+		}
+		return out
+	}
+	panic("unsupported CBOR value")
+}
+
+type authenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &authenticator{key: key, credentialID: []byte("credential-1")}
+}
+
+func (a *authenticator) coseKey() []byte {
+	return encodeCBOR(cborMap{
+		{coseKty, coseKtyEC2},
+		{coseAlg, AlgES256},
+		{-1, coseCrvP256},
+		{-2, a.key.X.FillBytes(make([]byte, 32))},
+		{-3, a.key.Y.FillBytes(make([]byte, 32))},
+	})
+}
+
+func authData(rpID string, flags byte, signCount uint32, attested []byte) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	data := append(hash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	return append(data, attested...)
+}
+
+func (a *authenticator) attestedData() []byte {
+	data := make([]byte, 16) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	return append(data, a.coseKey()...)
+}
+
+func clientDataJSON(ceremony string, challenge Bytes, origin string) []byte {
+	raw, _ := json.Marshal(clientData{Type: ceremony, Challenge: challenge.String(), Origin: origin})
+	return raw
+}
+
+func attestationObject(authData []byte) []byte {
+	return encodeCBOR(cborMap{{"fmt", "none"}, {"attStmt", cborMap{}}, {"authData", authData}})
+}
+
+func (a *authenticator) sign(t *testing.T, authData, clientDataJSON []byte) []byte {
+	t.Helper()
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+const testOrigin = "https://rizon.app"
+
+func newRelyingParty(t *testing.T) *RelyingParty {
+	t.Helper()
+	rp, err := New(Config{RPID: "rizon.app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	rp := newRelyingParty(t)
+	a := newAuthenticator(t)
+	challenge := Bytes("registration-challenge")
+	const uv = flagUserPresent | flagUserVerified | flagAttestedData
+
+	tests := []struct {
+		name      string
+		rpID      string
+		flags     byte
+		ceremony  string
+		challenge Bytes
+		origin    string
+		wantErr   bool
+	}{
+		{"valid", "rizon.app", uv, "webauthn.create", challenge, testOrigin, false},
+		{"backed up", "rizon.app", uv | flagBackedUp, "webauthn.create", challenge, testOrigin, false},
+		{"wrong rp id", "evil.example", uv, "webauthn.create", challenge, testOrigin, true},
+		{"user not present", "rizon.app", uv &^ flagUserPresent, "webauthn.create", challenge, testOrigin, true},
+		{"user not verified", "rizon.app", uv &^ flagUserVerified, "webauthn.create", challenge, testOrigin, true},
+		{"no attested data", "rizon.app", flagUserPresent | flagUserVerified, "webauthn.create", challenge, testOrigin, true},
+		{"wrong challenge", "rizon.app", uv, "webauthn.create", Bytes("other-challenge"), testOrigin, true},
+		{"wrong origin", "rizon.app", uv, "webauthn.create", challenge, "https://evil.example", true},
+		{"assertion type", "rizon.app", uv, "webauthn.get", challenge, testOrigin, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attested []byte
+			if tt.flags&flagAttestedData != 0 {
+				attested = a.attestedData()
+			}
+			resp := &RegistrationResponse{
+				Type:  "public-key",
+				RawID: a.credentialID,
+				Response: AttestationData{
+					ClientDataJSON:    clientDataJSON(tt.ceremony, tt.challenge, tt.origin),
+					AttestationObject: attestationObject(authData(tt.rpID, tt.flags, 0, attested)),
+				},
+			}
+			cred, err := rp.VerifyRegistration(challenge, resp)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidResponse) {
+					t.Fatalf("err = %v, want ErrInvalidResponse", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(cred.ID) != string(a.credentialID) || cred.Algorithm != AlgES256 {
+				t.Errorf("credential = %x alg %d, want %x alg %d", cred.ID, cred.Algorithm, a.credentialID, AlgES256)
+			}
+			if cred.BackedUp != (tt.flags&flagBackedUp != 0) {
+				t.Errorf("backed up = %v", cred.BackedUp)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	rp := newRelyingParty(t)
+	a := newAuthenticator(t)
+	challenge := Bytes("assertion-challenge")
+	const uv = flagUserPresent | flagUserVerified
+
+	tests := []struct {
+		name      string
+		rpID      string
+		flags     byte
+		stored    uint32 // sign count on file
+		signCount uint32 // sign count the authenticator reports
+		challenge Bytes
+		origin    string
+		tamper    bool // sign different authenticator data
+		wantErr   bool
+	}{
+		{"valid", "rizon.app", uv, 4, 5, challenge, testOrigin, false, false},
+		{"synced passkey without a counter", "rizon.app", uv, 0, 0, challenge, testOrigin, false, false},
+		{"wrong rp id", "evil.example", uv, 4, 5, challenge, testOrigin, false, true},
+		{"user not present", "rizon.app", uv &^ flagUserPresent, 4, 5, challenge, testOrigin, false, true},
+		{"user not verified", "rizon.app", uv &^ flagUserVerified, 4, 5, challenge, testOrigin, false, true},
+		{"counter repeated", "rizon.app", uv, 5, 5, challenge, testOrigin, false, true},
+		{"counter went backwards", "rizon.app", uv, 5, 3, challenge, testOrigin, false, true},
+		{"counter reset to zero", "rizon.app", uv, 5, 0, challenge, testOrigin, false, true},
+		{"wrong challenge", "rizon.app", uv, 4, 5, Bytes("other-challenge"), testOrigin, false, true},
+		{"wrong origin", "rizon.app", uv, 4, 5, challenge, "https://evil.example", false, true},
+		{"bad signature", "rizon.app", uv, 4, 5, challenge, testOrigin, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ad := authData(tt.rpID, tt.flags, tt.signCount, nil)
+			cd := clientDataJSON("webauthn.get", tt.challenge, tt.origin)
+			signed := ad
+			if tt.tamper {
+				signed = authData(tt.rpID, tt.flags, tt.signCount+1, nil)
+			}
+			resp := &AssertionResponse{
+				Type:  "public-key",
+				RawID: a.credentialID,
+				Response: AssertionData{
+					ClientDataJSON:    cd,
+					AuthenticatorData: ad,
+					Signature:         a.sign(t, signed, cd),
+				},
+			}
+			count, err := rp.VerifyAssertion(challenge, resp, a.coseKey(), tt.stored)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidResponse) {
+					t.Fatalf("err = %v, want ErrInvalidResponse", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.signCount {
+				t.Errorf("sign count = %d, want %d", count, tt.signCount)
+			}
+		})
+	}
+}
+
+func TestMalformedResponsesAreRejected(t *testing.T) {
+	rp := newRelyingParty(t)
+	a := newAuthenticator(t)
+	challenge := Bytes("challenge")
+	regCD := clientDataJSON("webauthn.create", challenge, testOrigin)
+	getCD := clientDataJSON("webauthn.get", challenge, testOrigin)
+	attObj := attestationObject(authData("rizon.app", flagUserPresent|flagUserVerified|flagAttestedData, 0, a.attestedData()))
+	assertion := authData("rizon.app", flagUserPresent|flagUserVerified, 1, nil)
+
+	// Every truncation of a valid response must fail cleanly
+	for n := 0; n < len(attObj); n++ {
+		resp := &RegistrationResponse{Type: "public-key", Response: AttestationData{ClientDataJSON: regCD, AttestationObject: attObj[:n]}}
+		if _, err := rp.VerifyRegistration(challenge, resp); !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("attestation object cut to %d bytes: err = %v", n, err)
+		}
+	}
+	for n := 0; n < len(assertion); n++ {
+		resp := &AssertionResponse{Type: "public-key", Response: AssertionData{ClientDataJSON: getCD, AuthenticatorData: assertion[:n], Signature: a.sign(t, assertion[:n], getCD)}}
+		if _, err := rp.VerifyAssertion(challenge, resp, a.coseKey(), 0); !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("authenticator data cut to %d bytes: err = %v", n, err)
+		}
+	}
+	key := a.coseKey()
+	for n := 0; n < len(key); n++ {
+		resp := &AssertionResponse{Type: "public-key", Response: AssertionData{ClientDataJSON: getCD, AuthenticatorData: assertion, Signature: a.sign(t, assertion, getCD)}}
+		if _, err := rp.VerifyAssertion(challenge, resp, key[:n], 0); !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("public key cut to %d bytes: err = %v", n, err)
+		}
+	}
+
+	malformed := map[string][]byte{
+		"not a map":             encodeCBOR("authData"),
+		"authData not bytes":    encodeCBOR(cborMap{{"authData", "text"}}),
+		"huge byte string":      {0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"huge map":              {0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"huge array":            {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"indefinite length map": {0xbf, 0xff},
+		"tag":                   {0xc0, 0x00},
+		"float":                 {0xfa, 0x00, 0x00, 0x00, 0x00},
+		"array map key":         {0xa1, 0x80, 0x00},
+		"deep nesting":          []byte("\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x81\x00"),
+		"trailing bytes":        append(attObj, 0x00),
+	}
+	for name, obj := range malformed {
+		resp := &RegistrationResponse{Type: "public-key", Response: AttestationData{ClientDataJSON: regCD, AttestationObject: obj}}
+		if _, err := rp.VerifyRegistration(challenge, resp); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("%s: err = %v, want ErrInvalidResponse", name, err)
+		}
+	}
+
+	for _, cd := range [][]byte{nil, []byte("{"), []byte(`{"type":"webauthn.create","challenge":"!!"}`)} {
+		resp := &RegistrationResponse{Type: "public-key", Response: AttestationData{ClientDataJSON: cd, AttestationObject: attObj}}
+		if _, err := rp.VerifyRegistration(challenge, resp); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("client data %q: err = %v, want ErrInvalidResponse", cd, err)
+		}
+	}
+}
+
+func TestChallenge(t *testing.T) {
+	want := Bytes("the-challenge")
+	got, err := Challenge(clientDataJSON("webauthn.get", want, testOrigin))
+	if err != nil || string(got) != string(want) {
+		t.Fatalf("Challenge = %q, %v; want %q", got, err, want)
+	}
+	for _, raw := range []string{"", "{", `{"challenge":""}`, `{"challenge":"%%%"}`} {
+		if _, err := Challenge([]byte(raw)); !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("Challenge(%q): err = %v, want ErrInvalidResponse", raw, err)
+		}
+	}
+}
+
+func FuzzDecodeCBOR(f *testing.F) {
+	f.Add(attestationObject(authData("rizon.app", flagUserPresent, 0, nil)))
+	f.Add([]byte{0xa1, 0x01, 0x02})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decodeCBOR(data)
+		parseAuthenticatorData(data)
+		parsePublicKey(data)
+	})
+}
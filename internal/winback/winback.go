@@ -0,0 +1,161 @@
+// Package winback emails users who stopped using the app, as configured by
+// admins at /admin/winback, and tracks how many come back. Email goes through
+// the bulkmail queue; there is no push channel to send on.
+package winback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"rizon-backend/internal/bulkmail"
+	"rizon-backend/internal/jobs"
+	"rizon-backend/internal/mailer"
+	"rizon-backend/internal/models"
+	"rizon-backend/internal/repository"
+	"rizon-backend/internal/templates"
+)
+
+// Attribution is how long after a winback email a return counts as
+// reactivated.
+const Attribution = 30 * 24 * time.Hour
+
+// MaxPerRun caps the emails queued by one run.
+const MaxPerRun = 1000
+
+// Validate checks the settings in place, lower-casing excluded domains and
+// defaulting the cooldown to 90 days.
+func Validate(settings *models.WinbackSettings) error {
+	if settings.InactiveDays < 7 || settings.InactiveDays > 365 {
+		return errors.New("inactive_days must be between 7 and 365")
+	}
+	if settings.CooldownDays == 0 {
+		settings.CooldownDays = 90
+	}
+	if settings.CooldownDays < settings.InactiveDays {
+		return errors.New("cooldown_days must be at least inactive_days")
+	}
+	settings.Subject = strings.TrimSpace(settings.Subject)
+	settings.Message = strings.TrimSpace(settings.Message)
+	if settings.Enabled && (settings.Subject == "" || settings.Message == "") {
+		return errors.New("subject and message are required")
+	}
+	if settings.LinkURL != "" {
+		if u, err := url.Parse(settings.LinkURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return errors.New("link_url must be an http(s) URL")
+		}
+	}
+	domains := make([]string, 0, len(settings.ExcludeDomains))
+	for _, d := range settings.ExcludeDomains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" || strings.ContainsAny(d, "@ ") {
+			return fmt.Errorf("invalid excluded domain %q", d)
+		}
+		domains = append(domains, d)
+	}
+	settings.ExcludeDomains = domains
+	return nil
+}
+
+type Runner struct {
+	repo         *repository.WinbackRepo
+	users        *repository.UserRepo
+	audiences    *repository.AudienceRepo
+	suppressions *repository.EmailSuppressionRepo
+	queue        *jobs.Queue
+}
+
+func NewRunner(repo *repository.WinbackRepo, users *repository.UserRepo, audiences *repository.AudienceRepo, suppressions *repository.EmailSuppressionRepo, queue *jobs.Queue) *Runner {
+	return &Runner{
+		repo:         repo,
+		users:        users,
+		audiences:    audiences,
+		suppressions: suppressions,
+		queue:        queue,
+	}
+}
+
+// Run records users who came back since their email, then queues winback
+// email for users now inactive. Meant to run from the scheduler.
+func (r *Runner) Run(ctx context.Context, now time.Time) (queued, reactivated int, err error) {
+	reactivated, err = r.repo.MarkReactivated(ctx, now.Add(-Attribution))
+	if err != nil {
+		return 0, 0, fmt.Errorf("marking reactivations: %w", err)
+	}
+
+	settings, err := r.repo.Settings(ctx)
+	if err != nil || settings == nil || !settings.Enabled {
+		return 0, reactivated, err
+	}
+	var filter models.AudienceFilter
+	if settings.AudienceID != nil {
+		audience, err := r.audiences.FindByID(ctx, *settings.AudienceID)
+		if err != nil {
+			return 0, reactivated, err
+		}
+		if audience == nil {
+			return 0, reactivated, fmt.Errorf("winback audience %s no longer exists", settings.AudienceID.Hex())
+		}
+		filter = audience.Filter
+	}
+
+	users, err := r.users.InactiveForWinback(ctx, filter,
+		now.AddDate(0, 0, -settings.InactiveDays),
+		now.AddDate(0, 0, -settings.CooldownDays),
+		settings.ExcludeDomains, MaxPerRun)
+	if err != nil {
+		return 0, reactivated, fmt.Errorf("finding inactive users: %w", err)
+	}
+	for i := range users {
+		sent, err := r.send(ctx, settings, &users[i])
+		if err != nil {
+			return queued, reactivated, err
+		}
+		if sent {
+			queued++
+		}
+	}
+	return queued, reactivated, nil
+}
+
+// send queues the email for one user, or records why it was skipped so the
+// cooldown applies either way.
+func (r *Runner) send(ctx context.Context, settings *models.WinbackSettings, user *models.User) (bool, error) {
+	record := &models.WinbackSend{UserID: user.ID, LastSeenAt: *user.LastSeenAt}
+	if p := user.Preferences; p != nil && p.ProductEmails != nil && !*p.ProductEmails {
+		record.Skipped = models.ReminderSkippedPreferences
+	} else if suppressed, err := r.suppressions.IsSuppressed(ctx, user.Email); err != nil {
+		return false, fmt.Errorf("checking email suppression: %w", err)
+	} else if suppressed {
+		record.Skipped = models.ReminderSkippedSuppressed
+	}
+	if err := r.repo.Record(ctx, record); err != nil || record.Skipped != "" {
+		return false, err
+	}
+
+	content, err := templates.RenderEmail("announcement", templates.EmailOptionsFor(user, ""), map[string]interface{}{
+		"Subject":   settings.Subject,
+		"Message":   settings.Message,
+		"LinkURL":   settings.LinkURL,
+		"LinkLabel": settings.LinkLabel,
+		"Brand":     templates.DefaultBrand,
+	})
+	var job *models.Job
+	if err == nil {
+		job, err = bulkmail.Enqueue(ctx, r.queue, user, mailer.FromRendered(user.Email, content), "winback")
+	}
+	if err != nil {
+		if deleteErr := r.repo.Delete(ctx, record.ID); deleteErr != nil {
+			log.Printf("Error removing unsent winback record: %v", deleteErr)
+		}
+		return false, fmt.Errorf("queueing winback email: %w", err)
+	}
+	if err := r.repo.SetJob(ctx, record.ID, job.ID); err != nil {
+		log.Printf("Error recording winback job: %v", err)
+	}
+	return true, nil
+}